@@ -19,6 +19,8 @@ import (
 	"net"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/klog"
@@ -26,21 +28,29 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver"
 	"github.com/vmware-tanzu/antrea/pkg/agent/cniserver"
-	_ "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/ipam"
+	"github.com/vmware-tanzu/antrea/pkg/agent/cniserver/ipam"
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/bgp"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/ipseccertificate"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/networkpolicy"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/noderoute"
+	"github.com/vmware-tanzu/antrea/pkg/agent/controller/serviceexternalip"
 	"github.com/vmware-tanzu/antrea/pkg/agent/controller/traceflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/connections"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/exporter"
 	"github.com/vmware-tanzu/antrea/pkg/agent/flowexporter/flowrecords"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipassigner"
 	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+	nplk8s "github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/k8s"
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/portcache"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy"
+	proxytypes "github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/querier"
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/stats"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
 	"github.com/vmware-tanzu/antrea/pkg/features"
@@ -63,7 +73,7 @@ const informerDefaultResync = 12 * time.Hour
 func run(o *Options) error {
 	klog.Infof("Starting Antrea agent (version %s)", version.GetFullVersion())
 	// Create K8s Clientset, CRD Clientset and SharedInformerFactory for the given config.
-	k8sClient, _, crdClient, err := k8s.CreateClients(o.config.ClientConnection)
+	k8sClient, _, crdClient, err := k8s.CreateClients(o.config.ClientConnection, o.config.KubeAPIServerOverride)
 	if err != nil {
 		return fmt.Errorf("error creating K8s clients: %v", err)
 	}
@@ -92,7 +102,8 @@ func run(o *Options) error {
 	ovsBridgeMgmtAddr := ofconfig.GetMgmtAddress(o.config.OVSRunDir, o.config.OVSBridge)
 	ofClient := openflow.NewClient(o.config.OVSBridge, ovsBridgeMgmtAddr,
 		features.DefaultFeatureGate.Enabled(features.AntreaProxy),
-		features.DefaultFeatureGate.Enabled(features.AntreaPolicy))
+		features.DefaultFeatureGate.Enabled(features.AntreaPolicy),
+		o.config.AntreaProxy.ProxyAll)
 
 	_, serviceCIDRNet, _ := net.ParseCIDR(o.config.ServiceCIDR)
 	var serviceCIDRNetv6 *net.IPNet
@@ -102,12 +113,28 @@ func run(o *Options) error {
 	}
 
 	_, encapMode := config.GetTrafficEncapModeFromStr(o.config.TrafficEncapMode)
+	_, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	_, ipsecAuthenticationMode := config.GetIPsecAuthenticationModeFromStr(o.config.IPsec.AuthenticationMode)
 	networkConfig := &config.NetworkConfig{
-		TunnelType:        ovsconfig.TunnelType(o.config.TunnelType),
-		TrafficEncapMode:  encapMode,
-		EnableIPSecTunnel: o.config.EnableIPSecTunnel}
+		TunnelType:              ovsconfig.TunnelType(o.config.TunnelType),
+		TrafficEncapMode:        encapMode,
+		TrafficEncryptionMode:   encryptionMode,
+		IPsecAuthenticationMode: ipsecAuthenticationMode}
+
+	var wireGuardConfig *config.WireGuardConfig
+	var wireGuardClient wireguard.Interface
+	if encryptionMode == config.TrafficEncryptionModeWireGuard {
+		wireGuardConfig = &config.WireGuardConfig{
+			Name: defaultWireGuardName,
+			Port: o.config.WireGuard.Port,
+		}
+		wireGuardClient, err = wireguard.New(wireGuardConfig)
+		if err != nil {
+			return fmt.Errorf("error creating WireGuard client: %v", err)
+		}
+	}
 
-	routeClient, err := route.NewClient(serviceCIDRNet, networkConfig, o.config.NoSNAT)
+	routeClient, err := route.NewClient(serviceCIDRNet, serviceCIDRNetv6, networkConfig, o.config.NoSNAT, o.config.AntreaProxy.ProxyAll)
 	if err != nil {
 		return fmt.Errorf("error creating route client: %v", err)
 	}
@@ -131,6 +158,8 @@ func run(o *Options) error {
 		serviceCIDRNet,
 		serviceCIDRNetv6,
 		networkConfig,
+		wireGuardConfig,
+		wireGuardClient,
 		networkReadyCh,
 		features.DefaultFeatureGate.Enabled(features.AntreaProxy))
 	err = agentInitializer.Initialize()
@@ -147,7 +176,8 @@ func run(o *Options) error {
 		routeClient,
 		ifaceStore,
 		networkConfig,
-		nodeConfig)
+		nodeConfig,
+		wireGuardClient)
 
 	// podUpdates is a channel for receiving Pod updates from CNIServer and
 	// notifying NetworkPolicyController to reconcile rules related to the
@@ -162,6 +192,7 @@ func run(o *Options) error {
 		antreaClientProvider,
 		ofClient,
 		ifaceStore,
+		routeClient,
 		nodeConfig.Name,
 		podUpdates,
 		features.DefaultFeatureGate.Enabled(features.AntreaPolicy),
@@ -174,20 +205,40 @@ func run(o *Options) error {
 	// NetworkPolicy stats.
 	var statsCollector *stats.Collector
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
-		statsCollector = stats.NewCollector(antreaClientProvider, ofClient, networkPolicyController)
+		statsCollector = stats.NewCollector(antreaClientProvider, ofClient, routeClient, networkPolicyController)
 	}
 
 	var proxier k8sproxy.Provider
+	// weightedPodInformerFactory watches the Pods which set the weights of their Endpoints for AntreaProxy,
+	// with a dedicated informer, as only a few Pods are expected to have the label.
+	var weightedPodInformerFactory informers.SharedInformerFactory
 	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
+		weightedPodInformerFactory = informers.NewSharedInformerFactoryWithOptions(k8sClient, informerDefaultResync,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = proxytypes.EndpointWeightLabelKey
+			}))
+		weightedPodInformer := weightedPodInformerFactory.Core().V1().Pods()
 		v4Enabled := config.IsIPv4Enabled(nodeConfig, networkConfig.TrafficEncapMode)
 		v6Enabled := config.IsIPv6Enabled(nodeConfig, networkConfig.TrafficEncapMode)
+		endpointSliceEnabled := false
+		if features.DefaultFeatureGate.Enabled(features.EndpointSlice) {
+			// Fall back to the Endpoints API if the K8s cluster is too old to
+			// serve the EndpointSlice API.
+			endpointSliceEnabled, err = k8s.EndpointSliceAPIAvailable(k8sClient)
+			if err != nil {
+				return fmt.Errorf("error checking if EndpointSlice API is available: %v", err)
+			}
+			if !endpointSliceEnabled {
+				klog.Warning("EndpointSlice API is not available, AntreaProxy will use Endpoints API")
+			}
+		}
 		switch {
 		case v4Enabled && v6Enabled:
-			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient)
+			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, weightedPodInformer, ofClient, endpointSliceEnabled, o.config.EnablePrometheusMetrics, o.terminatingEndpointTimeout)
 		case v4Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, false)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, weightedPodInformer, ofClient, false, endpointSliceEnabled, o.config.EnablePrometheusMetrics, o.terminatingEndpointTimeout)
 		case v6Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, true)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, weightedPodInformer, ofClient, true, endpointSliceEnabled, o.config.EnablePrometheusMetrics, o.terminatingEndpointTimeout)
 		default:
 			return fmt.Errorf("at least one of IPv4 or IPv6 should be enabled")
 		}
@@ -210,6 +261,9 @@ func run(o *Options) error {
 	if err != nil {
 		return fmt.Errorf("error initializing CNI server: %v", err)
 	}
+	if features.DefaultFeatureGate.Enabled(features.AntreaIPAM) {
+		ipam.InitializeAntreaIPAMDriver(crdClient, informerFactory.Core().V1().Namespaces(), crdInformerFactory.Core().V1alpha2().IPPools())
+	}
 
 	var traceflowController *traceflow.Controller
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
@@ -227,6 +281,56 @@ func run(o *Options) error {
 			serviceCIDRNet)
 	}
 
+	var bgpController *bgp.Controller
+	if features.DefaultFeatureGate.Enabled(features.BGPPolicy) {
+		bgpController = bgp.NewBGPController(nodeConfig, informerFactory, crdInformerFactory)
+	}
+
+	// serviceExternalIPController assigns the ingress IPs allocated from ExternalIPPools to the Node when it's
+	// elected for them.
+	var serviceExternalIPController *serviceexternalip.Controller
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		ipAssigner, err := ipassigner.NewIPAssigner(nodeConfig.NodeIPAddr.IP, defaultExternalIPDevice)
+		if err != nil {
+			return fmt.Errorf("error creating IPAssigner: %v", err)
+		}
+		serviceExternalIPController = serviceexternalip.NewServiceExternalIPController(
+			nodeConfig.Name,
+			ipAssigner,
+			informerFactory,
+			crdInformerFactory)
+	}
+
+	// nplController exposes the container ports of the local Pods selected by the Services with NodePortLocal
+	// enabled on the ports of the Node. It watches the local Pods only, with a dedicated informer.
+	var nplController *nplk8s.NPLController
+	var localPodInformerFactory informers.SharedInformerFactory
+	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
+		portTable, err := portcache.InitPortTable(o.nplStartPort, o.nplEndPort)
+		if err != nil {
+			return fmt.Errorf("error initializing NodePortLocal port table: %v", err)
+		}
+		localPodInformerFactory = informers.NewSharedInformerFactoryWithOptions(k8sClient, informerDefaultResync,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeConfig.Name).String()
+			}))
+		nplController = nplk8s.NewNPLController(k8sClient,
+			localPodInformerFactory.Core().V1().Pods(),
+			informerFactory.Core().V1().Services(),
+			portTable,
+			nodeConfig.NodeIPAddr.IP.String())
+	}
+
+	var ipsecCertificateController *ipseccertificate.Controller
+	if networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec &&
+		networkConfig.IPsecAuthenticationMode == config.IPsecAuthenticationModeCert {
+		ipsecCertificateController = ipseccertificate.NewIPsecCertificateController(
+			k8sClient,
+			ovsBridgeClient,
+			nodeConfig.Name,
+			o.config.OVSRunDir)
+	}
+
 	// TODO: we should call this after installing flows for initial node routes
 	//  and initial NetworkPolicies so that no packets will be mishandled.
 	if err := agentInitializer.FlowRestoreComplete(); err != nil {
@@ -247,11 +351,18 @@ func run(o *Options) error {
 
 	informerFactory.Start(stopCh)
 	crdInformerFactory.Start(stopCh)
+	if weightedPodInformerFactory != nil {
+		weightedPodInformerFactory.Start(stopCh)
+	}
 
 	go antreaClientProvider.Run(stopCh)
 
 	go nodeRouteController.Run(stopCh)
 
+	if ipsecCertificateController != nil {
+		go ipsecCertificateController.Run(stopCh)
+	}
+
 	go networkPolicyController.Run(stopCh)
 
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
@@ -262,6 +373,19 @@ func run(o *Options) error {
 		go traceflowController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.BGPPolicy) {
+		go bgpController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		go serviceExternalIPController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
+		localPodInformerFactory.Start(stopCh)
+		go nplController.Run(stopCh)
+	}
+
 	agentQuerier := querier.NewAgentQuerier(
 		nodeConfig,
 		networkConfig,
@@ -81,12 +81,14 @@ type AgentConfig struct {
 	HostProcPathPrefix string `yaml:"hostProcPathPrefix,omitempty"`
 	// ClusterIP CIDR range for Services. It's required when AntreaProxy is not enabled, and should be
 	// set to the same value as the one specified by --service-cluster-ip-range for kube-apiserver. When
-	// AntreaProxy is enabled, this parameter is not needed and will be ignored if provided.
+	// AntreaProxy is enabled, this parameter is not needed and will be ignored if provided, unless proxyAll is
+	// enabled.
 	// Default is 10.96.0.0/12
 	ServiceCIDR string `yaml:"serviceCIDR,omitempty"`
 	// ClusterIP CIDR range for IPv6 Services. It's required when using kube-proxy to provide IPv6 Service in a Dual-Stack
 	// cluster or an IPv6 only cluster. The value should be the same as the configuration for kube-apiserver specified by
-	// --service-cluster-ip-range. When AntreaProxy is enabled, this parameter is not needed.
+	// --service-cluster-ip-range. When AntreaProxy is enabled, this parameter is not needed, unless proxyAll is
+	// enabled.
 	// No default value for this field.
 	ServiceCIDRv6 string `yaml:"serviceCIDRv6,omitempty"`
 	// Whether or not to enable IPSec (ESP) encryption for Pod traffic across Nodes. IPSec encryption
@@ -94,7 +96,32 @@ type AgentConfig struct {
 	// authentication. When IPSec tunnel is enabled, the PSK value must be passed to Antrea Agent
 	// through an environment variable: ANTREA_IPSEC_PSK.
 	// Defaults to false.
+	// Deprecated: use trafficEncryptionMode instead.
 	EnableIPSecTunnel bool `yaml:"enableIPSecTunnel,omitempty"`
+	// Determines how tunnel traffic is encrypted. It has the following options:
+	// none(default): Inter-node Pod traffic will not be encrypted.
+	// ipsec:         Enable IPSec (ESP) encryption for Pod traffic across Nodes. Antrea uses
+	//                Preshared Key (PSK) for IKE authentication. When IPSec tunnel is enabled,
+	//                the PSK value must be passed to Antrea Agent through an environment
+	//                variable: ANTREA_IPSEC_PSK. IPSec encryption is supported only for the GRE
+	//                tunnel type in the encap mode.
+	// wireGuard:     Enable WireGuard for tunnel traffic encryption. Inter-node Pod traffic is
+	//                sent through the WireGuard device instead of the tunnel, in both the encap
+	//                and noEncap modes.
+	TrafficEncryptionMode string `yaml:"trafficEncryptionMode,omitempty"`
+	// WireGuard related configurations.
+	WireGuard WireGuardConfig `yaml:"wireGuard"`
+	// IPsec related configurations.
+	IPsec IPsecConfig `yaml:"ipsec"`
+	// NodePortLocal related configurations.
+	NodePortLocal NodePortLocalConfig `yaml:"nodePortLocal"`
+	// AntreaProxy related configurations.
+	AntreaProxy AntreaProxyConfig `yaml:"antreaProxy"`
+	// The address of the Kubernetes apiserver, overriding the one provided by the kubeconfig file or the
+	// in-cluster config. It must be a host string, a host:port pair, or a URL to the base of the apiserver.
+	// It's required when kube-proxy is removed with proxyAll, as the agent must reach the apiserver
+	// before AntreaProxy can load-balance the "kubernetes" Service. Defaults to "".
+	KubeAPIServerOverride string `yaml:"kubeAPIServerOverride,omitempty"`
 	// APIPort is the port for the antrea-agent APIServer to serve on.
 	// Defaults to 10350.
 	APIPort int `yaml:"apiPort,omitempty"`
@@ -116,3 +143,35 @@ type AgentConfig struct {
 	// Defaults to "12".
 	FlowExportFrequency uint `yaml:"flowExportFrequency,omitempty"`
 }
+
+type WireGuardConfig struct {
+	// The port for the WireGuard to receive traffic. Defaults to 51820.
+	Port int `yaml:"port,omitempty"`
+}
+
+type IPsecConfig struct {
+	// The authentication mode of the IPsec tunnels. It has the following options:
+	// psk(default): Use the pre-shared key passed through the environment variable
+	//               ANTREA_IPSEC_PSK.
+	// cert:         Use the certificate of the Node signed by antrea-controller, which
+	//               requires the IPsecCertAuth feature gate to be enabled.
+	AuthenticationMode string `yaml:"authenticationMode,omitempty"`
+}
+
+type NodePortLocalConfig struct {
+	// The range of the Node ports allocated to the container ports of the Pods, in the
+	// format "<start>-<end>", both ends included. Defaults to "40000-41000".
+	PortRange string `yaml:"portRange,omitempty"`
+}
+
+type AntreaProxyConfig struct {
+	// Proxy the ClusterIP Service traffic from the host network (host-network Pods and processes of the
+	// Node) as well, by routing the Service CIDRs to the host gateway. The Service CIDRs must be set with
+	// serviceCIDR and serviceCIDRv6. kube-proxy can then be removed, provided that kubeAPIServerOverride
+	// is set. Defaults to false.
+	ProxyAll bool `yaml:"proxyAll,omitempty"`
+	// How long the flows of an Endpoint removed from a Service are kept for its established
+	// connections, e.g. "90s". It should be longer than the termination grace period of the
+	// Pods of the Services. Defaults to "1m".
+	TerminatingEndpointTimeout string `yaml:"terminatingEndpointTimeout,omitempty"`
+}
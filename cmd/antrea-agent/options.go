@@ -19,11 +19,13 @@ import (
 	"io/ioutil"
 	"net"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
+	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/apis"
@@ -35,11 +37,17 @@ import (
 const (
 	defaultOVSBridge           = "br-int"
 	defaultHostGateway         = "antrea-gw0"
+	defaultWireGuardName       = "antrea-wg0"
+	defaultExternalIPDevice    = "antrea-ext0"
 	defaultHostProcPathPrefix  = "/host"
 	defaultServiceCIDR         = "10.96.0.0/12"
 	defaultTunnelType          = ovsconfig.GeneveTunnel
 	defaultFlowPollInterval    = 5 * time.Second
 	defaultFlowExportFrequency = 12
+	defaultWireGuardPort       = 51820
+	defaultNPLPortRange        = "40000-41000"
+
+	defaultTerminatingEndpointTimeout = time.Minute
 )
 
 type Options struct {
@@ -51,6 +59,11 @@ type Options struct {
 	flowCollector net.Addr
 	// Flow exporter poll interval
 	pollInterval time.Duration
+	// The range of the Node ports allocated by NodePortLocal
+	nplStartPort int
+	nplEndPort   int
+	// How long AntreaProxy keeps the flows of the removed Endpoints
+	terminatingEndpointTimeout time.Duration
 }
 
 func newOptions() *Options {
@@ -98,9 +111,6 @@ func (o *Options) validate(args []string) error {
 		o.config.TunnelType != ovsconfig.GRETunnel && o.config.TunnelType != ovsconfig.STTTunnel {
 		return fmt.Errorf("tunnel type %s is invalid", o.config.TunnelType)
 	}
-	if o.config.EnableIPSecTunnel && o.config.TunnelType != ovsconfig.GRETunnel {
-		return fmt.Errorf("IPSec encyption is supported only for GRE tunnel")
-	}
 	if o.config.OVSDatapathType != ovsconfig.OVSDatapathSystem && o.config.OVSDatapathType != ovsconfig.OVSDatapathNetdev {
 		return fmt.Errorf("OVS datapath type %s is not supported", o.config.OVSDatapathType)
 	}
@@ -108,6 +118,23 @@ func (o *Options) validate(args []string) error {
 	if !ok {
 		return fmt.Errorf("TrafficEncapMode %s is unknown", o.config.TrafficEncapMode)
 	}
+	ok, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	if !ok {
+		return fmt.Errorf("TrafficEncryptionMode %s is unknown", o.config.TrafficEncryptionMode)
+	}
+	if encryptionMode == config.TrafficEncryptionModeIPSec && o.config.TunnelType != ovsconfig.GRETunnel {
+		return fmt.Errorf("IPSec encyption is supported only for GRE tunnel")
+	}
+	if encryptionMode == config.TrafficEncryptionModeWireGuard && encapMode == config.TrafficEncapModeNetworkPolicyOnly {
+		return fmt.Errorf("WireGuard encryption is not supported in %s mode", config.TrafficEncapModeNetworkPolicyOnly)
+	}
+	ok, authenticationMode := config.GetIPsecAuthenticationModeFromStr(o.config.IPsec.AuthenticationMode)
+	if !ok {
+		return fmt.Errorf("IPsec AuthenticationMode %s is unknown", o.config.IPsec.AuthenticationMode)
+	}
+	if authenticationMode == config.IPsecAuthenticationModeCert && !features.DefaultFeatureGate.Enabled(features.IPsecCertAuth) {
+		return fmt.Errorf("IPsec AuthenticationMode %s requires feature gate %s to be enabled", authenticationMode, features.IPsecCertAuth)
+	}
 
 	// Check if the enabled features are supported on the OS.
 	err = o.checkUnsupportedFeatures()
@@ -119,7 +146,7 @@ func (o *Options) validate(args []string) error {
 		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 			return fmt.Errorf("TrafficEncapMode %s requires AntreaProxy to be enabled", o.config.TrafficEncapMode)
 		}
-		if o.config.EnableIPSecTunnel {
+		if encryptionMode == config.TrafficEncryptionModeIPSec {
 			return fmt.Errorf("IPsec tunnel may only be enabled in %s mode", config.TrafficEncapModeEncap)
 		}
 	}
@@ -134,6 +161,29 @@ func (o *Options) validate(args []string) error {
 	if err := o.validateFlowExporterConfig(); err != nil {
 		return fmt.Errorf("failed to validate flow exporter config: %v", err)
 	}
+	if err := o.validateNodePortLocalConfig(); err != nil {
+		return fmt.Errorf("failed to validate NodePortLocal config: %v", err)
+	}
+	if err := o.validateAntreaProxyConfig(); err != nil {
+		return fmt.Errorf("failed to validate AntreaProxy config: %v", err)
+	}
+	return nil
+}
+
+func (o *Options) validateAntreaProxyConfig() error {
+	if o.config.AntreaProxy.ProxyAll && !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
+		return fmt.Errorf("proxyAll requires AntreaProxy to be enabled")
+	}
+	if o.config.AntreaProxy.TerminatingEndpointTimeout != "" {
+		timeout, err := time.ParseDuration(o.config.AntreaProxy.TerminatingEndpointTimeout)
+		if err != nil {
+			return fmt.Errorf("terminatingEndpointTimeout is not provided in right format: %v", err)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("terminatingEndpointTimeout should not be negative")
+		}
+		o.terminatingEndpointTimeout = timeout
+	}
 	return nil
 }
 
@@ -177,6 +227,26 @@ func (o *Options) setDefaults() {
 	if o.config.APIPort == 0 {
 		o.config.APIPort = apis.AntreaAgentAPIPort
 	}
+	if o.config.TrafficEncryptionMode == "" {
+		if o.config.EnableIPSecTunnel {
+			klog.Warning("enableIPSecTunnel is deprecated, use trafficEncryptionMode instead.")
+			o.config.TrafficEncryptionMode = config.TrafficEncryptionModeIPSec.String()
+		} else {
+			o.config.TrafficEncryptionMode = config.TrafficEncryptionModeNone.String()
+		}
+	}
+	if o.config.WireGuard.Port == 0 {
+		o.config.WireGuard.Port = defaultWireGuardPort
+	}
+	if o.config.IPsec.AuthenticationMode == "" {
+		o.config.IPsec.AuthenticationMode = config.IPsecAuthenticationModePSK.String()
+	}
+	if o.config.NodePortLocal.PortRange == "" {
+		o.config.NodePortLocal.PortRange = defaultNPLPortRange
+	}
+	if o.config.AntreaProxy.TerminatingEndpointTimeout == "" {
+		o.terminatingEndpointTimeout = defaultTerminatingEndpointTimeout
+	}
 
 	if o.config.FeatureGates[string(features.FlowExporter)] {
 		if o.config.FlowPollInterval == "" {
@@ -259,3 +329,35 @@ func parseFlowCollectorAddr(addr string) ([]string, error) {
 	}
 	return strSlice, nil
 }
+
+func (o *Options) validateNodePortLocalConfig() error {
+	if !features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
+		return nil
+	}
+	startPort, endPort, err := parsePortRange(o.config.NodePortLocal.PortRange)
+	if err != nil {
+		return fmt.Errorf("portRange %s is invalid: %v", o.config.NodePortLocal.PortRange, err)
+	}
+	o.nplStartPort, o.nplEndPort = startPort, endPort
+	return nil
+}
+
+// parsePortRange parses a port range in the format "<start>-<end>".
+func parsePortRange(portRange string) (int, int, error) {
+	ports := strings.Split(portRange, "-")
+	if len(ports) != 2 {
+		return 0, 0, fmt.Errorf("expected format <start>-<end>")
+	}
+	startPort, err := strconv.Atoi(strings.TrimSpace(ports[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start port: %v", err)
+	}
+	endPort, err := strconv.Atoi(strings.TrimSpace(ports[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end port: %v", err)
+	}
+	if startPort < 1 || endPort > 65535 || startPort > endPort {
+		return 0, 0, fmt.Errorf("ports must satisfy 1 <= start <= end <= 65535")
+	}
+	return startPort, endPort, nil
+}
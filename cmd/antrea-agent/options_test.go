@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -76,3 +77,58 @@ func TestParseFlowCollectorAddr(t *testing.T) {
 		assert.Equal(t, tc.expected, res)
 	}
 }
+
+func TestParsePortRange(t *testing.T) {
+	testcases := []struct {
+		portRange     string
+		expectedStart int
+		expectedEnd   int
+		expectedErr   bool
+	}{
+		{portRange: "40000-41000", expectedStart: 40000, expectedEnd: 41000},
+		{portRange: "61000-61000", expectedStart: 61000, expectedEnd: 61000},
+		{portRange: "40000", expectedErr: true},
+		{portRange: "41000-40000", expectedErr: true},
+		{portRange: "0-100", expectedErr: true},
+		{portRange: "60000-70000", expectedErr: true},
+		{portRange: "a-b", expectedErr: true},
+	}
+	for _, tc := range testcases {
+		start, end, err := parsePortRange(tc.portRange)
+		if tc.expectedErr {
+			assert.Error(t, err, "Expected error for port range %s", tc.portRange)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedStart, start)
+		assert.Equal(t, tc.expectedEnd, end)
+	}
+}
+
+func TestOptions_validateAntreaProxyConfig(t *testing.T) {
+	testcases := []struct {
+		timeout    string
+		expTimeout time.Duration
+		expError   bool
+	}{
+		{timeout: "", expTimeout: defaultTerminatingEndpointTimeout},
+		{timeout: "90s", expTimeout: 90 * time.Second},
+		{timeout: "0s", expTimeout: 0},
+		{timeout: "90", expError: true},
+		{timeout: "-1m", expError: true},
+	}
+	for _, tc := range testcases {
+		testOptions := &Options{
+			config: new(AgentConfig),
+		}
+		testOptions.config.AntreaProxy.TerminatingEndpointTimeout = tc.timeout
+		testOptions.setDefaults()
+		err := testOptions.validateAntreaProxyConfig()
+		if tc.expError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expTimeout, testOptions.terminatingEndpointTimeout)
+		}
+	}
+}
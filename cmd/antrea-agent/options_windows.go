@@ -48,8 +48,12 @@ func (o *Options) checkUnsupportedFeatures() error {
 	if o.config.TunnelType == ovsconfig.GRETunnel {
 		unsupported = append(unsupported, "TunnelType: "+o.config.TunnelType)
 	}
-	if o.config.EnableIPSecTunnel {
-		unsupported = append(unsupported, "IPsecTunnel")
+	_, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode)
+	if encryptionMode != config.TrafficEncryptionModeNone {
+		unsupported = append(unsupported, "TrafficEncryptionMode: "+encryptionMode.String())
+	}
+	if o.config.AntreaProxy.ProxyAll {
+		unsupported = append(unsupported, "AntreaProxy.ProxyAll")
 	}
 
 	if unsupported != nil {
@@ -69,6 +69,16 @@ func TestCheckUnsupportedFeatures(t *testing.T) {
 			AgentConfig{EnableIPSecTunnel: true},
 			false,
 		},
+		{
+			"WireGuard encryption",
+			AgentConfig{TrafficEncryptionMode: config.TrafficEncryptionModeWireGuard.String()},
+			false,
+		},
+		{
+			"AntreaProxy proxyAll",
+			AgentConfig{AntreaProxy: AntreaProxyConfig{ProxyAll: true}},
+			false,
+		},
 		{
 			"hybrid mode and GRE tunnel",
 			AgentConfig{TrafficEncapMode: config.TrafficEncapModeHybrid.String(), TunnelType: ovsconfig.GRETunnel},
@@ -39,4 +39,10 @@ type ControllerConfig struct {
 	// antrea-controller container.
 	// Defaults to true.
 	SelfSignedCert bool `yaml:"selfSignedCert,omitempty"`
+	// Enable leader election among the antrea-controller replicas. All replicas compute the internal
+	// NetworkPolicy objects, while only the leader is ready to serve the Antrea APIs and updates resources
+	// in the K8s API, so that a standby replica can take over quickly when the leader fails.
+	// It must be enabled when running more than one antrea-controller replica.
+	// Defaults to false.
+	EnableLeaderElection bool `yaml:"enableLeaderElection,omitempty"`
 }
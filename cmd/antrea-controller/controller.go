@@ -24,6 +24,7 @@ import (
 
 	genericopenapi "k8s.io/apiserver/pkg/endpoints/openapi"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -35,10 +36,13 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/openapi"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
 	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	"github.com/vmware-tanzu/antrea/pkg/controller/certificatesigningrequest"
+	"github.com/vmware-tanzu/antrea/pkg/controller/leaderelection"
 	"github.com/vmware-tanzu/antrea/pkg/controller/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy/store"
 	"github.com/vmware-tanzu/antrea/pkg/controller/querier"
+	"github.com/vmware-tanzu/antrea/pkg/controller/serviceexternalip"
 	"github.com/vmware-tanzu/antrea/pkg/controller/stats"
 	"github.com/vmware-tanzu/antrea/pkg/controller/traceflow"
 	"github.com/vmware-tanzu/antrea/pkg/features"
@@ -46,6 +50,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/log"
 	"github.com/vmware-tanzu/antrea/pkg/monitor"
 	"github.com/vmware-tanzu/antrea/pkg/signals"
+	"github.com/vmware-tanzu/antrea/pkg/util/env"
 	"github.com/vmware-tanzu/antrea/pkg/version"
 )
 
@@ -70,6 +75,7 @@ const (
 
 var allowedPaths = []string{
 	"/healthz",
+	"/readyz",
 	"/mutate/acnp",
 	"/mutate/anp",
 	"/validate/tier",
@@ -83,7 +89,7 @@ func run(o *Options) error {
 	// Create K8s Clientset, Aggregator Clientset, CRD Clientset and SharedInformerFactory for the given config.
 	// Aggregator Clientset is used to update the CABundle of the APIServices backed by antrea-controller so that
 	// the aggregator can verify its serving certificate.
-	client, aggregatorClient, crdClient, err := k8s.CreateClients(o.config.ClientConnection)
+	client, aggregatorClient, crdClient, err := k8s.CreateClients(o.config.ClientConnection, "")
 	if err != nil {
 		return fmt.Errorf("error creating K8s clients: %v", err)
 	}
@@ -93,6 +99,7 @@ func run(o *Options) error {
 	namespaceInformer := informerFactory.Core().V1().Namespaces()
 	networkPolicyInformer := informerFactory.Networking().V1().NetworkPolicies()
 	nodeInformer := informerFactory.Core().V1().Nodes()
+	serviceInformer := informerFactory.Core().V1().Services()
 	cnpInformer := crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies()
 	externalEntityInformer := crdInformerFactory.Core().V1alpha2().ExternalEntities()
 	anpInformer := crdInformerFactory.Security().V1alpha1().NetworkPolicies()
@@ -113,17 +120,20 @@ func run(o *Options) error {
 		cnpInformer,
 		anpInformer,
 		tierInformer,
+		nodeInformer,
 		addressGroupStore,
 		appliedToGroupStore,
 		networkPolicyStore)
 
 	var networkPolicyStatusController *networkpolicy.StatusController
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
-		networkPolicyStatusController = networkpolicy.NewStatusController(crdClient, networkPolicyStore, cnpInformer, anpInformer)
+		networkPolicyStatusController = networkpolicy.NewStatusController(crdClient, networkPolicyStore, cnpInformer, anpInformer, networkpolicy.NewRuleAnalyzer(networkPolicyController))
 	}
 
 	endpointQuerier := networkpolicy.NewEndpointQuerier(networkPolicyController)
 
+	policyEvaluator := networkpolicy.NewPolicyEvaluator(networkPolicyController, serviceInformer)
+
 	controllerQuerier := querier.NewControllerQuerier(networkPolicyController, o.config.APIPort)
 
 	controllerMonitor := monitor.NewControllerMonitor(crdClient, nodeInformer, controllerQuerier)
@@ -133,6 +143,27 @@ func run(o *Options) error {
 		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, traceflowInformer)
 	}
 
+	// ipsecCSRSigningController approves and signs the certificates requested by antrea-agents for the IPsec
+	// tunnels.
+	var ipsecCSRSigningController *certificatesigningrequest.IPsecCSRSigningController
+	if features.DefaultFeatureGate.Enabled(features.IPsecCertAuth) {
+		ipsecCSRSigningController = certificatesigningrequest.NewIPsecCSRSigningController(client,
+			certificate.GetCAConfigMapNamespace(),
+			informerFactory.Certificates().V1beta1().CertificateSigningRequests(),
+			nodeInformer,
+			podInformer)
+	}
+
+	// serviceExternalIPController allocates the ingress IPs of the LoadBalancer Services from the ExternalIPPools
+	// requested by them.
+	var serviceExternalIPController *serviceexternalip.ServiceExternalIPController
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		serviceExternalIPController = serviceexternalip.NewServiceExternalIPController(client,
+			crdClient,
+			serviceInformer,
+			crdInformerFactory.Core().V1alpha2().ExternalIPPools())
+	}
+
 	// statsAggregator takes stats summaries from antrea-agents, aggregates them, and serves the Stats APIs with the
 	// aggregated data. For now it's only used for NetworkPolicy stats.
 	var statsAggregator *stats.Aggregator
@@ -140,6 +171,19 @@ func run(o *Options) error {
 		statsAggregator = stats.NewAggregator(networkPolicyInformer, cnpInformer, anpInformer)
 	}
 
+	// elector elects the leader among the antrea-controller replicas when leader election is enabled. Only the
+	// leader is ready to serve the Antrea APIs and updates resources in the K8s API, while all replicas compute
+	// the internal NetworkPolicy objects.
+	var elector *leaderelection.Elector
+	var readyzChecks []healthz.HealthChecker
+	if o.config.EnableLeaderElection {
+		elector, err = leaderelection.NewElector(client, env.GetPodNamespace(), env.GetPodName())
+		if err != nil {
+			return err
+		}
+		readyzChecks = append(readyzChecks, elector)
+	}
+
 	apiServerConfig, err := createAPIServerConfig(o.config.ClientConnection.Kubeconfig,
 		client,
 		aggregatorClient,
@@ -150,10 +194,12 @@ func run(o *Options) error {
 		networkPolicyStore,
 		controllerQuerier,
 		endpointQuerier,
+		policyEvaluator,
 		networkPolicyController,
 		networkPolicyStatusController,
 		statsAggregator,
-		o.config.EnablePrometheusMetrics)
+		o.config.EnablePrometheusMetrics,
+		readyzChecks)
 	if err != nil {
 		return fmt.Errorf("error creating API server config: %v", err)
 	}
@@ -177,10 +223,28 @@ func run(o *Options) error {
 	informerFactory.Start(stopCh)
 	crdInformerFactory.Start(stopCh)
 
-	go controllerMonitor.Run(stopCh)
+	// runAsLeader runs the function right away if leader election is disabled, and once the replica becomes
+	// the leader otherwise.
+	runAsLeader := func(f func(stopCh <-chan struct{})) {
+		if elector == nil {
+			go f(stopCh)
+			return
+		}
+		elector.AddLeaderFunc(f)
+	}
+
+	runAsLeader(controllerMonitor.Run)
 
 	go networkPolicyController.Run(stopCh)
 
+	if elector == nil {
+		// Make sure the CA cert is published before the APIServer starts serving.
+		apiServer.PublishCACert()
+		go apiServer.WatchCACert(stopCh)
+	} else {
+		elector.AddLeaderFunc(apiServer.RunCACertController)
+	}
+
 	go apiServer.Run(stopCh)
 
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
@@ -192,11 +256,23 @@ func run(o *Options) error {
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
-		go traceflowController.Run(stopCh)
+		runAsLeader(traceflowController.Run)
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
-		go networkPolicyStatusController.Run(stopCh)
+		runAsLeader(networkPolicyStatusController.Run)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.IPsecCertAuth) {
+		runAsLeader(ipsecCSRSigningController.Run)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		runAsLeader(serviceExternalIPController.Run)
+	}
+
+	if elector != nil {
+		go elector.Run(stopCh)
 	}
 
 	<-stopCh
@@ -214,10 +290,12 @@ func createAPIServerConfig(kubeconfig string,
 	networkPolicyStore storage.Interface,
 	controllerQuerier querier.ControllerQuerier,
 	endpointQuerier networkpolicy.EndpointQuerier,
+	policyEvaluator networkpolicy.PolicyEvaluator,
 	npController *networkpolicy.NetworkPolicyController,
 	networkPolicyStatusController *networkpolicy.StatusController,
 	statsAggregator *stats.Aggregator,
-	enableMetrics bool) (*apiserver.Config, error) {
+	enableMetrics bool,
+	readyzChecks []healthz.HealthChecker) (*apiserver.Config, error) {
 	secureServing := genericoptions.NewSecureServingOptions().WithLoopback()
 	authentication := genericoptions.NewDelegatingAuthenticationOptions()
 	authorization := genericoptions.NewDelegatingAuthorizationOptions().WithAlwaysAllowPaths(allowedPaths...)
@@ -258,6 +336,8 @@ func createAPIServerConfig(kubeconfig string,
 	serverConfig.OpenAPIConfig.Info.Title = "Antrea"
 	serverConfig.EnableMetrics = enableMetrics
 	serverConfig.MinRequestTimeout = int(serverMinWatchTimeout.Seconds())
+	// The readyz-only checks don't fail /healthz and /livez, which only check whether the APIServer is healthy.
+	serverConfig.ReadyzChecks = append(serverConfig.ReadyzChecks, readyzChecks...)
 
 	return apiserver.NewConfig(
 		serverConfig,
@@ -269,5 +349,6 @@ func createAPIServerConfig(kubeconfig string,
 		controllerQuerier,
 		networkPolicyStatusController,
 		endpointQuerier,
+		policyEvaluator,
 		npController), nil
 }
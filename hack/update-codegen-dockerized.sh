@@ -99,7 +99,10 @@ MOCKGEN_TARGETS=(
   "pkg/agent/interfacestore InterfaceStore"
   "pkg/agent/openflow Client,OFEntryOperations"
   "pkg/agent/route Interface"
-  "pkg/ovs/openflow Bridge,Table,Flow,Action,CTAction,FlowBuilder"
+  "pkg/agent/wireguard Interface"
+  "pkg/agent/ipassigner IPAssigner"
+  "pkg/agent/nodeportlocal/rules PodPortRules"
+  "pkg/ovs/openflow Bridge,Table,Flow,Action,CTAction,FlowBuilder,Meter,MeterBandBuilder"
   "pkg/ovs/ovsconfig OVSBridgeClient"
   "pkg/ovs/ovsctl OVSCtlClient"
   "pkg/agent/querier AgentQuerier"
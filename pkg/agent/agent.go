@@ -16,15 +16,18 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/containernetworking/plugins/pkg/ip"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
@@ -38,6 +41,9 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/features"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	"github.com/vmware-tanzu/antrea/pkg/util/env"
 )
@@ -51,6 +57,8 @@ const (
 	roundNumKey             = "roundNum" // round number key in externalIDs.
 	initialRoundNum         = 1
 	maxRetryForRoundNumSave = 5
+	// maxRetryForGatewayIPsPublish is the number of tries to publish the gateway IPs in the Node annotation.
+	maxRetryForGatewayIPsPublish = 10
 )
 
 // Initializer knows how to setup host networking, OpenVSwitch, and Openflow.
@@ -67,6 +75,8 @@ type Initializer struct {
 	serviceCIDRv6   *net.IPNet // K8s Service ClusterIP CIDR in IPv6
 	networkConfig   *config.NetworkConfig
 	nodeConfig      *config.NodeConfig
+	wireGuardConfig *config.WireGuardConfig
+	wireGuardClient wireguard.Interface
 	enableProxy     bool
 	// networkReadyCh should be closed once the Node's network is ready.
 	// The CNI server will wait for it before handling any CNI Add requests.
@@ -85,6 +95,8 @@ func NewInitializer(
 	serviceCIDR *net.IPNet,
 	serviceCIDRv6 *net.IPNet,
 	networkConfig *config.NetworkConfig,
+	wireGuardConfig *config.WireGuardConfig,
+	wireGuardClient wireguard.Interface,
 	networkReadyCh chan<- struct{},
 	enableProxy bool) *Initializer {
 	return &Initializer{
@@ -99,6 +111,8 @@ func NewInitializer(
 		serviceCIDR:     serviceCIDR,
 		serviceCIDRv6:   serviceCIDRv6,
 		networkConfig:   networkConfig,
+		wireGuardConfig: wireGuardConfig,
+		wireGuardClient: wireGuardClient,
 		networkReadyCh:  networkReadyCh,
 		enableProxy:     enableProxy,
 	}
@@ -214,6 +228,10 @@ func (i *Initializer) Initialize() error {
 		return err
 	}
 
+	if err := i.initializeWireGuard(); err != nil {
+		return err
+	}
+
 	if err := i.prepareHostNetwork(); err != nil {
 		return err
 	}
@@ -222,6 +240,12 @@ func (i *Initializer) Initialize() error {
 		return err
 	}
 
+	// The gateway IPs are only consumed by the nodeSelector peers of Antrea-native policies. Failing to publish them
+	// must not prevent the agent from starting, so they are published in the background.
+	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
+		go i.publishGatewayIPsWithRetry()
+	}
+
 	wg.Add(1)
 	// routeClient.Initialize() should be after i.setupOVSBridge() which
 	// creates the host gateway interface.
@@ -282,7 +306,7 @@ func (i *Initializer) initOpenFlowPipeline() error {
 	roundInfo := getRoundInfo(i.ovsBridgeClient)
 
 	// Set up all basic flows.
-	ofConnCh, err := i.ofClient.Initialize(roundInfo, i.nodeConfig, i.networkConfig.TrafficEncapMode)
+	ofConnCh, err := i.ofClient.Initialize(roundInfo, i.nodeConfig, i.networkConfig)
 	if err != nil {
 		klog.Errorf("Failed to initialize openflow client: %v", err)
 		return err
@@ -535,7 +559,7 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 			tunnelPortName = defaultTunInterfaceName
 			i.nodeConfig.DefaultTunName = tunnelPortName
 		}
-		tunnelPortUUID, err := i.ovsBridgeClient.CreateTunnelPortExt(tunnelPortName, i.networkConfig.TunnelType, config.DefaultTunOFPort, shouldEnableCsum, localIPStr, "", "", nil)
+		tunnelPortUUID, err := i.ovsBridgeClient.CreateTunnelPortExt(tunnelPortName, i.networkConfig.TunnelType, config.DefaultTunOFPort, shouldEnableCsum, localIPStr, "", "", "", nil)
 		if err != nil {
 			klog.Errorf("Failed to create tunnel port %s type %s on OVS bridge: %v", tunnelPortName, i.networkConfig.TunnelType, err)
 			return err
@@ -646,9 +670,11 @@ func (i *Initializer) initNodeLocalConfig() error {
 	return nil
 }
 
-// initializeIPSec checks if preconditions are met for using IPsec and reads the IPsec PSK value.
+// initializeIPSec checks if preconditions are met for using IPsec and reads the IPsec PSK value if PSK
+// authentication is used. With certificate authentication, the certificate is maintained by the IPsec
+// certificate controller instead.
 func (i *Initializer) initializeIPSec() error {
-	if !i.networkConfig.EnableIPSecTunnel {
+	if i.networkConfig.TrafficEncryptionMode != config.TrafficEncryptionModeIPSec {
 		return nil
 	}
 
@@ -675,6 +701,9 @@ func (i *Initializer) initializeIPSec() error {
 		}
 	}
 
+	if i.networkConfig.IPsecAuthenticationMode == config.IPsecAuthenticationModeCert {
+		return nil
+	}
 	if err := i.readIPSecPSK(); err != nil {
 		return err
 	}
@@ -693,6 +722,85 @@ func (i *Initializer) readIPSecPSK() error {
 	return nil
 }
 
+// initializeWireGuard sets up the WireGuard device and publishes its public key
+// in the Node annotation, from which the other Nodes configure their peers.
+func (i *Initializer) initializeWireGuard() error {
+	if i.networkConfig.TrafficEncryptionMode != config.TrafficEncryptionModeWireGuard {
+		return nil
+	}
+	// The Pod MTU already accounts for the WireGuard overhead.
+	i.wireGuardConfig.MTU = i.nodeConfig.NodeMTU
+	publicKey, err := i.wireGuardClient.Init()
+	if err != nil {
+		return fmt.Errorf("error when initializing WireGuard: %v", err)
+	}
+	i.nodeConfig.WireGuardConfig = i.wireGuardConfig
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				wireguard.NodeWireGuardPublicKeyAnnotationKey: publicKey,
+			},
+		},
+	})
+	if _, err := i.client.CoreV1().Nodes().Patch(context.TODO(), i.nodeConfig.Name, apimachinerytypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error when publishing WireGuard public key of Node %s: %v", i.nodeConfig.Name, err)
+	}
+	klog.Infof("Initialized WireGuard device %s with public key %s", i.wireGuardConfig.Name, publicKey)
+	return nil
+}
+
+// publishGatewayIPsWithRetry calls publishGatewayIPs until it succeeds or the
+// retries are exhausted, in which case antrea-controller keeps deriving the
+// gateway IPs of the Node from its PodCIDRs.
+func (i *Initializer) publishGatewayIPsWithRetry() {
+	backoff := wait.Backoff{Duration: 1 * time.Second, Factor: 2, Steps: maxRetryForGatewayIPsPublish, Cap: 2 * time.Minute}
+	if err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		if err := i.publishGatewayIPs(); err != nil {
+			klog.Warningf("Failed to publish gateway IPs of Node %s, will retry: %v", i.nodeConfig.Name, err)
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		klog.Errorf("Failed to publish gateway IPs of Node %s after %d tries", i.nodeConfig.Name, maxRetryForGatewayIPsPublish)
+	}
+}
+
+// publishGatewayIPs publishes the IPs of the host gateway interface in the Node
+// annotation, from which antrea-controller includes them in the addresses of
+// the Node matched by the nodeSelector peers of policy rules. The Node is not
+// patched if the annotation already holds the IPs.
+func (i *Initializer) publishGatewayIPs() error {
+	var gatewayIPs []string
+	for _, gatewayIP := range []net.IP{i.nodeConfig.GatewayConfig.IPv4, i.nodeConfig.GatewayConfig.IPv6} {
+		if gatewayIP != nil {
+			gatewayIPs = append(gatewayIPs, gatewayIP.String())
+		}
+	}
+	if len(gatewayIPs) == 0 {
+		return nil
+	}
+	value := strings.Join(gatewayIPs, ",")
+	node, err := i.client.CoreV1().Nodes().Get(context.TODO(), i.nodeConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error when getting Node %s: %v", i.nodeConfig.Name, err)
+	}
+	if node.Annotations[apis.NodeGatewayIPsAnnotationKey] == value {
+		return nil
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				apis.NodeGatewayIPsAnnotationKey: value,
+			},
+		},
+	})
+	if _, err := i.client.CoreV1().Nodes().Patch(context.TODO(), i.nodeConfig.Name, apimachinerytypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error when publishing gateway IPs of Node %s: %v", i.nodeConfig.Name, err)
+	}
+	return nil
+}
+
 func getLastRoundNum(bridgeClient ovsconfig.OVSBridgeClient) (uint64, error) {
 	extIDs, ovsCfgErr := bridgeClient.GetExternalIDs()
 	if ovsCfgErr != nil {
@@ -753,7 +861,14 @@ func (i *Initializer) getNodeMTU(localIntf *net.Interface) (int, error) {
 	if mtu <= 0 {
 		return 0, fmt.Errorf("Failed to fetch Node MTU : %v", mtu)
 	}
-	if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+	if i.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		// With WireGuard, the inter-Node Pod traffic is sent through the
+		// WireGuard device instead of the OVS tunnel, in all modes.
+		mtu -= config.WireGuardOverhead
+		if i.nodeConfig.NodeIPAddr.IP.To4() == nil {
+			mtu -= config.IPv6ExtraOverhead
+		}
+	} else if i.networkConfig.TrafficEncapMode.SupportsEncap() {
 		if i.networkConfig.TunnelType == ovsconfig.VXLANTunnel {
 			mtu -= config.VXLANOverhead
 		} else if i.networkConfig.TunnelType == ovsconfig.GeneveTunnel {
@@ -765,7 +880,7 @@ func (i *Initializer) getNodeMTU(localIntf *net.Interface) (int, error) {
 			mtu -= config.IPv6ExtraOverhead
 		}
 	}
-	if i.networkConfig.EnableIPSecTunnel {
+	if i.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		mtu -= config.IPSecESPOverhead
 	}
 	return mtu, nil
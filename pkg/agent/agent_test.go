@@ -15,6 +15,7 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"testing"
@@ -22,10 +23,15 @@ import (
 	mock "github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/cniserver"
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
+	"github.com/vmware-tanzu/antrea/pkg/apis"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	ovsconfigtest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig/testing"
 )
@@ -134,3 +140,25 @@ func TestGetRoundInfo(t *testing.T) {
 	roundInfo = getRoundInfo(mockOVSBridgeClient)
 	assert.Equal(t, uint64(initialRoundNum), roundInfo.RoundNum, "Unexpected round number")
 }
+
+func TestPublishGatewayIPs(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+	initializer := &Initializer{
+		client: client,
+		nodeConfig: &config.NodeConfig{
+			Name:          "node1",
+			GatewayConfig: &config.GatewayConfig{IPv4: net.ParseIP("10.10.0.1"), IPv6: net.ParseIP("fd00:10:10::1")},
+		},
+	}
+	require.NoError(t, initializer.publishGatewayIPs())
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.0.1,fd00:10:10::1", node.Annotations[apis.NodeGatewayIPsAnnotationKey])
+
+	// The Node is not patched again if the annotation already holds the gateway IPs.
+	client.ClearActions()
+	require.NoError(t, initializer.publishGatewayIPs())
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "patch", action.GetVerb())
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// The encoding of BGP-4 messages, see RFC 4271. Only the subset needed by an
+// advertise-only speaker is implemented: the attributes of the received UPDATE
+// messages are never decoded.
+
+const (
+	bgpVersion = 4
+
+	headerLen     = 19
+	maxMessageLen = 4096
+
+	msgTypeOpen         uint8 = 1
+	msgTypeUpdate       uint8 = 2
+	msgTypeNotification uint8 = 3
+	msgTypeKeepalive    uint8 = 4
+
+	optParamCapabilities uint8 = 2
+
+	capMultiprotocol uint8 = 1
+	capFourOctetASN  uint8 = 65
+
+	afiIPv4     uint16 = 1
+	afiIPv6     uint16 = 2
+	safiUnicast uint8  = 1
+
+	attrFlagOptional    uint8 = 0x80
+	attrFlagTransitive  uint8 = 0x40
+	attrFlagExtendedLen uint8 = 0x10
+
+	attrTypeOrigin        uint8 = 1
+	attrTypeASPath        uint8 = 2
+	attrTypeNextHop       uint8 = 3
+	attrTypeLocalPref     uint8 = 5
+	attrTypeMPReachNLRI   uint8 = 14
+	attrTypeMPUnreachNLRI uint8 = 15
+	attrTypeAS4Path       uint8 = 17
+
+	originIGP         uint8 = 0
+	asPathSegSequence uint8 = 2
+	defaultLocalPref        = 100
+
+	// asTrans is the AS number used in place of a 4-octet AS number with the
+	// peers which do not support them, see RFC 6793.
+	asTrans = 23456
+
+	notifCodeOpenMessageError uint8 = 2
+	notifCodeHoldTimerExpired uint8 = 4
+	notifCodeFSMError         uint8 = 5
+	notifCodeCease            uint8 = 6
+	notifSubcodeBadPeerAS     uint8 = 2
+	notifSubcodeBadHoldTime   uint8 = 6
+	notifSubcodeAdminShutdown uint8 = 2
+	notifSubcodeUnspecific    uint8 = 0
+)
+
+// openMessage is a decoded OPEN message.
+type openMessage struct {
+	// asn is the AS number of the sender, taken from the 4-octet AS number
+	// capability when it is present.
+	asn      uint32
+	holdTime uint16
+	routerID net.IP
+	// fourOctetASN is true if the sender supports 4-octet AS numbers.
+	fourOctetASN bool
+}
+
+func encodeMessage(msgType uint8, body []byte) []byte {
+	msg := make([]byte, headerLen+len(body))
+	for i := 0; i < 16; i++ {
+		msg[i] = 0xff
+	}
+	binary.BigEndian.PutUint16(msg[16:18], uint16(len(msg)))
+	msg[18] = msgType
+	copy(msg[headerLen:], body)
+	return msg
+}
+
+// readMessage reads a BGP message and returns its type and body.
+func readMessage(r io.Reader) (uint8, []byte, error) {
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	for i := 0; i < 16; i++ {
+		if header[i] != 0xff {
+			return 0, nil, fmt.Errorf("invalid message marker")
+		}
+	}
+	length := int(binary.BigEndian.Uint16(header[16:18]))
+	if length < headerLen || length > maxMessageLen {
+		return 0, nil, fmt.Errorf("invalid message length %d", length)
+	}
+	body := make([]byte, length-headerLen)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[18], body, nil
+}
+
+func encodeOpen(asn uint32, holdTime uint16, routerID net.IP, isIPv6 bool) []byte {
+	afi := afiIPv4
+	if isIPv6 {
+		afi = afiIPv6
+	}
+	capabilities := []byte{
+		capMultiprotocol, 4, byte(afi >> 8), byte(afi), 0, safiUnicast,
+		capFourOctetASN, 4, 0, 0, 0, 0,
+	}
+	binary.BigEndian.PutUint32(capabilities[8:12], asn)
+	myAS := uint16(asn)
+	if asn > 0xffff {
+		myAS = asTrans
+	}
+
+	body := make([]byte, 10, 10+2+len(capabilities))
+	body[0] = bgpVersion
+	binary.BigEndian.PutUint16(body[1:3], myAS)
+	binary.BigEndian.PutUint16(body[3:5], holdTime)
+	copy(body[5:9], routerID.To4())
+	body[9] = byte(2 + len(capabilities))
+	body = append(body, optParamCapabilities, byte(len(capabilities)))
+	body = append(body, capabilities...)
+	return encodeMessage(msgTypeOpen, body)
+}
+
+func decodeOpen(body []byte) (*openMessage, error) {
+	if len(body) < 10 {
+		return nil, fmt.Errorf("OPEN message too short")
+	}
+	if body[0] != bgpVersion {
+		return nil, fmt.Errorf("unsupported BGP version %d", body[0])
+	}
+	open := &openMessage{
+		asn:      uint32(binary.BigEndian.Uint16(body[1:3])),
+		holdTime: binary.BigEndian.Uint16(body[3:5]),
+		routerID: net.IP(append([]byte(nil), body[5:9]...)),
+	}
+	params := body[10:]
+	if int(body[9]) != len(params) {
+		return nil, fmt.Errorf("invalid optional parameters length %d", body[9])
+	}
+	for len(params) > 0 {
+		if len(params) < 2 || len(params) < 2+int(params[1]) {
+			return nil, fmt.Errorf("truncated optional parameter")
+		}
+		paramType, value := params[0], params[2:2+int(params[1])]
+		params = params[2+int(params[1]):]
+		if paramType != optParamCapabilities {
+			continue
+		}
+		for len(value) > 0 {
+			if len(value) < 2 || len(value) < 2+int(value[1]) {
+				return nil, fmt.Errorf("truncated capability")
+			}
+			capCode, capValue := value[0], value[2:2+int(value[1])]
+			value = value[2+int(value[1]):]
+			if capCode == capFourOctetASN && len(capValue) == 4 {
+				open.fourOctetASN = true
+				open.asn = binary.BigEndian.Uint32(capValue)
+			}
+		}
+	}
+	return open, nil
+}
+
+func encodeKeepalive() []byte {
+	return encodeMessage(msgTypeKeepalive, nil)
+}
+
+func encodeNotification(code, subcode uint8) []byte {
+	return encodeMessage(msgTypeNotification, []byte{code, subcode})
+}
+
+// updateEncoder encodes the UPDATE messages sent to a peer.
+type updateEncoder struct {
+	localASN uint32
+	// ebgp is true if the peer is in a different AS.
+	ebgp bool
+	// fourOctetASN is true if 4-octet AS numbers are supported by both sides.
+	fourOctetASN bool
+	// nextHop is the local address of the session, which is of the same IP
+	// family as the advertised prefixes.
+	nextHop net.IP
+}
+
+func (e *updateEncoder) isIPv6() bool {
+	return e.nextHop.To4() == nil
+}
+
+// encodeUpdates returns the UPDATE messages which announce and withdraw the
+// provided prefixes. The prefixes are split into as many messages as needed
+// to respect the maximum message length.
+func (e *updateEncoder) encodeUpdates(announced, withdrawn []net.IPNet) [][]byte {
+	var msgs [][]byte
+	for _, nlri := range splitPrefixes(withdrawn) {
+		if e.isIPv6() {
+			msgs = append(msgs, e.encodeUpdate(nil, encodeAttribute(attrFlagOptional, attrTypeMPUnreachNLRI, mpNLRI(afiIPv6, nil, nlri)), nil))
+		} else {
+			msgs = append(msgs, e.encodeUpdate(nlri, nil, nil))
+		}
+	}
+	for _, nlri := range splitPrefixes(announced) {
+		attrs := e.commonAttributes()
+		if e.isIPv6() {
+			attrs = append(attrs, encodeAttribute(attrFlagOptional, attrTypeMPReachNLRI, mpNLRI(afiIPv6, e.nextHop.To16(), nlri))...)
+			msgs = append(msgs, e.encodeUpdate(nil, attrs, nil))
+		} else {
+			attrs = append(attrs, encodeAttribute(attrFlagTransitive, attrTypeNextHop, e.nextHop.To4())...)
+			msgs = append(msgs, e.encodeUpdate(nil, attrs, nlri))
+		}
+	}
+	return msgs
+}
+
+func (e *updateEncoder) encodeUpdate(withdrawn, attrs, nlri []byte) []byte {
+	body := make([]byte, 0, 4+len(withdrawn)+len(attrs)+len(nlri))
+	body = append(body, byte(len(withdrawn)>>8), byte(len(withdrawn)))
+	body = append(body, withdrawn...)
+	body = append(body, byte(len(attrs)>>8), byte(len(attrs)))
+	body = append(body, attrs...)
+	body = append(body, nlri...)
+	return encodeMessage(msgTypeUpdate, body)
+}
+
+// commonAttributes returns the ORIGIN, AS_PATH and, for iBGP, LOCAL_PREF path
+// attributes. The speaker originates all the routes it advertises. A 4-octet
+// local AS number is sent to an eBGP peer which does not support them as
+// AS_TRANS in the AS_PATH, and in an AS4_PATH attribute, see RFC 6793.
+func (e *updateEncoder) commonAttributes() []byte {
+	attrs := encodeAttribute(attrFlagTransitive, attrTypeOrigin, []byte{originIGP})
+	var asPath, as4Path []byte
+	if e.ebgp {
+		if e.fourOctetASN {
+			asPath = []byte{asPathSegSequence, 1, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(asPath[2:], e.localASN)
+		} else if e.localASN > 0xffff {
+			asPath = []byte{asPathSegSequence, 1, 0, 0}
+			binary.BigEndian.PutUint16(asPath[2:], asTrans)
+			as4Path = []byte{asPathSegSequence, 1, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(as4Path[2:], e.localASN)
+		} else {
+			asPath = []byte{asPathSegSequence, 1, 0, 0}
+			binary.BigEndian.PutUint16(asPath[2:], uint16(e.localASN))
+		}
+	}
+	attrs = append(attrs, encodeAttribute(attrFlagTransitive, attrTypeASPath, asPath)...)
+	if as4Path != nil {
+		attrs = append(attrs, encodeAttribute(attrFlagOptional|attrFlagTransitive, attrTypeAS4Path, as4Path)...)
+	}
+	if !e.ebgp {
+		localPref := make([]byte, 4)
+		binary.BigEndian.PutUint32(localPref, defaultLocalPref)
+		attrs = append(attrs, encodeAttribute(attrFlagTransitive, attrTypeLocalPref, localPref)...)
+	}
+	return attrs
+}
+
+func encodeAttribute(flags, attrType uint8, value []byte) []byte {
+	if len(value) > 0xff {
+		attr := []byte{flags | attrFlagExtendedLen, attrType, byte(len(value) >> 8), byte(len(value))}
+		return append(attr, value...)
+	}
+	attr := []byte{flags, attrType, byte(len(value))}
+	return append(attr, value...)
+}
+
+// mpNLRI encodes the value of a MP_REACH_NLRI attribute if nextHop is not nil,
+// or of a MP_UNREACH_NLRI attribute otherwise, see RFC 4760.
+func mpNLRI(afi uint16, nextHop net.IP, nlri []byte) []byte {
+	value := []byte{byte(afi >> 8), byte(afi), safiUnicast}
+	if nextHop != nil {
+		value = append(value, byte(len(nextHop)))
+		value = append(value, nextHop...)
+		// Reserved byte.
+		value = append(value, 0)
+	}
+	return append(value, nlri...)
+}
+
+func encodePrefix(prefix net.IPNet) []byte {
+	ones, _ := prefix.Mask.Size()
+	ip := prefix.IP.To4()
+	if ip == nil {
+		ip = prefix.IP.To16()
+	}
+	return append([]byte{byte(ones)}, ip[:(ones+7)/8]...)
+}
+
+// maxNLRILen is the maximum length of the encoded prefixes in an UPDATE
+// message, which leaves enough room for the header and the path attributes.
+const maxNLRILen = maxMessageLen - 256
+
+// splitPrefixes encodes the prefixes into chunks of at most maxNLRILen bytes.
+func splitPrefixes(prefixes []net.IPNet) [][]byte {
+	var chunks [][]byte
+	var chunk []byte
+	for _, prefix := range prefixes {
+		encoded := encodePrefix(prefix)
+		if len(chunk)+len(encoded) > maxNLRILen {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+		chunk = append(chunk, encoded...)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
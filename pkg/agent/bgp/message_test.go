@@ -0,0 +1,238 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodedUpdate is the content of an UPDATE message, used to verify the
+// messages sent by the speaker.
+type decodedUpdate struct {
+	withdrawn []string
+	announced []string
+	// attrs maps the type of the path attributes to their value.
+	attrs map[uint8][]byte
+}
+
+func decodePrefixes(t *testing.T, data []byte, ipLen int) []string {
+	var prefixes []string
+	for len(data) > 0 {
+		ones := int(data[0])
+		n := (ones + 7) / 8
+		require.GreaterOrEqual(t, len(data), 1+n)
+		ip := make(net.IP, ipLen)
+		copy(ip, data[1:1+n])
+		prefix := net.IPNet{IP: ip, Mask: net.CIDRMask(ones, ipLen*8)}
+		prefixes = append(prefixes, prefix.String())
+		data = data[1+n:]
+	}
+	return prefixes
+}
+
+func decodeUpdate(t *testing.T, body []byte) *decodedUpdate {
+	update := &decodedUpdate{attrs: map[uint8][]byte{}}
+	withdrawnLen := int(binary.BigEndian.Uint16(body[0:2]))
+	update.withdrawn = decodePrefixes(t, body[2:2+withdrawnLen], net.IPv4len)
+	body = body[2+withdrawnLen:]
+	attrsLen := int(binary.BigEndian.Uint16(body[0:2]))
+	attrs := body[2 : 2+attrsLen]
+	update.announced = decodePrefixes(t, body[2+attrsLen:], net.IPv4len)
+	for len(attrs) > 0 {
+		flags, attrType := attrs[0], attrs[1]
+		var length, offset int
+		if flags&attrFlagExtendedLen != 0 {
+			length, offset = int(binary.BigEndian.Uint16(attrs[2:4])), 4
+		} else {
+			length, offset = int(attrs[2]), 3
+		}
+		value := attrs[offset : offset+length]
+		attrs = attrs[offset+length:]
+		update.attrs[attrType] = value
+		switch attrType {
+		case attrTypeMPReachNLRI:
+			nextHopLen := int(value[3])
+			update.announced = append(update.announced, decodePrefixes(t, value[4+nextHopLen+1:], net.IPv6len)...)
+		case attrTypeMPUnreachNLRI:
+			update.withdrawn = append(update.withdrawn, decodePrefixes(t, value[3:], net.IPv6len)...)
+		}
+	}
+	return update
+}
+
+func mustParseCIDRs(cidrs ...string) []net.IPNet {
+	var prefixes []net.IPNet
+	for _, cidr := range cidrs {
+		_, prefix, _ := net.ParseCIDR(cidr)
+		prefixes = append(prefixes, *prefix)
+	}
+	return prefixes
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name         string
+		asn          uint32
+		expectedMyAS uint16
+	}{
+		{name: "2-octet ASN", asn: 65000, expectedMyAS: 65000},
+		{name: "4-octet ASN", asn: 4200000000, expectedMyAS: asTrans},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := encodeOpen(tt.asn, 90, net.ParseIP("10.0.0.1"), false)
+			msgType, body, err := readMessage(bytes.NewReader(msg))
+			require.NoError(t, err)
+			assert.Equal(t, msgTypeOpen, msgType)
+			assert.Equal(t, tt.expectedMyAS, binary.BigEndian.Uint16(body[1:3]))
+			open, err := decodeOpen(body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.asn, open.asn)
+			assert.Equal(t, uint16(90), open.holdTime)
+			assert.Equal(t, "10.0.0.1", open.routerID.String())
+			assert.True(t, open.fourOctetASN)
+		})
+	}
+}
+
+func TestReadMessageInvalid(t *testing.T) {
+	msg := encodeKeepalive()
+	msg[0] = 0
+	_, _, err := readMessage(bytes.NewReader(msg))
+	assert.Error(t, err)
+
+	msg = encodeKeepalive()
+	binary.BigEndian.PutUint16(msg[16:18], maxMessageLen+1)
+	_, _, err = readMessage(bytes.NewReader(msg))
+	assert.Error(t, err)
+}
+
+func TestEncodeUpdates(t *testing.T) {
+	tests := []struct {
+		name              string
+		encoder           *updateEncoder
+		announced         []net.IPNet
+		withdrawn         []net.IPNet
+		expectedAnnounced []string
+		expectedWithdrawn []string
+		expectedASPath    []byte
+		expectedAS4Path   []byte
+		expectedNextHop   []byte
+		expectLocalPref   bool
+	}{
+		{
+			name:              "IPv4 eBGP",
+			encoder:           &updateEncoder{localASN: 65000, ebgp: true, fourOctetASN: true, nextHop: net.ParseIP("192.168.0.2")},
+			announced:         mustParseCIDRs("10.10.0.0/24", "172.16.1.10/32"),
+			withdrawn:         mustParseCIDRs("10.10.1.0/24"),
+			expectedAnnounced: []string{"10.10.0.0/24", "172.16.1.10/32"},
+			expectedWithdrawn: []string{"10.10.1.0/24"},
+			expectedASPath:    []byte{asPathSegSequence, 1, 0, 0, 0xfd, 0xe8},
+			expectedNextHop:   []byte{192, 168, 0, 2},
+		},
+		{
+			name:              "IPv4 eBGP 2-octet ASN",
+			encoder:           &updateEncoder{localASN: 65000, ebgp: true, nextHop: net.ParseIP("192.168.0.2")},
+			announced:         mustParseCIDRs("10.10.0.0/24"),
+			expectedAnnounced: []string{"10.10.0.0/24"},
+			expectedASPath:    []byte{asPathSegSequence, 1, 0xfd, 0xe8},
+			expectedNextHop:   []byte{192, 168, 0, 2},
+		},
+		{
+			name:              "IPv4 eBGP 4-octet local ASN with 2-octet peer",
+			encoder:           &updateEncoder{localASN: 4200000000, ebgp: true, nextHop: net.ParseIP("192.168.0.2")},
+			announced:         mustParseCIDRs("10.10.0.0/24"),
+			expectedAnnounced: []string{"10.10.0.0/24"},
+			expectedASPath:    []byte{asPathSegSequence, 1, 0x5b, 0xa0},
+			expectedAS4Path:   []byte{asPathSegSequence, 1, 0xfa, 0x56, 0xea, 0x00},
+			expectedNextHop:   []byte{192, 168, 0, 2},
+		},
+		{
+			name:              "IPv4 iBGP",
+			encoder:           &updateEncoder{localASN: 65000, nextHop: net.ParseIP("192.168.0.2")},
+			announced:         mustParseCIDRs("10.10.0.0/24"),
+			expectedAnnounced: []string{"10.10.0.0/24"},
+			expectedASPath:    []byte{},
+			expectedNextHop:   []byte{192, 168, 0, 2},
+			expectLocalPref:   true,
+		},
+		{
+			name:              "IPv6 eBGP",
+			encoder:           &updateEncoder{localASN: 65000, ebgp: true, fourOctetASN: true, nextHop: net.ParseIP("fd00::2")},
+			announced:         mustParseCIDRs("fd00:10:10::/64"),
+			withdrawn:         mustParseCIDRs("fd00:10:11::/64"),
+			expectedAnnounced: []string{"fd00:10:10::/64"},
+			expectedWithdrawn: []string{"fd00:10:11::/64"},
+			expectedASPath:    []byte{asPathSegSequence, 1, 0, 0, 0xfd, 0xe8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var announced, withdrawn []string
+			for _, msg := range tt.encoder.encodeUpdates(tt.announced, tt.withdrawn) {
+				msgType, body, err := readMessage(bytes.NewReader(msg))
+				require.NoError(t, err)
+				require.Equal(t, msgTypeUpdate, msgType)
+				update := decodeUpdate(t, body)
+				announced = append(announced, update.announced...)
+				withdrawn = append(withdrawn, update.withdrawn...)
+				if len(update.announced) == 0 {
+					continue
+				}
+				assert.Equal(t, []byte{originIGP}, update.attrs[attrTypeOrigin])
+				assert.Equal(t, tt.expectedASPath, update.attrs[attrTypeASPath])
+				assert.Equal(t, tt.expectedAS4Path, update.attrs[attrTypeAS4Path])
+				_, hasLocalPref := update.attrs[attrTypeLocalPref]
+				assert.Equal(t, tt.expectLocalPref, hasLocalPref)
+				if tt.expectedNextHop != nil {
+					assert.Equal(t, tt.expectedNextHop, update.attrs[attrTypeNextHop])
+				} else {
+					mpReach := update.attrs[attrTypeMPReachNLRI]
+					assert.Equal(t, []byte(tt.encoder.nextHop.To16()), mpReach[4:4+net.IPv6len])
+				}
+			}
+			assert.Equal(t, tt.expectedAnnounced, announced)
+			assert.Equal(t, tt.expectedWithdrawn, withdrawn)
+		})
+	}
+}
+
+func TestSplitPrefixes(t *testing.T) {
+	var prefixes []net.IPNet
+	var expected []string
+	for i := 0; i < 2000; i++ {
+		cidr := fmt.Sprintf("10.%d.%d.0/24", i/256, i%256)
+		prefixes = append(prefixes, mustParseCIDRs(cidr)...)
+		expected = append(expected, cidr)
+	}
+	encoder := &updateEncoder{localASN: 65000, ebgp: true, fourOctetASN: true, nextHop: net.ParseIP("192.168.0.2")}
+	msgs := encoder.encodeUpdates(prefixes, nil)
+	assert.Greater(t, len(msgs), 1)
+	var announced []string
+	for _, msg := range msgs {
+		assert.LessOrEqual(t, len(msg), maxMessageLen)
+		_, body, err := readMessage(bytes.NewReader(msg))
+		require.NoError(t, err)
+		announced = append(announced, decodeUpdate(t, body).announced...)
+	}
+	assert.Equal(t, expected, announced)
+}
@@ -0,0 +1,344 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// SessionState is the state of the BGP session with a peer. Only the states
+// which are relevant to a speaker initiating the connections are used.
+type SessionState string
+
+const (
+	SessionStateIdle        SessionState = "Idle"
+	SessionStateConnect     SessionState = "Connect"
+	SessionStateOpenSent    SessionState = "OpenSent"
+	SessionStateOpenConfirm SessionState = "OpenConfirm"
+	SessionStateEstablished SessionState = "Established"
+)
+
+// Declared as variables for testing.
+var (
+	connectTimeout       = 10 * time.Second
+	connectRetryInterval = 5 * time.Second
+)
+
+// session maintains the BGP session with a peer, and advertises the prefixes
+// of the same IP family as the peer address on it. It reconnects after any
+// error until it is stopped.
+type session struct {
+	peer     PeerConfig
+	localASN uint32
+	routerID net.IP
+
+	mutex    sync.RWMutex
+	state    SessionState
+	prefixes map[string]net.IPNet
+
+	// updateCh notifies the established session that the prefixes changed.
+	updateCh chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newSession(peer PeerConfig, localASN uint32, routerID net.IP, prefixes []net.IPNet) *session {
+	s := &session{
+		peer:     peer,
+		localASN: localASN,
+		routerID: routerID,
+		state:    SessionStateIdle,
+		updateCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	s.setPrefixes(prefixes)
+	return s
+}
+
+func (s *session) isIPv6() bool {
+	return s.peer.Address.To4() == nil
+}
+
+func (s *session) getState() SessionState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.state
+}
+
+func (s *session) setState(state SessionState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.state != state {
+		klog.V(2).Infof("BGP session with peer %s moved from %s to %s", s.peer.Address, s.state, state)
+		s.state = state
+	}
+}
+
+// setPrefixes sets the prefixes advertised to the peer, ignoring the ones of
+// the other IP family.
+func (s *session) setPrefixes(prefixes []net.IPNet) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.prefixes = make(map[string]net.IPNet, len(prefixes))
+	for _, prefix := range prefixes {
+		if (prefix.IP.To4() == nil) == s.isIPv6() {
+			s.prefixes[prefix.String()] = prefix
+		}
+	}
+	select {
+	case s.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *session) getPrefixes() map[string]net.IPNet {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	prefixes := make(map[string]net.IPNet, len(s.prefixes))
+	for k, v := range s.prefixes {
+		prefixes[k] = v
+	}
+	return prefixes
+}
+
+func (s *session) run() {
+	defer close(s.doneCh)
+	for {
+		if err := s.connectAndServe(); err != nil {
+			klog.Errorf("BGP session with peer %s failed: %v", s.peer.Address, err)
+		}
+		s.setState(SessionStateIdle)
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(connectRetryInterval):
+		}
+	}
+}
+
+// stop closes the session gracefully and waits for it to terminate.
+func (s *session) stop() {
+	close(s.stopCh)
+	<-s.doneCh
+}
+
+func (s *session) connectAndServe() error {
+	s.setState(SessionStateConnect)
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.peer.Address.String(), strconv.Itoa(int(s.peer.Port))))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Close the session with a Cease NOTIFICATION when it is stopped, which
+	// also unblocks the pending reads.
+	closedCh := make(chan struct{})
+	stoppedCh := make(chan struct{})
+	defer close(closedCh)
+	go func() {
+		defer close(stoppedCh)
+		select {
+		case <-s.stopCh:
+			sendNotification(conn, notifCodeCease, notifSubcodeAdminShutdown)
+			conn.Close()
+		case <-closedCh:
+		}
+	}()
+
+	holdTime := s.peer.HoldTime
+	if _, err := conn.Write(encodeOpen(s.localASN, uint16(holdTime/time.Second), s.routerID, s.isIPv6())); err != nil {
+		return err
+	}
+	s.setState(SessionStateOpenSent)
+	if err := conn.SetReadDeadline(time.Now().Add(holdTime)); err != nil {
+		return err
+	}
+	msgType, body, err := readMessage(conn)
+	if err != nil {
+		return err
+	}
+	if msgType != msgTypeOpen {
+		sendNotification(conn, notifCodeFSMError, notifSubcodeUnspecific)
+		return unexpectedMessageError(msgType, body)
+	}
+	open, err := decodeOpen(body)
+	if err != nil {
+		sendNotification(conn, notifCodeOpenMessageError, notifSubcodeUnspecific)
+		return err
+	}
+	if open.asn != s.peer.ASN {
+		sendNotification(conn, notifCodeOpenMessageError, notifSubcodeBadPeerAS)
+		return fmt.Errorf("peer AS number %d does not match the expected %d", open.asn, s.peer.ASN)
+	}
+	// A hold time of 1 or 2 seconds must be rejected, see RFC 4271.
+	if open.holdTime == 1 || open.holdTime == 2 {
+		sendNotification(conn, notifCodeOpenMessageError, notifSubcodeBadHoldTime)
+		return fmt.Errorf("peer hold time %ds is unacceptable", open.holdTime)
+	}
+	// The hold time is the smaller of the proposed ones, and 0 disables the
+	// keepalives.
+	if peerHoldTime := time.Duration(open.holdTime) * time.Second; peerHoldTime < holdTime {
+		holdTime = peerHoldTime
+	}
+	if _, err := conn.Write(encodeKeepalive()); err != nil {
+		return err
+	}
+	s.setState(SessionStateOpenConfirm)
+	msgType, body, err = readMessage(conn)
+	if err != nil {
+		return err
+	}
+	if msgType != msgTypeKeepalive {
+		return unexpectedMessageError(msgType, body)
+	}
+	s.setState(SessionStateEstablished)
+
+	// Reading from the connection is done in a separate goroutine, which
+	// enforces the hold time with read deadlines.
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			deadline := time.Time{}
+			if holdTime > 0 {
+				deadline = time.Now().Add(holdTime)
+			}
+			if err := conn.SetReadDeadline(deadline); err != nil {
+				errCh <- err
+				return
+			}
+			msgType, body, err := readMessage(conn)
+			if err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					sendNotification(conn, notifCodeHoldTimerExpired, notifSubcodeUnspecific)
+					err = fmt.Errorf("hold timer expired")
+				}
+				errCh <- err
+				return
+			}
+			// The routes received in UPDATE messages are ignored.
+			if msgType == msgTypeNotification {
+				errCh <- unexpectedMessageError(msgType, body)
+				return
+			}
+		}
+	}()
+
+	var keepaliveCh <-chan time.Time
+	if holdTime > 0 {
+		ticker := time.NewTicker(holdTime / 3)
+		defer ticker.Stop()
+		keepaliveCh = ticker.C
+	}
+	encoder := &updateEncoder{
+		localASN:     s.localASN,
+		ebgp:         s.peer.ASN != s.localASN,
+		fourOctetASN: open.fourOctetASN,
+		nextHop:      conn.LocalAddr().(*net.TCPAddr).IP,
+	}
+	advertised := map[string]net.IPNet{}
+	if err := s.syncPrefixes(conn, encoder, advertised); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-s.stopCh:
+			// Wait for the NOTIFICATION to be sent before closing the
+			// connection.
+			<-stoppedCh
+			return nil
+		case err := <-errCh:
+			return err
+		case <-keepaliveCh:
+			if _, err := conn.Write(encodeKeepalive()); err != nil {
+				return err
+			}
+		case <-s.updateCh:
+			if err := s.syncPrefixes(conn, encoder, advertised); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// syncPrefixes announces the desired prefixes which have not been advertised
+// yet, and withdraws the advertised prefixes which are no longer desired.
+func (s *session) syncPrefixes(conn net.Conn, encoder *updateEncoder, advertised map[string]net.IPNet) error {
+	desired := s.getPrefixes()
+	var announced, withdrawn []net.IPNet
+	for key, prefix := range desired {
+		if _, exists := advertised[key]; !exists {
+			announced = append(announced, prefix)
+		}
+	}
+	for key, prefix := range advertised {
+		if _, exists := desired[key]; !exists {
+			withdrawn = append(withdrawn, prefix)
+		}
+	}
+	if len(announced) == 0 && len(withdrawn) == 0 {
+		return nil
+	}
+	sortPrefixes(announced)
+	sortPrefixes(withdrawn)
+	for _, msg := range encoder.encodeUpdates(announced, withdrawn) {
+		if _, err := conn.Write(msg); err != nil {
+			return err
+		}
+	}
+	for _, prefix := range withdrawn {
+		delete(advertised, prefix.String())
+	}
+	for _, prefix := range announced {
+		advertised[prefix.String()] = prefix
+	}
+	klog.V(2).Infof("Announced %v and withdrew %v to BGP peer %s", announced, withdrawn, s.peer.Address)
+	return nil
+}
+
+func sortPrefixes(prefixes []net.IPNet) {
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixes[i].String() < prefixes[j].String()
+	})
+}
+
+func sendNotification(conn net.Conn, code, subcode uint8) {
+	// The connection is closed right after, so the error is ignored.
+	_, _ = conn.Write(encodeNotification(code, subcode))
+}
+
+func unexpectedMessageError(msgType uint8, body []byte) error {
+	if msgType == msgTypeNotification && len(body) >= 2 {
+		return fmt.Errorf("received NOTIFICATION with code %d and subcode %d", body[0], body[1])
+	}
+	return fmt.Errorf("received unexpected message of type %d", msgType)
+}
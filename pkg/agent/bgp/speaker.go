@@ -0,0 +1,157 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bgp implements a minimal BGP-4 speaker which advertises the prefixes
+// of the Node to its peers. It only supports what the BGPPolicies require: the
+// sessions are initiated by the speaker, the routes received from the peers are
+// ignored, and the prefixes are originated with the speaker as the next hop. A
+// complete implementation such as GoBGP would bring a large dependency and a
+// RIB which Antrea doesn't use.
+package bgp
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// DefaultPort is the default TCP port of BGP peers.
+	DefaultPort = 179
+	// DefaultHoldTime is the default hold time proposed to BGP peers.
+	DefaultHoldTime = 90 * time.Second
+)
+
+// PeerConfig is the configuration of a BGP peer.
+type PeerConfig struct {
+	Address  net.IP
+	Port     int32
+	ASN      uint32
+	HoldTime time.Duration
+}
+
+func (p *PeerConfig) key() string {
+	return net.JoinHostPort(p.Address.String(), strconv.Itoa(int(p.Port)))
+}
+
+// PeerStatus is the status of the BGP session with a peer.
+type PeerStatus struct {
+	Address net.IP
+	Port    int32
+	ASN     uint32
+	State   SessionState
+}
+
+// Interface is the interface of a BGP speaker.
+type Interface interface {
+	// SetPeers sets the BGP peers the speaker maintains sessions with.
+	SetPeers(peers []PeerConfig)
+	// SetPrefixes sets the prefixes the speaker advertises to its peers.
+	SetPrefixes(prefixes []net.IPNet)
+	// GetPeerStatus returns the status of the sessions with the peers.
+	GetPeerStatus() []PeerStatus
+	// Stop closes all the sessions.
+	Stop()
+}
+
+// Speaker is an advertise-only BGP speaker: it originates routes to a set of
+// prefixes, with the local address of each session as the next hop, and
+// ignores the routes received from its peers. It initiates the connections to
+// its peers and never accepts incoming connections.
+type Speaker struct {
+	localASN uint32
+	routerID net.IP
+
+	mutex    sync.Mutex
+	sessions map[string]*session
+	prefixes []net.IPNet
+}
+
+var _ Interface = &Speaker{}
+
+// NewSpeaker returns a Speaker using the provided AS number and router ID,
+// which must be an IPv4 address.
+func NewSpeaker(localASN uint32, routerID net.IP) *Speaker {
+	return &Speaker{
+		localASN: localASN,
+		routerID: routerID,
+		sessions: map[string]*session{},
+	}
+}
+
+func (s *Speaker) SetPeers(peers []PeerConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	desired := make(map[string]PeerConfig, len(peers))
+	for _, peer := range peers {
+		if peer.Port == 0 {
+			peer.Port = DefaultPort
+		}
+		if peer.HoldTime == 0 {
+			peer.HoldTime = DefaultHoldTime
+		}
+		desired[peer.key()] = peer
+	}
+	for key, sess := range s.sessions {
+		if peer, exists := desired[key]; !exists || peer.ASN != sess.peer.ASN || peer.HoldTime != sess.peer.HoldTime {
+			klog.Infof("Closing BGP session with peer %s", key)
+			sess.stop()
+			delete(s.sessions, key)
+		}
+	}
+	for key, peer := range desired {
+		if _, exists := s.sessions[key]; exists {
+			continue
+		}
+		klog.Infof("Starting BGP session with peer %s (AS %d)", key, peer.ASN)
+		sess := newSession(peer, s.localASN, s.routerID, s.prefixes)
+		s.sessions[key] = sess
+		go sess.run()
+	}
+}
+
+func (s *Speaker) SetPrefixes(prefixes []net.IPNet) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.prefixes = prefixes
+	for _, sess := range s.sessions {
+		sess.setPrefixes(prefixes)
+	}
+}
+
+func (s *Speaker) GetPeerStatus() []PeerStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	statuses := make([]PeerStatus, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		statuses = append(statuses, PeerStatus{
+			Address: sess.peer.Address,
+			Port:    sess.peer.Port,
+			ASN:     sess.peer.ASN,
+			State:   sess.getState(),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Address.String() < statuses[j].Address.String()
+	})
+	return statuses
+}
+
+func (s *Speaker) Stop() {
+	s.SetPeers(nil)
+}
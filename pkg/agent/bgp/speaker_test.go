@@ -0,0 +1,251 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// testPeer is a minimal BGP peer listening on the loopback address, which
+// records the prefixes announced by the speaker.
+type testPeer struct {
+	t        *testing.T
+	asn      uint32
+	holdTime uint16
+	listener net.Listener
+
+	mutex        sync.Mutex
+	routes       map[string]bool
+	notification []byte
+	open         *openMessage
+}
+
+func newTestPeer(t *testing.T, asn uint32) *testPeer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &testPeer{t: t, asn: asn, holdTime: 3, listener: listener, routes: map[string]bool{}}
+	go p.serve()
+	return p
+}
+
+func (p *testPeer) config() PeerConfig {
+	addr := p.listener.Addr().(*net.TCPAddr)
+	return PeerConfig{Address: addr.IP, Port: int32(addr.Port), ASN: p.asn, HoldTime: 3 * time.Second}
+}
+
+func (p *testPeer) close() {
+	p.listener.Close()
+}
+
+func (p *testPeer) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.handle(conn)
+	}
+}
+
+func (p *testPeer) handle(conn net.Conn) {
+	defer conn.Close()
+	msgType, body, err := readMessage(conn)
+	if err != nil || msgType != msgTypeOpen {
+		return
+	}
+	open, err := decodeOpen(body)
+	if err != nil {
+		return
+	}
+	p.mutex.Lock()
+	p.open = open
+	p.routes = map[string]bool{}
+	holdTime := p.holdTime
+	p.mutex.Unlock()
+	if _, err := conn.Write(encodeOpen(p.asn, holdTime, net.ParseIP("127.0.0.2"), false)); err != nil {
+		return
+	}
+	if _, err := conn.Write(encodeKeepalive()); err != nil {
+		return
+	}
+	for {
+		msgType, body, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+		switch msgType {
+		case msgTypeUpdate:
+			update := decodeUpdate(p.t, body)
+			p.mutex.Lock()
+			for _, prefix := range update.withdrawn {
+				delete(p.routes, prefix)
+			}
+			for _, prefix := range update.announced {
+				p.routes[prefix] = true
+			}
+			p.mutex.Unlock()
+		case msgTypeNotification:
+			p.mutex.Lock()
+			p.notification = body
+			p.mutex.Unlock()
+			return
+		}
+	}
+}
+
+func (p *testPeer) getRoutes() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	routes := make([]string, 0, len(p.routes))
+	for prefix := range p.routes {
+		routes = append(routes, prefix)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func (p *testPeer) getNotification() []byte {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.notification
+}
+
+func waitForRoutes(t *testing.T, p *testPeer, expected []string) {
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return assert.ObjectsAreEqual(expected, p.getRoutes()), nil
+	})
+	assert.NoError(t, err, "Expected routes %v, got %v", expected, p.getRoutes())
+}
+
+func waitForState(t *testing.T, s *Speaker, expected SessionState) {
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		statuses := s.GetPeerStatus()
+		return len(statuses) == 1 && statuses[0].State == expected, nil
+	})
+	assert.NoError(t, err, "Session did not reach state %s: %v", expected, s.GetPeerStatus())
+}
+
+func TestSpeaker(t *testing.T) {
+	defer func(interval time.Duration) { connectRetryInterval = interval }(connectRetryInterval)
+	connectRetryInterval = 50 * time.Millisecond
+
+	peer := newTestPeer(t, 65001)
+	defer peer.close()
+	s := NewSpeaker(65000, net.ParseIP("10.0.0.1"))
+	s.SetPrefixes(mustParseCIDRs("10.10.0.0/24", "fd00:10:10::/64"))
+	s.SetPeers([]PeerConfig{peer.config()})
+	waitForState(t, s, SessionStateEstablished)
+	// The IPv6 prefix is not advertised to the IPv4 peer.
+	waitForRoutes(t, peer, []string{"10.10.0.0/24"})
+
+	peer.mutex.Lock()
+	assert.Equal(t, uint32(65000), peer.open.asn)
+	assert.Equal(t, "10.0.0.1", peer.open.routerID.String())
+	peer.mutex.Unlock()
+
+	s.SetPrefixes(mustParseCIDRs("10.10.0.0/24", "172.16.1.10/32"))
+	waitForRoutes(t, peer, []string{"10.10.0.0/24", "172.16.1.10/32"})
+	s.SetPrefixes(mustParseCIDRs("172.16.1.10/32"))
+	waitForRoutes(t, peer, []string{"172.16.1.10/32"})
+
+	s.Stop()
+	assert.Empty(t, s.GetPeerStatus())
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return peer.getNotification() != nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{notifCodeCease, notifSubcodeAdminShutdown}, peer.getNotification())
+}
+
+func TestSpeakerPeerASNMismatch(t *testing.T) {
+	defer func(interval time.Duration) { connectRetryInterval = interval }(connectRetryInterval)
+	connectRetryInterval = 50 * time.Millisecond
+
+	peer := newTestPeer(t, 65001)
+	defer peer.close()
+	s := NewSpeaker(65000, net.ParseIP("10.0.0.1"))
+	defer s.Stop()
+	config := peer.config()
+	config.ASN = 65002
+	s.SetPeers([]PeerConfig{config})
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return peer.getNotification() != nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{notifCodeOpenMessageError, notifSubcodeBadPeerAS}, peer.getNotification())
+	assert.NotEqual(t, SessionStateEstablished, s.GetPeerStatus()[0].State)
+
+	// Correcting the ASN restarts the session.
+	s.SetPeers([]PeerConfig{peer.config()})
+	waitForState(t, s, SessionStateEstablished)
+}
+
+func TestSpeakerPeerHoldTimeUnacceptable(t *testing.T) {
+	defer func(interval time.Duration) { connectRetryInterval = interval }(connectRetryInterval)
+	connectRetryInterval = 50 * time.Millisecond
+
+	peer := newTestPeer(t, 65001)
+	defer peer.close()
+	peer.mutex.Lock()
+	peer.holdTime = 2
+	peer.mutex.Unlock()
+	s := NewSpeaker(65000, net.ParseIP("10.0.0.1"))
+	defer s.Stop()
+	s.SetPeers([]PeerConfig{peer.config()})
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return peer.getNotification() != nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{notifCodeOpenMessageError, notifSubcodeBadHoldTime}, peer.getNotification())
+	assert.NotEqual(t, SessionStateEstablished, s.GetPeerStatus()[0].State)
+}
+
+func TestSpeakerReconnect(t *testing.T) {
+	defer func(interval time.Duration) { connectRetryInterval = interval }(connectRetryInterval)
+	connectRetryInterval = 50 * time.Millisecond
+
+	// Reserve a port with no listener, so the first connection attempts fail.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	s := NewSpeaker(65000, net.ParseIP("10.0.0.1"))
+	defer s.Stop()
+	s.SetPrefixes(mustParseCIDRs("10.10.0.0/24"))
+	tcpAddr := listener.Addr().(*net.TCPAddr)
+	s.SetPeers([]PeerConfig{{Address: tcpAddr.IP, Port: int32(tcpAddr.Port), ASN: 65000}})
+	time.Sleep(100 * time.Millisecond)
+	assert.NotEqual(t, SessionStateEstablished, s.GetPeerStatus()[0].State)
+	assert.Equal(t, DefaultHoldTime, s.sessions[addr].peer.HoldTime)
+
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	peer := &testPeer{t: t, asn: 65000, listener: listener, routes: map[string]bool{}}
+	go peer.serve()
+	defer peer.close()
+	waitForState(t, s, SessionStateEstablished)
+	waitForRoutes(t, peer, []string{"10.10.0.0/24"})
+}
@@ -0,0 +1,431 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/current"
+	"github.com/containernetworking/plugins/pkg/ip"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	argtypes "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/types"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/core/v1alpha2"
+)
+
+const (
+	// AntreaIPAMAnnotationKey is the annotation of a Namespace which specifies
+	// the IPPools (separated by commas) the IPs of its Pods are allocated from.
+	// One IP is allocated from each IPPool, only IPv4 IPPools are supported.
+	AntreaIPAMAnnotationKey = "ipam.antrea.tanzu.vmware.com/ippools"
+
+	// containerIDIndex is the index of the IPPools by the IDs of the containers
+	// which have IPs allocated from them.
+	containerIDIndex = "containerID"
+)
+
+func containerIDIndexFunc(obj interface{}) ([]string, error) {
+	pool, ok := obj.(*v1alpha2.IPPool)
+	if !ok {
+		return nil, fmt.Errorf("obj is not IPPool: %+v", obj)
+	}
+	var containerIDs []string
+	for _, address := range pool.Status.IPAddresses {
+		if address.Owner.Pod != nil {
+			containerIDs = append(containerIDs, address.Owner.Pod.ContainerID)
+		}
+	}
+	return containerIDs, nil
+}
+
+// AntreaIPAM is an IPAM driver allocating Pod IPs from IPPool CRDs. It owns the
+// requests of the Pods whose Namespace is annotated with AntreaIPAMAnnotationKey,
+// and persists the allocations in the status of the IPPools, so that they are
+// shared by all the Nodes and survive agent restarts.
+type AntreaIPAM struct {
+	crdClient             clientset.Interface
+	namespaceLister       corelisters.NamespaceLister
+	namespaceListerSynced cache.InformerSynced
+	// ipPoolIndexer is used to find the IPPools which have IPs allocated to a
+	// container without listing them from the API server.
+	ipPoolIndexer      cache.Indexer
+	ipPoolListerSynced cache.InformerSynced
+}
+
+// antreaIPAMDriver is registered in init(), and does not own any request until
+// it is initialized.
+var antreaIPAMDriver = &AntreaIPAM{}
+
+// InitializeAntreaIPAMDriver enables the Antrea IPAM driver. It must be called
+// before the CNI server starts handling requests.
+func InitializeAntreaIPAMDriver(crdClient clientset.Interface, namespaceInformer coreinformers.NamespaceInformer, ipPoolInformer crdinformers.IPPoolInformer) {
+	ipPoolInformer.Informer().AddIndexers(cache.Indexers{containerIDIndex: containerIDIndexFunc})
+	antreaIPAMDriver.crdClient = crdClient
+	antreaIPAMDriver.namespaceLister = namespaceInformer.Lister()
+	antreaIPAMDriver.namespaceListerSynced = namespaceInformer.Informer().HasSynced
+	antreaIPAMDriver.ipPoolIndexer = ipPoolInformer.Informer().GetIndexer()
+	antreaIPAMDriver.ipPoolListerSynced = ipPoolInformer.Informer().HasSynced
+}
+
+// cacheSynced tells whether the Namespaces and the IPPools are synced, which
+// is required to tell whether a request should be handled by the driver.
+func (d *AntreaIPAM) cacheSynced() bool {
+	return d.namespaceListerSynced() && d.ipPoolListerSynced()
+}
+
+func (d *AntreaIPAM) Add(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, *current.Result, error) {
+	if d.crdClient == nil {
+		return false, nil, nil
+	}
+	// Without the Namespaces, we cannot tell whether the request should be
+	// handled by host-local IPAM, so fail it and let the runtime retry.
+	if !d.cacheSynced() {
+		return true, nil, fmt.Errorf("Namespace and IPPool caches are not synced yet")
+	}
+	poolNames, err := d.getIPPoolNames(string(k8sArgs.K8S_POD_NAMESPACE))
+	if err != nil {
+		return true, nil, err
+	}
+	if len(poolNames) == 0 {
+		return false, nil, nil
+	}
+
+	owner := v1alpha2.IPAddressOwner{Pod: &v1alpha2.PodOwner{
+		Name:        string(k8sArgs.K8S_POD_NAME),
+		Namespace:   string(k8sArgs.K8S_POD_NAMESPACE),
+		ContainerID: args.ContainerID,
+	}}
+	result := &current.Result{CNIVersion: current.ImplementedSpecVersion}
+	// Only one default route can be added for each address family, via the
+	// gateway of the first IPPool of the family.
+	defaultRouteAdded := map[string]bool{}
+	for i, poolName := range poolNames {
+		ipConfig, err := d.allocate(poolName, owner)
+		if err != nil {
+			if releaseErr := d.release(args.ContainerID, poolNames[:i]); releaseErr != nil {
+				klog.Errorf("Failed to release IPs allocated to container %s: %v", args.ContainerID, releaseErr)
+			}
+			return true, nil, fmt.Errorf("failed to allocate IP from IPPool %s: %v", poolName, err)
+		}
+		result.IPs = append(result.IPs, ipConfig)
+		if ipConfig.Gateway != nil && !defaultRouteAdded[ipConfig.Version] {
+			defaultRouteAdded[ipConfig.Version] = true
+			result.Routes = append(result.Routes, &cnitypes.Route{Dst: defaultRouteDst(ipConfig.Gateway), GW: ipConfig.Gateway})
+		}
+	}
+	klog.V(2).Infof("Allocated IPs %v from IPPools %v to container %s", result.IPs, poolNames, args.ContainerID)
+	return true, result, nil
+}
+
+func (d *AntreaIPAM) Del(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error) {
+	if d.crdClient == nil {
+		return false, nil
+	}
+	if !d.cacheSynced() {
+		return true, fmt.Errorf("Namespace and IPPool caches are not synced yet")
+	}
+	pools, err := d.findIPPools(args.ContainerID, string(k8sArgs.K8S_POD_NAMESPACE))
+	if err != nil {
+		return true, err
+	}
+	if len(pools) == 0 {
+		return false, nil
+	}
+	return true, d.release(args.ContainerID, pools)
+}
+
+func (d *AntreaIPAM) Check(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error) {
+	if d.crdClient == nil {
+		return false, nil
+	}
+	if !d.cacheSynced() {
+		return true, fmt.Errorf("Namespace and IPPool caches are not synced yet")
+	}
+	pools, err := d.findIPPools(args.ContainerID, string(k8sArgs.K8S_POD_NAMESPACE))
+	if err != nil {
+		return true, err
+	}
+	if len(pools) > 0 {
+		return true, nil
+	}
+	poolNames, err := d.getIPPoolNames(string(k8sArgs.K8S_POD_NAMESPACE))
+	if err != nil {
+		return true, err
+	}
+	if len(poolNames) > 0 {
+		return true, fmt.Errorf("no IP allocated to container %s from IPPools %v", args.ContainerID, poolNames)
+	}
+	return false, nil
+}
+
+// getIPPoolNames returns the IPPools specified by the annotation of the
+// Namespace, or nil if the Namespace is not annotated.
+func (d *AntreaIPAM) getIPPoolNames(namespace string) ([]string, error) {
+	ns, err := d.namespaceLister.Get(namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var poolNames []string
+	for _, name := range strings.Split(ns.Annotations[AntreaIPAMAnnotationKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			poolNames = append(poolNames, name)
+		}
+	}
+	return poolNames, nil
+}
+
+// findIPPools returns the names of the IPPools which have IPs allocated to the
+// container. The allocations are looked up by container ID in the IPPool cache,
+// as the Namespace annotation may have been changed or removed since the IPs
+// were allocated. The IPPools specified by the annotation of the Namespace are
+// got from the API server, as the cache may not have received the allocations
+// just made yet. The API server is therefore never queried for the containers
+// of other Namespaces.
+func (d *AntreaIPAM) findIPPools(containerID, namespace string) ([]string, error) {
+	poolNames := sets.NewString()
+	pools, err := d.ipPoolIndexer.ByIndex(containerIDIndex, containerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range pools {
+		poolNames.Insert(obj.(*v1alpha2.IPPool).Name)
+	}
+	namespacePoolNames, err := d.getIPPoolNames(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, poolName := range namespacePoolNames {
+		if poolNames.Has(poolName) {
+			continue
+		}
+		pool, err := d.crdClient.CoreV1alpha2().IPPools().Get(context.TODO(), poolName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if findAllocation(pool, containerID) >= 0 {
+			poolNames.Insert(poolName)
+		}
+	}
+	return poolNames.List(), nil
+}
+
+// allocate allocates an IP from the IPPool to the owner and persists it in the
+// IPPool status. It is idempotent: the IP already allocated to the same
+// container is returned if any.
+func (d *AntreaIPAM) allocate(poolName string, owner v1alpha2.IPAddressOwner) (*current.IPConfig, error) {
+	var ipConfig *current.IPConfig
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pool, err := d.crdClient.CoreV1alpha2().IPPools().Get(context.TODO(), poolName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// The Pods are connected to their gateways by proxy ARP on the host
+		// gateway interface, there is no equivalent for IPv6 yet.
+		if pool.Spec.IPVersion != 4 {
+			return fmt.Errorf("IPv%d IPPools are not supported yet", pool.Spec.IPVersion)
+		}
+		allocatedIP, ipRange, exists, err := allocateIP(pool, owner.Pod.ContainerID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			pool.Status.IPAddresses = append(pool.Status.IPAddresses, v1alpha2.IPAddressState{
+				IPAddress: allocatedIP.String(),
+				Phase:     v1alpha2.IPAddressPhaseAllocated,
+				Owner:     owner,
+			})
+			if _, err := d.crdClient.CoreV1alpha2().IPPools().UpdateStatus(context.TODO(), pool, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+		ipConfig = newIPConfig(allocatedIP, ipRange)
+		return nil
+	})
+	return ipConfig, err
+}
+
+// release removes the IPs allocated to the container from the IPPools.
+func (d *AntreaIPAM) release(containerID string, poolNames []string) error {
+	for _, poolName := range poolNames {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pool, err := d.crdClient.CoreV1alpha2().IPPools().Get(context.TODO(), poolName, metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return nil
+				}
+				return err
+			}
+			addresses := make([]v1alpha2.IPAddressState, 0, len(pool.Status.IPAddresses))
+			for _, address := range pool.Status.IPAddresses {
+				if address.Owner.Pod == nil || address.Owner.Pod.ContainerID != containerID {
+					addresses = append(addresses, address)
+				}
+			}
+			if len(addresses) == len(pool.Status.IPAddresses) {
+				return nil
+			}
+			pool.Status.IPAddresses = addresses
+			_, err = d.crdClient.CoreV1alpha2().IPPools().UpdateStatus(context.TODO(), pool, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to release IP of container %s from IPPool %s: %v", containerID, poolName, err)
+		}
+		klog.V(2).Infof("Released IP of container %s from IPPool %s", containerID, poolName)
+	}
+	return nil
+}
+
+// findAllocation returns the index of the IP allocated to the container in the
+// IPPool status, or -1 if there is none.
+func findAllocation(pool *v1alpha2.IPPool, containerID string) int {
+	for i, address := range pool.Status.IPAddresses {
+		if address.Owner.Pod != nil && address.Owner.Pod.ContainerID == containerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// allocateIP returns the IP allocated to the container from the IPPool and the
+// range it belongs to. If the container has no IP allocated yet, the first free
+// IP of the IPPool is returned, with exists set to false.
+func allocateIP(pool *v1alpha2.IPPool, containerID string) (net.IP, *v1alpha2.SubnetIPRange, bool, error) {
+	if i := findAllocation(pool, containerID); i >= 0 {
+		allocatedIP := net.ParseIP(pool.Status.IPAddresses[i].IPAddress)
+		for j := range pool.Spec.IPRanges {
+			ipRange := &pool.Spec.IPRanges[j]
+			start, end, err := parseIPRange(ipRange, pool.Spec.IPVersion)
+			if err != nil {
+				continue
+			}
+			if allocatedIP != nil && ip.Cmp(allocatedIP, start) >= 0 && ip.Cmp(allocatedIP, end) <= 0 {
+				return allocatedIP, ipRange, true, nil
+			}
+		}
+		return nil, nil, false, fmt.Errorf("IP %s allocated to container %s does not belong to any range", pool.Status.IPAddresses[i].IPAddress, containerID)
+	}
+
+	allocated := make(map[string]struct{}, len(pool.Status.IPAddresses))
+	for _, address := range pool.Status.IPAddresses {
+		if allocatedIP := net.ParseIP(address.IPAddress); allocatedIP != nil {
+			allocated[allocatedIP.String()] = struct{}{}
+		}
+	}
+	for j := range pool.Spec.IPRanges {
+		ipRange := &pool.Spec.IPRanges[j]
+		start, end, err := parseIPRange(ipRange, pool.Spec.IPVersion)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		gateway := net.ParseIP(ipRange.Gateway)
+		for candidate := start; ip.Cmp(candidate, end) <= 0; candidate = ip.NextIP(candidate) {
+			if gateway != nil && candidate.Equal(gateway) {
+				continue
+			}
+			if _, exists := allocated[candidate.String()]; exists {
+				continue
+			}
+			return candidate, ipRange, false, nil
+		}
+	}
+	return nil, nil, false, fmt.Errorf("no IP available in IPPool %s", pool.Name)
+}
+
+// parseIPRange returns the first and the last IPs which can be allocated from
+// the range. The network and broadcast addresses of an IPv4 CIDR, and the
+// Subnet-Router anycast address of an IPv6 CIDR are excluded.
+func parseIPRange(ipRange *v1alpha2.SubnetIPRange, ipVersion int) (net.IP, net.IP, error) {
+	var start, end net.IP
+	if ipRange.CIDR != "" {
+		_, ipNet, err := net.ParseCIDR(ipRange.CIDR)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CIDR %s: %v", ipRange.CIDR, err)
+		}
+		ones, bits := ipNet.Mask.Size()
+		start = ipNet.IP
+		end = make(net.IP, len(ipNet.IP))
+		for i := range ipNet.IP {
+			end[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+		}
+		if bits-ones > 1 {
+			start = ip.NextIP(start)
+			if bits == net.IPv4len*8 {
+				end = ip.PrevIP(end)
+			}
+		}
+	} else {
+		start = net.ParseIP(ipRange.Start)
+		end = net.ParseIP(ipRange.End)
+		if start == nil || end == nil {
+			return nil, nil, fmt.Errorf("invalid IP range %s-%s", ipRange.Start, ipRange.End)
+		}
+		if ip.Cmp(start, end) > 0 {
+			return nil, nil, fmt.Errorf("start IP %s of range is greater than end IP %s", ipRange.Start, ipRange.End)
+		}
+	}
+	isIPv4 := start.To4() != nil
+	if isIPv4 != (end.To4() != nil) || isIPv4 != (ipVersion == 4) {
+		return nil, nil, fmt.Errorf("IP range %s does not match IP version %d", ipRangeString(ipRange), ipVersion)
+	}
+	return start, end, nil
+}
+
+func ipRangeString(ipRange *v1alpha2.SubnetIPRange) string {
+	if ipRange.CIDR != "" {
+		return ipRange.CIDR
+	}
+	return ipRange.Start + "-" + ipRange.End
+}
+
+func newIPConfig(allocatedIP net.IP, ipRange *v1alpha2.SubnetIPRange) *current.IPConfig {
+	version, bits := "6", net.IPv6len*8
+	if allocatedIP.To4() != nil {
+		version, bits = "4", net.IPv4len*8
+		allocatedIP = allocatedIP.To4()
+	}
+	return &current.IPConfig{
+		Version: version,
+		Address: net.IPNet{IP: allocatedIP, Mask: net.CIDRMask(int(ipRange.PrefixLength), bits)},
+		Gateway: net.ParseIP(ipRange.Gateway),
+	}
+}
+
+func defaultRouteDst(gateway net.IP) net.IPNet {
+	if gateway.To4() != nil {
+		return net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, net.IPv4len*8)}
+	}
+	return net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, net.IPv6len*8)}
+}
@@ -0,0 +1,298 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	argtypes "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/types"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+func newIPPool(name string, ipVersion int, ipRanges []v1alpha2.SubnetIPRange, allocated ...string) *v1alpha2.IPPool {
+	pool := &v1alpha2.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha2.IPPoolSpec{IPVersion: ipVersion, IPRanges: ipRanges},
+	}
+	for i, ip := range allocated {
+		pool.Status.IPAddresses = append(pool.Status.IPAddresses, v1alpha2.IPAddressState{
+			IPAddress: ip,
+			Phase:     v1alpha2.IPAddressPhaseAllocated,
+			Owner:     v1alpha2.IPAddressOwner{Pod: &v1alpha2.PodOwner{Name: "pod", Namespace: "ns", ContainerID: string(rune('a' + i))}},
+		})
+	}
+	return pool
+}
+
+func TestAllocateIP(t *testing.T) {
+	cidrRange := v1alpha2.SubnetIPRange{
+		IPRange:    v1alpha2.IPRange{CIDR: "10.2.0.0/30"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "10.2.0.1", PrefixLength: 24},
+	}
+	startEndRange := v1alpha2.SubnetIPRange{
+		IPRange:    v1alpha2.IPRange{Start: "10.2.0.100", End: "10.2.0.101"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "10.2.0.1", PrefixLength: 24},
+	}
+	ipv6Range := v1alpha2.SubnetIPRange{
+		IPRange:    v1alpha2.IPRange{CIDR: "fd00:10:2::/120"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "fd00:10:2::1", PrefixLength: 64},
+	}
+	tests := []struct {
+		name          string
+		pool          *v1alpha2.IPPool
+		containerID   string
+		expectedIP    string
+		expectedRange *v1alpha2.SubnetIPRange
+		expectedExist bool
+		expectedErr   bool
+	}{
+		{
+			name:          "skip gateway",
+			pool:          newIPPool("pool", 4, []v1alpha2.SubnetIPRange{cidrRange}),
+			containerID:   "x",
+			expectedIP:    "10.2.0.2",
+			expectedRange: &cidrRange,
+		},
+		{
+			name:          "next range",
+			pool:          newIPPool("pool", 4, []v1alpha2.SubnetIPRange{cidrRange, startEndRange}, "10.2.0.2", "10.2.0.100"),
+			containerID:   "x",
+			expectedIP:    "10.2.0.101",
+			expectedRange: &startEndRange,
+		},
+		{
+			name:          "existing allocation",
+			pool:          newIPPool("pool", 4, []v1alpha2.SubnetIPRange{cidrRange, startEndRange}, "10.2.0.2", "10.2.0.100"),
+			containerID:   "b",
+			expectedIP:    "10.2.0.100",
+			expectedRange: &startEndRange,
+			expectedExist: true,
+		},
+		{
+			name:        "exhausted",
+			pool:        newIPPool("pool", 4, []v1alpha2.SubnetIPRange{cidrRange, startEndRange}, "10.2.0.2", "10.2.0.100", "10.2.0.101"),
+			containerID: "x",
+			expectedErr: true,
+		},
+		{
+			name:          "ipv6",
+			pool:          newIPPool("pool", 6, []v1alpha2.SubnetIPRange{ipv6Range}),
+			containerID:   "x",
+			expectedIP:    "fd00:10:2::2",
+			expectedRange: &ipv6Range,
+		},
+		{
+			name:        "mismatched version",
+			pool:        newIPPool("pool", 6, []v1alpha2.SubnetIPRange{cidrRange}),
+			containerID: "x",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, ipRange, exists, err := allocateIP(tt.pool, tt.containerID)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIP, ip.String())
+			assert.Equal(t, tt.expectedRange, ipRange)
+			assert.Equal(t, tt.expectedExist, exists)
+		})
+	}
+}
+
+func TestAntreaIPAM(t *testing.T) {
+	pool := newIPPool("pool", 4, []v1alpha2.SubnetIPRange{{
+		IPRange:    v1alpha2.IPRange{CIDR: "10.2.0.0/24"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "10.2.0.1", PrefixLength: 24},
+	}})
+	pool6 := newIPPool("pool6", 6, []v1alpha2.SubnetIPRange{{
+		IPRange:    v1alpha2.IPRange{CIDR: "fd00:10:2::/120"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "fd00:10:2::1", PrefixLength: 64},
+	}})
+	pool2 := newIPPool("pool2", 4, []v1alpha2.SubnetIPRange{{
+		IPRange:    v1alpha2.IPRange{CIDR: "10.3.0.0/24"},
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "10.3.0.1", PrefixLength: 24},
+	}})
+	annotatedNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Annotations: map[string]string{AntreaIPAMAnnotationKey: "pool"}}}
+	ipv6NS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant6", Annotations: map[string]string{AntreaIPAMAnnotationKey: "pool6"}}}
+	multiPoolNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant2", Annotations: map[string]string{AntreaIPAMAnnotationKey: "pool2, pool"}}}
+	defaultNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	crdClient := fakeversioned.NewSimpleClientset(pool, pool2, pool6)
+	k8sClient := fake.NewSimpleClientset(annotatedNS, ipv6NS, multiPoolNS, defaultNS)
+	informerFactory := informers.NewSharedInformerFactory(k8sClient, 0)
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	ipPoolInformer := crdInformerFactory.Core().V1alpha2().IPPools()
+
+	d := &AntreaIPAM{}
+	newArgs := func(namespace, containerID string) (*invoke.Args, *argtypes.K8sArgs) {
+		return &invoke.Args{ContainerID: containerID}, &argtypes.K8sArgs{
+			K8S_POD_NAME:      cnitypes.UnmarshallableString("pod-" + containerID),
+			K8S_POD_NAMESPACE: cnitypes.UnmarshallableString(namespace),
+		}
+	}
+	getAllocated := func() []v1alpha2.IPAddressState {
+		p, err := crdClient.CoreV1alpha2().IPPools().Get(context.TODO(), "pool", metav1.GetOptions{})
+		require.NoError(t, err)
+		return p.Status.IPAddresses
+	}
+
+	// The driver does not own any request before it is initialized.
+	args, k8sArgs := newArgs("tenant", "c1")
+	owns, _, err := d.Add(args, k8sArgs, nil)
+	assert.False(t, owns)
+	assert.NoError(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	d.crdClient = crdClient
+	d.namespaceLister = namespaceInformer.Lister()
+	d.namespaceListerSynced = namespaceInformer.Informer().HasSynced
+	ipPoolInformer.Informer().AddIndexers(cache.Indexers{containerIDIndex: containerIDIndexFunc})
+	d.ipPoolIndexer = ipPoolInformer.Informer().GetIndexer()
+	d.ipPoolListerSynced = ipPoolInformer.Informer().HasSynced
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	crdInformerFactory.Start(stopCh)
+	crdInformerFactory.WaitForCacheSync(stopCh)
+
+	args, k8sArgs = newArgs("default", "c0")
+	owns, _, err = d.Add(args, k8sArgs, nil)
+	assert.False(t, owns, "Pods in Namespaces without annotation should be handled by other drivers")
+	assert.NoError(t, err)
+
+	args, k8sArgs = newArgs("tenant", "c1")
+	owns, result, err := d.Add(args, k8sArgs, nil)
+	require.True(t, owns)
+	require.NoError(t, err)
+	require.Len(t, result.IPs, 1)
+	assert.Equal(t, "10.2.0.2/24", result.IPs[0].Address.String())
+	assert.Equal(t, "10.2.0.1", result.IPs[0].Gateway.String())
+	require.Len(t, result.Routes, 1)
+	assert.Equal(t, "0.0.0.0/0", result.Routes[0].Dst.String())
+	assert.Equal(t, []v1alpha2.IPAddressState{{
+		IPAddress: "10.2.0.2",
+		Phase:     v1alpha2.IPAddressPhaseAllocated,
+		Owner:     v1alpha2.IPAddressOwner{Pod: &v1alpha2.PodOwner{Name: "pod-c1", Namespace: "tenant", ContainerID: "c1"}},
+	}}, getAllocated())
+
+	// Add is idempotent.
+	_, result, err = d.Add(args, k8sArgs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0.2/24", result.IPs[0].Address.String())
+	assert.Len(t, getAllocated(), 1)
+
+	args2, k8sArgs2 := newArgs("tenant", "c2")
+	_, result, err = d.Add(args2, k8sArgs2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0.3/24", result.IPs[0].Address.String())
+
+	owns, err = d.Check(args, k8sArgs, nil)
+	assert.True(t, owns)
+	assert.NoError(t, err)
+
+	owns, err = d.Del(args, k8sArgs, nil)
+	assert.True(t, owns)
+	assert.NoError(t, err)
+	allocated := getAllocated()
+	require.Len(t, allocated, 1)
+	assert.Equal(t, "10.2.0.3", allocated[0].IPAddress)
+
+	// The released IP can be allocated again.
+	args3, k8sArgs3 := newArgs("tenant", "c3")
+	_, result, err = d.Add(args3, k8sArgs3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0.2/24", result.IPs[0].Address.String())
+
+	// Requests of containers without allocation are not owned by the driver,
+	// and the IPPools are not queried from the API server for them.
+	crdClient.ClearActions()
+	args, k8sArgs = newArgs("default", "c0")
+	owns, err = d.Del(args, k8sArgs, nil)
+	assert.False(t, owns)
+	assert.NoError(t, err)
+	owns, err = d.Check(args, k8sArgs, nil)
+	assert.False(t, owns)
+	assert.NoError(t, err)
+	assert.Empty(t, crdClient.Actions())
+
+	// The allocations are found from the IPPool cache after the Namespace
+	// annotation is removed.
+	assert.Eventually(t, func() bool {
+		pools, _ := ipPoolInformer.Informer().GetIndexer().ByIndex(containerIDIndex, "c2")
+		return len(pools) == 1
+	}, time.Second, 10*time.Millisecond)
+	args2, k8sArgs2 = newArgs("default", "c2")
+	owns, err = d.Del(args2, k8sArgs2, nil)
+	assert.True(t, owns)
+	assert.NoError(t, err)
+	allocated = getAllocated()
+	require.Len(t, allocated, 1)
+	assert.Equal(t, "10.2.0.2", allocated[0].IPAddress)
+
+	args, k8sArgs = newArgs("tenant", "c4")
+	owns, err = d.Check(args, k8sArgs, nil)
+	assert.True(t, owns)
+	assert.Error(t, err)
+
+	// One IP is allocated from each IPPool, but only one default route is
+	// added for the address family.
+	args, k8sArgs = newArgs("tenant2", "c6")
+	owns, result, err = d.Add(args, k8sArgs, nil)
+	require.True(t, owns)
+	require.NoError(t, err)
+	require.Len(t, result.IPs, 2)
+	assert.Equal(t, "10.3.0.2/24", result.IPs[0].Address.String())
+	assert.Equal(t, "10.2.0.3/24", result.IPs[1].Address.String())
+	require.Len(t, result.Routes, 1)
+	assert.Equal(t, "0.0.0.0/0", result.Routes[0].Dst.String())
+	assert.Equal(t, "10.3.0.1", result.Routes[0].GW.String())
+	owns, err = d.Del(args, k8sArgs, nil)
+	assert.True(t, owns)
+	assert.NoError(t, err)
+
+	// IPv6 IPPools are not supported by the datapath yet.
+	args, k8sArgs = newArgs("tenant6", "c5")
+	owns, _, err = d.Add(args, k8sArgs, nil)
+	assert.True(t, owns)
+	assert.Error(t, err)
+}
+
+func TestNewIPConfig(t *testing.T) {
+	ipConfig := newIPConfig(net.ParseIP("10.2.0.5"), &v1alpha2.SubnetIPRange{
+		SubnetInfo: v1alpha2.SubnetInfo{Gateway: "10.2.0.1", PrefixLength: 16},
+	})
+	assert.Equal(t, "4", ipConfig.Version)
+	assert.Equal(t, "10.2.0.5/16", ipConfig.Address.String())
+	assert.Equal(t, "10.2.0.1", ipConfig.Gateway.String())
+}
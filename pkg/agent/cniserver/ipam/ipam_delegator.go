@@ -23,6 +23,8 @@ import (
 	"github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/cni/pkg/types/current"
 	"k8s.io/klog"
+
+	argtypes "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/types"
 )
 
 const (
@@ -30,11 +32,13 @@ const (
 	defaultCNIPath = "/opt/cni/bin"
 )
 
+// IPAMDelegator delegates the IPAM requests to an IPAM plugin binary. It owns
+// all the requests it receives.
 type IPAMDelegator struct {
 	pluginType string
 }
 
-func (d *IPAMDelegator) Add(args *invoke.Args, networkConfig []byte) (*current.Result, error) {
+func (d *IPAMDelegator) Add(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, *current.Result, error) {
 	var success = false
 	defer func() {
 		if !success {
@@ -48,32 +52,32 @@ func (d *IPAMDelegator) Add(args *invoke.Args, networkConfig []byte) (*current.R
 	args.Command = "ADD"
 	r, err := delegateWithResult(d.pluginType, networkConfig, args)
 	if err != nil {
-		return nil, err
+		return true, nil, err
 	}
 
 	ipamResult, err := current.NewResultFromResult(r)
 	if err != nil {
-		return nil, err
+		return true, nil, err
 	}
 	success = true
-	return ipamResult, nil
+	return true, ipamResult, nil
 }
 
-func (d *IPAMDelegator) Del(args *invoke.Args, networkConfig []byte) error {
+func (d *IPAMDelegator) Del(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error) {
 	args.Command = "DEL"
 	if err := delegateNoResult(d.pluginType, networkConfig, args); err != nil {
-		return err
+		return true, err
 	}
 
-	return nil
+	return true, nil
 }
 
-func (d *IPAMDelegator) Check(args *invoke.Args, networkConfig []byte) error {
+func (d *IPAMDelegator) Check(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error) {
 	args.Command = "CHECK"
 	if err := delegateNoResult(d.pluginType, networkConfig, args); err != nil {
-		return err
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
 var defaultExec = &invoke.DefaultExec{
@@ -121,6 +125,13 @@ func delegateNoResult(delegatePlugin string, networkConfig []byte, args *invoke.
 }
 
 func init() {
+	// The Antrea IPAM driver is registered ahead of the host-local delegator,
+	// so that it gets the chance to handle the requests of the Pods whose
+	// Namespace is annotated with IPPools. It does not own any request until
+	// it is initialized with InitializeAntreaIPAMDriver.
+	if err := RegisterIPAMDriver(ipamHostLocal, antreaIPAMDriver); err != nil {
+		klog.Errorf("Failed to register Antrea IPAM driver on type %s", ipamHostLocal)
+	}
 	if err := RegisterIPAMDriver(ipamHostLocal, &IPAMDelegator{pluginType: ipamHostLocal}); err != nil {
 		klog.Errorf("Failed to register IPAM plugin on type %s", ipamHostLocal)
 	}
@@ -21,10 +21,14 @@ import (
 	"github.com/containernetworking/cni/pkg/invoke"
 	"github.com/containernetworking/cni/pkg/types/current"
 
+	argtypes "github.com/vmware-tanzu/antrea/pkg/agent/cniserver/types"
 	cnipb "github.com/vmware-tanzu/antrea/pkg/apis/cni/v1beta1"
 )
 
-var ipamDrivers map[string]IPAMDriver
+// ipamDrivers maps an IPAM type to the list of drivers registered for it. The
+// drivers are tried in their registration order, and the first driver which
+// claims the ownership of a request handles it.
+var ipamDrivers map[string][]IPAMDriver
 
 type Range struct {
 	Subnet  string `json:"subnet"`
@@ -38,22 +42,28 @@ type IPAMConfig struct {
 	Ranges []RangeSet `json:"ranges,omitempty"`
 }
 
+// IPAMDriver is the interface of the drivers handling IPAM requests. The first
+// return value of each method indicates whether the driver owns the request. A
+// driver which does not own a request must not take any action, and the request
+// is then passed to the next driver registered for the same IPAM type.
 type IPAMDriver interface {
-	Add(args *invoke.Args, networkConfig []byte) (*current.Result, error)
-	Del(args *invoke.Args, networkConfig []byte) error
-	Check(args *invoke.Args, networkConfig []byte) error
+	Add(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, *current.Result, error)
+	Del(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error)
+	Check(args *invoke.Args, k8sArgs *argtypes.K8sArgs, networkConfig []byte) (bool, error)
 }
 
 var ipamResults = sync.Map{}
 
 func RegisterIPAMDriver(ipamType string, ipamDriver IPAMDriver) error {
 	if ipamDrivers == nil {
-		ipamDrivers = make(map[string]IPAMDriver)
+		ipamDrivers = make(map[string][]IPAMDriver)
 	}
-	if _, existed := ipamDrivers[ipamType]; existed {
-		return fmt.Errorf("Already registered IPAM with type %s", ipamType)
+	for _, driver := range ipamDrivers[ipamType] {
+		if driver == ipamDriver {
+			return fmt.Errorf("Already registered IPAM driver with type %s", ipamType)
+		}
 	}
-	ipamDrivers[ipamType] = ipamDriver
+	ipamDrivers[ipamType] = append(ipamDrivers[ipamType], ipamDriver)
 	return nil
 }
 
@@ -66,7 +76,7 @@ func argsFromEnv(cniArgs *cnipb.CniCmdArgs) *invoke.Args {
 	}
 }
 
-func ExecIPAMAdd(cniArgs *cnipb.CniCmdArgs, ipamType string, resultKey string) (*current.Result, error) {
+func ExecIPAMAdd(cniArgs *cnipb.CniCmdArgs, k8sArgs *argtypes.K8sArgs, ipamType string, resultKey string) (*current.Result, error) {
 	// Return the cached IPAM result for the same Pod. This cache helps to ensure CNIAdd is idempotent. There are two
 	// usages of CNIAdd message on Windows: 1) add container network configuration, and 2) query Pod network status.
 	// kubelet on Windows sends CNIAdd messages to query Pod status periodically before the sandbox container is ready.
@@ -79,30 +89,45 @@ func ExecIPAMAdd(cniArgs *cnipb.CniCmdArgs, ipamType string, resultKey string) (
 	}
 
 	args := argsFromEnv(cniArgs)
-	driver := ipamDrivers[ipamType]
-	result, err := driver.Add(args, cniArgs.NetworkConfiguration)
-	if err != nil {
-		return nil, err
+	for _, driver := range ipamDrivers[ipamType] {
+		owns, result, err := driver.Add(args, k8sArgs, cniArgs.NetworkConfiguration)
+		if !owns {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ipamResults.Store(resultKey, result)
+		return result, nil
 	}
-	ipamResults.Store(resultKey, result)
-	return result, nil
+	return nil, fmt.Errorf("no IPAM driver of type %s handled the ADD request", ipamType)
 }
 
-func ExecIPAMDelete(cniArgs *cnipb.CniCmdArgs, ipamType string, resultKey string) error {
+func ExecIPAMDelete(cniArgs *cnipb.CniCmdArgs, k8sArgs *argtypes.K8sArgs, ipamType string, resultKey string) error {
 	args := argsFromEnv(cniArgs)
-	driver := ipamDrivers[ipamType]
-	err := driver.Del(args, cniArgs.NetworkConfiguration)
-	if err != nil {
-		return err
+	for _, driver := range ipamDrivers[ipamType] {
+		owns, err := driver.Del(args, k8sArgs, cniArgs.NetworkConfiguration)
+		if !owns {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ipamResults.Delete(resultKey)
+		return nil
 	}
-	ipamResults.Delete(resultKey)
-	return nil
+	return fmt.Errorf("no IPAM driver of type %s handled the DEL request", ipamType)
 }
 
-func ExecIPAMCheck(cniArgs *cnipb.CniCmdArgs, ipamType string) error {
+func ExecIPAMCheck(cniArgs *cnipb.CniCmdArgs, k8sArgs *argtypes.K8sArgs, ipamType string) error {
 	args := argsFromEnv(cniArgs)
-	driver := ipamDrivers[ipamType]
-	return driver.Check(args, cniArgs.NetworkConfiguration)
+	for _, driver := range ipamDrivers[ipamType] {
+		owns, err := driver.Check(args, k8sArgs, cniArgs.NetworkConfiguration)
+		if owns {
+			return err
+		}
+	}
+	return fmt.Errorf("no IPAM driver of type %s handled the CHECK request", ipamType)
 }
 
 func GetIPFromCache(resultKey string) (*current.Result, bool) {
@@ -41,13 +41,6 @@ type vethPair struct {
 	peerIndex int
 }
 
-type k8sArgs struct {
-	cnitypes.CommonArgs
-	K8S_POD_NAME               cnitypes.UnmarshallableString
-	K8S_POD_NAMESPACE          cnitypes.UnmarshallableString
-	K8S_POD_INFRA_CONTAINER_ID cnitypes.UnmarshallableString
-}
-
 const (
 	ovsExternalIDMAC          = "attached-mac"
 	ovsExternalIDIP           = "ip-address"
@@ -248,6 +241,11 @@ func (pc *podConfigurator) configureInterfaces(
 		}
 	}()
 
+	// Route the Pod IPs which are not allocated from the PodCIDR of the Node, e.g. by Antrea IPAM, to the host gateway.
+	if err = pc.routeClient.AddLocalPodRoutes(containerConfig.IPs); err != nil {
+		return fmt.Errorf("failed to add routes for container %s: %v", containerID, err)
+	}
+
 	// Note that the IP address should be advertised after Pod OpenFlow entries are installed, otherwise the packet might
 	// be dropped by OVS.
 	if err = pc.ifConfigurator.advertiseContainerAddr(containerNetNS, containerIface.Name, result); err != nil {
@@ -436,6 +434,9 @@ func (pc *podConfigurator) reconcile(pods []corev1.Pod) error {
 			); err != nil {
 				klog.Errorf("Error when re-installing flows for Pod %s", namespacedName)
 			}
+			if err := pc.routeClient.AddLocalPodRoutes(containerConfig.IPs); err != nil {
+				klog.Errorf("Error when re-installing routes for Pod %s: %v", namespacedName, err)
+			}
 		} else {
 			// clean-up and delete interface
 			klog.V(4).Infof("Deleting interface %s", containerConfig.InterfaceName)
@@ -516,6 +517,9 @@ func (pc *podConfigurator) disconnectInterfaceFromOVS(containerConfig *interface
 		// the OVS flows added for the new Pod can conflict with the stale
 		// flows of the deleted Pod.
 	}
+	if err := pc.routeClient.DeleteLocalPodRoutes(containerConfig.IPs); err != nil {
+		return fmt.Errorf("failed to delete routes for container %s: %v", containerID, err)
+	}
 
 	klog.V(2).Infof("Deleting OVS port %s for container %s", containerConfig.PortUUID, containerID)
 	// TODO: handle error and introduce garbage collection for failure on deletion
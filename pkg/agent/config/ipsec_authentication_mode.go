@@ -0,0 +1,57 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+)
+
+type IPsecAuthenticationModeType int
+
+const (
+	IPsecAuthenticationModePSK IPsecAuthenticationModeType = iota
+	IPsecAuthenticationModeCert
+	IPsecAuthenticationModeInvalid = -1
+)
+
+var (
+	ipsecAuthenticationModeStrs = [...]string{
+		"psk",
+		"cert",
+	}
+)
+
+// GetIPsecAuthenticationModeFromStr returns true and IPsecAuthenticationModeType corresponding to input string.
+// Otherwise, false and undefined value is returned
+func GetIPsecAuthenticationModeFromStr(str string) (bool, IPsecAuthenticationModeType) {
+	for idx, ms := range ipsecAuthenticationModeStrs {
+		if strings.EqualFold(ms, str) {
+			return true, IPsecAuthenticationModeType(idx)
+		}
+	}
+	return false, IPsecAuthenticationModeInvalid
+}
+
+func GetIPsecAuthenticationModes() []IPsecAuthenticationModeType {
+	return []IPsecAuthenticationModeType{
+		IPsecAuthenticationModePSK,
+		IPsecAuthenticationModeCert,
+	}
+}
+
+// String returns value in string.
+func (m IPsecAuthenticationModeType) String() string {
+	return ipsecAuthenticationModeStrs[m]
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIPsecAuthenticationModeFromStr(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		expBool bool
+		expMode IPsecAuthenticationModeType
+	}{
+		{"psk-mode-valid", "psk", true, IPsecAuthenticationModePSK},
+		{"cert-mode-valid", "Cert", true, IPsecAuthenticationModeCert},
+		{"invalid-str", "certificate", false, IPsecAuthenticationModeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualBool, actualMode := GetIPsecAuthenticationModeFromStr(tt.mode)
+			assert.Equal(t, tt.expBool, actualBool, "GetIPsecAuthenticationModeFromStr did not return correct boolean")
+			assert.Equal(t, tt.expMode, actualMode, "GetIPsecAuthenticationModeFromStr did not return correct authentication mode")
+		})
+	}
+}
+
+func TestIPsecAuthenticationModeTypeString(t *testing.T) {
+	for _, mode := range GetIPsecAuthenticationModes() {
+		ok, parsedMode := GetIPsecAuthenticationModeFromStr(mode.String())
+		assert.True(t, ok)
+		assert.Equal(t, mode, parsedMode)
+	}
+}
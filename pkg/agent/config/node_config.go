@@ -39,10 +39,21 @@ const (
 	GREOverhead    = 38
 	// IPsec ESP can add a maximum of 38 bytes to the packet including the ESP
 	// header and trailer.
-	IPSecESPOverhead  = 38
+	IPSecESPOverhead = 38
+	// WireGuard adds an outer IPv4 or IPv6 header, a UDP header and the
+	// WireGuard header and authentication tag to the packet.
+	WireGuardOverhead = 60
 	IPv6ExtraOverhead = 20
 )
 
+var (
+	// VirtualServiceIPv4 and VirtualServiceIPv6 are the source IPs of the Service traffic from the host
+	// network that is sent back to the host network after load-balancing by AntreaProxy, when proxyAll is
+	// enabled. They are routed to the host gateway, so that the replies enter OVS to be unNATed.
+	VirtualServiceIPv4 = net.ParseIP("169.254.169.253")
+	VirtualServiceIPv6 = net.ParseIP("fc01::aabb:ccdd:eeff")
+)
+
 type GatewayConfig struct {
 	// Name is the name of host gateway, e.g. antrea-gw0.
 	Name string
@@ -58,6 +69,17 @@ func (g *GatewayConfig) String() string {
 	return fmt.Sprintf("Name %s: IPv4 %s, IPv6 %s, MAC %s", g.Name, g.IPv4, g.IPv6, g.MAC)
 }
 
+type WireGuardConfig struct {
+	// Name is the name of the WireGuard device, e.g. antrea-wg0.
+	Name string
+	// Port is the UDP port the WireGuard device listens on.
+	Port int
+	// MTU is the MTU of the WireGuard device.
+	MTU int
+	// LinkIndex is the link index of the WireGuard device.
+	LinkIndex int
+}
+
 type AdapterNetConfig struct {
 	Name       string
 	Index      int
@@ -93,6 +115,9 @@ type NodeConfig struct {
 	GatewayConfig *GatewayConfig
 	// The config of the OVS bridge uplink interface. Only for Windows Node.
 	UplinkNetConfig *AdapterNetConfig
+	// The config of the WireGuard device. It's nil if WireGuard is not used
+	// for traffic encryption.
+	WireGuardConfig *WireGuardConfig
 }
 
 func (n *NodeConfig) String() string {
@@ -102,10 +127,19 @@ func (n *NodeConfig) String() string {
 
 // User provided network configuration parameters.
 type NetworkConfig struct {
-	TrafficEncapMode  TrafficEncapModeType
-	TunnelType        ovsconfig.TunnelType
-	EnableIPSecTunnel bool
-	IPSecPSK          string
+	TrafficEncapMode        TrafficEncapModeType
+	TunnelType              ovsconfig.TunnelType
+	TrafficEncryptionMode   TrafficEncryptionModeType
+	IPsecAuthenticationMode IPsecAuthenticationModeType
+	IPSecPSK                string
+}
+
+// NeedsTunnelToPeer returns true if Pod traffic to peer Node needs to be
+// forwarded to the tunnel port. When WireGuard is used, the traffic is routed
+// to the WireGuard device by the host instead.
+func (nc *NetworkConfig) NeedsTunnelToPeer(peerIP net.IP, localIP *net.IPNet) bool {
+	return nc.TrafficEncryptionMode != TrafficEncryptionModeWireGuard &&
+		nc.TrafficEncapMode.NeedsEncapToPeer(peerIP, localIP)
 }
 
 // IsIPv4Enabled returns true if the cluster network supports IPv4.
@@ -0,0 +1,75 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrafficEncryptionModeFromStr(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		expBool bool
+		expMode TrafficEncryptionModeType
+	}{
+		{"none-mode-valid", "none", true, TrafficEncryptionModeNone},
+		{"ipsec-mode-valid", "ipsec", true, TrafficEncryptionModeIPSec},
+		{"wireguard-mode-valid", "WireGuard", true, TrafficEncryptionModeWireGuard},
+		{"invalid-str", "wire guard", false, TrafficEncryptionModeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualBool, actualMode := GetTrafficEncryptionModeFromStr(tt.mode)
+			assert.Equal(t, tt.expBool, actualBool, "GetTrafficEncryptionModeFromStr did not return correct boolean")
+			assert.Equal(t, tt.expMode, actualMode, "GetTrafficEncryptionModeFromStr did not return correct traffic type")
+		})
+	}
+}
+
+func TestTrafficEncryptionModeTypeString(t *testing.T) {
+	for _, mode := range GetTrafficEncryptionModes() {
+		ok, parsedMode := GetTrafficEncryptionModeFromStr(mode.String())
+		assert.True(t, ok)
+		assert.Equal(t, mode, parsedMode)
+	}
+}
+
+func TestNetworkConfigNeedsTunnelToPeer(t *testing.T) {
+	_, localIP, _ := net.ParseCIDR("192.168.0.10/24")
+	tests := []struct {
+		name           string
+		encapMode      TrafficEncapModeType
+		encryptionMode TrafficEncryptionModeType
+		peerIP         string
+		expected       bool
+	}{
+		{"encap", TrafficEncapModeEncap, TrafficEncryptionModeNone, "192.168.0.11", true},
+		{"encap-ipsec", TrafficEncapModeEncap, TrafficEncryptionModeIPSec, "192.168.0.11", true},
+		{"encap-wireguard", TrafficEncapModeEncap, TrafficEncryptionModeWireGuard, "192.168.0.11", false},
+		{"hybrid-remote-subnet", TrafficEncapModeHybrid, TrafficEncryptionModeNone, "192.168.1.11", true},
+		{"hybrid-remote-subnet-wireguard", TrafficEncapModeHybrid, TrafficEncryptionModeWireGuard, "192.168.1.11", false},
+		{"noencap", TrafficEncapModeNoEncap, TrafficEncryptionModeNone, "192.168.1.11", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkConfig := &NetworkConfig{TrafficEncapMode: tt.encapMode, TrafficEncryptionMode: tt.encryptionMode}
+			assert.Equal(t, tt.expected, networkConfig.NeedsTunnelToPeer(net.ParseIP(tt.peerIP), localIP))
+		})
+	}
+}
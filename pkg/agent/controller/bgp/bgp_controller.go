@@ -0,0 +1,377 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/bgp"
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	crdlisters "github.com/vmware-tanzu/antrea/pkg/client/listers/core/v1alpha2"
+)
+
+const (
+	controllerName = "AntreaAgentBGPController"
+	// How long to wait before retrying the processing of a BGP configuration change.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// There is a single BGP speaker per Node, so all the changes are
+	// processed by a single worker with the same key.
+	workerItemKey = "key"
+)
+
+// newSpeaker creates the BGP speaker. Declared as a variable for testing.
+var newSpeaker = func(localASN uint32, routerID net.IP) bgp.Interface {
+	return bgp.NewSpeaker(localASN, routerID)
+}
+
+// Controller runs the BGP speaker of the Node according to the BGPPolicy
+// selecting it: it advertises the PodCIDRs of the Node and the ingress IPs of
+// the Services to the BGP peers of the policy.
+type Controller struct {
+	nodeConfig            *config.NodeConfig
+	bgpPolicyLister       crdlisters.BGPPolicyLister
+	bgpPolicyListerSynced cache.InformerSynced
+	nodeLister            corelisters.NodeLister
+	nodeListerSynced      cache.InformerSynced
+	serviceLister         corelisters.ServiceLister
+	serviceListerSynced   cache.InformerSynced
+	endpointsLister       corelisters.EndpointsLister
+	endpointsListerSynced cache.InformerSynced
+	queue                 workqueue.RateLimitingInterface
+
+	// speaker is the running BGP speaker, nil if no BGPPolicy selects the
+	// Node. It is only accessed by the single worker.
+	speaker    bgp.Interface
+	speakerASN int64
+}
+
+// NewBGPController returns a Controller which processes the BGPPolicies, the
+// local Node, the Services and their Endpoints.
+func NewBGPController(
+	nodeConfig *config.NodeConfig,
+	informerFactory informers.SharedInformerFactory,
+	crdInformerFactory crdinformers.SharedInformerFactory) *Controller {
+	bgpPolicyInformer := crdInformerFactory.Core().V1alpha2().BGPPolicies()
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	serviceInformer := informerFactory.Core().V1().Services()
+	endpointsInformer := informerFactory.Core().V1().Endpoints()
+	c := &Controller{
+		nodeConfig:            nodeConfig,
+		bgpPolicyLister:       bgpPolicyInformer.Lister(),
+		bgpPolicyListerSynced: bgpPolicyInformer.Informer().HasSynced,
+		nodeLister:            nodeInformer.Lister(),
+		nodeListerSynced:      nodeInformer.Informer().HasSynced,
+		serviceLister:         serviceInformer.Lister(),
+		serviceListerSynced:   serviceInformer.Informer().HasSynced,
+		endpointsLister:       endpointsInformer.Lister(),
+		endpointsListerSynced: endpointsInformer.Informer().HasSynced,
+		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "bgp"),
+	}
+	enqueue := func(obj interface{}) {
+		c.queue.Add(workerItemKey)
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, cur interface{}) {
+			enqueue(cur)
+		},
+		DeleteFunc: enqueue,
+	}
+	bgpPolicyInformer.Informer().AddEventHandler(handler)
+	serviceInformer.Informer().AddEventHandler(handler)
+	// The Endpoints determine whether the IPs of the Services with the Local
+	// external traffic policy are advertised.
+	endpointsInformer.Informer().AddEventHandler(handler)
+	// Only the labels of the local Node matter.
+	nodeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if node, ok := obj.(*corev1.Node); ok {
+				return node.Name == nodeConfig.Name
+			}
+			return false
+		},
+		Handler: handler,
+	})
+	return c
+}
+
+// Run starts the worker of the controller and blocks until stopCh is closed,
+// at which point the BGP sessions are closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.bgpPolicyListerSynced, c.nodeListerSynced, c.serviceListerSynced, c.endpointsListerSynced) {
+		return
+	}
+	c.queue.Add(workerItemKey)
+
+	workerDoneCh := make(chan struct{})
+	go func() {
+		defer close(workerDoneCh)
+		c.worker()
+	}()
+	<-stopCh
+	// Wait for the worker to exit before closing the sessions.
+	c.queue.ShutDown()
+	<-workerDoneCh
+	if c.speaker != nil {
+		c.speaker.Stop()
+	}
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if err := c.syncBGP(); err == nil {
+		c.queue.Forget(obj)
+	} else {
+		c.queue.AddRateLimited(obj)
+		klog.Errorf("Error syncing BGP configuration, requeuing. Error: %v", err)
+	}
+	return true
+}
+
+// getEffectivePolicy returns the oldest BGPPolicy selecting the Node, or nil if
+// no BGPPolicy selects it.
+func (c *Controller) getEffectivePolicy() (*v1alpha2.BGPPolicy, error) {
+	node, err := c.nodeLister.Get(c.nodeConfig.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting Node %s: %v", c.nodeConfig.Name, err)
+	}
+	policies, err := c.bgpPolicyLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var selected []*v1alpha2.BGPPolicy
+	for _, policy := range policies {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.NodeSelector)
+		if err != nil {
+			klog.Errorf("Invalid nodeSelector in BGPPolicy %s: %v", policy.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			selected = append(selected, policy)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		ti, tj := selected[i].CreationTimestamp, selected[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	if len(selected) > 1 {
+		klog.Warningf("Node %s is selected by multiple BGPPolicies, using the oldest one %s", c.nodeConfig.Name, selected[0].Name)
+	}
+	return selected[0], nil
+}
+
+func (c *Controller) syncBGP() error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing BGP configuration. (%v)", time.Since(startTime))
+	}()
+
+	policy, err := c.getEffectivePolicy()
+	if err != nil {
+		return err
+	}
+	if policy == nil || policy.Spec.LocalASN != c.speakerASN {
+		if c.speaker != nil {
+			klog.Infof("Stopping the BGP speaker with AS number %d", c.speakerASN)
+			c.speaker.Stop()
+			c.speaker = nil
+			c.speakerASN = 0
+		}
+	}
+	if policy == nil {
+		return nil
+	}
+	if policy.Spec.LocalASN < 1 || policy.Spec.LocalASN > 4294967295 {
+		// Retrying would not help, the BGPPolicy must be updated.
+		klog.Errorf("Invalid localASN %d in BGPPolicy %s", policy.Spec.LocalASN, policy.Name)
+		return nil
+	}
+	if c.speaker == nil {
+		routerID := c.getRouterID()
+		klog.Infof("Starting the BGP speaker with AS number %d and router ID %s", policy.Spec.LocalASN, routerID)
+		c.speaker = newSpeaker(uint32(policy.Spec.LocalASN), routerID)
+		c.speakerASN = policy.Spec.LocalASN
+	}
+
+	prefixes, err := c.getPrefixes(&policy.Spec.Advertisements)
+	if err != nil {
+		return err
+	}
+	c.speaker.SetPrefixes(prefixes)
+	c.speaker.SetPeers(getPeers(policy))
+	return nil
+}
+
+// getRouterID returns the IPv4 address of the Node as the router ID, or an ID
+// derived from the Node name when the Node has no IPv4 address.
+func (c *Controller) getRouterID() net.IP {
+	if c.nodeConfig.NodeIPAddr != nil {
+		if ip := c.nodeConfig.NodeIPAddr.IP.To4(); ip != nil {
+			return ip
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(c.nodeConfig.Name))
+	routerID := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(routerID, h.Sum32())
+	return routerID
+}
+
+func getPeers(policy *v1alpha2.BGPPolicy) []bgp.PeerConfig {
+	var peers []bgp.PeerConfig
+	for _, peer := range policy.Spec.BGPPeers {
+		address := net.ParseIP(peer.Address)
+		if address == nil {
+			klog.Errorf("Ignoring BGP peer with invalid address %s in BGPPolicy %s", peer.Address, policy.Name)
+			continue
+		}
+		if peer.ASN < 1 || peer.ASN > 4294967295 {
+			klog.Errorf("Ignoring BGP peer %s with invalid ASN %d in BGPPolicy %s", peer.Address, peer.ASN, policy.Name)
+			continue
+		}
+		peers = append(peers, bgp.PeerConfig{
+			Address:  address,
+			Port:     peer.Port,
+			ASN:      uint32(peer.ASN),
+			HoldTime: time.Duration(peer.HoldTime) * time.Second,
+		})
+	}
+	return peers
+}
+
+// getPrefixes returns the prefixes to advertise: the PodCIDRs of the Node and
+// the host routes to the selected Service IPs. The IPs of a Service with the
+// Local external traffic policy are only advertised by the Nodes running one of
+// its Endpoints, as the other Nodes drop the external traffic to it.
+func (c *Controller) getPrefixes(advertisements *v1alpha2.Advertisements) ([]net.IPNet, error) {
+	var prefixes []net.IPNet
+	if advertisements.Pod != nil {
+		for _, podCIDR := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
+			if podCIDR != nil {
+				prefixes = append(prefixes, *podCIDR)
+			}
+		}
+	}
+	if advertisements.Service == nil {
+		return prefixes, nil
+	}
+	var advertiseLoadBalancerIPs, advertiseExternalIPs bool
+	for _, ipType := range advertisements.Service.IPTypes {
+		switch ipType {
+		case v1alpha2.ServiceIPTypeLoadBalancerIP:
+			advertiseLoadBalancerIPs = true
+		case v1alpha2.ServiceIPTypeExternalIP:
+			advertiseExternalIPs = true
+		}
+	}
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	serviceIPs := map[string]net.IP{}
+	addServiceIP := func(ipStr string) {
+		if ip := net.ParseIP(ipStr); ip != nil {
+			serviceIPs[ip.String()] = ip
+		}
+	}
+	for _, service := range services {
+		if service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal {
+			hasLocalEndpoint, err := c.hasLocalEndpoint(service)
+			if err != nil {
+				return nil, err
+			}
+			if !hasLocalEndpoint {
+				continue
+			}
+		}
+		if advertiseLoadBalancerIPs && service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				addServiceIP(ingress.IP)
+			}
+		}
+		if advertiseExternalIPs {
+			for _, externalIP := range service.Spec.ExternalIPs {
+				addServiceIP(externalIP)
+			}
+		}
+	}
+	for _, ip := range serviceIPs {
+		if ip.To4() != nil {
+			prefixes = append(prefixes, net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)})
+		} else {
+			prefixes = append(prefixes, net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+		}
+	}
+	return prefixes, nil
+}
+
+// hasLocalEndpoint returns whether one of the ready Endpoints of the Service
+// runs on the Node.
+func (c *Controller) hasLocalEndpoint(service *corev1.Service) (bool, error) {
+	endpoints, err := c.endpointsLister.Endpoints(service.Namespace).Get(service.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.NodeName != nil && *address.NodeName == c.nodeConfig.Name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/bgp"
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+type fakeSpeaker struct {
+	asn      uint32
+	routerID net.IP
+	peers    []bgp.PeerConfig
+	prefixes []string
+	stopped  bool
+}
+
+func (s *fakeSpeaker) SetPeers(peers []bgp.PeerConfig) {
+	s.peers = peers
+}
+
+func (s *fakeSpeaker) SetPrefixes(prefixes []net.IPNet) {
+	s.prefixes = nil
+	for _, prefix := range prefixes {
+		s.prefixes = append(s.prefixes, prefix.String())
+	}
+	sort.Strings(s.prefixes)
+}
+
+func (s *fakeSpeaker) GetPeerStatus() []bgp.PeerStatus {
+	return nil
+}
+
+func (s *fakeSpeaker) Stop() {
+	s.stopped = true
+}
+
+type fakeController struct {
+	*Controller
+	clientset          *fake.Clientset
+	crdClientset       *fakeversioned.Clientset
+	informerFactory    informers.SharedInformerFactory
+	crdInformerFactory crdinformers.SharedInformerFactory
+	speakers           []*fakeSpeaker
+}
+
+func newController(t *testing.T, objects ...*v1alpha2.BGPPolicy) (*fakeController, func()) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"rack": "r1"}}}
+	clientset := fake.NewSimpleClientset(node)
+	crdClientset := fakeversioned.NewSimpleClientset()
+	for _, obj := range objects {
+		crdClientset.Tracker().Add(obj)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientset, 12*time.Hour)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClientset, 12*time.Hour)
+	_, podIPv4CIDR, _ := net.ParseCIDR("10.10.1.0/24")
+	nodeConfig := &config.NodeConfig{
+		Name:        "node1",
+		PodIPv4CIDR: podIPv4CIDR,
+		NodeIPAddr:  &net.IPNet{IP: net.ParseIP("192.168.0.11"), Mask: net.CIDRMask(24, 32)},
+	}
+	c := &fakeController{
+		Controller:         NewBGPController(nodeConfig, informerFactory, crdInformerFactory),
+		clientset:          clientset,
+		crdClientset:       crdClientset,
+		informerFactory:    informerFactory,
+		crdInformerFactory: crdInformerFactory,
+	}
+	originalNewSpeaker := newSpeaker
+	newSpeaker = func(localASN uint32, routerID net.IP) bgp.Interface {
+		speaker := &fakeSpeaker{asn: localASN, routerID: routerID}
+		c.speakers = append(c.speakers, speaker)
+		return speaker
+	}
+	stopCh := make(chan struct{})
+	informerFactory.Start(stopCh)
+	crdInformerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	crdInformerFactory.WaitForCacheSync(stopCh)
+	return c, func() {
+		close(stopCh)
+		newSpeaker = originalNewSpeaker
+	}
+}
+
+func newBGPPolicy(name string, creationTime time.Time, localASN int64, nodeLabels map[string]string) *v1alpha2.BGPPolicy {
+	return &v1alpha2.BGPPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(creationTime)},
+		Spec: v1alpha2.BGPPolicySpec{
+			NodeSelector: metav1.LabelSelector{MatchLabels: nodeLabels},
+			LocalASN:     localASN,
+			Advertisements: v1alpha2.Advertisements{
+				Pod: &v1alpha2.PodAdvertisement{},
+			},
+			BGPPeers: []v1alpha2.BGPPeer{
+				{Address: "192.168.0.1", ASN: 65001},
+				{Address: "invalid", ASN: 65001},
+				{Address: "192.168.0.2", Port: 1179, ASN: 65002, HoldTime: 30},
+			},
+		},
+	}
+}
+
+// waitForCache waits for the condition to be true on the informer caches.
+func waitForCache(t *testing.T, condition func() bool) {
+	err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return condition(), nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSyncBGP(t *testing.T) {
+	now := time.Now()
+	c, closeFn := newController(t,
+		newBGPPolicy("newer", now, 65100, map[string]string{"rack": "r1"}),
+		newBGPPolicy("older", now.Add(-time.Hour), 65000, map[string]string{"rack": "r1"}),
+		newBGPPolicy("other-rack", now.Add(-2*time.Hour), 65200, map[string]string{"rack": "r2"}),
+	)
+	defer closeFn()
+
+	require.NoError(t, c.syncBGP())
+	require.Len(t, c.speakers, 1)
+	speaker := c.speakers[0]
+	assert.Equal(t, uint32(65000), speaker.asn)
+	assert.Equal(t, "192.168.0.11", speaker.routerID.String())
+	assert.Equal(t, []bgp.PeerConfig{
+		{Address: net.ParseIP("192.168.0.1"), ASN: 65001},
+		{Address: net.ParseIP("192.168.0.2"), Port: 1179, ASN: 65002, HoldTime: 30 * time.Second},
+	}, speaker.peers)
+	assert.Equal(t, []string{"10.10.1.0/24"}, speaker.prefixes)
+
+	// Advertise the Service IPs.
+	policy, err := c.crdClientset.CoreV1alpha2().BGPPolicies().Get(context.TODO(), "older", metav1.GetOptions{})
+	require.NoError(t, err)
+	policy.Spec.Advertisements.Service = &v1alpha2.ServiceAdvertisement{
+		IPTypes: []v1alpha2.ServiceIPType{v1alpha2.ServiceIPTypeLoadBalancerIP},
+	}
+	_, err = c.crdClientset.CoreV1alpha2().BGPPolicies().Update(context.TODO(), policy, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:        corev1.ServiceTypeLoadBalancer,
+			ExternalIPs: []string{"172.16.0.20"},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{IP: "172.16.0.10"}, {IP: "fd00:172:16::10"}, {Hostname: "lb.example.com"}},
+		}},
+	}
+	_, err = c.clientset.CoreV1().Services("default").Create(context.TODO(), service, metav1.CreateOptions{})
+	require.NoError(t, err)
+	waitForCache(t, func() bool {
+		services, _ := c.serviceLister.List(labels.Everything())
+		policy, _ := c.bgpPolicyLister.Get("older")
+		return len(services) == 1 && policy.Spec.Advertisements.Service != nil
+	})
+	require.NoError(t, c.syncBGP())
+	require.Len(t, c.speakers, 1)
+	assert.Equal(t, []string{"10.10.1.0/24", "172.16.0.10/32", "fd00:172:16::10/128"}, speaker.prefixes)
+
+	policy.Spec.Advertisements.Pod = nil
+	policy.Spec.Advertisements.Service.IPTypes = []v1alpha2.ServiceIPType{v1alpha2.ServiceIPTypeExternalIP}
+	_, err = c.crdClientset.CoreV1alpha2().BGPPolicies().Update(context.TODO(), policy, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	waitForCache(t, func() bool {
+		policy, _ := c.bgpPolicyLister.Get("older")
+		return policy.Spec.Advertisements.Pod == nil
+	})
+	require.NoError(t, c.syncBGP())
+	assert.Equal(t, []string{"172.16.0.20/32"}, speaker.prefixes)
+
+	// Moving the Node to another rack restarts the speaker with the AS number
+	// of the other BGPPolicy.
+	node, err := c.clientset.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+	require.NoError(t, err)
+	node.Labels["rack"] = "r2"
+	_, err = c.clientset.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	waitForCache(t, func() bool {
+		node, _ := c.nodeLister.Get("node1")
+		return node.Labels["rack"] == "r2"
+	})
+	require.NoError(t, c.syncBGP())
+	assert.True(t, speaker.stopped)
+	require.Len(t, c.speakers, 2)
+	assert.Equal(t, uint32(65200), c.speakers[1].asn)
+
+	// The speaker is stopped when no BGPPolicy selects the Node.
+	require.NoError(t, c.crdClientset.CoreV1alpha2().BGPPolicies().Delete(context.TODO(), "other-rack", metav1.DeleteOptions{}))
+	waitForCache(t, func() bool {
+		_, err := c.bgpPolicyLister.Get("other-rack")
+		return err != nil
+	})
+	require.NoError(t, c.syncBGP())
+	assert.True(t, c.speakers[1].stopped)
+	assert.Nil(t, c.speaker)
+}
+
+func TestGetPrefixesExternalTrafficPolicyLocal(t *testing.T) {
+	c, closeFn := newController(t)
+	defer closeFn()
+
+	newService := func(name, ingressIP string, policy corev1.ServiceExternalTrafficPolicyType) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, ExternalTrafficPolicy: policy},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: ingressIP}},
+			}},
+		}
+	}
+	newEndpoints := func(name, nodeName string) *corev1.Endpoints {
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.10.2.10", NodeName: &nodeName}},
+			}},
+		}
+	}
+	services := []*corev1.Service{
+		newService("cluster", "172.16.0.10", corev1.ServiceExternalTrafficPolicyTypeCluster),
+		newService("local", "172.16.0.11", corev1.ServiceExternalTrafficPolicyTypeLocal),
+		newService("remote", "172.16.0.12", corev1.ServiceExternalTrafficPolicyTypeLocal),
+		newService("no-endpoints", "172.16.0.13", corev1.ServiceExternalTrafficPolicyTypeLocal),
+	}
+	for _, service := range services {
+		_, err := c.clientset.CoreV1().Services("default").Create(context.TODO(), service, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	for _, endpoints := range []*corev1.Endpoints{newEndpoints("local", "node1"), newEndpoints("remote", "node2")} {
+		_, err := c.clientset.CoreV1().Endpoints("default").Create(context.TODO(), endpoints, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	waitForCache(t, func() bool {
+		services, _ := c.serviceLister.List(labels.Everything())
+		endpoints, _ := c.endpointsLister.List(labels.Everything())
+		return len(services) == 4 && len(endpoints) == 2
+	})
+
+	prefixes, err := c.getPrefixes(&v1alpha2.Advertisements{
+		Service: &v1alpha2.ServiceAdvertisement{IPTypes: []v1alpha2.ServiceIPType{v1alpha2.ServiceIPTypeLoadBalancerIP}},
+	})
+	require.NoError(t, err)
+	var prefixStrs []string
+	for _, prefix := range prefixes {
+		prefixStrs = append(prefixStrs, prefix.String())
+	}
+	assert.ElementsMatch(t, []string{"172.16.0.10/32", "172.16.0.11/32"}, prefixStrs)
+}
+
+func TestGetRouterID(t *testing.T) {
+	c := &Controller{nodeConfig: &config.NodeConfig{
+		Name:       "node1",
+		NodeIPAddr: &net.IPNet{IP: net.ParseIP("fd00::11"), Mask: net.CIDRMask(64, 128)},
+	}}
+	routerID := c.getRouterID()
+	assert.NotNil(t, routerID.To4())
+	assert.Equal(t, routerID, c.getRouterID(), "The router ID should be stable")
+	c.nodeConfig.Name = "node2"
+	assert.NotEqual(t, routerID, c.getRouterID())
+}
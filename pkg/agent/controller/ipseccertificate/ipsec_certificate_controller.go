@@ -0,0 +1,335 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipseccertificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	mathrand "math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/certificate"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+)
+
+const (
+	controllerName = "AntreaAgentIPsecCertificateController"
+	// How long to wait before retrying the processing of the certificate.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// workerItemKey is the only key of the queue, as there is a single
+	// certificate to maintain.
+	workerItemKey = "key"
+
+	// The keys of the OVS other_config used by the OVS IPsec monitor for
+	// certificate authentication.
+	ovsConfigCertificateKey = "certificate"
+	ovsConfigPrivateKeyKey  = "private_key"
+	ovsConfigCACertKey      = "ca_cert"
+)
+
+var (
+	// certificateWaitInterval and certificateWaitTimeout are the interval and
+	// timeout of waiting for the CSR to be signed. Declared as variables for
+	// testing.
+	certificateWaitInterval = 2 * time.Second
+	certificateWaitTimeout  = 5 * time.Minute
+)
+
+// Controller requests a certificate for the IPsec tunnels of the Node from
+// antrea-controller with a CertificateSigningRequest, and configures OVS to use
+// it with the CA certificate published by antrea-controller. The certificate is
+// rotated before it expires.
+type Controller struct {
+	kubeClient      clientset.Interface
+	ovsBridgeClient ovsconfig.OVSBridgeClient
+	nodeName        string
+	// caNamespace is the Namespace of the ConfigMap storing the CA
+	// certificate.
+	caNamespace string
+	// certificateDir is the directory storing the certificate files, which
+	// must be accessible to the OVS IPsec monitor.
+	certificateDir string
+	queue          workqueue.RateLimitingInterface
+}
+
+// NewIPsecCertificateController returns a new *Controller.
+func NewIPsecCertificateController(
+	kubeClient clientset.Interface,
+	ovsBridgeClient ovsconfig.OVSBridgeClient,
+	nodeName string,
+	ovsRunDir string) *Controller {
+	return &Controller{
+		kubeClient:      kubeClient,
+		ovsBridgeClient: ovsBridgeClient,
+		nodeName:        nodeName,
+		caNamespace:     certificate.GetCAConfigMapNamespace(),
+		certificateDir:  filepath.Join(ovsRunDir, "ipsec"),
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "ipsecCertificate"),
+	}
+}
+
+// Run runs the single worker maintaining the certificate until stopCh is
+// closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	c.queue.Add(workerItemKey)
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	rotationDeadline, err := c.syncCertificate()
+	if err != nil {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing IPsec certificate, requeuing. Error: %v", err)
+		return true
+	}
+	c.queue.Forget(key)
+	klog.Infof("IPsec certificate will be rotated at %v", rotationDeadline)
+	c.queue.AddAfter(key, time.Until(rotationDeadline))
+	return true
+}
+
+// syncCertificate makes sure OVS is configured with a valid certificate and
+// the current CA certificate, requesting a new certificate if the existing one
+// is missing or should be rotated. It returns the deadline of rotating the
+// certificate.
+func (c *Controller) syncCertificate() (time.Time, error) {
+	caPEM, err := c.getCACertificate()
+	if err != nil {
+		return time.Time{}, err
+	}
+	otherConfig, err := c.ovsBridgeClient.GetOVSOtherConfig()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting OVS other_config: %v", err)
+	}
+	if deadline, ok := c.checkExistingCertificate(otherConfig, caPEM); ok {
+		return deadline, nil
+	}
+
+	keyPEM, certPEM, err := c.requestCertificate()
+	if err != nil {
+		return time.Time{}, err
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := c.installCertificate(keyPEM, certPEM, caPEM); err != nil {
+		return time.Time{}, err
+	}
+	return nextRotationDeadline(cert), nil
+}
+
+func (c *Controller) getCACertificate() ([]byte, error) {
+	configMap, err := c.kubeClient.CoreV1().ConfigMaps(c.caNamespace).Get(context.TODO(), apis.IPsecCAConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting ConfigMap %s: %v", apis.IPsecCAConfigMapName, err)
+	}
+	caPEM := []byte(configMap.Data[apis.IPsecCAConfigMapKey])
+	if _, err := certutil.ParseCertsPEM(caPEM); err != nil {
+		return nil, fmt.Errorf("error parsing the IPsec CA certificate: %v", err)
+	}
+	return caPEM, nil
+}
+
+// checkExistingCertificate returns the rotation deadline and true if OVS is
+// already configured with a certificate which doesn't need to be rotated, and
+// with the current CA certificate.
+func (c *Controller) checkExistingCertificate(otherConfig map[string]string, caPEM []byte) (time.Time, bool) {
+	certPath, keyPath, caPath := otherConfig[ovsConfigCertificateKey], otherConfig[ovsConfigPrivateKeyKey], otherConfig[ovsConfigCACertKey]
+	if certPath == "" || keyPath == "" || caPath == "" {
+		return time.Time{}, false
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		return time.Time{}, false
+	}
+	existingCAPEM, err := ioutil.ReadFile(caPath)
+	if err != nil || !bytes.Equal(existingCAPEM, caPEM) {
+		return time.Time{}, false
+	}
+	certPEM, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return time.Time{}, false
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil || cert.Subject.CommonName != c.nodeName {
+		return time.Time{}, false
+	}
+	deadline := nextRotationDeadline(cert)
+	if !time.Now().Before(deadline) {
+		return time.Time{}, false
+	}
+	return deadline, true
+}
+
+// requestCertificate generates a private key, and requests a certificate for
+// it with a CertificateSigningRequest. It returns the PEM encoded private key
+// and certificate once the request is signed.
+func (c *Controller) requestCertificate() ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating private key: %v", err)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	requestDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: c.nodeName},
+	}, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating certificate request: %v", err)
+	}
+	signerName := apis.IPsecCSRSignerName
+	csr := &certificatesv1beta1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: fmt.Sprintf("%s-ipsec-", c.nodeName)},
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:    pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateRequestBlockType, Bytes: requestDER}),
+			SignerName: &signerName,
+			Usages:     []certificatesv1beta1.KeyUsage{certificatesv1beta1.UsageIPsecTunnel},
+		},
+	}
+	csr, err = c.kubeClient.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), csr, metav1.CreateOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating CertificateSigningRequest: %v", err)
+	}
+	klog.Infof("Created CertificateSigningRequest %s for the IPsec certificate", csr.Name)
+
+	var certPEM []byte
+	if err := wait.PollImmediate(certificateWaitInterval, certificateWaitTimeout, func() (bool, error) {
+		csr, err := c.kubeClient.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, condition := range csr.Status.Conditions {
+			if condition.Type == certificatesv1beta1.CertificateDenied {
+				return false, fmt.Errorf("CertificateSigningRequest %s is denied: %s", csr.Name, condition.Message)
+			}
+		}
+		if len(csr.Status.Certificate) == 0 {
+			return false, nil
+		}
+		certPEM = csr.Status.Certificate
+		return true, nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("error waiting for CertificateSigningRequest %s to be signed: %v", csr.Name, err)
+	}
+	return keyPEM, certPEM, nil
+}
+
+// installCertificate writes the certificate files and configures OVS to use
+// them. New file names are used every time, so that the OVS IPsec monitor
+// notices the change and reloads the files. The previous files are removed
+// afterwards.
+func (c *Controller) installCertificate(keyPEM, certPEM, caPEM []byte) error {
+	if err := os.MkdirAll(c.certificateDir, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %v", c.certificateDir, err)
+	}
+	suffix := time.Now().Format("20060102150405")
+	keyPath := filepath.Join(c.certificateDir, fmt.Sprintf("%s-%s.key", c.nodeName, suffix))
+	certPath := filepath.Join(c.certificateDir, fmt.Sprintf("%s-%s.crt", c.nodeName, suffix))
+	caPath := filepath.Join(c.certificateDir, fmt.Sprintf("ca-%s.crt", suffix))
+	files := []struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}{
+		{keyPath, keyPEM, 0600},
+		{certPath, certPEM, 0644},
+		{caPath, caPEM, 0644},
+	}
+	for _, f := range files {
+		if err := ioutil.WriteFile(f.path, f.data, f.perm); err != nil {
+			return fmt.Errorf("error writing file %s: %v", f.path, err)
+		}
+	}
+	if err := c.ovsBridgeClient.AddOVSOtherConfig(map[string]interface{}{
+		ovsConfigCertificateKey: certPath,
+		ovsConfigPrivateKeyKey:  keyPath,
+		ovsConfigCACertKey:      caPath,
+	}); err != nil {
+		return fmt.Errorf("error configuring OVS with the IPsec certificate: %v", err)
+	}
+	klog.Infof("Configured OVS with IPsec certificate %s", certPath)
+
+	entries, err := ioutil.ReadDir(c.certificateDir)
+	if err != nil {
+		klog.Errorf("Failed to list directory %s: %v", c.certificateDir, err)
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(c.certificateDir, entry.Name())
+		if path == keyPath || path == certPath || path == caPath {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			klog.Errorf("Failed to remove stale IPsec certificate file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the IPsec certificate: %v", err)
+	}
+	return certs[0], nil
+}
+
+// nextRotationDeadline returns a random time between 70% and 90% of the
+// validity period of the certificate, like kubelet does for its client
+// certificate, so that the certificates of the Nodes are not rotated at the
+// same time.
+func nextRotationDeadline(cert *x509.Certificate) time.Time {
+	totalDuration := float64(cert.NotAfter.Sub(cert.NotBefore))
+	return cert.NotBefore.Add(time.Duration(totalDuration * (0.7 + 0.2*mathrand.Float64())))
+}
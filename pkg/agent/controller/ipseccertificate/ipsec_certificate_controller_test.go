@@ -0,0 +1,208 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipseccertificate
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+	ovsconfigtest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig/testing"
+)
+
+const (
+	testNodeName  = "node1"
+	testNamespace = "kube-system"
+)
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "antrea-ipsec-ca"}, key)
+	require.NoError(t, err)
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: cert.Raw}),
+	}
+}
+
+func (ca *testCA) sign(t *testing.T, csr *certificatesv1beta1.CertificateSigningRequest) []byte {
+	block, _ := pem.Decode(csr.Spec.Request)
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	require.NoError(t, err)
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: request.Subject.CommonName},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageIPSECTunnel},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, request.PublicKey, ca.key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der})
+}
+
+// newFakeClient returns a fake clientset which signs the created CSRs with the
+// provided CA right away, or denies them if ca is nil.
+func newFakeClient(t *testing.T, caPEM []byte, ca *testCA) *fake.Clientset {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: apis.IPsecCAConfigMapName, Namespace: testNamespace},
+		Data:       map[string]string{apis.IPsecCAConfigMapKey: string(caPEM)},
+	}
+	client := fake.NewSimpleClientset(configMap)
+	count := 0
+	client.PrependReactor("create", "certificatesigningrequests", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		csr := action.(k8stesting.CreateAction).GetObject().(*certificatesv1beta1.CertificateSigningRequest)
+		assert.Equal(t, apis.IPsecCSRSignerName, *csr.Spec.SignerName)
+		count++
+		csr.Name = fmt.Sprintf("%s%d", csr.GenerateName, count)
+		if ca == nil {
+			csr.Status.Conditions = []certificatesv1beta1.CertificateSigningRequestCondition{{Type: certificatesv1beta1.CertificateDenied}}
+		} else {
+			csr.Status.Certificate = ca.sign(t, csr)
+		}
+		return false, csr, nil
+	})
+	return client
+}
+
+func newTestController(t *testing.T, client *fake.Clientset, ovsBridgeClient *ovsconfigtest.MockOVSBridgeClient) *Controller {
+	ovsRunDir, err := ioutil.TempDir("", "ipseccertificate")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(ovsRunDir) })
+	c := NewIPsecCertificateController(client, ovsBridgeClient, testNodeName, ovsRunDir)
+	c.caNamespace = testNamespace
+	return c
+}
+
+func TestSyncCertificate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ovsBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(ctrl)
+	ca := newTestCA(t)
+	client := newFakeClient(t, ca.certPEM, ca)
+	c := newTestController(t, client, ovsBridgeClient)
+
+	// The certificate is requested and installed when OVS has no certificate.
+	otherConfig := map[string]string{}
+	ovsBridgeClient.EXPECT().GetOVSOtherConfig().Return(otherConfig, nil)
+	ovsBridgeClient.EXPECT().AddOVSOtherConfig(gomock.Any()).DoAndReturn(func(configs map[string]interface{}) error {
+		for k, v := range configs {
+			otherConfig[k] = v.(string)
+		}
+		return nil
+	})
+	deadline, err := c.syncCertificate()
+	require.NoError(t, err)
+	assert.True(t, deadline.After(time.Now().Add(41*time.Minute)))
+	assert.True(t, deadline.Before(time.Now().Add(55*time.Minute)))
+
+	certPEM, err := ioutil.ReadFile(otherConfig[ovsConfigCertificateKey])
+	require.NoError(t, err)
+	cert, err := parseCertificate(certPEM)
+	require.NoError(t, err)
+	assert.Equal(t, testNodeName, cert.Subject.CommonName)
+	caPEM, err := ioutil.ReadFile(otherConfig[ovsConfigCACertKey])
+	require.NoError(t, err)
+	assert.Equal(t, ca.certPEM, caPEM)
+	keyInfo, err := os.Stat(otherConfig[ovsConfigPrivateKeyKey])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), keyInfo.Mode().Perm())
+
+	// The installed certificate is kept if it's not due for rotation.
+	ovsBridgeClient.EXPECT().GetOVSOtherConfig().Return(otherConfig, nil)
+	_, err = c.syncCertificate()
+	require.NoError(t, err)
+
+	// A new certificate is requested when the CA changes, and the previous
+	// files are removed.
+	newCA := newTestCA(t)
+	configMap, _ := client.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), apis.IPsecCAConfigMapName, metav1.GetOptions{})
+	configMap.Data[apis.IPsecCAConfigMapKey] = string(newCA.certPEM)
+	_, err = client.CoreV1().ConfigMaps(testNamespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	oldCertPath := otherConfig[ovsConfigCertificateKey]
+	// Make sure the new files have different names.
+	time.Sleep(time.Second)
+	ovsBridgeClient.EXPECT().GetOVSOtherConfig().Return(otherConfig, nil)
+	ovsBridgeClient.EXPECT().AddOVSOtherConfig(gomock.Any()).DoAndReturn(func(configs map[string]interface{}) error {
+		for k, v := range configs {
+			otherConfig[k] = v.(string)
+		}
+		return nil
+	})
+	_, err = c.syncCertificate()
+	require.NoError(t, err)
+	caPEM, err = ioutil.ReadFile(otherConfig[ovsConfigCACertKey])
+	require.NoError(t, err)
+	assert.Equal(t, newCA.certPEM, caPEM)
+	_, err = os.Stat(oldCertPath)
+	assert.True(t, os.IsNotExist(err))
+	files, err := ioutil.ReadDir(c.certificateDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+}
+
+func TestSyncCertificateDenied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ovsBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(ctrl)
+	ca := newTestCA(t)
+	client := newFakeClient(t, ca.certPEM, nil)
+	c := newTestController(t, client, ovsBridgeClient)
+
+	ovsBridgeClient.EXPECT().GetOVSOtherConfig().Return(map[string]string{}, nil)
+	_, err := c.syncCertificate()
+	assert.Error(t, err)
+}
+
+func TestNextRotationDeadline(t *testing.T) {
+	notBefore := time.Now()
+	cert := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(100 * time.Hour)}
+	for i := 0; i < 10; i++ {
+		deadline := nextRotationDeadline(cert)
+		assert.False(t, deadline.Before(notBefore.Add(70*time.Hour)))
+		assert.False(t, deadline.After(notBefore.Add(90*time.Hour)))
+	}
+}
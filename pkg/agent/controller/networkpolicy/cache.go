@@ -87,6 +87,11 @@ type rule struct {
 	SourceRef *v1beta.NetworkPolicyReference
 	// EnableLogging is a boolean indicating whether logging is required for Antrea Policies. Always false for K8s NetworkPolicy.
 	EnableLogging bool
+	// RateLimit limits the rate of the traffic matched by this rule. nil for K8s NetworkPolicy.
+	RateLimit *v1beta.RateLimit
+	// L7Protocols restricts the traffic allowed by this rule to the matching application layer requests. nil for K8s
+	// NetworkPolicy.
+	L7Protocols []v1beta.L7Protocol
 }
 
 // hashRule calculates a string based on the rule's content.
@@ -409,15 +414,15 @@ func (c *ruleCache) addAddressGroupLocked(group *v1beta.AddressGroup) error {
 	return nil
 }
 
-// PatchAddressGroup updates a cached *v1beta.AddressGroup.
-// The rules referencing it will be regarded as dirty.
-func (c *ruleCache) PatchAddressGroup(patch *v1beta.AddressGroupPatch) error {
+// PatchAddressGroup updates a cached *v1beta.AddressGroup and returns the
+// patched group. The rules referencing it will be regarded as dirty.
+func (c *ruleCache) PatchAddressGroup(patch *v1beta.AddressGroupPatch) (*v1beta.AddressGroup, error) {
 	c.addressSetLock.Lock()
 	defer c.addressSetLock.Unlock()
 
 	groupMemberSet, exists := c.addressSetByGroup[patch.Name]
 	if !exists {
-		return fmt.Errorf("AddressGroup %v doesn't exist in cache, can't be patched", patch.Name)
+		return nil, fmt.Errorf("AddressGroup %v doesn't exist in cache, can't be patched", patch.Name)
 	}
 	for i := range patch.AddedGroupMembers {
 		groupMemberSet.Insert(&patch.AddedGroupMembers[i])
@@ -427,7 +432,7 @@ func (c *ruleCache) PatchAddressGroup(patch *v1beta.AddressGroupPatch) error {
 	}
 
 	c.onAddressGroupUpdate(patch.Name)
-	return nil
+	return &v1beta.AddressGroup{ObjectMeta: patch.ObjectMeta, GroupMembers: groupMemberSetToSlice(groupMemberSet)}, nil
 }
 
 // DeleteAddressGroup deletes a cached *v1beta.AddressGroup.
@@ -497,15 +502,15 @@ func (c *ruleCache) addAppliedToGroupLocked(group *v1beta.AppliedToGroup) error
 	return nil
 }
 
-// PatchAppliedToGroup updates a cached *v1beta.AppliedToGroupPatch.
-// The rules referencing it will be regarded as dirty.
-func (c *ruleCache) PatchAppliedToGroup(patch *v1beta.AppliedToGroupPatch) error {
+// PatchAppliedToGroup updates a cached *v1beta.AppliedToGroupPatch and returns
+// the patched group. The rules referencing it will be regarded as dirty.
+func (c *ruleCache) PatchAppliedToGroup(patch *v1beta.AppliedToGroupPatch) (*v1beta.AppliedToGroup, error) {
 	c.podSetLock.Lock()
 	defer c.podSetLock.Unlock()
 
 	podSet, exists := c.memberSetByGroup[patch.Name]
 	if !exists {
-		return fmt.Errorf("AppliedToGroup %v doesn't exist in cache, can't be patched", patch.Name)
+		return nil, fmt.Errorf("AppliedToGroup %v doesn't exist in cache, can't be patched", patch.Name)
 	}
 	for i := range patch.AddedGroupMembers {
 		podSet.Insert(&patch.AddedGroupMembers[i])
@@ -514,7 +519,7 @@ func (c *ruleCache) PatchAppliedToGroup(patch *v1beta.AppliedToGroupPatch) error
 		podSet.Delete(&patch.RemovedGroupMembers[i])
 	}
 	c.onAppliedToGroupUpdate(patch.Name)
-	return nil
+	return &v1beta.AppliedToGroup{ObjectMeta: patch.ObjectMeta, GroupMembers: groupMemberSetToSlice(podSet)}, nil
 }
 
 // DeleteAppliedToGroup deletes a cached *v1beta.AppliedToGroup.
@@ -528,6 +533,15 @@ func (c *ruleCache) DeleteAppliedToGroup(group *v1beta.AppliedToGroup) error {
 	return nil
 }
 
+// groupMemberSetToSlice copies the members of a GroupMemberSet to a slice.
+func groupMemberSetToSlice(s v1beta.GroupMemberSet) []v1beta.GroupMember {
+	members := make([]v1beta.GroupMember, 0, len(s))
+	for _, member := range s {
+		members = append(members, *member)
+	}
+	return members
+}
+
 // toRule converts v1beta.NetworkPolicyRule to *rule.
 func toRule(r *v1beta.NetworkPolicyRule, policy *v1beta.NetworkPolicy, maxPriority int32) *rule {
 	rule := &rule{
@@ -543,6 +557,8 @@ func toRule(r *v1beta.NetworkPolicyRule, policy *v1beta.NetworkPolicy, maxPriori
 		PolicyUID:       policy.UID,
 		SourceRef:       policy.SourceRef,
 		EnableLogging:   r.EnableLogging,
+		RateLimit:       r.RateLimit,
+		L7Protocols:     r.L7Protocols,
 	}
 	rule.ID = hashRule(rule)
 	rule.PolicyName = policy.Name
@@ -0,0 +1,159 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// fileStore checkpoints objects of a given type to files in a directory, one file per object named after it, so that
+// they can be restored when the agent restarts while the antrea-controller is unreachable.
+// Each file starts with the SHA-256 digest of the encoded object, which is verified when loading it: a file that
+// was only partially written or corrupted is discarded rather than restoring a wrong object.
+type fileStore struct {
+	fs  afero.Fs
+	dir string
+	// codec encodes and decodes the objects.
+	codec runtime.Codec
+}
+
+func newFileStore(fs afero.Fs, dir string, codec runtime.Codec) (*fileStore, error) {
+	if err := fs.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %v", dir, err)
+	}
+	return &fileStore{fs: fs, dir: dir, codec: codec}, nil
+}
+
+func (s *fileStore) fileName(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	if accessor.GetName() == "" {
+		return "", errors.New("object has no name")
+	}
+	return accessor.GetName(), nil
+}
+
+// save writes the object to its file, replacing the previous version of the object if any.
+func (s *fileStore) save(obj runtime.Object) error {
+	name, err := s.fileName(obj)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := s.codec.Encode(obj, &buf); err != nil {
+		return fmt.Errorf("error encoding object %s: %v", name, err)
+	}
+	digest := sha256.Sum256(buf.Bytes())
+	data := append(digest[:], buf.Bytes()...)
+	// Write to a temporary file first and rename it, so that the previous version is kept intact if the agent
+	// crashes in the middle of writing.
+	path := filepath.Join(s.dir, name)
+	tmpPath := path + ".tmp"
+	if err := afero.WriteFile(s.fs, tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("error writing file %s: %v", tmpPath, err)
+	}
+	if err := s.fs.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error renaming file %s to %s: %v", tmpPath, path, err)
+	}
+	return nil
+}
+
+// delete removes the file of the object if it exists.
+func (s *fileStore) delete(obj runtime.Object) error {
+	name, err := s.fileName(obj)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, name)
+	if err := s.fs.Remove(path); err != nil {
+		if exists, _ := afero.Exists(s.fs, path); exists {
+			return fmt.Errorf("error removing file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// replaceAll saves the provided objects and removes the files of any other objects.
+func (s *fileStore) replaceAll(objs []runtime.Object) error {
+	names := sets.NewString()
+	for _, obj := range objs {
+		if err := s.save(obj); err != nil {
+			return err
+		}
+		name, _ := s.fileName(obj)
+		names.Insert(name)
+	}
+	files, err := afero.ReadDir(s.fs, s.dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory %s: %v", s.dir, err)
+	}
+	for _, file := range files {
+		if file.IsDir() || names.Has(file.Name()) {
+			continue
+		}
+		path := filepath.Join(s.dir, file.Name())
+		if err := s.fs.Remove(path); err != nil {
+			return fmt.Errorf("error removing file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// loadAll reads all the objects from the files. The files which can't be verified or decoded are skipped.
+func (s *fileStore) loadAll() ([]runtime.Object, error) {
+	files, err := afero.ReadDir(s.fs, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %v", s.dir, err)
+	}
+	var objs []runtime.Object
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) == ".tmp" {
+			continue
+		}
+		path := filepath.Join(s.dir, file.Name())
+		data, err := afero.ReadFile(s.fs, path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %v", path, err)
+		}
+		if len(data) < sha256.Size {
+			klog.Errorf("Skipping file %s as it's truncated", path)
+			continue
+		}
+		digest, encoded := data[:sha256.Size], data[sha256.Size:]
+		if actualDigest := sha256.Sum256(encoded); !bytes.Equal(digest, actualDigest[:]) {
+			klog.Errorf("Skipping file %s as its content doesn't match its digest", path)
+			continue
+		}
+		obj, err := runtime.Decode(s.codec, encoded)
+		if err != nil {
+			klog.Errorf("Skipping file %s as it can't be decoded: %v", path, err)
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/scheme"
+)
+
+func newTestFileStore(t *testing.T, fs afero.Fs) *fileStore {
+	serializer := protobuf.NewSerializer(scheme.Scheme, scheme.Scheme)
+	codec := scheme.Codecs.CodecForVersions(serializer, serializer, v1beta2.SchemeGroupVersion, v1beta2.SchemeGroupVersion)
+	s, err := newFileStore(fs, "/var/run/antrea/networkpolicy/addressgroups", codec)
+	require.NoError(t, err)
+	return s
+}
+
+func getGroupNames(objs []runtime.Object) []string {
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.(*v1beta2.AddressGroup).Name)
+	}
+	return names
+}
+
+func TestFileStore(t *testing.T) {
+	s := newTestFileStore(t, afero.NewMemMapFs())
+	group1 := newAddressGroup("group1", []v1beta2.GroupMember{*newAddressGroupMember("1.1.1.1")})
+	group2 := newAddressGroup("group2", []v1beta2.GroupMember{*newAddressGroupMember("2.2.2.2")})
+	group3 := newAddressGroup("group3", []v1beta2.GroupMember{*newAddressGroupMember("3.3.3.3")})
+
+	require.NoError(t, s.save(group1))
+	require.NoError(t, s.save(group2))
+	objs, err := s.loadAll()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"group1", "group2"}, getGroupNames(objs))
+	for _, obj := range objs {
+		group := obj.(*v1beta2.AddressGroup)
+		if group.Name == "group1" {
+			assert.Equal(t, group1.GroupMembers, group.GroupMembers)
+		}
+	}
+
+	updatedGroup1 := newAddressGroup("group1", []v1beta2.GroupMember{*newAddressGroupMember("1.1.1.1"), *newAddressGroupMember("4.4.4.4")})
+	require.NoError(t, s.save(updatedGroup1))
+	require.NoError(t, s.delete(group2))
+	// Deleting a missing object is not an error.
+	require.NoError(t, s.delete(group2))
+	objs, err = s.loadAll()
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	assert.Equal(t, updatedGroup1.GroupMembers, objs[0].(*v1beta2.AddressGroup).GroupMembers)
+
+	require.NoError(t, s.replaceAll([]runtime.Object{group2, group3}))
+	objs, err = s.loadAll()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"group2", "group3"}, getGroupNames(objs))
+}
+
+func TestFileStoreCorruptedFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	s := newTestFileStore(t, fs)
+	group1 := newAddressGroup("group1", []v1beta2.GroupMember{*newAddressGroupMember("1.1.1.1")})
+	group2 := newAddressGroup("group2", []v1beta2.GroupMember{*newAddressGroupMember("2.2.2.2")})
+	group3 := newAddressGroup("group3", []v1beta2.GroupMember{*newAddressGroupMember("3.3.3.3")})
+	require.NoError(t, s.replaceAll([]runtime.Object{group1, group2, group3}))
+
+	// Flip the last byte of group2 and truncate group3.
+	path := s.dir + "/group2"
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, afero.WriteFile(fs, path, data, 0o600))
+	require.NoError(t, afero.WriteFile(fs, s.dir+"/group3", []byte("abc"), 0o600))
+	// A temporary file left by a crash while saving is ignored.
+	require.NoError(t, afero.WriteFile(fs, s.dir+"/group4.tmp", []byte("abc"), 0o600))
+
+	objs, err := s.loadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"group1"}, getGroupNames(objs))
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/l7engine"
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+const (
+	// l7ConnectionIdleTimeout is the duration after which the inspection state of an idle connection is discarded.
+	// The next request of the connection is then inspected with a new state.
+	l7ConnectionIdleTimeout = 10 * time.Minute
+	// l7ConnectionGCInterval is the minimum interval between two removals of the idle connections.
+	l7ConnectionGCInterval = time.Minute
+
+	// tcpFlagFIN, tcpFlagSYN and tcpFlagRST are the FIN, SYN and RST flags in the flags of a TCP header.
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+)
+
+// l7ConnectionKey is the original direction tuple of a TCP connection.
+type l7ConnectionKey struct {
+	srcIP, dstIP     string
+	srcPort, dstPort uint16
+}
+
+// l7Connection is the inspection state of a TCP connection.
+type l7Connection struct {
+	l7engine.Connection
+	lastSeen time.Time
+}
+
+// l7PacketInHandler is the packetin handler of the connections allowed by the ingress rules with application layer
+// protocols. It inspects all the packets sent by the clients of the connections with the L7 engine, which evaluates
+// every request, resumes the packets which are allowed, and denies the connections in OVS once a request is denied.
+type l7PacketInHandler struct {
+	ofClient openflow.Client
+
+	mutex       sync.Mutex
+	connections map[l7ConnectionKey]*l7Connection
+	lastGC      time.Time
+}
+
+func newL7PacketInHandler(ofClient openflow.Client) *l7PacketInHandler {
+	return &l7PacketInHandler{
+		ofClient:    ofClient,
+		connections: map[l7ConnectionKey]*l7Connection{},
+	}
+}
+
+// tcpSegment is the TCP segment of a packetin packet.
+type tcpSegment struct {
+	srcIP, dstIP     net.IP
+	srcPort, dstPort uint16
+	seqNum           uint32
+	flags            uint8
+	// pktLength is the length of the IP packet.
+	pktLength uint16
+	payload   []byte
+}
+
+// HandlePacketIn inspects the payload of the packetin packet with the application layer protocols of the rule which
+// allowed its connection. The packet is resumed if it's allowed, and the connection is denied if one of the requests
+// in the payload is denied. The denied connections are logged with the Antrea-native policy audit logger.
+func (h *l7PacketInHandler) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	if pktIn == nil {
+		return errors.New("empty packetin for L7 inspection")
+	}
+	match := getMatchRegField(pktIn.GetMatches(), uint32(openflow.IngressReg))
+	if match == nil {
+		return errors.New("no rule ID in packetin for L7 inspection")
+	}
+	ruleID, err := getInfoInReg(match, nil)
+	if err != nil {
+		return fmt.Errorf("received error while unloading rule ID from reg: %v", err)
+	}
+	segment, err := getTCPSegment(pktIn)
+	if err != nil {
+		return fmt.Errorf("received error while handling packetin for L7 inspection: %v", err)
+	}
+	protocols := h.ofClient.GetL7ProtocolsFromConjunction(ruleID)
+	if protocols == nil {
+		// The rule has been removed, the connection will be dropped by the default rules after it's flushed.
+		klog.V(2).Infof("No application layer protocols for rule %d, ignoring packet from %s:%d", ruleID, segment.srcIP, segment.srcPort)
+		return nil
+	}
+	verdict, request := h.inspect(segment, protocols)
+	switch verdict {
+	case l7engine.VerdictUndecided:
+		// The packet is dropped and will be inspected when the client retransmits it.
+		klog.V(4).Infof("Dropping out of order packet from %s:%d to %s:%d", segment.srcIP, segment.srcPort, segment.dstIP, segment.dstPort)
+		return nil
+	case l7engine.VerdictAllow:
+		if err := h.ofClient.ResumeL7Packet(pktIn); err != nil {
+			return fmt.Errorf("error when resuming packet from %s:%d to %s:%d: %v", segment.srcIP, segment.srcPort, segment.dstIP, segment.dstPort, err)
+		}
+		if request != "" {
+			klog.V(4).Infof("L7 verdict of rule %d for connection from %s:%d to %s:%d: %s %s", ruleID, segment.srcIP, segment.srcPort, segment.dstIP, segment.dstPort, verdict, request)
+		}
+		return nil
+	}
+	if request == "" {
+		// The connection has already been denied, the packet was sent before the deny flow took effect.
+		return nil
+	}
+	if err := h.ofClient.DenyL7Connection(segment.srcIP, segment.dstIP, segment.srcPort, segment.dstPort); err != nil {
+		return fmt.Errorf("error when denying connection from %s:%d to %s:%d: %v", segment.srcIP, segment.srcPort, segment.dstIP, segment.dstPort, err)
+	}
+	klog.V(4).Infof("L7 verdict of rule %d for connection from %s:%d to %s:%d: %s %s", ruleID, segment.srcIP, segment.srcPort, segment.dstIP, segment.dstPort, verdict, request)
+	if AntreaPolicyLogger != nil {
+		tableName := openflow.GetFlowTableName(binding.TableIDType(pktIn.TableId))
+		npRef, ofPriority := h.ofClient.GetPolicyInfoFromConjunction(ruleID)
+		AntreaPolicyLogger.Printf("%s %s %s %s SRC: %s DEST: %s %d %s %s", tableName, npRef, openflow.DispositionToString[openflow.DispositionDrop], ofPriority, segment.srcIP, segment.dstIP, segment.pktLength, "TCP", request)
+	}
+	return nil
+}
+
+// inspect evaluates the TCP segment with the inspection state of its connection. A new state is created when the
+// client sends a SYN, as the tuple of a closed connection can be reused by a new one, and the initial sequence number
+// of the connection is learned from the SYN. The state is removed when the client resets the connection or once its
+// FIN is inspected, and the states of the idle connections are removed periodically.
+func (h *l7PacketInHandler) inspect(segment *tcpSegment, protocols []v1beta2.L7Protocol) (l7engine.Verdict, string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	now := time.Now()
+	if now.Sub(h.lastGC) >= l7ConnectionGCInterval {
+		for key, conn := range h.connections {
+			if now.Sub(conn.lastSeen) >= l7ConnectionIdleTimeout {
+				delete(h.connections, key)
+			}
+		}
+		h.lastGC = now
+	}
+	key := l7ConnectionKey{srcIP: segment.srcIP.String(), dstIP: segment.dstIP.String(), srcPort: segment.srcPort, dstPort: segment.dstPort}
+	conn, ok := h.connections[key]
+	if !ok {
+		if segment.flags&tcpFlagSYN == 0 && len(segment.payload) == 0 {
+			// The segment of an unknown connection without payload, e.g. the last ACK of a closed connection, doesn't
+			// need a state.
+			return l7engine.VerdictAllow, ""
+		}
+		// The SYN of the connection wasn't received, e.g. the connection was established before the agent started, so
+		// its stream is inspected from this segment.
+		conn = &l7Connection{}
+		h.connections[key] = conn
+	}
+	conn.lastSeen = now
+	seqNum := segment.seqNum
+	if segment.flags&tcpFlagSYN != 0 {
+		conn.Start(seqNum)
+		// The SYN takes one sequence number, the payload of the segment, if any, follows it.
+		seqNum++
+	}
+	verdict, request := conn.Inspect(seqNum, segment.payload, protocols)
+	// An out of order FIN is dropped, the state is kept to inspect the missing segments and the retransmitted FIN.
+	if segment.flags&tcpFlagRST != 0 || (segment.flags&tcpFlagFIN != 0 && verdict != l7engine.VerdictUndecided) {
+		delete(h.connections, key)
+	}
+	return verdict, request
+}
+
+// getTCPSegment returns the addresses, the ports and the payload of the TCP segment of the packetin packet.
+func getTCPSegment(pktIn *ofctrl.PacketIn) (*tcpSegment, error) {
+	var srcIP, dstIP net.IP
+	var data util.Message
+	var length int
+	var pktLength uint16
+	switch ipPacket := pktIn.Data.Data.(type) {
+	case *protocol.IPv4:
+		if ipPacket.Protocol != protocol.Type_TCP {
+			return nil, errors.New("not a TCP packet")
+		}
+		srcIP, dstIP, data = ipPacket.NWSrc, ipPacket.NWDst, ipPacket.Data
+		// The frame may be padded after the IP packet.
+		length = int(ipPacket.Length) - int(ipPacket.IHL)*4
+		pktLength = ipPacket.Length
+	case *protocol.IPv6:
+		if ipPacket.NextHeader != protocol.Type_TCP {
+			return nil, errors.New("not a TCP packet")
+		}
+		srcIP, dstIP, data = ipPacket.NWSrc, ipPacket.NWDst, ipPacket.Data
+		length = -1
+		pktLength = ipPacket.Length + 40
+	default:
+		return nil, errors.New("not an IP packet")
+	}
+	if data == nil {
+		return nil, errors.New("no IP payload")
+	}
+	b, err := data.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if length >= 0 && length < len(b) {
+		b = b[:length]
+	}
+	tcp := new(protocol.TCP)
+	if err := tcp.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	headerLen := int(tcp.HdrLen) * 4
+	if headerLen < 20 || headerLen > len(b) {
+		return nil, fmt.Errorf("invalid TCP header length %d", headerLen)
+	}
+	return &tcpSegment{
+		srcIP:     srcIP,
+		dstIP:     dstIP,
+		srcPort:   tcp.PortSrc,
+		dstPort:   tcp.PortDst,
+		seqNum:    tcp.SeqNum,
+		flags:     tcp.Code,
+		pktLength: pktLength,
+		payload:   b[headerLen:],
+	}, nil
+}
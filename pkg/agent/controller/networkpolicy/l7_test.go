@@ -0,0 +1,241 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	openflowtest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+// newL7PacketIn returns a packetin of a TCP segment with the provided sequence number, flags and payload, sent for the
+// rule with the provided ID.
+func newL7PacketIn(ruleID uint32, seqNum uint32, flags uint8, payload string) *ofctrl.PacketIn {
+	tcp := &protocol.TCP{PortSrc: 34567, PortDst: 80, SeqNum: seqNum, HdrLen: 5, Code: flags, Data: []byte(payload)}
+	segment, _ := tcp.MarshalBinary()
+	ipLength := uint16(20 + len(segment))
+	// Pad the frame to the minimum Ethernet frame size.
+	for len(segment) < 26 {
+		segment = append(segment, 0)
+	}
+	pktIn := &ofctrl.PacketIn{
+		TableId: uint8(openflow.IngressL7Table),
+		Data: protocol.Ethernet{
+			Ethertype: 0x0800,
+			Data: &protocol.IPv4{
+				IHL:      5,
+				Length:   ipLength,
+				Protocol: protocol.Type_TCP,
+				NWSrc:    net.ParseIP("10.10.0.1").To4(),
+				NWDst:    net.ParseIP("10.10.0.2").To4(),
+				Data:     util.NewBuffer(segment),
+			},
+		},
+	}
+	pktIn.Match.Fields = append(pktIn.Match.Fields, *openflow13.NewRegMatchField(int(openflow.IngressReg), ruleID, nil))
+	return pktIn
+}
+
+type l7TestSegment struct {
+	seqNum  uint32
+	flags   uint8
+	payload string
+}
+
+func TestL7PacketInHandler(t *testing.T) {
+	protocols := []v1beta2.L7Protocol{{HTTP: &v1beta2.HTTPProtocol{Method: "GET"}}}
+	srcIP, dstIP := net.ParseIP("10.10.0.1").To4(), net.ParseIP("10.10.0.2").To4()
+	allowed := "GET / HTTP/1.1\r\nHost: web\r\n\r\n"
+	denied := "DELETE / HTTP/1.1\r\nHost: web\r\n\r\n"
+	deniedLog := "IngressL7 AntreaNetworkPolicy:ns1/web Drop 44900 SRC: 10.10.0.1 DEST: 10.10.0.2 72 TCP HTTP DELETE web/\n"
+	tests := []struct {
+		name        string
+		segments    []l7TestSegment
+		expectCalls func(mockOFClient *openflowtest.MockClientMockRecorder)
+		expectedLog string
+	}{
+		{
+			name:     "pure ACK",
+			segments: []l7TestSegment{{1000, 0x10, ""}},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "allowed request",
+			segments: []l7TestSegment{{1000, 0x18, allowed}},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "denied request",
+			segments: []l7TestSegment{{1000, 0x18, denied}},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols)
+				mockOFClient.DenyL7Connection(srcIP, dstIP, uint16(34567), uint16(80)).Return(nil)
+				mockOFClient.GetPolicyInfoFromConjunction(uint32(5)).Return("AntreaNetworkPolicy:ns1/web", "44900")
+			},
+			expectedLog: deniedLog,
+		},
+		{
+			name: "denied keep-alive request",
+			segments: []l7TestSegment{
+				{1000, 0x18, allowed},
+				{1000 + uint32(len(allowed)), 0x18, denied},
+				{1000 + uint32(len(allowed)+len(denied)), 0x11, ""},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(3)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil)
+				mockOFClient.DenyL7Connection(srcIP, dstIP, uint16(34567), uint16(80)).Return(nil)
+				mockOFClient.GetPolicyInfoFromConjunction(uint32(5)).Return("AntreaNetworkPolicy:ns1/web", "44900")
+			},
+			expectedLog: deniedLog,
+		},
+		{
+			name: "out of order request",
+			segments: []l7TestSegment{
+				{999, 0x02, ""},
+				{1000, 0x10, ""},
+				{1010, 0x18, allowed},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(3)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(2)
+			},
+		},
+		{
+			// The first data segment received is the second one of the stream, it must not be inspected as the
+			// beginning of a request.
+			name: "out of order first data segment",
+			segments: []l7TestSegment{
+				{999, 0x02, ""},
+				{1000 + 8, 0x18, allowed[8:]},
+				{1000, 0x10, allowed[:8]},
+				{1000 + 8, 0x18, allowed[8:]},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(4)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(3)
+			},
+		},
+		{
+			name: "reset connection",
+			segments: []l7TestSegment{
+				{1000, 0x18, allowed},
+				{1000 + uint32(len(allowed)), 0x14, ""},
+				{5000, 0x18, allowed},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(3)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(3)
+			},
+		},
+		{
+			name: "closed connection",
+			segments: []l7TestSegment{
+				{1000, 0x18, allowed},
+				{1000 + uint32(len(allowed)), 0x11, ""},
+				{1001 + uint32(len(allowed)), 0x10, ""},
+				{5000, 0x18, allowed},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(4)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(4)
+			},
+		},
+		{
+			name: "out of order FIN",
+			segments: []l7TestSegment{
+				{999, 0x02, ""},
+				{1000 + uint32(len(allowed)), 0x11, ""},
+				{1000, 0x18, allowed},
+				{1000 + uint32(len(allowed)), 0x11, ""},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(4)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(3)
+			},
+		},
+		{
+			// A new connection reusing the tuple of a connection whose FIN or RST wasn't seen, e.g. after the agent
+			// restarted, is inspected from its own initial sequence number.
+			name: "reused tuple",
+			segments: []l7TestSegment{
+				{1000, 0x18, allowed[:8]},
+				{5000, 0x02, ""},
+				{5001, 0x18, allowed},
+			},
+			expectCalls: func(mockOFClient *openflowtest.MockClientMockRecorder) {
+				mockOFClient.GetL7ProtocolsFromConjunction(uint32(5)).Return(protocols).Times(3)
+				mockOFClient.ResumeL7Packet(gomock.Any()).Return(nil).Times(3)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockOFClient := openflowtest.NewMockClient(controller)
+			tt.expectCalls(mockOFClient.EXPECT())
+			var logs bytes.Buffer
+			AntreaPolicyLogger = log.New(&logs, "", 0)
+			defer func() { AntreaPolicyLogger = nil }()
+
+			h := newL7PacketInHandler(mockOFClient)
+			for _, segment := range tt.segments {
+				require.NoError(t, h.HandlePacketIn(newL7PacketIn(5, segment.seqNum, segment.flags, segment.payload)))
+			}
+			assert.Equal(t, tt.expectedLog, logs.String())
+		})
+	}
+}
+
+func TestL7PacketInHandlerIdleConnections(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockOFClient := openflowtest.NewMockClient(controller)
+	mockOFClient.EXPECT().GetL7ProtocolsFromConjunction(uint32(5)).Return([]v1beta2.L7Protocol{{HTTP: &v1beta2.HTTPProtocol{}}}).Times(2)
+	mockOFClient.EXPECT().ResumeL7Packet(gomock.Any()).Return(nil).Times(2)
+
+	h := newL7PacketInHandler(mockOFClient)
+	idleKey := l7ConnectionKey{srcIP: "10.10.0.3", dstIP: "10.10.0.2", srcPort: 34567, dstPort: 80}
+	h.connections[idleKey] = &l7Connection{lastSeen: time.Now().Add(-l7ConnectionIdleTimeout)}
+	require.NoError(t, h.HandlePacketIn(newL7PacketIn(5, 1000, 0x02, "")))
+	assert.NotContains(t, h.connections, idleKey)
+	assert.Len(t, h.connections, 1)
+
+	// The idle connections are only removed once per interval.
+	h.connections[idleKey] = &l7Connection{lastSeen: time.Now().Add(-l7ConnectionIdleTimeout)}
+	require.NoError(t, h.HandlePacketIn(newL7PacketIn(5, 1001, 0x10, "")))
+	assert.Len(t, h.connections, 2)
+}
@@ -17,12 +17,17 @@ package networkpolicy
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/util/workqueue"
@@ -31,7 +36,9 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent"
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	"github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/scheme"
 	"github.com/vmware-tanzu/antrea/pkg/querier"
 )
 
@@ -43,6 +50,17 @@ const (
 	defaultWorkers = 4
 )
 
+var (
+	// Declared as variables for testing.
+	defaultFS = afero.NewOsFs()
+	// checkpointDir is the directory where the NetworkPolicies, AppliedToGroups and AddressGroups received from the
+	// antrea-controller are checkpointed.
+	checkpointDir = "/var/run/antrea/networkpolicy"
+	// restoreCheckpointTimeout is how long to wait for the watchers to complete full sync before restoring the
+	// checkpointed objects, e.g. when the antrea-controller is unreachable after the agent restarts.
+	restoreCheckpointTimeout = 10 * time.Second
+)
+
 // Controller is responsible for watching Antrea AddressGroups, AppliedToGroups,
 // and NetworkPolicies, feeding them to ruleCache, getting dirty rules from
 // ruleCache, invoking reconciler to reconcile them.
@@ -75,6 +93,9 @@ type Controller struct {
 	reconciler Reconciler
 	// ofClient registers packetin for Antrea Policy logging.
 	ofClient openflow.Client
+	// routeClient receives the packets logged by the policy rules enforced on the host network for Antrea Policy
+	// logging.
+	routeClient route.Interface
 	// statusManager syncs NetworkPolicy statuses with the antrea-controller.
 	// It's only for Antrea NetworkPolicies.
 	statusManager         StatusManager
@@ -82,12 +103,18 @@ type Controller struct {
 	appliedToGroupWatcher *watcher
 	addressGroupWatcher   *watcher
 	fullSyncGroup         sync.WaitGroup
+	// The file stores checkpoint the objects received by the watchers, so that the Pods on this Node keep being
+	// protected by the latest NetworkPolicies when the agent restarts while the antrea-controller is unreachable.
+	networkPolicyStore  *fileStore
+	appliedToGroupStore *fileStore
+	addressGroupStore   *fileStore
 }
 
 // NewNetworkPolicyController returns a new *Controller.
 func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	ofClient openflow.Client,
 	ifaceStore interfacestore.InterfaceStore,
+	routeClient route.Interface,
 	nodeName string,
 	podUpdates <-chan v1beta2.PodReference,
 	antreaPolicyEnabled bool,
@@ -95,8 +122,9 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	c := &Controller{
 		antreaClientProvider: antreaClientGetter,
 		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "networkpolicyrule"),
-		reconciler:           newReconciler(ofClient, ifaceStore, asyncRuleDeleteInterval),
+		reconciler:           newReconciler(ofClient, ifaceStore, routeClient, asyncRuleDeleteInterval),
 		ofClient:             ofClient,
+		routeClient:          routeClient,
 		antreaPolicyEnabled:  antreaPolicyEnabled,
 	}
 	c.ruleCache = newRuleCache(c.enqueueRule, podUpdates)
@@ -113,6 +141,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	if c.ofClient != nil && antreaPolicyEnabled {
 		// Register packetInHandler
 		c.ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonNP), "networkpolicy", c)
+		c.ofClient.RegisterL7PacketInHandler(newL7PacketInHandler(c.ofClient))
 		// Initiate logger for Antrea Policy audit logging
 		err := initLogger()
 		if err != nil {
@@ -120,25 +149,46 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		}
 	}
 
-	// Use nodeName to filter resources when watching resources.
+	serializer := protobuf.NewSerializer(scheme.Scheme, scheme.Scheme)
+	codec := scheme.Codecs.CodecForVersions(serializer, serializer, v1beta2.SchemeGroupVersion, v1beta2.SchemeGroupVersion)
+	var err error
+	if c.networkPolicyStore, err = newFileStore(defaultFS, filepath.Join(checkpointDir, "networkpolicies"), codec); err != nil {
+		return nil, err
+	}
+	if c.appliedToGroupStore, err = newFileStore(defaultFS, filepath.Join(checkpointDir, "appliedtogroups"), codec); err != nil {
+		return nil, err
+	}
+	if c.addressGroupStore, err = newFileStore(defaultFS, filepath.Join(checkpointDir, "addressgroups"), codec); err != nil {
+		return nil, err
+	}
+
+	// Use nodeName to filter resources when watching resources. Allow bookmarks so that the watchers
+	// know the resourceVersions they can resume from after reconnecting.
 	options := metav1.ListOptions{
-		FieldSelector: fields.OneTermEqualSelector("nodeName", nodeName).String(),
+		FieldSelector:       fields.OneTermEqualSelector("nodeName", nodeName).String(),
+		AllowWatchBookmarks: true,
+	}
+	watchOptions := func(resourceVersion string) metav1.ListOptions {
+		o := options
+		o.ResourceVersion = resourceVersion
+		return o
 	}
 
 	c.networkPolicyWatcher = &watcher{
 		objectType: "NetworkPolicy",
-		watchFunc: func() (watch.Interface, error) {
+		watchFunc: func(resourceVersion string) (watch.Interface, error) {
 			antreaClient, err := c.antreaClientProvider.GetAntreaClient()
 			if err != nil {
 				return nil, err
 			}
-			return antreaClient.ControlplaneV1beta2().NetworkPolicies().Watch(context.TODO(), options)
+			return antreaClient.ControlplaneV1beta2().NetworkPolicies().Watch(context.TODO(), watchOptions(resourceVersion))
 		},
 		AddFunc: func(obj runtime.Object) error {
 			policy, ok := obj.(*v1beta2.NetworkPolicy)
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.NetworkPolicy: %v", obj)
 			}
+			c.checkpoint(c.networkPolicyStore.save, policy)
 			if !c.antreaPolicyEnabled && policy.SourceRef.Type != v1beta2.K8sNetworkPolicy {
 				klog.Infof("Ignore Antrea NetworkPolicy %s since AntreaPolicy feature gate is not enabled",
 					policy.SourceRef.ToString())
@@ -153,6 +203,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.NetworkPolicy: %v", obj)
 			}
+			c.checkpoint(c.networkPolicyStore.save, policy)
 			if !c.antreaPolicyEnabled && policy.SourceRef.Type != v1beta2.K8sNetworkPolicy {
 				klog.Infof("Ignore Antrea NetworkPolicy %s since AntreaPolicy feature gate is not enabled",
 					policy.SourceRef.ToString())
@@ -166,6 +217,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.NetworkPolicy: %v", obj)
 			}
+			c.checkpoint(c.networkPolicyStore.delete, policy)
 			if !c.antreaPolicyEnabled && policy.SourceRef.Type != v1beta2.K8sNetworkPolicy {
 				klog.Infof("Ignore Antrea NetworkPolicy %s since AntreaPolicy feature gate is not enabled",
 					policy.SourceRef.ToString())
@@ -176,6 +228,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			return nil
 		},
 		ReplaceFunc: func(objs []runtime.Object) error {
+			c.checkpointAll(c.networkPolicyStore, objs)
 			policies := make([]*v1beta2.NetworkPolicy, len(objs))
 			var ok bool
 			for i := range objs {
@@ -202,22 +255,24 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		},
 		fullSyncWaitGroup: &c.fullSyncGroup,
 		fullSynced:        false,
+		store:             c.networkPolicyStore,
 	}
 
 	c.appliedToGroupWatcher = &watcher{
 		objectType: "AppliedToGroup",
-		watchFunc: func() (watch.Interface, error) {
+		watchFunc: func(resourceVersion string) (watch.Interface, error) {
 			antreaClient, err := c.antreaClientProvider.GetAntreaClient()
 			if err != nil {
 				return nil, err
 			}
-			return antreaClient.ControlplaneV1beta2().AppliedToGroups().Watch(context.TODO(), options)
+			return antreaClient.ControlplaneV1beta2().AppliedToGroups().Watch(context.TODO(), watchOptions(resourceVersion))
 		},
 		AddFunc: func(obj runtime.Object) error {
 			group, ok := obj.(*v1beta2.AppliedToGroup)
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AppliedToGroup: %v", obj)
 			}
+			c.checkpoint(c.appliedToGroupStore.save, group)
 			c.ruleCache.AddAppliedToGroup(group)
 			return nil
 		},
@@ -226,7 +281,11 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AppliedToGroup: %v", obj)
 			}
-			c.ruleCache.PatchAppliedToGroup(group)
+			patchedGroup, err := c.ruleCache.PatchAppliedToGroup(group)
+			if err != nil {
+				return err
+			}
+			c.checkpoint(c.appliedToGroupStore.save, patchedGroup)
 			return nil
 		},
 		DeleteFunc: func(obj runtime.Object) error {
@@ -234,10 +293,12 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AppliedToGroup: %v", obj)
 			}
+			c.checkpoint(c.appliedToGroupStore.delete, group)
 			c.ruleCache.DeleteAppliedToGroup(group)
 			return nil
 		},
 		ReplaceFunc: func(objs []runtime.Object) error {
+			c.checkpointAll(c.appliedToGroupStore, objs)
 			groups := make([]*v1beta2.AppliedToGroup, len(objs))
 			var ok bool
 			for i := range objs {
@@ -251,22 +312,24 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		},
 		fullSyncWaitGroup: &c.fullSyncGroup,
 		fullSynced:        false,
+		store:             c.appliedToGroupStore,
 	}
 
 	c.addressGroupWatcher = &watcher{
 		objectType: "AddressGroup",
-		watchFunc: func() (watch.Interface, error) {
+		watchFunc: func(resourceVersion string) (watch.Interface, error) {
 			antreaClient, err := c.antreaClientProvider.GetAntreaClient()
 			if err != nil {
 				return nil, err
 			}
-			return antreaClient.ControlplaneV1beta2().AddressGroups().Watch(context.TODO(), options)
+			return antreaClient.ControlplaneV1beta2().AddressGroups().Watch(context.TODO(), watchOptions(resourceVersion))
 		},
 		AddFunc: func(obj runtime.Object) error {
 			group, ok := obj.(*v1beta2.AddressGroup)
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AddressGroup: %v", obj)
 			}
+			c.checkpoint(c.addressGroupStore.save, group)
 			c.ruleCache.AddAddressGroup(group)
 			return nil
 		},
@@ -275,7 +338,11 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AddressGroup: %v", obj)
 			}
-			c.ruleCache.PatchAddressGroup(group)
+			patchedGroup, err := c.ruleCache.PatchAddressGroup(group)
+			if err != nil {
+				return err
+			}
+			c.checkpoint(c.addressGroupStore.save, patchedGroup)
 			return nil
 		},
 		DeleteFunc: func(obj runtime.Object) error {
@@ -283,10 +350,12 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 			if !ok {
 				return fmt.Errorf("cannot convert to *v1beta1.AddressGroup: %v", obj)
 			}
+			c.checkpoint(c.addressGroupStore.delete, group)
 			c.ruleCache.DeleteAddressGroup(group)
 			return nil
 		},
 		ReplaceFunc: func(objs []runtime.Object) error {
+			c.checkpointAll(c.addressGroupStore, objs)
 			groups := make([]*v1beta2.AddressGroup, len(objs))
 			var ok bool
 			for i := range objs {
@@ -300,10 +369,25 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		},
 		fullSyncWaitGroup: &c.fullSyncGroup,
 		fullSynced:        false,
+		store:             c.addressGroupStore,
 	}
 	return c, nil
 }
 
+// checkpoint saves or deletes the checkpoint of an object with the provided function. A failure is only logged as
+// the checkpoints are not needed to enforce the NetworkPolicies as long as the agent keeps running.
+func (c *Controller) checkpoint(f func(obj runtime.Object) error, obj runtime.Object) {
+	if err := f(obj); err != nil {
+		klog.Errorf("Failed to checkpoint %T: %v", obj, err)
+	}
+}
+
+func (c *Controller) checkpointAll(store *fileStore, objs []runtime.Object) {
+	if err := store.replaceAll(objs); err != nil {
+		klog.Errorf("Failed to checkpoint objects: %v", err)
+	}
+}
+
 func (c *Controller) GetNetworkPolicyNum() int {
 	return c.ruleCache.GetNetworkPolicyNum()
 }
@@ -357,31 +441,50 @@ func (c *Controller) GetControllerConnectionStatus() bool {
 // and NetworkPolicies, and spawns workers that reconciles NetworkPolicy rules.
 // Run will not return until stopCh is closed.
 func (c *Controller) Run(stopCh <-chan struct{}) {
-	attempts := 0
-	if err := wait.PollImmediateUntil(200*time.Millisecond, func() (bool, error) {
-		if attempts%10 == 0 {
-			klog.Info("Waiting for Antrea client to be ready")
-		}
-		if _, err := c.antreaClientProvider.GetAntreaClient(); err != nil {
-			attempts++
-			return false, nil
+	go func() {
+		attempts := 0
+		if err := wait.PollImmediateUntil(200*time.Millisecond, func() (bool, error) {
+			if attempts%10 == 0 {
+				klog.Info("Waiting for Antrea client to be ready")
+			}
+			if _, err := c.antreaClientProvider.GetAntreaClient(); err != nil {
+				attempts++
+				return false, nil
+			}
+			return true, nil
+		}, stopCh); err != nil {
+			klog.Info("Stopped waiting for Antrea client")
+			return
 		}
-		return true, nil
-	}, stopCh); err != nil {
-		klog.Info("Stopped waiting for Antrea client")
-		return
-	}
-	klog.Info("Antrea client is ready")
-
-	// Use NonSlidingUntil so that normal reconnection (disconnected after
-	// running a while) can reconnect immediately while abnormal reconnection
-	// won't be too aggressive.
-	go wait.NonSlidingUntil(c.appliedToGroupWatcher.watch, 5*time.Second, stopCh)
-	go wait.NonSlidingUntil(c.addressGroupWatcher.watch, 5*time.Second, stopCh)
-	go wait.NonSlidingUntil(c.networkPolicyWatcher.watch, 5*time.Second, stopCh)
+		klog.Info("Antrea client is ready")
+
+		// Use NonSlidingUntil so that normal reconnection (disconnected after
+		// running a while) can reconnect immediately while abnormal reconnection
+		// won't be too aggressive.
+		go wait.NonSlidingUntil(c.appliedToGroupWatcher.watch, 5*time.Second, stopCh)
+		go wait.NonSlidingUntil(c.addressGroupWatcher.watch, 5*time.Second, stopCh)
+		go wait.NonSlidingUntil(c.networkPolicyWatcher.watch, 5*time.Second, stopCh)
+	}()
 
+	fullSyncCh := make(chan struct{})
+	go func() {
+		c.fullSyncGroup.Wait()
+		close(fullSyncCh)
+	}()
 	klog.Infof("Waiting for all watchers to complete full sync")
-	c.fullSyncGroup.Wait()
+	select {
+	case <-fullSyncCh:
+	case <-time.After(restoreCheckpointTimeout):
+		// The watchers which haven't completed full sync yet restore the checkpointed objects, which will be
+		// replaced with the objects received from the antrea-controller once they complete full sync.
+		klog.Infof("Watchers didn't complete full sync in %v, restoring NetworkPolicies from checkpoints", restoreCheckpointTimeout)
+		c.appliedToGroupWatcher.restore()
+		c.addressGroupWatcher.restore()
+		c.networkPolicyWatcher.restore()
+		<-fullSyncCh
+	case <-stopCh:
+		return
+	}
 	klog.Infof("All watchers have completed full sync, installing flows for init events")
 	// Batch install all rules in queue after fullSync is finished.
 	c.processAllItemsInQueue()
@@ -399,6 +502,11 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 		go c.statusManager.Run(stopCh)
 	}
 
+	// The logger is only initialized with the packetin handlers.
+	if c.ofClient != nil && c.routeClient != nil && c.antreaPolicyEnabled {
+		go c.routeClient.ReceiveHostPolicyLogs(stopCh, handleHostPolicyLog)
+	}
+
 	<-stopCh
 }
 
@@ -528,8 +636,8 @@ func (c *Controller) handleErr(err error, key interface{}) {
 type watcher struct {
 	// objectType is the type of objects being watched, used for logging.
 	objectType string
-	// watchFunc is the function that starts the watch.
-	watchFunc func() (watch.Interface, error)
+	// watchFunc is the function that starts the watch, resuming from the provided resourceVersion if it's not empty.
+	watchFunc func(resourceVersion string) (watch.Interface, error)
 	// AddFunc is the function that handles added event.
 	AddFunc func(obj runtime.Object) error
 	// UpdateFunc is the function that handles modified event.
@@ -544,8 +652,17 @@ type watcher struct {
 	lock sync.RWMutex
 	// group to be notified when each watcher receives bookmark event
 	fullSyncWaitGroup *sync.WaitGroup
-	// fullSynced indicates if the resource has been synced at least once since agent started.
+	// fullSynced indicates if the resource has been synced at least once since agent started, either from the
+	// antrea-controller or from the checkpoints.
 	fullSynced bool
+	// syncLock serializes the full sync from the antrea-controller and the restoration from the checkpoints.
+	syncLock sync.Mutex
+	// store is where the objects are checkpointed.
+	store *fileStore
+	// resourceVersion is the resourceVersion up to which the events have been handled, from which the watch
+	// resumes after reconnecting, so that only the missed events are received. It's empty if the watch must
+	// receive all the objects again.
+	resourceVersion string
 }
 
 func (w *watcher) isConnected() bool {
@@ -560,11 +677,50 @@ func (w *watcher) setConnected(connected bool) {
 	w.connected = connected
 }
 
+// replace calls ReplaceFunc with the provided objects and notifies fullSyncWaitGroup the first time it succeeds.
+func (w *watcher) replace(objs []runtime.Object) error {
+	w.syncLock.Lock()
+	defer w.syncLock.Unlock()
+	if err := w.ReplaceFunc(objs); err != nil {
+		return err
+	}
+	if !w.fullSynced {
+		w.fullSynced = true
+		// Notify fullSyncWaitGroup that all events before bookmark is handled
+		w.fullSyncWaitGroup.Done()
+	}
+	return nil
+}
+
+// restore replaces the objects with the checkpointed ones if the watcher hasn't completed full sync yet.
+func (w *watcher) restore() {
+	w.syncLock.Lock()
+	defer w.syncLock.Unlock()
+	if w.fullSynced {
+		return
+	}
+	objs, err := w.store.loadAll()
+	if err != nil {
+		klog.Errorf("Failed to load checkpointed %s: %v", w.objectType, err)
+	} else if err := w.ReplaceFunc(objs); err != nil {
+		klog.Errorf("Failed to restore checkpointed %s: %v", w.objectType, err)
+	} else {
+		klog.Infof("Restored %d %s from checkpoints", len(objs), w.objectType)
+	}
+	// Complete the full sync even if nothing could be restored, so that the NetworkPolicy workers can start.
+	w.fullSynced = true
+	w.fullSyncWaitGroup.Done()
+}
+
 func (w *watcher) watch() {
 	klog.Infof("Starting watch for %s", w.objectType)
-	watcher, err := w.watchFunc()
+	watcher, err := w.watchFunc(w.resourceVersion)
 	if err != nil {
 		klog.Warningf("Failed to start watch for %s: %v", w.objectType, err)
+		if errors.IsResourceExpired(err) || errors.IsGone(err) {
+			// The antrea-controller no longer has the events since resourceVersion, receive all objects next time.
+			w.resourceVersion = ""
+		}
 		return
 	}
 
@@ -581,6 +737,7 @@ func (w *watcher) watch() {
 	// a Bookmark event is received, indicating that all init events have been
 	// received.
 	var initObjects []runtime.Object
+	var bookmarkVersion string
 loop:
 	for {
 		select {
@@ -594,21 +751,25 @@ loop:
 				klog.V(2).Infof("Added %s (%#v)", w.objectType, event.Object)
 				initObjects = append(initObjects, event.Object)
 			case watch.Bookmark:
+				bookmarkVersion = getResourceVersion(event.Object)
 				break loop
 			}
 		}
 	}
-	klog.Infof("Received %d init events for %s", len(initObjects), w.objectType)
-
-	eventCount += len(initObjects)
-	if err := w.ReplaceFunc(initObjects); err != nil {
-		klog.Errorf("Failed to handle init events: %v", err)
-		return
-	}
-	if !w.fullSynced {
-		w.fullSynced = true
-		// Notify fullSyncWaitGroup that all events before bookmark is handled
-		w.fullSyncWaitGroup.Done()
+	// When resuming from a resourceVersion, the antrea-controller sends a bookmark event with the same
+	// resourceVersion first, followed by the events missed since then.
+	if w.resourceVersion != "" && bookmarkVersion == w.resourceVersion && len(initObjects) == 0 {
+		klog.Infof("Resumed watch for %s from resourceVersion %s", w.objectType, w.resourceVersion)
+	} else {
+		klog.Infof("Received %d init events for %s", len(initObjects), w.objectType)
+
+		eventCount += len(initObjects)
+		if err := w.replace(initObjects); err != nil {
+			klog.Errorf("Failed to handle init events: %v", err)
+			w.resourceVersion = ""
+			return
+		}
+		w.resourceVersion = bookmarkVersion
 	}
 
 	for {
@@ -621,21 +782,27 @@ loop:
 			case watch.Added:
 				if err := w.AddFunc(event.Object); err != nil {
 					klog.Errorf("Failed to handle added event: %v", err)
+					w.resourceVersion = ""
 					return
 				}
 				klog.V(2).Infof("Added %s (%#v)", w.objectType, event.Object)
 			case watch.Modified:
 				if err := w.UpdateFunc(event.Object); err != nil {
 					klog.Errorf("Failed to handle modified event: %v", err)
+					w.resourceVersion = ""
 					return
 				}
 				klog.V(2).Infof("Updated %s (%#v)", w.objectType, event.Object)
 			case watch.Deleted:
 				if err := w.DeleteFunc(event.Object); err != nil {
 					klog.Errorf("Failed to handle deleted event: %v", err)
+					w.resourceVersion = ""
 					return
 				}
 				klog.V(2).Infof("Removed %s (%#v)", w.objectType, event.Object)
+			case watch.Bookmark:
+				w.resourceVersion = getResourceVersion(event.Object)
+				continue
 			default:
 				klog.Errorf("Unknown event: %v", event)
 				return
@@ -644,3 +811,16 @@ loop:
 		}
 	}
 }
+
+// getResourceVersion returns the resourceVersion carried by the object of a bookmark event, or an empty string if the
+// object is nil, e.g. when the antrea-controller doesn't support resuming watches.
+func getResourceVersion(obj runtime.Object) string {
+	if obj == nil {
+		return ""
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetResourceVersion()
+}
@@ -21,9 +21,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
@@ -52,7 +55,8 @@ func (g *antreaClientGetter) GetAntreaClient() (versioned.Interface, error) {
 func newTestController() (*Controller, *fake.Clientset, *mockReconciler) {
 	clientset := &fake.Clientset{}
 	ch := make(chan v1beta2.PodReference, 100)
-	controller, _ := NewNetworkPolicyController(&antreaClientGetter{clientset}, nil, nil, "node1", ch, true, testAsyncDeleteInterval)
+	defaultFS = afero.NewMemMapFs()
+	controller, _ := NewNetworkPolicyController(&antreaClientGetter{clientset}, nil, nil, nil, "node1", ch, true, testAsyncDeleteInterval)
 	reconciler := newMockReconciler()
 	controller.reconciler = reconciler
 	return controller, clientset, reconciler
@@ -590,3 +594,114 @@ func TestNetworkPolicyMetrics(t *testing.T) {
 	waitForReconcilerDeleted()
 	checkNetworkPolicyMetrics()
 }
+
+func TestRestoreCheckpoints(t *testing.T) {
+	controller, clientset, reconciler := newTestController()
+	addressGroupWatcher := watch.NewFake()
+	appliedToGroupWatcher := watch.NewFake()
+	networkPolicyWatcher := watch.NewFake()
+	clientset.AddWatchReactor("addressgroups", k8stesting.DefaultWatchReactor(addressGroupWatcher, nil))
+	clientset.AddWatchReactor("appliedtogroups", k8stesting.DefaultWatchReactor(appliedToGroupWatcher, nil))
+	clientset.AddWatchReactor("networkpolicies", k8stesting.DefaultWatchReactor(networkPolicyWatcher, nil))
+	defer func(timeout time.Duration) {
+		restoreCheckpointTimeout = timeout
+	}(restoreCheckpointTimeout)
+	restoreCheckpointTimeout = 100 * time.Millisecond
+
+	protocolTCP := v1beta2.ProtocolTCP
+	port := intstr.FromInt(80)
+	services := []v1beta2.Service{{Protocol: &protocolTCP, Port: &port}}
+	policy1 := newNetworkPolicy("policy1", "uid1", []string{"addressGroup1"}, []string{}, []string{"appliedToGroup1"}, services)
+	// The objects received before the agent restarted.
+	require.NoError(t, controller.networkPolicyStore.save(policy1))
+	require.NoError(t, controller.addressGroupStore.save(newAddressGroup("addressGroup1", []v1beta2.GroupMember{*newAddressGroupMember("1.1.1.1")})))
+	require.NoError(t, controller.appliedToGroupStore.save(newAppliedToGroup("appliedToGroup1", []v1beta2.GroupMember{*newAppliedToGroupMember("pod1", "ns1")})))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go controller.Run(stopCh)
+
+	// The watchers don't complete full sync, the checkpointed policy1 is enforced.
+	var ruleID string
+	select {
+	case ruleID = <-reconciler.updated:
+		actualRule, _ := reconciler.getLastRealized(ruleID)
+		assert.True(t, actualRule.FromAddresses.Equal(v1beta2.NewGroupMemberSet(newAddressGroupMember("1.1.1.1"))))
+		assert.True(t, actualRule.TargetMembers.Equal(v1beta2.NewGroupMemberSet(newAppliedToGroupMember("pod1", "ns1"))))
+	case <-time.After(time.Second):
+		t.Fatal("Expected one update, got none")
+	}
+	assert.Equal(t, 1, controller.GetNetworkPolicyNum())
+
+	// The antrea-controller no longer has policy1, it's removed from the rules and the checkpoints.
+	networkPolicyWatcher.Action(watch.Bookmark, nil)
+	select {
+	case deletedRuleID := <-reconciler.deleted:
+		assert.Equal(t, ruleID, deletedRuleID)
+	case <-time.After(time.Second):
+		t.Fatal("Expected one deletion, got none")
+	}
+	assert.Equal(t, 0, controller.GetNetworkPolicyNum())
+	objs, err := controller.networkPolicyStore.loadAll()
+	require.NoError(t, err)
+	assert.Empty(t, objs)
+}
+
+func TestWatcherResume(t *testing.T) {
+	var requestedVersions []string
+	var fakeWatcher *watch.FakeWatcher
+	var watchErr error
+	var replaced, added []runtime.Object
+	var fullSyncGroup sync.WaitGroup
+	fullSyncGroup.Add(1)
+	w := &watcher{
+		objectType: "AddressGroup",
+		watchFunc: func(resourceVersion string) (watch.Interface, error) {
+			requestedVersions = append(requestedVersions, resourceVersion)
+			if watchErr != nil {
+				return nil, watchErr
+			}
+			return fakeWatcher, nil
+		},
+		AddFunc: func(obj runtime.Object) error {
+			added = append(added, obj)
+			return nil
+		},
+		ReplaceFunc: func(objs []runtime.Object) error {
+			replaced = objs
+			return nil
+		},
+		fullSyncWaitGroup: &fullSyncGroup,
+	}
+	newBookmark := func(resourceVersion string) *v1beta2.AddressGroup {
+		return &v1beta2.AddressGroup{ObjectMeta: v1.ObjectMeta{ResourceVersion: resourceVersion}}
+	}
+	group1 := newAddressGroup("group1", nil)
+	group2 := newAddressGroup("group2", nil)
+
+	// The first watch receives all objects and the resourceVersion to resume from.
+	fakeWatcher = watch.NewFakeWithChanSize(10, false)
+	fakeWatcher.Add(group1)
+	fakeWatcher.Action(watch.Bookmark, newBookmark("10"))
+	fakeWatcher.Action(watch.Bookmark, newBookmark("12"))
+	fakeWatcher.Stop()
+	w.watch()
+	assert.Equal(t, []runtime.Object{group1}, replaced)
+	assert.Equal(t, "12", w.resourceVersion)
+
+	// The second watch resumes from it and only receives the missed events.
+	replaced = nil
+	fakeWatcher = watch.NewFakeWithChanSize(10, false)
+	fakeWatcher.Action(watch.Bookmark, newBookmark("12"))
+	fakeWatcher.Add(group2)
+	fakeWatcher.Stop()
+	w.watch()
+	assert.Nil(t, replaced)
+	assert.Equal(t, []runtime.Object{group2}, added)
+
+	// The antrea-controller can't resume from it, the next watch receives all objects.
+	watchErr = errors.NewResourceExpired("too old resourceVersion")
+	w.watch()
+	assert.Equal(t, []string{"", "12", "12"}, requestedVersions)
+	assert.Empty(t, w.resourceVersion)
+}
@@ -15,9 +15,11 @@
 package networkpolicy
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 
 	"github.com/contiv/libOpenflow/openflow13"
@@ -27,6 +29,7 @@ import (
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	opsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/ops/v1alpha1"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 )
@@ -100,10 +103,31 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 	}
 
 	// Store log file
-	AntreaPolicyLogger.Printf("%s %s %s %s SRC: %s DEST: %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
+	writeLog(ob)
 	return nil
 }
 
+// handleHostPolicyLog logs the packets logged by the policy rules enforced on the host network of the Node, in the
+// same format as the packetin of the Pod policy rules. The iptables chain of the rule is logged as the table name, and
+// its Tier, policy and rule priorities as the priority.
+func handleHostPolicyLog(hostLog *types.HostPolicyLog) {
+	ob := &logInfo{
+		tableName:   hostLog.Chain,
+		npRef:       hostLog.PolicyRef.ToString(),
+		disposition: hostLog.Disposition,
+		ofPriority:  fmt.Sprintf("%d,%v,%d", hostLog.Priority.TierPriority, hostLog.Priority.PolicyPriority, hostLog.Priority.RulePriority),
+	}
+	if err := getIPPacketInfo(hostLog.Packet, ob); err != nil {
+		klog.Errorf("Failed to get info of packet logged by host policy rule of %s: %v", ob.npRef, err)
+		return
+	}
+	writeLog(ob)
+}
+
+func writeLog(ob *logInfo) {
+	AntreaPolicyLogger.Printf("%s %s %s %s SRC: %s DEST: %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
+}
+
 // getMatchRegField returns match to the regNum register.
 func getMatchRegField(matchers *ofctrl.Matchers, regNum uint32) *ofctrl.MatchField {
 	return matchers.GetMatchByName(fmt.Sprintf("NXM_NX_REG%d", regNum))
@@ -187,3 +211,33 @@ func getPacketInfo(pktIn *ofctrl.PacketIn, ob *logInfo) error {
 	}
 	return nil
 }
+
+// getIPPacketInfo fills in srcIP, destIP, pktLength, protocol of logInfo ob from the headers of an IP packet. The
+// extension headers of IPv6 packets are not parsed.
+func getIPPacketInfo(packet []byte, ob *logInfo) error {
+	if len(packet) == 0 {
+		return errors.New("empty IP packet")
+	}
+	switch packet[0] >> 4 {
+	case 4:
+		if len(packet) < 20 {
+			return errors.New("invalid IPv4 packet")
+		}
+		ob.srcIP = net.IP(packet[12:16]).String()
+		ob.destIP = net.IP(packet[16:20]).String()
+		ob.pktLength = binary.BigEndian.Uint16(packet[2:4])
+		ob.protocolStr = opsv1alpha1.ProtocolsToString[int32(packet[9])]
+	case 6:
+		if len(packet) < 40 {
+			return errors.New("invalid IPv6 packet")
+		}
+		ob.srcIP = net.IP(packet[8:24]).String()
+		ob.destIP = net.IP(packet[24:40]).String()
+		// The payload length doesn't include the fixed header.
+		ob.pktLength = binary.BigEndian.Uint16(packet[4:6]) + 40
+		ob.protocolStr = opsv1alpha1.ProtocolsToString[int32(packet[6])]
+	default:
+		return fmt.Errorf("unknown IP version %d", packet[0]>>4)
+	}
+	return nil
+}
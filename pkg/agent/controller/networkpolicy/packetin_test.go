@@ -63,3 +63,53 @@ func TestGetPacketInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestGetIPPacketInfo(t *testing.T) {
+	ipv4Packet := []byte{
+		0x45, 0x00, 0x00, 0x3c, 0x00, 0x00, 0x40, 0x00, 0x40, 0x06, 0x00, 0x00,
+		0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x02,
+	}
+	ipv6Packet := append([]byte{0x60, 0x00, 0x00, 0x00, 0x00, 0x08, 0x11, 0x40}, net.ParseIP("fd00::1")...)
+	ipv6Packet = append(ipv6Packet, net.ParseIP("fd00::2")...)
+
+	tests := []struct {
+		name       string
+		packet     []byte
+		expectedOb logInfo
+		wantErr    bool
+	}{
+		{
+			"ipv4",
+			ipv4Packet,
+			logInfo{srcIP: "10.0.0.1", destIP: "10.0.0.2", pktLength: 60, protocolStr: "TCP"},
+			false,
+		},
+		{
+			"ipv6",
+			ipv6Packet,
+			logInfo{srcIP: "fd00::1", destIP: "fd00::2", pktLength: 48, protocolStr: "UDP"},
+			false,
+		},
+		{
+			"truncated",
+			ipv4Packet[:10],
+			logInfo{},
+			true,
+		},
+		{
+			"empty",
+			nil,
+			logInfo{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualOb := logInfo{}
+			if err := getIPPacketInfo(tt.packet, &actualOb); (err != nil) != tt.wantErr {
+				t.Errorf("getIPPacketInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.expectedOb, actualOb, "Expect to retrieve exact packet info while differed")
+		})
+	}
+}
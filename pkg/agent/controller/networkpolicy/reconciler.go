@@ -30,6 +30,7 @@ import (
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
@@ -148,6 +149,9 @@ type lastRealized struct {
 	// It's same in all Openflow rules, because named port is only for
 	// destination Pods.
 	podIPs sets.String
+	// hostFlowID identifies the rule enforced on the host traffic of the Node
+	// by the route client. It's 0 if the rule is not applied to the Node.
+	hostFlowID uint32
 }
 
 func newLastRealized(rule *CompletedRule) *lastRealized {
@@ -177,6 +181,9 @@ type reconciler struct {
 	// ifaceStore provides container interface OFPort and IP information.
 	ifaceStore interfacestore.InterfaceStore
 
+	// routeClient enforces the rules applied to the Node on its host traffic.
+	routeClient route.Interface
+
 	// lastRealizeds caches the last realized rules.
 	// It's a mapping from ruleID to *lastRealized.
 	lastRealizeds sync.Map
@@ -193,7 +200,7 @@ type reconciler struct {
 }
 
 // newReconciler returns a new *reconciler.
-func newReconciler(ofClient openflow.Client, ifaceStore interfacestore.InterfaceStore, asyncRuleDeleteInterval time.Duration) *reconciler {
+func newReconciler(ofClient openflow.Client, ifaceStore interfacestore.InterfaceStore, routeClient route.Interface, asyncRuleDeleteInterval time.Duration) *reconciler {
 	priorityAssigners := map[binding.TableIDType]*tablePriorityAssigner{}
 	for _, table := range openflow.GetAntreaPolicyBaselineTierTables() {
 		priorityAssigners[table] = &tablePriorityAssigner{
@@ -208,6 +215,7 @@ func newReconciler(ofClient openflow.Client, ifaceStore interfacestore.Interface
 	reconciler := &reconciler{
 		ofClient:          ofClient,
 		ifaceStore:        ifaceStore,
+		routeClient:       routeClient,
 		lastRealizeds:     sync.Map{},
 		idAllocator:       newIDAllocator(asyncRuleDeleteInterval),
 		priorityAssigners: priorityAssigners,
@@ -258,7 +266,11 @@ func (r *reconciler) Reconcile(rule *CompletedRule) error {
 	if ofRuleInstallErr != nil && ofPriority != nil {
 		priorityAssigner.assigner.Release(*ofPriority)
 	}
-	return ofRuleInstallErr
+	if ofRuleInstallErr != nil {
+		return ofRuleInstallErr
+	}
+	value, _ = r.lastRealizeds.Load(rule.ID)
+	return r.reconcileHostRule(value.(*lastRealized), rule)
 }
 
 // getOFRuleTable retreives the OpenFlow table to install the CompletedRule.
@@ -359,8 +371,15 @@ func (r *reconciler) BatchReconcile(rules []*CompletedRule) error {
 				pa.assigner.Release(*ofPriority)
 			}
 		}
+		return ofRuleInstallErr
 	}
-	return ofRuleInstallErr
+	for _, rule := range rulesToInstall {
+		value, _ := r.lastRealizeds.Load(rule.ID)
+		if err := r.reconcileHostRule(value.(*lastRealized), rule); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // registerOFPriorities constructs a Priority type for each CompletedRule in the input list,
@@ -434,6 +453,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 				TableID:       table,
 				PolicyRef:     rule.SourceRef,
 				EnableLogging: rule.EnableLogging,
+				RateLimit:     rule.RateLimit,
+				L7Protocols:   rule.L7Protocols,
 			}
 		}
 	} else {
@@ -452,6 +473,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 				TableID:       table,
 				PolicyRef:     rule.SourceRef,
 				EnableLogging: rule.EnableLogging,
+				RateLimit:     rule.RateLimit,
+				L7Protocols:   rule.L7Protocols,
 			}
 		}
 
@@ -475,6 +498,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 					TableID:       table,
 					PolicyRef:     rule.SourceRef,
 					EnableLogging: rule.EnableLogging,
+					RateLimit:     rule.RateLimit,
+					L7Protocols:   rule.L7Protocols,
 				}
 				ofRuleByServicesMap[svcKey] = ofRule
 			}
@@ -562,6 +587,8 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 					TableID:       table,
 					PolicyRef:     newRule.SourceRef,
 					EnableLogging: newRule.EnableLogging,
+					RateLimit:     newRule.RateLimit,
+					L7Protocols:   newRule.L7Protocols,
 				}
 				err := r.idAllocator.allocateForRule(ofRule)
 				if err != nil {
@@ -611,6 +638,8 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 					TableID:       table,
 					PolicyRef:     newRule.SourceRef,
 					EnableLogging: newRule.EnableLogging,
+					RateLimit:     newRule.RateLimit,
+					L7Protocols:   newRule.L7Protocols,
 				}
 				err := r.idAllocator.allocateForRule(ofRule)
 				if err != nil {
@@ -729,11 +758,91 @@ func (r *reconciler) Forget(ruleID string) error {
 		delete(lastRealized.ofIDs, svcKey)
 		delete(lastRealized.podOFPorts, svcKey)
 	}
+	if err := r.uninstallHostRule(lastRealized); err != nil {
+		return err
+	}
 
 	r.lastRealizeds.Delete(ruleID)
 	return nil
 }
 
+// reconcileHostRule enforces the rule on the host traffic of the Node via the
+// route client if the Node is one of the rule's target members, as host traffic
+// doesn't go through OVS. Otherwise it removes the rule realized previously.
+func (r *reconciler) reconcileHostRule(lastRealized *lastRealized, rule *CompletedRule) error {
+	if !rule.isAntreaNetworkPolicyRule() || !hasNodeMember(rule.TargetMembers) {
+		return r.uninstallHostRule(lastRealized)
+	}
+	peer, peerMembers := rule.From, rule.FromAddresses
+	if rule.Direction == v1beta2.DirectionOut {
+		peer, peerMembers = rule.To, rule.ToAddresses
+	}
+	// nil means the rule is not restricted by peer addresses.
+	var peerAddresses []types.Address
+	if len(peer.AddressGroups) > 0 || len(peer.IPBlocks) > 0 {
+		peerAddresses = append(groupMembersToOFAddresses(peerMembers), ipBlocksToOFAddresses(peer.IPBlocks, r.ipv4Enabled, r.ipv6Enabled)...)
+	}
+	hostRule := &types.PolicyRule{
+		Direction:     rule.Direction,
+		Service:       filterUnresolvablePort(rule.Services),
+		Action:        rule.Action,
+		FlowID:        lastRealized.hostFlowID,
+		PolicyRef:     rule.SourceRef,
+		EnableLogging: rule.EnableLogging,
+	}
+	if rule.Direction == v1beta2.DirectionIn {
+		hostRule.From = peerAddresses
+	} else {
+		hostRule.To = peerAddresses
+	}
+	// The FlowID is kept across updates of the rule.
+	if hostRule.FlowID == 0 {
+		if err := r.idAllocator.allocateForRule(hostRule); err != nil {
+			return fmt.Errorf("error allocating Openflow ID")
+		}
+	}
+	priority := types.Priority{
+		TierPriority:   *rule.TierPriority,
+		PolicyPriority: *rule.PolicyPriority,
+		RulePriority:   rule.Priority,
+	}
+	klog.V(2).Infof("Installing host rule %d (Direction: %v, Peers: %d, Service: %d)",
+		hostRule.FlowID, hostRule.Direction, len(peerAddresses), len(hostRule.Service))
+	if err := r.routeClient.InstallHostPolicyRule(hostRule, priority); err != nil {
+		if lastRealized.hostFlowID == 0 {
+			r.idAllocator.forgetRule(hostRule.FlowID)
+		}
+		return fmt.Errorf("error installing host rule %v: %v", hostRule.FlowID, err)
+	}
+	lastRealized.hostFlowID = hostRule.FlowID
+	return nil
+}
+
+// uninstallHostRule removes the rule enforced on the host traffic of the Node
+// if it was realized before.
+func (r *reconciler) uninstallHostRule(lastRealized *lastRealized) error {
+	if lastRealized.hostFlowID == 0 {
+		return nil
+	}
+	klog.V(2).Infof("Uninstalling host rule %d", lastRealized.hostFlowID)
+	if err := r.routeClient.UninstallHostPolicyRule(lastRealized.hostFlowID); err != nil {
+		return fmt.Errorf("error uninstalling host rule %v: %v", lastRealized.hostFlowID, err)
+	}
+	r.idAllocator.forgetRule(lastRealized.hostFlowID)
+	lastRealized.hostFlowID = 0
+	return nil
+}
+
+// hasNodeMember returns whether any of the provided GroupMembers is a Node.
+func hasNodeMember(members v1beta2.GroupMemberSet) bool {
+	for _, m := range members {
+		if m.Node != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *reconciler) GetRuleByFlowID(ruleFlowID uint32) (*types.PolicyRule, bool, error) {
 	return r.idAllocator.getRuleFromAsyncCache(ruleFlowID)
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	openflowtest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
@@ -173,7 +174,7 @@ func TestReconcilerForget(t *testing.T) {
 					mockOFClient.EXPECT().UninstallPolicyRuleFlows(ofID)
 				}
 			}
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			for key, value := range tt.lastRealizeds {
 				r.lastRealizeds.Store(key, value)
 			}
@@ -517,7 +518,7 @@ func TestReconcilerReconcile(t *testing.T) {
 			for i := 0; i < len(tt.expectedOFRules); i++ {
 				mockOFClient.EXPECT().InstallPolicyRuleFlows(gomock.Any())
 			}
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			if err := r.Reconcile(tt.args); (err != nil) != tt.wantErr {
 				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -627,7 +628,7 @@ func TestReconcilerBatchReconcile(t *testing.T) {
 			mockOFClient := openflowtest.NewMockClient(controller)
 			mockOFClient.EXPECT().IsIPv4Enabled().Return(true).AnyTimes()
 			mockOFClient.EXPECT().IsIPv6Enabled().Return(false).AnyTimes()
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			if tt.numInstalledRules > 0 {
 				// BatchInstall should skip rules already installed
 				r.lastRealizeds.Store(tt.args[0].ID, newLastRealized(tt.args[0]))
@@ -843,7 +844,7 @@ func TestReconcilerUpdate(t *testing.T) {
 			if len(tt.expectedDeletedTo) > 0 {
 				mockOFClient.EXPECT().DeletePolicyRuleAddress(gomock.Any(), types.DstAddress, gomock.Eq(tt.expectedDeletedTo), priority)
 			}
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			if err := r.Reconcile(tt.originalRule); (err != nil) != tt.wantErr {
 				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -854,6 +855,68 @@ func TestReconcilerUpdate(t *testing.T) {
 	}
 }
 
+func TestReconcilerHostRule(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	ifaceStore := interfacestore.NewInterfaceStore()
+	mockOFClient := openflowtest.NewMockClient(controller)
+	mockOFClient.EXPECT().IsIPv4Enabled().Return(true).AnyTimes()
+	mockOFClient.EXPECT().IsIPv6Enabled().Return(false).AnyTimes()
+	mockOFClient.EXPECT().InstallPolicyRuleFlows(gomock.Any()).AnyTimes()
+	mockOFClient.EXPECT().AddPolicyRuleAddress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockOFClient.EXPECT().DeletePolicyRuleAddress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockOFClient.EXPECT().UninstallPolicyRuleFlows(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockRouteClient := routetest.NewMockInterface(controller)
+	r := newReconciler(mockOFClient, ifaceStore, mockRouteClient, testAsyncDeleteInterval)
+
+	nodeGroup := v1beta2.NewGroupMemberSet(&v1beta2.GroupMember{Node: &v1beta2.NodeReference{Name: "node1"}})
+	newRule := func(fromAddresses, targetMembers v1beta2.GroupMemberSet) *CompletedRule {
+		return &CompletedRule{
+			rule: &rule{
+				ID:             "ingress-rule",
+				Direction:      v1beta2.DirectionIn,
+				From:           v1beta2.NetworkPolicyPeer{AddressGroups: []string{"addressGroup1"}},
+				Services:       services1,
+				PolicyPriority: &policyPriority,
+				TierPriority:   &tierPriority,
+				SourceRef:      &cnp1,
+			},
+			FromAddresses: fromAddresses,
+			TargetMembers: targetMembers,
+		}
+	}
+	expectedPriority := types.Priority{TierPriority: tierPriority, PolicyPriority: policyPriority, RulePriority: 0}
+
+	// The rule is installed on the host network with its peer addresses.
+	var hostFlowID uint32
+	mockRouteClient.EXPECT().InstallHostPolicyRule(gomock.Any(), expectedPriority).Do(func(rule *types.PolicyRule, _ types.Priority) {
+		assert.Equal(t, ipsToOFAddresses(sets.NewString("1.1.1.1")), rule.From)
+		assert.Equal(t, services1, rule.Service)
+		assert.NotZero(t, rule.FlowID)
+		hostFlowID = rule.FlowID
+	})
+	assert.NoError(t, r.Reconcile(newRule(addressGroup1, nodeGroup)))
+
+	// The rule is updated with the same FlowID.
+	mockRouteClient.EXPECT().InstallHostPolicyRule(gomock.Any(), expectedPriority).Do(func(rule *types.PolicyRule, _ types.Priority) {
+		assert.Equal(t, ipsToOFAddresses(sets.NewString("1.1.1.2")), rule.From)
+		assert.Equal(t, hostFlowID, rule.FlowID)
+	})
+	assert.NoError(t, r.Reconcile(newRule(addressGroup2, nodeGroup)))
+
+	// The rule is uninstalled from the host network once it no longer applies to the Node.
+	mockRouteClient.EXPECT().UninstallHostPolicyRule(hostFlowID)
+	assert.NoError(t, r.Reconcile(newRule(addressGroup2, appliedToGroup1)))
+
+	// The rule is uninstalled from the host network when it's forgotten.
+	mockRouteClient.EXPECT().InstallHostPolicyRule(gomock.Any(), expectedPriority).Do(func(rule *types.PolicyRule, _ types.Priority) {
+		hostFlowID = rule.FlowID
+	})
+	assert.NoError(t, r.Reconcile(newRule(addressGroup2, nodeGroup)))
+	mockRouteClient.EXPECT().UninstallHostPolicyRule(hostFlowID)
+	assert.NoError(t, r.Forget("ingress-rule"))
+}
+
 func TestGroupMembersByServices(t *testing.T) {
 	numberedServices := []v1beta2.Service{serviceTCP80, serviceTCP443}
 	numberedServicesKey := normalizeServices(numberedServices)
@@ -1349,7 +1412,7 @@ func TestReconcilerReconcileIPv6Only(t *testing.T) {
 			for i := 0; i < len(tt.expectedOFRules); i++ {
 				mockOFClient.EXPECT().InstallPolicyRuleFlows(gomock.Any())
 			}
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			if err := r.Reconcile(tt.args); (err != nil) != tt.wantErr {
 				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -1752,7 +1815,7 @@ func TestReconcilerReconcileDualStack(t *testing.T) {
 			for i := 0; i < len(tt.expectedOFRules); i++ {
 				mockOFClient.EXPECT().InstallPolicyRuleFlows(gomock.Any())
 			}
-			r := newReconciler(mockOFClient, ifaceStore, testAsyncDeleteInterval)
+			r := newReconciler(mockOFClient, ifaceStore, nil, testAsyncDeleteInterval)
 			if err := r.Reconcile(tt.args); (err != nil) != tt.wantErr {
 				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
 			}
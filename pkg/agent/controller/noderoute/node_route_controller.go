@@ -36,6 +36,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/route"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 )
 
@@ -63,6 +64,7 @@ type Controller struct {
 	interfaceStore   interfacestore.InterfaceStore
 	networkConfig    *config.NetworkConfig
 	nodeConfig       *config.NodeConfig
+	wireGuardClient  wireguard.Interface
 	nodeInformer     coreinformers.NodeInformer
 	nodeLister       corelisters.NodeLister
 	nodeListerSynced cache.InformerSynced
@@ -83,7 +85,8 @@ func NewNodeRouteController(
 	routeClient route.Interface,
 	interfaceStore interfacestore.InterfaceStore,
 	networkConfig *config.NetworkConfig,
-	nodeConfig *config.NodeConfig) *Controller {
+	nodeConfig *config.NodeConfig,
+	wireGuardClient wireguard.Interface) *Controller {
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	controller := &Controller{
 		kubeClient:       kubeClient,
@@ -93,6 +96,7 @@ func NewNodeRouteController(
 		interfaceStore:   interfaceStore,
 		networkConfig:    networkConfig,
 		nodeConfig:       nodeConfig,
+		wireGuardClient:  wireGuardClient,
 		nodeInformer:     nodeInformer,
 		nodeLister:       nodeInformer.Lister(),
 		nodeListerSynced: nodeInformer.Informer().HasSynced,
@@ -133,6 +137,9 @@ type nodeRouteInfo struct {
 	podCIDRs  []*net.IPNet
 	nodeIP    net.IP
 	gatewayIP []net.IP
+	// wireGuardPublicKey is the public key of the Node's WireGuard device.
+	// It's empty if WireGuard is not used for traffic encryption.
+	wireGuardPublicKey string
 }
 
 // enqueueNode adds an object to the controller work queue
@@ -202,7 +209,7 @@ func (c *Controller) removeStaleTunnelPorts() error {
 	// knownInterfaces is the list of interfaces currently in the local cache.
 	knownInterfaces := c.interfaceStore.GetInterfaceKeysByType(interfacestore.TunnelInterface)
 
-	if c.networkConfig.EnableIPSecTunnel {
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		for _, node := range nodes {
 			interfaceConfig, found := c.interfaceStore.GetNodeTunnelInterface(node.Name)
 			if !found {
@@ -217,7 +224,9 @@ func (c *Controller) removeStaleTunnelPorts() error {
 			}
 
 			ifaceID := util.GenerateNodeTunnelInterfaceKey(node.Name)
-			validConfiguration := interfaceConfig.PSK == c.networkConfig.IPSecPSK &&
+			remoteName, psk := c.getIPSecAuthenticationOptions(node.Name)
+			validConfiguration := interfaceConfig.PSK == psk &&
+				interfaceConfig.RemoteName == remoteName &&
 				interfaceConfig.RemoteIP.Equal(peerNodeIP) &&
 				interfaceConfig.TunnelInterfaceConfig.Type == c.networkConfig.TunnelType
 			if validConfiguration {
@@ -253,6 +262,25 @@ func (c *Controller) removeStaleTunnelPorts() error {
 	return nil
 }
 
+// removeStaleWireGuardPeers removes all the WireGuard peers which no longer
+// correspond to a Node in the cluster, or whose public key has changed.
+func (c *Controller) removeStaleWireGuardPeers() error {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error when listing Nodes: %v", err)
+	}
+	currentPeerPublicKeys := make(map[string]string)
+	for _, node := range nodes {
+		if node.Name == c.nodeConfig.Name {
+			continue
+		}
+		if publicKey := node.Annotations[wireguard.NodeWireGuardPublicKeyAnnotationKey]; publicKey != "" {
+			currentPeerPublicKeys[node.Name] = publicKey
+		}
+	}
+	return c.wireGuardClient.RemoveStalePeers(currentPeerPublicKeys)
+}
+
 func (c *Controller) reconcile() error {
 	klog.Infof("Reconciliation for %s", controllerName)
 	// reconciliation consists of removing stale routes and stale / invalid tunnel ports:
@@ -264,6 +292,11 @@ func (c *Controller) reconcile() error {
 	if err := c.removeStaleTunnelPorts(); err != nil {
 		return fmt.Errorf("error when removing stale tunnel ports: %v", err)
 	}
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		if err := c.removeStaleWireGuardPeers(); err != nil {
+			return fmt.Errorf("error when removing stale WireGuard peers: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -385,9 +418,14 @@ func (c *Controller) deleteNodeRoute(nodeName string) error {
 	if err := c.ofClient.UninstallNodeFlows(nodeName); err != nil {
 		return fmt.Errorf("failed to uninstall flows to Node %s: %v", nodeName, err)
 	}
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		if err := c.wireGuardClient.DeletePeer(nodeName); err != nil {
+			return fmt.Errorf("failed to delete WireGuard peer of Node %s: %v", nodeName, err)
+		}
+	}
 	c.installedNodes.Delete(obj)
 
-	if c.networkConfig.EnableIPSecTunnel {
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface(nodeName)
 		if !ok {
 			// Tunnel port not created for this Node.
@@ -404,9 +442,10 @@ func (c *Controller) deleteNodeRoute(nodeName string) error {
 }
 
 func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
-	if _, installed, _ := c.installedNodes.GetByKey(nodeName); installed {
-		// Route is already added for this Node.
-		return nil
+	if obj, installed, _ := c.installedNodes.GetByKey(nodeName); installed {
+		// Route is already added for this Node, but the WireGuard peer
+		// needs to be updated if the Node changed its public key.
+		return c.updateWireGuardPeer(node, obj.(*nodeRouteInfo))
 	}
 
 	podCIDRStrs := getPodCIDRsOnNode(node)
@@ -460,8 +499,22 @@ func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
 		return nil
 	}
 
+	var wireGuardPublicKey string
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		wireGuardPublicKey = node.Annotations[wireguard.NodeWireGuardPublicKeyAnnotationKey]
+		if wireGuardPublicKey == "" {
+			// The Node will be processed again when its agent publishes
+			// the public key.
+			klog.Infof("WireGuard public key of Node %s is not available yet", nodeName)
+			return nil
+		}
+		if err := c.wireGuardClient.UpdatePeer(nodeName, wireGuardPublicKey, peerNodeIP, podCIDRs); err != nil {
+			return fmt.Errorf("failed to update WireGuard peer of Node %s: %v", nodeName, err)
+		}
+	}
+
 	ipsecTunOFPort := int32(0)
-	if c.networkConfig.EnableIPSecTunnel {
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeIPSec {
 		// Create a separate tunnel port for the Node, as OVS IPSec monitor needs to
 		// read PSK and remote IP from the Node's tunnel interface to create IPSec
 		// security policies.
@@ -491,10 +544,32 @@ func (c *Controller) addNodeRoute(nodeName string, node *corev1.Node) error {
 		podCIDRs:  podCIDRs,
 		nodeIP:    peerNodeIP,
 		gatewayIP: peerGatewayIPs,
+
+		wireGuardPublicKey: wireGuardPublicKey,
 	})
 	return err
 }
 
+// updateWireGuardPeer updates the WireGuard peer of an installed Node if its
+// public key changed, e.g. after the WireGuard device of the Node was recreated.
+func (c *Controller) updateWireGuardPeer(node *corev1.Node, info *nodeRouteInfo) error {
+	if c.networkConfig.TrafficEncryptionMode != config.TrafficEncryptionModeWireGuard {
+		return nil
+	}
+	publicKey := node.Annotations[wireguard.NodeWireGuardPublicKeyAnnotationKey]
+	if publicKey == "" || publicKey == info.wireGuardPublicKey {
+		return nil
+	}
+	klog.Infof("Updating WireGuard peer of Node %s", node.Name)
+	if err := c.wireGuardClient.UpdatePeer(node.Name, publicKey, info.nodeIP, info.podCIDRs); err != nil {
+		return fmt.Errorf("failed to update WireGuard peer of Node %s: %v", node.Name, err)
+	}
+	newInfo := *info
+	newInfo.wireGuardPublicKey = publicKey
+	c.installedNodes.Update(&newInfo)
+	return nil
+}
+
 func getPodCIDRsOnNode(node *corev1.Node) []string {
 	if node.Spec.PodCIDRs != nil {
 		return node.Spec.PodCIDRs
@@ -522,6 +597,7 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 	} else {
 		portName := util.GenerateNodeTunnelInterfaceName(nodeName)
 		ovsExternalIDs := map[string]interface{}{ovsExternalIDNodeName: nodeName}
+		remoteName, psk := c.getIPSecAuthenticationOptions(nodeName)
 		portUUID, err := c.ovsBridgeClient.CreateTunnelPortExt(
 			portName,
 			c.networkConfig.TunnelType,
@@ -529,7 +605,8 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 			false,
 			"",
 			nodeIP.String(),
-			c.networkConfig.IPSecPSK,
+			remoteName,
+			psk,
 			ovsExternalIDs)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create IPSec tunnel port for Node %s", nodeName)
@@ -542,7 +619,8 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 			c.networkConfig.TunnelType,
 			nodeName,
 			nodeIP,
-			c.networkConfig.IPSecPSK)
+			remoteName,
+			psk)
 		interfaceConfig.OVSPortConfig = ovsPortConfig
 		c.interfaceStore.AddInterface(interfaceConfig)
 	}
@@ -558,9 +636,21 @@ func (c *Controller) createIPSecTunnelPort(nodeName string, nodeIP net.IP) (int3
 	return ofPort, nil
 }
 
+// getIPSecAuthenticationOptions returns the remote name and the PSK of the IPSec
+// tunnel to the remote Node. With certificate authentication, the certificate
+// of the remote Node must have the Node name as its common name, and no PSK is
+// used.
+func (c *Controller) getIPSecAuthenticationOptions(nodeName string) (string, string) {
+	if c.networkConfig.IPsecAuthenticationMode == config.IPsecAuthenticationModeCert {
+		return nodeName, ""
+	}
+	return "", c.networkConfig.IPSecPSK
+}
+
 // ParseTunnelInterfaceConfig initializes and returns an InterfaceConfig struct
-// for a tunnel interface. It reads tunnel type, remote IP, IPSec PSK from the
-// OVS interface options, and NodeName from the OVS port external_ids.
+// for a tunnel interface. It reads tunnel type, remote IP, IPSec remote name and
+// PSK from the OVS interface options, and NodeName from the OVS port
+// external_ids.
 // nil is returned, if the OVS port and interface configurations are not valid
 // for a tunnel interface.
 func ParseTunnelInterfaceConfig(
@@ -570,19 +660,20 @@ func ParseTunnelInterfaceConfig(
 		klog.V(2).Infof("OVS port %s has no options", portData.Name)
 		return nil
 	}
-	remoteIP, localIP, psk, csum := ovsconfig.ParseTunnelInterfaceOptions(portData)
+	remoteIP, localIP, remoteName, psk, csum := ovsconfig.ParseTunnelInterfaceOptions(portData)
 
 	var interfaceConfig *interfacestore.InterfaceConfig
 	var nodeName string
 	if portData.ExternalIDs != nil {
 		nodeName = portData.ExternalIDs[ovsExternalIDNodeName]
 	}
-	if psk != "" {
+	if remoteName != "" || psk != "" {
 		interfaceConfig = interfacestore.NewIPSecTunnelInterface(
 			portData.Name,
 			ovsconfig.TunnelType(portData.IFType),
 			nodeName,
 			remoteIP,
+			remoteName,
 			psk)
 	} else {
 		interfaceConfig = interfacestore.NewTunnelInterface(portData.Name, ovsconfig.TunnelType(portData.IFType), localIP, csum)
@@ -22,6 +22,8 @@ import (
 
 	"github.com/containernetworking/plugins/pkg/ip"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
@@ -31,6 +33,10 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/interfacestore"
 	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
 	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/agent/wireguard"
+	wireguardtest "github.com/vmware-tanzu/antrea/pkg/agent/wireguard/testing"
+	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	ovsconfigtest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig/testing"
 )
 
@@ -49,21 +55,23 @@ type fakeController struct {
 	ofClient        *oftest.MockClient
 	ovsClient       *ovsconfigtest.MockOVSBridgeClient
 	routeClient     *routetest.MockInterface
+	wireGuardClient *wireguardtest.MockInterface
 	interfaceStore  interfacestore.InterfaceStore
 }
 
-func newController(t *testing.T) (*fakeController, func()) {
+func newController(t *testing.T, networkConfig *config.NetworkConfig) (*fakeController, func()) {
 	clientset := fake.NewSimpleClientset()
 	informerFactory := informers.NewSharedInformerFactory(clientset, 12*time.Hour)
 	ctrl := gomock.NewController(t)
 	ofClient := oftest.NewMockClient(ctrl)
 	ovsClient := ovsconfigtest.NewMockOVSBridgeClient(ctrl)
 	routeClient := routetest.NewMockInterface(ctrl)
+	wireGuardClient := wireguardtest.NewMockInterface(ctrl)
 	interfaceStore := interfacestore.NewInterfaceStore()
-	c := NewNodeRouteController(clientset, informerFactory, ofClient, ovsClient, routeClient, interfaceStore, &config.NetworkConfig{}, &config.NodeConfig{GatewayConfig: &config.GatewayConfig{
+	c := NewNodeRouteController(clientset, informerFactory, ofClient, ovsClient, routeClient, interfaceStore, networkConfig, &config.NodeConfig{GatewayConfig: &config.GatewayConfig{
 		IPv4: nil,
 		MAC:  gatewayMAC,
-	}})
+	}}, wireGuardClient)
 	return &fakeController{
 		Controller:      c,
 		clientset:       clientset,
@@ -71,12 +79,13 @@ func newController(t *testing.T) (*fakeController, func()) {
 		ofClient:        ofClient,
 		ovsClient:       ovsClient,
 		routeClient:     routeClient,
+		wireGuardClient: wireGuardClient,
 		interfaceStore:  interfaceStore,
 	}, ctrl.Finish
 }
 
 func TestControllerWithDuplicatePodCIDR(t *testing.T) {
-	c, closeFn := newController(t)
+	c, closeFn := newController(t, &config.NetworkConfig{})
 	defer closeFn()
 	defer c.queue.ShutDown()
 
@@ -154,3 +163,123 @@ func TestControllerWithDuplicatePodCIDR(t *testing.T) {
 	case <-finishCh:
 	}
 }
+
+func TestControllerWithWireGuard(t *testing.T) {
+	c, closeFn := newController(t, &config.NetworkConfig{TrafficEncryptionMode: config.TrafficEncryptionModeWireGuard})
+	defer closeFn()
+	defer c.queue.ShutDown()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.informerFactory.Start(stopCh)
+
+	publicKey1 := "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	publicKey2 := "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="
+	node1 := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Spec: corev1.NodeSpec{
+			PodCIDR:  podCIDR.String(),
+			PodCIDRs: []string{podCIDR.String()},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{
+					Type:    corev1.NodeInternalIP,
+					Address: nodeIP1.String(),
+				},
+			},
+		},
+	}
+
+	finishCh := make(chan struct{})
+	go func() {
+		defer close(finishCh)
+
+		// Nothing should be installed before the Node publishes its public key.
+		c.clientset.CoreV1().Nodes().Create(context.TODO(), node1, metav1.CreateOptions{})
+		c.processNextWorkItem()
+
+		node1.Annotations = map[string]string{wireguard.NodeWireGuardPublicKeyAnnotationKey: publicKey1}
+		c.clientset.CoreV1().Nodes().Update(context.TODO(), node1, metav1.UpdateOptions{})
+		c.wireGuardClient.EXPECT().UpdatePeer("node1", publicKey1, nodeIP1, []*net.IPNet{podCIDR}).Times(1)
+		c.ofClient.EXPECT().InstallNodeFlows("node1", gomock.Any(), nodeIP1, uint32(0)).Times(1)
+		c.routeClient.EXPECT().AddRoutes(podCIDR, nodeIP1, podCIDRGateway).Times(1)
+		c.processNextWorkItem()
+
+		// Only the WireGuard peer should be updated when the public key changes.
+		node1.Annotations = map[string]string{wireguard.NodeWireGuardPublicKeyAnnotationKey: publicKey2}
+		c.clientset.CoreV1().Nodes().Update(context.TODO(), node1, metav1.UpdateOptions{})
+		c.wireGuardClient.EXPECT().UpdatePeer("node1", publicKey2, nodeIP1, []*net.IPNet{podCIDR}).Times(1)
+		c.processNextWorkItem()
+
+		c.clientset.CoreV1().Nodes().Delete(context.TODO(), node1.Name, metav1.DeleteOptions{})
+		c.ofClient.EXPECT().UninstallNodeFlows("node1").Times(1)
+		c.routeClient.EXPECT().DeleteRoutes(podCIDR).Times(1)
+		c.wireGuardClient.EXPECT().DeletePeer("node1").Times(1)
+		c.processNextWorkItem()
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Errorf("Test didn't finish in time")
+	case <-finishCh:
+	}
+}
+
+func TestControllerWithIPsecCertAuth(t *testing.T) {
+	c, closeFn := newController(t, &config.NetworkConfig{
+		TunnelType:              ovsconfig.GRETunnel,
+		TrafficEncryptionMode:   config.TrafficEncryptionModeIPSec,
+		IPsecAuthenticationMode: config.IPsecAuthenticationModeCert,
+	})
+	defer closeFn()
+	defer c.queue.ShutDown()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.informerFactory.Start(stopCh)
+
+	node1 := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Spec: corev1.NodeSpec{
+			PodCIDR:  podCIDR.String(),
+			PodCIDRs: []string{podCIDR.String()},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{
+					Type:    corev1.NodeInternalIP,
+					Address: nodeIP1.String(),
+				},
+			},
+		},
+	}
+
+	finishCh := make(chan struct{})
+	go func() {
+		defer close(finishCh)
+
+		// The tunnel port expects the certificate of the peer Node instead of a PSK.
+		c.clientset.CoreV1().Nodes().Create(context.TODO(), node1, metav1.CreateOptions{})
+		portName := util.GenerateNodeTunnelInterfaceName("node1")
+		c.ovsClient.EXPECT().CreateTunnelPortExt(portName, ovsconfig.TunnelType(ovsconfig.GRETunnel), int32(0), false, "", nodeIP1.String(), "node1", "", gomock.Any()).Return("port-uuid", nil).Times(1)
+		c.ovsClient.EXPECT().GetOFPort(portName).Return(int32(10), nil).Times(1)
+		c.ofClient.EXPECT().InstallNodeFlows("node1", gomock.Any(), nodeIP1, uint32(10)).Times(1)
+		c.routeClient.EXPECT().AddRoutes(podCIDR, nodeIP1, podCIDRGateway).Times(1)
+		c.processNextWorkItem()
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Errorf("Test didn't finish in time")
+	case <-finishCh:
+	}
+	interfaceConfig, ok := c.interfaceStore.GetNodeTunnelInterface("node1")
+	require.True(t, ok)
+	assert.Equal(t, "node1", interfaceConfig.RemoteName)
+	assert.Empty(t, interfaceConfig.PSK)
+}
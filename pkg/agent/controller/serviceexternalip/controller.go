@@ -0,0 +1,279 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/ipassigner"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+	crdlisters "github.com/vmware-tanzu/antrea/pkg/client/listers/core/v1alpha2"
+)
+
+const (
+	controllerName = "AntreaAgentServiceExternalIPController"
+	// How long to wait before retrying the processing of a Service external
+	// IP change.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// The assigned IPs are reconciled as a whole, so all the changes are
+	// processed by a single worker with the same key.
+	workerItemKey = "key"
+)
+
+// Controller assigns the ingress IPs of the LoadBalancer Services allocated
+// from ExternalIPPools to the local Node, when the Node is elected for them.
+// Each IP is owned by a single Node, which is elected among the Ready Nodes
+// selected by the ExternalIPPool with rendezvous hashing, so that all the
+// agents agree on the owner without coordination, and only the IPs owned by an
+// unavailable Node are moved to other Nodes.
+type Controller struct {
+	nodeName                   string
+	ipAssigner                 ipassigner.IPAssigner
+	externalIPPoolLister       crdlisters.ExternalIPPoolLister
+	externalIPPoolListerSynced cache.InformerSynced
+	nodeLister                 corelisters.NodeLister
+	nodeListerSynced           cache.InformerSynced
+	serviceLister              corelisters.ServiceLister
+	serviceListerSynced        cache.InformerSynced
+	queue                      workqueue.RateLimitingInterface
+}
+
+// NewServiceExternalIPController returns a Controller which processes the
+// ExternalIPPools, the Nodes and the Services.
+func NewServiceExternalIPController(
+	nodeName string,
+	ipAssigner ipassigner.IPAssigner,
+	informerFactory informers.SharedInformerFactory,
+	crdInformerFactory crdinformers.SharedInformerFactory) *Controller {
+	externalIPPoolInformer := crdInformerFactory.Core().V1alpha2().ExternalIPPools()
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	serviceInformer := informerFactory.Core().V1().Services()
+	c := &Controller{
+		nodeName:                   nodeName,
+		ipAssigner:                 ipAssigner,
+		externalIPPoolLister:       externalIPPoolInformer.Lister(),
+		externalIPPoolListerSynced: externalIPPoolInformer.Informer().HasSynced,
+		nodeLister:                 nodeInformer.Lister(),
+		nodeListerSynced:           nodeInformer.Informer().HasSynced,
+		serviceLister:              serviceInformer.Lister(),
+		serviceListerSynced:        serviceInformer.Informer().HasSynced,
+		queue:                      workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "serviceExternalIP"),
+	}
+	enqueue := func(obj interface{}) {
+		c.queue.Add(workerItemKey)
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, cur interface{}) {
+			enqueue(cur)
+		},
+		DeleteFunc: enqueue,
+	}
+	externalIPPoolInformer.Informer().AddEventHandler(handler)
+	serviceInformer.Informer().AddEventHandler(handler)
+	// Only the labels and the availability of the Nodes affect the election,
+	// the other updates such as the heartbeats are ignored.
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, cur interface{}) {
+			oldNode, oldOK := old.(*corev1.Node)
+			curNode, curOK := cur.(*corev1.Node)
+			if oldOK && curOK && labels.Equals(oldNode.Labels, curNode.Labels) && isNodeAvailable(oldNode) == isNodeAvailable(curNode) {
+				return
+			}
+			enqueue(cur)
+		},
+		DeleteFunc: enqueue,
+	})
+	return c
+}
+
+// Run starts the worker of the controller and blocks until stopCh is closed.
+// The assigned IPs are kept when the agent stops, so that the Services are
+// still reachable while the agent restarts.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.externalIPPoolListerSynced, c.nodeListerSynced, c.serviceListerSynced) {
+		return
+	}
+	c.queue.Add(workerItemKey)
+
+	go wait.Until(c.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if err := c.syncExternalIPs(); err == nil {
+		c.queue.Forget(obj)
+	} else {
+		c.queue.AddRateLimited(obj)
+		klog.Errorf("Error syncing Service external IPs, requeuing. Error: %v", err)
+	}
+	return true
+}
+
+func (c *Controller) syncExternalIPs() error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing Service external IPs. (%v)", time.Since(startTime))
+	}()
+
+	desiredIPs, err := c.getDesiredIPs()
+	if err != nil {
+		return err
+	}
+	assignedIPs := c.ipAssigner.AssignedIPs()
+	var errs []error
+	for ip := range assignedIPs.Difference(desiredIPs) {
+		if err := c.ipAssigner.UnassignIP(ip); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for ip := range desiredIPs.Difference(assignedIPs) {
+		if err := c.ipAssigner.AssignIP(ip); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error when reconciling Service external IPs: %v", errs)
+	}
+	return nil
+}
+
+// getDesiredIPs returns the ingress IPs of the Services allocated from
+// ExternalIPPools which the local Node is elected for.
+func (c *Controller) getDesiredIPs() (sets.String, error) {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	desiredIPs := sets.NewString()
+	// eligibleNodes caches the names of the Nodes eligible for each
+	// ExternalIPPool, nil if the ExternalIPPool doesn't exist or is invalid.
+	eligibleNodes := map[string][]string{}
+	for _, service := range services {
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		poolName := service.Annotations[v1alpha2.ExternalIPPoolAnnotationKey]
+		if poolName == "" {
+			continue
+		}
+		candidates, ok := eligibleNodes[poolName]
+		if !ok {
+			candidates = c.getEligibleNodes(poolName, nodes)
+			eligibleNodes[poolName] = candidates
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			ip := net.ParseIP(ingress.IP)
+			if ip == nil {
+				continue
+			}
+			if electNode(ip.String(), candidates) == c.nodeName {
+				desiredIPs.Insert(ip.String())
+			}
+		}
+	}
+	return desiredIPs, nil
+}
+
+// getEligibleNodes returns the names of the available Nodes selected by the
+// ExternalIPPool.
+func (c *Controller) getEligibleNodes(poolName string, nodes []*corev1.Node) []string {
+	pool, err := c.externalIPPoolLister.Get(poolName)
+	if err != nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.NodeSelector)
+	if err != nil {
+		klog.Errorf("Invalid nodeSelector in ExternalIPPool %s: %v", pool.Name, err)
+		return nil
+	}
+	var candidates []string
+	for _, node := range nodes {
+		if isNodeAvailable(node) && selector.Matches(labels.Set(node.Labels)) {
+			candidates = append(candidates, node.Name)
+		}
+	}
+	return candidates
+}
+
+// electNode returns the Node with the highest hash of the IP and the Node name,
+// or an empty string if there is no candidate. A Node leaving or joining the
+// candidates only affects the IPs it owns or will own.
+func electNode(ip string, candidates []string) string {
+	var elected string
+	var maxScore uint64
+	for _, candidate := range candidates {
+		h := fnv.New64a()
+		h.Write([]byte(ip))
+		h.Write([]byte{0})
+		h.Write([]byte(candidate))
+		score := h.Sum64()
+		if elected == "" || score > maxScore || (score == maxScore && candidate < elected) {
+			elected = candidate
+			maxScore = score
+		}
+	}
+	return elected
+}
+
+// isNodeAvailable returns whether the Node is Ready and not being deleted.
+func isNodeAvailable(node *corev1.Node) bool {
+	if node.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ipassignertest "github.com/vmware-tanzu/antrea/pkg/agent/ipassigner/testing"
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+func newNode(name string, ready bool, nodeLabels map[string]string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func newService(name, poolName string, ingressIPs ...string) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{v1alpha2.ExternalIPPoolAnnotationKey: poolName},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	for _, ingressIP := range ingressIPs {
+		service.Status.LoadBalancer.Ingress = append(service.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ingressIP})
+	}
+	return service
+}
+
+func newTestController(nodeName string, ipAssigner *ipassignertest.MockIPAssigner, objects []runtime.Object, crdObjects []runtime.Object) (*Controller, func()) {
+	client := fake.NewSimpleClientset(objects...)
+	crdClient := fakeversioned.NewSimpleClientset(crdObjects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	c := NewServiceExternalIPController(nodeName, ipAssigner, informerFactory, crdInformerFactory)
+	stopCh := make(chan struct{})
+	informerFactory.Start(stopCh)
+	crdInformerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	crdInformerFactory.WaitForCacheSync(stopCh)
+	return c, func() { close(stopCh) }
+}
+
+func TestSyncExternalIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ipAssigner := ipassignertest.NewMockIPAssigner(ctrl)
+
+	pool := &v1alpha2.ExternalIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1"},
+		Spec: v1alpha2.ExternalIPPoolSpec{
+			IPRanges:     []v1alpha2.IPRange{{CIDR: "10.10.10.0/24"}},
+			NodeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"external-ip": "true"}},
+		},
+	}
+	objects := []runtime.Object{
+		// node1 is the only Node selected by the ExternalIPPool.
+		newNode("node1", true, map[string]string{"external-ip": "true"}),
+		newNode("node2", true, nil),
+		newService("svc1", "pool1", "10.10.10.1"),
+		newService("svc2", "unknown-pool", "10.10.10.2"),
+	}
+	c, stop := newTestController("node1", ipAssigner, objects, []runtime.Object{pool})
+	defer stop()
+
+	// The IP of svc1 is assigned and the stale IP is unassigned.
+	ipAssigner.EXPECT().AssignedIPs().Return(sets.NewString("10.10.10.100"))
+	ipAssigner.EXPECT().UnassignIP("10.10.10.100").Return(nil)
+	ipAssigner.EXPECT().AssignIP("10.10.10.1").Return(nil)
+	require.NoError(t, c.syncExternalIPs())
+
+	// Nothing changes when the IP is already assigned.
+	ipAssigner.EXPECT().AssignedIPs().Return(sets.NewString("10.10.10.1"))
+	require.NoError(t, c.syncExternalIPs())
+
+	// The IP is unassigned from node2, which is not selected.
+	c2, stop2 := newTestController("node2", ipAssigner, objects, []runtime.Object{pool})
+	defer stop2()
+	ipAssigner.EXPECT().AssignedIPs().Return(sets.NewString("10.10.10.1"))
+	ipAssigner.EXPECT().UnassignIP("10.10.10.1").Return(nil)
+	require.NoError(t, c2.syncExternalIPs())
+}
+
+func TestElection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := &v1alpha2.ExternalIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1"},
+		Spec:       v1alpha2.ExternalIPPoolSpec{IPRanges: []v1alpha2.IPRange{{CIDR: "10.10.10.0/24"}}},
+	}
+	ips := []string{"10.10.10.1", "10.10.10.2", "10.10.10.3", "10.10.10.4", "10.10.10.5", "10.10.10.6"}
+	nodeNames := []string{"node1", "node2", "node3"}
+
+	// getOwners returns the Node elected for each IP by the agent of each
+	// Node, and checks that each IP is owned by exactly one Node.
+	getOwners := func(nodes []runtime.Object) map[string]string {
+		owners := map[string]string{}
+		objects := append([]runtime.Object{newService("svc", "pool1", ips...)}, nodes...)
+		for _, nodeName := range nodeNames {
+			ipAssigner := ipassignertest.NewMockIPAssigner(ctrl)
+			c, stop := newTestController(nodeName, ipAssigner, objects, []runtime.Object{pool})
+			ipAssigner.EXPECT().AssignedIPs().Return(sets.NewString())
+			ipAssigner.EXPECT().AssignIP(gomock.Any()).DoAndReturn(func(ip string) error {
+				if owner, exists := owners[ip]; exists {
+					t.Errorf("IP %s is assigned to both %s and %s", ip, owner, nodeName)
+				}
+				owners[ip] = nodeName
+				return nil
+			}).AnyTimes()
+			require.NoError(t, c.syncExternalIPs())
+			stop()
+		}
+		return owners
+	}
+
+	owners := getOwners([]runtime.Object{newNode("node1", true, nil), newNode("node2", true, nil), newNode("node3", true, nil)})
+	assert.Len(t, owners, len(ips))
+
+	// Only the IPs owned by node3 move when it becomes unavailable.
+	ownersAfterFailure := getOwners([]runtime.Object{newNode("node1", true, nil), newNode("node2", true, nil), newNode("node3", false, nil)})
+	assert.Len(t, ownersAfterFailure, len(ips))
+	for _, ip := range ips {
+		assert.NotEqual(t, "node3", ownersAfterFailure[ip])
+		if owners[ip] != "node3" {
+			assert.Equal(t, owners[ip], ownersAfterFailure[ip])
+		}
+	}
+}
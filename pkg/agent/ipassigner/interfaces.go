@@ -0,0 +1,36 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipassigner
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// IPAssigner makes the Node own and announce IPs which don't belong to any of
+// its interfaces, so that the traffic destined to them is sent to the Node.
+type IPAssigner interface {
+	// AssignIP should assign the IP to the Node and announce it to the
+	// local network. It should do nothing if the IP is already assigned,
+	// without error.
+	AssignIP(ip string) error
+
+	// UnassignIP should unassign the IP from the Node. It should do nothing
+	// if the IP is not assigned, without error.
+	UnassignIP(ip string) error
+
+	// AssignedIPs returns the IPs assigned to the Node, including the ones
+	// assigned before the agent restarted.
+	AssignedIPs() sets.String
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipassigner
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/arping"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/sysctl"
+)
+
+// ipAssigner assigns the IPs to a dummy device, so that the Node owns them
+// while the routes of the other interfaces are not affected. The IPv4
+// addresses are answered by the kernel on the transport interface, as ARP
+// requests are answered for any local address by default. The IPv6 addresses
+// are answered through proxy NDP entries on the transport interface.
+type ipAssigner struct {
+	// externalInterface is the interface which the IPs are announced on.
+	externalInterface *net.Interface
+	// dummyDevice is the device the IPs are assigned to.
+	dummyDevice netlink.Link
+
+	mutex       sync.Mutex
+	assignedIPs sets.String
+}
+
+// NewIPAssigner returns an IPAssigner which announces the IPs on the interface
+// of the provided transport IP, and restores the IPs assigned to the dummy
+// device before the agent restarted.
+func NewIPAssigner(nodeTransportIP net.IP, dummyDeviceName string) (IPAssigner, error) {
+	_, externalInterface, err := util.GetIPNetDeviceFromIP(nodeTransportIP)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting the interface of IP %s: %v", nodeTransportIP, err)
+	}
+	a := &ipAssigner{
+		externalInterface: externalInterface,
+		assignedIPs:       sets.NewString(),
+	}
+	if nodeTransportIP.To4() == nil {
+		if err := sysctl.EnsureSysctlNetValue(fmt.Sprintf("ipv6/conf/%s/proxy_ndp", externalInterface.Name), 1); err != nil {
+			return nil, err
+		}
+	}
+	dummyDevice, err := ensureDummyDevice(dummyDeviceName)
+	if err != nil {
+		return nil, err
+	}
+	a.dummyDevice = dummyDevice
+	if err := a.loadIPAddresses(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func ensureDummyDevice(name string) (netlink.Link, error) {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); !ok {
+			return nil, fmt.Errorf("error when getting dummy device %s: %v", name, err)
+		}
+		link = &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: name}}
+		if err := netlink.LinkAdd(link); err != nil {
+			return nil, fmt.Errorf("error when creating dummy device %s: %v", name, err)
+		}
+		klog.Infof("Created dummy device %s", name)
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return nil, fmt.Errorf("error when setting dummy device %s up: %v", name, err)
+	}
+	return link, nil
+}
+
+// loadIPAddresses restores the assigned IPs from the addresses of the dummy
+// device.
+func (a *ipAssigner) loadIPAddresses() error {
+	addresses, err := netlink.AddrList(a.dummyDevice, netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("error when listing the addresses of dummy device %s: %v", a.dummyDevice.Attrs().Name, err)
+	}
+	for _, address := range addresses {
+		// The IPv6 link-local address is not assigned by the IPAssigner.
+		if address.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		a.assignedIPs.Insert(address.IP.String())
+	}
+	return nil
+}
+
+func (a *ipAssigner) AssignIP(ip string) error {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return fmt.Errorf("invalid IP %s", ip)
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.assignedIPs.Has(parsedIP.String()) {
+		return nil
+	}
+	// The address may already be assigned if a previous attempt failed after adding it.
+	if err := netlink.AddrAdd(a.dummyDevice, getIPAddress(parsedIP)); err != nil && !isExist(err) {
+		return fmt.Errorf("error when assigning IP %s to dummy device %s: %v", ip, a.dummyDevice.Attrs().Name, err)
+	}
+	if parsedIP.To4() != nil {
+		if err := arping.GratuitousARPOverIface(parsedIP, a.externalInterface); err != nil {
+			// The IP is still reachable after the ARP caches of the
+			// neighbors expire.
+			klog.Warningf("Failed to send gratuitous ARP for IP %s: %v", ip, err)
+		}
+	} else {
+		if err := netlink.NeighSet(a.getProxyNeighbor(parsedIP)); err != nil {
+			return fmt.Errorf("error when adding proxy NDP entry for IP %s: %v", ip, err)
+		}
+		if err := arping.GratuitousNDPOverIface(parsedIP, a.externalInterface); err != nil {
+			klog.Warningf("Failed to send unsolicited Neighbor Advertisement for IP %s: %v", ip, err)
+		}
+	}
+	a.assignedIPs.Insert(parsedIP.String())
+	klog.Infof("Assigned IP %s to dummy device %s", ip, a.dummyDevice.Attrs().Name)
+	return nil
+}
+
+func (a *ipAssigner) UnassignIP(ip string) error {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return fmt.Errorf("invalid IP %s", ip)
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.assignedIPs.Has(parsedIP.String()) {
+		return nil
+	}
+	if parsedIP.To4() == nil {
+		if err := netlink.NeighDel(a.getProxyNeighbor(parsedIP)); err != nil && !isNotExist(err) {
+			return fmt.Errorf("error when deleting proxy NDP entry for IP %s: %v", ip, err)
+		}
+	}
+	if err := netlink.AddrDel(a.dummyDevice, getIPAddress(parsedIP)); err != nil && !isNotExist(err) {
+		return fmt.Errorf("error when unassigning IP %s from dummy device %s: %v", ip, a.dummyDevice.Attrs().Name, err)
+	}
+	a.assignedIPs.Delete(parsedIP.String())
+	klog.Infof("Unassigned IP %s from dummy device %s", ip, a.dummyDevice.Attrs().Name)
+	return nil
+}
+
+func (a *ipAssigner) AssignedIPs() sets.String {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	// Return a copy as the set is updated by the other methods.
+	return sets.NewString(a.assignedIPs.UnsortedList()...)
+}
+
+func (a *ipAssigner) getProxyNeighbor(ip net.IP) *netlink.Neigh {
+	return &netlink.Neigh{
+		LinkIndex: a.externalInterface.Index,
+		Family:    netlink.FAMILY_V6,
+		Flags:     netlink.NTF_PROXY,
+		IP:        ip,
+	}
+}
+
+// getIPAddress returns the host address of the IP.
+func getIPAddress(ip net.IP) *netlink.Addr {
+	if ip.To4() != nil {
+		return &netlink.Addr{IPNet: &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}}
+	}
+	return &netlink.Addr{IPNet: &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}}
+}
+
+func isNotExist(err error) bool {
+	errno, ok := err.(syscall.Errno)
+	return ok && (errno == syscall.ENOENT || errno == syscall.EADDRNOTAVAIL)
+}
+
+func isExist(err error) bool {
+	errno, ok := err.(syscall.Errno)
+	return ok && errno == syscall.EEXIST
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package l7engine implements the node-local enforcement of the application
+// layer protocols of Antrea-native policy rules. It follows the byte stream
+// sent by the client of each connection and evaluates every request of the
+// stream.
+package l7engine
+
+import (
+	"bytes"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+const (
+	// maxRequestHeadSize is the maximum size of the request line and the
+	// headers of a request. The requests with a larger head are denied.
+	maxRequestHeadSize = 16 * 1024
+	// maxRetainedSize is the maximum number of inspected bytes retained to
+	// verify the retransmissions.
+	maxRetainedSize = 128 * 1024
+)
+
+// Verdict is the decision made by the engine for a TCP segment sent by the
+// client of a connection.
+type Verdict int
+
+const (
+	// VerdictUndecided means that the segment can't be inspected yet as some
+	// of the previous segments are missing. It must be dropped, and it will be
+	// inspected when the client retransmits it.
+	VerdictUndecided Verdict = iota
+	// VerdictAllow means that all the requests in the segment match one of
+	// the protocols, and that the segment can be forwarded.
+	VerdictAllow
+	// VerdictDeny means that a request in the segment doesn't match any of the
+	// protocols, and that the connection must be denied.
+	VerdictDeny
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictAllow:
+		return "Allow"
+	case VerdictDeny:
+		return "Deny"
+	default:
+		return "Undecided"
+	}
+}
+
+// Connection is the inspection state of the byte stream sent by the client of
+// a connection. The zero value is ready to inspect a new stream, whose first
+// byte is the first inspected byte unless Start is called with the initial
+// sequence number of the connection.
+type Connection struct {
+	// started is true once the sequence number of the stream is known.
+	started bool
+	// nextSeq is the sequence number of the next byte to inspect.
+	nextSeq uint32
+	// retained holds the last inspected bytes, which precede nextSeq.
+	retained []byte
+	// head buffers the incomplete head of the current request.
+	head []byte
+	// bodyLeft is the number of bytes left in the body of the current request.
+	bodyLeft int64
+	// denied is true once a request has been denied.
+	denied bool
+}
+
+// Start resets the state to inspect a new stream, with the initial sequence
+// number of the connection found in the SYN sent by the client. The first byte
+// of the stream follows the SYN in the sequence number space, so the segments
+// received out of order are not inspected before the first one, even if they
+// are the first segments received with a payload.
+func (c *Connection) Start(isn uint32) {
+	*c = Connection{started: true, nextSeq: isn + 1}
+}
+
+// Inspect evaluates the TCP segment with the provided sequence number and
+// payload sent by the client, with the protocols of the rule which allowed
+// the connection. The segment must be forwarded only if the verdict is
+// VerdictAllow. It also returns a short description of the last request
+// found in the segment for logging.
+//
+// Every request of the stream is evaluated once its head is complete, so the
+// segments carrying an incomplete head or a request body are allowed: the
+// server can't process a request before receiving its whole head. The
+// retransmitted bytes are allowed only if they are identical to the bytes
+// which were inspected, so that a client can't replace them after they were
+// discarded by the server.
+func (c *Connection) Inspect(seq uint32, payload []byte, protocols []v1beta2.L7Protocol) (Verdict, string) {
+	if c.denied {
+		return VerdictDeny, ""
+	}
+	if !c.started {
+		c.started = true
+		c.nextSeq = seq
+	}
+	// The difference is computed in the sequence number space, which wraps
+	// around.
+	offset := int32(seq - c.nextSeq)
+	if offset > 0 {
+		return VerdictUndecided, ""
+	}
+	if len(payload) == 0 {
+		return VerdictAllow, ""
+	}
+	// The bytes before nextSeq have already been inspected.
+	inspected := int64(-offset)
+	if inspected > int64(len(payload)) {
+		inspected = int64(len(payload))
+	}
+	if inspected > 0 {
+		start := int64(len(c.retained)) + int64(offset)
+		if start < 0 || !bytes.Equal(c.retained[start:start+inspected], payload[:inspected]) {
+			c.denied = true
+			return VerdictDeny, "TCP retransmission mismatch"
+		}
+	}
+	data := payload[inspected:]
+	if len(data) == 0 {
+		return VerdictAllow, ""
+	}
+	c.nextSeq += uint32(len(data))
+	c.retain(data)
+	verdict, request := c.inspectStream(data, protocols)
+	if verdict == VerdictDeny {
+		c.denied = true
+	}
+	return verdict, request
+}
+
+// retain appends the inspected bytes to the retained bytes, and discards the
+// oldest ones beyond maxRetainedSize.
+func (c *Connection) retain(data []byte) {
+	c.retained = append(c.retained, data...)
+	if extra := len(c.retained) - maxRetainedSize; extra > 0 {
+		c.retained = append(c.retained[:0], c.retained[extra:]...)
+	}
+}
+
+// inspectStream evaluates the requests in the next bytes of the stream.
+func (c *Connection) inspectStream(data []byte, protocols []v1beta2.L7Protocol) (Verdict, string) {
+	var request string
+	for len(data) > 0 {
+		if c.bodyLeft > 0 {
+			n := int64(len(data))
+			if n > c.bodyLeft {
+				n = c.bodyLeft
+			}
+			c.bodyLeft -= n
+			data = data[n:]
+			continue
+		}
+		c.head = append(c.head, data...)
+		end := bytes.Index(c.head, []byte("\r\n\r\n"))
+		if end < 0 {
+			if len(c.head) > maxRequestHeadSize {
+				return VerdictDeny, "HTTP request head too large"
+			}
+			if !isHTTPRequestPrefix(c.head) {
+				// The only supported protocol is HTTP, so any other payload
+				// is denied.
+				return VerdictDeny, "non-HTTP request"
+			}
+			// The rest of the head is in the next segments.
+			return VerdictAllow, request
+		}
+		end += len("\r\n\r\n")
+		req, err := parseHTTPRequest(c.head[:end])
+		if err != nil {
+			return VerdictDeny, err.Error()
+		}
+		request = req.String()
+		if !req.matchesAny(protocols) {
+			return VerdictDeny, request
+		}
+		// The bytes following the head belong to the body of the request or
+		// to the next requests.
+		data = c.head[end:]
+		c.head = nil
+		c.bodyLeft = req.contentLength
+	}
+	return VerdictAllow, request
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+var testProtocols = []v1beta2.L7Protocol{
+	{HTTP: &v1beta2.HTTPProtocol{Method: "GET", Path: "/api/*"}},
+	{HTTP: &v1beta2.HTTPProtocol{Host: "*.example.com", Path: "/healthz"}},
+}
+
+func TestInspectRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		payload         string
+		expectedVerdict Verdict
+		expectedRequest string
+	}{
+		{
+			name:            "no payload",
+			payload:         "",
+			expectedVerdict: VerdictAllow,
+		},
+		{
+			name:            "path prefix",
+			payload:         "GET /api/v1/users?limit=10 HTTP/1.1\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictAllow,
+			expectedRequest: "HTTP GET backend/api/v1/users",
+		},
+		{
+			name:            "method mismatch",
+			payload:         "DELETE /api/v1/users/1 HTTP/1.1\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP DELETE backend/api/v1/users/1",
+		},
+		{
+			name:            "subdomain with port",
+			payload:         "POST /healthz HTTP/1.1\r\nUser-Agent: curl\r\nhost: Web.Example.com:8080\r\n\r\n",
+			expectedVerdict: VerdictAllow,
+			expectedRequest: "HTTP POST web.example.com/healthz",
+		},
+		{
+			name:            "domain itself",
+			payload:         "GET /healthz HTTP/1.1\r\nHost: example.com\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP GET example.com/healthz",
+		},
+		{
+			name:            "exact path",
+			payload:         "GET /healthz/live HTTP/1.1\r\nHost: web.example.com\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP GET web.example.com/healthz/live",
+		},
+		{
+			name:            "missing host",
+			payload:         "GET /healthz HTTP/1.0\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP GET /healthz",
+		},
+		{
+			name:            "dot segments",
+			payload:         "GET /api/../admin HTTP/1.1\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP GET backend/admin",
+		},
+		{
+			name:            "percent-encoded dot segments",
+			payload:         "GET /api/%2e%2E/admin HTTP/1.1\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "HTTP GET backend/admin",
+		},
+		{
+			name:            "absolute target",
+			payload:         "POST http://web.example.com/healthz HTTP/1.1\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictAllow,
+			expectedRequest: "HTTP POST web.example.com/healthz",
+		},
+		{
+			name:            "duplicate host",
+			payload:         "POST /healthz HTTP/1.1\r\nHost: web.example.com\r\nHost: backend\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "ambiguous HTTP request host",
+		},
+		{
+			name:            "chunked body",
+			payload:         "GET /api/v1/users HTTP/1.1\r\nHost: backend\r\nTransfer-Encoding: chunked\r\n\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "unsupported HTTP request Transfer-Encoding",
+		},
+		{
+			name:            "incomplete request",
+			payload:         "GET /api/v1/us",
+			expectedVerdict: VerdictAllow,
+		},
+		{
+			name:            "non-HTTP",
+			payload:         "\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03\r\n",
+			expectedVerdict: VerdictDeny,
+			expectedRequest: "non-HTTP request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Connection{}
+			verdict, request := c.Inspect(1000, []byte(tt.payload), testProtocols)
+			assert.Equal(t, tt.expectedVerdict, verdict)
+			assert.Equal(t, tt.expectedRequest, request)
+		})
+	}
+}
+
+type segment struct {
+	seq     uint32
+	payload string
+}
+
+func TestInspectStream(t *testing.T) {
+	allowed := "GET /api/v1 HTTP/1.1\r\nHost: backend\r\n\r\n"
+	denied := "GET /admin HTTP/1.1\r\nHost: backend\r\n\r\n"
+	post := "POST /healthz HTTP/1.1\r\nHost: web.example.com\r\nContent-Length: 17\r\n\r\n"
+	tests := []struct {
+		name             string
+		segments         []segment
+		expectedVerdicts []Verdict
+	}{
+		{
+			name: "keep-alive requests",
+			segments: []segment{
+				{1000, ""},
+				{1000, allowed},
+				{1000 + uint32(len(allowed)), denied},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictAllow, VerdictDeny},
+		},
+		{
+			name: "pipelined requests",
+			segments: []segment{
+				{1000, allowed + denied},
+			},
+			expectedVerdicts: []Verdict{VerdictDeny},
+		},
+		{
+			name: "request split across segments",
+			segments: []segment{
+				{1000, "GET /adm"},
+				{1008, "in HTTP/1.1\r\nHost: backend\r\n\r\n"},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictDeny},
+		},
+		{
+			name: "body looking like a request",
+			segments: []segment{
+				{1000, post + denied[:10]},
+				{1000 + uint32(len(post)) + 10, denied[10:17] + allowed},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictAllow},
+		},
+		{
+			name: "request following a body",
+			segments: []segment{
+				{1000, post + "0123456789abcdefg" + denied},
+			},
+			expectedVerdicts: []Verdict{VerdictDeny},
+		},
+		{
+			name: "out of order segment",
+			segments: []segment{
+				{1000, ""},
+				{1008, "in HTTP/1.1\r\nHost: backend\r\n\r\n"},
+				{1000, "GET /adm"},
+				{1008, "in HTTP/1.1\r\nHost: backend\r\n\r\n"},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictUndecided, VerdictAllow, VerdictDeny},
+		},
+		{
+			name: "identical retransmission",
+			segments: []segment{
+				{1000, allowed},
+				{1000, allowed},
+				{1000 + uint32(len(allowed)) - 4, "\r\n\r\n" + allowed},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictAllow, VerdictAllow},
+		},
+		{
+			name: "modified retransmission",
+			segments: []segment{
+				{1000, allowed},
+				{1000, denied},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictDeny},
+		},
+		{
+			name: "sequence number wraparound",
+			segments: []segment{
+				{0xfffffff0, allowed},
+				{0xfffffff0 + uint32(len(allowed)), allowed},
+			},
+			expectedVerdicts: []Verdict{VerdictAllow, VerdictAllow},
+		},
+		{
+			name: "denied connection",
+			segments: []segment{
+				{1000, denied},
+				{1000 + uint32(len(denied)), ""},
+			},
+			expectedVerdicts: []Verdict{VerdictDeny, VerdictDeny},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Connection{}
+			var verdicts []Verdict
+			for _, s := range tt.segments {
+				verdict, _ := c.Inspect(s.seq, []byte(s.payload), testProtocols)
+				verdicts = append(verdicts, verdict)
+			}
+			assert.Equal(t, tt.expectedVerdicts, verdicts)
+		})
+	}
+}
+
+func TestInspectStartedStream(t *testing.T) {
+	allowed := "GET /api/v1 HTTP/1.1\r\nHost: backend\r\n\r\n"
+	c := &Connection{}
+	verdict, _ := c.Inspect(500, []byte("GET /adm"), testProtocols)
+	assert.Equal(t, VerdictAllow, verdict)
+
+	// The state of the previous stream is discarded, and the second segment of
+	// the new stream is not inspected before the first one.
+	c.Start(999)
+	verdict, _ = c.Inspect(1008, []byte(allowed[8:]), testProtocols)
+	assert.Equal(t, VerdictUndecided, verdict)
+	verdict, _ = c.Inspect(1000, []byte(allowed[:8]), testProtocols)
+	assert.Equal(t, VerdictAllow, verdict)
+	verdict, request := c.Inspect(1008, []byte(allowed[8:]), testProtocols)
+	assert.Equal(t, VerdictAllow, verdict)
+	assert.Equal(t, "HTTP GET backend/api/v1", request)
+}
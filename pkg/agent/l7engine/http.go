@@ -0,0 +1,240 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+// maxMethodLength is the maximum length of the method of an HTTP request.
+const maxMethodLength = 16
+
+var (
+	errNonHTTPRequest         = errors.New("non-HTTP request")
+	errInvalidHeader          = errors.New("invalid HTTP request header")
+	errInvalidPath            = errors.New("invalid HTTP request path")
+	errAmbiguousHost          = errors.New("ambiguous HTTP request host")
+	errInvalidContentLength   = errors.New("invalid HTTP request Content-Length")
+	errUnsupportedTransferEnc = errors.New("unsupported HTTP request Transfer-Encoding")
+)
+
+// httpRequest contains the fields of an HTTP request which can be matched by
+// a rule, and the length of its body.
+type httpRequest struct {
+	method string
+	host   string
+	// path is the normalized path of the request.
+	path          string
+	contentLength int64
+}
+
+func (r *httpRequest) String() string {
+	return fmt.Sprintf("HTTP %s %s%s", r.method, r.host, r.path)
+}
+
+// parseHTTPRequest parses the complete head of an HTTP request, i.e. the
+// request line and the headers up to the empty line. The requests whose
+// target or length may be interpreted differently by the server are
+// rejected.
+func parseHTTPRequest(head []byte) (*httpRequest, error) {
+	lines := strings.Split(strings.TrimSuffix(string(head), "\r\n\r\n"), "\r\n")
+	req, err := parseRequestLine(lines[0])
+	if err != nil {
+		return nil, err
+	}
+	hostFromTarget := req.host != ""
+	hostFound, lengthFound := false, false
+	for _, line := range lines[1:] {
+		i := strings.IndexByte(line, ':')
+		// Whitespace isn't allowed in the header name, which also rejects the
+		// obsolete line folding.
+		if i <= 0 || strings.ContainsAny(line[:i], " \t") {
+			return nil, errInvalidHeader
+		}
+		name, value := line[:i], strings.TrimSpace(line[i+1:])
+		switch {
+		case strings.EqualFold(name, "Host"):
+			if hostFound {
+				return nil, errAmbiguousHost
+			}
+			hostFound = true
+			// The host of an absolute target takes precedence over the header.
+			if !hostFromTarget {
+				req.host = normalizeHost(value)
+			}
+		case strings.EqualFold(name, "Content-Length"):
+			length, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || length < 0 || strings.HasPrefix(value, "+") || (lengthFound && length != req.contentLength) {
+				return nil, errInvalidContentLength
+			}
+			lengthFound = true
+			req.contentLength = length
+		case strings.EqualFold(name, "Transfer-Encoding"):
+			// The end of a chunked body can't be found without decoding it.
+			return nil, errUnsupportedTransferEnc
+		}
+	}
+	return req, nil
+}
+
+// parseRequestLine parses the method and the target of the request line of an
+// HTTP request.
+func parseRequestLine(line string) (*httpRequest, error) {
+	fields := strings.Split(line, " ")
+	if len(fields) != 3 || !isHTTPMethod(fields[0]) || !strings.HasPrefix(fields[2], "HTTP/") {
+		return nil, errNonHTTPRequest
+	}
+	req := &httpRequest{method: fields[0]}
+	target := fields[1]
+	if target == "*" {
+		// The asterisk form of the OPTIONS requests.
+		req.path = target
+		return req, nil
+	}
+	if !strings.HasPrefix(target, "/") {
+		// The absolute form, which includes the host.
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errInvalidPath
+		}
+		req.host = normalizeHost(u.Host)
+		target = u.EscapedPath()
+		if target == "" {
+			target = "/"
+		}
+	}
+	p, ok := normalizePath(target)
+	if !ok {
+		return nil, errInvalidPath
+	}
+	req.path = p
+	return req, nil
+}
+
+// normalizeHost returns the lower case host without the port.
+func normalizeHost(host string) string {
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// normalizePath decodes the percent-encoded octets of the path of a request
+// target and removes its dot segments, so that different encodings of the
+// same path are matched the same way. The query doesn't take part in the
+// match. The paths which servers may resolve differently, i.e. with encoded
+// slashes or backslashes, with empty segments or with control characters,
+// are rejected.
+func normalizePath(target string) (string, bool) {
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	lower := strings.ToLower(target)
+	if strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return "", false
+	}
+	p, err := url.PathUnescape(target)
+	if err != nil || !strings.HasPrefix(p, "/") || strings.Contains(p, "//") {
+		return "", false
+	}
+	for _, c := range []byte(p) {
+		if c < 0x20 || c == 0x7f || c == '\\' {
+			return "", false
+		}
+	}
+	cleaned := path.Clean(p)
+	// A trailing slash, also implied by a trailing dot segment, is kept as
+	// it's significant for the prefix match.
+	if cleaned != "/" && (strings.HasSuffix(p, "/") || strings.HasSuffix(p, "/.") || strings.HasSuffix(p, "/..")) {
+		cleaned += "/"
+	}
+	return cleaned, true
+}
+
+// isHTTPRequestPrefix returns whether the incomplete head of a request may be
+// the beginning of an HTTP request.
+func isHTTPRequestPrefix(head []byte) bool {
+	if i := bytes.Index(head, []byte("\r\n")); i >= 0 {
+		_, err := parseRequestLine(string(head[:i]))
+		return err == nil
+	}
+	method := string(head)
+	if i := strings.IndexByte(method, ' '); i >= 0 {
+		method = method[:i]
+	}
+	return isHTTPMethod(method)
+}
+
+// isHTTPMethod returns whether the token is a valid HTTP method name, which is
+// made of upper case letters.
+func isHTTPMethod(token string) bool {
+	if token == "" || len(token) > maxMethodLength {
+		return false
+	}
+	for _, c := range token {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny returns whether the HTTP request matches one of the protocols.
+func (r *httpRequest) matchesAny(protocols []v1beta2.L7Protocol) bool {
+	for _, protocol := range protocols {
+		if protocol.HTTP != nil && r.matches(protocol.HTTP) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the HTTP request matches all the non-empty fields
+// of the protocol.
+func (r *httpRequest) matches(http *v1beta2.HTTPProtocol) bool {
+	if http.Method != "" && http.Method != r.method {
+		return false
+	}
+	if http.Host != "" {
+		host := strings.ToLower(http.Host)
+		if strings.HasPrefix(host, "*.") {
+			if !strings.HasSuffix(r.host, host[1:]) {
+				return false
+			}
+		} else if host != r.host {
+			return false
+		}
+	}
+	if http.Path != "" {
+		if strings.HasSuffix(http.Path, "*") {
+			if !strings.HasPrefix(r.path, strings.TrimSuffix(http.Path, "*")) {
+				return false
+			}
+		} else if http.Path != r.path {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		target       string
+		expectedPath string
+		expectedOK   bool
+	}{
+		{"/", "/", true},
+		{"/api/v1?limit=10", "/api/v1", true},
+		{"/api/", "/api/", true},
+		{"/api/./v1/", "/api/v1/", true},
+		{"/api/..", "/", true},
+		{"/api/v1/..", "/api/", true},
+		{"/a/../admin", "/admin", true},
+		{"/%61pi/v1", "/api/v1", true},
+		{"/api/%2e%2e/admin", "/admin", true},
+		{"/../admin", "/admin", true},
+		{"/api%2f..%2fadmin", "", false},
+		{"/api%5C..%5Cadmin", "", false},
+		{"/api\\..\\admin", "", false},
+		{"/x//../api", "", false},
+		{"/api/%zz", "", false},
+		{"/api/%00", "", false},
+		{"api", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			path, ok := normalizePath(tt.target)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+}
@@ -116,6 +116,69 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		},
 	)
+
+	ProxyServiceConnectionCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_service_connection_count",
+			Help:           "Number of new connections load-balanced by AntreaProxy to the Endpoints of a Service port.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol"},
+	)
+
+	ProxyServicePacketCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_service_packet_count",
+			Help:           "Number of packets of the connections load-balanced by AntreaProxy to the Endpoints of a Service port.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol"},
+	)
+
+	ProxyServiceByteCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_service_byte_count",
+			Help:           "Number of bytes of the connections load-balanced by AntreaProxy to the Endpoints of a Service port.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol"},
+	)
+
+	ProxyServiceNoEndpointPacketCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_service_no_endpoint_packet_count",
+			Help:           "Number of packets to a Service port which were dropped by AntreaProxy because the Service port had no Endpoint.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol"},
+	)
+
+	ProxyEndpointConnectionCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_endpoint_connection_count",
+			Help:           "Number of new connections load-balanced by AntreaProxy to an Endpoint of a Service port.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol", "endpoint"},
+	)
+
+	ProxyEndpointPacketCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_endpoint_packet_count",
+			Help:           "Number of packets sent to an Endpoint of a Service port by the connections load-balanced to it by AntreaProxy.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol", "endpoint"},
+	)
+
+	ProxyEndpointByteCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "antrea_agent_proxy_endpoint_byte_count",
+			Help:           "Number of bytes sent to an Endpoint of a Service port by the connections load-balanced to it by AntreaProxy.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port", "protocol", "endpoint"},
+	)
 )
 
 func InitializePrometheusMetrics() {
@@ -125,6 +188,7 @@ func InitializePrometheusMetrics() {
 	InitializeNetworkPolicyMetrics()
 	InitializeOVSMetrics()
 	InitializeConnectionMetrics()
+	InitializeProxyMetrics()
 }
 
 func InitializePodMetrics() {
@@ -185,3 +249,27 @@ func InitializeConnectionMetrics() {
 		klog.Errorf("Failed to register antrea_agent_conntrack_max_connection_count with error: %v", err)
 	}
 }
+
+func InitializeProxyMetrics() {
+	if err := legacyregistry.Register(ProxyServiceConnectionCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_service_connection_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyServicePacketCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_service_packet_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyServiceByteCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_service_byte_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyServiceNoEndpointPacketCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_service_no_endpoint_packet_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyEndpointConnectionCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_endpoint_connection_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyEndpointPacketCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_endpoint_packet_count with error: %v", err)
+	}
+	if err := legacyregistry.Register(ProxyEndpointByteCount); err != nil {
+		klog.Errorf("Failed to register antrea_agent_proxy_endpoint_byte_count with error: %v", err)
+	}
+}
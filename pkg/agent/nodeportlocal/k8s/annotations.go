@@ -0,0 +1,35 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+const (
+	// NPLEnabledAnnotationKey is the annotation of the Services whose
+	// selected Pods get their container ports exposed with NodePortLocal,
+	// when its value is "true".
+	NPLEnabledAnnotationKey = "nodeportlocal.antrea.tanzu.vmware.com/enabled"
+	// NPLAnnotationKey is the annotation of the Pods which publishes the
+	// Node ports mapped to their container ports, as a JSON list of
+	// NPLAnnotation.
+	NPLAnnotationKey = "nodeportlocal.antrea.tanzu.vmware.com"
+)
+
+// NPLAnnotation is the mapping from a port of the Node to a container port of
+// the Pod.
+type NPLAnnotation struct {
+	PodPort  int    `json:"podPort"`
+	NodeIP   string `json:"nodeIP"`
+	NodePort int    `json:"nodePort"`
+	Protocol string `json:"protocol"`
+}
@@ -0,0 +1,421 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/portcache"
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules"
+)
+
+const (
+	controllerName = "AntreaAgentNodePortLocalController"
+	// How long to wait before retrying the processing of a Pod.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing the Pods.
+	defaultWorkers = 4
+)
+
+// How long to wait before retrying to restore the rules. It is a variable so
+// that it can be changed in tests.
+var restoreRulesRetryInterval = 5 * time.Second
+
+// NPLController exposes the container ports of the local Pods selected by the
+// Services annotated with NPLEnabledAnnotationKey on the ports of the Node, and
+// publishes the mappings in the NPLAnnotationKey annotation of the Pods, so
+// that external load balancers can send the traffic to the Pods directly
+// through the Node.
+type NPLController struct {
+	kubeClient          kubernetes.Interface
+	portTable           *portcache.PortTable
+	nodeIP              string
+	podLister           corelisters.PodLister
+	podListerSynced     cache.InformerSynced
+	serviceLister       corelisters.ServiceLister
+	serviceListerSynced cache.InformerSynced
+	queue               workqueue.RateLimitingInterface
+
+	// podIPsMutex protects podIPs.
+	podIPsMutex sync.Mutex
+	// podIPs maps the keys of the Pods to the IPs their rules were added
+	// for, so that the rules can be deleted after the Pods are deleted.
+	podIPs map[string]string
+}
+
+// NewNPLController returns a new *NPLController. podInformer must only watch
+// the Pods of the local Node.
+func NewNPLController(
+	kubeClient kubernetes.Interface,
+	podInformer coreinformers.PodInformer,
+	serviceInformer coreinformers.ServiceInformer,
+	portTable *portcache.PortTable,
+	nodeIP string) *NPLController {
+	c := &NPLController{
+		kubeClient:          kubeClient,
+		portTable:           portTable,
+		nodeIP:              nodeIP,
+		podLister:           podInformer.Lister(),
+		podListerSynced:     podInformer.Informer().HasSynced,
+		serviceLister:       serviceInformer.Lister(),
+		serviceListerSynced: serviceInformer.Informer().HasSynced,
+		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "nodePortLocal"),
+		podIPs:              map[string]string{},
+	}
+	podInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueuePod,
+			UpdateFunc: func(old, cur interface{}) {
+				c.enqueuePod(cur)
+			},
+			DeleteFunc: c.enqueuePod,
+		},
+	)
+	serviceInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueServicePods,
+			UpdateFunc: func(old, cur interface{}) {
+				// The Pods selected by the old Service may no longer
+				// be selected.
+				c.enqueueServicePods(old)
+				c.enqueueServicePods(cur)
+			},
+			DeleteFunc: c.enqueueServicePods,
+		},
+	)
+	return c
+}
+
+func (c *NPLController) enqueuePod(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get key of Pod: %v", err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueServicePods enqueues the local Pods selected by the Service if it's
+// annotated with NPLEnabledAnnotationKey.
+func (c *NPLController) enqueueServicePods(obj interface{}) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		service, ok = deletedState.Obj.(*corev1.Service)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Service object: %v", deletedState.Obj)
+			return
+		}
+	}
+	if !isNPLEnabled(service) {
+		return
+	}
+	pods, err := c.podLister.Pods(service.Namespace).List(labels.SelectorFromSet(service.Spec.Selector))
+	if err != nil {
+		klog.Errorf("Failed to list Pods of Service %s/%s: %v", service.Namespace, service.Name, err)
+		return
+	}
+	for _, pod := range pods {
+		c.enqueuePod(pod)
+	}
+}
+
+// isNPLEnabled returns whether NodePortLocal is enabled for the Pods selected
+// by the Service. A Service without selector selects no Pod.
+func isNPLEnabled(service *corev1.Service) bool {
+	return service.Annotations[NPLEnabledAnnotationKey] == "true" && len(service.Spec.Selector) > 0
+}
+
+// Run restores the rules of the Pods from their annotations, then starts the
+// workers processing the Pods. Restoring the rules is retried until it
+// succeeds, so that the Pods don't get new Node ports because of a transient
+// error.
+func (c *NPLController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.podListerSynced, c.serviceListerSynced) {
+		return
+	}
+	if err := wait.PollImmediateUntil(restoreRulesRetryInterval, func() (bool, error) {
+		if err := c.restoreRules(); err != nil {
+			klog.Errorf("Failed to restore NodePortLocal rules, retrying: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}, stopCh); err != nil {
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// restoreRules restores the rules from the annotations of the Pods, so that the
+// Pods keep their Node ports when the agent restarts.
+func (c *NPLController) restoreRules() error {
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	var allRules []rules.PodNodePort
+	c.podIPsMutex.Lock()
+	defer c.podIPsMutex.Unlock()
+	for _, pod := range pods {
+		value, exists := pod.Annotations[NPLAnnotationKey]
+		if !exists || pod.Status.PodIP == "" {
+			continue
+		}
+		var annotations []NPLAnnotation
+		if err := json.Unmarshal([]byte(value), &annotations); err != nil {
+			klog.Warningf("Ignoring invalid annotation %s of Pod %s/%s: %v", NPLAnnotationKey, pod.Namespace, pod.Name, err)
+			continue
+		}
+		key, _ := cache.MetaNamespaceKeyFunc(pod)
+		for _, annotation := range annotations {
+			allRules = append(allRules, rules.PodNodePort{
+				NodePort: annotation.NodePort,
+				PodPort:  annotation.PodPort,
+				PodIP:    pod.Status.PodIP,
+				Protocol: annotation.Protocol,
+			})
+		}
+		c.podIPs[key] = pod.Status.PodIP
+	}
+	return c.portTable.RestoreRules(allRules)
+}
+
+func (c *NPLController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *NPLController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncPod(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing NodePortLocal rules of Pod %s, requeuing. Error: %v", key, err)
+	}
+	return true
+}
+
+func (c *NPLController) syncPod(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing NodePortLocal rules of Pod %s. (%v)", key, time.Since(startTime))
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		pod = nil
+	}
+
+	var podIP string
+	if pod != nil && !pod.Spec.HostNetwork && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		podIP = pod.Status.PodIP
+	}
+	// Delete the rules of the previous IP of the Pod.
+	c.podIPsMutex.Lock()
+	oldPodIP := c.podIPs[key]
+	c.podIPsMutex.Unlock()
+	if oldPodIP != "" && oldPodIP != podIP {
+		if err := c.portTable.DeleteRulesForPod(oldPodIP); err != nil {
+			return err
+		}
+		c.setPodIP(key, "")
+	}
+	if pod == nil {
+		return nil
+	}
+
+	var annotations []NPLAnnotation
+	if podIP != "" {
+		if annotations, err = c.syncPodRules(pod, podIP); err != nil {
+			return err
+		}
+		c.setPodIP(key, podIP)
+	}
+	return c.updatePodAnnotation(pod, annotations)
+}
+
+func (c *NPLController) setPodIP(key, podIP string) {
+	c.podIPsMutex.Lock()
+	defer c.podIPsMutex.Unlock()
+	if podIP == "" {
+		delete(c.podIPs, key)
+	} else {
+		c.podIPs[key] = podIP
+	}
+}
+
+// syncPodRules adds the rules of the container ports of the Pod if it's
+// selected by a Service with NodePortLocal enabled, deletes the stale rules,
+// and returns the annotations of the rules.
+func (c *NPLController) syncPodRules(pod *corev1.Pod, podIP string) ([]NPLAnnotation, error) {
+	desiredPorts := map[string]bool{}
+	selected, err := c.isPodSelected(pod)
+	if err != nil {
+		return nil, err
+	}
+	if selected {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				protocol := getProtocol(port.Protocol)
+				if protocol == "" {
+					continue
+				}
+				desiredPorts[fmt.Sprintf("%d/%s", port.ContainerPort, protocol)] = true
+			}
+		}
+	}
+
+	for _, entry := range c.portTable.GetEntriesByPodIP(podIP) {
+		if desiredPorts[fmt.Sprintf("%d/%s", entry.PodPort, entry.Protocol)] {
+			continue
+		}
+		if err := c.portTable.DeleteRule(podIP, entry.PodPort, entry.Protocol); err != nil {
+			return nil, err
+		}
+	}
+	var annotations []NPLAnnotation
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := getProtocol(port.Protocol)
+			if !desiredPorts[fmt.Sprintf("%d/%s", port.ContainerPort, protocol)] {
+				continue
+			}
+			nodePort, err := c.portTable.AddRule(podIP, int(port.ContainerPort), protocol)
+			if err != nil {
+				return nil, fmt.Errorf("error when adding NodePortLocal rule for %s:%d/%s: %v", podIP, port.ContainerPort, protocol, err)
+			}
+			annotations = append(annotations, NPLAnnotation{
+				PodPort:  int(port.ContainerPort),
+				NodeIP:   c.nodeIP,
+				NodePort: nodePort,
+				Protocol: protocol,
+			})
+		}
+	}
+	sort.Slice(annotations, func(i, j int) bool {
+		if annotations[i].PodPort != annotations[j].PodPort {
+			return annotations[i].PodPort < annotations[j].PodPort
+		}
+		return annotations[i].Protocol < annotations[j].Protocol
+	})
+	return annotations, nil
+}
+
+// isPodSelected returns whether the Pod is selected by a Service with
+// NodePortLocal enabled.
+func (c *NPLController) isPodSelected(pod *corev1.Pod) (bool, error) {
+	services, err := c.serviceLister.Services(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, service := range services {
+		if isNPLEnabled(service) && labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getProtocol returns the protocol of the container port as used by the rules,
+// or an empty string if the protocol is not supported.
+func getProtocol(protocol corev1.Protocol) string {
+	switch protocol {
+	case "", corev1.ProtocolTCP:
+		return "tcp"
+	case corev1.ProtocolUDP:
+		return "udp"
+	}
+	return ""
+}
+
+// updatePodAnnotation sets the NPLAnnotationKey annotation of the Pod to the
+// provided annotations, or removes it if there is none.
+func (c *NPLController) updatePodAnnotation(pod *corev1.Pod, annotations []NPLAnnotation) error {
+	var value interface{}
+	if len(annotations) > 0 {
+		data, err := json.Marshal(annotations)
+		if err != nil {
+			return err
+		}
+		if pod.Annotations[NPLAnnotationKey] == string(data) {
+			return nil
+		}
+		value = string(data)
+	} else if _, exists := pod.Annotations[NPLAnnotationKey]; !exists {
+		return nil
+	}
+	// A nil value removes the annotation.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{NPLAnnotationKey: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error when patching annotation of Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
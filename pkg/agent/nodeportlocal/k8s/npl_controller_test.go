@@ -0,0 +1,248 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/portcache"
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules"
+	rulestesting "github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules/testing"
+)
+
+const (
+	testNodeIP    = "192.168.1.1"
+	testStartPort = 40000
+	testEndPort   = 40100
+)
+
+type fakeSocket struct{}
+
+func (s *fakeSocket) Close() error {
+	return nil
+}
+
+type fakePortOpener struct{}
+
+func (o *fakePortOpener) OpenLocalPort(port int, protocol string) (io.Closer, error) {
+	return &fakeSocket{}, nil
+}
+
+func newTestPod(name, podIP string, podLabels map[string]string, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: podLabels, Annotations: annotations},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "app",
+				Ports: []corev1.ContainerPort{
+					{ContainerPort: 80, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 53, Protocol: corev1.ProtocolUDP},
+					{ContainerPort: 9000, Protocol: corev1.ProtocolSCTP},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{PodIP: podIP, Phase: corev1.PodRunning},
+	}
+}
+
+func newTestService(name string, enabled bool) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	}
+	if enabled {
+		service.Annotations = map[string]string{NPLEnabledAnnotationKey: "true"}
+	}
+	return service
+}
+
+type testData struct {
+	client    *fake.Clientset
+	mockRules *rulestesting.MockPodPortRules
+	portTable *portcache.PortTable
+}
+
+func setUp(t *testing.T, objects ...runtime.Object) *testData {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	portTable := portcache.NewPortTable(testStartPort, testEndPort, mockRules, &fakePortOpener{})
+	client := fake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	c := NewNPLController(client, informerFactory.Core().V1().Pods(), informerFactory.Core().V1().Services(), portTable, testNodeIP)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	informerFactory.Start(stopCh)
+	go c.Run(stopCh)
+	return &testData{client: client, mockRules: mockRules, portTable: portTable}
+}
+
+func (d *testData) waitForAnnotations(t *testing.T, podName string, expectedPodPorts ...int) []NPLAnnotation {
+	var annotations []NPLAnnotation
+	err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		pod, err := d.client.CoreV1().Pods("default").Get(context.TODO(), podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		annotations = nil
+		value, exists := pod.Annotations[NPLAnnotationKey]
+		if exists {
+			if err := json.Unmarshal([]byte(value), &annotations); err != nil {
+				return false, err
+			}
+		}
+		if len(annotations) != len(expectedPodPorts) {
+			return false, nil
+		}
+		for i := range annotations {
+			if annotations[i].PodPort != expectedPodPorts[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	require.NoError(t, err, "Expected annotations for Pod ports %v, got %v", expectedPodPorts, annotations)
+	return annotations
+}
+
+func TestPodAddedAndRemoved(t *testing.T) {
+	pod := newTestPod("pod1", "10.0.0.1", map[string]string{"app": "web"}, nil)
+	d := setUp(t, newTestService("svc1", true), pod)
+	d.mockRules.EXPECT().AddAllRules(gomock.Len(0)).Return(nil)
+	d.mockRules.EXPECT().AddRule(gomock.Any(), "10.0.0.1", 53, "udp").Return(nil)
+	d.mockRules.EXPECT().AddRule(gomock.Any(), "10.0.0.1", 80, "tcp").Return(nil)
+
+	// The SCTP port is not exposed.
+	annotations := d.waitForAnnotations(t, "pod1", 53, 80)
+	for _, annotation := range annotations {
+		assert.Equal(t, testNodeIP, annotation.NodeIP)
+		assert.True(t, annotation.NodePort >= testStartPort && annotation.NodePort <= testEndPort)
+		entry := d.portTable.GetEntry("10.0.0.1", annotation.PodPort, annotation.Protocol)
+		require.NotNil(t, entry)
+		assert.Equal(t, annotation.NodePort, entry.NodePort)
+	}
+
+	// The rules are deleted when the Pod is deleted.
+	deleted := make(chan struct{}, 2)
+	d.mockRules.EXPECT().DeleteRule(gomock.Any(), "10.0.0.1", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(nodePort int, podIP string, podPort int, protocol string) error {
+			deleted <- struct{}{}
+			return nil
+		}).Times(2)
+	require.NoError(t, d.client.CoreV1().Pods("default").Delete(context.TODO(), "pod1", metav1.DeleteOptions{}))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-deleted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout when waiting for the rules to be deleted")
+		}
+	}
+}
+
+func TestServiceAnnotationRemoved(t *testing.T) {
+	pod := newTestPod("pod1", "10.0.0.1", map[string]string{"app": "web"}, nil)
+	service := newTestService("svc1", true)
+	d := setUp(t, service, pod, newTestPod("pod2", "10.0.0.2", map[string]string{"app": "db"}, nil))
+	d.mockRules.EXPECT().AddAllRules(gomock.Len(0)).Return(nil)
+	d.mockRules.EXPECT().AddRule(gomock.Any(), "10.0.0.1", gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	d.waitForAnnotations(t, "pod1", 53, 80)
+	// pod2 is not selected by the Service.
+	d.waitForAnnotations(t, "pod2")
+
+	d.mockRules.EXPECT().DeleteRule(gomock.Any(), "10.0.0.1", gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	service.Annotations = nil
+	_, err := d.client.CoreV1().Services("default").Update(context.TODO(), service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	d.waitForAnnotations(t, "pod1")
+	assert.Empty(t, d.portTable.GetEntriesByPodIP("10.0.0.1"))
+}
+
+func TestRestoreRules(t *testing.T) {
+	existing := []NPLAnnotation{
+		{PodPort: 53, NodeIP: testNodeIP, NodePort: 40050, Protocol: "udp"},
+		{PodPort: 80, NodeIP: testNodeIP, NodePort: 40060, Protocol: "tcp"},
+	}
+	data, _ := json.Marshal(existing)
+	pod := newTestPod("pod1", "10.0.0.1", map[string]string{"app": "web"}, map[string]string{NPLAnnotationKey: string(data)})
+	d := setUp(t, newTestService("svc1", true), pod)
+
+	// The Pod keeps its Node ports after the agent restarts.
+	d.mockRules.EXPECT().AddAllRules([]rules.PodNodePort{
+		{NodePort: 40050, PodPort: 53, PodIP: "10.0.0.1", Protocol: "udp"},
+		{NodePort: 40060, PodPort: 80, PodIP: "10.0.0.1", Protocol: "tcp"},
+	}).Return(nil)
+	annotations := d.waitForAnnotations(t, "pod1", 53, 80)
+	assert.Equal(t, existing, annotations)
+	assert.Eventually(t, func() bool {
+		return d.portTable.GetEntry("10.0.0.1", 80, "tcp") != nil
+	}, time.Second, 10*time.Millisecond, fmt.Sprintf("Rule of Pod %s not restored", pod.Name))
+}
+
+func TestRestoreRulesRetry(t *testing.T) {
+	oldInterval := restoreRulesRetryInterval
+	restoreRulesRetryInterval = 50 * time.Millisecond
+	defer func() { restoreRulesRetryInterval = oldInterval }()
+
+	existing := []NPLAnnotation{
+		{PodPort: 53, NodeIP: testNodeIP, NodePort: 40050, Protocol: "udp"},
+		{PodPort: 80, NodeIP: testNodeIP, NodePort: 40060, Protocol: "tcp"},
+	}
+	data, _ := json.Marshal(existing)
+	pod := newTestPod("pod1", "10.0.0.1", map[string]string{"app": "web"}, map[string]string{NPLAnnotationKey: string(data)})
+	restored := []rules.PodNodePort{
+		{NodePort: 40050, PodPort: 53, PodIP: "10.0.0.1", Protocol: "udp"},
+		{NodePort: 40060, PodPort: 80, PodIP: "10.0.0.1", Protocol: "tcp"},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	// The rules are restored again after a failure, and the Pod keeps its Node
+	// port.
+	gomock.InOrder(
+		mockRules.EXPECT().AddAllRules(restored).Return(fmt.Errorf("iptables error")),
+		mockRules.EXPECT().AddAllRules(restored).Return(nil),
+	)
+	portTable := portcache.NewPortTable(testStartPort, testEndPort, mockRules, &fakePortOpener{})
+	client := fake.NewSimpleClientset(newTestService("svc1", true), pod)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	c := NewNPLController(client, informerFactory.Core().V1().Pods(), informerFactory.Core().V1().Services(), portTable, testNodeIP)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	go c.Run(stopCh)
+
+	d := &testData{client: client, mockRules: mockRules, portTable: portTable}
+	annotations := d.waitForAnnotations(t, "pod1", 53, 80)
+	assert.Equal(t, existing, annotations)
+	assert.Eventually(t, func() bool {
+		data := portTable.GetEntry("10.0.0.1", 80, "tcp")
+		return data != nil && data.NodePort == 40060
+	}, 5*time.Second, 10*time.Millisecond, fmt.Sprintf("Rule of Pod %s not restored", pod.Name))
+}
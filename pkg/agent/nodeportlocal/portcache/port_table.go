@@ -0,0 +1,249 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package portcache
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules"
+)
+
+// LocalPortOpener opens a local port so that no other process of the Node can
+// bind to it while it's used by a NodePortLocal rule.
+type LocalPortOpener interface {
+	OpenLocalPort(port int, protocol string) (io.Closer, error)
+}
+
+type localPortOpener struct{}
+
+func (o *localPortOpener) OpenLocalPort(port int, protocol string) (io.Closer, error) {
+	address := fmt.Sprintf(":%d", port)
+	switch protocol {
+	case "tcp":
+		return net.Listen("tcp", address)
+	case "udp":
+		return net.ListenPacket("udp", address)
+	}
+	return nil, fmt.Errorf("unsupported protocol %s", protocol)
+}
+
+// NodePortData is the mapping from a port of the Node to a port of a Pod.
+type NodePortData struct {
+	NodePort int
+	PodPort  int
+	PodIP    string
+	Protocol string
+	// socket is the socket reserving the Node port.
+	socket io.Closer
+}
+
+// PortTable allocates the Node ports from a range, and maintains the rules
+// forwarding them to the Pods.
+type PortTable struct {
+	StartPort       int
+	EndPort         int
+	PodPortRules    rules.PodPortRules
+	LocalPortOpener LocalPortOpener
+
+	tableLock sync.RWMutex
+	// nodePortTable maps the Node ports to their data.
+	nodePortTable map[int]*NodePortData
+	// podEndpointTable maps the Pod endpoints, keyed by podEndpointKey, to
+	// their data.
+	podEndpointTable map[string]*NodePortData
+}
+
+// InitPortTable returns a PortTable which allocates the ports in [start, end]
+// and reserves them with local sockets, and initializes the rules of the
+// platform.
+func InitPortTable(start, end int) (*PortTable, error) {
+	ptable := NewPortTable(start, end, rules.InitRules(), &localPortOpener{})
+	if err := ptable.PodPortRules.Init(); err != nil {
+		return nil, err
+	}
+	return ptable, nil
+}
+
+// NewPortTable returns a PortTable which allocates the ports in [start, end]
+// with the provided rules and LocalPortOpener.
+func NewPortTable(start, end int, podPortRules rules.PodPortRules, portOpener LocalPortOpener) *PortTable {
+	return &PortTable{
+		StartPort:        start,
+		EndPort:          end,
+		PodPortRules:     podPortRules,
+		LocalPortOpener:  portOpener,
+		nodePortTable:    map[int]*NodePortData{},
+		podEndpointTable: map[string]*NodePortData{},
+	}
+}
+
+func podEndpointKey(podIP string, podPort int, protocol string) string {
+	return fmt.Sprintf("%s:%d:%s", podIP, podPort, protocol)
+}
+
+// GetEntry returns the data of the Pod endpoint, or nil if it has no Node port.
+func (pt *PortTable) GetEntry(podIP string, podPort int, protocol string) *NodePortData {
+	pt.tableLock.RLock()
+	defer pt.tableLock.RUnlock()
+	return pt.podEndpointTable[podEndpointKey(podIP, podPort, protocol)]
+}
+
+// GetEntriesByPodIP returns the data of all the endpoints of the Pod IP.
+func (pt *PortTable) GetEntriesByPodIP(podIP string) []NodePortData {
+	pt.tableLock.RLock()
+	defer pt.tableLock.RUnlock()
+	var entries []NodePortData
+	for _, data := range pt.nodePortTable {
+		if data.PodIP == podIP {
+			entries = append(entries, *data)
+		}
+	}
+	return entries
+}
+
+// openFreePort returns a free port of the range, which is opened until the
+// rule using it is deleted. It must be called with tableLock held.
+func (pt *PortTable) openFreePort(protocol string) (int, io.Closer, error) {
+	for port := pt.StartPort; port <= pt.EndPort; port++ {
+		if _, exists := pt.nodePortTable[port]; exists {
+			continue
+		}
+		socket, err := pt.LocalPortOpener.OpenLocalPort(port, protocol)
+		if err != nil {
+			// The port is used by another process.
+			klog.V(4).Infof("Port %d/%s is not available: %v", port, protocol, err)
+			continue
+		}
+		return port, socket, nil
+	}
+	return 0, nil, fmt.Errorf("no free port in range %d-%d", pt.StartPort, pt.EndPort)
+}
+
+// AddRule allocates a Node port to the Pod endpoint and adds the rule
+// forwarding it to the Pod, and returns the Node port. It returns the Node
+// port allocated previously if the Pod endpoint already has one.
+func (pt *PortTable) AddRule(podIP string, podPort int, protocol string) (int, error) {
+	pt.tableLock.Lock()
+	defer pt.tableLock.Unlock()
+	key := podEndpointKey(podIP, podPort, protocol)
+	if data, exists := pt.podEndpointTable[key]; exists {
+		return data.NodePort, nil
+	}
+	nodePort, socket, err := pt.openFreePort(protocol)
+	if err != nil {
+		return 0, err
+	}
+	if err := pt.PodPortRules.AddRule(nodePort, podIP, podPort, protocol); err != nil {
+		socket.Close()
+		return 0, err
+	}
+	data := &NodePortData{NodePort: nodePort, PodPort: podPort, PodIP: podIP, Protocol: protocol, socket: socket}
+	pt.nodePortTable[nodePort] = data
+	pt.podEndpointTable[key] = data
+	return nodePort, nil
+}
+
+// DeleteRule deletes the rule of the Pod endpoint and releases its Node port.
+func (pt *PortTable) DeleteRule(podIP string, podPort int, protocol string) error {
+	pt.tableLock.Lock()
+	defer pt.tableLock.Unlock()
+	data, exists := pt.podEndpointTable[podEndpointKey(podIP, podPort, protocol)]
+	if !exists {
+		return nil
+	}
+	return pt.deleteRule(data)
+}
+
+// DeleteRulesForPod deletes the rules of all the endpoints of the Pod IP.
+func (pt *PortTable) DeleteRulesForPod(podIP string) error {
+	pt.tableLock.Lock()
+	defer pt.tableLock.Unlock()
+	for _, data := range pt.nodePortTable {
+		if data.PodIP != podIP {
+			continue
+		}
+		if err := pt.deleteRule(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRule must be called with tableLock held.
+func (pt *PortTable) deleteRule(data *NodePortData) error {
+	if err := pt.PodPortRules.DeleteRule(data.NodePort, data.PodIP, data.PodPort, data.Protocol); err != nil {
+		return err
+	}
+	if data.socket != nil {
+		if err := data.socket.Close(); err != nil {
+			klog.Warningf("Failed to close the socket of port %d/%s: %v", data.NodePort, data.Protocol, err)
+		}
+	}
+	delete(pt.nodePortTable, data.NodePort)
+	delete(pt.podEndpointTable, podEndpointKey(data.PodIP, data.PodPort, data.Protocol))
+	return nil
+}
+
+// RestoreRules restores the rules which were installed before the agent
+// restarted, so that the Pod endpoints keep the same Node ports. The rules
+// which are not provided are removed. The rules whose Node port is out of
+// the range, or is already used by another rule or by another process, are
+// skipped, and the Pod endpoints will be allocated new Node ports. If the
+// rules cannot be installed, the Node ports are released so that restoring
+// can be retried.
+func (pt *PortTable) RestoreRules(allRules []rules.PodNodePort) error {
+	pt.tableLock.Lock()
+	defer pt.tableLock.Unlock()
+	var validRules []rules.PodNodePort
+	var restored []*NodePortData
+	for _, rule := range allRules {
+		if rule.NodePort < pt.StartPort || rule.NodePort > pt.EndPort {
+			continue
+		}
+		if _, exists := pt.nodePortTable[rule.NodePort]; exists {
+			continue
+		}
+		key := podEndpointKey(rule.PodIP, rule.PodPort, rule.Protocol)
+		if _, exists := pt.podEndpointTable[key]; exists {
+			continue
+		}
+		socket, err := pt.LocalPortOpener.OpenLocalPort(rule.NodePort, rule.Protocol)
+		if err != nil {
+			klog.Warningf("Failed to restore NodePortLocal rule of %s: port %d is not available: %v", key, rule.NodePort, err)
+			continue
+		}
+		data := &NodePortData{NodePort: rule.NodePort, PodPort: rule.PodPort, PodIP: rule.PodIP, Protocol: rule.Protocol, socket: socket}
+		pt.nodePortTable[rule.NodePort] = data
+		pt.podEndpointTable[key] = data
+		validRules = append(validRules, rule)
+		restored = append(restored, data)
+	}
+	if err := pt.PodPortRules.AddAllRules(validRules); err != nil {
+		for _, data := range restored {
+			if err := data.socket.Close(); err != nil {
+				klog.Warningf("Failed to close the socket of port %d/%s: %v", data.NodePort, data.Protocol, err)
+			}
+			delete(pt.nodePortTable, data.NodePort)
+			delete(pt.podEndpointTable, podEndpointKey(data.PodIP, data.PodPort, data.Protocol))
+		}
+		return err
+	}
+	return nil
+}
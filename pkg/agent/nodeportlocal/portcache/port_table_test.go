@@ -0,0 +1,164 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package portcache
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules"
+	rulestesting "github.com/vmware-tanzu/antrea/pkg/agent/nodeportlocal/rules/testing"
+)
+
+type fakeSocket struct {
+	opener *fakePortOpener
+	port   int
+}
+
+func (s *fakeSocket) Close() error {
+	delete(s.opener.openPorts, s.port)
+	return nil
+}
+
+// fakePortOpener fails to open the ports in usedPorts, which are used by other
+// processes, and the ports it has already opened.
+type fakePortOpener struct {
+	usedPorts map[int]bool
+	openPorts map[int]bool
+}
+
+func newFakePortOpener(usedPorts ...int) *fakePortOpener {
+	o := &fakePortOpener{usedPorts: map[int]bool{}, openPorts: map[int]bool{}}
+	for _, port := range usedPorts {
+		o.usedPorts[port] = true
+	}
+	return o
+}
+
+func (o *fakePortOpener) OpenLocalPort(port int, protocol string) (io.Closer, error) {
+	if o.usedPorts[port] || o.openPorts[port] {
+		return nil, fmt.Errorf("port %d is in use", port)
+	}
+	o.openPorts[port] = true
+	return &fakeSocket{opener: o, port: port}, nil
+}
+
+func TestAddDeleteRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	opener := newFakePortOpener(40000)
+	pt := NewPortTable(40000, 40002, mockRules, opener)
+
+	// Port 40000 is used by another process.
+	mockRules.EXPECT().AddRule(40001, "10.0.0.1", 80, "tcp").Return(nil)
+	nodePort, err := pt.AddRule("10.0.0.1", 80, "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, 40001, nodePort)
+
+	// The same port is returned for the same Pod endpoint.
+	nodePort, err = pt.AddRule("10.0.0.1", 80, "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, 40001, nodePort)
+
+	mockRules.EXPECT().AddRule(40002, "10.0.0.1", 53, "udp").Return(nil)
+	nodePort, err = pt.AddRule("10.0.0.1", 53, "udp")
+	require.NoError(t, err)
+	assert.Equal(t, 40002, nodePort)
+	assert.Len(t, pt.GetEntriesByPodIP("10.0.0.1"), 2)
+
+	// The range is exhausted.
+	_, err = pt.AddRule("10.0.0.2", 80, "tcp")
+	assert.Error(t, err)
+
+	// The port is released and reused after the rule is deleted.
+	mockRules.EXPECT().DeleteRule(40001, "10.0.0.1", 80, "tcp").Return(nil)
+	require.NoError(t, pt.DeleteRule("10.0.0.1", 80, "tcp"))
+	assert.Nil(t, pt.GetEntry("10.0.0.1", 80, "tcp"))
+	assert.False(t, opener.openPorts[40001])
+	mockRules.EXPECT().AddRule(40001, "10.0.0.2", 80, "tcp").Return(nil)
+	nodePort, err = pt.AddRule("10.0.0.2", 80, "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, 40001, nodePort)
+
+	mockRules.EXPECT().DeleteRule(40002, "10.0.0.1", 53, "udp").Return(nil)
+	require.NoError(t, pt.DeleteRulesForPod("10.0.0.1"))
+	assert.Empty(t, pt.GetEntriesByPodIP("10.0.0.1"))
+	assert.Len(t, pt.GetEntriesByPodIP("10.0.0.2"), 1)
+}
+
+func TestAddRuleError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	opener := newFakePortOpener()
+	pt := NewPortTable(40000, 40002, mockRules, opener)
+
+	// The port is released when the rule cannot be added.
+	mockRules.EXPECT().AddRule(40000, "10.0.0.1", 80, "tcp").Return(fmt.Errorf("iptables error"))
+	_, err := pt.AddRule("10.0.0.1", 80, "tcp")
+	assert.Error(t, err)
+	assert.Empty(t, opener.openPorts)
+	assert.Nil(t, pt.GetEntry("10.0.0.1", 80, "tcp"))
+}
+
+func TestRestoreRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	opener := newFakePortOpener(40002)
+	pt := NewPortTable(40000, 40010, mockRules, opener)
+
+	valid := rules.PodNodePort{NodePort: 40005, PodIP: "10.0.0.1", PodPort: 80, Protocol: "tcp"}
+	mockRules.EXPECT().AddAllRules([]rules.PodNodePort{valid}).Return(nil)
+	require.NoError(t, pt.RestoreRules([]rules.PodNodePort{
+		valid,
+		// Out of range.
+		{NodePort: 30000, PodIP: "10.0.0.2", PodPort: 80, Protocol: "tcp"},
+		// Used by another process.
+		{NodePort: 40002, PodIP: "10.0.0.3", PodPort: 80, Protocol: "tcp"},
+		// Used by another rule.
+		{NodePort: 40005, PodIP: "10.0.0.4", PodPort: 80, Protocol: "tcp"},
+	}))
+	assert.Equal(t, 40005, pt.GetEntry("10.0.0.1", 80, "tcp").NodePort)
+	assert.Nil(t, pt.GetEntry("10.0.0.2", 80, "tcp"))
+	assert.Nil(t, pt.GetEntry("10.0.0.3", 80, "tcp"))
+	assert.Nil(t, pt.GetEntry("10.0.0.4", 80, "tcp"))
+}
+
+func TestRestoreRulesError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRules := rulestesting.NewMockPodPortRules(ctrl)
+	opener := newFakePortOpener()
+	pt := NewPortTable(40000, 40010, mockRules, opener)
+
+	// The ports are released when the rules cannot be restored, so that
+	// restoring can be retried.
+	rule := rules.PodNodePort{NodePort: 40005, PodIP: "10.0.0.1", PodPort: 80, Protocol: "tcp"}
+	mockRules.EXPECT().AddAllRules([]rules.PodNodePort{rule}).Return(fmt.Errorf("iptables error"))
+	assert.Error(t, pt.RestoreRules([]rules.PodNodePort{rule}))
+	assert.Empty(t, opener.openPorts)
+	assert.Nil(t, pt.GetEntry("10.0.0.1", 80, "tcp"))
+
+	mockRules.EXPECT().AddAllRules([]rules.PodNodePort{rule}).Return(nil)
+	require.NoError(t, pt.RestoreRules([]rules.PodNodePort{rule}))
+	assert.Equal(t, 40005, pt.GetEntry("10.0.0.1", 80, "tcp").NodePort)
+}
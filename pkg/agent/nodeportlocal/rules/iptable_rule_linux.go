@@ -0,0 +1,110 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+)
+
+const (
+	// NodePortLocalChain is the iptables chain in the nat table which holds
+	// the DNAT rules from the Node ports to the Pods.
+	NodePortLocalChain = "ANTREA-NODE-PORT-LOCAL"
+)
+
+// iptablesRules implements PodPortRules with iptables DNAT rules.
+type iptablesRules struct {
+	table *iptables.Client
+}
+
+// InitRules returns the PodPortRules implementation of the platform.
+func InitRules() PodPortRules {
+	return &iptablesRules{}
+}
+
+// Init creates the NodePortLocal chain and the rules jumping to it from the
+// PREROUTING and OUTPUT chains, for the traffic destined to the local
+// addresses.
+func (r *iptablesRules) Init() error {
+	// IPv6 is not supported yet.
+	ipt, err := iptables.New(true, false)
+	if err != nil {
+		return fmt.Errorf("error when creating iptables client: %v", err)
+	}
+	r.table = ipt
+	if err := r.table.EnsureChain(iptables.NATTable, NodePortLocalChain); err != nil {
+		return err
+	}
+	ruleSpec := []string{
+		"-m", "addrtype", "--dst-type", "LOCAL",
+		"-m", "comment", "--comment", "Antrea: jump to Antrea NodePortLocal rules",
+		"-j", NodePortLocalChain,
+	}
+	for _, chain := range []string{iptables.PreRoutingChain, iptables.OutputChain} {
+		if err := r.table.EnsureRule(iptables.NATTable, chain, ruleSpec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildRuleSpec(nodePort int, podIP string, podPort int, protocol string) []string {
+	return []string{
+		"-p", protocol, "-m", protocol, "--dport", strconv.Itoa(nodePort),
+		"-j", "DNAT", "--to-destination", fmt.Sprintf("%s:%d", podIP, podPort),
+	}
+}
+
+func (r *iptablesRules) AddRule(nodePort int, podIP string, podPort int, protocol string) error {
+	ruleSpec := buildRuleSpec(nodePort, podIP, podPort, protocol)
+	if err := r.table.EnsureRule(iptables.NATTable, NodePortLocalChain, ruleSpec); err != nil {
+		return err
+	}
+	klog.V(2).Infof("Added NodePortLocal rule from Node port %d to %s:%d/%s", nodePort, podIP, podPort, protocol)
+	return nil
+}
+
+func (r *iptablesRules) DeleteRule(nodePort int, podIP string, podPort int, protocol string) error {
+	ruleSpec := buildRuleSpec(nodePort, podIP, podPort, protocol)
+	if err := r.table.DeleteRule(iptables.NATTable, NodePortLocalChain, ruleSpec); err != nil {
+		return err
+	}
+	klog.V(2).Infof("Deleted NodePortLocal rule from Node port %d to %s:%d/%s", nodePort, podIP, podPort, protocol)
+	return nil
+}
+
+// AddAllRules rebuilds the NodePortLocal chain with iptables-restore, which
+// removes the stale rules left by the previous run of the agent.
+func (r *iptablesRules) AddAllRules(rules []PodNodePort) error {
+	iptablesData := bytes.NewBuffer(nil)
+	iptablesData.WriteString("*nat\n")
+	iptablesData.WriteString(iptables.MakeChainLine(NodePortLocalChain) + "\n")
+	for _, rule := range rules {
+		iptablesData.WriteString("-A " + NodePortLocalChain)
+		for _, word := range buildRuleSpec(rule.NodePort, rule.PodIP, rule.PodPort, rule.Protocol) {
+			iptablesData.WriteString(" " + word)
+		}
+		iptablesData.WriteString("\n")
+	}
+	iptablesData.WriteString("COMMIT\n")
+	// Setting --noflush to only flush the NodePortLocal chain.
+	return r.table.Restore(iptablesData.Bytes(), false, false)
+}
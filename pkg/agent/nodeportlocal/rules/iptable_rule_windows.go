@@ -0,0 +1,46 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"errors"
+)
+
+// unsupportedRules is the PodPortRules implementation of the platforms which
+// don't support NodePortLocal.
+type unsupportedRules struct{}
+
+var errUnsupported = errors.New("NodePortLocal is not supported on Windows")
+
+// InitRules returns the PodPortRules implementation of the platform.
+func InitRules() PodPortRules {
+	return &unsupportedRules{}
+}
+
+func (r *unsupportedRules) Init() error {
+	return errUnsupported
+}
+
+func (r *unsupportedRules) AddRule(nodePort int, podIP string, podPort int, protocol string) error {
+	return errUnsupported
+}
+
+func (r *unsupportedRules) DeleteRule(nodePort int, podIP string, podPort int, protocol string) error {
+	return errUnsupported
+}
+
+func (r *unsupportedRules) AddAllRules(rules []PodNodePort) error {
+	return errUnsupported
+}
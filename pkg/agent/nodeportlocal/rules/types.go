@@ -0,0 +1,41 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+// PodNodePort is a rule forwarding the traffic destined to a port of the Node to
+// a port of a Pod.
+type PodNodePort struct {
+	NodePort int
+	PodPort  int
+	PodIP    string
+	Protocol string
+}
+
+// PodPortRules is the interface for the rules forwarding the traffic destined
+// to the ports of the Node to the Pods.
+type PodPortRules interface {
+	// Init should prepare the rules, it should be called before the other
+	// methods.
+	Init() error
+	// AddRule should add the rule forwarding the traffic destined to the
+	// Node port to the Pod IP and port.
+	AddRule(nodePort int, podIP string, podPort int, protocol string) error
+	// DeleteRule should delete the rule added by AddRule. It should do
+	// nothing if the rule doesn't exist, without error.
+	DeleteRule(nodePort int, podIP string, podPort int, protocol string) error
+	// AddAllRules should replace all the existing rules with the provided
+	// rules.
+	AddAllRules(rules []PodNodePort) error
+}
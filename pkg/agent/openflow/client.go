@@ -21,11 +21,13 @@ import (
 
 	"github.com/contiv/ofnet/ofctrl"
 	"k8s.io/klog"
+	utilnet "k8s.io/utils/net"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	"github.com/vmware-tanzu/antrea/third_party/proxy"
 )
@@ -39,7 +41,7 @@ type Client interface {
 	// be called to ensure that the set of OVS flows is correct. All flows programmed in the
 	// switch which match the current round number will be deleted before any new flow is
 	// installed.
-	Initialize(roundInfo types.RoundInfo, config *config.NodeConfig, encapMode config.TrafficEncapModeType) (<-chan struct{}, error)
+	Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeConfig, networkConfig *config.NetworkConfig) (<-chan struct{}, error)
 
 	// InstallGatewayFlows sets up flows related to an OVS gateway port, the gateway must exist.
 	InstallGatewayFlows() error
@@ -51,6 +53,8 @@ type Client interface {
 
 	// InstallClusterServiceFlows sets up the appropriate flows so that traffic can reach
 	// the different Services running in the Cluster. This method needs to be invoked once.
+	// If proxyAll is enabled, it also sets up the flows for the Service traffic from the host
+	// network.
 	InstallClusterServiceFlows() error
 
 	// InstallDefaultTunnelFlows sets up the classification flow for the default (flow based) tunnel.
@@ -87,7 +91,7 @@ type Client interface {
 	UninstallPodFlows(interfaceName string) error
 
 	// InstallServiceGroup installs a group for Service LB. Each endpoint
-	// is a bucket of the group. For now, each bucket has the same weight.
+	// is a bucket of the group, weighted with the weight of the endpoint.
 	InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error
 	// UninstallServiceGroup removes the group and its buckets that are
 	// installed by InstallServiceGroup.
@@ -100,6 +104,11 @@ type Client interface {
 	// UninstallEndpointFlows removes flows of the Endpoint installed by
 	// InstallEndpointFlows.
 	UninstallEndpointFlows(protocol binding.Protocol, endpoint proxy.Endpoint) error
+	// DrainEndpointFlows removes the flow which performs DNAT of the new
+	// connections to the Endpoint, and keeps the other flows installed by
+	// InstallEndpointFlows, which are required by its established connections,
+	// until UninstallEndpointFlows is called.
+	DrainEndpointFlows(protocol binding.Protocol, endpoint proxy.Endpoint) error
 
 	// InstallServiceFlows installs flows for accessing Service with clusterIP.
 	// It installs the flow that uses the group/bucket to do service LB. If the
@@ -110,6 +119,28 @@ type Client interface {
 	InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) error
 	// UninstallServiceFlows removes flows installed by InstallServiceFlows.
 	UninstallServiceFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// InstallServiceMetricFlows installs the flows which count the packets of
+	// the connections to the Service with clusterIP, after they are
+	// load-balanced by the flows installed by InstallServiceFlows, and the
+	// packets sent to each of the Endpoints. It updates the flows of the
+	// Endpoints if they are already installed.
+	InstallServiceMetricFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, endpoints []proxy.Endpoint) error
+	// UninstallServiceMetricFlows removes flows installed by
+	// InstallServiceMetricFlows.
+	UninstallServiceMetricFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// InstallServiceNoEndpointFlows installs the flow which drops and counts the
+	// new connections to the Service with clusterIP while it has no Endpoint. The
+	// flows installed by InstallServiceFlows for the Service must be
+	// uninstalled before.
+	InstallServiceNoEndpointFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// UninstallServiceNoEndpointFlows removes flows installed by
+	// InstallServiceNoEndpointFlows.
+	UninstallServiceNoEndpointFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// ServiceMetrics returns the metrics of the Services of the IP family,
+	// keyed by the IDs of their groups, collected from the statistics of the
+	// groups and of the flows installed by InstallServiceMetricFlows and
+	// InstallServiceNoEndpointFlows.
+	ServiceMetrics(isIPv6 bool) map[binding.GroupIDType]*types.ServiceMetric
 	// InstallLoadBalancerServiceFromOutsideFlows installs flows for LoadBalancer Service traffic from outside node.
 	// The traffic is received from uplink port and will be forwarded to gateway by the installed flows. And then
 	// kube-proxy will handle the traffic.
@@ -223,10 +254,25 @@ type Client interface {
 	// Find Network Policy reference and OFpriority by conjunction ID.
 	GetPolicyInfoFromConjunction(ruleID uint32) (string, string)
 
+	// GetL7ProtocolsFromConjunction returns the application layer protocols of the policy rule with the provided ID.
+	GetL7ProtocolsFromConjunction(ruleID uint32) []v1beta2.L7Protocol
+
+	// DenyL7Connection installs the flow denying the TCP connection with the provided original direction tuple after
+	// one of its application layer requests was denied.
+	DenyL7Connection(srcIP, dstIP net.IP, srcPort, dstPort uint16) error
+
+	// ResumeL7Packet resumes the processing of the packet of the packetIn message after its application layer payload
+	// was allowed.
+	ResumeL7Packet(pktIn *ofctrl.PacketIn) error
+
 	// RegisterPacketInHandler uses SubscribePacketIn to get PacketIn message and process received
 	// packets through registered handlers.
 	RegisterPacketInHandler(packetHandlerReason uint8, packetHandlerName string, packetInHandler interface{})
 
+	// RegisterL7PacketInHandler registers the handler of the packetIn messages sent for L7 inspection, which are
+	// processed with their own queue once StartPacketInHandler is called.
+	RegisterL7PacketInHandler(packetInHandler interface{})
+
 	StartPacketInHandler(packetInStartedReason []uint8, stopCh <-chan struct{})
 	// Get traffic metrics of each NetworkPolicy rule.
 	NetworkPolicyMetrics() map[uint32]*types.RuleMetric
@@ -306,7 +352,7 @@ func (c *client) InstallNodeFlows(hostname string,
 			// only work for IPv4 addresses.
 			flows = append(flows, c.arpResponderFlow(peerGatewayIP, cookie.Node))
 		}
-		if c.encapMode.NeedsEncapToPeer(tunnelPeerIP, c.nodeConfig.NodeIPAddr) {
+		if c.networkConfig.NeedsTunnelToPeer(tunnelPeerIP, c.nodeConfig.NodeIPAddr) {
 			// tunnelPeerIP is the Node Internal Address. In a dual-stack setup, whether this address is an IPv4 address or an
 			// IPv6 one is decided by the address family of Node Internal Address.
 			flows = append(flows, c.l3FwdFlowToRemote(localGatewayMAC, *peerPodCIDR, tunnelPeerIP, cookie.Node))
@@ -393,6 +439,12 @@ func (c *client) InstallServiceGroup(groupID binding.GroupIDType, withSessionAff
 		return fmt.Errorf("error when installing Service Endpoints Group: %w", err)
 	}
 	c.groupCache.Store(groupID, group)
+	buckets := &serviceGroupBuckets{endpoints: make([]string, 0, len(endpoints))}
+	for _, endpoint := range endpoints {
+		buckets.isIPv6 = utilnet.IsIPv6String(endpoint.IP())
+		buckets.endpoints = append(buckets.endpoints, endpoint.String())
+	}
+	c.serviceGroupEndpoints.Store(groupID, buckets)
 	return nil
 }
 
@@ -403,6 +455,7 @@ func (c *client) UninstallServiceGroup(groupID binding.GroupIDType) error {
 		return fmt.Errorf("group %d delete failed", groupID)
 	}
 	c.groupCache.Delete(groupID)
+	c.serviceGroupEndpoints.Delete(groupID)
 	return nil
 }
 
@@ -443,6 +496,46 @@ func (c *client) UninstallEndpointFlows(protocol binding.Protocol, endpoint prox
 	return c.deleteFlows(c.serviceFlowCache, cacheKey)
 }
 
+func (c *client) DrainEndpointFlows(protocol binding.Protocol, endpoint proxy.Endpoint) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	port, err := endpoint.Port()
+	if err != nil {
+		return fmt.Errorf("error when getting port: %w", err)
+	}
+	cacheKey := fmt.Sprintf("Endpoints_%s_%d_%s", endpoint.IP(), port, protocol)
+	fCacheI, ok := c.serviceFlowCache.Load(cacheKey)
+	if !ok {
+		return nil
+	}
+	endpointIP := net.ParseIP(endpoint.IP())
+	if endpointIP.To4() != nil {
+		endpointIP = endpointIP.To4()
+	}
+	dnatFlow := c.endpointDNATFlow(endpointIP, uint16(port), protocol)
+	fCache := fCacheI.(flowCache)
+	if _, ok := fCache[dnatFlow.MatchString()]; !ok {
+		return nil
+	}
+	if err := c.ofEntryOperations.Delete(dnatFlow); err != nil {
+		return err
+	}
+	// The cache is shared with the replay of the flows, update a copy of it.
+	newFCache := flowCache{}
+	for match, flow := range fCache {
+		if match != dnatFlow.MatchString() {
+			newFCache[match] = flow
+		}
+	}
+	if len(newFCache) == 0 {
+		c.serviceFlowCache.Delete(cacheKey)
+	} else {
+		c.serviceFlowCache.Store(cacheKey, newFCache)
+	}
+	return nil
+}
+
 func (c *client) InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -462,6 +555,78 @@ func (c *client) UninstallServiceFlows(svcIP net.IP, svcPort uint16, protocol bi
 	return c.deleteFlows(c.serviceFlowCache, cacheKey)
 }
 
+func (c *client) InstallServiceMetricFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, endpoints []proxy.Endpoint) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	flows := []binding.Flow{c.serviceMetricFlow(groupID, svcIP, svcPort, protocol)}
+	for _, endpoint := range endpoints {
+		endpointPort, _ := endpoint.Port()
+		endpointIP := net.ParseIP(endpoint.IP())
+		if endpointIP.To4() != nil {
+			endpointIP = endpointIP.To4()
+		}
+		flows = append(flows, c.endpointMetricFlow(groupID, svcIP, svcPort, protocol, endpointIP, uint16(endpointPort)))
+	}
+	cacheKey := fmt.Sprintf("ServiceMetric_%s_%d_%s", svcIP, svcPort, protocol)
+	fCacheI, ok := c.serviceFlowCache.Load(cacheKey)
+	if !ok {
+		return c.addFlows(c.serviceFlowCache, cacheKey, flows)
+	}
+	// The flows of the Endpoints are updated when the Endpoints of the Service change, the flows which are still
+	// required are kept so that their counters are not reset.
+	fCache := fCacheI.(flowCache)
+	newFCache := flowCache{}
+	var addFlows, delFlows []binding.Flow
+	for _, flow := range flows {
+		match := flow.MatchString()
+		if installedFlow, ok := fCache[match]; ok {
+			newFCache[match] = installedFlow
+			continue
+		}
+		newFCache[match] = flow
+		addFlows = append(addFlows, flow)
+	}
+	for match, flow := range fCache {
+		if _, ok := newFCache[match]; !ok {
+			delFlows = append(delFlows, flow)
+		}
+	}
+	if len(addFlows) > 0 {
+		if err := c.ofEntryOperations.AddAll(addFlows); err != nil {
+			return err
+		}
+	}
+	if len(delFlows) > 0 {
+		if err := c.ofEntryOperations.DeleteAll(delFlows); err != nil {
+			return err
+		}
+	}
+	// The cache is shared with the replay of the flows, store a new one.
+	c.serviceFlowCache.Store(cacheKey, newFCache)
+	return nil
+}
+
+func (c *client) UninstallServiceMetricFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := fmt.Sprintf("ServiceMetric_%s_%d_%s", svcIP, svcPort, protocol)
+	return c.deleteFlows(c.serviceFlowCache, cacheKey)
+}
+
+func (c *client) InstallServiceNoEndpointFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := fmt.Sprintf("ServiceNoEndpoint_%s_%d_%s", svcIP, svcPort, protocol)
+	return c.addFlows(c.serviceFlowCache, cacheKey, []binding.Flow{c.serviceNoEndpointFlow(groupID, svcIP, svcPort, protocol)})
+}
+
+func (c *client) UninstallServiceNoEndpointFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := fmt.Sprintf("ServiceNoEndpoint_%s_%d_%s", svcIP, svcPort, protocol)
+	return c.deleteFlows(c.serviceFlowCache, cacheKey)
+}
+
 func (c *client) InstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -488,11 +653,17 @@ func (c *client) InstallClusterServiceFlows() error {
 		flows = append(flows,
 			c.serviceHairpinResponseDNATFlow(binding.ProtocolIP),
 			c.serviceLBBypassFlow(binding.ProtocolIP))
+		if c.proxyAll {
+			flows = append(flows, c.serviceHostFlows(binding.ProtocolIP)...)
+		}
 	}
 	if c.IsIPv6Enabled() {
 		flows = append(flows,
 			c.serviceHairpinResponseDNATFlow(binding.ProtocolIPv6),
 			c.serviceLBBypassFlow(binding.ProtocolIPv6))
+		if c.proxyAll {
+			flows = append(flows, c.serviceHostFlows(binding.ProtocolIPv6)...)
+		}
 	}
 	if err := c.ofEntryOperations.AddAll(flows); err != nil {
 		return err
@@ -588,6 +759,14 @@ func (c *client) initialize() error {
 	if err := c.ofEntryOperations.AddAll(c.establishedConnectionFlows(cookie.Default)); err != nil {
 		return fmt.Errorf("failed to install flows to skip established connections: %v", err)
 	}
+	if c.enableAntreaPolicy {
+		if err := c.ofEntryOperations.AddAll(c.l7InspectionFlows(cookie.L7)); err != nil {
+			return fmt.Errorf("failed to install L7 inspection flows: %v", err)
+		}
+		if err := c.installL7PacketInMeter(cookie.L7); err != nil {
+			return fmt.Errorf("failed to install L7 packetin meter: %v", err)
+		}
+	}
 	if c.encapMode.IsNetworkPolicyOnly() {
 		if err := c.setupPolicyOnlyFlows(); err != nil {
 			return fmt.Errorf("failed to setup policy only flows: %w", err)
@@ -596,14 +775,15 @@ func (c *client) initialize() error {
 	return nil
 }
 
-func (c *client) Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeConfig, encapMode config.TrafficEncapModeType) (<-chan struct{}, error) {
+func (c *client) Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeConfig, networkConfig *config.NetworkConfig) (<-chan struct{}, error) {
 	c.nodeConfig = nodeConfig
-	c.encapMode = encapMode
+	c.networkConfig = networkConfig
+	c.encapMode = networkConfig.TrafficEncapMode
 
-	if config.IsIPv4Enabled(nodeConfig, encapMode) {
+	if config.IsIPv4Enabled(nodeConfig, c.encapMode) {
 		c.ipProtocols = append(c.ipProtocols, binding.ProtocolIP)
 	}
-	if config.IsIPv6Enabled(nodeConfig, encapMode) {
+	if config.IsIPv6Enabled(nodeConfig, c.encapMode) {
 		c.ipProtocols = append(c.ipProtocols, binding.ProtocolIPv6)
 	}
 
@@ -626,6 +806,11 @@ func (c *client) Initialize(roundInfo types.RoundInfo, nodeConfig *config.NodeCo
 	if err := c.deleteFlowsByRoundNum(roundInfo.RoundNum); err != nil {
 		return nil, fmt.Errorf("error when deleting exiting flows for current round number: %v", err)
 	}
+	if c.enableAntreaPolicy {
+		if err := c.deleteAllMeters(); err != nil {
+			return nil, fmt.Errorf("error when deleting existing meters: %v", err)
+		}
+	}
 
 	return connCh, c.initialize()
 }
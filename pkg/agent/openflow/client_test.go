@@ -34,6 +34,7 @@ import (
 	ofconfig "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	ovsoftest "github.com/vmware-tanzu/antrea/pkg/ovs/openflow/testing"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 )
 
 const bridgeName = "dummy-br"
@@ -91,7 +92,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -99,6 +100,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			gwMAC, _ := net.ParseMAC("AA:BB:CC:DD:EE:EE")
 			gatewayConfig := &config.GatewayConfig{MAC: gwMAC}
 			client.nodeConfig = &config.NodeConfig{GatewayConfig: gatewayConfig}
+			client.networkConfig = &config.NetworkConfig{}
 
 			m.EXPECT().AddAll(gomock.Any()).Return(nil).Times(1)
 			// Installing the flows should succeed, and all the flows should be added into the cache.
@@ -122,7 +124,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -130,6 +132,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			gwMAC, _ := net.ParseMAC("AA:BB:CC:DD:EE:EE")
 			gatewayConfig := &config.GatewayConfig{MAC: gwMAC}
 			client.nodeConfig = &config.NodeConfig{GatewayConfig: gatewayConfig}
+			client.networkConfig = &config.NetworkConfig{}
 
 			errorCall := m.EXPECT().AddAll(gomock.Any()).Return(errors.New("Bundle error")).Times(1)
 			m.EXPECT().AddAll(gomock.Any()).Return(nil).After(errorCall)
@@ -166,7 +169,7 @@ func TestFlowInstallationFailed(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -174,6 +177,7 @@ func TestFlowInstallationFailed(t *testing.T) {
 			gwMAC, _ := net.ParseMAC("AA:BB:CC:DD:EE:EE")
 			gatewayConfig := &config.GatewayConfig{MAC: gwMAC}
 			client.nodeConfig = &config.NodeConfig{GatewayConfig: gatewayConfig}
+			client.networkConfig = &config.NetworkConfig{}
 
 			// We generate an error for AddAll call.
 			m.EXPECT().AddAll(gomock.Any()).Return(errors.New("Bundle error"))
@@ -203,7 +207,7 @@ func TestConcurrentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -211,6 +215,7 @@ func TestConcurrentFlowInstallation(t *testing.T) {
 			gwMAC, _ := net.ParseMAC("AA:BB:CC:DD:EE:EE")
 			gatewayConfig := &config.GatewayConfig{MAC: gwMAC}
 			client.nodeConfig = &config.NodeConfig{GatewayConfig: gatewayConfig}
+			client.networkConfig = &config.NetworkConfig{}
 
 			var concurrentCalls atomic.Value // set to true if we observe concurrent calls
 			timeoutCh := make(chan struct{})
@@ -253,6 +258,102 @@ func TestConcurrentFlowInstallation(t *testing.T) {
 
 }
 
+func TestInstallClusterServiceFlows(t *testing.T) {
+	_, podIPv4CIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	_, podIPv6CIDR, _ := net.ParseCIDR("fd74:ca9b:172:18::/64")
+	testCases := []struct {
+		name      string
+		proxyAll  bool
+		nodeCfg   *config.NodeConfig
+		wantFlows int
+	}{
+		{"IPv4", false, &config.NodeConfig{PodIPv4CIDR: podIPv4CIDR}, 5},
+		{"IPv4 proxyAll", true, &config.NodeConfig{PodIPv4CIDR: podIPv4CIDR}, 10},
+		{"dual-stack proxyAll", true, &config.NodeConfig{PodIPv4CIDR: podIPv4CIDR, PodIPv6CIDR: podIPv6CIDR}, 17},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			m := oftest.NewMockOFEntryOperations(ctrl)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, tc.proxyAll)
+			client := ofClient.(*client)
+			client.cookieAllocator = cookie.NewAllocator(0)
+			client.ofEntryOperations = m
+			client.nodeConfig = tc.nodeCfg
+
+			m.EXPECT().AddAll(gomock.Any()).Return(nil).Times(1)
+			require.NoError(t, ofClient.InstallClusterServiceFlows())
+			assert.Len(t, client.defaultServiceFlows, tc.wantFlows)
+		})
+	}
+}
+
+func TestDrainEndpointFlows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
+	client := ofClient.(*client)
+	client.cookieAllocator = cookie.NewAllocator(0)
+	client.ofEntryOperations = m
+
+	endpoint := &k8sproxy.BaseEndpointInfo{Endpoint: "10.10.0.10:80", IsLocal: true}
+	cacheKey := "Endpoints_10.10.0.10_80_tcp"
+	m.EXPECT().AddAll(gomock.Any()).Return(nil).Times(1)
+	require.NoError(t, ofClient.InstallEndpointFlows(ofconfig.ProtocolTCP, []k8sproxy.Endpoint{endpoint}, false))
+	fCache, ok := client.serviceFlowCache.Load(cacheKey)
+	require.True(t, ok)
+	assert.Len(t, fCache, 2)
+
+	// Only the DNAT flow is removed, the hairpin flow is kept.
+	m.EXPECT().Delete(gomock.Any()).Return(nil).Times(1)
+	require.NoError(t, ofClient.DrainEndpointFlows(ofconfig.ProtocolTCP, endpoint))
+	fCache, ok = client.serviceFlowCache.Load(cacheKey)
+	require.True(t, ok)
+	assert.Len(t, fCache, 1)
+	require.NoError(t, ofClient.DrainEndpointFlows(ofconfig.ProtocolTCP, endpoint))
+
+	m.EXPECT().DeleteAll(gomock.Len(1)).Return(nil).Times(1)
+	require.NoError(t, ofClient.UninstallEndpointFlows(ofconfig.ProtocolTCP, endpoint))
+	_, ok = client.serviceFlowCache.Load(cacheKey)
+	assert.False(t, ok)
+}
+
+func TestInstallServiceMetricFlows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := oftest.NewMockOFEntryOperations(ctrl)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
+	client := ofClient.(*client)
+	client.cookieAllocator = cookie.NewAllocator(0)
+	client.ofEntryOperations = m
+
+	svcIP := net.ParseIP("10.96.0.10")
+	endpoint1 := &k8sproxy.BaseEndpointInfo{Endpoint: "10.10.0.10:80"}
+	endpoint2 := &k8sproxy.BaseEndpointInfo{Endpoint: "10.10.0.11:80"}
+	cacheKey := "ServiceMetric_10.96.0.10_80_tcp"
+	m.EXPECT().AddAll(gomock.Len(2)).Return(nil).Times(1)
+	require.NoError(t, ofClient.InstallServiceMetricFlows(1, svcIP, 80, ofconfig.ProtocolTCP, []k8sproxy.Endpoint{endpoint1}))
+	fCache, ok := client.serviceFlowCache.Load(cacheKey)
+	require.True(t, ok)
+	assert.Len(t, fCache, 2)
+
+	// Only the flows of the changed Endpoints are added and deleted.
+	m.EXPECT().AddAll(gomock.Len(1)).Return(nil).Times(1)
+	m.EXPECT().DeleteAll(gomock.Len(1)).Return(nil).Times(1)
+	require.NoError(t, ofClient.InstallServiceMetricFlows(1, svcIP, 80, ofconfig.ProtocolTCP, []k8sproxy.Endpoint{endpoint2}))
+	fCache, ok = client.serviceFlowCache.Load(cacheKey)
+	require.True(t, ok)
+	assert.Len(t, fCache, 2)
+	require.NoError(t, ofClient.InstallServiceMetricFlows(1, svcIP, 80, ofconfig.ProtocolTCP, []k8sproxy.Endpoint{endpoint2}))
+
+	m.EXPECT().DeleteAll(gomock.Len(2)).Return(nil).Times(1)
+	require.NoError(t, ofClient.UninstallServiceMetricFlows(svcIP, 80, ofconfig.ProtocolTCP))
+	_, ok = client.serviceFlowCache.Load(cacheKey)
+	assert.False(t, ok)
+}
+
 func Test_client_InstallTraceflowFlows(t *testing.T) {
 	type ofSwitch struct {
 		ofctrl.OFSwitch
@@ -290,7 +391,7 @@ func Test_client_InstallTraceflowFlows(t *testing.T) {
 }
 
 func prepareTraceflowFlow(ctrl *gomock.Controller) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, true, false)
 	c := ofClient.(*client)
 	c.cookieAllocator = cookie.NewAllocator(0)
 	c.nodeConfig = &config.NodeConfig{}
@@ -37,6 +37,7 @@ const (
 	Service
 	Policy
 	SNAT
+	L7
 )
 
 func (c Category) String() string {
@@ -55,6 +56,8 @@ func (c Category) String() string {
 		return "Policy"
 	case SNAT:
 		return "SNAT"
+	case L7:
+		return "L7"
 	default:
 		return "Invalid"
 	}
@@ -81,6 +84,12 @@ func CookieMaskForRound(round uint64) (uint64, uint64) {
 	return round << (64 - BitwidthRound), RoundMask
 }
 
+// CookieMaskForCategory returns a cookie and mask value that can be used to select all flows belonging to the
+// provided category.
+func CookieMaskForCategory(cat Category) (uint64, uint64) {
+	return uint64(cat) << BitwidthReserved, CategoryMask
+}
+
 // Raw returns the unit64 type value of the ID.
 func (i ID) Raw() uint64 {
 	return uint64(i)
@@ -96,6 +105,11 @@ func (i ID) Category() Category {
 	return Category((i.Raw() & CategoryMask) >> BitwidthReserved)
 }
 
+// ObjectID returns the object ID of the ID.
+func (i ID) ObjectID() uint32 {
+	return uint32(i.Raw())
+}
+
 // String returns the string representation of the ID.
 func (i ID) String() string {
 	return fmt.Sprintf("<round:%d,category:%s>", i.Round(), i.Category().String())
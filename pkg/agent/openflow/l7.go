@@ -0,0 +1,194 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/contiv/ofnet/ofctrl"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+// The connections allowed by an ingress Antrea-native policy rule with application layer protocols are committed with
+// the L7 state "inspect" in the ct_label, and their packets are sent to the IngressL7Table after the metric flows:
+// - the reply packets are forwarded;
+// - the other packets, i.e. all the packets sent by the client including the SYN, go to the IngressL7InspectTable
+//   through the L7 packetin meter, and are sent to the agent, which learns the initial sequence number of the
+//   connection from the SYN and evaluates every request of the connection. The packets allowed by the agent are sent
+//   back to OVS and resumed from the table following the IngressL7Table, while the agent installs a flow committing
+//   the connection with the L7 state "denied" as soon as one of its requests is denied;
+// - the packets of the denied connections are dropped.
+// The meter caps the rate of the packetIn messages sent for L7 inspection, which would otherwise delay the packetIn
+// messages of the other features on the OpenFlow connection. The packets exceeding the rate are dropped and
+// retransmitted by the clients. The packetIn messages are sent with PacketInReasonNP, as OpenFlow has no reason for
+// them, and the agent tells them apart by the L7 cookie category of the flows sending them.
+
+const (
+	// l7StateInspect means that the application layer requests of the connection must be inspected.
+	l7StateInspect = 0b01
+	// l7StateDenied means that the connection is denied after one of its application layer requests was inspected.
+	l7StateDenied = 0b11
+
+	// l7DenyFlowIdleTimeout is the idle timeout of the deny flow of a connection. The flow is only hit by the packets
+	// sent before the connection is committed with the denied state, so it's removed shortly after.
+	l7DenyFlowIdleTimeout = 10
+
+	priorityL7Deny    = priorityLow
+	priorityL7Inspect = priorityLow - 10
+	priorityL7Drop    = priorityLow - 20
+
+	// l7PacketInMeterID is the ID of the meter capping the rate of the packetIn messages sent for L7 inspection. It's
+	// out of the range of the IDs allocated to the rate limits of the rules.
+	l7PacketInMeterID = maxMeterID + 1
+	// l7PacketInRate is the maximum number of packets per second sent to the agent for L7 inspection.
+	l7PacketInRate = 5000
+)
+
+var (
+	// l7StateLabelRange takes 64..65 range of ct_label to store the L7 state of the connections allowed by the rules
+	// with application layer protocols.
+	l7StateLabelRange = binding.Range{64, 65}
+)
+
+// l7InspectionFlows generates the flows of the IngressL7Table which enforce the L7 state of the connections, and the
+// flows of the IngressL7InspectTable sending the packets to the agent. The flows applying the L7 packetin meter are
+// generated by l7PacketInMeterFlows. The category must be cookie.L7 for the packetIn messages to reach the L7 handler.
+func (c *client) l7InspectionFlows(category cookie.Category) []binding.Flow {
+	l7Table := c.pipeline[IngressL7Table]
+	inspectTable := c.pipeline[IngressL7InspectTable]
+	var flows []binding.Flow
+	for _, ipProto := range c.ipProtocols {
+		tcpProto := binding.ProtocolTCP
+		if ipProto == binding.ProtocolIPv6 {
+			tcpProto = binding.ProtocolTCPv6
+		}
+		flows = append(flows,
+			// Drop the packets of the denied connections.
+			l7Table.BuildFlow(priorityHigh).MatchProtocol(ipProto).
+				MatchCTLabelRange(l7StateDenied, 0, l7StateLabelRange).
+				Action().Drop().
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done(),
+			// Allow the reply packets of the connections being inspected.
+			l7Table.BuildFlow(priorityNormal).MatchProtocol(tcpProto).
+				MatchCTStateRpl(true).MatchCTLabelRange(l7StateInspect, 0, l7StateLabelRange).
+				Action().GotoTable(l7Table.GetNext()).
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done(),
+			// Send the other packets of the connections being inspected to the agent, with the ID of the ingress rule
+			// which allowed the connection. The agent resumes the packets it allows. The packets reach the flow
+			// through the L7 packetin meter.
+			inspectTable.BuildFlow(priorityNormal).MatchProtocol(tcpProto).
+				Action().MoveRange(binding.NxmFieldCtLabel, IngressReg.nxm(), metricIngressRuleIDRange, binding.Range{0, 31}).
+				Action().SendToController(uint8(PacketInReasonNP)).
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done(),
+			// Drop the non-TCP packets of the connections being inspected, as the application layer protocols are all
+			// over TCP.
+			l7Table.BuildFlow(priorityL7Drop).MatchProtocol(ipProto).
+				MatchCTLabelRange(l7StateInspect, 0, l7StateLabelRange).
+				Action().Drop().
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done(),
+		)
+	}
+	return flows
+}
+
+// l7PacketInMeter returns the meter capping the rate of the packetIn messages sent for L7 inspection.
+func (c *client) l7PacketInMeter() binding.Meter {
+	return c.bridge.CreateMeter(l7PacketInMeterID, binding.MeterPktps|binding.MeterStats).
+		MeterBand().MeterType(binding.MeterBandDrop).Rate(l7PacketInRate).Done()
+}
+
+// l7PacketInMeterFlows generates the flows of the IngressL7Table applying the L7 packetin meter to the packets sent
+// by the clients of the connections being inspected, before they go to the IngressL7InspectTable.
+func (c *client) l7PacketInMeterFlows(category cookie.Category) []binding.Flow {
+	l7Table := c.pipeline[IngressL7Table]
+	var flows []binding.Flow
+	for _, ipProto := range c.ipProtocols {
+		tcpProto := binding.ProtocolTCP
+		if ipProto == binding.ProtocolIPv6 {
+			tcpProto = binding.ProtocolTCPv6
+		}
+		flows = append(flows, l7Table.BuildFlow(priorityL7Inspect).MatchProtocol(tcpProto).
+			MatchCTLabelRange(l7StateInspect, 0, l7StateLabelRange).
+			Action().Meter(l7PacketInMeterID).
+			Action().GotoTable(IngressL7InspectTable).
+			Cookie(c.cookieAllocator.Request(category).Raw()).
+			Done())
+	}
+	return flows
+}
+
+// installL7PacketInMeter installs the L7 packetin meter and the flows applying it. They are sent to OVS directly, as
+// OVS doesn't support meters in bundles. They are installed again when the flows are replayed, as OVS removes the
+// meters when it restarts.
+func (c *client) installL7PacketInMeter(category cookie.Category) error {
+	meter := c.l7PacketInMeter()
+	if err := meter.Add(); err != nil {
+		return fmt.Errorf("error when installing meter %s: %v", meter.KeyString(), err)
+	}
+	for _, flow := range c.l7PacketInMeterFlows(category) {
+		if err := flow.Add(); err != nil {
+			return fmt.Errorf("error when installing L7 packetin meter flow %s: %v", flow.MatchString(), err)
+		}
+	}
+	return nil
+}
+
+// l7DenyFlow generates the flow committing the TCP connection with the provided original direction tuple with the
+// L7 state "denied". The packets hitting the flow are dropped.
+func (c *client) l7DenyFlow(srcIP, dstIP net.IP, srcPort, dstPort uint16) binding.Flow {
+	l7Table := c.pipeline[IngressL7Table]
+	tcpProto, ctZone := binding.ProtocolTCP, CtZone
+	if srcIP.To4() == nil {
+		tcpProto, ctZone = binding.ProtocolTCPv6, CtZoneV6
+	}
+	return l7Table.BuildFlow(priorityL7Deny).MatchProtocol(tcpProto).
+		MatchSrcIP(srcIP).MatchDstIP(dstIP).
+		MatchSrcPort(srcPort, nil).MatchDstPort(dstPort, nil).
+		MatchCTLabelRange(l7StateInspect, 0, l7StateLabelRange).
+		SetIdleTimeout(l7DenyFlowIdleTimeout).
+		Action().CT(true, binding.LastTableID, ctZone).LoadToLabelRange(l7StateDenied, &l7StateLabelRange).CTDone().
+		Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+		Done()
+}
+
+// DenyL7Connection installs the flow denying the TCP connection with the provided original direction tuple. The flow
+// is not replayed, as it expires after the connection is committed with the denied state.
+func (c *client) DenyL7Connection(srcIP, dstIP net.IP, srcPort, dstPort uint16) error {
+	return c.ofEntryOperations.Add(c.l7DenyFlow(srcIP, dstIP, srcPort, dstPort))
+}
+
+// ResumeL7Packet sends the packet of the packetIn message allowed by the agent back to OVS, where it's processed from
+// the table following the IngressL7Table. It's not sent through conntrack again, as the following tables only commit
+// new connections.
+func (c *client) ResumeL7Packet(pktIn *ofctrl.PacketIn) error {
+	return c.bridge.ResumePacketIn(pktIn, c.pipeline[IngressL7Table].GetNext())
+}
+
+// GetL7ProtocolsFromConjunction returns the application layer protocols of the policy rule with the provided ID.
+func (c *client) GetL7ProtocolsFromConjunction(ruleID uint32) []v1beta2.L7Protocol {
+	conj := c.getPolicyRuleConjunction(ruleID)
+	if conj == nil {
+		return nil
+	}
+	return conj.l7Protocols
+}
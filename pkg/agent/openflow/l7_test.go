@@ -0,0 +1,123 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	mocks "github.com/vmware-tanzu/antrea/pkg/ovs/openflow/testing"
+)
+
+func newL7TestClient() *client {
+	bridge := binding.NewOFBridge("br-int", "")
+	c := &client{
+		pipeline: map[binding.TableIDType]binding.Table{
+			IngressMetricTable:    bridge.CreateTable(IngressMetricTable, conntrackCommitTable, binding.TableMissActionNext),
+			IngressRateLimitTable: bridge.CreateTable(IngressRateLimitTable, IngressL7Table, binding.TableMissActionNext),
+			IngressL7Table:        bridge.CreateTable(IngressL7Table, conntrackCommitTable, binding.TableMissActionNext),
+			IngressL7InspectTable: bridge.CreateTable(IngressL7InspectTable, conntrackCommitTable, binding.TableMissActionDrop),
+		},
+		ipProtocols: []binding.Protocol{binding.ProtocolIP, binding.ProtocolIPv6},
+	}
+	c.cookieAllocator = cookie.NewAllocator(0)
+	return c
+}
+
+func TestL7InspectionFlows(t *testing.T) {
+	c := newL7TestClient()
+	var matches []string
+	for _, flow := range c.l7InspectionFlows(cookie.L7) {
+		matches = append(matches, flow.MatchString())
+	}
+	assert.Equal(t, []string{
+		"table=103,ip,ct_label[64..65]=0x30",
+		"table=103,tcp,ct_label[64..65]=0x10,ct_state=+rpl",
+		"table=104,tcp",
+		"table=103,ip,ct_label[64..65]=0x10",
+		"table=103,ipv6,ct_label[64..65]=0x30",
+		"table=103,tcpv6,ct_label[64..65]=0x10,ct_state=+rpl",
+		"table=104,tcpv6",
+		"table=103,ipv6,ct_label[64..65]=0x10",
+	}, matches)
+}
+
+func TestL7PacketInMeter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bridge := mocks.NewMockBridge(ctrl)
+	meter := mocks.NewMockMeter(ctrl)
+	meterBand := mocks.NewMockMeterBandBuilder(ctrl)
+	c := newL7TestClient()
+	c.bridge = bridge
+	bridge.EXPECT().CreateMeter(l7PacketInMeterID, binding.MeterPktps|binding.MeterStats).Return(meter)
+	meter.EXPECT().MeterBand().Return(meterBand)
+	meterBand.EXPECT().MeterType(binding.MeterBandDrop).Return(meterBand)
+	meterBand.EXPECT().Rate(uint32(l7PacketInRate)).Return(meterBand)
+	meterBand.EXPECT().Done().Return(meter)
+	assert.Equal(t, meter, c.l7PacketInMeter())
+
+	var matches []string
+	for _, flow := range c.l7PacketInMeterFlows(cookie.L7) {
+		matches = append(matches, flow.MatchString())
+		assert.Equal(t, priorityL7Inspect, flow.FlowPriority())
+	}
+	assert.Equal(t, []string{
+		"table=103,tcp,ct_label[64..65]=0x10",
+		"table=103,tcpv6,ct_label[64..65]=0x10",
+	}, matches)
+}
+
+func TestL7DenyFlow(t *testing.T) {
+	c := newL7TestClient()
+	flow := c.l7DenyFlow(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 34567, 80)
+	assert.Equal(t, "table=103,tcp,nw_src=10.10.0.1,nw_dst=10.10.0.2,tp_src=0x8707,tp_dst=0x50,ct_label[64..65]=0x10", flow.MatchString())
+	assert.Equal(t, priorityL7Deny, flow.FlowPriority())
+	flow = c.l7DenyFlow(net.ParseIP("fd00::1"), net.ParseIP("fd00::2"), 34567, 80)
+	assert.Equal(t, "table=103,tcpv6,ipv6_src=fd00::1,ipv6_dst=fd00::2,tp_src=0x8707,tp_dst=0x50,ct_label[64..65]=0x10", flow.MatchString())
+}
+
+func TestResumeL7Packet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bridge := mocks.NewMockBridge(ctrl)
+	c := newL7TestClient()
+	c.bridge = bridge
+	pktIn := &ofctrl.PacketIn{TableId: uint8(IngressL7Table)}
+	bridge.EXPECT().ResumePacketIn(pktIn, conntrackCommitTable).Return(nil)
+	assert.NoError(t, c.ResumeL7Packet(pktIn))
+}
+
+func TestGetL7ProtocolsFromConjunction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c = prepareClient(ctrl)
+	protocols := []v1beta2.L7Protocol{{HTTP: &v1beta2.HTTPProtocol{Method: "GET"}}}
+	c.policyCache.Add(&policyRuleConjunction{id: 10, ruleTableID: AntreaPolicyIngressRuleTable, l7Protocols: protocols})
+	c.policyCache.Add(&policyRuleConjunction{id: 11, ruleTableID: AntreaPolicyIngressRuleTable})
+	assert.Equal(t, protocols, c.GetL7ProtocolsFromConjunction(10))
+	assert.Nil(t, c.GetL7ProtocolsFromConjunction(11))
+	assert.Nil(t, c.GetL7ProtocolsFromConjunction(12))
+}
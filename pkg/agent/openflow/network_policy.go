@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
@@ -51,6 +52,8 @@ var (
 	// There could be other flows like default flow and Traceflow flows in the table. Only metric flows are supposed to
 	// have normal priority.
 	metricFlowIdentifier = fmt.Sprintf("priority=%d,", priorityNormal)
+	// auditFlowIdentifier is used to identify conjunction action flows of audit rules in rule tables.
+	auditFlowIdentifier = "conj_id="
 )
 
 // IP address calculated from Pod's address.
@@ -496,6 +499,17 @@ type policyRuleConjunction struct {
 	// NetworkPolicy reference information for debugging usage.
 	npRef       *v1beta2.NetworkPolicyReference
 	ruleTableID binding.TableIDType
+	// audit is true if the rule only logs the matched packets without enforcing its action.
+	audit bool
+	// rateLimit is the rate limit enforced with OVS meters on the traffic matched by the rule. nil if the rule has
+	// no rate limit.
+	rateLimit *v1beta2.RateLimit
+	// rateLimitPeers are the meters and the meter flows enforcing the rate limit for each peer of the rule, keyed by
+	// the peer address. The only key is empty if the rule matches any peer. It is protected by conjMatchFlowLock.
+	rateLimitPeers map[string]*rateLimitPeer
+	// l7Protocols are the application layer protocols of an ingress rule, which the requests of the connections
+	// allowed by the rule are inspected with. nil if the rule has no application layer protocols.
+	l7Protocols []v1beta2.L7Protocol
 }
 
 // clause groups conjunctive match flows. Matches in a clause represent source addresses(for fromClause), or destination
@@ -787,13 +801,20 @@ func (c *client) InstallPolicyRuleFlows(rule *types.PolicyRule) error {
 	defer c.conjMatchFlowLock.Unlock()
 	ctxChanges := c.calculateMatchFlowChangesForRule(conj, rule, false)
 
+	if err := c.addRateLimitPeers(conj, rateLimitPeerAddresses(conj, rule)); err != nil {
+		c.rollbackRateLimit(conj)
+		return err
+	}
 	if err := c.ofEntryOperations.AddAll(conj.metricFlows); err != nil {
+		c.rollbackRateLimit(conj)
 		return err
 	}
 	if err := c.ofEntryOperations.AddAll(conj.actionFlows); err != nil {
+		c.rollbackRateLimit(conj)
 		return err
 	}
 	if err := c.applyConjunctiveMatchFlows(ctxChanges); err != nil {
+		c.rollbackRateLimit(conj)
 		return err
 	}
 	// Add the policyRuleConjunction into policyCache
@@ -829,9 +850,20 @@ func (c *client) calculateActionFlowChangesForRule(rule *types.PolicyRule) *poli
 		if rule.IsAntreaNetworkPolicyRule() && *rule.Action == secv1alpha1.RuleActionDrop {
 			metricFlows = append(metricFlows, c.dropRuleMetricFlow(ruleOfID, isIngress))
 			actionFlows = append(actionFlows, c.conjunctionActionDropFlow(ruleOfID, ruleTable.GetID(), rule.Priority, rule.EnableLogging))
+		} else if rule.IsAntreaNetworkPolicyRule() && *rule.Action == secv1alpha1.RuleActionAudit {
+			// Audit rules have no metric flows, their metrics are collected from the action flow directly.
+			actionFlows = append(actionFlows, c.conjunctionActionAuditFlow(ruleOfID, ruleTable.GetID(), rule.Priority))
+			conj.audit = true
 		} else {
-			metricFlows = append(metricFlows, c.allowRulesMetricFlows(ruleOfID, isIngress)...)
-			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable.GetID(), dropTable.GetNext(), rule.Priority, rule.EnableLogging)...)
+			if rule.IsAntreaNetworkPolicyRule() && rule.RateLimit != nil {
+				conj.rateLimit = rule.RateLimit
+			}
+			if rule.IsAntreaNetworkPolicyRule() && isIngress && len(rule.L7Protocols) > 0 {
+				conj.l7Protocols = rule.L7Protocols
+			}
+			inspectL7 := conj.l7Protocols != nil
+			metricFlows = append(metricFlows, c.allowRulesMetricFlows(ruleOfID, isIngress, conj.rateLimit != nil, inspectL7)...)
+			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable.GetID(), dropTable.GetNext(), rule.Priority, rule.EnableLogging, inspectL7)...)
 		}
 		conj.actionFlows = actionFlows
 		conj.metricFlows = metricFlows
@@ -863,9 +895,11 @@ func (c *client) BatchInstallPolicyRuleFlows(ofPolicyRules []*types.PolicyRule)
 	var allCtxChanges []*conjMatchFlowContextChange
 	var allFlows []binding.Flow
 	var updatedConjunctions []*policyRuleConjunction
+	var rateLimitPeers [][]types.Address
 
 	for _, rule := range ofPolicyRules {
 		conj := c.calculateActionFlowChangesForRule(rule)
+		rateLimitPeers = append(rateLimitPeers, rateLimitPeerAddresses(conj, rule))
 		ctxChanges := c.calculateMatchFlowChangesForRule(conj, rule, true)
 		allFlows = append(allFlows, conj.actionFlows...)
 		allFlows = append(allFlows, conj.metricFlows...)
@@ -881,6 +915,13 @@ func (c *client) BatchInstallPolicyRuleFlows(ofPolicyRules []*types.PolicyRule)
 		// Add the policyRuleConjunction into policyCache
 		c.policyCache.Add(conj)
 	}
+	c.conjMatchFlowLock.Lock()
+	defer c.conjMatchFlowLock.Unlock()
+	for i, conj := range updatedConjunctions {
+		if err := c.addRateLimitPeers(conj, rateLimitPeers[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1102,6 +1143,9 @@ func (c *client) UninstallPolicyRuleFlows(ruleID uint32) ([]string, error) {
 
 	c.conjMatchFlowLock.Lock()
 	defer c.conjMatchFlowLock.Unlock()
+	if err := c.uninstallRateLimit(conj); err != nil {
+		return nil, err
+	}
 	// Get the conjMatchFlowContext changes.
 	ctxChanges := conj.calculateChangesForRuleDeletion()
 	// Send the changed OpenFlow entries to the OVS bridge and update the conjMatchFlowContext.
@@ -1179,6 +1223,7 @@ func (c *client) replayPolicyFlows() {
 	if err := c.ofEntryOperations.AddAll(flows); err != nil {
 		klog.Errorf("Error when replaying flows: %v", err)
 	}
+	c.replayRateLimits()
 }
 
 // AddPolicyRuleAddress adds one or multiple addresses to the specified NetworkPolicy rule. If addrType is srcAddress, the
@@ -1202,6 +1247,11 @@ func (c *client) AddPolicyRuleAddress(ruleID uint32, addrType types.AddressType,
 
 	c.conjMatchFlowLock.Lock()
 	defer c.conjMatchFlowLock.Unlock()
+	if addrType == conj.rateLimitPeerType() {
+		if err := c.addRateLimitPeers(conj, addresses); err != nil {
+			return err
+		}
+	}
 	flowChanges := clause.addAddrFlows(c, addrType, addresses, priority)
 	return c.applyConjunctiveMatchFlows(flowChanges)
 }
@@ -1231,7 +1281,13 @@ func (c *client) DeletePolicyRuleAddress(ruleID uint32, addrType types.AddressTy
 	// Remove policyRuleConjunction to actions of conjunctive match using specific address.
 	changes := clause.deleteAddrFlows(addrType, addresses, priority)
 	// Update the Openflow entries on the OVS bridge, and update local cache.
-	return c.applyConjunctiveMatchFlows(changes)
+	if err := c.applyConjunctiveMatchFlows(changes); err != nil {
+		return err
+	}
+	if addrType == conj.rateLimitPeerType() {
+		return c.deleteRateLimitPeers(conj, addresses)
+	}
+	return nil
 }
 
 func (c *client) GetNetworkPolicyFlowKeys(npName, npNamespace string) []string {
@@ -1312,13 +1368,17 @@ func (c *client) updateConjunctionActionFlows(conj *policyRuleConjunction, updat
 	newActionFlows := make([]binding.Flow, len(conj.actionFlows))
 	copy(newActionFlows, updates.newActionFlows)
 	newConj := &policyRuleConjunction{
-		id:            conj.id,
-		fromClause:    conj.fromClause,
-		toClause:      conj.toClause,
-		serviceClause: conj.serviceClause,
-		actionFlows:   newActionFlows,
-		npRef:         conj.npRef,
-		ruleTableID:   conj.ruleTableID,
+		id:             conj.id,
+		fromClause:     conj.fromClause,
+		toClause:       conj.toClause,
+		serviceClause:  conj.serviceClause,
+		actionFlows:    newActionFlows,
+		npRef:          conj.npRef,
+		ruleTableID:    conj.ruleTableID,
+		audit:          conj.audit,
+		rateLimit:      conj.rateLimit,
+		rateLimitPeers: conj.rateLimitPeers,
+		l7Protocols:    conj.l7Protocols,
 	}
 	return newConj
 }
@@ -1484,5 +1544,56 @@ func (c *client) NetworkPolicyMetrics() map[uint32]*types.RuleMetric {
 	// flows to get the correct number of total packets.
 	collectMetricsFromFlows(egressFlows)
 	collectMetricsFromFlows(ingressFlows)
+	c.collectAuditRuleMetrics(result)
+	c.collectRateLimitMetrics(result)
 	return result
 }
+
+// collectAuditRuleMetrics collects the metrics of audit rules from their conjunction action flows, as the packets
+// matching them are not committed with the rule ID and thus not counted in the metric tables. Only the first packet
+// of a connection is evaluated by the rule tables, so the number of sessions equals the number of packets.
+func (c *client) collectAuditRuleMetrics(result map[uint32]*types.RuleMetric) {
+	auditRuleTables := map[binding.TableIDType]sets.Int64{}
+	for _, obj := range c.policyCache.List() {
+		conj := obj.(*policyRuleConjunction)
+		if !conj.audit {
+			continue
+		}
+		if _, ok := auditRuleTables[conj.ruleTableID]; !ok {
+			auditRuleTables[conj.ruleTableID] = sets.NewInt64()
+		}
+		auditRuleTables[conj.ruleTableID].Insert(int64(conj.id))
+	}
+	for tableID, ruleIDs := range auditRuleTables {
+		flows, _ := c.ovsctlClient.DumpTableFlows(uint8(tableID))
+		for _, flow := range flows {
+			if !strings.Contains(flow, auditFlowIdentifier) {
+				continue
+			}
+			ruleID, metric := parseAuditFlow(parseFlowToMap(flow))
+			if !ruleIDs.Has(int64(ruleID)) {
+				continue
+			}
+			if accMetric, ok := result[ruleID]; ok {
+				accMetric.Merge(&metric)
+			} else {
+				result[ruleID] = &metric
+			}
+		}
+	}
+}
+
+func parseAuditFlow(flowMap map[string]string) (uint32, types.RuleMetric) {
+	m := types.RuleMetric{}
+	pkts, _ := strconv.ParseUint(flowMap["n_packets"], 10, 64)
+	m.Packets = pkts
+	m.Sessions = pkts
+	bytes, _ := strconv.ParseUint(flowMap["n_bytes"], 10, 64)
+	m.Bytes = bytes
+	conjID := flowMap["conj_id"]
+	if i := strings.Index(conjID, " "); i != -1 {
+		conjID = conjID[:i]
+	}
+	id, _ := strconv.ParseUint(conjID, 10, 32)
+	return uint32(id), m
+}
@@ -898,3 +898,72 @@ func TestNetworkPolicyMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkPolicyMetricsWithAuditRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c = prepareClient(ctrl)
+	mockOVSClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	c.ovsctlClient = mockOVSClient
+	c.policyCache.Add(&policyRuleConjunction{id: 7, ruleTableID: AntreaPolicyEgressRuleTable, audit: true})
+	c.policyCache.Add(&policyRuleConjunction{id: 8, ruleTableID: AntreaPolicyEgressRuleTable})
+	egressFlows := []string{
+		"table=61, n_packets=4, n_bytes=336, priority=200,reg0=0x100000/0x100000,reg3=0x4 actions=drop",
+	}
+	ruleFlows := []string{
+		"table=45, n_packets=3, n_bytes=222, priority=14900,conj_id=7 actions=load:0x7->NXM_NX_REG5[],load:0x2->NXM_NX_REG0[21..22],controller(max_len=65535,reason=no_match,id=1),goto_table:50",
+		"table=45, n_packets=5, n_bytes=370, priority=14800,conj_id=8 actions=load:0x8->NXM_NX_REG3[],load:0x1->NXM_NX_REG0[20],goto_table:61",
+		"table=45, n_packets=9, n_bytes=666, priority=14900,ip,nw_src=10.10.0.5 actions=conjunction(7,1/2)",
+		"table=45, n_packets=0, n_bytes=0, priority=0 actions=goto_table:50",
+	}
+	gomock.InOrder(
+		mockOVSClient.EXPECT().DumpTableFlows(uint8(EgressMetricTable)).Return(egressFlows, nil),
+		mockOVSClient.EXPECT().DumpTableFlows(uint8(IngressMetricTable)).Return(nil, nil),
+		mockOVSClient.EXPECT().DumpTableFlows(uint8(AntreaPolicyEgressRuleTable)).Return(ruleFlows, nil),
+	)
+	want := map[uint32]*types.RuleMetric{
+		4: {Bytes: 336, Sessions: 4, Packets: 4},
+		7: {Bytes: 222, Sessions: 3, Packets: 3},
+	}
+	assert.Equal(t, want, c.NetworkPolicyMetrics())
+}
+
+func TestConjunctionActionAuditFlow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
+	c := ofClient.(*client)
+	c.cookieAllocator = cookie.NewAllocator(0)
+	priority := uint16(14900)
+	for _, tc := range []struct {
+		tableID     binding.TableIDType
+		conjReg     regType
+		auditedMark binding.Range
+	}{
+		{AntreaPolicyEgressRuleTable, EgressReg, binding.Range{23, 23}},
+		{AntreaPolicyIngressRuleTable, IngressReg, binding.Range{25, 25}},
+		{EgressDefaultTable, EgressReg, binding.Range{27, 27}},
+	} {
+		table := mocks.NewMockTable(ctrl)
+		builder := mocks.NewMockFlowBuilder(ctrl)
+		action := mocks.NewMockAction(ctrl)
+		c.pipeline = map[binding.TableIDType]binding.Table{tc.tableID: table}
+		table.EXPECT().BuildFlow(priority).Return(builder)
+		builder.EXPECT().MatchConjID(uint32(7)).Return(builder)
+		// The audit flow doesn't match the packets which have been audited in the same table.
+		builder.EXPECT().MatchRegRange(int(marksReg), uint32(0), tc.auditedMark).Return(builder)
+		builder.EXPECT().Action().Return(action).AnyTimes()
+		action.EXPECT().LoadRegRange(int(tc.conjReg), uint32(7), binding.Range{0, 31}).Return(builder)
+		action.EXPECT().LoadRegRange(int(marksReg), uint32(DispositionAudit), APDispositionMarkRange).Return(builder)
+		action.EXPECT().SendToController(uint8(PacketInReasonNP)).Return(builder)
+		action.EXPECT().LoadRegRange(int(marksReg), uint32(auditedMark), tc.auditedMark).Return(builder)
+		// The audited packet is resubmitted to the same table to be evaluated by the lower priority rules, instead of
+		// skipping them by going to the next table.
+		action.EXPECT().ResubmitToTable(tc.tableID).Return(builder)
+		action.EXPECT().GotoTable(gomock.Any()).Times(0)
+		builder.EXPECT().Cookie(gomock.Any()).Return(builder)
+		builder.EXPECT().Done().Return(mocks.NewMockFlow(ctrl))
+		c.conjunctionActionAuditFlow(7, tc.tableID, &priority)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/contiv/ofnet/ofctrl"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
 )
 
 type ofpPacketInReason uint8
@@ -32,6 +34,9 @@ type PacketInHandler interface {
 const (
 	// Max packetInQueue size.
 	packetInQueueSize int = 256
+	// l7PacketInQueueSize is the max size of the queue of the packetIn messages sent for L7 inspection. It's larger
+	// than packetInQueueSize as all the packets sent by the clients of the inspected connections are queued.
+	l7PacketInQueueSize int = 4096
 	// PacketIn reasons
 	PacketInReasonTF ofpPacketInReason = 1
 	PacketInReasonNP ofpPacketInReason = 0
@@ -50,18 +55,30 @@ func (c *client) RegisterPacketInHandler(packetHandlerReason uint8, packetHandle
 	c.packetInHandlers[packetHandlerReason][packetHandlerName] = handler
 }
 
+// RegisterL7PacketInHandler stores the handler of the packetIn messages sent for L7 inspection. The messages are
+// identified by the L7 cookie category of the flows sending them rather than by their reason.
+func (c *client) RegisterL7PacketInHandler(packetInHandler interface{}) {
+	handler, ok := packetInHandler.(PacketInHandler)
+	if !ok {
+		klog.Errorf("Invalid controller to handle L7 packetin.")
+		return
+	}
+	c.l7PacketInHandler = handler
+}
+
 // featureStartPacketIn contains packetin resources specifically for each feature that uses packetin.
 type featureStartPacketIn struct {
 	reason        uint8
+	queueSize     int
 	subscribeCh   chan *ofctrl.PacketIn
 	stopCh        <-chan struct{}
 	packetInQueue *workqueue.Type
 }
 
-func newfeatureStartPacketIn(reason uint8, stopCh <-chan struct{}) *featureStartPacketIn {
-	featurePacketIn := featureStartPacketIn{reason: reason, stopCh: stopCh}
+func newfeatureStartPacketIn(reason uint8, queueName string, queueSize int, stopCh <-chan struct{}) *featureStartPacketIn {
+	featurePacketIn := featureStartPacketIn{reason: reason, queueSize: queueSize, stopCh: stopCh}
 	featurePacketIn.subscribeCh = make(chan *ofctrl.PacketIn)
-	featurePacketIn.packetInQueue = workqueue.NewNamed(string(reason))
+	featurePacketIn.packetInQueue = workqueue.NewNamed(queueName)
 	return &featurePacketIn
 }
 
@@ -70,7 +87,7 @@ func (f *featureStartPacketIn) ListenPacketIn() {
 		select {
 		case pktIn := <-f.subscribeCh:
 			// Ensure that the queue doesn't grow too big. This is NOT to provide an exact guarantee.
-			if f.packetInQueue.Len() < packetInQueueSize {
+			if f.packetInQueue.Len() < f.queueSize {
 				f.packetInQueue.Add(pktIn)
 			} else {
 				klog.Warningf("Max packetInQueue size exceeded.")
@@ -83,14 +100,21 @@ func (f *featureStartPacketIn) ListenPacketIn() {
 }
 
 // StartPacketInHandler is the starting point for processing feature packetin requests.
+// The packetIn messages sent for L7 inspection, if an L7 handler is registered, are processed with their own queue and
+// goroutine, so that the other features are not delayed by the L7 inspected connections.
 func (c *client) StartPacketInHandler(packetInStartedReason []uint8, stopCh <-chan struct{}) {
+	if c.l7PacketInHandler != nil {
+		if err := c.subscribeL7PacketIn(stopCh); err != nil {
+			klog.Errorf("received error %+v while subscribing packetin for L7 inspection", err)
+		}
+	}
 	if len(c.packetInHandlers) == 0 || len(packetInStartedReason) == 0 {
 		return
 	}
 
 	// Iterate through each feature that starts packetin. Subscribe with their specified reason.
 	for _, reason := range packetInStartedReason {
-		featurePacketIn := newfeatureStartPacketIn(reason, stopCh)
+		featurePacketIn := newfeatureStartPacketIn(reason, string(reason), packetInQueueSize, stopCh)
 		err := c.subscribeFeaturePacketIn(featurePacketIn)
 		if err != nil {
 			klog.Errorf("received error %+v while subscribing packetin for each feature", err)
@@ -103,12 +127,37 @@ func (c *client) subscribeFeaturePacketIn(featurePacketIn *featureStartPacketIn)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Subscribe %d PacketIn failed %+v", featurePacketIn.reason, err))
 	}
-	go c.parsePacketIn(featurePacketIn.packetInQueue, featurePacketIn.reason)
+	go c.parsePacketIn(featurePacketIn.packetInQueue, func(pktIn *ofctrl.PacketIn) {
+		// Use corresponding handlers subscribed to the reason to handle PacketIn
+		for name, handler := range c.packetInHandlers[featurePacketIn.reason] {
+			err := handler.HandlePacketIn(pktIn)
+			if err != nil {
+				klog.Errorf("PacketIn handler %s failed to process packet: %+v", name, err)
+			}
+		}
+	})
+	go featurePacketIn.ListenPacketIn()
+	return nil
+}
+
+// subscribeL7PacketIn subscribes the packetIn messages sent by the flows of the L7 cookie category, which are sent
+// with PacketInReasonNP, and handles them with the L7 handler.
+func (c *client) subscribeL7PacketIn(stopCh <-chan struct{}) error {
+	featurePacketIn := newfeatureStartPacketIn(uint8(PacketInReasonNP), "l7", l7PacketInQueueSize, stopCh)
+	l7Cookie, l7CookieMask := cookie.CookieMaskForCategory(cookie.L7)
+	if err := c.bridge.SubscribePacketInByCookie(l7Cookie, l7CookieMask, featurePacketIn.subscribeCh); err != nil {
+		return fmt.Errorf("Subscribe L7 PacketIn failed %+v", err)
+	}
+	go c.parsePacketIn(featurePacketIn.packetInQueue, func(pktIn *ofctrl.PacketIn) {
+		if err := c.l7PacketInHandler.HandlePacketIn(pktIn); err != nil {
+			klog.Errorf("L7 PacketIn handler failed to process packet: %+v", err)
+		}
+	})
 	go featurePacketIn.ListenPacketIn()
 	return nil
 }
 
-func (c *client) parsePacketIn(packetInQueue workqueue.Interface, packetHandlerReason uint8) {
+func (c *client) parsePacketIn(packetInQueue workqueue.Interface, handlePacketIn func(pktIn *ofctrl.PacketIn)) {
 	for {
 		obj, quit := packetInQueue.Get()
 		if quit {
@@ -120,12 +169,6 @@ func (c *client) parsePacketIn(packetInQueue workqueue.Interface, packetHandlerR
 			klog.Errorf("Invalid packetin data in queue, skipping.")
 			continue
 		}
-		// Use corresponding handlers subscribed to the reason to handle PacketIn
-		for name, handler := range c.packetInHandlers[packetHandlerReason] {
-			err := handler.HandlePacketIn(pktIn)
-			if err != nil {
-				klog.Errorf("PacketIn handler %s failed to process packet: %+v", name, err)
-			}
-		}
+		handlePacketIn(pktIn)
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
 	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	proxytypes "github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl"
@@ -52,6 +53,7 @@ const (
 	EgressRuleTable              binding.TableIDType = 50
 	EgressDefaultTable           binding.TableIDType = 60
 	EgressMetricTable            binding.TableIDType = 61
+	EgressRateLimitTable         binding.TableIDType = 62
 	l3ForwardingTable            binding.TableIDType = 70
 	l3DecTTLTable                binding.TableIDType = 71
 	l2ForwardingCalcTable        binding.TableIDType = 80
@@ -60,6 +62,9 @@ const (
 	IngressRuleTable             binding.TableIDType = 90
 	IngressDefaultTable          binding.TableIDType = 100
 	IngressMetricTable           binding.TableIDType = 101
+	IngressRateLimitTable        binding.TableIDType = 102
+	IngressL7Table               binding.TableIDType = 103
+	IngressL7InspectTable        binding.TableIDType = 104
 	conntrackCommitTable         binding.TableIDType = 105
 	hairpinSNATTable             binding.TableIDType = 106
 	L2ForwardingOutTable         binding.TableIDType = 110
@@ -116,12 +121,16 @@ var (
 		{EgressRuleTable, "EgressRule"},
 		{EgressDefaultTable, "EgressDefaultRule"},
 		{EgressMetricTable, "EgressMetric"},
+		{EgressRateLimitTable, "EgressRateLimit"},
 		{l3ForwardingTable, "l3Forwarding"},
 		{l2ForwardingCalcTable, "L2Forwarding"},
 		{AntreaPolicyIngressRuleTable, "AntreaPolicyIngressRule"},
 		{IngressRuleTable, "IngressRule"},
 		{IngressDefaultTable, "IngressDefaultRule"},
 		{IngressMetricTable, "IngressMetric"},
+		{IngressRateLimitTable, "IngressRateLimit"},
+		{IngressL7Table, "IngressL7"},
+		{IngressL7InspectTable, "IngressL7Inspect"},
 		{conntrackCommitTable, "ConntrackCommit"},
 		{hairpinSNATTable, "HairpinSNATTable"},
 		{L2ForwardingOutTable, "Output"},
@@ -195,7 +204,8 @@ func (rt regType) reg() string {
 
 const (
 	// marksReg stores traffic-source mark and pod-found mark.
-	// traffic-source resides in [0..15], pod-found resides in [16], Antrea Policy disposition Allow or Drop in [21]
+	// traffic-source resides in [0..15], pod-found resides in [16], Antrea Policy disposition Allow, Drop or Audit in [21..22],
+	// audited marks of the Antrea Policy rule tables in [23..28]
 	marksReg        regType = 0
 	PortCacheReg    regType = 1
 	swapReg         regType = 2
@@ -219,6 +229,10 @@ const (
 
 	CtZone   = 0xfff0
 	CtZoneV6 = 0xffe6
+	// SNATCtZone and SNATCtZoneV6 are used to SNAT the Service traffic from the host network which is sent back
+	// to the host gateway after load-balancing, when proxyAll is enabled.
+	SNATCtZone   = 0xfff1
+	SNATCtZoneV6 = 0xffe7
 
 	portFoundMark    = 0b1
 	snatRequiredMark = 0b1
@@ -228,6 +242,7 @@ const (
 	// AntreaProxy is enabled.
 	macRewriteMark = 0b1
 	cnpDropMark    = 0b1
+	auditedMark    = 0b1
 
 	// gatewayCTMark is used to to mark connections initiated through the host gateway interface
 	// (i.e. for which the first packet of the connection was received through the gateway).
@@ -235,21 +250,23 @@ const (
 	snatCTMark    = 0x40
 	ServiceCTMark = 0x21
 
-	// disposition is loaded in marksReg [21]
+	// disposition is loaded in marksReg [21..22]
 	DispositionMarkReg regType = 0
-	// disposition marks the flow action as either Allow or Drop
-	DispositionAllow = 0b0
-	DispositionDrop  = 0b1
+	// disposition marks the flow action as either Allow, Drop or Audit
+	DispositionAllow = 0b00
+	DispositionDrop  = 0b01
+	DispositionAudit = 0b10
 )
 
 var DispositionToString = map[uint32]string{
 	DispositionAllow: "Allow",
 	DispositionDrop:  "Drop",
+	DispositionAudit: "Audit",
 }
 
 var (
-	// APDispositionMarkRange takes the 21 to 21 bits of register marksReg to indicate disposition of Antrea Policy.
-	APDispositionMarkRange = binding.Range{21, 21}
+	// APDispositionMarkRange takes the 21 to 22 bits of register marksReg to indicate disposition of Antrea Policy.
+	APDispositionMarkRange = binding.Range{21, 22}
 	// ofPortMarkRange takes the 16th bit of register marksReg to indicate if the ofPort number of an interface
 	// is found or not. Its value is 0x1 if yes.
 	ofPortMarkRange = binding.Range{16, 16}
@@ -265,6 +282,14 @@ var (
 	// if the packet's MAC addresses need to be rewritten. Its value is 0x1 if yes.
 	macRewriteMarkRange = binding.Range{19, 19}
 	cnpDropMarkRange    = binding.Range{20, 20}
+	// auditedMarkRanges takes one bit of register marksReg for each Antrea Policy rule table to indicate if the packet
+	// has been logged by an Audit rule of the table. Its value is 0x1 if yes.
+	auditedMarkRanges = map[binding.TableIDType]binding.Range{
+		AntreaPolicyEgressRuleTable:  {23, 23},
+		AntreaPolicyIngressRuleTable: {25, 25},
+		EgressDefaultTable:           {27, 27},
+		IngressDefaultTable:          {28, 28},
+	}
 	// endpointIPRegRange takes a 32-bit range of register endpointIPReg to store
 	// the selected Service Endpoint IP.
 	endpointIPRegRange = binding.Range{0, 31}
@@ -310,6 +335,7 @@ type flowCategoryCache struct {
 
 type client struct {
 	enableProxy                                   bool
+	proxyAll                                      bool
 	enableAntreaPolicy                            bool
 	roundInfo                                     types.RoundInfo
 	cookieAllocator                               cookie.Allocator
@@ -329,21 +355,28 @@ type client struct {
 	policyCache       cache.Indexer
 	conjMatchFlowLock sync.Mutex // Lock for access globalConjMatchFlowCache
 	groupCache        sync.Map
+	// serviceGroupEndpoints stores the Endpoints of the Service groups, in the order of their buckets.
+	serviceGroupEndpoints sync.Map
 	// globalConjMatchFlowCache is a global map for conjMatchFlowContext. The key is a string generated from the
 	// conjMatchFlowContext.
 	globalConjMatchFlowCache map[string]*conjMatchFlowContext
 	// replayMutex provides exclusive access to the OFSwitch to the ReplayFlows method.
 	replayMutex   sync.RWMutex
 	nodeConfig    *config.NodeConfig
+	networkConfig *config.NetworkConfig
 	encapMode     config.TrafficEncapModeType
 	gatewayOFPort uint32
 	// packetInHandlers stores handler to process PacketIn event. Each packetin reason can have multiple handlers registered.
 	// When a packetin arrives, openflow send packet to registered handlers in this map.
 	packetInHandlers map[uint8]map[string]PacketInHandler
+	// l7PacketInHandler is the handler of the packetIn messages sent for L7 inspection.
+	l7PacketInHandler PacketInHandler
 	// Supported IP Protocols (IP or IPv6) on the current Node.
 	ipProtocols []binding.Protocol
 	// ovsctlClient is the interface for executing OVS "ovs-ofctl" and "ovs-appctl" commands.
 	ovsctlClient ovsctl.OVSCtlClient
+	// meterIDAllocator allocates the IDs of the meters enforcing the rate limits of policy rules.
+	meterIDAllocator *meterIDAllocator
 }
 
 func (c *client) GetTunnelVirtualMAC() net.HardwareAddr {
@@ -1083,23 +1116,34 @@ func (c *client) arpNormalFlow(category cookie.Category) binding.Flow {
 		Done()
 }
 
-func (c *client) allowRulesMetricFlows(conjunctionID uint32, ingress bool) []binding.Flow {
+// allowRulesMetricFlows generates the flows counting the packets of an allow rule. If rateLimited is true, the
+// packets are sent to the rate limit table after being counted, where the meters of the rule are applied to them.
+// If inspectL7 is true, the packets are sent to the IngressL7Table, which the IngressRateLimitTable also goes to.
+func (c *client) allowRulesMetricFlows(conjunctionID uint32, ingress bool, rateLimited bool, inspectL7 bool) []binding.Flow {
 	metricTableID := IngressMetricTable
+	rateLimitTableID := IngressRateLimitTable
 	offset := 0
 	// We use the 0..31 bits of the ct_label to store the ingress rule ID and use the 32..63 bits to store the
 	// egress rule ID.
 	labelRange := metricIngressRuleIDRange
 	if !ingress {
 		metricTableID = EgressMetricTable
+		rateLimitTableID = EgressRateLimitTable
 		offset = 32
 		labelRange = metricEgressRuleIDRange
 	}
+	nextTableID := c.pipeline[metricTableID].GetNext()
+	if rateLimited {
+		nextTableID = rateLimitTableID
+	} else if inspectL7 && ingress {
+		nextTableID = IngressL7Table
+	}
 	metricFlow := func(isCTNew bool, protocol binding.Protocol) binding.Flow {
 		return c.pipeline[metricTableID].BuildFlow(priorityNormal).
 			MatchProtocol(protocol).
 			MatchCTStateNew(isCTNew).
 			MatchCTLabelRange(0, uint64(conjunctionID)<<offset, labelRange).
-			Action().GotoTable(c.pipeline[metricTableID].GetNext()).
+			Action().GotoTable(nextTableID).
 			Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
 			Done()
 	}
@@ -1168,7 +1212,7 @@ func (c *client) ipv6Flows(category cookie.Category) []binding.Flow {
 
 // conjunctionActionFlow generates the flow to jump to a specific table if policyRuleConjunction ID is matched. Priority of
 // conjunctionActionFlow is created at priorityLow for k8s network policies, and *priority assigned by PriorityAssigner for AntreaPolicy.
-func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.TableIDType, nextTable binding.TableIDType, priority *uint16, enableLogging bool) []binding.Flow {
+func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.TableIDType, nextTable binding.TableIDType, priority *uint16, enableLogging bool, inspectL7 bool) []binding.Flow {
 	var ofPriority uint16
 	if priority == nil {
 		ofPriority = priorityLow
@@ -1186,27 +1230,23 @@ func (c *client) conjunctionActionFlow(conjunctionID uint32, tableID binding.Tab
 		if proto == binding.ProtocolIPv6 {
 			ctZone = CtZoneV6
 		}
+		fb := c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
+			MatchConjID(conjunctionID).
+			Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}) // Traceflow.
 		if enableLogging {
-			return c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
-				MatchConjID(conjunctionID).
-				Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}).       // Traceflow.
-				Action().LoadRegRange(int(marksReg), DispositionAllow, APDispositionMarkRange). // AntreaPolicy
-				Action().SendToController(uint8(PacketInReasonNP)).
-				Action().CT(true, nextTable, ctZone). // CT action requires commit flag if actions other than NAT without arguments are specified.
-				LoadToLabelRange(uint64(conjunctionID), &labelRange).
-				CTDone().
-				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
-				Done()
-		} else {
-			return c.pipeline[tableID].BuildFlow(ofPriority).MatchProtocol(proto).
-				MatchConjID(conjunctionID).
-				Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}). // Traceflow.
-				Action().CT(true, nextTable, ctZone).                                     // CT action requires commit flag if actions other than NAT without arguments are specified.
-				LoadToLabelRange(uint64(conjunctionID), &labelRange).
-				CTDone().
-				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
-				Done()
+			// AntreaPolicy audit logging.
+			fb = fb.Action().LoadRegRange(int(marksReg), DispositionAllow, APDispositionMarkRange).
+				Action().SendToController(uint8(PacketInReasonNP))
 		}
+		// CT action requires commit flag if actions other than NAT without arguments are specified.
+		ctAction := fb.Action().CT(true, nextTable, ctZone).LoadToLabelRange(uint64(conjunctionID), &labelRange)
+		if inspectL7 {
+			// The application layer requests of the connection must be inspected before it's allowed.
+			ctAction = ctAction.LoadToLabelRange(l7StateInspect, &l7StateLabelRange)
+		}
+		return ctAction.CTDone().
+			Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+			Done()
 	}
 	var flows []binding.Flow
 	for _, proto := range c.ipProtocols {
@@ -1245,6 +1285,36 @@ func (c *client) conjunctionActionDropFlow(conjunctionID uint32, tableID binding
 	}
 }
 
+// conjunctionActionAuditFlow generates the flow to log the packet if policyRuleConjunction ID is matched, without
+// enforcing the rule. The packet is sent to the controller for logging, marked as audited in the table, and then
+// resubmitted to the same table, so that it is evaluated by the lower priority rules of the table, including the rules
+// of the lower Tiers, and by the rules in the following tables. The audit flows of the table don't match the audited
+// packets, hence only the highest priority Audit rule of a table which matches the packet logs it. The packet and byte
+// counts of this flow are reported as the metrics of the rule.
+func (c *client) conjunctionActionAuditFlow(conjunctionID uint32, tableID binding.TableIDType, priority *uint16) binding.Flow {
+	var ofPriority uint16
+	if priority == nil {
+		ofPriority = priorityLow
+	} else {
+		ofPriority = *priority
+	}
+	conjReg := IngressReg
+	if _, ok := egressTables[tableID]; ok {
+		conjReg = EgressReg
+	}
+	auditedMarkRange := auditedMarkRanges[tableID]
+	return c.pipeline[tableID].BuildFlow(ofPriority).
+		MatchConjID(conjunctionID).
+		MatchRegRange(int(marksReg), 0, auditedMarkRange).
+		Action().LoadRegRange(int(conjReg), conjunctionID, binding.Range{0, 31}).
+		Action().LoadRegRange(int(marksReg), DispositionAudit, APDispositionMarkRange). // Logging
+		Action().SendToController(uint8(PacketInReasonNP)).
+		Action().LoadRegRange(int(marksReg), auditedMark, auditedMarkRange).
+		Action().ResubmitToTable(tableID).
+		Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+		Done()
+}
+
 func (c *client) Disconnect() error {
 	return c.bridge.Disconnect()
 }
@@ -1675,6 +1745,60 @@ func (c *client) serviceLBFlow(groupID binding.GroupIDType, svcIP net.IP, svcPor
 		Done()
 }
 
+// serviceMetricFlow generates the flow which counts the packets of the tracked
+// connections of a Service port. It has the same actions as serviceLBBypassFlow
+// and a higher priority, and the group ID of the Service is the object ID of its
+// cookie.
+func (c *client) serviceMetricFlow(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) binding.Flow {
+	return c.pipeline[conntrackStateTable].BuildFlow(priorityNormal+1).
+		MatchProtocol(protocol).
+		MatchCTMark(ServiceCTMark, nil).
+		MatchCTStateNew(false).MatchCTStateTrk(true).
+		MatchCTDstIP(svcIP).
+		MatchCTProtocol(protocol).
+		MatchCTDstPort(svcPort).
+		Action().LoadRegRange(int(marksReg), macRewriteMark, macRewriteMarkRange).
+		Action().GotoTable(EgressRuleTable).
+		Cookie(c.cookieAllocator.RequestWithObjectID(cookie.Service, uint32(groupID)).Raw()).
+		Done()
+}
+
+// endpointMetricFlow generates the flow which counts the packets sent to an
+// Endpoint by the tracked connections of a Service port. It has a higher priority
+// than serviceMetricFlow and the same actions, and the group ID of the Service is
+// the object ID of its cookie. The reply packets can't be matched, as they are
+// un-DNATed when they reach the table.
+func (c *client) endpointMetricFlow(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, endpointIP net.IP, endpointPort uint16) binding.Flow {
+	return c.pipeline[conntrackStateTable].BuildFlow(priorityNormal+2).
+		MatchProtocol(protocol).
+		MatchCTMark(ServiceCTMark, nil).
+		MatchCTStateNew(false).MatchCTStateTrk(true).MatchCTStateRpl(false).
+		MatchCTDstIP(svcIP).
+		MatchCTProtocol(protocol).
+		MatchCTDstPort(svcPort).
+		MatchDstIP(endpointIP).
+		MatchDstPort(endpointPort, nil).
+		Action().LoadRegRange(int(marksReg), macRewriteMark, macRewriteMarkRange).
+		Action().GotoTable(EgressRuleTable).
+		Cookie(c.cookieAllocator.RequestWithObjectID(cookie.Service, uint32(groupID)).Raw()).
+		Done()
+}
+
+// serviceNoEndpointFlow generates the flow which drops the new connections to
+// a Service port without Endpoint, and whose statistics count them. It has the
+// same match as serviceLBFlow, so they must not be installed at the same time,
+// and the group ID of the Service is the object ID of its cookie.
+func (c *client) serviceNoEndpointFlow(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) binding.Flow {
+	return c.pipeline[serviceLBTable].BuildFlow(priorityNormal).
+		MatchProtocol(protocol).
+		MatchDstPort(svcPort, nil).
+		MatchDstIP(svcIP).
+		MatchRegRange(int(serviceLearnReg), marksRegServiceNeedLB, serviceLearnRegRange).
+		Action().Drop().
+		Cookie(c.cookieAllocator.RequestWithObjectID(cookie.Service, uint32(groupID)).Raw()).
+		Done()
+}
+
 // endpointDNATFlow generates the flow which transforms the Service Cluster IP
 // to the Endpoint IP according to the Endpoint selection decision which is stored
 // in regs.
@@ -1727,11 +1851,92 @@ func (c *client) hairpinSNATFlow(endpointIP net.IP) binding.Flow {
 		Done()
 }
 
+// serviceHostFlows generates the flows for the Service traffic from the host
+// network when proxyAll is enabled. The host network routes the Service CIDR
+// to the host gateway, so that the traffic is load-balanced by AntreaProxy like
+// the traffic from the local Pods. When the selected Endpoint is reached
+// through the host gateway (e.g. a host-network Endpoint), the packets are
+// sent back to the host gateway with the virtual Service IP as the source IP,
+// as the host network would drop the packets with one of its own IPs as the
+// source IP. The SNAT is done in a dedicated conntrack zone, because the DNAT
+// of the connection has already been committed in the default zone. The
+// replies to the virtual Service IP are unSNAT'd in conntrackTable, before
+// they are unDNAT'd.
+func (c *client) serviceHostFlows(ipProtocol binding.Protocol) []binding.Flow {
+	virtualIP := config.VirtualServiceIPv4
+	snatZone := SNATCtZone
+	if ipProtocol == binding.ProtocolIPv6 {
+		virtualIP = config.VirtualServiceIPv6
+		snatZone = SNATCtZoneV6
+	}
+	connectionTrackTable := c.pipeline[conntrackTable]
+	connectionTrackCommitTable := c.pipeline[conntrackCommitTable]
+	hairpinTable := c.pipeline[hairpinSNATTable]
+	return []binding.Flow{
+		// UnSNAT the replies to the virtual Service IP, and then resubmit
+		// them to conntrackTable to unDNAT them.
+		connectionTrackTable.BuildFlow(priorityHigh).MatchProtocol(ipProtocol).
+			MatchRegRange(int(marksReg), markTrafficFromGateway, binding.Range{0, 15}).
+			MatchDstIP(virtualIP).
+			Action().CT(false, conntrackTable, snatZone).NAT().CTDone().
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			Done(),
+		// The first packet of a connection from the host gateway has been
+		// committed with ServiceCTMark in endpointDNATTable. Do not commit it
+		// again with gatewayCTMark, otherwise the following packets would
+		// bypass the Service flows.
+		connectionTrackCommitTable.BuildFlow(priorityHigh).MatchProtocol(ipProtocol).
+			MatchRegRange(int(marksReg), markTrafficFromGateway, binding.Range{0, 15}).
+			MatchCTStateNew(true).MatchCTStateTrk(true).
+			MatchCTMark(ServiceCTMark, nil).
+			Action().GotoTable(connectionTrackCommitTable.GetNext()).
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			Done(),
+		// Output the replies which are sent back to the host gateway
+		// with the in_port action.
+		hairpinTable.BuildFlow(priorityHigh).MatchProtocol(ipProtocol).
+			MatchRegRange(int(marksReg), markTrafficFromGateway, binding.Range{0, 15}).
+			MatchReg(int(PortCacheReg), config.HostGatewayOFPort).
+			MatchCTStateRpl(true).MatchCTStateTrk(true).
+			MatchCTMark(ServiceCTMark, nil).
+			Action().LoadRegRange(int(marksReg), hairpinMark, hairpinMarkRange).
+			Action().GotoTable(L2ForwardingOutTable).
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			Done(),
+		// Look up the SNAT zone for the requests which are sent back to
+		// the host gateway, and resubmit them to hairpinSNATTable with the
+		// hairpin mark.
+		hairpinTable.BuildFlow(priorityNormal).MatchProtocol(ipProtocol).
+			MatchRegRange(int(marksReg), markTrafficFromGateway, binding.Range{0, 15}).
+			MatchReg(int(PortCacheReg), config.HostGatewayOFPort).
+			MatchRegRange(int(marksReg), 0, hairpinMarkRange).
+			MatchCTMark(ServiceCTMark, nil).
+			Action().LoadRegRange(int(marksReg), hairpinMark, hairpinMarkRange).
+			Action().CT(false, hairpinSNATTable, snatZone).NAT().CTDone().
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			Done(),
+		// Commit the new connections with the virtual Service IP as the
+		// source IP in the SNAT zone. The packets of the established
+		// connections go to L2ForwardingOutTable with the table-miss flow.
+		hairpinTable.BuildFlow(priorityNormal).MatchProtocol(ipProtocol).
+			MatchRegRange(int(marksReg), markTrafficFromGateway, binding.Range{0, 15}).
+			MatchReg(int(PortCacheReg), config.HostGatewayOFPort).
+			MatchRegRange(int(marksReg), hairpinMark, hairpinMarkRange).
+			MatchCTStateNew(true).MatchCTStateTrk(true).
+			Action().CT(true, L2ForwardingOutTable, snatZone).
+			SNAT(&binding.IPRange{StartIP: virtualIP, EndIP: virtualIP}, nil).
+			CTDone().
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			Done(),
+	}
+}
+
 // serviceEndpointGroup creates/modifies the group/buckets of Endpoints. If the
 // withSessionAffinity is true, then buckets will resubmit packets back to
 // serviceLBTable to trigger the learn flow, the learn flow will then send packets
 // to endpointDNATTable. Otherwise, buckets will resubmit packets to
-// endpointDNATTable directly.
+// endpointDNATTable directly. The weight of each bucket is the weight of its
+// Endpoint.
 func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints ...proxy.Endpoint) binding.Group {
 	group := c.bridge.CreateGroup(groupID).ResetBuckets()
 	var resubmitTableID binding.TableIDType
@@ -1749,9 +1954,10 @@ func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAf
 		endpointIP := net.ParseIP(endpoint.IP())
 		portVal := uint16(endpointPort)
 		ipProtocol := getIPProtocol(endpointIP)
+		weight := proxytypes.GetEndpointWeight(endpoint)
 		if ipProtocol == binding.ProtocolIP {
 			ipVal := binary.BigEndian.Uint32(endpointIP.To4())
-			group = group.Bucket().Weight(100).
+			group = group.Bucket().Weight(weight).
 				LoadReg(int(endpointIPReg), ipVal).
 				LoadRegRange(int(endpointPortReg), uint32(portVal), endpointPortRegRange).
 				LoadRegRange(int(serviceLearnReg), lbResultMark, serviceLearnRegRange).
@@ -1760,7 +1966,7 @@ func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAf
 				Done()
 		} else if ipProtocol == binding.ProtocolIPv6 {
 			ipVal := []byte(endpointIP)
-			group = group.Bucket().Weight(100).
+			group = group.Bucket().Weight(weight).
 				LoadXXReg(int(endpointIPv6XXReg), ipVal).
 				LoadRegRange(int(endpointPortReg), uint32(portVal), endpointPortRegRange).
 				LoadRegRange(int(serviceLearnReg), lbResultMark, serviceLearnRegRange).
@@ -1863,11 +2069,15 @@ func (c *client) generatePipeline() {
 	if c.enableAntreaPolicy {
 		c.pipeline[AntreaPolicyEgressRuleTable] = bridge.CreateTable(AntreaPolicyEgressRuleTable, EgressRuleTable, binding.TableMissActionNext)
 		c.pipeline[AntreaPolicyIngressRuleTable] = bridge.CreateTable(AntreaPolicyIngressRuleTable, IngressRuleTable, binding.TableMissActionNext)
+		c.pipeline[EgressRateLimitTable] = bridge.CreateTable(EgressRateLimitTable, l3ForwardingTable, binding.TableMissActionNext)
+		c.pipeline[IngressRateLimitTable] = bridge.CreateTable(IngressRateLimitTable, IngressL7Table, binding.TableMissActionNext)
+		c.pipeline[IngressL7Table] = bridge.CreateTable(IngressL7Table, conntrackCommitTable, binding.TableMissActionNext)
+		c.pipeline[IngressL7InspectTable] = bridge.CreateTable(IngressL7InspectTable, conntrackCommitTable, binding.TableMissActionDrop)
 	}
 }
 
 // NewClient is the constructor of the Client interface.
-func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy bool) Client {
+func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy, proxyAll bool) Client {
 	bridge := binding.NewOFBridge(bridgeName, mgmtAddr)
 	policyCache := cache.NewIndexer(
 		policyConjKeyFunc,
@@ -1876,6 +2086,7 @@ func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy bool
 	c := &client{
 		bridge:                   bridge,
 		enableProxy:              enableProxy,
+		proxyAll:                 proxyAll,
 		enableAntreaPolicy:       enableAntreaPolicy,
 		nodeFlowCache:            newFlowCategoryCache(),
 		podFlowCache:             newFlowCategoryCache(),
@@ -1885,6 +2096,7 @@ func NewClient(bridgeName, mgmtAddr string, enableProxy, enableAntreaPolicy bool
 		globalConjMatchFlowCache: map[string]*conjMatchFlowContext{},
 		packetInHandlers:         map[uint8]map[string]PacketInHandler{},
 		ovsctlClient:             ovsctl.NewClient(bridgeName),
+		meterIDAllocator:         newMeterIDAllocator(),
 	}
 	c.ofEntryOperations = c
 	if enableAntreaPolicy {
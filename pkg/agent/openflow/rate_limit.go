@@ -0,0 +1,465 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+// The rate limit of an Antrea-native policy rule is enforced for each peer of the rule, i.e. each source address of an
+// ingress rule and each destination address of an egress rule, with up to two OVS meters installed in the
+// IngressRateLimitTable or EgressRateLimitTable which the metric flows of the rule goto:
+// - the connection meter limits the packets of new connections (ct_state=+new) with the peer, i.e. the connections
+//   per second;
+// - the traffic meter limits the packets or bytes per second of the other packets exchanged with the peer, in both
+//   directions. If the rule has no connection limit, it limits all the packets exchanged with the peer.
+// A rule without peers, which matches any peer, shares its meters between all the peers. The meters drop the packets
+// exceeding the rates, and their drop counts are reported as the RateLimitedPackets of the rule.
+// As the meters are allocated per peer, a rule can rate limit at most maxRateLimitPeersPerRule peers on a Node, and
+// all the rules share a budget of maxMeterID meters. A rule exceeding them fails to be installed.
+
+const (
+	// priorityRateLimitConnection is the priority of the flow applying the connection meter of a peer. It must be
+	// higher than priorityRateLimitTraffic so that the packets of new connections are only limited by the connection
+	// meter when the rule limits both.
+	priorityRateLimitConnection = priorityNormal
+	priorityRateLimitTraffic    = priorityNormal - 10
+
+	// maxRateLimitPeersPerRule is the maximum number of peers a rule can rate limit on a Node. An ipBlock peer counts
+	// as a single peer.
+	maxRateLimitPeersPerRule = 1024
+	// maxMeterID is the largest ID of the meters enforcing the rate limits, which bounds the number of meters
+	// installed by the agent well below the largest meter ID of OpenFlow (OFPM_MAX).
+	maxMeterID binding.MeterIDType = 65535
+)
+
+// meterIDAllocator allocates the IDs of the meters enforcing the rate limits. The released IDs are reused.
+type meterIDAllocator struct {
+	sync.Mutex
+	nextID   binding.MeterIDType
+	released []binding.MeterIDType
+}
+
+func newMeterIDAllocator() *meterIDAllocator {
+	return &meterIDAllocator{nextID: 1}
+}
+
+func (a *meterIDAllocator) allocate() (binding.MeterIDType, error) {
+	a.Lock()
+	defer a.Unlock()
+	if n := len(a.released); n > 0 {
+		id := a.released[n-1]
+		a.released = a.released[:n-1]
+		return id, nil
+	}
+	if a.nextID > maxMeterID {
+		return 0, fmt.Errorf("no meter ID available")
+	}
+	id := a.nextID
+	a.nextID++
+	return id, nil
+}
+
+func (a *meterIDAllocator) release(id binding.MeterIDType) {
+	a.Lock()
+	defer a.Unlock()
+	a.released = append(a.released, id)
+}
+
+// rateLimitPeer contains the meters enforcing the rate limit of a rule for one of its peers, and the flows applying
+// them.
+type rateLimitPeer struct {
+	meters []binding.Meter
+	flows  []binding.Flow
+}
+
+// rateLimitPeerType returns the type of the addresses of the peers of the policyRuleConjunction.
+func (c *policyRuleConjunction) rateLimitPeerType() types.AddressType {
+	if _, ok := egressTables[c.ruleTableID]; ok {
+		return types.DstAddress
+	}
+	return types.SrcAddress
+}
+
+// rateLimitPeerAddresses returns the addresses of the peers of the PolicyRule. It returns nil if the rule matches any
+// peer.
+func rateLimitPeerAddresses(conj *policyRuleConjunction, rule *types.PolicyRule) []types.Address {
+	if conj.rateLimitPeerType() == types.DstAddress {
+		return rule.To
+	}
+	return rule.From
+}
+
+// rateLimitTable returns the table where the meter flows of the policyRuleConjunction are installed, and the ct_label
+// value and range of the packets committed by the rule.
+func (c *policyRuleConjunction) rateLimitTable() (binding.TableIDType, uint64, binding.Range) {
+	if c.rateLimitPeerType() == types.DstAddress {
+		return EgressRateLimitTable, uint64(c.id) << 32, metricEgressRuleIDRange
+	}
+	return IngressRateLimitTable, uint64(c.id), metricIngressRuleIDRange
+}
+
+// rateLimitMeter returns a meter dropping the packets exceeding the given rate. A rate in bytes per second is
+// converted to kilobits per second, which is the unit of OVS meters.
+func (c *client) rateLimitMeter(id binding.MeterIDType, packetsPerSecond, bytesPerSecond int32) binding.Meter {
+	if packetsPerSecond > 0 {
+		return c.bridge.CreateMeter(id, binding.MeterPktps|binding.MeterStats).
+			MeterBand().MeterType(binding.MeterBandDrop).Rate(uint32(packetsPerSecond)).Done()
+	}
+	return c.bridge.CreateMeter(id, binding.MeterKbps|binding.MeterStats).
+		MeterBand().MeterType(binding.MeterBandDrop).Rate(meterRateKbps(bytesPerSecond)).Done()
+}
+
+// meterRateKbps converts a rate in bytes per second to the rate in kilobits per second of a meter. The result is at
+// least 1 and at most the largest rate of a meter band.
+func meterRateKbps(bytesPerSecond int32) uint32 {
+	kbps := uint64(bytesPerSecond) * 8 / 1000
+	if kbps == 0 {
+		return 1
+	}
+	if kbps > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(kbps)
+}
+
+// rateLimitFlows returns the flows applying the connection meter and the traffic meter of the peer to the packets
+// committed by the policyRuleConjunction. peer is nil if the rule matches any peer. A meter ID is 0 if the rule
+// doesn't limit the corresponding rate.
+func (c *client) rateLimitFlows(conj *policyRuleConjunction, peer types.Address, connectionMeterID, trafficMeterID binding.MeterIDType) []binding.Flow {
+	tableID, labelValue, labelRange := conj.rateLimitTable()
+	nextTableID := c.pipeline[tableID].GetNext()
+	flowCookie := c.cookieAllocator.Request(cookie.Policy).Raw()
+	peerType := conj.rateLimitPeerType()
+	replyPeerType := types.SrcAddress
+	if peerType == types.SrcAddress {
+		replyPeerType = types.DstAddress
+	}
+	// buildFlows returns the FlowBuilders matching the packets of the rule sent by or to the peer, according to
+	// peerType.
+	buildFlows := func(priority uint16, peerType types.AddressType) []binding.FlowBuilder {
+		var builders []binding.FlowBuilder
+		if peer == nil {
+			for _, proto := range c.ipProtocols {
+				builders = append(builders, c.pipeline[tableID].BuildFlow(priority).
+					MatchProtocol(proto).
+					MatchCTLabelRange(0, labelValue, labelRange))
+			}
+			return builders
+		}
+		fb := c.pipeline[tableID].BuildFlow(priority).MatchCTLabelRange(0, labelValue, labelRange)
+		return append(builders, c.addFlowMatch(fb, peer.GetMatchKey(peerType), peer.GetValue()))
+	}
+	var flows []binding.Flow
+	if connectionMeterID != 0 {
+		for _, fb := range buildFlows(priorityRateLimitConnection, peerType) {
+			flows = append(flows, fb.MatchCTStateNew(true).
+				Action().Meter(connectionMeterID).
+				Action().GotoTable(nextTableID).
+				Cookie(flowCookie).
+				Done())
+		}
+	}
+	if trafficMeterID != 0 {
+		if peer == nil {
+			for _, fb := range buildFlows(priorityRateLimitTraffic, peerType) {
+				flows = append(flows, fb.Action().Meter(trafficMeterID).
+					Action().GotoTable(nextTableID).
+					Cookie(flowCookie).
+					Done())
+			}
+		} else {
+			// The reply packets of the connections are matched with the peer address on the other side.
+			for _, fb := range buildFlows(priorityRateLimitTraffic, peerType) {
+				flows = append(flows, fb.MatchCTStateRpl(false).
+					Action().Meter(trafficMeterID).
+					Action().GotoTable(nextTableID).
+					Cookie(flowCookie).
+					Done())
+			}
+			for _, fb := range buildFlows(priorityRateLimitTraffic, replyPeerType) {
+				flows = append(flows, fb.MatchCTStateRpl(true).
+					Action().Meter(trafficMeterID).
+					Action().GotoTable(nextTableID).
+					Cookie(flowCookie).
+					Done())
+			}
+		}
+	}
+	return flows
+}
+
+// rateLimitPeerKey returns the key of the peer in policyRuleConjunction.rateLimitPeers.
+func rateLimitPeerKey(peer types.Address) string {
+	if peer == nil {
+		return ""
+	}
+	return peer.GetMatchValue()
+}
+
+// newRateLimitPeer allocates the meters enforcing the rate limit of the policyRuleConjunction for the peer, and
+// generates the flows applying them.
+func (c *client) newRateLimitPeer(conj *policyRuleConjunction, peer types.Address) (*rateLimitPeer, error) {
+	rlPeer := &rateLimitPeer{}
+	var connectionMeterID, trafficMeterID binding.MeterIDType
+	if conj.rateLimit.ConnectionsPerSecond > 0 {
+		id, err := c.meterIDAllocator.allocate()
+		if err != nil {
+			return nil, err
+		}
+		connectionMeterID = id
+		rlPeer.meters = append(rlPeer.meters, c.rateLimitMeter(id, conj.rateLimit.ConnectionsPerSecond, 0))
+	}
+	if conj.rateLimit.PacketsPerSecond > 0 || conj.rateLimit.BytesPerSecond > 0 {
+		id, err := c.meterIDAllocator.allocate()
+		if err != nil {
+			c.releaseRateLimitPeer(rlPeer)
+			return nil, err
+		}
+		trafficMeterID = id
+		rlPeer.meters = append(rlPeer.meters, c.rateLimitMeter(id, conj.rateLimit.PacketsPerSecond, conj.rateLimit.BytesPerSecond))
+	}
+	rlPeer.flows = c.rateLimitFlows(conj, peer, connectionMeterID, trafficMeterID)
+	return rlPeer, nil
+}
+
+// releaseRateLimitPeer releases the IDs of the meters of the rateLimitPeer.
+func (c *client) releaseRateLimitPeer(rlPeer *rateLimitPeer) {
+	for _, meter := range rlPeer.meters {
+		c.meterIDAllocator.release(meter.GetID())
+	}
+}
+
+// installRateLimitPeer installs the meters of the rateLimitPeer and the flows applying them. The meters and the
+// flows are sent to OVS directly, as OVS doesn't support meters in bundles.
+func installRateLimitPeer(rlPeer *rateLimitPeer) error {
+	for _, meter := range rlPeer.meters {
+		if err := meter.Add(); err != nil {
+			return fmt.Errorf("error when installing meter %s: %v", meter.KeyString(), err)
+		}
+	}
+	for _, flow := range rlPeer.flows {
+		if err := flow.Add(); err != nil {
+			return fmt.Errorf("error when installing rate limit flow %s: %v", flow.MatchString(), err)
+		}
+	}
+	return nil
+}
+
+// uninstallRateLimitPeer removes the flows applying the meters of the rateLimitPeer and the meters.
+func uninstallRateLimitPeer(rlPeer *rateLimitPeer) error {
+	for _, flow := range rlPeer.flows {
+		if err := flow.Delete(); err != nil {
+			return fmt.Errorf("error when deleting rate limit flow %s: %v", flow.MatchString(), err)
+		}
+	}
+	for _, meter := range rlPeer.meters {
+		if err := meter.Delete(); err != nil {
+			return fmt.Errorf("error when deleting meter %s: %v", meter.KeyString(), err)
+		}
+	}
+	return nil
+}
+
+// addRateLimitPeers installs the rate limits of the policyRuleConjunction for the peers. A nil peers means that the
+// rule matches any peer. It does nothing if the policyRuleConjunction has no rate limit. The caller must hold
+// conjMatchFlowLock.
+func (c *client) addRateLimitPeers(conj *policyRuleConjunction, peers []types.Address) error {
+	if conj.rateLimit == nil {
+		return nil
+	}
+	if conj.rateLimitPeers == nil {
+		conj.rateLimitPeers = map[string]*rateLimitPeer{}
+	}
+	newPeers := sets.NewString()
+	for _, peer := range peers {
+		if key := rateLimitPeerKey(peer); conj.rateLimitPeers[key] == nil {
+			newPeers.Insert(key)
+		}
+	}
+	if n := len(conj.rateLimitPeers) + newPeers.Len(); n > maxRateLimitPeersPerRule {
+		return fmt.Errorf("rule %d would rate limit %d peers, more than the maximum %d", conj.id, n, maxRateLimitPeersPerRule)
+	}
+	addPeer := func(peer types.Address) error {
+		key := rateLimitPeerKey(peer)
+		if _, exists := conj.rateLimitPeers[key]; exists {
+			return nil
+		}
+		rlPeer, err := c.newRateLimitPeer(conj, peer)
+		if err != nil {
+			return fmt.Errorf("error when allocating meters for rule %d: %v", conj.id, err)
+		}
+		if err := installRateLimitPeer(rlPeer); err != nil {
+			// The meters and flows which were installed must be removed before their IDs are reused.
+			if err := uninstallRateLimitPeer(rlPeer); err != nil {
+				klog.Errorf("Error when removing partially installed rate limit of rule %d: %v", conj.id, err)
+			}
+			c.releaseRateLimitPeer(rlPeer)
+			return fmt.Errorf("error when installing rate limit of rule %d: %v", conj.id, err)
+		}
+		conj.rateLimitPeers[key] = rlPeer
+		return nil
+	}
+	if peers == nil {
+		return addPeer(nil)
+	}
+	for _, peer := range peers {
+		if err := addPeer(peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRateLimitPeers uninstalls the rate limits of the policyRuleConjunction for the peers. The caller must hold
+// conjMatchFlowLock.
+func (c *client) deleteRateLimitPeers(conj *policyRuleConjunction, peers []types.Address) error {
+	for _, peer := range peers {
+		key := rateLimitPeerKey(peer)
+		rlPeer, exists := conj.rateLimitPeers[key]
+		if !exists {
+			continue
+		}
+		if err := uninstallRateLimitPeer(rlPeer); err != nil {
+			return fmt.Errorf("error when uninstalling rate limit of rule %d: %v", conj.id, err)
+		}
+		c.releaseRateLimitPeer(rlPeer)
+		delete(conj.rateLimitPeers, key)
+	}
+	return nil
+}
+
+// uninstallRateLimit uninstalls the rate limits of the policyRuleConjunction for all its peers. The caller must hold
+// conjMatchFlowLock.
+func (c *client) uninstallRateLimit(conj *policyRuleConjunction) error {
+	for key, rlPeer := range conj.rateLimitPeers {
+		if err := uninstallRateLimitPeer(rlPeer); err != nil {
+			return fmt.Errorf("error when uninstalling rate limit of rule %d: %v", conj.id, err)
+		}
+		c.releaseRateLimitPeer(rlPeer)
+		delete(conj.rateLimitPeers, key)
+	}
+	return nil
+}
+
+// rollbackRateLimit uninstalls the rate limits of a policyRuleConjunction which failed to be installed. As it is not
+// added into policyCache, its meters would otherwise never be deleted and their IDs never be released. The caller must
+// hold conjMatchFlowLock.
+func (c *client) rollbackRateLimit(conj *policyRuleConjunction) {
+	if err := c.uninstallRateLimit(conj); err != nil {
+		klog.Errorf("Error when rolling back rate limit of rule %d: %v", conj.id, err)
+	}
+}
+
+// deleteAllMeters deletes all the meters on the OVS bridge, which may be left by a previous run of the agent with
+// different meter IDs. OVS also deletes the flows applying the meters.
+func (c *client) deleteAllMeters() error {
+	return c.bridge.CreateMeter(binding.MeterIDAll, 0).Delete()
+}
+
+// replayRateLimits installs the meters and the meter flows of all the rate-limited policyRuleConjunctions again.
+func (c *client) replayRateLimits() {
+	for _, obj := range c.policyCache.List() {
+		conj := obj.(*policyRuleConjunction)
+		for _, rlPeer := range conj.rateLimitPeers {
+			for _, flow := range rlPeer.flows {
+				flow.Reset()
+			}
+			if err := installRateLimitPeer(rlPeer); err != nil {
+				klog.Errorf("Error when replaying rate limit of rule %d: %v", conj.id, err)
+			}
+		}
+	}
+}
+
+// collectRateLimitMetrics collects the numbers of packets dropped by the meters of the rate-limited rules.
+func (c *client) collectRateLimitMetrics(result map[uint32]*types.RuleMetric) {
+	c.conjMatchFlowLock.Lock()
+	meterRules := map[binding.MeterIDType]uint32{}
+	for _, obj := range c.policyCache.List() {
+		conj := obj.(*policyRuleConjunction)
+		for _, rlPeer := range conj.rateLimitPeers {
+			for _, meter := range rlPeer.meters {
+				meterRules[meter.GetID()] = conj.id
+			}
+		}
+	}
+	c.conjMatchFlowLock.Unlock()
+	if len(meterRules) == 0 {
+		return
+	}
+	out, err := c.ovsctlClient.RunOfctlCmd("meter-stats")
+	if err != nil {
+		klog.Errorf("Error when dumping meter stats: %v", err)
+		return
+	}
+	for meterID, dropped := range parseMeterStats(out) {
+		// The meters which don't belong to a rate-limited rule are ignored.
+		ruleID, ok := meterRules[binding.MeterIDType(meterID)]
+		if !ok {
+			continue
+		}
+		metric := types.RuleMetric{RateLimitedPackets: dropped}
+		if accMetric, ok := result[ruleID]; ok {
+			accMetric.Merge(&metric)
+		} else {
+			result[ruleID] = &metric
+		}
+	}
+}
+
+// parseMeterStats parses the output of "ovs-ofctl meter-stats" and returns the number of packets dropped by the
+// band of each meter. Example output:
+// OFPST_METER reply (OF1.3) (xid=0x2):
+// meter:10 flow_count:1 packet_in_count:25 byte_in_count:1850 duration:9.101s bands:
+// 0: packet_count:15 byte_count:1110
+func parseMeterStats(out []byte) map[uint32]uint64 {
+	result := map[uint32]uint64{}
+	var meterID uint32
+	meterFound := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "meter:") {
+			idStr := strings.TrimPrefix(strings.Fields(line)[0], "meter:")
+			id, err := strconv.ParseUint(idStr, 10, 32)
+			meterID, meterFound = uint32(id), err == nil
+			continue
+		}
+		if !meterFound || !strings.HasPrefix(line, "0:") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "packet_count:") {
+				count, _ := strconv.ParseUint(strings.TrimPrefix(field, "packet_count:"), 10, 64)
+				result[meterID] += count
+			}
+		}
+	}
+	return result
+}
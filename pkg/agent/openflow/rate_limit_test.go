@@ -0,0 +1,314 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	mocks "github.com/vmware-tanzu/antrea/pkg/ovs/openflow/testing"
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
+)
+
+func TestMeterIDAllocator(t *testing.T) {
+	a := newMeterIDAllocator()
+	id1, err := a.allocate()
+	require.NoError(t, err)
+	id2, err := a.allocate()
+	require.NoError(t, err)
+	assert.Equal(t, []binding.MeterIDType{1, 2}, []binding.MeterIDType{id1, id2})
+	// The released IDs are reused.
+	a.release(id1)
+	id3, err := a.allocate()
+	require.NoError(t, err)
+	assert.Equal(t, id1, id3)
+	// The IDs are bounded by the meter budget.
+	a.nextID = maxMeterID + 1
+	_, err = a.allocate()
+	assert.Error(t, err)
+}
+
+func TestMeterRateKbps(t *testing.T) {
+	assert.Equal(t, uint32(1), meterRateKbps(100))
+	assert.Equal(t, uint32(8000), meterRateKbps(1000000))
+	// The rates whose bit rate exceeds 32 bits are not truncated.
+	assert.Equal(t, uint32(4294967), meterRateKbps(536870911))
+	assert.Equal(t, uint32(4294967), meterRateKbps(536870912))
+	assert.Equal(t, uint32(17179869), meterRateKbps(math.MaxInt32))
+}
+
+func TestRateLimitFlows(t *testing.T) {
+	bridge := binding.NewOFBridge("br-int", "")
+	c := &client{
+		pipeline: map[binding.TableIDType]binding.Table{
+			EgressRateLimitTable:  bridge.CreateTable(EgressRateLimitTable, l3ForwardingTable, binding.TableMissActionNext),
+			IngressRateLimitTable: bridge.CreateTable(IngressRateLimitTable, IngressL7Table, binding.TableMissActionNext),
+		},
+		ipProtocols: []binding.Protocol{binding.ProtocolIP},
+	}
+	c.cookieAllocator = cookie.NewAllocator(0)
+	_, peerNet, _ := net.ParseCIDR("10.10.0.0/16")
+	tests := []struct {
+		name              string
+		conj              *policyRuleConjunction
+		peer              types.Address
+		connectionMeterID binding.MeterIDType
+		trafficMeterID    binding.MeterIDType
+		expectedFlows     []string
+	}{
+		{
+			name:              "ingress connections and packets from peer",
+			conj:              &policyRuleConjunction{id: 5, ruleTableID: AntreaPolicyIngressRuleTable},
+			peer:              NewIPAddress(net.ParseIP("10.10.0.1")),
+			connectionMeterID: 1,
+			trafficMeterID:    2,
+			expectedFlows: []string{
+				"table=102,ip,ct_label[0..31]=0x05,nw_src=10.10.0.1,ct_state=+new",
+				"table=102,ip,ct_label[0..31]=0x05,nw_src=10.10.0.1,ct_state=-rpl",
+				"table=102,ip,ct_label[0..31]=0x05,nw_dst=10.10.0.1,ct_state=+rpl",
+			},
+		},
+		{
+			name:              "egress connections to peer network",
+			conj:              &policyRuleConjunction{id: 5, ruleTableID: AntreaPolicyEgressRuleTable},
+			peer:              NewIPNetAddress(*peerNet),
+			connectionMeterID: 3,
+			expectedFlows: []string{
+				"table=62,ip,ct_label[32..63]=0x0500000000,nw_dst=10.10.0.0/16,ct_state=+new",
+			},
+		},
+		{
+			name:           "ingress bytes from any peer",
+			conj:           &policyRuleConjunction{id: 3, ruleTableID: AntreaPolicyIngressRuleTable},
+			trafficMeterID: 4,
+			expectedFlows: []string{
+				"table=102,ip,ct_label[0..31]=0x03",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matches []string
+			for _, flow := range c.rateLimitFlows(tt.conj, tt.peer, tt.connectionMeterID, tt.trafficMeterID) {
+				matches = append(matches, flow.MatchString())
+			}
+			assert.Equal(t, tt.expectedFlows, matches)
+		})
+	}
+}
+
+// prepareRateLimitClient returns a client whose meters and meter flows are mocked. The IDs of the created meters
+// are sent to meterIDs.
+func prepareRateLimitClient(ctrl *gomock.Controller, flow *mocks.MockFlow, meterIDs chan<- binding.MeterIDType) *client {
+	bridge := mocks.NewMockBridge(ctrl)
+	bridge.EXPECT().CreateMeter(gomock.Any(), gomock.Any()).DoAndReturn(func(id binding.MeterIDType, flags binding.MeterFlag) binding.Meter {
+		meter := mocks.NewMockMeter(ctrl)
+		bandBuilder := mocks.NewMockMeterBandBuilder(ctrl)
+		meter.EXPECT().MeterBand().Return(bandBuilder)
+		bandBuilder.EXPECT().MeterType(binding.MeterBandDrop).Return(bandBuilder)
+		bandBuilder.EXPECT().Rate(gomock.Any()).Return(bandBuilder)
+		bandBuilder.EXPECT().Done().Return(meter)
+		meter.EXPECT().GetID().Return(id).AnyTimes()
+		meter.EXPECT().KeyString().Return("").AnyTimes()
+		meter.EXPECT().Add().Return(nil)
+		meter.EXPECT().Delete().Return(nil)
+		meterIDs <- id
+		return meter
+	}).AnyTimes()
+	table := createMockTable(ctrl, IngressRateLimitTable, IngressL7Table, binding.TableMissActionNext)
+	builder := mocks.NewMockFlowBuilder(ctrl)
+	action := mocks.NewMockAction(ctrl)
+	table.EXPECT().BuildFlow(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().MatchCTLabelRange(gomock.Any(), gomock.Any(), gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().MatchProtocol(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().MatchSrcIP(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().MatchDstIP(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().MatchCTStateNew(true).Return(builder).AnyTimes()
+	builder.EXPECT().MatchCTStateRpl(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().Action().Return(action).AnyTimes()
+	action.EXPECT().Meter(gomock.Any()).Return(builder).AnyTimes()
+	action.EXPECT().GotoTable(IngressL7Table).Return(builder).AnyTimes()
+	builder.EXPECT().Cookie(gomock.Any()).Return(builder).AnyTimes()
+	builder.EXPECT().Done().Return(flow).AnyTimes()
+	c := &client{
+		bridge:           bridge,
+		pipeline:         map[binding.TableIDType]binding.Table{IngressRateLimitTable: table},
+		ipProtocols:      []binding.Protocol{binding.ProtocolIP},
+		meterIDAllocator: newMeterIDAllocator(),
+	}
+	c.cookieAllocator = cookie.NewAllocator(0)
+	return c
+}
+
+func TestAddAndDeleteRateLimitPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flow := mocks.NewMockFlow(ctrl)
+	flow.EXPECT().MatchString().Return("").AnyTimes()
+	meterIDs := make(chan binding.MeterIDType, 10)
+	c := prepareRateLimitClient(ctrl, flow, meterIDs)
+	conj := &policyRuleConjunction{id: 5, ruleTableID: AntreaPolicyIngressRuleTable, rateLimit: &v1beta2.RateLimit{ConnectionsPerSecond: 100, PacketsPerSecond: 1000}}
+	peer1 := NewIPAddress(net.ParseIP("10.10.0.1"))
+	peer2 := NewIPAddress(net.ParseIP("10.10.0.2"))
+
+	// Each peer has a connection meter and a traffic meter, applied by 1 flow for new connections and 2 flows for the
+	// packets in both directions.
+	flow.EXPECT().Add().Return(nil).Times(6)
+	require.NoError(t, c.addRateLimitPeers(conj, []types.Address{peer1, peer2}))
+	assert.Equal(t, []binding.MeterIDType{1, 2, 3, 4}, []binding.MeterIDType{<-meterIDs, <-meterIDs, <-meterIDs, <-meterIDs})
+	assert.Len(t, conj.rateLimitPeers, 2)
+	// Adding an existing peer does nothing.
+	require.NoError(t, c.addRateLimitPeers(conj, []types.Address{peer1}))
+
+	flow.EXPECT().Delete().Return(nil).Times(3)
+	require.NoError(t, c.deleteRateLimitPeers(conj, []types.Address{peer1}))
+	assert.Len(t, conj.rateLimitPeers, 1)
+	assert.Contains(t, conj.rateLimitPeers, peer2.GetMatchValue())
+
+	// The meter IDs of the deleted peer are reused.
+	peer3 := NewIPAddress(net.ParseIP("10.10.0.3"))
+	flow.EXPECT().Add().Return(nil).Times(3)
+	require.NoError(t, c.addRateLimitPeers(conj, []types.Address{peer3}))
+	assert.ElementsMatch(t, []binding.MeterIDType{1, 2}, []binding.MeterIDType{<-meterIDs, <-meterIDs})
+
+	flow.EXPECT().Delete().Return(nil).Times(6)
+	require.NoError(t, c.uninstallRateLimit(conj))
+	assert.Empty(t, conj.rateLimitPeers)
+
+	// A rule without peers shares its meters between all the peers.
+	conj = &policyRuleConjunction{id: 6, ruleTableID: AntreaPolicyIngressRuleTable, rateLimit: &v1beta2.RateLimit{BytesPerSecond: 125000}}
+	flow.EXPECT().Add().Return(nil).Times(1)
+	require.NoError(t, c.addRateLimitPeers(conj, nil))
+	assert.Contains(t, conj.rateLimitPeers, "")
+	<-meterIDs
+	flow.EXPECT().Delete().Return(nil).Times(1)
+	require.NoError(t, c.uninstallRateLimit(conj))
+
+	// Nothing is done for rules without rate limit.
+	conj = &policyRuleConjunction{id: 7}
+	require.NoError(t, c.addRateLimitPeers(conj, []types.Address{peer1}))
+	assert.Empty(t, conj.rateLimitPeers)
+}
+
+func TestAddRateLimitPeersExceedingMaximum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flow := mocks.NewMockFlow(ctrl)
+	meterIDs := make(chan binding.MeterIDType, maxRateLimitPeersPerRule)
+	c := prepareRateLimitClient(ctrl, flow, meterIDs)
+	// A rule cannot rate limit more peers than the maximum, and no meter is allocated for it.
+	conj := &policyRuleConjunction{id: 8, ruleTableID: AntreaPolicyIngressRuleTable, rateLimit: &v1beta2.RateLimit{ConnectionsPerSecond: 100}}
+	peers := make([]types.Address, maxRateLimitPeersPerRule+1)
+	for i := range peers {
+		peers[i] = NewIPAddress(net.IPv4(10, 20, byte(i>>8), byte(i)))
+	}
+	assert.Error(t, c.addRateLimitPeers(conj, peers))
+	assert.Empty(t, conj.rateLimitPeers)
+	// The peers which are already rate limited are not counted twice.
+	flow.EXPECT().Add().Return(nil).Times(maxRateLimitPeersPerRule)
+	require.NoError(t, c.addRateLimitPeers(conj, peers[:maxRateLimitPeersPerRule]))
+	require.NoError(t, c.addRateLimitPeers(conj, peers[:maxRateLimitPeersPerRule]))
+	assert.Error(t, c.addRateLimitPeers(conj, peers[maxRateLimitPeersPerRule:]))
+	assert.Len(t, conj.rateLimitPeers, maxRateLimitPeersPerRule)
+
+	flow.EXPECT().Delete().Return(nil).Times(maxRateLimitPeersPerRule)
+	require.NoError(t, c.uninstallRateLimit(conj))
+}
+
+func TestRollbackRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flow := mocks.NewMockFlow(ctrl)
+	flow.EXPECT().MatchString().Return("").AnyTimes()
+	meterIDs := make(chan binding.MeterIDType, 10)
+	c := prepareRateLimitClient(ctrl, flow, meterIDs)
+	conj := &policyRuleConjunction{id: 5, ruleTableID: AntreaPolicyIngressRuleTable, rateLimit: &v1beta2.RateLimit{ConnectionsPerSecond: 100}}
+	peer1 := NewIPAddress(net.ParseIP("10.10.0.1"))
+	peer2 := NewIPAddress(net.ParseIP("10.10.0.2"))
+
+	flow.EXPECT().Add().Return(nil).Times(2)
+	require.NoError(t, c.addRateLimitPeers(conj, []types.Address{peer1, peer2}))
+	assert.ElementsMatch(t, []binding.MeterIDType{1, 2}, []binding.MeterIDType{<-meterIDs, <-meterIDs})
+
+	// The meters of a rule which failed to be installed are deleted and their IDs are released.
+	flow.EXPECT().Delete().Return(nil).Times(2)
+	c.rollbackRateLimit(conj)
+	assert.Empty(t, conj.rateLimitPeers)
+	id, err := c.meterIDAllocator.allocate()
+	require.NoError(t, err)
+	assert.Contains(t, []binding.MeterIDType{1, 2}, id)
+}
+
+func TestNetworkPolicyMetricsWithRateLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c = prepareClient(ctrl)
+	mockOVSClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	c.ovsctlClient = mockOVSClient
+	newMeter := func(id binding.MeterIDType) binding.Meter {
+		meter := mocks.NewMockMeter(ctrl)
+		meter.EXPECT().GetID().Return(id).AnyTimes()
+		return meter
+	}
+	c.policyCache.Add(&policyRuleConjunction{
+		id:          5,
+		ruleTableID: AntreaPolicyEgressRuleTable,
+		rateLimit:   &v1beta2.RateLimit{ConnectionsPerSecond: 10, PacketsPerSecond: 100},
+		rateLimitPeers: map[string]*rateLimitPeer{
+			"10.10.0.1": {meters: []binding.Meter{newMeter(3), newMeter(4)}},
+			"10.10.0.2": {meters: []binding.Meter{newMeter(7), newMeter(8)}},
+		},
+	})
+	egressFlows := []string{
+		"table=61, n_packets=30, n_bytes=2220, priority=200,ct_state=+new,ct_label=0x500000000/0xffffffff00000000,ip actions=goto_table:62",
+		"table=61, n_packets=70, n_bytes=5180, priority=200,ct_state=-new,ct_label=0x500000000/0xffffffff00000000,ip actions=goto_table:62",
+	}
+	meterStats := `OFPST_METER reply (OF1.3) (xid=0x2):
+meter:3 flow_count:1 packet_in_count:30 byte_in_count:2220 duration:9.101s bands:
+0: packet_count:20 byte_count:1480
+
+meter:4 flow_count:2 packet_in_count:70 byte_in_count:5180 duration:9.101s bands:
+0: packet_count:3 byte_count:222
+
+meter:8 flow_count:2 packet_in_count:10 byte_in_count:740 duration:9.101s bands:
+0: packet_count:1 byte_count:74
+
+meter:20 flow_count:0 packet_in_count:0 byte_in_count:0 duration:100.000s bands:
+0: packet_count:8 byte_count:592
+`
+	gomock.InOrder(
+		mockOVSClient.EXPECT().DumpTableFlows(uint8(EgressMetricTable)).Return(egressFlows, nil),
+		mockOVSClient.EXPECT().DumpTableFlows(uint8(IngressMetricTable)).Return(nil, nil),
+		mockOVSClient.EXPECT().RunOfctlCmd("meter-stats").Return([]byte(meterStats), nil),
+	)
+	// The meter 20 doesn't belong to a rate-limited rule and is ignored.
+	want := map[uint32]*types.RuleMetric{
+		5: {Bytes: 7400, Sessions: 30, Packets: 100, RateLimitedPackets: 24},
+	}
+	assert.Equal(t, want, c.NetworkPolicyMetrics())
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+)
+
+// serviceGroupBuckets stores the Endpoints of a Service group in the order of its buckets.
+type serviceGroupBuckets struct {
+	isIPv6    bool
+	endpoints []string
+}
+
+func (c *client) ServiceMetrics(isIPv6 bool) map[binding.GroupIDType]*types.ServiceMetric {
+	result := map[binding.GroupIDType]*types.ServiceMetric{}
+	getMetric := func(groupID binding.GroupIDType) *types.ServiceMetric {
+		metric, ok := result[groupID]
+		if !ok {
+			metric = &types.ServiceMetric{Endpoints: map[string]*types.EndpointMetric{}}
+			result[groupID] = metric
+		}
+		return metric
+	}
+	getEndpointMetric := func(metric *types.ServiceMetric, endpoint string) *types.EndpointMetric {
+		endpointMetric, ok := metric.Endpoints[endpoint]
+		if !ok {
+			endpointMetric = &types.EndpointMetric{}
+			metric.Endpoints[endpoint] = endpointMetric
+		}
+		return endpointMetric
+	}
+
+	// The first packet of each connection is load-balanced by the group of the Service, so the statistics of the
+	// group and of its buckets give the numbers of connections to the Service and to each Endpoint, and count the
+	// first packets of the connections.
+	out, err := c.ovsctlClient.RunOfctlCmd("dump-group-stats")
+	if err != nil {
+		klog.Errorf("Error when dumping group stats: %v", err)
+	} else {
+		for groupID, stats := range parseGroupStats(out) {
+			obj, ok := c.serviceGroupEndpoints.Load(groupID)
+			if !ok {
+				continue
+			}
+			buckets := obj.(*serviceGroupBuckets)
+			if buckets.isIPv6 != isIPv6 {
+				continue
+			}
+			metric := getMetric(groupID)
+			metric.Connections += stats.packets
+			metric.Packets += stats.packets
+			metric.Bytes += stats.bytes
+			for i, bucket := range stats.buckets {
+				if i < len(buckets.endpoints) {
+					endpointMetric := getEndpointMetric(metric, buckets.endpoints[i])
+					endpointMetric.Connections += bucket.packets
+					endpointMetric.Packets += bucket.packets
+					endpointMetric.Bytes += bucket.bytes
+				}
+			}
+		}
+	}
+
+	// The subsequent packets of the connections are counted by the flows generated by serviceMetricFlow, except the
+	// packets sent to the Endpoints, which are counted by the flows generated by endpointMetricFlow.
+	for _, flow := range c.dumpServiceFlows(conntrackStateTable, isIPv6) {
+		groupID, flowMap := flow.groupID, flow.flowMap
+		metric := getMetric(groupID)
+		packets, bytes := parseFlowCounter(flowMap, "n_packets"), parseFlowCounter(flowMap, "n_bytes")
+		metric.Packets += packets
+		metric.Bytes += bytes
+		if flowMap["priority"] != strconv.Itoa(int(priorityNormal+2)) {
+			continue
+		}
+		endpoint, ok := parseEndpointMetricFlow(flowMap, isIPv6)
+		if !ok {
+			continue
+		}
+		endpointMetric := getEndpointMetric(metric, endpoint)
+		endpointMetric.Packets += packets
+		endpointMetric.Bytes += bytes
+	}
+	// The packets to the Services without Endpoint are counted by the flows generated by serviceNoEndpointFlow.
+	// The flows generated by serviceLearnFlow share the same cookie and are ignored.
+	for _, flow := range c.dumpServiceFlows(serviceLBTable, isIPv6) {
+		if flow.flowMap["priority"] != strconv.Itoa(int(priorityNormal)) {
+			continue
+		}
+		metric := getMetric(flow.groupID)
+		metric.NoEndpointPackets += parseFlowCounter(flow.flowMap, "n_packets")
+	}
+	return result
+}
+
+type serviceFlow struct {
+	groupID binding.GroupIDType
+	flowMap map[string]string
+}
+
+// dumpServiceFlows returns the flows of the table whose cookie belongs to the Service category and carries the
+// group ID of a Service. Only the flows of the given IP family are returned.
+func (c *client) dumpServiceFlows(tableID binding.TableIDType, isIPv6 bool) []serviceFlow {
+	out, err := c.ovsctlClient.RunOfctlCmd("dump-flows", fmt.Sprintf("table=%d", tableID))
+	if err != nil {
+		klog.Errorf("Error when dumping flows of table %d: %v", tableID, err)
+		return nil
+	}
+	var flows []serviceFlow
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "cookie=") {
+			continue
+		}
+		if strings.Contains(line, "ipv6") != isIPv6 {
+			continue
+		}
+		flowMap := parseFlowToMap(line)
+		cookieVal, err := strconv.ParseUint(flowMap["cookie"], 0, 64)
+		if err != nil {
+			klog.Errorf("Failed to parse cookie of flow %s: %v", line, err)
+			continue
+		}
+		cookieID := cookie.ID(cookieVal)
+		if cookieID.Category() != cookie.Service || cookieID.ObjectID() == 0 {
+			continue
+		}
+		flows = append(flows, serviceFlow{groupID: binding.GroupIDType(cookieID.ObjectID()), flowMap: flowMap})
+	}
+	return flows
+}
+
+// parseEndpointMetricFlow returns the Endpoint string (IP:port) matched by a flow generated by endpointMetricFlow.
+func parseEndpointMetricFlow(flowMap map[string]string, isIPv6 bool) (string, bool) {
+	ipKey := "nw_dst"
+	if isIPv6 {
+		ipKey = "ipv6_dst"
+	}
+	// The value of the last match field is followed by the actions of the flow.
+	fieldValue := func(key string) string {
+		fields := strings.Fields(flowMap[key])
+		if len(fields) == 0 {
+			return ""
+		}
+		return fields[0]
+	}
+	ip, port := fieldValue(ipKey), fieldValue("tp_dst")
+	if ip == "" || port == "" {
+		klog.Errorf("Failed to parse the Endpoint of flow %v", flowMap)
+		return "", false
+	}
+	return net.JoinHostPort(ip, port), true
+}
+
+func parseFlowCounter(flowMap map[string]string, key string) uint64 {
+	val, err := strconv.ParseUint(flowMap[key], 10, 64)
+	if err != nil {
+		klog.Errorf("Failed to parse %s %s: %v", key, flowMap[key], err)
+		return 0
+	}
+	return val
+}
+
+type groupStats struct {
+	packets uint64
+	bytes   uint64
+	buckets []bucketStats
+}
+
+type bucketStats struct {
+	packets uint64
+	bytes   uint64
+}
+
+// parseGroupStats parses the output of "ovs-ofctl dump-group-stats" and returns the statistics of each group and of
+// its buckets. Example output:
+// OFPST_GROUP reply (OF1.3) (xid=0x2):
+// group_id=1,duration=10.503s,ref_count=1,packet_count=5,byte_count=370,bucket0:packet_count=3,byte_count=222,bucket1:packet_count=2,byte_count=148
+func parseGroupStats(out []byte) map[binding.GroupIDType]*groupStats {
+	result := map[binding.GroupIDType]*groupStats{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "group_id=") {
+			continue
+		}
+		var groupID uint64
+		stats := &groupStats{}
+		valid := true
+		// The statistics of the buckets follow the ones of the group and only their packet counts are prefixed with
+		// the bucket index, their byte counts follow them.
+		inBuckets := false
+		for _, seg := range strings.Split(line, ",") {
+			kv := strings.SplitN(seg, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			key, val := kv[0], kv[1]
+			var err error
+			switch {
+			case key == "group_id":
+				groupID, err = strconv.ParseUint(val, 10, 32)
+			case key == "packet_count" && !inBuckets:
+				stats.packets, err = strconv.ParseUint(val, 10, 64)
+			case key == "byte_count" && !inBuckets:
+				stats.bytes, err = strconv.ParseUint(val, 10, 64)
+			case strings.HasPrefix(key, "bucket") && strings.HasSuffix(key, ":packet_count"):
+				inBuckets = true
+				var packets uint64
+				packets, err = strconv.ParseUint(val, 10, 64)
+				stats.buckets = append(stats.buckets, bucketStats{packets: packets})
+			case key == "byte_count" && inBuckets:
+				stats.buckets[len(stats.buckets)-1].bytes, err = strconv.ParseUint(val, 10, 64)
+			}
+			if err != nil {
+				klog.Errorf("Failed to parse group stats %s: %v", line, err)
+				valid = false
+				break
+			}
+		}
+		if valid {
+			result[binding.GroupIDType(groupID)] = stats
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow/cookie"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	ovsctltest "github.com/vmware-tanzu/antrea/pkg/ovs/ovsctl/testing"
+)
+
+func TestParseGroupStats(t *testing.T) {
+	out := `OFPST_GROUP reply (OF1.3) (xid=0x2):
+ group_id=1,duration=10.503s,ref_count=1,packet_count=5,byte_count=370,bucket0:packet_count=3,byte_count=222,bucket1:packet_count=2,byte_count=148
+ group_id=2,duration=3.100s,ref_count=1,packet_count=0,byte_count=0,bucket0:packet_count=0,byte_count=0
+ group_id=3,duration=3.100s,ref_count=1,packet_count=invalid,byte_count=0
+`
+	expected := map[binding.GroupIDType]*groupStats{
+		1: {packets: 5, bytes: 370, buckets: []bucketStats{{packets: 3, bytes: 222}, {packets: 2, bytes: 148}}},
+		2: {packets: 0, bytes: 0, buckets: []bucketStats{{packets: 0, bytes: 0}}},
+	}
+	assert.Equal(t, expected, parseGroupStats([]byte(out)))
+}
+
+func TestServiceMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, true, false, false)
+	client := ofClient.(*client)
+	client.cookieAllocator = cookie.NewAllocator(0)
+	mockOVSClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	client.ovsctlClient = mockOVSClient
+	client.serviceGroupEndpoints.Store(binding.GroupIDType(1), &serviceGroupBuckets{endpoints: []string{"10.10.0.10:80", "10.10.0.11:80"}})
+	client.serviceGroupEndpoints.Store(binding.GroupIDType(2), &serviceGroupBuckets{isIPv6: true, endpoints: []string{"[fd74:ca9b:172:18::a]:80"}})
+
+	cookieFor := func(objectID uint32) uint64 {
+		return client.cookieAllocator.RequestWithObjectID(cookie.Service, objectID).Raw()
+	}
+	groupStats := `OFPST_GROUP reply (OF1.3) (xid=0x2):
+ group_id=1,duration=10.503s,ref_count=1,packet_count=5,byte_count=370,bucket0:packet_count=3,byte_count=222,bucket1:packet_count=2,byte_count=148
+ group_id=2,duration=10.503s,ref_count=1,packet_count=4,byte_count=300,bucket0:packet_count=4,byte_count=300
+ group_id=3,duration=10.503s,ref_count=1,packet_count=1,byte_count=60,bucket0:packet_count=1,byte_count=60
+`
+	ctStateFlows := fmt.Sprintf(`NXST_FLOW reply (xid=0x4):
+ cookie=%#x, duration=10.5s, table=31, n_packets=6, n_bytes=600, priority=202,ct_state=-new-rpl+trk,ct_mark=0x21,tcp,nw_dst=10.10.0.10,ct_nw_dst=10.96.0.10,ct_nw_proto=6,ct_tp_dst=80,tp_dst=80 actions=load:0x1->NXM_NX_REG0[19],goto_table:70
+ cookie=%#x, duration=10.5s, table=31, n_packets=20, n_bytes=1480, priority=201,ct_state=-new+trk,ct_mark=0x21,tcp,ct_nw_dst=10.96.0.10,ct_nw_proto=6,ct_tp_dst=80 actions=load:0x1->NXM_NX_REG0[19],goto_table:70
+ cookie=%#x, duration=10.5s, table=31, n_packets=8, n_bytes=800, priority=201,ct_state=-new+trk,ct_mark=0x21,tcp6,ct_ipv6_dst=fd00::a,ct_nw_proto=6,ct_tp_dst=80 actions=load:0x1->NXM_NX_REG0[19],goto_table:70
+ cookie=%#x, duration=10.5s, table=31, n_packets=100, n_bytes=7400, priority=200,ct_state=-new+trk,ct_mark=0x21,ip actions=load:0x1->NXM_NX_REG0[19],goto_table:70
+`, cookieFor(1), cookieFor(1), cookieFor(2), cookieFor(0))
+	lbFlows := fmt.Sprintf(`NXST_FLOW reply (xid=0x4):
+ cookie=%#x, duration=10.5s, table=41, n_packets=7, n_bytes=518, priority=200,tcp,reg4=0x10000/0x70000,nw_dst=10.96.0.20,tp_dst=80 actions=drop
+ cookie=%#x, duration=10.5s, table=41, n_packets=3, n_bytes=222, priority=190,tcp,reg4=0x30000/0x70000,nw_dst=10.96.0.10,tp_dst=80 actions=learn(table=40,hard_timeout=300)
+ cookie=%#x, duration=10.5s, table=41, n_packets=5, n_bytes=370, priority=200,tcp,reg4=0x10000/0x70000,nw_dst=10.96.0.10,tp_dst=80 actions=group:1
+`, cookieFor(4), cookieFor(1), cookieFor(0))
+	mockOVSClient.EXPECT().RunOfctlCmd("dump-group-stats").Return([]byte(groupStats), nil)
+	mockOVSClient.EXPECT().RunOfctlCmd("dump-flows", "table=31").Return([]byte(ctStateFlows), nil)
+	mockOVSClient.EXPECT().RunOfctlCmd("dump-flows", "table=41").Return([]byte(lbFlows), nil)
+
+	expected := map[binding.GroupIDType]*types.ServiceMetric{
+		1: {
+			Connections: 5,
+			Packets:     31,
+			Bytes:       2450,
+			Endpoints: map[string]*types.EndpointMetric{
+				"10.10.0.10:80": {Connections: 3, Packets: 9, Bytes: 822},
+				"10.10.0.11:80": {Connections: 2, Packets: 2, Bytes: 148},
+			},
+		},
+		4: {
+			NoEndpointPackets: 7,
+			Endpoints:         map[string]*types.EndpointMetric{},
+		},
+	}
+	assert.Equal(t, expected, ofClient.ServiceMetrics(false))
+}
@@ -24,6 +24,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	config "github.com/vmware-tanzu/antrea/pkg/agent/config"
 	types "github.com/vmware-tanzu/antrea/pkg/agent/types"
+	v1beta2 "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	openflow "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	proxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 	net "net"
@@ -109,6 +110,20 @@ func (mr *MockClientMockRecorder) DeleteStaleFlows() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleFlows", reflect.TypeOf((*MockClient)(nil).DeleteStaleFlows))
 }
 
+// DenyL7Connection mocks base method
+func (m *MockClient) DenyL7Connection(arg0, arg1 net.IP, arg2, arg3 uint16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenyL7Connection", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DenyL7Connection indicates an expected call of DenyL7Connection
+func (mr *MockClientMockRecorder) DenyL7Connection(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenyL7Connection", reflect.TypeOf((*MockClient)(nil).DenyL7Connection), arg0, arg1, arg2, arg3)
+}
+
 // Disconnect mocks base method
 func (m *MockClient) Disconnect() error {
 	m.ctrl.T.Helper()
@@ -123,6 +138,20 @@ func (mr *MockClientMockRecorder) Disconnect() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockClient)(nil).Disconnect))
 }
 
+// DrainEndpointFlows mocks base method
+func (m *MockClient) DrainEndpointFlows(arg0 openflow.Protocol, arg1 proxy.Endpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DrainEndpointFlows", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DrainEndpointFlows indicates an expected call of DrainEndpointFlows
+func (mr *MockClientMockRecorder) DrainEndpointFlows(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrainEndpointFlows", reflect.TypeOf((*MockClient)(nil).DrainEndpointFlows), arg0, arg1)
+}
+
 // GetFlowTableStatus mocks base method
 func (m *MockClient) GetFlowTableStatus() []openflow.TableStatus {
 	m.ctrl.T.Helper()
@@ -137,6 +166,20 @@ func (mr *MockClientMockRecorder) GetFlowTableStatus() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlowTableStatus", reflect.TypeOf((*MockClient)(nil).GetFlowTableStatus))
 }
 
+// GetL7ProtocolsFromConjunction mocks base method
+func (m *MockClient) GetL7ProtocolsFromConjunction(arg0 uint32) []v1beta2.L7Protocol {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetL7ProtocolsFromConjunction", arg0)
+	ret0, _ := ret[0].([]v1beta2.L7Protocol)
+	return ret0
+}
+
+// GetL7ProtocolsFromConjunction indicates an expected call of GetL7ProtocolsFromConjunction
+func (mr *MockClientMockRecorder) GetL7ProtocolsFromConjunction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetL7ProtocolsFromConjunction", reflect.TypeOf((*MockClient)(nil).GetL7ProtocolsFromConjunction), arg0)
+}
+
 // GetNetworkPolicyFlowKeys mocks base method
 func (m *MockClient) GetNetworkPolicyFlowKeys(arg0, arg1 string) []string {
 	m.ctrl.T.Helper()
@@ -209,7 +252,7 @@ func (mr *MockClientMockRecorder) InitialTLVMap() *gomock.Call {
 }
 
 // Initialize mocks base method
-func (m *MockClient) Initialize(arg0 types.RoundInfo, arg1 *config.NodeConfig, arg2 config.TrafficEncapModeType) (<-chan struct{}, error) {
+func (m *MockClient) Initialize(arg0 types.RoundInfo, arg1 *config.NodeConfig, arg2 *config.NetworkConfig) (<-chan struct{}, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Initialize", arg0, arg1, arg2)
 	ret0, _ := ret[0].(<-chan struct{})
@@ -405,6 +448,34 @@ func (mr *MockClientMockRecorder) InstallServiceGroup(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceGroup", reflect.TypeOf((*MockClient)(nil).InstallServiceGroup), arg0, arg1, arg2)
 }
 
+// InstallServiceMetricFlows mocks base method
+func (m *MockClient) InstallServiceMetricFlows(arg0 openflow.GroupIDType, arg1 net.IP, arg2 uint16, arg3 openflow.Protocol, arg4 []proxy.Endpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallServiceMetricFlows", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallServiceMetricFlows indicates an expected call of InstallServiceMetricFlows
+func (mr *MockClientMockRecorder) InstallServiceMetricFlows(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceMetricFlows", reflect.TypeOf((*MockClient)(nil).InstallServiceMetricFlows), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InstallServiceNoEndpointFlows mocks base method
+func (m *MockClient) InstallServiceNoEndpointFlows(arg0 openflow.GroupIDType, arg1 net.IP, arg2 uint16, arg3 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallServiceNoEndpointFlows", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallServiceNoEndpointFlows indicates an expected call of InstallServiceNoEndpointFlows
+func (mr *MockClientMockRecorder) InstallServiceNoEndpointFlows(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceNoEndpointFlows", reflect.TypeOf((*MockClient)(nil).InstallServiceNoEndpointFlows), arg0, arg1, arg2, arg3)
+}
+
 // InstallTraceflowFlows mocks base method
 func (m *MockClient) InstallTraceflowFlows(arg0 byte) error {
 	m.ctrl.T.Helper()
@@ -489,6 +560,18 @@ func (mr *MockClientMockRecorder) ReassignFlowPriorities(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignFlowPriorities", reflect.TypeOf((*MockClient)(nil).ReassignFlowPriorities), arg0, arg1)
 }
 
+// RegisterL7PacketInHandler mocks base method
+func (m *MockClient) RegisterL7PacketInHandler(arg0 interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterL7PacketInHandler", arg0)
+}
+
+// RegisterL7PacketInHandler indicates an expected call of RegisterL7PacketInHandler
+func (mr *MockClientMockRecorder) RegisterL7PacketInHandler(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterL7PacketInHandler", reflect.TypeOf((*MockClient)(nil).RegisterL7PacketInHandler), arg0)
+}
+
 // RegisterPacketInHandler mocks base method
 func (m *MockClient) RegisterPacketInHandler(arg0 byte, arg1 string, arg2 interface{}) {
 	m.ctrl.T.Helper()
@@ -513,6 +596,20 @@ func (mr *MockClientMockRecorder) ReplayFlows() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayFlows", reflect.TypeOf((*MockClient)(nil).ReplayFlows))
 }
 
+// ResumeL7Packet mocks base method
+func (m *MockClient) ResumeL7Packet(arg0 *ofctrl.PacketIn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeL7Packet", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeL7Packet indicates an expected call of ResumeL7Packet
+func (mr *MockClientMockRecorder) ResumeL7Packet(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeL7Packet", reflect.TypeOf((*MockClient)(nil).ResumeL7Packet), arg0)
+}
+
 // SendTraceflowPacket mocks base method
 func (m *MockClient) SendTraceflowPacket(arg0 byte, arg1, arg2, arg3, arg4 string, arg5, arg6 byte, arg7, arg8, arg9 uint16, arg10 byte, arg11, arg12 uint16, arg13, arg14 byte, arg15, arg16 uint16, arg17 uint32, arg18 int32) error {
 	m.ctrl.T.Helper()
@@ -527,6 +624,20 @@ func (mr *MockClientMockRecorder) SendTraceflowPacket(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTraceflowPacket", reflect.TypeOf((*MockClient)(nil).SendTraceflowPacket), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13, arg14, arg15, arg16, arg17, arg18)
 }
 
+// ServiceMetrics mocks base method
+func (m *MockClient) ServiceMetrics(arg0 bool) map[openflow.GroupIDType]*types.ServiceMetric {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServiceMetrics", arg0)
+	ret0, _ := ret[0].(map[openflow.GroupIDType]*types.ServiceMetric)
+	return ret0
+}
+
+// ServiceMetrics indicates an expected call of ServiceMetrics
+func (mr *MockClientMockRecorder) ServiceMetrics(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceMetrics", reflect.TypeOf((*MockClient)(nil).ServiceMetrics), arg0)
+}
+
 // StartPacketInHandler mocks base method
 func (m *MockClient) StartPacketInHandler(arg0 []byte, arg1 <-chan struct{}) {
 	m.ctrl.T.Helper()
@@ -652,6 +763,34 @@ func (mr *MockClientMockRecorder) UninstallServiceGroup(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallServiceGroup", reflect.TypeOf((*MockClient)(nil).UninstallServiceGroup), arg0)
 }
 
+// UninstallServiceMetricFlows mocks base method
+func (m *MockClient) UninstallServiceMetricFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallServiceMetricFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallServiceMetricFlows indicates an expected call of UninstallServiceMetricFlows
+func (mr *MockClientMockRecorder) UninstallServiceMetricFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallServiceMetricFlows", reflect.TypeOf((*MockClient)(nil).UninstallServiceMetricFlows), arg0, arg1, arg2)
+}
+
+// UninstallServiceNoEndpointFlows mocks base method
+func (m *MockClient) UninstallServiceNoEndpointFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallServiceNoEndpointFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallServiceNoEndpointFlows indicates an expected call of UninstallServiceNoEndpointFlows
+func (mr *MockClientMockRecorder) UninstallServiceNoEndpointFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallServiceNoEndpointFlows", reflect.TypeOf((*MockClient)(nil).UninstallServiceNoEndpointFlows), arg0, arg1, arg2)
+}
+
 // MockOFEntryOperations is a mock of OFEntryOperations interface
 type MockOFEntryOperations struct {
 	ctrl     *gomock.Controller
@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 
@@ -35,7 +36,8 @@ type endpointsChange struct {
 	current  types.EndpointsMap
 }
 
-// endpointsChangesTracker tracks Endpoints changes.
+// endpointsChangesTracker tracks Endpoints changes, either from the Endpoints
+// or from the EndpointSlices of the Services.
 type endpointsChangesTracker struct {
 	// hostname is used to tell whether the Endpoint is located on current Node.
 	hostname string
@@ -45,13 +47,20 @@ type endpointsChangesTracker struct {
 	initialized bool
 	// changes contains endpoints changes since the last checkoutChanges call.
 	changes map[apimachinerytypes.NamespacedName]*endpointsChange
+	// endpointSliceCache caches the EndpointSlices of the Services. It's nil
+	// when Endpoints are used instead of EndpointSlices.
+	endpointSliceCache *endpointSliceCache
 }
 
-func newEndpointsChangesTracker(hostname string) *endpointsChangesTracker {
-	return &endpointsChangesTracker{
+func newEndpointsChangesTracker(hostname string, endpointSliceEnabled bool) *endpointsChangesTracker {
+	t := &endpointsChangesTracker{
 		hostname: hostname,
 		changes:  map[apimachinerytypes.NamespacedName]*endpointsChange{},
 	}
+	if endpointSliceEnabled {
+		t.endpointSliceCache = newEndpointSliceCache(hostname)
+	}
+	return t
 }
 
 // OnEndpointUpdate updates given Service's Endpoints change map based on the
@@ -95,6 +104,18 @@ func (t *endpointsChangesTracker) OnEndpointUpdate(previous, current *corev1.End
 	return len(t.changes) > 0
 }
 
+// OnEndpointSliceUpdate updates the EndpointSlice cache with the given
+// EndpointSlice. It returns true if the Endpoints of the Service owning the
+// EndpointSlice changed, otherwise it returns false. removeSlice is true when
+// the EndpointSlice is deleted.
+func (t *endpointsChangesTracker) OnEndpointSliceUpdate(endpointSlice *discovery.EndpointSlice, removeSlice bool) bool {
+	// endpointSlice == nil is unexpected, we should return false directly.
+	if endpointSlice == nil || t.endpointSliceCache == nil {
+		return false
+	}
+	return t.endpointSliceCache.updatePending(endpointSlice, removeSlice)
+}
+
 func (t *endpointsChangesTracker) checkoutChanges() []*endpointsChange {
 	t.Lock()
 	defer t.Unlock()
@@ -104,6 +125,9 @@ func (t *endpointsChangesTracker) checkoutChanges() []*endpointsChange {
 		changes = append(changes, change)
 	}
 	t.changes = make(map[apimachinerytypes.NamespacedName]*endpointsChange)
+	if t.endpointSliceCache != nil {
+		changes = append(changes, t.endpointSliceCache.checkoutChanges()...)
+	}
 	return changes
 }
 
@@ -0,0 +1,252 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+// endpointSliceCache caches the EndpointSlices of each Service, so that the
+// Endpoints of a Service can be computed from all its EndpointSlices when one
+// of them changes. An EndpointSlice event only stores the changed EndpointSlice
+// as pending, and the changes of a Service are merged until the next
+// checkoutChanges call, which rebuilds the previous and the current Endpoints
+// of each changed Service from all its EndpointSlices. The cost of a sync is
+// therefore proportional to the size of the changed Services, not to the size
+// of the changed EndpointSlices.
+type endpointSliceCache struct {
+	// hostname is used to tell whether the Endpoint is located on current Node.
+	hostname string
+
+	mutex sync.Mutex
+	// trackerByService tracks the EndpointSlices of each Service.
+	trackerByService map[apimachinerytypes.NamespacedName]*endpointSliceTracker
+}
+
+// endpointSliceTracker tracks the EndpointSlices of a Service. applied contains
+// the EndpointSlices which are reflected in the EndpointsMap of the proxier,
+// pending contains the EndpointSlices which changed since the last
+// checkoutChanges call.
+type endpointSliceTracker struct {
+	applied map[string]*endpointSliceInfo
+	pending map[string]*endpointSliceInfo
+}
+
+// endpointSliceInfo contains the information of an EndpointSlice which is
+// relevant to the proxier. The ports and the endpoints are sorted, so that
+// endpointSliceInfos can be compared with reflect.DeepEqual.
+type endpointSliceInfo struct {
+	ports     []discovery.EndpointPort
+	endpoints []*endpointInfo
+	remove    bool
+}
+
+type endpointInfo struct {
+	address  string
+	topology map[string]string
+}
+
+func newEndpointSliceCache(hostname string) *endpointSliceCache {
+	return &endpointSliceCache{
+		hostname:         hostname,
+		trackerByService: map[apimachinerytypes.NamespacedName]*endpointSliceTracker{},
+	}
+}
+
+func newEndpointSliceInfo(endpointSlice *discovery.EndpointSlice, remove bool) *endpointSliceInfo {
+	info := &endpointSliceInfo{
+		ports:  make([]discovery.EndpointPort, len(endpointSlice.Ports)),
+		remove: remove,
+	}
+	copy(info.ports, endpointSlice.Ports)
+	sort.Slice(info.ports, func(i, j int) bool {
+		return portName(info.ports[i]) < portName(info.ports[j])
+	})
+	if remove {
+		return info
+	}
+	for _, endpoint := range endpointSlice.Endpoints {
+		// Endpoints which are not ready must not receive traffic. A nil Ready
+		// condition must be interpreted as ready.
+		if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+			continue
+		}
+		if len(endpoint.Addresses) == 0 {
+			klog.Warningf("Ignoring endpoint with empty addresses in EndpointSlice %s/%s", endpointSlice.Namespace, endpointSlice.Name)
+			continue
+		}
+		// The addresses of an endpoint are fungible, only the first one is
+		// used.
+		info.endpoints = append(info.endpoints, &endpointInfo{
+			address:  endpoint.Addresses[0],
+			topology: endpoint.Topology,
+		})
+	}
+	sort.Slice(info.endpoints, func(i, j int) bool {
+		return info.endpoints[i].address < info.endpoints[j].address
+	})
+	return info
+}
+
+func portName(port discovery.EndpointPort) string {
+	if port.Name == nil {
+		return ""
+	}
+	return *port.Name
+}
+
+// endpointSliceCacheKeys returns the NamespacedName of the Service owning the
+// EndpointSlice, and the name of the EndpointSlice.
+func endpointSliceCacheKeys(endpointSlice *discovery.EndpointSlice) (apimachinerytypes.NamespacedName, string, error) {
+	var err error
+	serviceName, ok := endpointSlice.Labels[discovery.LabelServiceName]
+	if !ok || serviceName == "" {
+		err = fmt.Errorf("no %s label set on EndpointSlice %s/%s", discovery.LabelServiceName, endpointSlice.Namespace, endpointSlice.Name)
+	} else if endpointSlice.Namespace == "" || endpointSlice.Name == "" {
+		err = fmt.Errorf("expected EndpointSlice name and namespace to be set: %v", endpointSlice)
+	}
+	return apimachinerytypes.NamespacedName{Namespace: endpointSlice.Namespace, Name: serviceName}, endpointSlice.Name, err
+}
+
+// updatePending records the given EndpointSlice as pending, and returns true if
+// it changes the Endpoints of its Service. remove is true when the
+// EndpointSlice is deleted.
+func (c *endpointSliceCache) updatePending(endpointSlice *discovery.EndpointSlice, remove bool) bool {
+	serviceKey, sliceKey, err := endpointSliceCacheKeys(endpointSlice)
+	if err != nil {
+		klog.Warningf("Error getting EndpointSlice cache keys: %v", err)
+		return false
+	}
+	info := newEndpointSliceInfo(endpointSlice, remove)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tracker, ok := c.trackerByService[serviceKey]
+	if !ok {
+		tracker = &endpointSliceTracker{
+			applied: map[string]*endpointSliceInfo{},
+			pending: map[string]*endpointSliceInfo{},
+		}
+		c.trackerByService[serviceKey] = tracker
+	}
+	if !tracker.changed(sliceKey, info) {
+		return false
+	}
+	tracker.pending[sliceKey] = info
+	return true
+}
+
+// changed returns whether the given endpointSliceInfo is different from the
+// pending one, or from the applied one if there is no pending one.
+func (t *endpointSliceTracker) changed(sliceKey string, info *endpointSliceInfo) bool {
+	if pending, ok := t.pending[sliceKey]; ok {
+		return !reflect.DeepEqual(info, pending)
+	}
+	if applied, ok := t.applied[sliceKey]; ok {
+		return !reflect.DeepEqual(info, applied)
+	}
+	// An EndpointSlice which is removed before being applied has no effect.
+	return !info.remove
+}
+
+// checkoutChanges applies the pending EndpointSlices, and returns the changes
+// of the Endpoints of the Services they belong to.
+func (c *endpointSliceCache) checkoutChanges() []*endpointsChange {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var changes []*endpointsChange
+	for serviceKey, tracker := range c.trackerByService {
+		if len(tracker.pending) == 0 {
+			continue
+		}
+		change := &endpointsChange{previous: c.endpointsMap(serviceKey, tracker.applied)}
+		for sliceKey, info := range tracker.pending {
+			if info.remove {
+				delete(tracker.applied, sliceKey)
+			} else {
+				tracker.applied[sliceKey] = info
+			}
+			delete(tracker.pending, sliceKey)
+		}
+		change.current = c.endpointsMap(serviceKey, tracker.applied)
+		if len(tracker.applied) == 0 {
+			delete(c.trackerByService, serviceKey)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// endpointsMap builds the EndpointsMap of a Service from its EndpointSlices.
+// The endpoints are deduplicated by IP, as the same endpoint may be present in
+// several EndpointSlices while it is moved from one to another.
+func (c *endpointSliceCache) endpointsMap(serviceKey apimachinerytypes.NamespacedName, infos map[string]*endpointSliceInfo) types.EndpointsMap {
+	endpointsMap := types.EndpointsMap{}
+	for _, info := range infos {
+		for _, port := range info.ports {
+			if port.Name == nil {
+				klog.Warningf("Ignoring port with nil name in EndpointSlices of Service %s", serviceKey)
+				continue
+			}
+			if port.Port == nil || *port.Port == 0 {
+				klog.Warningf("Ignoring invalid endpoint port %s of Service %s", *port.Name, serviceKey)
+				continue
+			}
+			protocol := corev1.ProtocolTCP
+			if port.Protocol != nil {
+				protocol = *port.Protocol
+			}
+			svcPortName := k8sproxy.ServicePortName{
+				NamespacedName: serviceKey,
+				Port:           *port.Name,
+				Protocol:       protocol,
+			}
+			endpoints, ok := endpointsMap[svcPortName]
+			if !ok {
+				endpoints = map[string]k8sproxy.Endpoint{}
+				endpointsMap[svcPortName] = endpoints
+			}
+			for _, endpoint := range info.endpoints {
+				isLocal := endpoint.topology[corev1.LabelHostname] == c.hostname
+				ei := types.NewEndpointInfo(&k8sproxy.BaseEndpointInfo{
+					Endpoint: net.JoinHostPort(endpoint.address, strconv.Itoa(int(*port.Port))),
+					IsLocal:  isLocal,
+					Topology: endpoint.topology,
+				})
+				// isLocal should not vary between the duplicates of an
+				// endpoint, but if it does, the local one is preferred.
+				if _, exists := endpoints[ei.String()]; !exists || isLocal {
+					endpoints[ei.String()] = ei
+				}
+			}
+		}
+	}
+	return endpointsMap
+}
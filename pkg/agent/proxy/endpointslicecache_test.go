@@ -0,0 +1,108 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+func newEndpointSlice(name string, ready *bool, addresses ...string) *discovery.EndpointSlice {
+	portName := "http"
+	port := int32(80)
+	protocol := corev1.ProtocolTCP
+	slice := makeTestEndpointSlice("ns1", "svc1", name, func(slice *discovery.EndpointSlice) {
+		slice.AddressType = discovery.AddressTypeIPv4
+		slice.Ports = []discovery.EndpointPort{{Name: &portName, Port: &port, Protocol: &protocol}}
+	})
+	for _, address := range addresses {
+		slice.Endpoints = append(slice.Endpoints, discovery.Endpoint{
+			Addresses:  []string{address},
+			Conditions: discovery.EndpointConditions{Ready: ready},
+			Topology:   map[string]string{corev1.LabelHostname: "node1"},
+		})
+	}
+	return slice
+}
+
+func endpointStrings(endpoints map[string]k8sproxy.Endpoint) []string {
+	var result []string
+	for endpoint := range endpoints {
+		result = append(result, endpoint)
+	}
+	return result
+}
+
+func TestEndpointSliceCache(t *testing.T) {
+	notReady := false
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: apimachinerytypes.NamespacedName{Namespace: "ns1", Name: "svc1"},
+		Port:           "http",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	cache := newEndpointSliceCache("node1")
+
+	// The endpoints of all the EndpointSlices are merged, and the duplicate
+	// endpoint is only kept once.
+	assert.True(t, cache.updatePending(newEndpointSlice("slice1", nil, "10.0.0.1", "10.0.0.2"), false))
+	assert.True(t, cache.updatePending(newEndpointSlice("slice2", nil, "10.0.0.2", "10.0.0.3"), false))
+	changes := cache.checkoutChanges()
+	assert.Len(t, changes, 1)
+	assert.Empty(t, changes[0].previous)
+	assert.ElementsMatch(t, []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"}, endpointStrings(changes[0].current[svcPortName]))
+	assert.True(t, changes[0].current[svcPortName]["10.0.0.1:80"].GetIsLocal())
+
+	// An update which doesn't change the EndpointSlice is ignored.
+	assert.False(t, cache.updatePending(newEndpointSlice("slice1", nil, "10.0.0.2", "10.0.0.1"), false))
+	assert.Empty(t, cache.checkoutChanges())
+
+	// The endpoints which are not ready are excluded.
+	assert.True(t, cache.updatePending(newEndpointSlice("slice2", &notReady, "10.0.0.2", "10.0.0.3"), false))
+	changes = cache.checkoutChanges()
+	assert.Len(t, changes, 1)
+	assert.ElementsMatch(t, []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"}, endpointStrings(changes[0].previous[svcPortName]))
+	assert.ElementsMatch(t, []string{"10.0.0.1:80", "10.0.0.2:80"}, endpointStrings(changes[0].current[svcPortName]))
+
+	// Removing all the EndpointSlices removes the endpoints of the Service.
+	assert.True(t, cache.updatePending(newEndpointSlice("slice1", nil), true))
+	assert.True(t, cache.updatePending(newEndpointSlice("slice2", nil), true))
+	changes = cache.checkoutChanges()
+	assert.Len(t, changes, 1)
+	assert.Empty(t, changes[0].current)
+	assert.Empty(t, cache.trackerByService)
+
+	// Removing an EndpointSlice which was never applied has no effect.
+	assert.True(t, cache.updatePending(newEndpointSlice("slice3", nil, "10.0.0.4"), false))
+	assert.True(t, cache.updatePending(newEndpointSlice("slice3", nil), true))
+	assert.False(t, cache.updatePending(newEndpointSlice("slice4", nil), true))
+}
+
+func TestEndpointSliceCacheKeys(t *testing.T) {
+	slice := newEndpointSlice("slice1", nil, "10.0.0.1")
+	serviceKey, sliceKey, err := endpointSliceCacheKeys(slice)
+	assert.NoError(t, err)
+	assert.Equal(t, apimachinerytypes.NamespacedName{Namespace: "ns1", Name: "svc1"}, serviceKey)
+	assert.Equal(t, "slice1", sliceKey)
+
+	delete(slice.Labels, discovery.LabelServiceName)
+	_, _, err = endpointSliceCacheKeys(slice)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"time"
+
+	k8smetrics "k8s.io/component-base/metrics"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+// serviceMetricsInterval is the interval at which the metrics of the Services
+// are collected from OVS.
+const serviceMetricsInterval = 30 * time.Second
+
+// updateServiceGroups updates the map from the group IDs to the installed
+// services, which is read by collectServiceMetrics.
+func (p *proxier) updateServiceGroups() {
+	serviceGroups := make(map[binding.GroupIDType]k8sproxy.ServicePortName, len(p.serviceInstalledMap)+len(p.serviceNoEndpointMap))
+	for _, m := range []k8sproxy.ServiceMap{p.serviceInstalledMap, p.serviceNoEndpointMap} {
+		for svcPortName := range m {
+			groupID, _ := p.groupCounter.Get(svcPortName)
+			serviceGroups[groupID] = svcPortName
+		}
+	}
+	p.serviceGroupsMutex.Lock()
+	defer p.serviceGroupsMutex.Unlock()
+	p.serviceGroups = serviceGroups
+}
+
+// counterDelta returns the increase of an OVS counter since its last value.
+// The counters are reset when the groups and flows are re-installed, in which
+// case the current value is the increase.
+func counterDelta(current, last uint64) float64 {
+	if current < last {
+		return float64(current)
+	}
+	return float64(current - last)
+}
+
+// addCounter adds the increase to the counter with the labels. The counters are
+// only created once they are non-zero, so that no series is exported for the
+// Services and the Endpoints without traffic.
+func addCounter(counter *k8smetrics.CounterVec, labels map[string]string, delta float64) {
+	if delta > 0 {
+		counter.With(labels).Add(delta)
+	}
+}
+
+func serviceMetricLabels(svcPortName k8sproxy.ServicePortName) map[string]string {
+	return map[string]string{
+		"service":  svcPortName.NamespacedName.String(),
+		"port":     svcPortName.Port,
+		"protocol": string(svcPortName.Protocol),
+	}
+}
+
+func endpointMetricLabels(svcPortName k8sproxy.ServicePortName, endpoint string) map[string]string {
+	labels := serviceMetricLabels(svcPortName)
+	labels["endpoint"] = endpoint
+	return labels
+}
+
+func deleteServiceMetrics(svcPortName k8sproxy.ServicePortName, last *agenttypes.ServiceMetric) {
+	labels := serviceMetricLabels(svcPortName)
+	metrics.ProxyServiceConnectionCount.Delete(labels)
+	metrics.ProxyServicePacketCount.Delete(labels)
+	metrics.ProxyServiceByteCount.Delete(labels)
+	metrics.ProxyServiceNoEndpointPacketCount.Delete(labels)
+	for endpoint := range last.Endpoints {
+		deleteEndpointMetrics(svcPortName, endpoint)
+	}
+}
+
+func deleteEndpointMetrics(svcPortName k8sproxy.ServicePortName, endpoint string) {
+	labels := endpointMetricLabels(svcPortName, endpoint)
+	metrics.ProxyEndpointConnectionCount.Delete(labels)
+	metrics.ProxyEndpointPacketCount.Delete(labels)
+	metrics.ProxyEndpointByteCount.Delete(labels)
+}
+
+// collectServiceMetrics collects the statistics of the groups and flows of the
+// installed services from OVS, and adds their increases since the last
+// collection to the Prometheus counters.
+func (p *proxier) collectServiceMetrics() {
+	p.serviceGroupsMutex.Lock()
+	serviceGroups := p.serviceGroups
+	p.serviceGroupsMutex.Unlock()
+
+	serviceMetrics := make(map[k8sproxy.ServicePortName]*agenttypes.ServiceMetric, len(serviceGroups))
+	for groupID, metric := range p.ofClient.ServiceMetrics(p.isIPv6) {
+		if svcPortName, ok := serviceGroups[groupID]; ok {
+			serviceMetrics[svcPortName] = metric
+		}
+	}
+	for _, svcPortName := range serviceGroups {
+		last, ok := p.serviceMetrics[svcPortName]
+		if !ok {
+			last = &agenttypes.ServiceMetric{}
+		}
+		metric, ok := serviceMetrics[svcPortName]
+		if !ok {
+			// The statistics of the service could not be retrieved, its last
+			// metrics are kept.
+			serviceMetrics[svcPortName] = last
+			continue
+		}
+		labels := serviceMetricLabels(svcPortName)
+		addCounter(metrics.ProxyServiceConnectionCount, labels, counterDelta(metric.Connections, last.Connections))
+		addCounter(metrics.ProxyServicePacketCount, labels, counterDelta(metric.Packets, last.Packets))
+		addCounter(metrics.ProxyServiceByteCount, labels, counterDelta(metric.Bytes, last.Bytes))
+		addCounter(metrics.ProxyServiceNoEndpointPacketCount, labels, counterDelta(metric.NoEndpointPackets, last.NoEndpointPackets))
+		for endpoint, endpointMetric := range metric.Endpoints {
+			lastEndpoint, ok := last.Endpoints[endpoint]
+			if !ok {
+				lastEndpoint = &agenttypes.EndpointMetric{}
+			}
+			endpointLabels := endpointMetricLabels(svcPortName, endpoint)
+			addCounter(metrics.ProxyEndpointConnectionCount, endpointLabels, counterDelta(endpointMetric.Connections, lastEndpoint.Connections))
+			addCounter(metrics.ProxyEndpointPacketCount, endpointLabels, counterDelta(endpointMetric.Packets, lastEndpoint.Packets))
+			addCounter(metrics.ProxyEndpointByteCount, endpointLabels, counterDelta(endpointMetric.Bytes, lastEndpoint.Bytes))
+		}
+		for endpoint := range last.Endpoints {
+			if _, ok := metric.Endpoints[endpoint]; !ok {
+				deleteEndpointMetrics(svcPortName, endpoint)
+			}
+		}
+	}
+	for svcPortName, last := range p.serviceMetrics {
+		if _, ok := serviceMetrics[svcPortName]; !ok {
+			deleteServiceMetrics(svcPortName, last)
+		}
+	}
+	p.serviceMetrics = serviceMetrics
+}
@@ -0,0 +1,220 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/metrics"
+	ofmock "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
+	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
+	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
+)
+
+const (
+	serviceConnectionCountHeader = `
+	# HELP antrea_agent_proxy_service_connection_count [ALPHA] Number of new connections load-balanced by AntreaProxy to the Endpoints of a Service port.
+	# TYPE antrea_agent_proxy_service_connection_count counter
+	`
+	servicePacketCountHeader = `
+	# HELP antrea_agent_proxy_service_packet_count [ALPHA] Number of packets of the connections load-balanced by AntreaProxy to the Endpoints of a Service port.
+	# TYPE antrea_agent_proxy_service_packet_count counter
+	`
+	endpointConnectionCountHeader = `
+	# HELP antrea_agent_proxy_endpoint_connection_count [ALPHA] Number of new connections load-balanced by AntreaProxy to an Endpoint of a Service port.
+	# TYPE antrea_agent_proxy_endpoint_connection_count counter
+	`
+	endpointPacketCountHeader = `
+	# HELP antrea_agent_proxy_endpoint_packet_count [ALPHA] Number of packets sent to an Endpoint of a Service port by the connections load-balanced to it by AntreaProxy.
+	# TYPE antrea_agent_proxy_endpoint_packet_count counter
+	`
+)
+
+func checkProxyMetrics(t *testing.T, expected string, names ...string) {
+	err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(expected), names...)
+	assert.NoError(t, err)
+}
+
+func TestCollectServiceMetrics(t *testing.T) {
+	metrics.InitializeProxyMetrics()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, false)
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	fp.serviceGroups = map[binding.GroupIDType]k8sproxy.ServicePortName{1: svcPortName}
+
+	mockOFClient.EXPECT().ServiceMetrics(false).Return(map[binding.GroupIDType]*agenttypes.ServiceMetric{
+		1: {
+			Connections: 5,
+			Packets:     25,
+			Bytes:       1850,
+			Endpoints: map[string]*agenttypes.EndpointMetric{
+				"10.180.0.1:80": {Connections: 3, Packets: 9, Bytes: 822},
+				"10.180.0.2:80": {Connections: 2, Packets: 2, Bytes: 148},
+				"10.180.0.3:80": {},
+			},
+		},
+		// The metrics of the groups which don't belong to an installed
+		// Service are ignored.
+		2: {Connections: 1},
+	})
+	fp.collectServiceMetrics()
+	checkProxyMetrics(t, serviceConnectionCountHeader+`antrea_agent_proxy_service_connection_count{port="80",protocol="TCP",service="ns1/svc1"} 5
+`, "antrea_agent_proxy_service_connection_count")
+	checkProxyMetrics(t, endpointConnectionCountHeader+`antrea_agent_proxy_endpoint_connection_count{endpoint="10.180.0.1:80",port="80",protocol="TCP",service="ns1/svc1"} 3
+antrea_agent_proxy_endpoint_connection_count{endpoint="10.180.0.2:80",port="80",protocol="TCP",service="ns1/svc1"} 2
+`, "antrea_agent_proxy_endpoint_connection_count")
+	checkProxyMetrics(t, endpointPacketCountHeader+`antrea_agent_proxy_endpoint_packet_count{endpoint="10.180.0.1:80",port="80",protocol="TCP",service="ns1/svc1"} 9
+antrea_agent_proxy_endpoint_packet_count{endpoint="10.180.0.2:80",port="80",protocol="TCP",service="ns1/svc1"} 2
+`, "antrea_agent_proxy_endpoint_packet_count")
+	// No series is created for the counters which are still zero.
+	checkProxyMetrics(t, "", "antrea_agent_proxy_service_no_endpoint_packet_count")
+
+	// The increases of the OVS counters are added to the metrics, and the
+	// counters which decreased have been reset.
+	mockOFClient.EXPECT().ServiceMetrics(false).Return(map[binding.GroupIDType]*agenttypes.ServiceMetric{
+		1: {
+			Connections: 2,
+			Packets:     30,
+			Bytes:       2000,
+			Endpoints: map[string]*agenttypes.EndpointMetric{
+				"10.180.0.1:80": {Connections: 2, Packets: 12, Bytes: 1000},
+			},
+		},
+	})
+	fp.collectServiceMetrics()
+	checkProxyMetrics(t, serviceConnectionCountHeader+`antrea_agent_proxy_service_connection_count{port="80",protocol="TCP",service="ns1/svc1"} 7
+`, "antrea_agent_proxy_service_connection_count")
+	checkProxyMetrics(t, servicePacketCountHeader+`antrea_agent_proxy_service_packet_count{port="80",protocol="TCP",service="ns1/svc1"} 30
+`, "antrea_agent_proxy_service_packet_count")
+	checkProxyMetrics(t, endpointConnectionCountHeader+`antrea_agent_proxy_endpoint_connection_count{endpoint="10.180.0.1:80",port="80",protocol="TCP",service="ns1/svc1"} 5
+`, "antrea_agent_proxy_endpoint_connection_count")
+	checkProxyMetrics(t, endpointPacketCountHeader+`antrea_agent_proxy_endpoint_packet_count{endpoint="10.180.0.1:80",port="80",protocol="TCP",service="ns1/svc1"} 12
+`, "antrea_agent_proxy_endpoint_packet_count")
+
+	// The metrics are kept when the statistics can't be retrieved.
+	mockOFClient.EXPECT().ServiceMetrics(false).Return(map[binding.GroupIDType]*agenttypes.ServiceMetric{})
+	fp.collectServiceMetrics()
+	checkProxyMetrics(t, serviceConnectionCountHeader+`antrea_agent_proxy_service_connection_count{port="80",protocol="TCP",service="ns1/svc1"} 7
+`, "antrea_agent_proxy_service_connection_count")
+
+	// The metrics of the removed Service are deleted.
+	fp.serviceGroups = map[binding.GroupIDType]k8sproxy.ServicePortName{}
+	mockOFClient.EXPECT().ServiceMetrics(false).Return(map[binding.GroupIDType]*agenttypes.ServiceMetric{})
+	fp.collectServiceMetrics()
+	checkProxyMetrics(t, "", "antrea_agent_proxy_service_connection_count", "antrea_agent_proxy_endpoint_connection_count", "antrea_agent_proxy_endpoint_packet_count")
+}
+
+func testServiceMetricFlows(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, isIPv6)
+	fp.metricsEnabled = true
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Spec.ClusterIP = svcIP.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+
+	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{
+				IP: epIP.String(),
+			}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	})
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	makeEndpointsMap(fp, ep)
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	gomock.InOrder(
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1),
+		mockOFClient.EXPECT().InstallServiceMetricFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, gomock.Any()).Times(1),
+	)
+	fp.syncProxyRules()
+	assert.Equal(t, map[binding.GroupIDType]k8sproxy.ServicePortName{groupID: svcPortName}, fp.serviceGroups)
+
+	// The Service without Endpoint is uninstalled and its connections are
+	// dropped and counted.
+	gomock.InOrder(
+		mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().UninstallServiceMetricFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1),
+		mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().DrainEndpointFlows(bindingProtocol, gomock.Any()).Times(1),
+	)
+	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
+	fp.syncProxyRules()
+	// The flow is only installed once.
+	fp.syncProxyRules()
+	assert.Equal(t, map[binding.GroupIDType]k8sproxy.ServicePortName{groupID: svcPortName}, fp.serviceGroups)
+
+	// The Service is installed again when it has Endpoints, after the flow
+	// dropping its connections without Endpoint is removed.
+	gomock.InOrder(
+		mockOFClient.EXPECT().UninstallServiceNoEndpointFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1),
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1),
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1),
+		mockOFClient.EXPECT().InstallServiceMetricFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, gomock.Any()).Times(1),
+	)
+	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	fp.endpointsChanges.OnEndpointUpdate(nil, ep)
+	fp.syncProxyRules()
+}
+
+func TestServiceMetricFlowsIPv4(t *testing.T) {
+	testServiceMetricFlows(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), false)
+}
+
+func TestServiceMetricFlowsIPv6(t *testing.T) {
+	testServiceMetricFlows(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
+}
@@ -16,12 +16,17 @@ package proxy
 
 import (
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 	utilnet "k8s.io/utils/net"
@@ -29,6 +34,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/querier"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 	"github.com/vmware-tanzu/antrea/third_party/proxy/config"
@@ -39,11 +45,21 @@ const (
 	componentName = "antrea-agent-proxy"
 )
 
-// TODO: Add metrics
+// terminatingEndpoint is an Endpoint which has been removed from its Service.
+// It's not selected for the new connections anymore, but its flows required by
+// the established connections are kept until the deadline.
+type terminatingEndpoint struct {
+	endpoint k8sproxy.Endpoint
+	deadline time.Time
+}
+
 type proxier struct {
 	once            sync.Once
 	endpointsConfig *config.EndpointsConfig
-	serviceConfig   *config.ServiceConfig
+	// endpointSliceConfig is used instead of endpointsConfig when EndpointSlices
+	// are enabled.
+	endpointSliceConfig *config.EndpointSliceConfig
+	serviceConfig       *config.ServiceConfig
 	// endpointsChanges and serviceChanges contains all changes to endpoints and
 	// services that happened since last syncProxyRules call. For a single object,
 	// changes are accumulated. Once both endpointsChanges and serviceChanges
@@ -54,15 +70,43 @@ type proxier struct {
 	serviceMap k8sproxy.ServiceMap
 	// serviceInstalledMap stores services we actually installed.
 	serviceInstalledMap k8sproxy.ServiceMap
+	// serviceNoEndpointMap stores services without endpoint, for which only
+	// the flow dropping and counting their connections is installed.
+	serviceNoEndpointMap k8sproxy.ServiceMap
 	// endpointsMap stores endpoints we expect to be installed.
 	endpointsMap types.EndpointsMap
-	// endpointInstalledMap stores endpoints we actually installed.
-	endpointInstalledMap map[k8sproxy.ServicePortName]map[string]struct{}
-	groupCounter         types.GroupCounter
+	// endpointInstalledMap stores endpoints we actually installed, with the
+	// weights of their buckets.
+	endpointInstalledMap map[k8sproxy.ServicePortName]map[string]uint16
+	// terminatingEndpoints stores the removed endpoints whose flows are kept
+	// for their established connections.
+	terminatingEndpoints map[k8sproxy.ServicePortName]map[string]*terminatingEndpoint
+	// terminatingEndpointTimeout is how long the flows of a removed endpoint
+	// are kept for its established connections. It's not based on the actual
+	// termination grace period of the Pods, as the proxier only watches the
+	// Pods with the types.EndpointWeightLabelKey label.
+	terminatingEndpointTimeout time.Duration
+	groupCounter               types.GroupCounter
+	// endpointWeights stores the weights of the endpoints by IP, which are set
+	// with the types.EndpointWeightLabelKey label of their Pods.
+	endpointWeights map[string]uint16
+	// endpointWeightsMutex protects endpointWeights object, which is updated
+	// by the Pod event handlers.
+	endpointWeightsMutex sync.RWMutex
+	podListerSynced      cache.InformerSynced
 	// serviceStringMap provides map from serviceString(ClusterIP:Port/Proto) to ServicePortName.
 	serviceStringMap map[string]k8sproxy.ServicePortName
 	// serviceStringMapMutex protects serviceStringMap object.
 	serviceStringMapMutex sync.Mutex
+	// serviceGroups provides map from the group IDs of the installed services
+	// to their ServicePortNames, which is used to collect the metrics.
+	serviceGroups map[binding.GroupIDType]k8sproxy.ServicePortName
+	// serviceGroupsMutex protects serviceGroups object.
+	serviceGroupsMutex sync.Mutex
+	// serviceMetrics stores the last metrics collected from OVS for each
+	// service. It's only accessed by collectServiceMetrics.
+	serviceMetrics map[k8sproxy.ServicePortName]*agenttypes.ServiceMetric
+	metricsEnabled bool
 
 	runner       *k8sproxy.BoundedFrequencyRunner
 	stopChan     <-chan struct{}
@@ -75,27 +119,89 @@ func (p *proxier) isInitialized() bool {
 	return p.endpointsChanges.Synced() && p.serviceChanges.Synced()
 }
 
+// uninstallService removes the flows and the group of an installed service.
+// The flows of its endpoints are not removed.
+func (p *proxier) uninstallService(svcPortName k8sproxy.ServicePortName, svcInfo *types.ServiceInfo) bool {
+	if err := p.ofClient.UninstallServiceFlows(svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+		klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
+		return false
+	}
+	if p.metricsEnabled {
+		if err := p.ofClient.UninstallServiceMetricFlows(svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+			klog.Errorf("Failed to remove metric flows of Service %v: %v", svcPortName, err)
+			return false
+		}
+	}
+	for _, ingress := range svcInfo.LoadBalancerIPStrings() {
+		if ingress != "" {
+			if err := p.uninstallLoadBalancerServiceFlows(net.ParseIP(ingress), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+				klog.Errorf("Error when removing Service flows: %v", err)
+				continue
+			}
+		}
+	}
+	groupID, _ := p.groupCounter.Get(svcPortName)
+	if err := p.ofClient.UninstallServiceGroup(groupID); err != nil {
+		klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
+		return false
+	}
+	return true
+}
+
+// uninstallServiceNoEndpoint removes the flow dropping the connections of a
+// service without endpoint.
+func (p *proxier) uninstallServiceNoEndpoint(svcPortName k8sproxy.ServicePortName, svcInfo *types.ServiceInfo) bool {
+	if err := p.ofClient.UninstallServiceNoEndpointFlows(svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+		klog.Errorf("Failed to remove no Endpoint flows of Service %v: %v", svcPortName, err)
+		return false
+	}
+	delete(p.serviceNoEndpointMap, svcPortName)
+	return true
+}
+
+// installServiceNoEndpoint installs the flow dropping the connections of a
+// service without endpoint, which also counts them. The flows and the group of
+// the service are removed first if it was installed with endpoints.
+func (p *proxier) installServiceNoEndpoint(svcPortName k8sproxy.ServicePortName, svcInfo *types.ServiceInfo, groupID binding.GroupIDType) {
+	if installedSvcPort, ok := p.serviceInstalledMap[svcPortName]; ok {
+		pSvcInfo := installedSvcPort.(*types.ServiceInfo)
+		if !p.uninstallService(svcPortName, pSvcInfo) {
+			return
+		}
+		delete(p.serviceInstalledMap, svcPortName)
+		p.deleteServiceByIP(pSvcInfo.String())
+	}
+	if installedSvcPort, ok := p.serviceNoEndpointMap[svcPortName]; ok {
+		pSvcInfo := installedSvcPort.(*types.ServiceInfo)
+		if !serviceIdentityChanged(svcInfo, pSvcInfo) {
+			return
+		}
+		if !p.uninstallServiceNoEndpoint(svcPortName, pSvcInfo) {
+			return
+		}
+	}
+	if err := p.ofClient.InstallServiceNoEndpointFlows(groupID, svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+		klog.Errorf("Error when installing no Endpoint flows of Service %v: %v", svcPortName, err)
+		return
+	}
+	p.serviceNoEndpointMap[svcPortName] = svcInfo
+}
+
 func (p *proxier) removeStaleServices() {
-	for svcPortName, svcPort := range p.serviceInstalledMap {
+	for svcPortName, svcPort := range p.serviceNoEndpointMap {
 		if _, ok := p.serviceMap[svcPortName]; ok {
 			continue
 		}
-		svcInfo := svcPort.(*types.ServiceInfo)
-		if err := p.ofClient.UninstallServiceFlows(svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
-			klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
-			continue
+		if p.uninstallServiceNoEndpoint(svcPortName, svcPort.(*types.ServiceInfo)) {
+			p.groupCounter.Recycle(svcPortName)
 		}
-		for _, ingress := range svcInfo.LoadBalancerIPStrings() {
-			if ingress != "" {
-				if err := p.uninstallLoadBalancerServiceFlows(net.ParseIP(ingress), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
-					klog.Errorf("Error when removing Service flows: %v", err)
-					continue
-				}
-			}
+	}
+	for svcPortName, svcPort := range p.serviceInstalledMap {
+		if _, ok := p.serviceMap[svcPortName]; ok {
+			continue
 		}
-		groupID, _ := p.groupCounter.Get(svcPortName)
-		if err := p.ofClient.UninstallServiceGroup(groupID); err != nil {
-			klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
+		svcInfo := svcPort.(*types.ServiceInfo)
+		if !p.uninstallService(svcPortName, svcInfo) {
 			continue
 		}
 		for _, endpoint := range p.endpointsMap[svcPortName] {
@@ -130,12 +236,18 @@ func getBindingProtoForIPProto(endpointIP string, protocol corev1.Protocol) bind
 	return bindingProtocol
 }
 
+// removeStaleEndpoints stops selecting the stale endpoints for the new
+// connections. Their buckets have been removed from the groups of the Services
+// by installServices, and their DNAT flows are removed here, so that the
+// connections with session affinity select another endpoint. The other flows
+// are kept for the established connections until terminatingEndpointTimeout
+// has elapsed.
 func (p *proxier) removeStaleEndpoints(staleEndpoints map[k8sproxy.ServicePortName]map[string]k8sproxy.Endpoint) {
 	for svcPortName, endpoints := range staleEndpoints {
 		for _, endpoint := range endpoints {
 			bindingProtocol := getBindingProtoForIPProto(endpoint.IP(), svcPortName.Protocol)
-			if err := p.ofClient.UninstallEndpointFlows(bindingProtocol, endpoint); err != nil {
-				klog.Errorf("Error when removing Endpoint %v for %v", endpoint, svcPortName)
+			if err := p.ofClient.DrainEndpointFlows(bindingProtocol, endpoint); err != nil {
+				klog.Errorf("Error when draining Endpoint %v for %v: %v", endpoint, svcPortName, err)
 				continue
 			}
 			if m, ok := p.endpointInstalledMap[svcPortName]; ok {
@@ -144,6 +256,46 @@ func (p *proxier) removeStaleEndpoints(staleEndpoints map[k8sproxy.ServicePortNa
 					delete(p.endpointInstalledMap, svcPortName)
 				}
 			}
+			if _, ok := p.terminatingEndpoints[svcPortName]; !ok {
+				p.terminatingEndpoints[svcPortName] = map[string]*terminatingEndpoint{}
+			}
+			p.terminatingEndpoints[svcPortName][endpoint.String()] = &terminatingEndpoint{
+				endpoint: endpoint,
+				deadline: time.Now().Add(p.terminatingEndpointTimeout),
+			}
+		}
+	}
+}
+
+// removeTerminatingEndpoint removes the remaining flows of the endpoint if it's
+// terminating. It's called when the endpoint is added back to the Service, so
+// that all its flows can be installed again.
+func (p *proxier) removeTerminatingEndpoint(svcPortName k8sproxy.ServicePortName, endpoint k8sproxy.Endpoint) {
+	te, ok := p.terminatingEndpoints[svcPortName][endpoint.String()]
+	if !ok {
+		return
+	}
+	bindingProtocol := getBindingProtoForIPProto(te.endpoint.IP(), svcPortName.Protocol)
+	if err := p.ofClient.UninstallEndpointFlows(bindingProtocol, te.endpoint); err != nil {
+		klog.Errorf("Error when removing terminating Endpoint %v for %v: %v", te.endpoint, svcPortName, err)
+		return
+	}
+	delete(p.terminatingEndpoints[svcPortName], endpoint.String())
+	if len(p.terminatingEndpoints[svcPortName]) == 0 {
+		delete(p.terminatingEndpoints, svcPortName)
+	}
+}
+
+// removeExpiredTerminatingEndpoints removes the remaining flows of the
+// terminating endpoints whose deadline has passed.
+func (p *proxier) removeExpiredTerminatingEndpoints() {
+	now := time.Now()
+	for svcPortName, endpoints := range p.terminatingEndpoints {
+		for _, te := range endpoints {
+			if now.Before(te.deadline) {
+				continue
+			}
+			p.removeTerminatingEndpoint(svcPortName, te.endpoint)
 		}
 	}
 }
@@ -181,12 +333,22 @@ func (p *proxier) installServices() {
 		groupID, _ := p.groupCounter.Get(svcPortName)
 		endpoints, ok := p.endpointsMap[svcPortName]
 		if !ok || len(endpoints) == 0 {
+			// The flow is installed whether the metrics are enabled or not,
+			// so that the forwarding doesn't depend on them.
+			p.installServiceNoEndpoint(svcPortName, svcInfo, groupID)
 			continue
 		}
+		// The flow dropping the connections without endpoint has the same
+		// match as the flows of the service, it must be removed first.
+		if installedSvcPort, ok := p.serviceNoEndpointMap[svcPortName]; ok {
+			if !p.uninstallServiceNoEndpoint(svcPortName, installedSvcPort.(*types.ServiceInfo)) {
+				continue
+			}
+		}
 
-		endpointInstalled, ok := p.endpointInstalledMap[svcPortName]
-		if !ok {
-			p.endpointInstalledMap[svcPortName] = map[string]struct{}{}
+		endpointInstalled := p.endpointInstalledMap[svcPortName]
+		if endpointInstalled == nil {
+			p.endpointInstalledMap[svcPortName] = map[string]uint16{}
 			endpointInstalled = p.endpointInstalledMap[svcPortName]
 		}
 
@@ -202,11 +364,21 @@ func (p *proxier) installServices() {
 
 		var endpointUpdateList []k8sproxy.Endpoint
 		for _, endpoint := range endpoints {
-			if _, ok := endpointInstalled[endpoint.String()]; !ok {
+			weight := p.getEndpointWeight(endpoint.IP())
+			installedWeight, ok := endpointInstalled[endpoint.String()]
+			if !ok {
+				p.removeTerminatingEndpoint(svcPortName, endpoint)
+			}
+			if !ok || installedWeight != weight {
 				needUpdate = true
-				endpointInstalled[endpoint.String()] = struct{}{}
+				endpointInstalled[endpoint.String()] = weight
 			}
-			endpointUpdateList = append(endpointUpdateList, endpoint)
+			endpointUpdateList = append(endpointUpdateList, &types.EndpointInfo{Endpoint: endpoint, Weight: weight})
+		}
+		// Some installed Endpoints were removed, the group must be updated to
+		// remove their buckets.
+		if len(endpointInstalled) != len(endpoints) {
+			needUpdate = true
 		}
 
 		var deletedLoadBalancerIPs, addedLoadBalancerIPs []string
@@ -240,11 +412,22 @@ func (p *proxier) installServices() {
 			if err := p.ofClient.UninstallServiceFlows(pSvcInfo.ClusterIP(), uint16(pSvcInfo.Port()), pSvcInfo.OFProtocol); err != nil {
 				klog.Errorf("Failed to remove flows of Service %v: %v", svcPortName, err)
 			}
+			if p.metricsEnabled {
+				if err := p.ofClient.UninstallServiceMetricFlows(pSvcInfo.ClusterIP(), uint16(pSvcInfo.Port()), pSvcInfo.OFProtocol); err != nil {
+					klog.Errorf("Failed to remove metric flows of Service %v: %v", svcPortName, err)
+				}
+			}
 		}
 		if err := p.ofClient.InstallServiceFlows(groupID, svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(svcInfo.StickyMaxAgeSeconds())); err != nil {
 			klog.Errorf("Error when installing Service flows: %v", err)
 			continue
 		}
+		if p.metricsEnabled {
+			if err := p.ofClient.InstallServiceMetricFlows(groupID, svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol, endpointUpdateList); err != nil {
+				klog.Errorf("Error when installing Service metric flows: %v", err)
+				continue
+			}
+		}
 		// Install OpenFlow entries for the ingress IPs of LoadBalancer Service.
 		// The LoadBalancer Service should be accessible from Pod, Node and
 		// external host.
@@ -302,6 +485,10 @@ func (p *proxier) syncProxyRules() {
 	p.removeStaleServices()
 	p.installServices()
 	p.removeStaleEndpoints(staleEndpoints)
+	p.removeExpiredTerminatingEndpoints()
+	if p.metricsEnabled {
+		p.updateServiceGroups()
+	}
 }
 
 func (p *proxier) SyncLoop() {
@@ -329,6 +516,39 @@ func (p *proxier) OnEndpointsSynced() {
 	}
 }
 
+func (p *proxier) OnEndpointSliceAdd(endpointSlice *discovery.EndpointSlice) {
+	p.onEndpointSliceUpdate(endpointSlice, false)
+}
+
+func (p *proxier) OnEndpointSliceUpdate(oldEndpointSlice, newEndpointSlice *discovery.EndpointSlice) {
+	p.onEndpointSliceUpdate(newEndpointSlice, false)
+}
+
+func (p *proxier) OnEndpointSliceDelete(endpointSlice *discovery.EndpointSlice) {
+	p.onEndpointSliceUpdate(endpointSlice, true)
+}
+
+func (p *proxier) onEndpointSliceUpdate(endpointSlice *discovery.EndpointSlice, removeSlice bool) {
+	// EndpointSlices of the other address family, or of FQDN type, are ignored.
+	addressType := discovery.AddressTypeIPv4
+	if p.isIPv6 {
+		addressType = discovery.AddressTypeIPv6
+	}
+	if endpointSlice.AddressType != addressType {
+		return
+	}
+	if p.endpointsChanges.OnEndpointSliceUpdate(endpointSlice, removeSlice) && p.isInitialized() {
+		p.runner.Run()
+	}
+}
+
+func (p *proxier) OnEndpointSlicesSynced() {
+	p.endpointsChanges.OnEndpointsSynced()
+	if p.isInitialized() {
+		p.runner.Run()
+	}
+}
+
 func (p *proxier) OnServiceAdd(service *corev1.Service) {
 	p.OnServiceUpdate(nil, service)
 }
@@ -358,6 +578,93 @@ func (p *proxier) OnServiceSynced() {
 	}
 }
 
+// getEndpointWeight returns the weight of the endpoint with the given IP.
+func (p *proxier) getEndpointWeight(ip string) uint16 {
+	p.endpointWeightsMutex.RLock()
+	defer p.endpointWeightsMutex.RUnlock()
+
+	if weight, ok := p.endpointWeights[ip]; ok {
+		return weight
+	}
+	return types.DefaultEndpointWeight
+}
+
+// podEndpointWeights returns the weight of each IP of the Pod, in the IP
+// family of the proxier, set with the types.EndpointWeightLabelKey label. A
+// weight of 0 is invalid, as the Services whose Endpoints all have a weight of
+// 0 would drop all the new connections.
+func (p *proxier) podEndpointWeights(pod *corev1.Pod) map[string]uint16 {
+	weights := map[string]uint16{}
+	if pod == nil {
+		return weights
+	}
+	value, ok := pod.Labels[types.EndpointWeightLabelKey]
+	if !ok {
+		return weights
+	}
+	weight, err := strconv.ParseUint(value, 10, 16)
+	if err != nil || weight == 0 {
+		klog.Warningf("Ignoring invalid value %q of label %s of Pod %s/%s", value, types.EndpointWeightLabelKey, pod.Namespace, pod.Name)
+		return weights
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		if utilnet.IsIPv6String(podIP.IP) == p.isIPv6 {
+			weights[podIP.IP] = uint16(weight)
+		}
+	}
+	return weights
+}
+
+// updateEndpointWeights updates the weights of the endpoints of a Pod, and
+// triggers a sync if they changed.
+func (p *proxier) updateEndpointWeights(oldPod, newPod *corev1.Pod) {
+	oldWeights := p.podEndpointWeights(oldPod)
+	newWeights := p.podEndpointWeights(newPod)
+	changed := false
+	p.endpointWeightsMutex.Lock()
+	for ip := range oldWeights {
+		if _, ok := newWeights[ip]; !ok {
+			delete(p.endpointWeights, ip)
+			changed = true
+		}
+	}
+	for ip, weight := range newWeights {
+		if currentWeight, ok := p.endpointWeights[ip]; !ok || currentWeight != weight {
+			p.endpointWeights[ip] = weight
+			changed = true
+		}
+	}
+	p.endpointWeightsMutex.Unlock()
+	if changed && p.isInitialized() {
+		p.runner.Run()
+	}
+}
+
+func (p *proxier) onPodAdd(obj interface{}) {
+	p.updateEndpointWeights(nil, obj.(*corev1.Pod))
+}
+
+func (p *proxier) onPodUpdate(oldObj, newObj interface{}) {
+	p.updateEndpointWeights(oldObj.(*corev1.Pod), newObj.(*corev1.Pod))
+}
+
+func (p *proxier) onPodDelete(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		pod, ok = deletedState.Obj.(*corev1.Pod)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Pod object: %v", deletedState.Obj)
+			return
+		}
+	}
+	p.updateEndpointWeights(pod, nil)
+}
+
 func (p *proxier) GetServiceByIP(serviceStr string) (k8sproxy.ServicePortName, bool) {
 	p.serviceStringMapMutex.Lock()
 	defer p.serviceStringMapMutex.Unlock()
@@ -383,51 +690,101 @@ func (p *proxier) deleteServiceByIP(serviceStr string) {
 func (p *proxier) Run(stopCh <-chan struct{}) {
 	p.once.Do(func() {
 		go p.serviceConfig.Run(stopCh)
-		go p.endpointsConfig.Run(stopCh)
+		if p.endpointSliceConfig != nil {
+			go p.endpointSliceConfig.Run(stopCh)
+		} else {
+			go p.endpointsConfig.Run(stopCh)
+		}
 		p.stopChan = stopCh
+		// Wait for the weights of the endpoints to avoid installing the
+		// groups with the default weights first.
+		if !cache.WaitForNamedCacheSync(componentName, stopCh, p.podListerSynced) {
+			return
+		}
+		if p.metricsEnabled {
+			go wait.Until(p.collectServiceMetrics, serviceMetricsInterval, stopCh)
+		}
 		p.SyncLoop()
 	})
 }
 
+// NewProxier creates a proxier for the given IP family. The Endpoints of the
+// Services are retrieved from the EndpointSlices when endpointSliceEnabled is
+// true, otherwise from the Endpoints. The weights of the Endpoints are retrieved
+// from the Pods of podInformer, which should only watch the Pods with the
+// types.EndpointWeightLabelKey label. The metrics of the Services are collected
+// when metricsEnabled is true. The flows of the removed Endpoints are kept for
+// their established connections during terminatingEndpointTimeout.
 func NewProxier(
 	hostname string,
 	informerFactory informers.SharedInformerFactory,
+	podInformer coreinformers.PodInformer,
 	ofClient openflow.Client,
-	isIPv6 bool) *proxier {
+	isIPv6 bool,
+	endpointSliceEnabled bool,
+	metricsEnabled bool,
+	terminatingEndpointTimeout time.Duration) *proxier {
 	recorder := record.NewBroadcaster().NewRecorder(
 		runtime.NewScheme(),
 		corev1.EventSource{Component: componentName, Host: hostname},
 	)
 
-	klog.Infof("Creating proxier with IPv6 enabled=%t", isIPv6)
+	klog.Infof("Creating proxier with IPv6 enabled=%t, EndpointSlice enabled=%t, metrics enabled=%t", isIPv6, endpointSliceEnabled, metricsEnabled)
 	p := &proxier{
-		endpointsConfig:      config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod),
-		serviceConfig:        config.NewServiceConfig(informerFactory.Core().V1().Services(), resyncPeriod),
-		endpointsChanges:     newEndpointsChangesTracker(hostname),
-		serviceChanges:       newServiceChangesTracker(recorder, isIPv6),
-		serviceMap:           k8sproxy.ServiceMap{},
-		serviceInstalledMap:  k8sproxy.ServiceMap{},
-		endpointInstalledMap: map[k8sproxy.ServicePortName]map[string]struct{}{},
-		endpointsMap:         types.EndpointsMap{},
-		serviceStringMap:     map[string]k8sproxy.ServicePortName{},
-		groupCounter:         types.NewGroupCounter(),
-		ofClient:             ofClient,
-		isIPv6:               isIPv6,
+		serviceConfig:              config.NewServiceConfig(informerFactory.Core().V1().Services(), resyncPeriod),
+		endpointsChanges:           newEndpointsChangesTracker(hostname, endpointSliceEnabled),
+		serviceChanges:             newServiceChangesTracker(recorder, isIPv6),
+		serviceMap:                 k8sproxy.ServiceMap{},
+		serviceInstalledMap:        k8sproxy.ServiceMap{},
+		serviceNoEndpointMap:       k8sproxy.ServiceMap{},
+		endpointInstalledMap:       map[k8sproxy.ServicePortName]map[string]uint16{},
+		terminatingEndpoints:       map[k8sproxy.ServicePortName]map[string]*terminatingEndpoint{},
+		endpointsMap:               types.EndpointsMap{},
+		serviceStringMap:           map[string]k8sproxy.ServicePortName{},
+		serviceGroups:              map[binding.GroupIDType]k8sproxy.ServicePortName{},
+		serviceMetrics:             map[k8sproxy.ServicePortName]*agenttypes.ServiceMetric{},
+		metricsEnabled:             metricsEnabled,
+		terminatingEndpointTimeout: terminatingEndpointTimeout,
+		groupCounter:               types.NewGroupCounter(),
+		endpointWeights:            map[string]uint16{},
+		podListerSynced:            podInformer.Informer().HasSynced,
+		ofClient:                   ofClient,
+		isIPv6:                     isIPv6,
 	}
+	podInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    p.onPodAdd,
+			UpdateFunc: p.onPodUpdate,
+			DeleteFunc: p.onPodDelete,
+		},
+		resyncPeriod,
+	)
 	p.serviceConfig.RegisterEventHandler(p)
-	p.endpointsConfig.RegisterEventHandler(p)
+	if endpointSliceEnabled {
+		p.endpointSliceConfig = config.NewEndpointSliceConfig(informerFactory.Discovery().V1beta1().EndpointSlices(), resyncPeriod)
+		p.endpointSliceConfig.RegisterEventHandler(p)
+	} else {
+		p.endpointsConfig = config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod)
+		p.endpointsConfig.RegisterEventHandler(p)
+	}
 	p.runner = k8sproxy.NewBoundedFrequencyRunner(componentName, p.syncProxyRules, 0, 30*time.Second, -1)
 	return p
 }
 
 func NewDualStackProxier(
-	hostname string, informerFactory informers.SharedInformerFactory, ofClient openflow.Client) k8sproxy.Provider {
+	hostname string,
+	informerFactory informers.SharedInformerFactory,
+	podInformer coreinformers.PodInformer,
+	ofClient openflow.Client,
+	endpointSliceEnabled bool,
+	metricsEnabled bool,
+	terminatingEndpointTimeout time.Duration) k8sproxy.Provider {
 
 	// Create an ipv4 instance of the single-stack proxier
-	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, false)
+	ipv4Proxier := NewProxier(hostname, informerFactory, podInformer, ofClient, false, endpointSliceEnabled, metricsEnabled, terminatingEndpointTimeout)
 
 	// Create an ipv6 instance of the single-stack proxier
-	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, true)
+	ipv6Proxier := NewProxier(hostname, informerFactory, podInformer, ofClient, true, endpointSliceEnabled, metricsEnabled, terminatingEndpointTimeout)
 
 	// Return a meta-proxier that dispatch calls between the two
 	// single-stack proxier instances
@@ -18,9 +18,12 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
@@ -30,6 +33,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
 	ofmock "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
 	"github.com/vmware-tanzu/antrea/pkg/agent/proxy/types"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	binding "github.com/vmware-tanzu/antrea/pkg/ovs/openflow"
 	k8sproxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 )
@@ -77,7 +81,30 @@ func makeTestEndpoints(namespace, name string, eptFunc func(*corev1.Endpoints))
 	return ept
 }
 
+func makeEndpointSliceMap(proxier *proxier, allEndpointSlices ...*discovery.EndpointSlice) {
+	for i := range allEndpointSlices {
+		proxier.endpointsChanges.OnEndpointSliceUpdate(allEndpointSlices[i], false)
+	}
+	proxier.endpointsChanges.OnEndpointsSynced()
+}
+
+func makeTestEndpointSlice(namespace, serviceName, name string, sliceFunc func(*discovery.EndpointSlice)) *discovery.EndpointSlice {
+	slice := &discovery.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{discovery.LabelServiceName: serviceName},
+		},
+	}
+	sliceFunc(slice)
+	return slice
+}
+
 func NewFakeProxier(ofClient openflow.Client, isIPv6 bool) *proxier {
+	return newFakeProxier(ofClient, isIPv6, false)
+}
+
+func newFakeProxier(ofClient openflow.Client, isIPv6 bool, endpointSliceEnabled bool) *proxier {
 	hostname := "localhost"
 	eventBroadcaster := record.NewBroadcaster()
 	recorder := eventBroadcaster.NewRecorder(
@@ -85,17 +112,24 @@ func NewFakeProxier(ofClient openflow.Client, isIPv6 bool) *proxier {
 		corev1.EventSource{Component: componentName, Host: hostname},
 	)
 	p := &proxier{
-		endpointsChanges:     newEndpointsChangesTracker(hostname),
-		serviceChanges:       newServiceChangesTracker(recorder, isIPv6),
-		serviceMap:           k8sproxy.ServiceMap{},
-		serviceInstalledMap:  k8sproxy.ServiceMap{},
-		endpointInstalledMap: map[k8sproxy.ServicePortName]map[string]struct{}{},
-		endpointsMap:         types.EndpointsMap{},
-		groupCounter:         types.NewGroupCounter(),
-		ofClient:             ofClient,
-		serviceStringMap:     map[string]k8sproxy.ServicePortName{},
-		isIPv6:               isIPv6,
+		endpointsChanges:           newEndpointsChangesTracker(hostname, endpointSliceEnabled),
+		serviceChanges:             newServiceChangesTracker(recorder, isIPv6),
+		serviceMap:                 k8sproxy.ServiceMap{},
+		serviceInstalledMap:        k8sproxy.ServiceMap{},
+		serviceNoEndpointMap:       k8sproxy.ServiceMap{},
+		endpointInstalledMap:       map[k8sproxy.ServicePortName]map[string]uint16{},
+		terminatingEndpoints:       map[k8sproxy.ServicePortName]map[string]*terminatingEndpoint{},
+		terminatingEndpointTimeout: time.Minute,
+		endpointsMap:               types.EndpointsMap{},
+		groupCounter:               types.NewGroupCounter(),
+		endpointWeights:            map[string]uint16{},
+		ofClient:                   ofClient,
+		serviceStringMap:           map[string]k8sproxy.ServicePortName{},
+		serviceGroups:              map[binding.GroupIDType]k8sproxy.ServicePortName{},
+		serviceMetrics:             map[k8sproxy.ServicePortName]*agenttypes.ServiceMetric{},
+		isIPv6:                     isIPv6,
 	}
+	p.runner = k8sproxy.NewBoundedFrequencyRunner(componentName, p.syncProxyRules, 0, 30*time.Second, -1)
 	return p
 }
 
@@ -247,6 +281,13 @@ func testClusterIPNoEndpoint(t *testing.T, svcIP net.IP, isIPv6 bool) {
 	)
 	makeEndpointsMap(fp)
 
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1)
+
 	fp.syncProxyRules()
 }
 
@@ -334,7 +375,10 @@ func testClusterIPRemoveSamePortEndpoint(t *testing.T, svcIP net.IP, epIP net.IP
 	mockOFClient.EXPECT().InstallEndpointFlows(protocolUDP, gomock.Any(), isIPv6).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), protocolTCP, uint16(0)).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupIDUDP, svcIP, uint16(svcPort), protocolUDP, uint16(0)).Times(1)
-	mockOFClient.EXPECT().UninstallEndpointFlows(protocolUDP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), protocolUDP).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(groupIDUDP).Times(1)
+	mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupIDUDP, svcIP, uint16(svcPort), protocolUDP).Times(1)
+	mockOFClient.EXPECT().DrainEndpointFlows(protocolUDP, gomock.Any()).Times(1)
 	fp.syncProxyRules()
 
 	fp.endpointsChanges.OnEndpointUpdate(epUDP, nil)
@@ -349,11 +393,15 @@ func TestClusterIPRemoveSamePortEndpointIPv6(t *testing.T) {
 	testClusterIPRemoveSamePortEndpoint(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
 }
 
-func testClusterIPRemoveEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool) {
+// testClusterIPRemoveEndpoints checks that the connections to a Service whose
+// Endpoints are removed are dropped, with the same flows whether the metrics
+// are enabled or not.
+func testClusterIPRemoveEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool, metricsEnabled bool) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockOFClient := ofmock.NewMockClient(ctrl)
 	fp := NewFakeProxier(mockOFClient, isIPv6)
+	fp.metricsEnabled = metricsEnabled
 
 	svcPort := 80
 	svcPortName := k8sproxy.ServicePortName{
@@ -393,19 +441,39 @@ func testClusterIPRemoveEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv
 	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
-	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	// The metric flows are the only difference when the metrics are enabled.
+	metricFlowsCount := 0
+	if metricsEnabled {
+		metricFlowsCount = 1
+	}
+	mockOFClient.EXPECT().InstallServiceMetricFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, gomock.Any()).Times(metricFlowsCount)
+	mockOFClient.EXPECT().UninstallServiceMetricFlows(svcIP, uint16(svcPort), bindingProtocol).Times(metricFlowsCount)
 	fp.syncProxyRules()
 
+	gomock.InOrder(
+		mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1),
+		mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1),
+		mockOFClient.EXPECT().DrainEndpointFlows(bindingProtocol, gomock.Any()).Times(1),
+	)
 	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
 	fp.syncProxyRules()
 }
 
 func TestClusterIPRemoveEndpointsIPv4(t *testing.T) {
-	testClusterIPRemoveEndpoints(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), false)
+	testClusterIPRemoveEndpoints(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), false, false)
 }
 
 func TestClusterIPRemoveEndpointsIPv6(t *testing.T) {
-	testClusterIPRemoveEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
+	testClusterIPRemoveEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true, false)
+}
+
+func TestClusterIPRemoveEndpointsWithMetricsIPv4(t *testing.T) {
+	testClusterIPRemoveEndpoints(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), false, true)
+}
+
+func TestClusterIPRemoveEndpointsWithMetricsIPv6(t *testing.T) {
+	testClusterIPRemoveEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true, true)
 }
 
 func testSessionAffinityNoEndpoint(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP net.IP, isIPv6 bool) {
@@ -512,6 +580,13 @@ func testSessionAffinity(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, isIP
 	)
 	makeEndpointsMap(fp)
 
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1)
+
 	fp.syncProxyRules()
 }
 
@@ -595,3 +670,284 @@ func TestPortChangeIPv4(t *testing.T) {
 func TestPortChangeIPv6(t *testing.T) {
 	testPortChange(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
 }
+
+func testClusterIPEndpointSlices(t *testing.T, svcIP net.IP, epIP1, epIP2 net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := newFakeProxier(mockOFClient, isIPv6, true)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Spec.ClusterIP = svcIP.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+
+	addressType := discovery.AddressTypeIPv4
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		addressType = discovery.AddressTypeIPv6
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	sliceFunc := func(epIP net.IP) func(*discovery.EndpointSlice) {
+		return func(slice *discovery.EndpointSlice) {
+			slice.AddressType = addressType
+			slice.Endpoints = []discovery.Endpoint{{
+				Addresses: []string{epIP.String()},
+			}}
+			slice.Ports = []discovery.EndpointPort{{
+				Name:     &svcPortName.Port,
+				Port:     func() *int32 { p := int32(svcPort); return &p }(),
+				Protocol: &svcPortName.Protocol,
+			}}
+		}
+	}
+	slice1 := makeTestEndpointSlice(svcPortName.Namespace, svcPortName.Name, "svc1-abcde", sliceFunc(epIP1))
+	slice2 := makeTestEndpointSlice(svcPortName.Namespace, svcPortName.Name, "svc1-fghij", sliceFunc(epIP2))
+	makeEndpointSliceMap(fp, slice1, slice2)
+
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Do(
+		func(_ binding.GroupIDType, _ bool, endpoints []k8sproxy.Endpoint) {
+			assert.Len(t, endpoints, 2)
+		}).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	fp.syncProxyRules()
+
+	// Deleting an EndpointSlice only removes its own endpoints.
+	removedEndpoint := net.JoinHostPort(epIP2.String(), fmt.Sprint(svcPort))
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Do(
+		func(_ binding.GroupIDType, _ bool, endpoints []k8sproxy.Endpoint) {
+			assert.Len(t, endpoints, 1)
+		}).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	mockOFClient.EXPECT().DrainEndpointFlows(bindingProtocol, gomock.Any()).Do(
+		func(_ binding.Protocol, endpoint k8sproxy.Endpoint) {
+			assert.Equal(t, removedEndpoint, endpoint.String())
+		}).Times(1)
+	fp.endpointsChanges.OnEndpointSliceUpdate(slice2, true)
+	fp.syncProxyRules()
+	assert.Len(t, fp.endpointsMap[svcPortName], 1)
+}
+
+func TestClusterIPEndpointSlicesIPv4(t *testing.T) {
+	testClusterIPEndpointSlices(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), net.ParseIP("10.180.0.2"), false)
+}
+
+func TestClusterIPEndpointSlicesIPv6(t *testing.T) {
+	testClusterIPEndpointSlices(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), net.ParseIP("10:180::2"), true)
+}
+
+func testTerminatingEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, isIPv6)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Spec.ClusterIP = svcIP.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+
+	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{
+				IP: epIP.String(),
+			}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	})
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	endpointString := net.JoinHostPort(epIP.String(), fmt.Sprint(svcPort))
+	makeEndpointsMap(fp, ep)
+	groupID, _ := fp.groupCounter.Get(svcPortName)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	fp.syncProxyRules()
+
+	// The removed Endpoint is drained and its remaining flows are kept until
+	// the deadline, while the new connections to the Service are dropped.
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
+	mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().DrainEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
+	fp.syncProxyRules()
+	assert.Contains(t, fp.terminatingEndpoints[svcPortName], endpointString)
+	fp.syncProxyRules()
+	assert.Contains(t, fp.terminatingEndpoints[svcPortName], endpointString)
+
+	// The Endpoint which is added back is reinstalled.
+	mockOFClient.EXPECT().UninstallServiceNoEndpointFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	fp.endpointsChanges.OnEndpointUpdate(nil, ep)
+	fp.syncProxyRules()
+	assert.NotContains(t, fp.terminatingEndpoints, svcPortName)
+
+	// The flows of the terminating Endpoint are removed after the deadline.
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
+	mockOFClient.EXPECT().InstallServiceNoEndpointFlows(groupID, svcIP, uint16(svcPort), bindingProtocol).Times(1)
+	mockOFClient.EXPECT().DrainEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
+	fp.endpointsChanges.OnEndpointUpdate(ep, nil)
+	fp.syncProxyRules()
+	fp.terminatingEndpoints[svcPortName][endpointString].deadline = time.Now().Add(-time.Second)
+	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Do(
+		func(_ binding.Protocol, endpoint k8sproxy.Endpoint) {
+			assert.Equal(t, endpointString, endpoint.String())
+		}).Times(1)
+	fp.syncProxyRules()
+	assert.NotContains(t, fp.terminatingEndpoints, svcPortName)
+}
+
+func TestTerminatingEndpointsIPv4(t *testing.T) {
+	testTerminatingEndpoints(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), false)
+}
+
+func TestTerminatingEndpointsIPv6(t *testing.T) {
+	testTerminatingEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
+}
+
+func testEndpointWeights(t *testing.T, svcIP net.IP, epIP1, epIP2 net.IP, isIPv6 bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	fp := NewFakeProxier(mockOFClient, isIPv6)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Spec.ClusterIP = svcIP.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+	makeEndpointsMap(fp,
+		makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+			ept.Subsets = []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: epIP1.String()}, {IP: epIP2.String()}},
+				Ports: []corev1.EndpointPort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}},
+			}}
+		}),
+	)
+	canaryPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "canary",
+			Namespace: svcPortName.Namespace,
+			Labels:    map[string]string{types.EndpointWeightLabelKey: "10"},
+		},
+		Status: corev1.PodStatus{
+			PodIPs: []corev1.PodIP{{IP: "10.180.0.2"}, {IP: "10:180::2"}},
+		},
+	}
+	fp.updateEndpointWeights(nil, canaryPod)
+
+	bindingProtocol := binding.ProtocolTCP
+	if isIPv6 {
+		bindingProtocol = binding.ProtocolTCPv6
+	}
+	expectWeights := func(weights map[string]uint16) {
+		groupID, _ := fp.groupCounter.Get(svcPortName)
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Do(
+			func(_ binding.GroupIDType, _ bool, endpoints []k8sproxy.Endpoint) {
+				actualWeights := map[string]uint16{}
+				for _, endpoint := range endpoints {
+					actualWeights[endpoint.IP()] = types.GetEndpointWeight(endpoint)
+				}
+				assert.Equal(t, weights, actualWeights)
+			}).Times(1)
+		mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any(), isIPv6).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0)).Times(1)
+	}
+	expectWeights(map[string]uint16{epIP1.String(): types.DefaultEndpointWeight, epIP2.String(): 10})
+	fp.syncProxyRules()
+
+	// Nothing is reinstalled if the weights are unchanged.
+	fp.syncProxyRules()
+
+	// The group is updated when the label is removed from the Pod.
+	updatedPod := canaryPod.DeepCopy()
+	delete(updatedPod.Labels, types.EndpointWeightLabelKey)
+	fp.updateEndpointWeights(canaryPod, updatedPod)
+	expectWeights(map[string]uint16{epIP1.String(): types.DefaultEndpointWeight, epIP2.String(): types.DefaultEndpointWeight})
+	fp.syncProxyRules()
+}
+
+func TestEndpointWeightsIPv4(t *testing.T) {
+	testEndpointWeights(t, net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), net.ParseIP("10.180.0.2"), false)
+}
+
+func TestEndpointWeightsIPv6(t *testing.T) {
+	testEndpointWeights(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), net.ParseIP("10:180::2"), true)
+}
+
+func TestPodEndpointWeights(t *testing.T) {
+	fp := NewFakeProxier(nil, false)
+	makePod := func(weight string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				Labels:    map[string]string{types.EndpointWeightLabelKey: weight},
+			},
+			Status: corev1.PodStatus{
+				PodIPs: []corev1.PodIP{{IP: "10.180.0.1"}, {IP: "10:180::1"}},
+			},
+		}
+	}
+	assert.Equal(t, map[string]uint16{"10.180.0.1": 1}, fp.podEndpointWeights(makePod("1")))
+	assert.Empty(t, fp.podEndpointWeights(makePod("0")))
+	assert.Equal(t, map[string]uint16{"10.180.0.1": 65535}, fp.podEndpointWeights(makePod("65535")))
+	assert.Empty(t, fp.podEndpointWeights(makePod("65536")))
+	assert.Empty(t, fp.podEndpointWeights(makePod("-1")))
+	assert.Empty(t, fp.podEndpointWeights(makePod("abc")))
+	assert.Empty(t, fp.podEndpointWeights(nil))
+}
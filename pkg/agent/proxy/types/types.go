@@ -50,6 +50,32 @@ func NewServiceInfo(port *corev1.ServicePort, service *corev1.Service, baseInfo
 	return info
 }
 
+const (
+	// EndpointWeightLabelKey is the key of the Pod label which sets the weight
+	// of the Pod's Endpoints in the load-balancing of new connections, e.g. to
+	// give a smaller share of the traffic to a canary Pod.
+	EndpointWeightLabelKey = "proxy.antrea.tanzu.vmware.com/weight"
+	// DefaultEndpointWeight is the weight of the Endpoints whose Pod doesn't
+	// have the EndpointWeightLabelKey label.
+	DefaultEndpointWeight uint16 = 100
+)
+
+// EndpointInfo is the internal struct for an Endpoint with the weight of its
+// bucket in the group of the Service.
+type EndpointInfo struct {
+	k8sproxy.Endpoint
+	Weight uint16
+}
+
+// GetEndpointWeight returns the weight of the Endpoint, which is
+// DefaultEndpointWeight if it's not an EndpointInfo.
+func GetEndpointWeight(endpoint k8sproxy.Endpoint) uint16 {
+	if info, ok := endpoint.(*EndpointInfo); ok {
+		return info.Weight
+	}
+	return DefaultEndpointWeight
+}
+
 // NewEndpointInfo returns a new k8sproxy.Endpoint which abstracts an endpointsInfo.
 func NewEndpointInfo(baseInfo *k8sproxy.BaseEndpointInfo) k8sproxy.Endpoint {
 	return baseInfo
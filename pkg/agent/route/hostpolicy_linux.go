@@ -0,0 +1,525 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/ipset"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/nflog"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+const (
+	// Antrea managed iptables chains enforcing the policy rules applied to the Node on its host traffic.
+	antreaHostIngressChain         = "ANTREA-HOST-INGRESS"
+	antreaHostIngressBaselineChain = "ANTREA-HOST-INGRESS-BASELINE"
+	antreaHostEgressChain          = "ANTREA-HOST-EGRESS"
+	antreaHostEgressBaselineChain  = "ANTREA-HOST-EGRESS-BASELINE"
+
+	// Antrea managed ipsets containing the peer addresses of a host policy rule, suffixed with the rule's FlowID.
+	hostPolicyIPSetPrefix  = "ANTREA-HP-"
+	hostPolicyIP6SetPrefix = "ANTREA-HP6-"
+
+	// hostPolicyRuleCommentPrefix is used to identify the iptables rules of a host policy rule when collecting
+	// their statistics.
+	hostPolicyRuleCommentPrefix = "Antrea: host policy rule "
+
+	// The packets matched by the host policy rules with logging enabled are sent to this NFLOG group, with the prefix
+	// "Antrea:<FlowID>:<action>". The Antrea Agent configures the group to only copy the headers of the packets.
+	hostPolicyNFLogGroup               = 61
+	hostPolicyNFLogCopyRange           = 128
+	hostPolicyNFLogPrefix              = "Antrea:"
+	hostPolicyNFLogResubscribeInterval = 5 * time.Second
+
+	baselineTierPriority int32 = 253
+)
+
+var hostPolicyRuleCommentPattern = regexp.MustCompile(hostPolicyRuleCommentPrefix + `(\d+)`)
+
+// hostPolicyRule is a policy rule enforced on the host traffic of the Node.
+type hostPolicyRule struct {
+	*types.PolicyRule
+	priority types.Priority
+	// restricted tells whether the rule only applies to the peers in peerIPs and peerIP6s.
+	// Otherwise the rule applies to any peer.
+	restricted bool
+	peerIPs    sets.String
+	peerIP6s   sets.String
+}
+
+func newHostPolicyRule(rule *types.PolicyRule, priority types.Priority) *hostPolicyRule {
+	peers := rule.From
+	if rule.Direction == v1beta2.DirectionOut {
+		peers = rule.To
+	}
+	r := &hostPolicyRule{
+		PolicyRule: rule,
+		priority:   priority,
+		// A nil slice means the rule is not restricted by peer addresses.
+		restricted: peers != nil,
+		peerIPs:    sets.NewString(),
+		peerIP6s:   sets.NewString(),
+	}
+	for _, peer := range peers {
+		var entry string
+		var isIPv6 bool
+		switch v := peer.GetValue().(type) {
+		case net.IP:
+			entry, isIPv6 = v.String(), v.To4() == nil
+		case net.IPNet:
+			entry, isIPv6 = v.String(), v.IP.To4() == nil
+		default:
+			continue
+		}
+		if isIPv6 {
+			r.peerIP6s.Insert(entry)
+		} else {
+			r.peerIPs.Insert(entry)
+		}
+	}
+	return r
+}
+
+func (r *hostPolicyRule) isBaseline() bool {
+	return r.priority.TierPriority == baselineTierPriority
+}
+
+func (r *hostPolicyRule) action() secv1alpha1.RuleAction {
+	if r.Action != nil {
+		return *r.Action
+	}
+	return secv1alpha1.RuleActionAllow
+}
+
+// chain returns the iptables chain where the rule is enforced.
+func (r *hostPolicyRule) chain() string {
+	switch {
+	case r.Direction == v1beta2.DirectionIn && r.isBaseline():
+		return antreaHostIngressBaselineChain
+	case r.Direction == v1beta2.DirectionIn:
+		return antreaHostIngressChain
+	case r.isBaseline():
+		return antreaHostEgressBaselineChain
+	default:
+		return antreaHostEgressChain
+	}
+}
+
+// namedPorts returns the named ports of the rule, which are not supported as they cannot be resolved for the Node.
+func (r *hostPolicyRule) namedPorts() []string {
+	var ports []string
+	for _, svc := range r.Service {
+		if svc.Port != nil && svc.Port.Type == intstr.String {
+			ports = append(ports, svc.Port.StrVal)
+		}
+	}
+	return ports
+}
+
+func getHostPolicyIPSetName(flowID uint32, isIPv6 bool) string {
+	if isIPv6 {
+		return hostPolicyIP6SetPrefix + strconv.FormatUint(uint64(flowID), 10)
+	}
+	return hostPolicyIPSetPrefix + strconv.FormatUint(uint64(flowID), 10)
+}
+
+// InstallHostPolicyRule enforces the provided policy rule on the host traffic of the Node with iptables. The peer
+// addresses of the rule are maintained in ipsets, while the iptables chains are rebuilt from all the cached rules so
+// that they are always ordered by priority. The chains are only built when iptables has been initialized, otherwise
+// they will be built by the initialization. The rules with named ports, which are rejected by the validation of the
+// policies applied to Nodes, cannot be installed.
+func (c *Client) InstallHostPolicyRule(rule *types.PolicyRule, priority types.Priority) error {
+	c.hostPolicyMutex.Lock()
+	defer c.hostPolicyMutex.Unlock()
+
+	newRule := newHostPolicyRule(rule, priority)
+	if namedPorts := newRule.namedPorts(); len(namedPorts) > 0 {
+		return fmt.Errorf("named ports %v of host policy rule %d of %s are not supported for Nodes", namedPorts, rule.FlowID, rule.PolicyRef.ToString())
+	}
+	oldRule, exists := c.hostPolicyRules[rule.FlowID]
+	if newRule.restricted {
+		if err := syncHostPolicyIPSets(newRule, oldRule); err != nil {
+			return err
+		}
+	}
+	if c.hostPolicyRules == nil {
+		c.hostPolicyRules = map[uint32]*hostPolicyRule{}
+	}
+	c.hostPolicyRules[rule.FlowID] = newRule
+	if err := c.syncHostPolicyChains(); err != nil {
+		if exists {
+			c.hostPolicyRules[rule.FlowID] = oldRule
+		} else {
+			delete(c.hostPolicyRules, rule.FlowID)
+		}
+		return err
+	}
+	if exists && oldRule.restricted && !newRule.restricted {
+		if err := destroyHostPolicyIPSets(rule.FlowID); err != nil {
+			return err
+		}
+	}
+	klog.V(2).Infof("Installed host policy rule %d of %s", rule.FlowID, rule.PolicyRef.ToString())
+	return nil
+}
+
+// UninstallHostPolicyRule removes the policy rule identified by the provided FlowID from the host network. It does
+// nothing if the rule doesn't exist.
+func (c *Client) UninstallHostPolicyRule(flowID uint32) error {
+	c.hostPolicyMutex.Lock()
+	defer c.hostPolicyMutex.Unlock()
+
+	rule, exists := c.hostPolicyRules[flowID]
+	if !exists {
+		return nil
+	}
+	delete(c.hostPolicyRules, flowID)
+	if err := c.syncHostPolicyChains(); err != nil {
+		c.hostPolicyRules[flowID] = rule
+		return err
+	}
+	delete(c.hostPolicyMetrics, flowID)
+	// The ipsets can only be destroyed after they are no longer referenced by iptables.
+	if rule.restricted {
+		if err := destroyHostPolicyIPSets(flowID); err != nil {
+			return err
+		}
+	}
+	klog.V(2).Infof("Uninstalled host policy rule %d", flowID)
+	return nil
+}
+
+// HostPolicyRuleMetrics returns the statistics of the policy rules enforced on the host network. As established
+// connections are accepted before the policy rules are evaluated, only the first packet of each connection is
+// counted, hence the number of sessions equals the number of packets.
+func (c *Client) HostPolicyRuleMetrics() map[uint32]*types.RuleMetric {
+	c.hostPolicyMutex.Lock()
+	defer c.hostPolicyMutex.Unlock()
+
+	current, err := c.getHostPolicyCounters()
+	if err != nil {
+		klog.Errorf("Failed to get statistics of host policy rules: %v", err)
+	}
+	metrics := make(map[uint32]*types.RuleMetric, len(c.hostPolicyRules))
+	for flowID := range c.hostPolicyRules {
+		metric := &types.RuleMetric{}
+		if m, exists := c.hostPolicyMetrics[flowID]; exists {
+			metric.Merge(m)
+		}
+		if m, exists := current[flowID]; exists {
+			metric.Merge(m)
+		}
+		metrics[flowID] = metric
+	}
+	return metrics
+}
+
+// syncHostPolicyIPSets makes the ipsets of the new rule contain exactly its peer addresses. The entries of the old
+// rule are used as the actual state if it was restricted, otherwise the ipsets are listed.
+func syncHostPolicyIPSets(newRule, oldRule *hostPolicyRule) error {
+	for _, isIPv6 := range []bool{false, true} {
+		name := getHostPolicyIPSetName(newRule.FlowID, isIPv6)
+		desired := newRule.peerIPs
+		if isIPv6 {
+			desired = newRule.peerIP6s
+		}
+		var actual sets.String
+		if oldRule != nil && oldRule.restricted {
+			actual = oldRule.peerIPs
+			if isIPv6 {
+				actual = oldRule.peerIP6s
+			}
+		} else {
+			if err := ipset.CreateIPSet(name, ipset.HashNet, isIPv6); err != nil {
+				return err
+			}
+			entries, err := ipset.ListEntries(name)
+			if err != nil {
+				return err
+			}
+			actual = sets.NewString(entries...)
+		}
+		for entry := range desired.Difference(actual) {
+			if err := ipset.AddEntry(name, entry); err != nil {
+				return err
+			}
+		}
+		for entry := range actual.Difference(desired) {
+			if err := ipset.DelEntry(name, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func destroyHostPolicyIPSets(flowID uint32) error {
+	for _, isIPv6 := range []bool{false, true} {
+		if err := ipset.DestroyIPSet(getHostPolicyIPSetName(flowID, isIPv6)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncHostPolicyChains rebuilds the host policy chains from the cached rules. It does nothing if iptables is not
+// initialized yet, as the initialization builds the chains. It must be called with hostPolicyMutex held.
+func (c *Client) syncHostPolicyChains() error {
+	if c.ipt == nil {
+		return nil
+	}
+	// Rebuilding the chains resets the counters of their rules, accumulate them first.
+	current, err := c.getHostPolicyCounters()
+	if err != nil {
+		return err
+	}
+	if c.hostPolicyMetrics == nil {
+		c.hostPolicyMetrics = map[uint32]*types.RuleMetric{}
+	}
+	for flowID, metric := range current {
+		if m, exists := c.hostPolicyMetrics[flowID]; exists {
+			m.Merge(metric)
+		} else {
+			c.hostPolicyMetrics[flowID] = metric
+		}
+	}
+
+	for _, isIPv6 := range []bool{false, true} {
+		if isIPv6 && !config.IsIPv6Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode) ||
+			!isIPv6 && !config.IsIPv4Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode) {
+			continue
+		}
+		iptablesData := bytes.NewBuffer(nil)
+		writeLine(iptablesData, "*filter")
+		writeHostPolicyChains(iptablesData, c.hostPolicyRules, isIPv6)
+		writeLine(iptablesData, "COMMIT")
+		// Setting --noflush to only flush the host policy chains.
+		if err := c.ipt.Restore(iptablesData.Bytes(), false, isIPv6); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getHostPolicyCounters returns the counters of the iptables rules of each host policy rule since the host policy
+// chains were built last time.
+func (c *Client) getHostPolicyCounters() (map[uint32]*types.RuleMetric, error) {
+	counters := map[uint32]*types.RuleMetric{}
+	if c.ipt == nil {
+		return counters, nil
+	}
+	for _, chain := range []string{antreaHostIngressChain, antreaHostIngressBaselineChain, antreaHostEgressChain, antreaHostEgressBaselineChain} {
+		stats, err := c.ipt.Stats(iptables.FilterTable, chain)
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range stats {
+			match := hostPolicyRuleCommentPattern.FindStringSubmatch(stat.Options)
+			if match == nil {
+				continue
+			}
+			flowID, err := strconv.ParseUint(match[1], 10, 32)
+			if err != nil {
+				continue
+			}
+			// The packets logged by the NFLOG rules are counted again by the rules taking the actions, except for
+			// the Audit rules which only log the packets.
+			if stat.Target == iptables.NFLOGTarget {
+				if rule, exists := c.hostPolicyRules[uint32(flowID)]; !exists || rule.action() != secv1alpha1.RuleActionAudit {
+					continue
+				}
+			}
+			metric, exists := counters[uint32(flowID)]
+			if !exists {
+				metric = &types.RuleMetric{}
+				counters[uint32(flowID)] = metric
+			}
+			metric.Merge(&types.RuleMetric{Bytes: stat.Bytes, Packets: stat.Packets, Sessions: stat.Packets})
+		}
+	}
+	return counters, nil
+}
+
+// writeHostPolicyChains writes the host policy chains of the provided IP family to the iptablesData buffer. Rules of
+// higher precedence are evaluated first and the baseline Tier rules are only evaluated if no other rule is matched.
+// Packets allowed by the rules are returned to the built-in chains so that other firewall rules of the host still
+// apply to them.
+func writeHostPolicyChains(iptablesData *bytes.Buffer, rules map[uint32]*hostPolicyRule, isIPv6 bool) {
+	writeLine(iptablesData, iptables.MakeChainLine(antreaHostIngressChain))
+	writeLine(iptablesData, iptables.MakeChainLine(antreaHostIngressBaselineChain))
+	writeLine(iptablesData, iptables.MakeChainLine(antreaHostEgressChain))
+	writeLine(iptablesData, iptables.MakeChainLine(antreaHostEgressBaselineChain))
+
+	var ingressRules, egressRules []*hostPolicyRule
+	for _, rule := range rules {
+		if rule.Direction == v1beta2.DirectionIn {
+			ingressRules = append(ingressRules, rule)
+		} else {
+			egressRules = append(egressRules, rule)
+		}
+	}
+	writeHostPolicyDirection(iptablesData, ingressRules, antreaHostIngressChain, antreaHostIngressBaselineChain, "-i", isIPv6)
+	writeHostPolicyDirection(iptablesData, egressRules, antreaHostEgressChain, antreaHostEgressBaselineChain, "-o", isIPv6)
+}
+
+func writeHostPolicyDirection(iptablesData *bytes.Buffer, rules []*hostPolicyRule, chain, baselineChain, ifaceOption string, isIPv6 bool) {
+	if len(rules) == 0 {
+		return
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].priority.Equals(rules[j].priority) {
+			return rules[i].FlowID < rules[j].FlowID
+		}
+		return rules[j].priority.Less(rules[i].priority)
+	})
+	// Antrea tunnel packets are not tracked, they must not be subject to the policy rules.
+	writeLine(iptablesData, []string{
+		"-A", chain,
+		"-m", "comment", "--comment", `"Antrea: skip packets of established connections"`,
+		"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED,UNTRACKED",
+		"-j", iptables.ReturnTarget,
+	}...)
+	writeLine(iptablesData, []string{
+		"-A", chain,
+		"-m", "comment", "--comment", `"Antrea: skip loopback packets"`,
+		ifaceOption, "lo",
+		"-j", iptables.ReturnTarget,
+	}...)
+	for _, rule := range rules {
+		writeHostPolicyRule(iptablesData, rule, isIPv6)
+	}
+	writeLine(iptablesData, []string{
+		"-A", chain,
+		"-m", "comment", "--comment", `"Antrea: jump to baseline host policy rules"`,
+		"-j", baselineChain,
+	}...)
+}
+
+// writeHostPolicyRule writes the iptables rules of a host policy rule to its chain. Audit rules only log the packets,
+// which are then evaluated by the following rules.
+func writeHostPolicyRule(iptablesData *bytes.Buffer, rule *hostPolicyRule, isIPv6 bool) {
+	matches := []string{
+		"-m", "comment", "--comment", fmt.Sprintf(`"%s%d"`, hostPolicyRuleCommentPrefix, rule.FlowID),
+	}
+	if rule.restricted {
+		direction := "src"
+		if rule.Direction == v1beta2.DirectionOut {
+			direction = "dst"
+		}
+		matches = append(matches, "-m", "set", "--match-set", getHostPolicyIPSetName(rule.FlowID, isIPv6), direction)
+	}
+
+	action := rule.action()
+	var target []string
+	switch action {
+	case secv1alpha1.RuleActionDrop:
+		target = []string{"-j", iptables.DropTarget}
+	case secv1alpha1.RuleActionAudit:
+		// The NFLOG target doesn't terminate the evaluation of the packets.
+	default:
+		target = []string{"-j", iptables.ReturnTarget}
+	}
+	logging := rule.EnableLogging || action == secv1alpha1.RuleActionAudit
+
+	var serviceMatches [][]string
+	for _, svc := range rule.Service {
+		protocol := v1beta2.ProtocolTCP
+		if svc.Protocol != nil {
+			protocol = *svc.Protocol
+		}
+		serviceMatch := []string{"-p", strings.ToLower(string(protocol))}
+		if svc.Port != nil {
+			serviceMatch = append(serviceMatch, "--dport", strconv.Itoa(int(svc.Port.IntVal)))
+		}
+		serviceMatches = append(serviceMatches, serviceMatch)
+	}
+	if len(rule.Service) == 0 {
+		serviceMatches = [][]string{nil}
+	}
+
+	for _, serviceMatch := range serviceMatches {
+		spec := append([]string{"-A", rule.chain()}, matches...)
+		spec = append(spec, serviceMatch...)
+		if logging {
+			writeLine(iptablesData, append(spec,
+				"-j", iptables.NFLOGTarget,
+				"--nflog-group", strconv.Itoa(hostPolicyNFLogGroup),
+				"--nflog-prefix", fmt.Sprintf(`"%s%d:%s"`, hostPolicyNFLogPrefix, rule.FlowID, action))...)
+		}
+		if target != nil {
+			writeLine(iptablesData, append(spec, target...)...)
+		}
+	}
+}
+
+// ReceiveHostPolicyLogs calls the handler with the packets logged by the host policy rules until stopCh is closed.
+func (c *Client) ReceiveHostPolicyLogs(stopCh <-chan struct{}, handler func(log *types.HostPolicyLog)) {
+	wait.Until(func() {
+		err := nflog.Subscribe(hostPolicyNFLogGroup, hostPolicyNFLogCopyRange, stopCh, func(packet *nflog.Packet) {
+			if log := c.getHostPolicyLog(packet); log != nil {
+				handler(log)
+			}
+		})
+		if err != nil {
+			klog.Errorf("Failed to receive packets logged by host policy rules: %v", err)
+		}
+	}, hostPolicyNFLogResubscribeInterval, stopCh)
+}
+
+// getHostPolicyLog returns the log of a packet logged by a host policy rule, or nil if the rule doesn't exist anymore.
+func (c *Client) getHostPolicyLog(packet *nflog.Packet) *types.HostPolicyLog {
+	fields := strings.Split(strings.TrimPrefix(packet.Prefix, hostPolicyNFLogPrefix), ":")
+	if !strings.HasPrefix(packet.Prefix, hostPolicyNFLogPrefix) || len(fields) != 2 {
+		klog.V(2).Infof("Ignoring packet logged with unknown prefix %q", packet.Prefix)
+		return nil
+	}
+	flowID, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		klog.V(2).Infof("Ignoring packet logged with unknown prefix %q", packet.Prefix)
+		return nil
+	}
+
+	c.hostPolicyMutex.Lock()
+	defer c.hostPolicyMutex.Unlock()
+	rule, exists := c.hostPolicyRules[uint32(flowID)]
+	if !exists {
+		klog.V(2).Infof("Ignoring packet logged by host policy rule %d which was uninstalled", flowID)
+		return nil
+	}
+	return &types.HostPolicyLog{
+		Chain:       rule.chain(),
+		PolicyRef:   rule.PolicyRef,
+		Disposition: fields[1],
+		Priority:    rule.priority,
+		Packet:      packet.Payload,
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/nflog"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func TestWriteHostPolicyChains(t *testing.T) {
+	actionAllow := secv1alpha1.RuleActionAllow
+	actionDrop := secv1alpha1.RuleActionDrop
+	actionAudit := secv1alpha1.RuleActionAudit
+	protocolUDP := v1beta2.ProtocolUDP
+	port22 := intstr.FromInt(22)
+	port53 := intstr.FromInt(53)
+	_, cidr, _ := net.ParseCIDR("10.10.0.0/16")
+
+	rules := map[uint32]*hostPolicyRule{
+		1: newHostPolicyRule(&types.PolicyRule{
+			Direction:     v1beta2.DirectionIn,
+			From:          []types.Address{openflow.NewIPNetAddress(*cidr), openflow.NewIPAddress(net.ParseIP("fd00::1"))},
+			Service:       []v1beta2.Service{{Port: &port22}},
+			Action:        &actionAllow,
+			FlowID:        1,
+			EnableLogging: true,
+		}, types.Priority{TierPriority: 250, PolicyPriority: 1, RulePriority: 0}),
+		2: newHostPolicyRule(&types.PolicyRule{
+			Direction: v1beta2.DirectionIn,
+			Action:    &actionDrop,
+			FlowID:    2,
+		}, types.Priority{TierPriority: baselineTierPriority, PolicyPriority: 1, RulePriority: 0}),
+		3: newHostPolicyRule(&types.PolicyRule{
+			Direction: v1beta2.DirectionIn,
+			From:      []types.Address{},
+			Action:    &actionDrop,
+			FlowID:    3,
+		}, types.Priority{TierPriority: 100, PolicyPriority: 5, RulePriority: 0}),
+		4: newHostPolicyRule(&types.PolicyRule{
+			Direction: v1beta2.DirectionOut,
+			Service:   []v1beta2.Service{{Protocol: &protocolUDP, Port: &port53}, {Protocol: &protocolUDP}},
+			Action:    &actionAudit,
+			FlowID:    4,
+		}, types.Priority{TierPriority: 250, PolicyPriority: 1, RulePriority: 1}),
+		5: newHostPolicyRule(&types.PolicyRule{
+			Direction: v1beta2.DirectionOut,
+			Service:   []v1beta2.Service{{Protocol: &protocolUDP}},
+			Action:    &actionDrop,
+			FlowID:    5,
+		}, types.Priority{TierPriority: 250, PolicyPriority: 2, RulePriority: 0}),
+	}
+
+	assert.Equal(t, []string{"10.10.0.0/16"}, rules[1].peerIPs.List())
+	assert.Equal(t, []string{"fd00::1"}, rules[1].peerIP6s.List())
+	assert.False(t, rules[2].restricted)
+	assert.True(t, rules[3].restricted)
+
+	expected := `:ANTREA-HOST-INGRESS - [0:0]
+:ANTREA-HOST-INGRESS-BASELINE - [0:0]
+:ANTREA-HOST-EGRESS - [0:0]
+:ANTREA-HOST-EGRESS-BASELINE - [0:0]
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: skip packets of established connections" -m conntrack --ctstate ESTABLISHED,RELATED,UNTRACKED -j RETURN
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: skip loopback packets" -i lo -j RETURN
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: host policy rule 3" -m set --match-set ANTREA-HP-3 src -j DROP
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: host policy rule 1" -m set --match-set ANTREA-HP-1 src -p tcp --dport 22 -j NFLOG --nflog-group 61 --nflog-prefix "Antrea:1:Allow"
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: host policy rule 1" -m set --match-set ANTREA-HP-1 src -p tcp --dport 22 -j RETURN
+-A ANTREA-HOST-INGRESS-BASELINE -m comment --comment "Antrea: host policy rule 2" -j DROP
+-A ANTREA-HOST-INGRESS -m comment --comment "Antrea: jump to baseline host policy rules" -j ANTREA-HOST-INGRESS-BASELINE
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: skip packets of established connections" -m conntrack --ctstate ESTABLISHED,RELATED,UNTRACKED -j RETURN
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: skip loopback packets" -o lo -j RETURN
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: host policy rule 4" -p udp --dport 53 -j NFLOG --nflog-group 61 --nflog-prefix "Antrea:4:Audit"
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: host policy rule 4" -p udp -j NFLOG --nflog-group 61 --nflog-prefix "Antrea:4:Audit"
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: host policy rule 5" -p udp -j DROP
+-A ANTREA-HOST-EGRESS -m comment --comment "Antrea: jump to baseline host policy rules" -j ANTREA-HOST-EGRESS-BASELINE
+`
+	buf := bytes.NewBuffer(nil)
+	writeHostPolicyChains(buf, rules, false)
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	writeHostPolicyChains(buf, rules, true)
+	assert.Contains(t, buf.String(), "--match-set ANTREA-HP6-1 src")
+}
+
+func TestInstallHostPolicyRuleWithNamedPort(t *testing.T) {
+	actionAllow := secv1alpha1.RuleActionAllow
+	port22 := intstr.FromInt(22)
+	portHTTP := intstr.FromString("http")
+	c := &Client{}
+	err := c.InstallHostPolicyRule(&types.PolicyRule{
+		Direction: v1beta2.DirectionIn,
+		Service:   []v1beta2.Service{{Port: &port22}, {Port: &portHTTP}},
+		Action:    &actionAllow,
+		FlowID:    1,
+		PolicyRef: &v1beta2.NetworkPolicyReference{Type: v1beta2.AntreaClusterNetworkPolicy, Name: "cnp1"},
+	}, types.Priority{TierPriority: 250, PolicyPriority: 1, RulePriority: 0})
+	assert.Error(t, err)
+	assert.Empty(t, c.hostPolicyRules)
+}
+
+func TestWriteHostPolicyChainsWithoutRules(t *testing.T) {
+	expected := `:ANTREA-HOST-INGRESS - [0:0]
+:ANTREA-HOST-INGRESS-BASELINE - [0:0]
+:ANTREA-HOST-EGRESS - [0:0]
+:ANTREA-HOST-EGRESS-BASELINE - [0:0]
+`
+	buf := bytes.NewBuffer(nil)
+	writeHostPolicyChains(buf, nil, false)
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestGetHostPolicyLog(t *testing.T) {
+	actionAudit := secv1alpha1.RuleActionAudit
+	policyRef := &v1beta2.NetworkPolicyReference{Type: v1beta2.AntreaClusterNetworkPolicy, Name: "cnp1"}
+	priority := types.Priority{TierPriority: baselineTierPriority, PolicyPriority: 1, RulePriority: 0}
+	c := &Client{
+		hostPolicyRules: map[uint32]*hostPolicyRule{
+			1: newHostPolicyRule(&types.PolicyRule{
+				Direction: v1beta2.DirectionOut,
+				Action:    &actionAudit,
+				FlowID:    1,
+				PolicyRef: policyRef,
+			}, priority),
+		},
+	}
+	payload := []byte{0x45, 0x00, 0x00, 0x54}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   *types.HostPolicyLog
+	}{
+		{
+			name:   "existing rule",
+			prefix: "Antrea:1:Audit",
+			want: &types.HostPolicyLog{
+				Chain:       antreaHostEgressBaselineChain,
+				PolicyRef:   policyRef,
+				Disposition: "Audit",
+				Priority:    priority,
+				Packet:      payload,
+			},
+		},
+		{
+			name:   "uninstalled rule",
+			prefix: "Antrea:2:Drop",
+		},
+		{
+			name:   "unknown prefix",
+			prefix: "Antrea:foo",
+		},
+		{
+			name:   "other prefix",
+			prefix: "1:Drop",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.getHostPolicyLog(&nflog.Packet{Prefix: tt.prefix, Payload: payload}))
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"net"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 )
 
 // Interface is the interface for routing container packets in host network.
@@ -38,10 +39,35 @@ type Interface interface {
 	// It should do nothing if the routes don't exist, without error.
 	DeleteRoutes(podCIDR *net.IPNet) error
 
+	// AddLocalPodRoutes should route the IPs of a local Pod which are not allocated from the PodCIDRs of the Node,
+	// e.g. by Antrea IPAM, to the host gateway. It should do nothing for the IPs allocated from the PodCIDRs.
+	// It should override the routes if they already exist, without error.
+	AddLocalPodRoutes(podIPs []net.IP) error
+
+	// DeleteLocalPodRoutes should delete the routes added by AddLocalPodRoutes for the IPs of a local Pod.
+	// It should do nothing if the routes don't exist, without error.
+	DeleteLocalPodRoutes(podIPs []net.IP) error
+
 	// MigrateRoutesToGw should move routes from device linkname to local gateway.
 	MigrateRoutesToGw(linkName string) error
 
 	// UnMigrateRoutesFromGw should move routes back from local gateway to original device linkName
 	// if linkName is nil, it should remove the routes.
 	UnMigrateRoutesFromGw(route *net.IPNet, linkName string) error
+
+	// InstallHostPolicyRule should enforce the provided policy rule on the traffic sent and received by the host
+	// network of the Node. The rule is identified by its FlowID. It should override the rule if it already exists.
+	InstallHostPolicyRule(rule *types.PolicyRule, priority types.Priority) error
+
+	// UninstallHostPolicyRule should remove the policy rule identified by the provided FlowID from the host network.
+	// It should do nothing if the rule doesn't exist, without error.
+	UninstallHostPolicyRule(flowID uint32) error
+
+	// HostPolicyRuleMetrics should return the statistics of the policy rules enforced on the host network, keyed by
+	// their FlowIDs.
+	HostPolicyRuleMetrics() map[uint32]*types.RuleMetric
+
+	// ReceiveHostPolicyLogs should call the handler with the packets logged by the policy rules enforced on the host
+	// network until stopCh is closed.
+	ReceiveHostPolicyLogs(stopCh <-chan struct{}, handler func(log *types.HostPolicyLog))
 }
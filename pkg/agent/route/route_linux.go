@@ -30,9 +30,11 @@ import (
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/ipset"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/iptables"
+	"github.com/vmware-tanzu/antrea/pkg/agent/util/sysctl"
 	"github.com/vmware-tanzu/antrea/pkg/ovs/ovsconfig"
 	"github.com/vmware-tanzu/antrea/pkg/util/env"
 )
@@ -70,21 +72,39 @@ type Client struct {
 	networkConfig *config.NetworkConfig
 	noSNAT        bool
 	serviceCIDR   *net.IPNet
-	ipt           *iptables.Client
+	serviceCIDRv6 *net.IPNet
+	// proxyAll indicates whether the Service traffic from the host network is routed to AntreaProxy.
+	proxyAll bool
+	ipt      *iptables.Client
 	// nodeRoutes caches ip routes to remote Pods. It's a map of podCIDR to routes.
 	nodeRoutes sync.Map
 	// nodeNeighbors caches IPv6 Neighbors to remote host gateway
 	nodeNeighbors sync.Map
+	// serviceRoutes caches ip routes of the Service CIDRs and the virtual Service IPs when proxyAll is enabled. It's a
+	// map of destination to route.
+	serviceRoutes sync.Map
+	// localPodRoutes caches ip routes to the local Pods whose IPs are not allocated from the PodCIDRs of the Node, e.g.
+	// by Antrea IPAM. It's a map of destination to route.
+	localPodRoutes sync.Map
+	// hostPolicyMutex protects ipt, hostPolicyRules and hostPolicyMetrics, as the host policy rules can be installed
+	// before iptables is initialized.
+	hostPolicyMutex sync.Mutex
+	// hostPolicyRules caches the policy rules enforced on the host traffic. It's a map of FlowID to *hostPolicyRule.
+	hostPolicyRules map[uint32]*hostPolicyRule
+	// hostPolicyMetrics caches the statistics of the host policy rules that were reset by rebuilding the chains.
+	hostPolicyMetrics map[uint32]*types.RuleMetric
 }
 
 // NewClient returns a route client.
-// TODO: remove param serviceCIDR after kube-proxy is replaced by Antrea Proxy. This param is not used in this file;
-// leaving it here is to be compatible with the implementation on Windows.
-func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool) (*Client, error) {
+// The Service CIDRs are only used when proxyAll is enabled, to route the Service traffic from the host network to
+// AntreaProxy.
+func NewClient(serviceCIDR, serviceCIDRv6 *net.IPNet, networkConfig *config.NetworkConfig, noSNAT, proxyAll bool) (*Client, error) {
 	return &Client{
 		serviceCIDR:   serviceCIDR,
+		serviceCIDRv6: serviceCIDRv6,
 		networkConfig: networkConfig,
 		noSNAT:        noSNAT,
+		proxyAll:      proxyAll,
 	}, nil
 }
 
@@ -184,6 +204,9 @@ func (c *Client) writeEKSMangleRule(iptablesData *bytes.Buffer) {
 // initIPTables ensure that the iptables infrastructure we use is set up.
 // It's idempotent and can safely be called on every startup.
 func (c *Client) initIPTables() error {
+	c.hostPolicyMutex.Lock()
+	defer c.hostPolicyMutex.Unlock()
+
 	var err error
 	v4Enabled := config.IsIPv4Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
 	v6Enabled := config.IsIPv6Enabled(c.nodeConfig, c.networkConfig.TrafficEncapMode)
@@ -198,6 +221,8 @@ func (c *Client) initIPTables() error {
 		{iptables.RawTable, iptables.PreRoutingChain, antreaPreRoutingChain, "Antrea: jump to Antrea prerouting rules"},
 		{iptables.RawTable, iptables.OutputChain, antreaOutputChain, "Antrea: jump to Antrea output rules"},
 		{iptables.FilterTable, iptables.ForwardChain, antreaForwardChain, "Antrea: jump to Antrea forwarding rules"},
+		{iptables.FilterTable, iptables.InputChain, antreaHostIngressChain, "Antrea: jump to Antrea host ingress policy rules"},
+		{iptables.FilterTable, iptables.OutputChain, antreaHostEgressChain, "Antrea: jump to Antrea host egress policy rules"},
 		{iptables.NATTable, iptables.PostRoutingChain, antreaPostRoutingChain, "Antrea: jump to Antrea postrouting rules"},
 		{iptables.MangleTable, iptables.PreRoutingChain, antreaMangleChain, "Antrea: jump to Antrea mangle rules"},
 	}
@@ -213,7 +238,7 @@ func (c *Client) initIPTables() error {
 
 	// Use iptables-restore to configure IPv4 settings.
 	if v4Enabled {
-		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv4CIDR, antreaPodIPSet)
+		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv4CIDR, antreaPodIPSet, false)
 		// Setting --noflush to keep the previous contents (i.e. non antrea managed chains) of the tables.
 		if err := c.ipt.Restore(iptablesData.Bytes(), false, false); err != nil {
 			return err
@@ -222,7 +247,7 @@ func (c *Client) initIPTables() error {
 
 	// Use ip6tables-restore to configure IPv6 settings.
 	if v6Enabled {
-		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv6CIDR, antreaPodIP6Set)
+		iptablesData := c.restoreIptablesData(c.nodeConfig.PodIPv6CIDR, antreaPodIP6Set, true)
 		// Setting --noflush to keep the previous contents (i.e. non antrea managed chains) of the tables.
 		if err := c.ipt.Restore(iptablesData.Bytes(), false, true); err != nil {
 			return err
@@ -231,7 +256,7 @@ func (c *Client) initIPTables() error {
 	return nil
 }
 
-func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes.Buffer {
+func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string, isIPv6 bool) *bytes.Buffer {
 	// Create required rules in the antrea chains.
 	// Use iptables-restore as it flushes the involved chains and creates the desired rules
 	// with a single call, instead of string matching to clean up stale rules.
@@ -295,6 +320,8 @@ func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes
 		"-o", hostGateway,
 		"-j", iptables.AcceptTarget,
 	}...)
+	// Write the host policy chains anyway so the stale rules can be deleted on startup.
+	writeHostPolicyChains(iptablesData, c.hostPolicyRules, isIPv6)
 	writeLine(iptablesData, "COMMIT")
 
 	writeLine(iptablesData, "*nat")
@@ -307,6 +334,32 @@ func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet string) *bytes
 			"-j", iptables.MasqueradeTarget,
 		}...)
 	}
+	if c.proxyAll {
+		serviceCIDR, virtualServiceIP := c.serviceCIDR, config.VirtualServiceIPv4
+		if isIPv6 {
+			serviceCIDR, virtualServiceIP = c.serviceCIDRv6, config.VirtualServiceIPv6
+		}
+		if serviceCIDR != nil {
+			// The host network selects the IP of the host gateway as the source IP of the Service traffic
+			// routed to the host gateway, unless the process binds to another local IP. The replies to
+			// other local IPs would not go back to OVS, so the traffic must be masqueraded.
+			writeLine(iptablesData, []string{
+				"-A", antreaPostRoutingChain,
+				"-m", "comment", "--comment", `"Antrea: masquerade host to Service packets"`,
+				"-d", serviceCIDR.String(), "-o", hostGateway,
+				"-m", "addrtype", "--src-type", "LOCAL",
+				"-j", iptables.MasqueradeTarget,
+			}...)
+		}
+		// The Service traffic from the host network which is sent back to the host gateway by AntreaProxy is
+		// SNAT'd with the virtual Service IP. It must be masqueraded if it's forwarded to another Node.
+		writeLine(iptablesData, []string{
+			"-A", antreaPostRoutingChain,
+			"-m", "comment", "--comment", `"Antrea: masquerade virtual Service IP packets"`,
+			"-s", virtualServiceIP.String(), "!", "-o", hostGateway,
+			"-j", iptables.MasqueradeTarget,
+		}...)
+	}
 	writeLine(iptablesData, "COMMIT")
 	return iptablesData
 }
@@ -319,6 +372,66 @@ func (c *Client) initIPRoutes() error {
 			return fmt.Errorf("failed to add address %s to gw %s: %v", gwIP, gwLink.Attrs().Name, err)
 		}
 	}
+	if c.proxyAll {
+		if c.nodeConfig.PodIPv6CIDR != nil && c.serviceCIDRv6 == nil {
+			// The IPv6 Service traffic from the host network wouldn't be routed to AntreaProxy.
+			return fmt.Errorf("serviceCIDRv6 must be set when proxyAll is enabled on a Node with an IPv6 PodCIDR")
+		}
+		gatewayConfig := c.nodeConfig.GatewayConfig
+		if c.nodeConfig.PodIPv4CIDR != nil && c.serviceCIDR != nil {
+			if err := c.addServiceRoutes(c.serviceCIDR, config.VirtualServiceIPv4, gatewayConfig.IPv4); err != nil {
+				return err
+			}
+		}
+		if c.nodeConfig.PodIPv6CIDR != nil && c.serviceCIDRv6 != nil {
+			if err := c.addServiceRoutes(c.serviceCIDRv6, config.VirtualServiceIPv6, gatewayConfig.IPv6); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addServiceRoutes routes the Service CIDR to the host gateway, with the virtual Service IP as the next hop, so that
+// the Service traffic from the host network is load-balanced by AntreaProxy. The virtual Service IP is resolved to
+// the MAC of the host gateway with a permanent neighbor, and is routed to the host gateway as well, as it's the
+// source IP of the Service traffic sent back to the host network by AntreaProxy.
+func (c *Client) addServiceRoutes(serviceCIDR *net.IPNet, virtualServiceIP, gatewayIP net.IP) error {
+	gatewayConfig := c.nodeConfig.GatewayConfig
+	family, mask := netlink.FAMILY_V4, net.CIDRMask(32, 32)
+	if virtualServiceIP.To4() == nil {
+		family, mask = netlink.FAMILY_V6, net.CIDRMask(128, 128)
+	}
+	neigh := &netlink.Neigh{
+		LinkIndex:    gatewayConfig.LinkIndex,
+		Family:       family,
+		State:        netlink.NUD_PERMANENT,
+		IP:           virtualServiceIP,
+		HardwareAddr: gatewayConfig.MAC,
+	}
+	if err := netlink.NeighSet(neigh); err != nil {
+		return fmt.Errorf("failed to add neigh %v to gw %s: %v", neigh, gatewayConfig.Name, err)
+	}
+	routes := []*netlink.Route{
+		{
+			LinkIndex: gatewayConfig.LinkIndex,
+			Scope:     netlink.SCOPE_LINK,
+			Dst:       &net.IPNet{IP: virtualServiceIP, Mask: mask},
+		},
+		{
+			LinkIndex: gatewayConfig.LinkIndex,
+			Dst:       serviceCIDR,
+			Gw:        virtualServiceIP,
+			Src:       gatewayIP,
+			Flags:     int(netlink.FLAG_ONLINK),
+		},
+	}
+	for _, route := range routes {
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to install route %v with netlink: %v", route, err)
+		}
+		c.serviceRoutes.Store(route.Dst.String(), route)
+	}
 	return nil
 }
 
@@ -352,11 +465,19 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 		}
 	}
 
-	// Remove any unknown routes on antrea-gw0.
-	routes, err := c.listIPRoutesOnGW()
+	// Remove any unknown routes on antrea-gw0, and on the WireGuard device
+	// if it's used.
+	routes, err := c.listIPRoutesOnLink(c.nodeConfig.GatewayConfig.LinkIndex)
 	if err != nil {
 		return fmt.Errorf("error listing ip routes: %v", err)
 	}
+	if c.nodeConfig.WireGuardConfig != nil {
+		wireGuardRoutes, err := c.listIPRoutesOnLink(c.nodeConfig.WireGuardConfig.LinkIndex)
+		if err != nil {
+			return fmt.Errorf("error listing ip routes: %v", err)
+		}
+		routes = append(routes, wireGuardRoutes...)
+	}
 	for i := range routes {
 		route := routes[i]
 		if reflect.DeepEqual(route.Dst, c.nodeConfig.PodIPv4CIDR) || reflect.DeepEqual(route.Dst, c.nodeConfig.PodIPv6CIDR) {
@@ -365,6 +486,12 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 		if desiredPodCIDRs.Has(route.Dst.String()) {
 			continue
 		}
+		if _, isServiceRoute := c.serviceRoutes.Load(route.Dst.String()); isServiceRoute {
+			continue
+		}
+		if _, isLocalPodRoute := c.localPodRoutes.Load(route.Dst.String()); isLocalPodRoute {
+			continue
+		}
 		klog.Infof("Deleting unknown route %v", route)
 		if err := netlink.RouteDel(&route); err != nil && err != unix.ESRCH {
 			return err
@@ -373,6 +500,10 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 
 	// Remove any unknown IPv6 neighbors on antrea-gw0.
 	desiredGWs := getIPv6Gateways(podCIDRs)
+	if c.proxyAll && c.serviceCIDRv6 != nil {
+		// The neighbor of the IPv6 virtual Service IP is the next hop of the IPv6 Service routes.
+		desiredGWs.Insert(config.VirtualServiceIPv6.String())
+	}
 	// Return immediately if there is no IPv6 gateway address configured on the Nodes.
 	if desiredGWs.Len() == 0 {
 		return nil
@@ -395,9 +526,9 @@ func (c *Client) Reconcile(podCIDRs []string) error {
 }
 
 // listIPRoutes returns list of routes on antrea-gw0.
-func (c *Client) listIPRoutesOnGW() ([]netlink.Route, error) {
+func (c *Client) listIPRoutesOnLink(linkIndex int) ([]netlink.Route, error) {
 	filter := &netlink.Route{
-		LinkIndex: c.nodeConfig.GatewayConfig.LinkIndex}
+		LinkIndex: linkIndex}
 	routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, filter, netlink.RT_FILTER_OIF)
 	if err != nil {
 		return nil, err
@@ -452,6 +583,17 @@ func (c *Client) AddRoutes(podCIDR *net.IPNet, nodeIP, nodeGwIP net.IP) error {
 		Dst: podCIDR,
 	}
 	var routes []*netlink.Route
+	if c.networkConfig.TrafficEncryptionMode == config.TrafficEncryptionModeWireGuard {
+		// Pod traffic to the Node is encrypted by the WireGuard device,
+		// which selects the peer with the destination IP.
+		route.LinkIndex = c.nodeConfig.WireGuardConfig.LinkIndex
+		route.Scope = netlink.SCOPE_LINK
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to install route to peer %s with netlink: %v", nodeIP, err)
+		}
+		c.nodeRoutes.Store(podCIDRStr, []*netlink.Route{route})
+		return nil
+	}
 	if c.networkConfig.TrafficEncapMode.NeedsEncapToPeer(nodeIP, c.nodeConfig.NodeIPAddr) {
 		if podCIDR.IP.To4() == nil {
 			// "on-link" is not identified in IPv6 route entries, so split the configuration into 2 entries.
@@ -531,6 +673,76 @@ func (c *Client) DeleteRoutes(podCIDR *net.IPNet) error {
 	return nil
 }
 
+// AddLocalPodRoutes routes the IPs of a local Pod which are not allocated from the PodCIDRs of the Node to the host
+// gateway, so that the host network can forward the traffic destined to them. As the gateways of these IPs are not on
+// the Node, proxy ARP is enabled on the host gateway to resolve them to the host gateway MAC, which makes the Pods
+// send their traffic to the host network. It overrides the routes if they already exist.
+func (c *Client) AddLocalPodRoutes(podIPs []net.IP) error {
+	for _, route := range c.getLocalPodRoutes(podIPs) {
+		if route.Dst.IP.To4() != nil {
+			if err := c.enableProxyARPOnGateway(); err != nil {
+				return err
+			}
+		}
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to install route to local Pod IP %s with netlink: %v", route.Dst.IP, err)
+		}
+		c.localPodRoutes.Store(route.Dst.String(), route)
+	}
+	return nil
+}
+
+// DeleteLocalPodRoutes deletes the routes added by AddLocalPodRoutes for the IPs of a local Pod. It does nothing if
+// the routes don't exist.
+func (c *Client) DeleteLocalPodRoutes(podIPs []net.IP) error {
+	for _, route := range c.getLocalPodRoutes(podIPs) {
+		klog.V(4).Infof("Deleting route %v", route)
+		if err := netlink.RouteDel(route); err != nil && err != unix.ESRCH {
+			return err
+		}
+		c.localPodRoutes.Delete(route.Dst.String())
+	}
+	return nil
+}
+
+// getLocalPodRoutes returns the routes to the provided local Pod IPs which are not in the PodCIDRs of the Node. It
+// returns nothing if the Node has no PodCIDR, e.g. in networkPolicyOnly mode, as Antrea doesn't allocate the Pod IPs.
+func (c *Client) getLocalPodRoutes(podIPs []net.IP) []*netlink.Route {
+	if c.nodeConfig.PodIPv4CIDR == nil && c.nodeConfig.PodIPv6CIDR == nil {
+		return nil
+	}
+	var routes []*netlink.Route
+	for _, podIP := range podIPs {
+		if c.nodeConfig.PodIPv4CIDR != nil && c.nodeConfig.PodIPv4CIDR.Contains(podIP) ||
+			c.nodeConfig.PodIPv6CIDR != nil && c.nodeConfig.PodIPv6CIDR.Contains(podIP) {
+			continue
+		}
+		dst := &net.IPNet{IP: podIP, Mask: net.CIDRMask(net.IPv6len*8, net.IPv6len*8)}
+		if ipv4 := podIP.To4(); ipv4 != nil {
+			dst = &net.IPNet{IP: ipv4, Mask: net.CIDRMask(net.IPv4len*8, net.IPv4len*8)}
+		}
+		routes = append(routes, &netlink.Route{
+			Dst:       dst,
+			LinkIndex: c.nodeConfig.GatewayConfig.LinkIndex,
+			Scope:     netlink.SCOPE_LINK,
+		})
+	}
+	return routes
+}
+
+// enableProxyARPOnGateway makes the host gateway reply to the ARP requests of the IPs which are routed via another
+// interface, without delay.
+func (c *Client) enableProxyARPOnGateway() error {
+	gatewayName := c.nodeConfig.GatewayConfig.Name
+	if err := sysctl.EnsureSysctlNetValue(fmt.Sprintf("ipv4/conf/%s/proxy_arp", gatewayName), 1); err != nil {
+		return fmt.Errorf("failed to enable proxy ARP on %s: %v", gatewayName, err)
+	}
+	if err := sysctl.EnsureSysctlNetValue(fmt.Sprintf("ipv4/neigh/%s/proxy_delay", gatewayName), 0); err != nil {
+		return fmt.Errorf("failed to disable proxy ARP delay on %s: %v", gatewayName, err)
+	}
+	return nil
+}
+
 // Join all words with spaces, terminate with newline and write to buf.
 func writeLine(buf *bytes.Buffer, words ...string) {
 	// We avoid strings.Join for performance reasons.
@@ -0,0 +1,102 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+)
+
+func TestRestoreIptablesDataProxyAll(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	_, serviceCIDR, _ := net.ParseCIDR("10.96.0.0/12")
+	_, podCIDRv6, _ := net.ParseCIDR("fd74:ca9b:172:18::/64")
+	_, serviceCIDRv6, _ := net.ParseCIDR("fd00:10:96::/112")
+	nodeConfig := &config.NodeConfig{
+		GatewayConfig: &config.GatewayConfig{Name: "antrea-gw0"},
+		PodIPv4CIDR:   podCIDR,
+		PodIPv6CIDR:   podCIDRv6,
+	}
+	proxyAllRules := []string{
+		`-A ANTREA-POSTROUTING -m comment --comment "Antrea: masquerade host to Service packets" -d 10.96.0.0/12 -o antrea-gw0 -m addrtype --src-type LOCAL -j MASQUERADE`,
+		`-A ANTREA-POSTROUTING -m comment --comment "Antrea: masquerade virtual Service IP packets" -s 169.254.169.253 ! -o antrea-gw0 -j MASQUERADE`,
+	}
+	proxyAllRulesV6 := []string{
+		`-A ANTREA-POSTROUTING -m comment --comment "Antrea: masquerade host to Service packets" -d fd00:10:96::/112 -o antrea-gw0 -m addrtype --src-type LOCAL -j MASQUERADE`,
+		`-A ANTREA-POSTROUTING -m comment --comment "Antrea: masquerade virtual Service IP packets" -s fc01::aabb:ccdd:eeff ! -o antrea-gw0 -j MASQUERADE`,
+	}
+
+	for _, proxyAll := range []bool{false, true} {
+		c, _ := NewClient(serviceCIDR, serviceCIDRv6, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false, proxyAll)
+		c.nodeConfig = nodeConfig
+
+		data := c.restoreIptablesData(podCIDR, antreaPodIPSet, false).String()
+		dataV6 := c.restoreIptablesData(podCIDRv6, antreaPodIP6Set, true).String()
+		for _, rule := range proxyAllRules {
+			if proxyAll {
+				assert.Contains(t, data, rule)
+			} else {
+				assert.NotContains(t, data, rule)
+			}
+			assert.NotContains(t, dataV6, rule)
+		}
+		for _, rule := range proxyAllRulesV6 {
+			if proxyAll {
+				assert.Contains(t, dataV6, rule)
+			} else {
+				assert.NotContains(t, dataV6, rule)
+			}
+			assert.NotContains(t, data, rule)
+		}
+	}
+}
+
+func TestInitIPRoutesProxyAllWithoutServiceCIDRv6(t *testing.T) {
+	_, serviceCIDR, _ := net.ParseCIDR("10.96.0.0/12")
+	_, podCIDRv6, _ := net.ParseCIDR("fd74:ca9b:172:18::/64")
+	c, _ := NewClient(serviceCIDR, nil, &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap}, false, true)
+	c.nodeConfig = &config.NodeConfig{
+		GatewayConfig: &config.GatewayConfig{Name: "antrea-gw0"},
+		PodIPv6CIDR:   podCIDRv6,
+	}
+	assert.EqualError(t, c.initIPRoutes(), "serviceCIDRv6 must be set when proxyAll is enabled on a Node with an IPv6 PodCIDR")
+}
+
+func TestGetLocalPodRoutes(t *testing.T) {
+	_, podCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	_, podCIDRv6, _ := net.ParseCIDR("fd74:ca9b:172:18::/64")
+	podIPs := []net.IP{net.ParseIP("10.10.0.5"), net.ParseIP("192.168.240.5"), net.ParseIP("fd74:ca9b:172:18::5"), net.ParseIP("fd00:240::5")}
+	gatewayConfig := &config.GatewayConfig{Name: "antrea-gw0", LinkIndex: 10}
+
+	c := &Client{nodeConfig: &config.NodeConfig{GatewayConfig: gatewayConfig, PodIPv4CIDR: podCIDR, PodIPv6CIDR: podCIDRv6}}
+	routes := c.getLocalPodRoutes(podIPs)
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, "192.168.240.5/32", routes[0].Dst.String())
+		assert.Equal(t, "fd00:240::5/128", routes[1].Dst.String())
+		for _, route := range routes {
+			assert.Equal(t, 10, route.LinkIndex)
+			assert.Equal(t, netlink.SCOPE_LINK, route.Scope)
+		}
+	}
+
+	// Antrea doesn't allocate the Pod IPs when the Node has no PodCIDR.
+	c = &Client{nodeConfig: &config.NodeConfig{GatewayConfig: gatewayConfig}}
+	assert.Empty(t, c.getLocalPodRoutes(podIPs))
+}
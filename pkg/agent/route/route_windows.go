@@ -26,6 +26,7 @@ import (
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+	"github.com/vmware-tanzu/antrea/pkg/agent/types"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util"
 	"github.com/vmware-tanzu/antrea/pkg/agent/util/winfirewall"
 )
@@ -45,7 +46,8 @@ type Client struct {
 
 // NewClient returns a route client.
 // Todo: remove param serviceCIDR after kube-proxy is replaced by Antrea Proxy completely.
-func NewClient(serviceCIDR *net.IPNet, networkConfig *config.NetworkConfig, noSNAT bool) (*Client, error) {
+// Params serviceCIDRv6 and proxyAll are not used on Windows, as proxyAll is not supported.
+func NewClient(serviceCIDR, serviceCIDRv6 *net.IPNet, networkConfig *config.NetworkConfig, noSNAT, proxyAll bool) (*Client, error) {
 	nr := netroute.New()
 	return &Client{
 		nr:          nr,
@@ -152,6 +154,35 @@ func (c *Client) UnMigrateRoutesFromGw(route *net.IPNet, linkName string) error
 	return errors.New("UnMigrateRoutesFromGw is unsupported on Windows")
 }
 
+// AddLocalPodRoutes does nothing on Windows, as the Pod IPs are always allocated from the PodCIDR of the Node.
+func (c *Client) AddLocalPodRoutes(podIPs []net.IP) error {
+	return nil
+}
+
+// DeleteLocalPodRoutes does nothing on Windows, as no route is added by AddLocalPodRoutes.
+func (c *Client) DeleteLocalPodRoutes(podIPs []net.IP) error {
+	return nil
+}
+
+// InstallHostPolicyRule is not supported on Windows.
+func (c *Client) InstallHostPolicyRule(rule *types.PolicyRule, priority types.Priority) error {
+	return errors.New("InstallHostPolicyRule is unsupported on Windows")
+}
+
+// UninstallHostPolicyRule is not supported on Windows. It does nothing as no rule can be installed.
+func (c *Client) UninstallHostPolicyRule(flowID uint32) error {
+	return nil
+}
+
+// HostPolicyRuleMetrics is not supported on Windows. It returns nil as no rule can be installed.
+func (c *Client) HostPolicyRuleMetrics() map[uint32]*types.RuleMetric {
+	return nil
+}
+
+// ReceiveHostPolicyLogs is not supported on Windows. It returns immediately as no rule can be installed.
+func (c *Client) ReceiveHostPolicyLogs(stopCh <-chan struct{}, handler func(log *types.HostPolicyLog)) {
+}
+
 func (c *Client) listRoutes() (map[string]*netroute.Route, error) {
 	routes, err := c.nr.GetNetRoutesAll()
 	if err != nil {
@@ -22,6 +22,7 @@ package testing
 import (
 	gomock "github.com/golang/mock/gomock"
 	config "github.com/vmware-tanzu/antrea/pkg/agent/config"
+	types "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	net "net"
 	reflect "reflect"
 )
@@ -49,6 +50,20 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AddLocalPodRoutes mocks base method
+func (m *MockInterface) AddLocalPodRoutes(arg0 []net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLocalPodRoutes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLocalPodRoutes indicates an expected call of AddLocalPodRoutes
+func (mr *MockInterfaceMockRecorder) AddLocalPodRoutes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLocalPodRoutes", reflect.TypeOf((*MockInterface)(nil).AddLocalPodRoutes), arg0)
+}
+
 // AddRoutes mocks base method
 func (m *MockInterface) AddRoutes(arg0 *net.IPNet, arg1, arg2 net.IP) error {
 	m.ctrl.T.Helper()
@@ -63,6 +78,20 @@ func (mr *MockInterfaceMockRecorder) AddRoutes(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRoutes", reflect.TypeOf((*MockInterface)(nil).AddRoutes), arg0, arg1, arg2)
 }
 
+// DeleteLocalPodRoutes mocks base method
+func (m *MockInterface) DeleteLocalPodRoutes(arg0 []net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLocalPodRoutes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLocalPodRoutes indicates an expected call of DeleteLocalPodRoutes
+func (mr *MockInterfaceMockRecorder) DeleteLocalPodRoutes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLocalPodRoutes", reflect.TypeOf((*MockInterface)(nil).DeleteLocalPodRoutes), arg0)
+}
+
 // DeleteRoutes mocks base method
 func (m *MockInterface) DeleteRoutes(arg0 *net.IPNet) error {
 	m.ctrl.T.Helper()
@@ -77,6 +106,20 @@ func (mr *MockInterfaceMockRecorder) DeleteRoutes(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoutes", reflect.TypeOf((*MockInterface)(nil).DeleteRoutes), arg0)
 }
 
+// HostPolicyRuleMetrics mocks base method
+func (m *MockInterface) HostPolicyRuleMetrics() map[uint32]*types.RuleMetric {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HostPolicyRuleMetrics")
+	ret0, _ := ret[0].(map[uint32]*types.RuleMetric)
+	return ret0
+}
+
+// HostPolicyRuleMetrics indicates an expected call of HostPolicyRuleMetrics
+func (mr *MockInterfaceMockRecorder) HostPolicyRuleMetrics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostPolicyRuleMetrics", reflect.TypeOf((*MockInterface)(nil).HostPolicyRuleMetrics))
+}
+
 // Initialize mocks base method
 func (m *MockInterface) Initialize(arg0 *config.NodeConfig, arg1 func()) error {
 	m.ctrl.T.Helper()
@@ -91,6 +134,20 @@ func (mr *MockInterfaceMockRecorder) Initialize(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Initialize", reflect.TypeOf((*MockInterface)(nil).Initialize), arg0, arg1)
 }
 
+// InstallHostPolicyRule mocks base method
+func (m *MockInterface) InstallHostPolicyRule(arg0 *types.PolicyRule, arg1 types.Priority) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallHostPolicyRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallHostPolicyRule indicates an expected call of InstallHostPolicyRule
+func (mr *MockInterfaceMockRecorder) InstallHostPolicyRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallHostPolicyRule", reflect.TypeOf((*MockInterface)(nil).InstallHostPolicyRule), arg0, arg1)
+}
+
 // MigrateRoutesToGw mocks base method
 func (m *MockInterface) MigrateRoutesToGw(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -105,6 +162,18 @@ func (mr *MockInterfaceMockRecorder) MigrateRoutesToGw(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateRoutesToGw", reflect.TypeOf((*MockInterface)(nil).MigrateRoutesToGw), arg0)
 }
 
+// ReceiveHostPolicyLogs mocks base method
+func (m *MockInterface) ReceiveHostPolicyLogs(arg0 <-chan struct{}, arg1 func(*types.HostPolicyLog)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceiveHostPolicyLogs", arg0, arg1)
+}
+
+// ReceiveHostPolicyLogs indicates an expected call of ReceiveHostPolicyLogs
+func (mr *MockInterfaceMockRecorder) ReceiveHostPolicyLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveHostPolicyLogs", reflect.TypeOf((*MockInterface)(nil).ReceiveHostPolicyLogs), arg0, arg1)
+}
+
 // Reconcile mocks base method
 func (m *MockInterface) Reconcile(arg0 []string) error {
 	m.ctrl.T.Helper()
@@ -132,3 +201,17 @@ func (mr *MockInterfaceMockRecorder) UnMigrateRoutesFromGw(arg0, arg1 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnMigrateRoutesFromGw", reflect.TypeOf((*MockInterface)(nil).UnMigrateRoutesFromGw), arg0, arg1)
 }
+
+// UninstallHostPolicyRule mocks base method
+func (m *MockInterface) UninstallHostPolicyRule(arg0 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallHostPolicyRule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallHostPolicyRule indicates an expected call of UninstallHostPolicyRule
+func (mr *MockInterfaceMockRecorder) UninstallHostPolicyRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallHostPolicyRule", reflect.TypeOf((*MockInterface)(nil).UninstallHostPolicyRule), arg0)
+}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/vmware-tanzu/antrea/pkg/agent"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/agent/route"
+	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	statsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/stats/v1alpha1"
 	"github.com/vmware-tanzu/antrea/pkg/querier"
@@ -53,19 +55,22 @@ type Collector struct {
 	// antrea-controller.
 	antreaClientProvider agent.AntreaClientProvider
 	// ofClient is the Openflow interface that can fetch the statistic of the Openflow entries.
-	ofClient             openflow.Client
+	ofClient openflow.Client
+	// routeClient is the route interface that can fetch the statistic of the rules enforced on the host traffic.
+	routeClient          route.Interface
 	networkPolicyQuerier querier.AgentNetworkPolicyInfoQuerier
 	// lastStatsCollection is the last statistics that has been reported to antrea-controller successfully.
 	// It is used to calculate the delta of the statistics that will be reported.
 	lastStatsCollection *statsCollection
 }
 
-func NewCollector(antreaClientProvider agent.AntreaClientProvider, ofClient openflow.Client, npQuerier querier.AgentNetworkPolicyInfoQuerier) *Collector {
+func NewCollector(antreaClientProvider agent.AntreaClientProvider, ofClient openflow.Client, routeClient route.Interface, npQuerier querier.AgentNetworkPolicyInfoQuerier) *Collector {
 	nodeName, _ := env.GetNodeName()
 	manager := &Collector{
 		nodeName:             nodeName,
 		antreaClientProvider: antreaClientProvider,
 		ofClient:             ofClient,
+		routeClient:          routeClient,
 		networkPolicyQuerier: npQuerier,
 	}
 	return manager
@@ -99,10 +104,19 @@ func (m *Collector) Run(stopCh <-chan struct{}) {
 	}
 }
 
-// collect collects the stats of Openflow rules, maps them to the stats of NetworkPolicies.
+// collect collects the stats of Openflow rules and host rules, maps them to the stats of NetworkPolicies.
 // It returns a map from NetworkPolicyReferences to their stats.
 func (m *Collector) collect() *statsCollection {
 	ruleStatsMap := m.ofClient.NetworkPolicyMetrics()
+	// Host rules and Openflow rules are allocated IDs from the same space, they never conflict.
+	if hostRuleStatsMap := m.routeClient.HostPolicyRuleMetrics(); len(hostRuleStatsMap) > 0 {
+		if ruleStatsMap == nil {
+			ruleStatsMap = make(map[uint32]*agenttypes.RuleMetric, len(hostRuleStatsMap))
+		}
+		for ruleID, ruleStats := range hostRuleStatsMap {
+			ruleStatsMap[ruleID] = ruleStats
+		}
+	}
 	npStatsMap := map[types.UID]*statsv1alpha1.TrafficStats{}
 	acnpStatsMap := map[types.UID]*statsv1alpha1.TrafficStats{}
 	anpStatsMap := map[types.UID]*statsv1alpha1.TrafficStats{}
@@ -134,6 +148,7 @@ func (m *Collector) collect() *statsCollection {
 		policyStats.Bytes += int64(ruleStats.Bytes)
 		policyStats.Sessions += int64(ruleStats.Sessions)
 		policyStats.Packets += int64(ruleStats.Packets)
+		policyStats.RateLimitedPackets += int64(ruleStats.RateLimitedPackets)
 	}
 	return &statsCollection{
 		networkPolicyStats:              npStatsMap,
@@ -189,9 +204,10 @@ func calculateDiff(curStatsMap, lastStatsMap map[types.UID]*statsv1alpha1.Traffi
 			stats = curStats
 		} else {
 			stats = &statsv1alpha1.TrafficStats{
-				Packets:  curStats.Packets - lastStats.Packets,
-				Sessions: curStats.Sessions - lastStats.Sessions,
-				Bytes:    curStats.Bytes - lastStats.Bytes,
+				Packets:            curStats.Packets - lastStats.Packets,
+				Sessions:           curStats.Sessions - lastStats.Sessions,
+				Bytes:              curStats.Bytes - lastStats.Bytes,
+				RateLimitedPackets: curStats.RateLimitedPackets - lastStats.RateLimitedPackets,
 			}
 		}
 		// If the statistics of the NetworkPolicy remain unchanged, no need to report it.
@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	oftest "github.com/vmware-tanzu/antrea/pkg/agent/openflow/testing"
+	routetest "github.com/vmware-tanzu/antrea/pkg/agent/route/testing"
 	agenttypes "github.com/vmware-tanzu/antrea/pkg/agent/types"
 	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	statsv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/stats/v1alpha1"
@@ -62,6 +63,7 @@ func TestCollect(t *testing.T) {
 	tests := []struct {
 		name                    string
 		ruleStats               map[uint32]*agenttypes.RuleMetric
+		hostRuleStats           map[uint32]*agenttypes.RuleMetric
 		ofIDToPolicyMap         map[uint32]*cpv1beta.NetworkPolicyReference
 		expectedStatsCollection *statsCollection
 	}{
@@ -154,6 +156,44 @@ func TestCollect(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "host rules",
+			ruleStats: map[uint32]*agenttypes.RuleMetric{
+				1: {
+					Bytes:    10,
+					Packets:  1,
+					Sessions: 1,
+				},
+			},
+			hostRuleStats: map[uint32]*agenttypes.RuleMetric{
+				2: {
+					Bytes:    60,
+					Packets:  1,
+					Sessions: 1,
+				},
+				3: {
+					Bytes:    120,
+					Packets:  2,
+					Sessions: 2,
+				},
+			},
+			ofIDToPolicyMap: map[uint32]*cpv1beta.NetworkPolicyReference{
+				1: &acnp1,
+				2: &acnp1,
+				3: &acnp1,
+			},
+			expectedStatsCollection: &statsCollection{
+				networkPolicyStats: map[types.UID]*statsv1alpha1.TrafficStats{},
+				antreaClusterNetworkPolicyStats: map[types.UID]*statsv1alpha1.TrafficStats{
+					acnp1.UID: {
+						Bytes:    190,
+						Packets:  4,
+						Sessions: 4,
+					},
+				},
+				antreaNetworkPolicyStats: map[types.UID]*statsv1alpha1.TrafficStats{},
+			},
+		},
 		{
 			name: "unknown policy",
 			ruleStats: map[uint32]*agenttypes.RuleMetric{
@@ -189,12 +229,14 @@ func TestCollect(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ofClient := oftest.NewMockClient(ctrl)
 			npQuerier := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
+			routeClient := routetest.NewMockInterface(ctrl)
 			ofClient.EXPECT().NetworkPolicyMetrics().Return(tt.ruleStats).Times(1)
+			routeClient.EXPECT().HostPolicyRuleMetrics().Return(tt.hostRuleStats).Times(1)
 			for ofID, policy := range tt.ofIDToPolicyMap {
 				npQuerier.EXPECT().GetNetworkPolicyByRuleFlowID(ofID).Return(policy)
 			}
 
-			m := &Collector{ofClient: ofClient, networkPolicyQuerier: npQuerier}
+			m := &Collector{ofClient: ofClient, routeClient: routeClient, networkPolicyQuerier: npQuerier}
 			actualPolicyStats := m.collect()
 			assert.Equal(t, tt.expectedStatsCollection, actualPolicyStats)
 		})
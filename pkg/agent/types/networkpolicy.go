@@ -81,6 +81,8 @@ type PolicyRule struct {
 	TableID       binding.TableIDType
 	PolicyRef     *v1beta2.NetworkPolicyReference
 	EnableLogging bool
+	RateLimit     *v1beta2.RateLimit
+	L7Protocols   []v1beta2.L7Protocol
 }
 
 // IsAntreaNetworkPolicyRule returns if a PolicyRule is created for Antrea NetworkPolicy types.
@@ -133,10 +135,24 @@ func (bp ByPriority) Less(i, j int) bool { return bp[i].Less(bp[j]) }
 
 type RuleMetric struct {
 	Bytes, Packets, Sessions uint64
+	// RateLimitedPackets is the number of packets dropped by the rate limits of the rule.
+	RateLimitedPackets uint64
 }
 
 func (m *RuleMetric) Merge(m1 *RuleMetric) {
 	m.Bytes += m1.Bytes
 	m.Packets += m1.Packets
 	m.Sessions += m1.Sessions
+	m.RateLimitedPackets += m1.RateLimitedPackets
+}
+
+// HostPolicyLog is a packet logged by a policy rule enforced on the host network of the Node.
+type HostPolicyLog struct {
+	// Chain is the iptables chain of the rule.
+	Chain       string
+	PolicyRef   *v1beta2.NetworkPolicyReference
+	Disposition string
+	Priority    Priority
+	// Packet is the logged IP packet, it may be truncated.
+	Packet []byte
 }
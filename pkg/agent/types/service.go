@@ -0,0 +1,43 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// ServiceMetric is the statistics of a Service port collected from the OVS
+// group and flows of the Service.
+type ServiceMetric struct {
+	// Connections is the number of new connections load-balanced by the group
+	// of the Service.
+	Connections uint64
+	// Packets and Bytes count the packets of the connections of the Service.
+	Packets, Bytes uint64
+	// NoEndpointPackets is the number of packets dropped because they were
+	// sent to the Service while it had no Endpoint. All the packets are
+	// counted, as the dropped connections are never committed to conntrack.
+	NoEndpointPackets uint64
+	// Endpoints is the statistics of each Endpoint, keyed by the Endpoint
+	// string (IP:port).
+	Endpoints map[string]*EndpointMetric
+}
+
+// EndpointMetric is the statistics of an Endpoint of a Service port.
+type EndpointMetric struct {
+	// Connections is the number of new connections load-balanced to the
+	// Endpoint by the group of the Service.
+	Connections uint64
+	// Packets and Bytes count the packets sent to the Endpoint by the
+	// connections load-balanced to it. The reply packets are only counted in
+	// the ServiceMetric, as they can't be told apart once they are un-DNATed.
+	Packets, Bytes uint64
+}
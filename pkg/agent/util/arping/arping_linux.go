@@ -25,6 +25,8 @@ import (
 const (
 	// 1544 = htons(ETH_P_ARP)
 	protoARP = 1544
+	// 56710 = htons(ETH_P_IPV6)
+	protoIPv6 = 56710
 )
 
 // GratuitousARPOverIface sends an gratuitous arp over interface 'iface' from 'srcIP'.
@@ -68,3 +70,82 @@ func newARPRequest(sha, spa, tha, tpa []byte) []byte {
 	frame.Write(tpa)                                      // Target protocol address.
 	return frame.Bytes()
 }
+
+// GratuitousNDPOverIface sends an unsolicited Neighbor Advertisement over
+// interface 'iface' for 'srcIP' to the all-nodes multicast address, which is
+// the IPv6 counterpart of a gratuitous ARP.
+func GratuitousNDPOverIface(srcIP net.IP, iface *net.Interface) error {
+	if srcIP.To4() != nil || srcIP.To16() == nil {
+		return fmt.Errorf("IP %s is not a valid IPv6 address", srcIP)
+	}
+
+	advertisement := newNeighborAdvertisement(iface.HardwareAddr, srcIP.To16())
+
+	toSockaddr := &syscall.SockaddrLinklayer{Ifindex: iface.Index}
+
+	sock, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, protoIPv6)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(sock)
+
+	return syscall.Sendto(sock, advertisement, 0, toSockaddr)
+}
+
+func newNeighborAdvertisement(sha, tpa []byte) []byte {
+	// All-nodes multicast address and the corresponding MAC address.
+	dstIP := net.ParseIP("ff02::1").To16()
+	dstMac := []byte{0x33, 0x33, 0x00, 0x00, 0x00, 0x01}
+
+	// ICMPv6 message.
+	icmp := bytes.NewBuffer(nil)
+	binary.Write(icmp, binary.BigEndian, uint8(136))         // Type, Neighbor Advertisement is 136.
+	binary.Write(icmp, binary.BigEndian, uint8(0))           // Code.
+	binary.Write(icmp, binary.BigEndian, uint16(0))          // Checksum, computed below.
+	binary.Write(icmp, binary.BigEndian, uint32(0x20000000)) // Flags, Override is set.
+	icmp.Write(tpa)                                          // Target address.
+	binary.Write(icmp, binary.BigEndian, uint8(2))           // Option type, Target Link-Layer Address is 2.
+	binary.Write(icmp, binary.BigEndian, uint8(1))           // Option length in units of 8 bytes.
+	icmp.Write(sha)                                          // Target link-layer address.
+	message := icmp.Bytes()
+	binary.BigEndian.PutUint16(message[2:4], icmpv6Checksum(tpa, dstIP, message))
+
+	frame := bytes.NewBuffer(nil)
+	// Ethernet header.
+	frame.Write(dstMac)             // Destination MAC address.
+	frame.Write(sha)                // Source MAC address.
+	frame.Write([]byte{0x86, 0xdd}) // Ethernet protocol type, 0x86dd for IPv6.
+	// IPv6 header.
+	binary.Write(frame, binary.BigEndian, uint32(0x60000000))   // Version 6, traffic class and flow label 0.
+	binary.Write(frame, binary.BigEndian, uint16(len(message))) // Payload length.
+	binary.Write(frame, binary.BigEndian, uint8(58))            // Next header, ICMPv6 is 58.
+	binary.Write(frame, binary.BigEndian, uint8(255))           // Hop limit, must be 255 for NDP.
+	frame.Write(tpa)                                            // Source address.
+	frame.Write(dstIP)                                          // Destination address.
+	frame.Write(message)
+	return frame.Bytes()
+}
+
+// icmpv6Checksum computes the checksum of an ICMPv6 message, which covers the
+// IPv6 pseudo-header.
+func icmpv6Checksum(src, dst net.IP, message []byte) uint16 {
+	pseudoHeader := bytes.NewBuffer(nil)
+	pseudoHeader.Write(src)
+	pseudoHeader.Write(dst)
+	binary.Write(pseudoHeader, binary.BigEndian, uint32(len(message)))
+	pseudoHeader.Write([]byte{0, 0, 0, 58})
+	pseudoHeader.Write(message)
+	data := pseudoHeader.Bytes()
+
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
@@ -53,3 +53,35 @@ func TestNewARPRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestNewNeighborAdvertisement(t *testing.T) {
+	sha := []byte{0x42, 0xaf, 0xb8, 0x14, 0xcb, 0x4e}
+	tpa := net.ParseIP("2001:db8::1").To16()
+	frame := newNeighborAdvertisement(sha, tpa)
+
+	if len(frame) != 14+40+32 {
+		t.Fatalf("Unexpected frame length %d", len(frame))
+	}
+	ethernetHeader := []byte{0x33, 0x33, 0x00, 0x00, 0x00, 0x01, 0x42, 0xaf, 0xb8, 0x14, 0xcb, 0x4e, 0x86, 0xdd}
+	if !reflect.DeepEqual(frame[:14], ethernetHeader) {
+		t.Errorf("Ethernet header = %v, want %v", frame[:14], ethernetHeader)
+	}
+	ipv6Header := frame[14:54]
+	if ipv6Header[6] != 58 || ipv6Header[7] != 255 {
+		t.Errorf("Unexpected next header %d or hop limit %d", ipv6Header[6], ipv6Header[7])
+	}
+	if !net.IP(ipv6Header[8:24]).Equal(tpa) || !net.IP(ipv6Header[24:40]).Equal(net.ParseIP("ff02::1")) {
+		t.Errorf("Unexpected source %v or destination %v", net.IP(ipv6Header[8:24]), net.IP(ipv6Header[24:40]))
+	}
+	message := frame[54:]
+	if message[0] != 136 || message[4] != 0x20 {
+		t.Errorf("Unexpected type %d or flags %x", message[0], message[4])
+	}
+	if !net.IP(message[8:24]).Equal(tpa) || !reflect.DeepEqual([]byte(message[26:32]), sha) {
+		t.Errorf("Unexpected target address %v or target link-layer address %v", net.IP(message[8:24]), message[26:32])
+	}
+	// The checksum of a message including a valid checksum is 0.
+	if checksum := icmpv6Checksum(tpa, net.ParseIP("ff02::1").To16(), message); checksum != 0 {
+		t.Errorf("Invalid checksum, got %x when verifying it", checksum)
+	}
+}
@@ -47,6 +47,18 @@ func CreateIPSet(name string, setType SetType, isIPv6 bool) error {
 	return nil
 }
 
+// DestroyIPSet destroys the set, it will ignore error when the set doesn't exist.
+func DestroyIPSet(name string) error {
+	cmd := exec.Command("ipset", "destroy", name)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(output), "does not exist") {
+			return nil
+		}
+		return fmt.Errorf("error destroying ipset %s: %v", name, err)
+	}
+	return nil
+}
+
 // AddEntry adds a new entry to the set, it will ignore error when the entry already exists.
 func AddEntry(name string, entry string) error {
 	cmd := exec.Command("ipset", "add", name, entry, "-exist")
@@ -35,12 +35,16 @@ const (
 	RawTable    = "raw"
 
 	AcceptTarget     = "ACCEPT"
+	DropTarget       = "DROP"
+	ReturnTarget     = "RETURN"
+	NFLOGTarget      = "NFLOG"
 	MasqueradeTarget = "MASQUERADE"
 	MarkTarget       = "MARK"
 	ConnTrackTarget  = "CT"
 	NoTrackTarget    = "NOTRACK"
 
 	PreRoutingChain  = "PREROUTING"
+	InputChain       = "INPUT"
 	ForwardChain     = "FORWARD"
 	PostRoutingChain = "POSTROUTING"
 	OutputChain      = "OUTPUT"
@@ -127,6 +131,25 @@ func (c *Client) EnsureRule(table string, chain string, ruleSpec []string) error
 	return nil
 }
 
+// DeleteRule checks if target rule already exists, deletes it if so.
+func (c *Client) DeleteRule(table string, chain string, ruleSpec []string) error {
+	for idx := range c.ipts {
+		ipt := c.ipts[idx]
+		exist, err := ipt.Exists(table, chain, ruleSpec...)
+		if err != nil {
+			return fmt.Errorf("error checking if rule %v exists in table %s chain %s: %v", ruleSpec, table, chain, err)
+		}
+		if !exist {
+			continue
+		}
+		if err := ipt.Delete(table, chain, ruleSpec...); err != nil {
+			return fmt.Errorf("error deleting rule %v from table %s chain %s: %v", ruleSpec, table, chain, err)
+		}
+	}
+	klog.V(2).Infof("Deleted rule %v from table %s chain %s", ruleSpec, table, chain)
+	return nil
+}
+
 // Restore calls iptables-restore to restore iptables with the provided content.
 // If flush is true, all previous contents of the respective tables will be flushed.
 // Otherwise only involved chains will be flushed. Restore supports "ip6tables-restore" for IPv6.
@@ -167,6 +190,21 @@ func (c *Client) Restore(data []byte, flush bool, useIPv6 bool) error {
 	return nil
 }
 
+// Stats returns the packet and byte counters of the rules in the specified
+// table/chain, for all the enabled IP families.
+func (c *Client) Stats(table string, chain string) ([]iptables.Stat, error) {
+	var stats []iptables.Stat
+	for idx := range c.ipts {
+		ipt := c.ipts[idx]
+		chainStats, err := ipt.StructuredStats(table, chain)
+		if err != nil {
+			return nil, fmt.Errorf("error getting stats of table %s chain %s: %v", table, chain, err)
+		}
+		stats = append(stats, chainStats...)
+	}
+	return stats, nil
+}
+
 // Save calls iptables-saves to dump chains and tables in iptables.
 func (c *Client) Save() ([]byte, error) {
 	var output []byte
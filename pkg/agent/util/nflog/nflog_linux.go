@@ -0,0 +1,191 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nflog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog"
+)
+
+const (
+	// Message types and attributes of the nfnetlink_log subsystem, see linux/netfilter/nfnetlink_log.h.
+	nfulnlMsgPacket = 0
+	nfulnlMsgConfig = 1
+
+	nfulaPayload = 9
+	nfulaPrefix  = 10
+
+	nfulaCfgCmd  = 1
+	nfulaCfgMode = 2
+
+	nfulnlCfgCmdBind   = 1
+	nfulnlCopyPacket   = 2
+	nfnetlinkV0        = 0
+	sizeofNfgenmsg     = 4
+	nlaTypeMask        = ^uint16(unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+	receiveTimeoutUsec = 500000
+)
+
+// Packet is a packet logged to an NFLOG group by the NFLOG iptables target.
+type Packet struct {
+	// Prefix is the --nflog-prefix of the iptables rule which logged the packet.
+	Prefix string
+	// Payload is the network layer packet, it may be truncated to the copy range of the group.
+	Payload []byte
+}
+
+// nfgenmsg is the generic header of the nfnetlink messages.
+type nfgenmsg struct {
+	family  uint8
+	version uint8
+	resID   uint16
+}
+
+func (m *nfgenmsg) Len() int {
+	return sizeofNfgenmsg
+}
+
+func (m *nfgenmsg) Serialize() []byte {
+	b := make([]byte, sizeofNfgenmsg)
+	b[0] = m.family
+	b[1] = m.version
+	binary.BigEndian.PutUint16(b[2:], m.resID)
+	return b
+}
+
+func newConfigRequest(group uint16, attr *nl.RtAttr) *nl.NetlinkRequest {
+	req := nl.NewNetlinkRequest(unix.NFNL_SUBSYS_ULOG<<8|nfulnlMsgConfig, unix.NLM_F_ACK)
+	req.AddData(&nfgenmsg{family: unix.AF_UNSPEC, version: nfnetlinkV0, resID: group})
+	req.AddData(attr)
+	return req
+}
+
+// newBindRequest returns the request binding the netlink socket to the NFLOG group.
+func newBindRequest(group uint16) *nl.NetlinkRequest {
+	return newConfigRequest(group, nl.NewRtAttr(nfulaCfgCmd, []byte{nfulnlCfgCmdBind}))
+}
+
+// newModeRequest returns the request making the group copy at most copyRange bytes of the packets.
+func newModeRequest(group uint16, copyRange uint32) *nl.NetlinkRequest {
+	mode := make([]byte, 6)
+	binary.BigEndian.PutUint32(mode, copyRange)
+	mode[4] = nfulnlCopyPacket
+	return newConfigRequest(group, nl.NewRtAttr(nfulaCfgMode, mode))
+}
+
+// Subscribe binds to the NFLOG group and calls the handler with the packets logged to it until stopCh is closed.
+// At most copyRange bytes of each packet are received. Only one process can bind to a group at a time.
+func Subscribe(group uint16, copyRange uint32, stopCh <-chan struct{}, handler func(packet *Packet)) error {
+	sock, err := nl.Subscribe(unix.NETLINK_NETFILTER)
+	if err != nil {
+		return fmt.Errorf("error creating netfilter netlink socket: %v", err)
+	}
+	defer sock.Close()
+	// The timeout allows the receiving loop to check stopCh periodically.
+	if err := sock.SetReceiveTimeout(&unix.Timeval{Usec: receiveTimeoutUsec}); err != nil {
+		return fmt.Errorf("error setting receive timeout of netfilter netlink socket: %v", err)
+	}
+	for _, req := range []*nl.NetlinkRequest{newBindRequest(group), newModeRequest(group, copyRange)} {
+		if err := sock.Send(req); err != nil {
+			return fmt.Errorf("error configuring NFLOG group %d: %v", group, err)
+		}
+		if err := receiveAck(sock, req.Seq); err != nil {
+			return fmt.Errorf("error configuring NFLOG group %d: %v", group, err)
+		}
+	}
+	klog.Infof("Subscribed to NFLOG group %d", group)
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		default:
+		}
+		msgs, _, err := sock.Receive()
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EWOULDBLOCK || err == unix.EINTR {
+				continue
+			}
+			// The kernel drops the packets when the socket buffer is full, the following ones can still be received.
+			if err == unix.ENOBUFS {
+				klog.Warningf("NFLOG group %d dropped packets as the receiving buffer was full", group)
+				continue
+			}
+			return fmt.Errorf("error receiving from NFLOG group %d: %v", group, err)
+		}
+		for _, msg := range msgs {
+			packet, err := parsePacketMessage(msg)
+			if err != nil {
+				klog.Errorf("Failed to parse message from NFLOG group %d: %v", group, err)
+				continue
+			}
+			if packet != nil {
+				handler(packet)
+			}
+		}
+	}
+}
+
+// receiveAck waits for the acknowledgement of the request identified by seq.
+func receiveAck(sock *nl.NetlinkSocket, seq uint32) error {
+	for {
+		msgs, _, err := sock.Receive()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if msg.Header.Seq != seq || msg.Header.Type != unix.NLMSG_ERROR {
+				continue
+			}
+			if len(msg.Data) < 4 {
+				return fmt.Errorf("invalid netlink acknowledgement")
+			}
+			if errno := int32(nl.NativeEndian().Uint32(msg.Data[:4])); errno != 0 {
+				return syscall.Errno(-errno)
+			}
+			return nil
+		}
+	}
+}
+
+// parsePacketMessage returns the packet carried by the netlink message, or nil if the message doesn't carry a packet.
+func parsePacketMessage(msg syscall.NetlinkMessage) (*Packet, error) {
+	if msg.Header.Type != unix.NFNL_SUBSYS_ULOG<<8|nfulnlMsgPacket {
+		return nil, nil
+	}
+	if len(msg.Data) < sizeofNfgenmsg {
+		return nil, fmt.Errorf("message is too short")
+	}
+	attrs, err := nl.ParseRouteAttr(msg.Data[sizeofNfgenmsg:])
+	if err != nil {
+		return nil, err
+	}
+	packet := &Packet{}
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nlaTypeMask {
+		case nfulaPrefix:
+			packet.Prefix = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nfulaPayload:
+			packet.Payload = attr.Value
+		}
+	}
+	return packet, nil
+}
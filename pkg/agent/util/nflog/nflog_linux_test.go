@@ -0,0 +1,86 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nflog
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestNewModeRequest(t *testing.T) {
+	req := newModeRequest(100, 128)
+	b := req.Serialize()
+	// 16 bytes of nlmsghdr, 4 bytes of nfgenmsg and 12 bytes of the aligned mode attribute.
+	require.Len(t, b, 32)
+	assert.Equal(t, []byte{unix.AF_UNSPEC, 0, 0, 100}, b[16:20])
+	assert.Equal(t, []byte{10, 0, nfulaCfgMode, 0, 0, 0, 0, 128, nfulnlCopyPacket, 0, 0, 0}, b[20:32])
+}
+
+func TestParsePacketMessage(t *testing.T) {
+	payload := []byte{0x45, 0x00, 0x00, 0x54}
+	data := []byte{unix.AF_INET, 0, 0, 100}
+	// NFULA_PREFIX "Antrea:1:Drop" with its terminating null byte and padding.
+	data = append(data, 18, 0, nfulaPrefix, 0)
+	data = append(data, []byte("Antrea:1:Drop\x00\x00\x00")...)
+	// NFULA_PAYLOAD.
+	data = append(data, 8, 0, nfulaPayload, 0)
+	data = append(data, payload...)
+
+	tests := []struct {
+		name      string
+		msg       syscall.NetlinkMessage
+		want      *Packet
+		expectErr bool
+	}{
+		{
+			name: "packet",
+			msg: syscall.NetlinkMessage{
+				Header: syscall.NlMsghdr{Type: unix.NFNL_SUBSYS_ULOG << 8},
+				Data:   data,
+			},
+			want: &Packet{Prefix: "Antrea:1:Drop", Payload: payload},
+		},
+		{
+			name: "not a packet",
+			msg: syscall.NetlinkMessage{
+				Header: syscall.NlMsghdr{Type: unix.NLMSG_ERROR},
+				Data:   []byte{0, 0, 0, 0},
+			},
+		},
+		{
+			name: "truncated",
+			msg: syscall.NetlinkMessage{
+				Header: syscall.NlMsghdr{Type: unix.NFNL_SUBSYS_ULOG << 8},
+				Data:   []byte{unix.AF_INET},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePacketMessage(tt.msg)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"net"
+)
+
+const (
+	// NodeWireGuardPublicKeyAnnotationKey is the annotation of the Node
+	// which publishes the public key of its WireGuard device to the other
+	// Nodes.
+	NodeWireGuardPublicKeyAnnotationKey = "node.antrea.tanzu.vmware.com/wireguard-public-key"
+)
+
+// Interface is the interface for configuring the WireGuard device which
+// encrypts the Pod traffic across Nodes.
+type Interface interface {
+	// Init creates the WireGuard device if it doesn't exist, configures its
+	// private key and listen port, and returns its public key. It should be
+	// idempotent and reuse the private key of an existing device, so that
+	// the peers don't need to be updated when the agent restarts.
+	Init() (string, error)
+
+	// UpdatePeer should add or update the peer of the provided Node, which
+	// sends the traffic destined to podCIDRs to the peer Node IP. It should
+	// replace the previous peer of the Node if its public key changed.
+	UpdatePeer(nodeName, publicKey string, peerNodeIP net.IP, podCIDRs []*net.IPNet) error
+
+	// DeletePeer should delete the peer of the provided Node. It should do
+	// nothing if the peer doesn't exist, without error.
+	DeletePeer(nodeName string) error
+
+	// RemoveStalePeers should remove the peers which are not in the provided
+	// map, keyed by Node name, of the public keys of the current peers.
+	RemoveStalePeers(currentPeerPublicKeys map[string]string) error
+}
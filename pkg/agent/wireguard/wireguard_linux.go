@@ -0,0 +1,209 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/crypto/curve25519"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+)
+
+const keyLen = 32
+
+// runWG runs the wg command with the provided arguments and input, and returns
+// its output. Declared as a variable for testing.
+var runWG = func(input string, args ...string) (string, error) {
+	cmd := exec.Command("wg", args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running wg %s: %v: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return string(output), nil
+}
+
+type client struct {
+	wireGuardConfig *config.WireGuardConfig
+
+	mutex sync.Mutex
+	// peerPublicKeys maps the name of the peer Nodes to the public keys of
+	// their configured peers.
+	peerPublicKeys map[string]string
+}
+
+// New returns a WireGuard client, which configures the device with the
+// provided name with the wg command.
+func New(wireGuardConfig *config.WireGuardConfig) (Interface, error) {
+	return &client{
+		wireGuardConfig: wireGuardConfig,
+		peerPublicKeys:  map[string]string{},
+	}, nil
+}
+
+func (c *client) Init() (string, error) {
+	name := c.wireGuardConfig.Name
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); !ok {
+			return "", fmt.Errorf("error when getting WireGuard device %s: %v", name, err)
+		}
+		link = &netlink.GenericLink{LinkAttrs: netlink.LinkAttrs{Name: name}, LinkType: "wireguard"}
+		if err := netlink.LinkAdd(link); err != nil {
+			return "", fmt.Errorf("error when creating WireGuard device %s: %v", name, err)
+		}
+		klog.Infof("Created WireGuard device %s", name)
+		if link, err = netlink.LinkByName(name); err != nil {
+			return "", fmt.Errorf("error when getting WireGuard device %s: %v", name, err)
+		}
+	}
+	if c.wireGuardConfig.MTU > 0 && link.Attrs().MTU != c.wireGuardConfig.MTU {
+		if err := netlink.LinkSetMTU(link, c.wireGuardConfig.MTU); err != nil {
+			return "", fmt.Errorf("error when setting MTU of WireGuard device %s: %v", name, err)
+		}
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return "", fmt.Errorf("error when setting WireGuard device %s up: %v", name, err)
+	}
+	c.wireGuardConfig.LinkIndex = link.Attrs().Index
+
+	output, err := runWG("", "show", name, "private-key")
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := parseKey(strings.TrimSpace(output))
+	if err != nil {
+		// The device was just created and has no private key yet.
+		if privateKey, err = generatePrivateKey(); err != nil {
+			return "", err
+		}
+		klog.Infof("Generated private key for WireGuard device %s", name)
+	}
+	if _, err := runWG(base64.StdEncoding.EncodeToString(privateKey), "set", name,
+		"listen-port", strconv.Itoa(c.wireGuardConfig.Port), "private-key", "/dev/stdin"); err != nil {
+		return "", err
+	}
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+func (c *client) UpdatePeer(nodeName, publicKey string, peerNodeIP net.IP, podCIDRs []*net.IPNet) error {
+	if _, err := parseKey(publicKey); err != nil {
+		return fmt.Errorf("invalid WireGuard public key of Node %s: %v", nodeName, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if oldPublicKey, ok := c.peerPublicKeys[nodeName]; ok && oldPublicKey != publicKey {
+		klog.Infof("WireGuard public key of Node %s changed, removing the previous peer", nodeName)
+		if err := c.removePeer(oldPublicKey); err != nil {
+			return err
+		}
+		delete(c.peerPublicKeys, nodeName)
+	}
+	allowedIPs := make([]string, 0, len(podCIDRs))
+	for _, podCIDR := range podCIDRs {
+		allowedIPs = append(allowedIPs, podCIDR.String())
+	}
+	endpoint := net.JoinHostPort(peerNodeIP.String(), strconv.Itoa(c.wireGuardConfig.Port))
+	if _, err := runWG("", "set", c.wireGuardConfig.Name, "peer", publicKey,
+		"endpoint", endpoint, "allowed-ips", strings.Join(allowedIPs, ",")); err != nil {
+		return err
+	}
+	c.peerPublicKeys[nodeName] = publicKey
+	return nil
+}
+
+func (c *client) DeletePeer(nodeName string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	publicKey, ok := c.peerPublicKeys[nodeName]
+	if !ok {
+		return nil
+	}
+	if err := c.removePeer(publicKey); err != nil {
+		return err
+	}
+	delete(c.peerPublicKeys, nodeName)
+	return nil
+}
+
+func (c *client) RemoveStalePeers(currentPeerPublicKeys map[string]string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	desired := make(map[string]string, len(currentPeerPublicKeys))
+	for nodeName, publicKey := range currentPeerPublicKeys {
+		desired[publicKey] = nodeName
+	}
+	output, err := runWG("", "show", c.wireGuardConfig.Name, "peers")
+	if err != nil {
+		return err
+	}
+	for _, publicKey := range strings.Fields(output) {
+		if nodeName, ok := desired[publicKey]; ok {
+			c.peerPublicKeys[nodeName] = publicKey
+			continue
+		}
+		klog.Infof("Removing stale WireGuard peer %s", publicKey)
+		if err := c.removePeer(publicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) removePeer(publicKey string) error {
+	_, err := runWG("", "set", c.wireGuardConfig.Name, "peer", publicKey, "remove")
+	return err
+}
+
+func parseKey(key string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != keyLen {
+		return nil, fmt.Errorf("key should be %d bytes, got %d", keyLen, len(decoded))
+	}
+	return decoded, nil
+}
+
+// generatePrivateKey returns a random Curve25519 private key, clamped as
+// described in https://cr.yp.to/ecdh.html.
+func generatePrivateKey() ([]byte, error) {
+	key := make([]byte, keyLen)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error when generating WireGuard private key: %v", err)
+	}
+	key[0] &= 248
+	key[31] &= 127
+	key[31] |= 64
+	return key, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware-tanzu/antrea/pkg/agent/config"
+)
+
+func newTestKey(t *testing.T) string {
+	key, err := generatePrivateKey()
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// fakeWG records the wg commands and returns the configured output of the
+// "show" commands.
+type fakeWG struct {
+	commands []string
+	peers    []string
+}
+
+func (f *fakeWG) run(input string, args ...string) (string, error) {
+	if args[0] == "show" {
+		return strings.Join(f.peers, "\n") + "\n", nil
+	}
+	f.commands = append(f.commands, strings.Join(args, " "))
+	return "", nil
+}
+
+func newTestClient(t *testing.T) (*client, *fakeWG) {
+	fake := &fakeWG{}
+	originalRunWG := runWG
+	runWG = fake.run
+	t.Cleanup(func() { runWG = originalRunWG })
+	c, err := New(&config.WireGuardConfig{Name: "antrea-wg0", Port: 51820})
+	require.NoError(t, err)
+	return c.(*client), fake
+}
+
+func TestUpdatePeer(t *testing.T) {
+	c, fake := newTestClient(t)
+	key1, key2 := newTestKey(t), newTestKey(t)
+	_, podCIDRv4, _ := net.ParseCIDR("10.10.1.0/24")
+	_, podCIDRv6, _ := net.ParseCIDR("fd00:10:10:1::/64")
+
+	require.NoError(t, c.UpdatePeer("node1", key1, net.ParseIP("192.168.0.11"), []*net.IPNet{podCIDRv4, podCIDRv6}))
+	assert.Equal(t, []string{
+		"set antrea-wg0 peer " + key1 + " endpoint 192.168.0.11:51820 allowed-ips 10.10.1.0/24,fd00:10:10:1::/64",
+	}, fake.commands)
+
+	// The previous peer is removed when the public key changes.
+	fake.commands = nil
+	require.NoError(t, c.UpdatePeer("node1", key2, net.ParseIP("fd00::11"), []*net.IPNet{podCIDRv6}))
+	assert.Equal(t, []string{
+		"set antrea-wg0 peer " + key1 + " remove",
+		"set antrea-wg0 peer " + key2 + " endpoint [fd00::11]:51820 allowed-ips fd00:10:10:1::/64",
+	}, fake.commands)
+
+	fake.commands = nil
+	require.NoError(t, c.DeletePeer("node1"))
+	require.NoError(t, c.DeletePeer("node1"))
+	assert.Equal(t, []string{"set antrea-wg0 peer " + key2 + " remove"}, fake.commands)
+
+	assert.Error(t, c.UpdatePeer("node1", "invalid", net.ParseIP("192.168.0.11"), []*net.IPNet{podCIDRv4}))
+}
+
+func TestRemoveStalePeers(t *testing.T) {
+	c, fake := newTestClient(t)
+	key1, key2, staleKey := newTestKey(t), newTestKey(t), newTestKey(t)
+	fake.peers = []string{key1, staleKey}
+
+	require.NoError(t, c.RemoveStalePeers(map[string]string{"node1": key1, "node2": key2}))
+	assert.Equal(t, []string{"set antrea-wg0 peer " + staleKey + " remove"}, fake.commands)
+	// The existing peers are known after the reconciliation.
+	assert.Equal(t, map[string]string{"node1": key1}, c.peerPublicKeys)
+}
+
+func TestGeneratePrivateKey(t *testing.T) {
+	key, err := generatePrivateKey()
+	require.NoError(t, err)
+	require.Len(t, key, keyLen)
+	assert.Equal(t, byte(0), key[0]&7)
+	assert.Equal(t, byte(64), key[31]&192)
+	_, err = parseKey(base64.StdEncoding.EncodeToString(key))
+	assert.NoError(t, err)
+	_, err = parseKey(base64.StdEncoding.EncodeToString(key[:16]))
+	assert.Error(t, err)
+}
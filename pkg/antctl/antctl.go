@@ -23,6 +23,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/ovstracing"
 	"github.com/vmware-tanzu/antrea/pkg/agent/apiserver/handlers/podinterface"
 	"github.com/vmware-tanzu/antrea/pkg/agent/openflow"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/raw/policyrecommendation"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/raw/proxy"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/raw/supportbundle"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/raw/traceflow"
@@ -30,6 +31,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/appliedtogroup"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/controllerinfo"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/networkpolicy"
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/networkpolicyevaluation"
 	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/version"
 	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
 	systemv1beta1 "github.com/vmware-tanzu/antrea/pkg/apis/system/v1beta1"
@@ -407,6 +409,55 @@ var CommandList = &commandList{
 			},
 			transformedResponse: reflect.TypeOf(controllernetworkpolicy.EndpointQueryResponse{}),
 		},
+		{
+			use:     "networkpolicyevaluation",
+			aliases: []string{"netpoleval"},
+			short:   "Evaluate network policies for traffic between two endpoints.",
+			long:    "Evaluate the K8s NetworkPolicies and Antrea-native policies for traffic from a source to a destination, and print whether the traffic is allowed along with the rules deciding it. Draft policies can be provided to evaluate their effect before applying them.",
+			example: `  Evaluate whether Pod ns1/client can reach Pod ns2/server on TCP port 80
+  $ antctl query networkpolicyevaluation --source ns1/client --destination ns2/server --port 80
+  Evaluate whether Pod ns1/client can reach the backends of Service ns2/web on UDP port 53
+  $ antctl query networkpolicyevaluation --source ns1/client --destination svc:ns2/web --protocol UDP --port 53
+  Evaluate whether IP 10.0.0.1 can reach Pod ns2/server on TCP port 80 if the policies in draft.yaml were applied
+  $ antctl query networkpolicyevaluation --source 10.0.0.1 --destination ns2/server --port 80 -f draft.yaml
+`,
+			commandGroup: query,
+			controllerEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/networkpolicyevaluation",
+					params: []flagInfo{
+						{
+							name:  "source",
+							usage: "Source of the traffic, either a Pod in the form of <namespace>/<name> or an IP address",
+						},
+						{
+							name:  "destination",
+							usage: "Destination of the traffic, either a Pod in the form of <namespace>/<name>, a Service in the form of svc:<namespace>/<name>, or an IP address",
+						},
+						{
+							name:            "protocol",
+							usage:           "Protocol of the traffic",
+							defaultValue:    "TCP",
+							supportedValues: []string{"TCP", "UDP", "SCTP"},
+						},
+						{
+							name:  "port",
+							usage: "Destination port of the traffic, which is the Service port if the destination is a Service",
+						},
+						{
+							name:      "policy",
+							usage:     "Path to a YAML file of draft policies to evaluate as if they were applied",
+							shorthand: "f",
+							isFile:    true,
+						},
+					},
+					outputType: multiple,
+					postParams: true,
+				},
+				addonTransform: networkpolicyevaluation.Transform,
+			},
+			transformedResponse: reflect.TypeOf(networkpolicyevaluation.Response{}),
+		},
 	},
 	rawCommands: []rawCommand{
 		{
@@ -424,6 +475,11 @@ var CommandList = &commandList{
 			supportAgent:      false,
 			supportController: true,
 		},
+		{
+			cobraCommand:      policyrecommendation.Command,
+			supportAgent:      true,
+			supportController: true,
+		},
 	},
 	codec: scheme.Codecs,
 }
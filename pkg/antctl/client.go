@@ -17,6 +17,7 @@ package antctl
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -107,14 +108,23 @@ func (c *client) nonResourceRequest(e *nonResourceEndpoint, opt *requestOption)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rest client: %w", err)
 	}
-	u := url.URL{Path: e.path}
-	q := u.Query()
-	for k, v := range opt.args {
-		q.Set(k, v)
+	var request *rest.Request
+	if e.postParams {
+		body, err := json.Marshal(opt.args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode params: %w", err)
+		}
+		request = restClient.Post().AbsPath(e.path).SetHeader("Content-Type", "application/json").Body(body)
+	} else {
+		u := url.URL{Path: e.path}
+		q := u.Query()
+		for k, v := range opt.args {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+		request = restClient.Get().RequestURI(u.RequestURI())
 	}
-	u.RawQuery = q.Encode()
-	getter := restClient.Get().RequestURI(u.RequestURI()).Timeout(opt.timeout)
-	result, err := getter.DoRaw(context.TODO())
+	result, err := request.Timeout(opt.timeout).DoRaw(context.TODO())
 	if err != nil {
 		statusErr, ok := err.(*errors.StatusError)
 		if !ok {
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"sort"
@@ -143,6 +144,10 @@ type nonResourceEndpoint struct {
 	path       string
 	params     []flagInfo
 	outputType OutputType
+	// postParams indicates the params are sent as a JSON object in the body of a
+	// POST request instead of the query of a GET request, for params which may be
+	// too large for a URL, e.g. the content of a file.
+	postParams bool
 }
 
 func (e *nonResourceEndpoint) flags() []flagInfo {
@@ -171,6 +176,9 @@ type flagInfo struct {
 	supportedValues []string
 	arg             bool
 	usage           string
+	// isFile indicates the flag value is the path of a local file, whose content is sent
+	// as the parameter value instead of the path.
+	isFile bool
 }
 
 // rawCommand defines a full function cobra.Command which lets developers
@@ -724,13 +732,13 @@ func (cd *commandDefinition) output(resp io.Reader, writer io.Writer, ft formatt
 			if cd.controllerEndpoint.nonResourceEndpoint.path == "/endpoint" {
 				return cd.tableOutputForQueryEndpoint(obj, writer)
 			}
+			return cd.tableOutputForGetCommands(obj, writer)
 		} else {
 			return cd.tableOutput(obj, writer)
 		}
 	default:
 		return fmt.Errorf("unsupported format type: %v", ft)
 	}
-	return nil
 }
 
 func (cd *commandDefinition) collectFlags(cmd *cobra.Command, args []string) (map[string]string, error) {
@@ -747,6 +755,13 @@ func (cd *commandDefinition) collectFlags(cmd *cobra.Command, args []string) (ma
 					if f.supportedValues != nil && !cd.validateFlagValue(vs, f.supportedValues) {
 						return nil, fmt.Errorf("unsupported value %s for flag %s", vs, f.name)
 					}
+					if f.isFile {
+						content, err := ioutil.ReadFile(vs)
+						if err != nil {
+							return nil, fmt.Errorf("error when reading file %s for flag %s: %w", vs, f.name, err)
+						}
+						vs = string(content)
+					}
 					argMap[f.name] = vs
 					continue
 				}
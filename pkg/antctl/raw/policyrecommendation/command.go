@@ -0,0 +1,168 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyrecommendation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/antctl/runtime"
+)
+
+var (
+	Command *cobra.Command
+	option  = &struct {
+		file       string
+		start      string
+		end        string
+		policyType string
+		labelKeys  string
+		namespace  string
+	}{}
+)
+
+func init() {
+	Command = &cobra.Command{
+		Use:     "policyrecommendation",
+		Short:   "Recommend NetworkPolicies from flow records",
+		Long:    "Recommend least-privilege NetworkPolicies for the applications in the cluster from the flow records exported by the Flow Exporter. The flow records must be provided as JSON objects, one per line, whose keys are the names of the IPFIX information elements, at the top level or in the \"ipfix\" field as stored by the ELK flow collector. The Pods of an application are grouped by workload labels, and a policy which allows the observed traffic only is generated for each application.",
+		Aliases: []string{"recommendpolicy", "pr"},
+		Example: `  Recommend K8s NetworkPolicies from the flow records in flows.json
+  $antctl policyrecommendation -f flows.json
+  Recommend Antrea NetworkPolicies for the applications in Namespace ns1, from the flow records observed in a time window
+  $antctl policyrecommendation -f flows.json --type anp -n ns1 --start 2020-11-01T00:00:00Z --end 2020-11-08T00:00:00Z
+  Recommend K8s NetworkPolicies for applications identified by the "app" and "tier" labels, reading the flow records from stdin
+  $cat flows.json | antctl policyrecommendation -f - --label-keys app,tier
+`,
+		RunE: runE,
+	}
+
+	Command.Flags().StringVarP(&option.file, "file", "f", "", "path of the file containing the flow records, or - to read them from stdin")
+	Command.Flags().StringVarP(&option.start, "start", "", "", "start of the time window of the flow records in RFC 3339 format, e.g. 2020-11-01T00:00:00Z")
+	Command.Flags().StringVarP(&option.end, "end", "", "", "end of the time window of the flow records in RFC 3339 format, e.g. 2020-11-08T00:00:00Z")
+	Command.Flags().StringVarP(&option.policyType, "type", "", policyTypeK8s, "type of the recommended policies: k8s (default) for K8s NetworkPolicies, anp for Antrea NetworkPolicies")
+	Command.Flags().StringVarP(&option.labelKeys, "label-keys", "", "app", "comma-separated keys of the Pod labels identifying an application; all the labels of a Pod are used if it has none of them")
+	Command.Flags().StringVarP(&option.namespace, "namespace", "n", "", "only recommend policies for the applications in this Namespace")
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func runE(cmd *cobra.Command, _ []string) error {
+	if option.file == "" {
+		return fmt.Errorf("please provide the file containing the flow records")
+	}
+	if option.policyType != policyTypeK8s && option.policyType != policyTypeAntrea {
+		return fmt.Errorf("policy type should be %s or %s", policyTypeK8s, policyTypeAntrea)
+	}
+	start, err := parseTime(option.start)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %w", err)
+	}
+	end, err := parseTime(option.end)
+	if err != nil {
+		return fmt.Errorf("invalid end time: %w", err)
+	}
+
+	var reader io.Reader = os.Stdin
+	if option.file != "-" {
+		f, err := os.Open(option.file)
+		if err != nil {
+			return fmt.Errorf("error when opening flow records file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+	records, err := parseFlowRecords(reader)
+	if err != nil {
+		return err
+	}
+
+	kubeconfigPath, err := cmd.Flags().GetString("kubeconfig")
+	if err != nil {
+		return err
+	}
+	kubeconfig, err := runtime.ResolveKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	k8sclient, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("error when creating kubernetes clientset: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pods, err := k8sclient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error when listing Pods: %w", err)
+	}
+	namespaces, err := k8sclient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error when listing Namespaces: %w", err)
+	}
+
+	var labelKeys []string
+	for _, k := range strings.Split(option.labelKeys, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			labelKeys = append(labelKeys, k)
+		}
+	}
+	r := newRecommender(pods.Items, namespaces.Items, labelKeys, option.policyType, option.namespace)
+	if skipped := r.addFlowRecords(records, start, end); skipped > 0 {
+		klog.Warningf("Skipped %d flow records whose protocol or endpoints cannot be matched by policy rules", skipped)
+	}
+	return output(r.recommend(), cmd.OutOrStdout())
+}
+
+// output writes the policies to the writer as a multi-document YAML stream.
+func output(policies []k8sruntime.Object, writer io.Writer) error {
+	for i, policy := range policies {
+		if i > 0 {
+			if _, err := fmt.Fprintln(writer, "---"); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(policy)
+		if err != nil {
+			return fmt.Errorf("error when encoding policy: %w", err)
+		}
+		// The policy is converted to YAML through a generic object so that the field names
+		// and the omitempty tags of the JSON encoding are honored.
+		var obj yaml.MapSlice
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("error when encoding policy: %w", err)
+		}
+		if err := yaml.NewEncoder(writer).Encode(obj); err != nil {
+			return fmt.Errorf("error when encoding policy: %w", err)
+		}
+	}
+	return nil
+}
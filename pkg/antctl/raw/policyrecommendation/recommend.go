@@ -0,0 +1,570 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyrecommendation
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+const (
+	policyTypeK8s    = "k8s"
+	policyTypeAntrea = "anp"
+
+	// policyNamePrefix is the prefix of the names of the recommended policies.
+	policyNamePrefix = "recommend-"
+	// recommendedPolicyPriority is the priority of the recommended Antrea NetworkPolicies.
+	recommendedPolicyPriority = 5
+)
+
+// ignoredLabelKeys are the keys of the labels added to Pods by workload controllers, which
+// differ between Pods of the same application.
+var ignoredLabelKeys = map[string]bool{
+	"pod-template-hash":                  true,
+	"controller-revision-hash":           true,
+	"pod-template-generation":            true,
+	"statefulset.kubernetes.io/pod-name": true,
+}
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// unixTime is a time decoded either from seconds since the epoch or from a RFC 3339 string.
+type unixTime struct {
+	time.Time
+}
+
+func (t *unixTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+			t.Time = time.Unix(seconds, 0)
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		t.Time = parsed
+		return nil
+	}
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	t.Time = time.Unix(seconds, 0)
+	return nil
+}
+
+// flowRecord is a flow record exported by the Flow Exporter, decoded from a JSON object whose
+// keys are the names of the IPFIX information elements.
+type flowRecord struct {
+	FlowStartSeconds         unixTime `json:"flowStartSeconds"`
+	FlowEndSeconds           unixTime `json:"flowEndSeconds"`
+	SourceIPv4Address        string   `json:"sourceIPv4Address"`
+	SourceIPv6Address        string   `json:"sourceIPv6Address"`
+	DestinationIPv4Address   string   `json:"destinationIPv4Address"`
+	DestinationIPv6Address   string   `json:"destinationIPv6Address"`
+	DestinationTransportPort uint16   `json:"destinationTransportPort"`
+	ProtocolIdentifier       uint8    `json:"protocolIdentifier"`
+	SourcePodNamespace       string   `json:"sourcePodNamespace"`
+	SourcePodName            string   `json:"sourcePodName"`
+	DestinationPodNamespace  string   `json:"destinationPodNamespace"`
+	DestinationPodName       string   `json:"destinationPodName"`
+}
+
+func (r *flowRecord) sourceIP() string {
+	if r.SourceIPv4Address != "" {
+		return r.SourceIPv4Address
+	}
+	return r.SourceIPv6Address
+}
+
+func (r *flowRecord) destinationIP() string {
+	if r.DestinationIPv4Address != "" {
+		return r.DestinationIPv4Address
+	}
+	return r.DestinationIPv6Address
+}
+
+// parseFlowRecords decodes flow records from a stream of JSON objects, one per line. The
+// information elements can be set either at the top level of the objects or in their "ipfix"
+// field, as stored by the ELK flow collector.
+func parseFlowRecords(reader io.Reader) ([]flowRecord, error) {
+	var records []flowRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return nil, fmt.Errorf("invalid flow record at line %d: %v", lineNum, err)
+		}
+		data := []byte(line)
+		if ipfix, ok := fields["ipfix"]; ok {
+			data = ipfix
+		}
+		var record flowRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("invalid flow record at line %d: %v", lineNum, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error when reading flow records: %v", err)
+	}
+	return records, nil
+}
+
+// application is a group of Pods in a Namespace sharing the same workload labels.
+type application struct {
+	namespace string
+	labels    map[string]string
+}
+
+func (a *application) key() string {
+	return a.namespace + "/" + labels.SelectorFromSet(a.labels).String()
+}
+
+// endpoint is one end of a flow, either an application or an IP address outside of the cluster
+// or not selectable by labels.
+type endpoint struct {
+	app *application
+	ip  string
+}
+
+func (e *endpoint) key() string {
+	if e.app != nil {
+		return e.app.key()
+	}
+	return e.ip
+}
+
+type portKey struct {
+	protocol corev1.Protocol
+	port     int32
+}
+
+// peerPorts are the ports on which traffic was observed between an application and a peer.
+type peerPorts struct {
+	peer endpoint
+	// ips are the IP addresses of the peer application, used to select it if its Namespace
+	// cannot be selected by labels.
+	ips   map[string]bool
+	ports map[portKey]bool
+}
+
+// applicationFlows aggregates the flows observed for an application.
+type applicationFlows struct {
+	app     *application
+	ingress map[string]*peerPorts
+	egress  map[string]*peerPorts
+}
+
+// recommender generates least-privilege policies for the applications from the flows observed
+// between them.
+type recommender struct {
+	// labelKeys are the keys of the Pod labels identifying an application. If a Pod has none of
+	// them, all its labels except the ones in ignoredLabelKeys are used.
+	labelKeys  []string
+	policyType string
+	// namespace limits the recommendation to the applications in a Namespace if not empty.
+	namespace  string
+	pods       map[string]*corev1.Pod
+	podsByIP   map[string]*corev1.Pod
+	namespaces map[string]*corev1.Namespace
+	flows      map[string]*applicationFlows
+}
+
+func newRecommender(pods []corev1.Pod, namespaces []corev1.Namespace, labelKeys []string, policyType, namespace string) *recommender {
+	r := &recommender{
+		labelKeys:  labelKeys,
+		policyType: policyType,
+		namespace:  namespace,
+		pods:       map[string]*corev1.Pod{},
+		podsByIP:   map[string]*corev1.Pod{},
+		namespaces: map[string]*corev1.Namespace{},
+		flows:      map[string]*applicationFlows{},
+	}
+	for i := range pods {
+		pod := &pods[i]
+		r.pods[pod.Namespace+"/"+pod.Name] = pod
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		for _, podIP := range pod.Status.PodIPs {
+			r.podsByIP[podIP.IP] = pod
+		}
+	}
+	for i := range namespaces {
+		r.namespaces[namespaces[i].Name] = &namespaces[i]
+	}
+	return r
+}
+
+// getApplication returns the application of a Pod, or nil if the Pod cannot be selected by labels.
+func (r *recommender) getApplication(pod *corev1.Pod) *application {
+	if pod.Spec.HostNetwork {
+		return nil
+	}
+	appLabels := map[string]string{}
+	for _, k := range r.labelKeys {
+		if v, ok := pod.Labels[k]; ok {
+			appLabels[k] = v
+		}
+	}
+	if len(appLabels) == 0 {
+		for k, v := range pod.Labels {
+			if !ignoredLabelKeys[k] {
+				appLabels[k] = v
+			}
+		}
+	}
+	if len(appLabels) == 0 {
+		return nil
+	}
+	return &application{namespace: pod.Namespace, labels: appLabels}
+}
+
+// resolveEndpoint resolves one end of a flow. It returns nil if the endpoint is unknown.
+func (r *recommender) resolveEndpoint(namespace, name, ip string) *endpoint {
+	var pod *corev1.Pod
+	if name != "" {
+		pod = r.pods[namespace+"/"+name]
+		if pod == nil {
+			klog.Warningf("Pod %s/%s of flow record was not found", namespace, name)
+			return nil
+		}
+	} else {
+		pod = r.podsByIP[ip]
+	}
+	if pod != nil {
+		if app := r.getApplication(pod); app != nil {
+			return &endpoint{app: app, ip: ip}
+		}
+	}
+	if net.ParseIP(ip) == nil {
+		return nil
+	}
+	return &endpoint{ip: ip}
+}
+
+func protocolFromIdentifier(identifier uint8) corev1.Protocol {
+	switch identifier {
+	case 6:
+		return corev1.ProtocolTCP
+	case 17:
+		return corev1.ProtocolUDP
+	case 132:
+		return corev1.ProtocolSCTP
+	}
+	return ""
+}
+
+// addFlowRecords adds the flow records observed in the time window to the recommender. A zero
+// start or end time means the window is unbounded on that side. It returns the number of
+// records which were skipped because they cannot be expressed by policy rules.
+func (r *recommender) addFlowRecords(records []flowRecord, start, end time.Time) int {
+	skipped := 0
+	for i := range records {
+		record := &records[i]
+		if !start.IsZero() && record.FlowEndSeconds.Before(start) || !end.IsZero() && record.FlowStartSeconds.After(end) {
+			continue
+		}
+		protocol := protocolFromIdentifier(record.ProtocolIdentifier)
+		if protocol == "" || record.DestinationTransportPort == 0 {
+			skipped++
+			continue
+		}
+		src := r.resolveEndpoint(record.SourcePodNamespace, record.SourcePodName, record.sourceIP())
+		dst := r.resolveEndpoint(record.DestinationPodNamespace, record.DestinationPodName, record.destinationIP())
+		if src == nil || dst == nil {
+			skipped++
+			continue
+		}
+		port := portKey{protocol: protocol, port: int32(record.DestinationTransportPort)}
+		if dst.app != nil {
+			r.getApplicationFlows(dst.app).addPeer(true, src, port)
+		}
+		if src.app != nil {
+			r.getApplicationFlows(src.app).addPeer(false, dst, port)
+		}
+	}
+	return skipped
+}
+
+func (r *recommender) getApplicationFlows(app *application) *applicationFlows {
+	flows, ok := r.flows[app.key()]
+	if !ok {
+		flows = &applicationFlows{
+			app:     app,
+			ingress: map[string]*peerPorts{},
+			egress:  map[string]*peerPorts{},
+		}
+		r.flows[app.key()] = flows
+	}
+	return flows
+}
+
+func (f *applicationFlows) addPeer(ingress bool, peer *endpoint, port portKey) {
+	peers := f.egress
+	if ingress {
+		peers = f.ingress
+	}
+	pp, ok := peers[peer.key()]
+	if !ok {
+		pp = &peerPorts{peer: *peer, ips: map[string]bool{}, ports: map[portKey]bool{}}
+		peers[peer.key()] = pp
+	}
+	pp.ips[peer.ip] = true
+	pp.ports[port] = true
+}
+
+// recommend returns a policy for each application, sorted by Namespace and name.
+func (r *recommender) recommend() []runtime.Object {
+	var appKeys []string
+	for key, flows := range r.flows {
+		if r.namespace == "" || flows.app.namespace == r.namespace {
+			appKeys = append(appKeys, key)
+		}
+	}
+	sort.Strings(appKeys)
+	var policies []runtime.Object
+	usedNames := map[string]bool{}
+	for _, key := range appKeys {
+		flows := r.flows[key]
+		name := policyName(flows.app)
+		for i := 1; usedNames[flows.app.namespace+"/"+name]; i++ {
+			name = fmt.Sprintf("%s-%d", policyName(flows.app), i)
+		}
+		usedNames[flows.app.namespace+"/"+name] = true
+		if r.policyType == policyTypeAntrea {
+			policies = append(policies, r.toAntreaNetworkPolicy(name, flows))
+		} else {
+			policies = append(policies, r.toK8sNetworkPolicy(name, flows))
+		}
+	}
+	return policies
+}
+
+// policyName generates a name for the policy of an application from its label values.
+func policyName(app *application) string {
+	keys := make([]string, 0, len(app.labels))
+	for k := range app.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, app.labels[k])
+	}
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(strings.Join(values, "-")), "-")
+	name = policyNamePrefix + strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// peerRule is a group of peers allowed on the same ports.
+type peerRule struct {
+	peers []*peerPorts
+	ports []portKey
+}
+
+// groupPeersByPorts groups the peers which are allowed on the same ports, to generate one rule
+// for each group.
+func groupPeersByPorts(peers map[string]*peerPorts) []*peerRule {
+	var peerKeys []string
+	for key := range peers {
+		peerKeys = append(peerKeys, key)
+	}
+	sort.Strings(peerKeys)
+	rulesByPorts := map[string]*peerRule{}
+	var rules []*peerRule
+	for _, key := range peerKeys {
+		pp := peers[key]
+		var ports []portKey
+		for port := range pp.ports {
+			ports = append(ports, port)
+		}
+		sort.Slice(ports, func(i, j int) bool {
+			if ports[i].protocol != ports[j].protocol {
+				return ports[i].protocol < ports[j].protocol
+			}
+			return ports[i].port < ports[j].port
+		})
+		portsKey := fmt.Sprint(ports)
+		rule, ok := rulesByPorts[portsKey]
+		if !ok {
+			rule = &peerRule{ports: ports}
+			rulesByPorts[portsKey] = rule
+			rules = append(rules, rule)
+		}
+		rule.peers = append(rule.peers, pp)
+	}
+	return rules
+}
+
+// peerSelector is the selector of a peer in a rule of a policy.
+type peerSelector struct {
+	podSelector       *metav1.LabelSelector
+	namespaceSelector *metav1.LabelSelector
+	cidr              string
+}
+
+// toPeerSelectors returns the selectors of a peer of the policy of an application in a
+// Namespace. An application in another Namespace is selected by the labels of its Namespace, or
+// by its IP addresses if its Namespace has no labels.
+func (r *recommender) toPeerSelectors(policyNamespace string, pp *peerPorts) []peerSelector {
+	if pp.peer.app != nil {
+		app := pp.peer.app
+		podSelector := &metav1.LabelSelector{MatchLabels: app.labels}
+		if app.namespace == policyNamespace {
+			return []peerSelector{{podSelector: podSelector}}
+		}
+		if ns, ok := r.namespaces[app.namespace]; ok && len(ns.Labels) > 0 {
+			return []peerSelector{{podSelector: podSelector, namespaceSelector: &metav1.LabelSelector{MatchLabels: ns.Labels}}}
+		}
+		klog.Warningf("Namespace %s has no labels, selecting the Pods of application %s by IP addresses", app.namespace, app.key())
+	}
+	var ips []string
+	for ip := range pp.ips {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	selectors := make([]peerSelector, 0, len(ips))
+	for _, ip := range ips {
+		cidr := ip + "/32"
+		if net.ParseIP(ip).To4() == nil {
+			cidr = ip + "/128"
+		}
+		selectors = append(selectors, peerSelector{cidr: cidr})
+	}
+	return selectors
+}
+
+func (r *recommender) toK8sNetworkPolicy(name string, flows *applicationFlows) *networkingv1.NetworkPolicy {
+	namespace := flows.app.namespace
+	toPeers := func(rule *peerRule) []networkingv1.NetworkPolicyPeer {
+		var peers []networkingv1.NetworkPolicyPeer
+		for _, pp := range rule.peers {
+			for _, s := range r.toPeerSelectors(namespace, pp) {
+				peer := networkingv1.NetworkPolicyPeer{PodSelector: s.podSelector, NamespaceSelector: s.namespaceSelector}
+				if s.cidr != "" {
+					peer.IPBlock = &networkingv1.IPBlock{CIDR: s.cidr}
+				}
+				peers = append(peers, peer)
+			}
+		}
+		return peers
+	}
+	toPorts := func(rule *peerRule) []networkingv1.NetworkPolicyPort {
+		ports := make([]networkingv1.NetworkPolicyPort, 0, len(rule.ports))
+		for _, p := range rule.ports {
+			protocol, port := p.protocol, intstr.FromInt(int(p.port))
+			ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+		}
+		return ports
+	}
+	np := &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: flows.app.labels},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	for _, rule := range groupPeersByPorts(flows.ingress) {
+		np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: toPeers(rule), Ports: toPorts(rule)})
+	}
+	for _, rule := range groupPeersByPorts(flows.egress) {
+		np.Spec.Egress = append(np.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: toPeers(rule), Ports: toPorts(rule)})
+	}
+	return np
+}
+
+func (r *recommender) toAntreaNetworkPolicy(name string, flows *applicationFlows) *secv1alpha1.NetworkPolicy {
+	namespace := flows.app.namespace
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	toRules := func(peers map[string]*peerPorts, ingress bool) []secv1alpha1.Rule {
+		var rules []secv1alpha1.Rule
+		for i, pr := range groupPeersByPorts(peers) {
+			rule := secv1alpha1.Rule{Action: &allowAction}
+			for _, pp := range pr.peers {
+				for _, s := range r.toPeerSelectors(namespace, pp) {
+					peer := secv1alpha1.NetworkPolicyPeer{PodSelector: s.podSelector, NamespaceSelector: s.namespaceSelector}
+					if s.cidr != "" {
+						peer.IPBlock = &secv1alpha1.IPBlock{CIDR: s.cidr}
+					}
+					if ingress {
+						rule.From = append(rule.From, peer)
+					} else {
+						rule.To = append(rule.To, peer)
+					}
+				}
+			}
+			for _, p := range pr.ports {
+				protocol, port := p.protocol, intstr.FromInt(int(p.port))
+				rule.Ports = append(rule.Ports, secv1alpha1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+			}
+			if ingress {
+				rule.Name = fmt.Sprintf("allow-ingress-%d", i)
+			} else {
+				rule.Name = fmt.Sprintf("allow-egress-%d", i)
+			}
+			rules = append(rules, rule)
+		}
+		// Drop all the traffic which was not observed.
+		if ingress {
+			return append(rules, secv1alpha1.Rule{Action: &dropAction, Name: "default-drop-ingress"})
+		}
+		return append(rules, secv1alpha1.Rule{Action: &dropAction, Name: "default-drop-egress"})
+	}
+	return &secv1alpha1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: secv1alpha1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: secv1alpha1.NetworkPolicySpec{
+			Tier:      "application",
+			Priority:  recommendedPolicyPriority,
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: flows.app.labels}}},
+			Ingress:   toRules(flows.ingress, true),
+			Egress:    toRules(flows.egress, false),
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyrecommendation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+const testFlowRecords = `
+{"flowStartSeconds": 1604188800, "flowEndSeconds": 1604188860, "sourceIPv4Address": "10.0.0.1", "destinationIPv4Address": "10.0.0.2", "destinationTransportPort": 80, "protocolIdentifier": 6, "sourcePodNamespace": "ns1", "sourcePodName": "web-1", "destinationPodNamespace": "", "destinationPodName": ""}
+{"@timestamp": "2020-11-01T00:01:00Z", "ipfix": {"flowStartSeconds": "2020-11-01T00:00:00Z", "flowEndSeconds": "2020-11-01T00:01:00Z", "sourceIPv4Address": "10.0.0.3", "destinationIPv4Address": "10.0.0.2", "destinationTransportPort": 80, "protocolIdentifier": 6, "destinationPodNamespace": "ns1", "destinationPodName": "db-1"}}
+{"flowStartSeconds": 1604188800, "flowEndSeconds": 1604188860, "sourceIPv4Address": "10.0.0.2", "destinationIPv4Address": "8.8.8.8", "destinationTransportPort": 53, "protocolIdentifier": 17, "sourcePodNamespace": "ns1", "sourcePodName": "db-1"}
+{"flowStartSeconds": 1604188800, "flowEndSeconds": 1604188860, "sourceIPv4Address": "10.0.1.1", "destinationIPv4Address": "10.0.0.2", "destinationTransportPort": 80, "protocolIdentifier": 6, "sourcePodNamespace": "ns2", "sourcePodName": "client-1"}
+{"flowStartSeconds": 1604188800, "flowEndSeconds": 1604188860, "sourceIPv4Address": "10.0.0.1", "destinationIPv4Address": "10.0.0.2", "destinationTransportPort": 0, "protocolIdentifier": 1, "sourcePodNamespace": "ns1", "sourcePodName": "web-1"}
+{"flowStartSeconds": 1604793600, "flowEndSeconds": 1604793660, "sourceIPv4Address": "10.0.0.1", "destinationIPv4Address": "10.0.0.2", "destinationTransportPort": 443, "protocolIdentifier": 6, "sourcePodNamespace": "ns1", "sourcePodName": "web-1"}
+`
+
+func newTestPod(namespace, name, ip string, podLabels map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Status:     corev1.PodStatus{PodIP: ip, PodIPs: []corev1.PodIP{{IP: ip}}},
+	}
+}
+
+func newTestRecommender(policyType string) *recommender {
+	pods := []corev1.Pod{
+		newTestPod("ns1", "web-1", "10.0.0.1", map[string]string{"app": "web", "pod-template-hash": "abc"}),
+		newTestPod("ns1", "db-1", "10.0.0.2", map[string]string{"app": "db", "pod-template-hash": "def"}),
+		newTestPod("ns1", "web-2", "10.0.0.3", map[string]string{"app": "web", "pod-template-hash": "ghi"}),
+		newTestPod("ns2", "client-1", "10.0.1.1", map[string]string{"role": "client", "pod-template-hash": "jkl"}),
+	}
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"team": "client"}}},
+	}
+	return newRecommender(pods, namespaces, []string{"app"}, policyType, "")
+}
+
+func TestParseFlowRecords(t *testing.T) {
+	records, err := parseFlowRecords(strings.NewReader(testFlowRecords))
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, "10.0.0.3", records[1].sourceIP())
+	assert.Equal(t, "db-1", records[1].DestinationPodName)
+	assert.Equal(t, time.Unix(1604188800, 0).UTC(), records[1].FlowStartSeconds.UTC())
+
+	_, err = parseFlowRecords(strings.NewReader("{invalid"))
+	assert.Error(t, err)
+}
+
+func TestRecommendK8sNetworkPolicies(t *testing.T) {
+	records, err := parseFlowRecords(strings.NewReader(testFlowRecords))
+	require.NoError(t, err)
+	r := newTestRecommender(policyTypeK8s)
+	// The last flow record is out of the time window.
+	skipped := r.addFlowRecords(records, time.Unix(1604188800, 0), time.Unix(1604275200, 0))
+	assert.Equal(t, 1, skipped)
+	policies := r.recommend()
+	require.Len(t, policies, 3)
+
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	db := policies[0].(*networkingv1.NetworkPolicy)
+	assert.Equal(t, "recommend-db", db.Name)
+	assert.Equal(t, "ns1", db.Namespace)
+	assert.Equal(t, map[string]string{"app": "db"}, db.Spec.PodSelector.MatchLabels)
+	require.Len(t, db.Spec.Ingress, 1)
+	// Pods of application web and client are allowed on the same port, they are grouped in
+	// the same rule. The Pods of application client are selected with the labels of their
+	// Namespace.
+	assert.Equal(t, []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "client"}},
+		},
+	}, db.Spec.Ingress[0].From)
+	assert.Equal(t, &tcp, db.Spec.Ingress[0].Ports[0].Protocol)
+	assert.Equal(t, 80, db.Spec.Ingress[0].Ports[0].Port.IntValue())
+	require.Len(t, db.Spec.Egress, 1)
+	assert.Equal(t, &networkingv1.IPBlock{CIDR: "8.8.8.8/32"}, db.Spec.Egress[0].To[0].IPBlock)
+	assert.Equal(t, &udp, db.Spec.Egress[0].Ports[0].Protocol)
+
+	web := policies[1].(*networkingv1.NetworkPolicy)
+	assert.Equal(t, "recommend-web", web.Name)
+	assert.Empty(t, web.Spec.Ingress)
+	require.Len(t, web.Spec.Egress, 1)
+	assert.Equal(t, map[string]string{"app": "db"}, web.Spec.Egress[0].To[0].PodSelector.MatchLabels)
+
+	// The Pod has none of the label keys, all its labels except the ones added by workload
+	// controllers are used. The Pods of application db are selected by IP addresses as their
+	// Namespace has no labels.
+	client := policies[2].(*networkingv1.NetworkPolicy)
+	assert.Equal(t, "recommend-client", client.Name)
+	assert.Equal(t, map[string]string{"role": "client"}, client.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, &networkingv1.IPBlock{CIDR: "10.0.0.2/32"}, client.Spec.Egress[0].To[0].IPBlock)
+}
+
+func TestRecommendAntreaNetworkPolicies(t *testing.T) {
+	records, err := parseFlowRecords(strings.NewReader(testFlowRecords))
+	require.NoError(t, err)
+	r := newTestRecommender(policyTypeAntrea)
+	r.namespace = "ns1"
+	r.addFlowRecords(records, time.Time{}, time.Time{})
+	policies := r.recommend()
+	require.Len(t, policies, 2)
+
+	web := policies[1].(*secv1alpha1.NetworkPolicy)
+	assert.Equal(t, "recommend-web", web.Name)
+	assert.Equal(t, "application", web.Spec.Tier)
+	require.Len(t, web.Spec.Ingress, 1)
+	assert.Equal(t, secv1alpha1.RuleActionDrop, *web.Spec.Ingress[0].Action)
+	require.Len(t, web.Spec.Egress, 2)
+	assert.Equal(t, secv1alpha1.RuleActionAllow, *web.Spec.Egress[0].Action)
+	// Ports 80 and 443 were both observed for the same peer.
+	assert.Len(t, web.Spec.Egress[0].Ports, 2)
+	assert.Equal(t, secv1alpha1.RuleActionDrop, *web.Spec.Egress[1].Action)
+
+	var b bytes.Buffer
+	require.NoError(t, output(policies, &b))
+	assert.Contains(t, b.String(), "kind: NetworkPolicy")
+	assert.Contains(t, b.String(), "apiVersion: security.antrea.tanzu.vmware.com/v1alpha1")
+	assert.Contains(t, b.String(), "\n---\n")
+}
+
+func TestPolicyName(t *testing.T) {
+	app := &application{namespace: "ns1", labels: map[string]string{"app": "My_App", "tier": "front.end"}}
+	assert.Equal(t, "recommend-my-app-front-end", policyName(app))
+	app = &application{namespace: "ns1", labels: map[string]string{"app": strings.Repeat("a", 80)}}
+	assert.Len(t, policyName(app), 63)
+}
@@ -67,6 +67,11 @@ func (r Response) GetTableHeader() []string {
 func (r Response) GetPodNames(maxColumnLength int) string {
 	list := make([]string, len(r.Pods))
 	for i, pod := range r.Pods {
+		if pod.Node != nil {
+			// Nodes are cluster scoped.
+			list[i] = pod.Node.Name
+			continue
+		}
 		list[i] = pod.Pod.Namespace + "/" + pod.Pod.Name
 	}
 	return common.GenerateTableElementWithSummary(list, maxColumnLength)
@@ -25,6 +25,8 @@ import (
 
 type GroupMember struct {
 	Pod *cpv1beta.PodReference `json:"pod,omitempty"`
+	// Node maintains the reference to the Node when the Node itself is the member.
+	Node *cpv1beta.NodeReference `json:"node,omitempty"`
 	// IP maintains the IPAddresses associated with the Pod.
 	IP string `json:"ip,omitempty"`
 	// Ports maintain the named port mapping of this Pod.
@@ -39,7 +41,7 @@ func GroupMemberPodTransform(member cpv1beta.GroupMember) GroupMember {
 		}
 		ipStr += net.IP(ip).String()
 	}
-	return GroupMember{Pod: member.Pod, IP: ipStr, Ports: member.Ports}
+	return GroupMember{Pod: member.Pod, Node: member.Node, IP: ipStr, Ports: member.Ports}
 }
 
 type TableOutput interface {
@@ -0,0 +1,110 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicyevaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/vmware-tanzu/antrea/pkg/antctl/transform/common"
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+)
+
+type Response struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	Port        string `json:"port" yaml:"port"`
+	Verdict     string `json:"verdict" yaml:"verdict"`
+	EgressRule  string `json:"egressRule" yaml:"egressRule"`
+	IngressRule string `json:"ingressRule" yaml:"ingressRule"`
+}
+
+func endpointToString(ep networkpolicy.EvaluationEndpoint) string {
+	if ep.Name == "" {
+		return ep.IP
+	}
+	return fmt.Sprintf("%s/%s(%s)", ep.Namespace, ep.Name, ep.IP)
+}
+
+func ruleToString(r *networkpolicy.EvaluatedRule) string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	ruleStr := fmt.Sprintf("%s:%s %s-rule-%d", r.Type, name, strings.ToLower(string(r.Direction)), r.RuleIndex)
+	if r.RuleName != "" {
+		ruleStr += fmt.Sprintf("(%s)", r.RuleName)
+	}
+	ruleStr += " " + string(r.Action)
+	if r.Draft {
+		ruleStr += " [draft]"
+	}
+	return ruleStr
+}
+
+func directionToString(d networkpolicy.DirectionEvaluation) string {
+	var result string
+	if d.Rule != nil {
+		result = ruleToString(d.Rule)
+	} else if d.Isolated {
+		result = "<ISOLATED>"
+	} else {
+		result = "<NONE>"
+	}
+	for i := range d.AuditRules {
+		result += ", audited by " + ruleToString(&d.AuditRules[i])
+	}
+	return result
+}
+
+func Transform(reader io.Reader, _ bool, _ map[string]string) (interface{}, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	evaluationResponse := new(networkpolicy.EvaluationResponse)
+	if err := json.Unmarshal(b, evaluationResponse); err != nil {
+		return nil, err
+	}
+	result := []interface{}{}
+	for _, e := range evaluationResponse.Evaluations {
+		result = append(result, Response{
+			Source:      endpointToString(e.Source),
+			Destination: endpointToString(e.Destination),
+			Port:        fmt.Sprintf("%s/%d", e.Protocol, e.Port),
+			Verdict:     string(e.Verdict),
+			EgressRule:  directionToString(e.Egress),
+			IngressRule: directionToString(e.Ingress),
+		})
+	}
+	return result, nil
+}
+
+var _ common.TableOutput = new(Response)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"SOURCE", "DESTINATION", "PORT", "VERDICT", "EGRESS-RULE", "INGRESS-RULE"}
+}
+
+func (r Response) GetTableRow(_ int) []string {
+	return []string{r.Source, r.Destination, r.Port, r.Verdict, r.EgressRule, r.IngressRule}
+}
+
+func (r Response) SortRows() bool {
+	return true
+}
@@ -58,6 +58,12 @@ type ExternalEntityReference struct {
 	Namespace string
 }
 
+// NodeReference represents a Node Reference.
+type NodeReference struct {
+	// The name of this Node.
+	Name string
+}
+
 // GroupMember represents an resource member to be populated in Groups.
 type GroupMember struct {
 	// Pod maintains the reference to the Pod.
@@ -68,6 +74,9 @@ type GroupMember struct {
 	IPs []IPAddress
 	// Ports is the list NamedPort of the GroupMember.
 	Ports []NamedPort
+	// Node maintains the reference to the Node. It's only set in AppliedToGroups
+	// selecting Nodes, whose NetworkPolicies are enforced on the Node's host traffic.
+	Node *NodeReference
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -190,6 +199,42 @@ type NetworkPolicyRule struct {
 	// EnableLogging is used to indicate if agent should generate logs
 	// when rules are matched. Should be default to false.
 	EnableLogging bool
+	// RateLimit limits the rate of the traffic matched by the rule. nil means
+	// the traffic is not limited.
+	RateLimit *RateLimit
+	// L7Protocols restricts the traffic allowed by the rule to the application
+	// layer requests matching any of these protocols. Empty means the traffic
+	// is not restricted.
+	L7Protocols []L7Protocol
+}
+
+// L7Protocol describes an application layer protocol match.
+type L7Protocol struct {
+	// HTTP matches HTTP requests.
+	HTTP *HTTPProtocol
+}
+
+// HTTPProtocol matches HTTP requests. An empty field matches any value.
+type HTTPProtocol struct {
+	// Method is the method of the request.
+	Method string
+	// Host is the host of the request. It matches any subdomain of a domain
+	// if it starts with "*.".
+	Host string
+	// Path is the path of the request. It matches any path starting with a
+	// prefix if it ends with "*".
+	Path string
+}
+
+// RateLimit describes the limits on the rate of the traffic matched by a rule.
+// A zero value means the corresponding rate is not limited.
+type RateLimit struct {
+	// ConnectionsPerSecond is the maximum rate of new connections.
+	ConnectionsPerSecond int32
+	// PacketsPerSecond is the maximum rate of packets of the connections.
+	PacketsPerSecond int32
+	// BytesPerSecond is the maximum rate of bytes of the connections.
+	BytesPerSecond int32
 }
 
 // Protocol defines network protocols supported for things like container ports.
@@ -325,10 +325,38 @@ func (m *GroupMemberPod) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GroupMemberPod proto.InternalMessageInfo
 
+func (m *HTTPProtocol) Reset()      { *m = HTTPProtocol{} }
+func (*HTTPProtocol) ProtoMessage() {}
+func (*HTTPProtocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_345cd0a9074e5729, []int{10}
+}
+func (m *HTTPProtocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HTTPProtocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *HTTPProtocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPProtocol.Merge(m, src)
+}
+func (m *HTTPProtocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *HTTPProtocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPProtocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPProtocol proto.InternalMessageInfo
+
 func (m *IPBlock) Reset()      { *m = IPBlock{} }
 func (*IPBlock) ProtoMessage() {}
 func (*IPBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{10}
+	return fileDescriptor_345cd0a9074e5729, []int{11}
 }
 func (m *IPBlock) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -356,7 +384,7 @@ var xxx_messageInfo_IPBlock proto.InternalMessageInfo
 func (m *IPNet) Reset()      { *m = IPNet{} }
 func (*IPNet) ProtoMessage() {}
 func (*IPNet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{11}
+	return fileDescriptor_345cd0a9074e5729, []int{12}
 }
 func (m *IPNet) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -381,10 +409,38 @@ func (m *IPNet) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_IPNet proto.InternalMessageInfo
 
+func (m *L7Protocol) Reset()      { *m = L7Protocol{} }
+func (*L7Protocol) ProtoMessage() {}
+func (*L7Protocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_345cd0a9074e5729, []int{13}
+}
+func (m *L7Protocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *L7Protocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *L7Protocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_L7Protocol.Merge(m, src)
+}
+func (m *L7Protocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *L7Protocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_L7Protocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_L7Protocol proto.InternalMessageInfo
+
 func (m *NamedPort) Reset()      { *m = NamedPort{} }
 func (*NamedPort) ProtoMessage() {}
 func (*NamedPort) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{12}
+	return fileDescriptor_345cd0a9074e5729, []int{14}
 }
 func (m *NamedPort) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -412,7 +468,7 @@ var xxx_messageInfo_NamedPort proto.InternalMessageInfo
 func (m *NetworkPolicy) Reset()      { *m = NetworkPolicy{} }
 func (*NetworkPolicy) ProtoMessage() {}
 func (*NetworkPolicy) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{13}
+	return fileDescriptor_345cd0a9074e5729, []int{15}
 }
 func (m *NetworkPolicy) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -440,7 +496,7 @@ var xxx_messageInfo_NetworkPolicy proto.InternalMessageInfo
 func (m *NetworkPolicyList) Reset()      { *m = NetworkPolicyList{} }
 func (*NetworkPolicyList) ProtoMessage() {}
 func (*NetworkPolicyList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{14}
+	return fileDescriptor_345cd0a9074e5729, []int{16}
 }
 func (m *NetworkPolicyList) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -468,7 +524,7 @@ var xxx_messageInfo_NetworkPolicyList proto.InternalMessageInfo
 func (m *NetworkPolicyPeer) Reset()      { *m = NetworkPolicyPeer{} }
 func (*NetworkPolicyPeer) ProtoMessage() {}
 func (*NetworkPolicyPeer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{15}
+	return fileDescriptor_345cd0a9074e5729, []int{17}
 }
 func (m *NetworkPolicyPeer) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -496,7 +552,7 @@ var xxx_messageInfo_NetworkPolicyPeer proto.InternalMessageInfo
 func (m *NetworkPolicyReference) Reset()      { *m = NetworkPolicyReference{} }
 func (*NetworkPolicyReference) ProtoMessage() {}
 func (*NetworkPolicyReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{16}
+	return fileDescriptor_345cd0a9074e5729, []int{18}
 }
 func (m *NetworkPolicyReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -524,7 +580,7 @@ var xxx_messageInfo_NetworkPolicyReference proto.InternalMessageInfo
 func (m *NetworkPolicyRule) Reset()      { *m = NetworkPolicyRule{} }
 func (*NetworkPolicyRule) ProtoMessage() {}
 func (*NetworkPolicyRule) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{17}
+	return fileDescriptor_345cd0a9074e5729, []int{19}
 }
 func (m *NetworkPolicyRule) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -552,7 +608,7 @@ var xxx_messageInfo_NetworkPolicyRule proto.InternalMessageInfo
 func (m *NetworkPolicyStats) Reset()      { *m = NetworkPolicyStats{} }
 func (*NetworkPolicyStats) ProtoMessage() {}
 func (*NetworkPolicyStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{18}
+	return fileDescriptor_345cd0a9074e5729, []int{20}
 }
 func (m *NetworkPolicyStats) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -580,7 +636,7 @@ var xxx_messageInfo_NetworkPolicyStats proto.InternalMessageInfo
 func (m *NodeStatsSummary) Reset()      { *m = NodeStatsSummary{} }
 func (*NodeStatsSummary) ProtoMessage() {}
 func (*NodeStatsSummary) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{19}
+	return fileDescriptor_345cd0a9074e5729, []int{21}
 }
 func (m *NodeStatsSummary) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -608,7 +664,7 @@ var xxx_messageInfo_NodeStatsSummary proto.InternalMessageInfo
 func (m *PodReference) Reset()      { *m = PodReference{} }
 func (*PodReference) ProtoMessage() {}
 func (*PodReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{20}
+	return fileDescriptor_345cd0a9074e5729, []int{22}
 }
 func (m *PodReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -633,10 +689,38 @@ func (m *PodReference) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PodReference proto.InternalMessageInfo
 
+func (m *RateLimit) Reset()      { *m = RateLimit{} }
+func (*RateLimit) ProtoMessage() {}
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_345cd0a9074e5729, []int{23}
+}
+func (m *RateLimit) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RateLimit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *RateLimit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimit.Merge(m, src)
+}
+func (m *RateLimit) XXX_Size() int {
+	return m.Size()
+}
+func (m *RateLimit) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimit proto.InternalMessageInfo
+
 func (m *Service) Reset()      { *m = Service{} }
 func (*Service) ProtoMessage() {}
 func (*Service) Descriptor() ([]byte, []int) {
-	return fileDescriptor_345cd0a9074e5729, []int{21}
+	return fileDescriptor_345cd0a9074e5729, []int{24}
 }
 func (m *Service) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -672,8 +756,10 @@ func init() {
 	proto.RegisterType((*ExternalEntityReference)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.ExternalEntityReference")
 	proto.RegisterType((*GroupMember)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.GroupMember")
 	proto.RegisterType((*GroupMemberPod)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.GroupMemberPod")
+	proto.RegisterType((*HTTPProtocol)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.HTTPProtocol")
 	proto.RegisterType((*IPBlock)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.IPBlock")
 	proto.RegisterType((*IPNet)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.IPNet")
+	proto.RegisterType((*L7Protocol)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.L7Protocol")
 	proto.RegisterType((*NamedPort)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.NamedPort")
 	proto.RegisterType((*NetworkPolicy)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.NetworkPolicy")
 	proto.RegisterType((*NetworkPolicyList)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.NetworkPolicyList")
@@ -683,6 +769,7 @@ func init() {
 	proto.RegisterType((*NetworkPolicyStats)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.NetworkPolicyStats")
 	proto.RegisterType((*NodeStatsSummary)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.NodeStatsSummary")
 	proto.RegisterType((*PodReference)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.PodReference")
+	proto.RegisterType((*RateLimit)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.RateLimit")
 	proto.RegisterType((*Service)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta1.Service")
 }
 
@@ -691,111 +778,124 @@ func init() {
 }
 
 var fileDescriptor_345cd0a9074e5729 = []byte{
-	// 1652 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x1b, 0x45,
-	0x1b, 0xcf, 0xfa, 0x23, 0x89, 0x27, 0x76, 0x3e, 0x26, 0x6f, 0xdf, 0xfa, 0xed, 0x5b, 0xec, 0x74,
-	0xe1, 0x90, 0x03, 0x5d, 0x37, 0xa5, 0x40, 0x25, 0xca, 0x21, 0x6e, 0xd2, 0xca, 0x90, 0xa6, 0xd6,
-	0x24, 0xbd, 0x20, 0x24, 0xd8, 0xec, 0x8e, 0x9d, 0x6d, 0xd6, 0x3b, 0xdb, 0xd9, 0x71, 0xda, 0x20,
-	0x81, 0xa8, 0x38, 0xd1, 0x0b, 0x5f, 0x17, 0x2e, 0x1c, 0x91, 0x10, 0xe2, 0x2f, 0xe0, 0xc6, 0xad,
-	0xc7, 0x1e, 0x7b, 0xc1, 0x22, 0xae, 0xa8, 0xb8, 0x71, 0x8f, 0x84, 0x84, 0x66, 0x76, 0xf6, 0xcb,
-	0x8e, 0xdb, 0x20, 0x3b, 0x11, 0x87, 0x9e, 0x92, 0x99, 0x79, 0xe6, 0xf9, 0xfd, 0xe6, 0x79, 0x9e,
-	0xf9, 0xed, 0xb3, 0x6b, 0xb0, 0xd6, 0xb4, 0xd8, 0x76, 0x7b, 0x4b, 0x33, 0x48, 0xab, 0xb2, 0xdb,
-	0xba, 0xab, 0x53, 0x7c, 0x9e, 0xe9, 0xce, 0x47, 0xed, 0x8a, 0xee, 0x30, 0x8a, 0xf5, 0x8a, 0xbb,
-	0xd3, 0xac, 0xe8, 0xae, 0xe5, 0x55, 0x0c, 0xe2, 0x30, 0x4a, 0x6c, 0xd7, 0xd6, 0x1d, 0x5c, 0xd9,
-	0x5d, 0xda, 0xc2, 0x4c, 0x5f, 0xaa, 0x34, 0xb1, 0x83, 0xa9, 0xce, 0xb0, 0xa9, 0xb9, 0x94, 0x30,
-	0x02, 0xaf, 0x44, 0xde, 0x34, 0xdf, 0xdb, 0x07, 0xc2, 0x9b, 0xe6, 0x7b, 0xd3, 0xdc, 0x9d, 0xa6,
-	0xc6, 0xbd, 0x69, 0x71, 0x6f, 0x9a, 0xf4, 0x76, 0xe6, 0x7c, 0x8c, 0x4b, 0x93, 0x34, 0x49, 0x45,
-	0x38, 0xdd, 0x6a, 0x37, 0xc4, 0x48, 0x0c, 0xc4, 0x7f, 0x3e, 0xd8, 0x99, 0x6b, 0x47, 0xa5, 0xee,
-	0x31, 0x9d, 0x79, 0x95, 0xdd, 0x25, 0xdd, 0x76, 0xb7, 0xfb, 0x49, 0x9f, 0xb9, 0xb4, 0x73, 0xd9,
-	0xd3, 0x2c, 0xc2, 0x6d, 0x5b, 0xba, 0xb1, 0x6d, 0x39, 0x98, 0xee, 0x45, 0x9b, 0x5b, 0x98, 0xe9,
-	0x95, 0xdd, 0xfe, 0x5d, 0x95, 0x41, 0xbb, 0x68, 0xdb, 0x61, 0x56, 0x0b, 0xf7, 0x6d, 0x78, 0xe3,
-	0x79, 0x1b, 0x3c, 0x63, 0x1b, 0xb7, 0xf4, 0xbe, 0x7d, 0xaf, 0x0d, 0xda, 0xd7, 0x66, 0x96, 0x5d,
-	0xb1, 0x1c, 0xe6, 0x31, 0xda, 0xbb, 0x49, 0x7d, 0x9a, 0x02, 0xf9, 0x65, 0xd3, 0xa4, 0xd8, 0xf3,
-	0xae, 0x53, 0xd2, 0x76, 0xe1, 0x87, 0x60, 0x92, 0x9f, 0xc4, 0xd4, 0x99, 0x5e, 0x54, 0x16, 0x94,
-	0xc5, 0xa9, 0x8b, 0x17, 0x34, 0xdf, 0xb1, 0x16, 0x77, 0x1c, 0x65, 0x88, 0x5b, 0x6b, 0xbb, 0x4b,
-	0xda, 0xcd, 0xad, 0xdb, 0xd8, 0x60, 0x37, 0x30, 0xd3, 0xab, 0xf0, 0x61, 0xa7, 0x3c, 0xd6, 0xed,
-	0x94, 0x41, 0x34, 0x87, 0x42, 0xaf, 0xd0, 0x01, 0x19, 0x97, 0x98, 0x5e, 0x31, 0xb5, 0x90, 0x5e,
-	0x9c, 0xba, 0xb8, 0xa6, 0x0d, 0x53, 0x0a, 0x9a, 0x20, 0x7d, 0x03, 0xb7, 0xb6, 0x30, 0xad, 0x13,
-	0xb3, 0x9a, 0x97, 0xc8, 0x99, 0x3a, 0x31, 0x3d, 0x24, 0x70, 0xe0, 0x67, 0x0a, 0xc8, 0x37, 0x23,
-	0x33, 0xaf, 0x98, 0x16, 0xc0, 0xb5, 0x91, 0x01, 0x57, 0xff, 0x23, 0x51, 0xf3, 0xb1, 0x49, 0x0f,
-	0x25, 0x40, 0xd5, 0x7d, 0x05, 0xcc, 0xc6, 0x03, 0xbd, 0x66, 0x79, 0x0c, 0xbe, 0xdf, 0x17, 0x6c,
-	0xed, 0x68, 0xc1, 0xe6, 0xbb, 0x45, 0xa8, 0x67, 0x25, 0xf4, 0x64, 0x30, 0x13, 0x0b, 0x34, 0x01,
-	0x59, 0x8b, 0xe1, 0x56, 0x10, 0xe9, 0x77, 0x86, 0x3b, 0x70, 0x9c, 0x7c, 0xb5, 0x20, 0x61, 0xb3,
-	0x35, 0x0e, 0x80, 0x7c, 0x1c, 0xf5, 0xc7, 0x2c, 0x98, 0x8b, 0x9b, 0xd5, 0x75, 0x66, 0x6c, 0x9f,
-	0x40, 0x45, 0x7d, 0x0c, 0x72, 0xba, 0x69, 0x62, 0xb3, 0x7e, 0x5c, 0x65, 0x35, 0x27, 0xe1, 0x73,
-	0xcb, 0x01, 0x0c, 0x8a, 0x10, 0x79, 0x81, 0x4d, 0x51, 0xdc, 0x22, 0xbb, 0x92, 0x41, 0xfa, 0x18,
-	0x18, 0xcc, 0x4b, 0x06, 0x53, 0x28, 0x02, 0x42, 0x71, 0x54, 0xf8, 0xb5, 0x02, 0xe6, 0x04, 0xa7,
-	0x78, 0x11, 0x16, 0x33, 0xa3, 0xae, 0xf5, 0xff, 0x49, 0x22, 0x73, 0xcb, 0xbd, 0x58, 0xa8, 0x1f,
-	0x1e, 0x7e, 0xab, 0x80, 0x79, 0x49, 0x32, 0x41, 0x2b, 0x3b, 0x6a, 0x5a, 0xff, 0x97, 0xb4, 0xe6,
-	0x51, 0x3f, 0x1a, 0x3a, 0x8c, 0x82, 0xfa, 0x47, 0x0a, 0x4c, 0x2f, 0xbb, 0xae, 0x6d, 0x61, 0x73,
-	0x93, 0xbc, 0xd0, 0xbe, 0xe3, 0xd4, 0xbe, 0xdf, 0x15, 0x00, 0x93, 0xa1, 0x3e, 0x01, 0xf5, 0xbb,
-	0x93, 0x54, 0xbf, 0x21, 0x63, 0x9d, 0xa4, 0x3f, 0x40, 0xff, 0x7e, 0xca, 0x82, 0xf9, 0xa4, 0xe1,
-	0x0b, 0x05, 0x7c, 0xa1, 0x80, 0xff, 0x5a, 0x05, 0xfc, 0x4e, 0x01, 0x93, 0xab, 0x8e, 0xe9, 0x12,
-	0xcb, 0x61, 0xf0, 0x65, 0x90, 0xb2, 0x5c, 0x51, 0x9d, 0xf9, 0xea, 0x7c, 0xb7, 0x53, 0x4e, 0xd5,
-	0xea, 0x07, 0x9d, 0x72, 0xae, 0x56, 0x97, 0x0f, 0x74, 0x94, 0xb2, 0x5c, 0x68, 0x83, 0xac, 0x4b,
-	0x28, 0x0b, 0x4a, 0xec, 0xfa, 0x70, 0xec, 0xd7, 0xf5, 0x16, 0xcf, 0x1c, 0x65, 0xd1, 0x75, 0xe2,
-	0x23, 0x0f, 0xf9, 0x20, 0xaa, 0x0d, 0x4e, 0xaf, 0xde, 0x63, 0x98, 0x3a, 0xba, 0xbd, 0xea, 0x30,
-	0x8b, 0xed, 0x21, 0xdc, 0xc0, 0x14, 0x3b, 0x06, 0x86, 0x0b, 0x20, 0xe3, 0xe8, 0x2d, 0x2c, 0xf8,
-	0xe6, 0x22, 0xe5, 0xe3, 0x1e, 0x91, 0x58, 0x81, 0x15, 0x90, 0xe3, 0x7f, 0x3d, 0x57, 0x37, 0x70,
-	0x31, 0x25, 0xcc, 0xc2, 0x1a, 0x5e, 0x0f, 0x16, 0x50, 0x64, 0xa3, 0xde, 0x4f, 0x83, 0xa9, 0x58,
-	0x78, 0x20, 0x06, 0x69, 0x97, 0x98, 0xf2, 0xbe, 0x0e, 0xd9, 0x3b, 0xd5, 0x89, 0x19, 0x72, 0xaf,
-	0x4e, 0x74, 0x3b, 0xe5, 0x34, 0x9f, 0xe1, 0xfe, 0xe1, 0x57, 0x0a, 0x98, 0xc6, 0x89, 0x53, 0x0a,
-	0xb6, 0x53, 0x17, 0x6f, 0x0d, 0x07, 0x39, 0x20, 0x72, 0x55, 0xd8, 0xed, 0x94, 0xa7, 0x7b, 0x16,
-	0x7b, 0x08, 0xc0, 0xbb, 0x20, 0x87, 0x65, 0x5d, 0x04, 0x77, 0xf9, 0xda, 0x90, 0x6c, 0xa4, 0xbb,
-	0x28, 0x07, 0xc1, 0x8c, 0x87, 0x22, 0x2c, 0xf5, 0x41, 0x0a, 0x4c, 0x27, 0xaf, 0xfd, 0x49, 0xa5,
-	0xc1, 0x2f, 0xff, 0xd4, 0x11, 0xcb, 0x3f, 0x7d, 0x12, 0xe5, 0xff, 0xab, 0x02, 0x26, 0x6a, 0xf5,
-	0xaa, 0x4d, 0x8c, 0x1d, 0x88, 0x41, 0xc6, 0xb0, 0x4c, 0x2a, 0xc3, 0x70, 0x75, 0x38, 0xe0, 0x5a,
-	0x7d, 0x1d, 0xb3, 0xe8, 0xd2, 0x5c, 0xad, 0xad, 0x20, 0x24, 0xdc, 0xc3, 0x1d, 0x30, 0x8e, 0xef,
-	0x19, 0xd8, 0x65, 0xf2, 0x82, 0x8f, 0x04, 0x68, 0x5a, 0x02, 0x8d, 0xaf, 0x0a, 0xd7, 0x48, 0x42,
-	0xa8, 0x0d, 0x90, 0x15, 0x06, 0x47, 0x93, 0x9e, 0xcb, 0x20, 0xef, 0x52, 0xdc, 0xb0, 0xee, 0xad,
-	0x61, 0xa7, 0xc9, 0xb6, 0x45, 0xaa, 0xb2, 0x51, 0xf7, 0x51, 0x8f, 0xad, 0xa1, 0x84, 0xa5, 0xfa,
-	0xb9, 0x02, 0x72, 0x61, 0xac, 0xb9, 0x72, 0xf0, 0xf0, 0x0a, 0xb8, 0x6c, 0xbc, 0x67, 0xa2, 0x0c,
-	0x89, 0x95, 0x50, 0x5b, 0x52, 0x03, 0xb5, 0xe5, 0x32, 0x98, 0x14, 0x6f, 0xcf, 0x06, 0xb1, 0x8b,
-	0x69, 0x61, 0x75, 0x36, 0x68, 0x44, 0xea, 0x72, 0xfe, 0x20, 0xf6, 0x3f, 0x0a, 0xad, 0xd5, 0x07,
-	0x19, 0x50, 0x58, 0xc7, 0xec, 0x2e, 0xa1, 0x3b, 0x75, 0x62, 0x5b, 0xc6, 0xde, 0x09, 0xf4, 0x06,
-	0x0c, 0x64, 0x69, 0xdb, 0xc6, 0x81, 0x68, 0xdf, 0x1c, 0xb2, 0x6a, 0xe3, 0xec, 0x51, 0xdb, 0xc6,
-	0x51, 0xf5, 0xf2, 0x91, 0x87, 0x7c, 0x30, 0xf8, 0x36, 0x98, 0xd1, 0x13, 0xad, 0x90, 0x7f, 0x6b,
-	0x72, 0x22, 0xc3, 0x33, 0xc9, 0x2e, 0xc9, 0x43, 0xbd, 0xb6, 0x70, 0x91, 0x87, 0xd8, 0x22, 0x94,
-	0xeb, 0x61, 0x66, 0x41, 0x59, 0x54, 0xaa, 0x79, 0x3f, 0xbc, 0xfe, 0x1c, 0x0a, 0x57, 0xe1, 0x25,
-	0x90, 0x67, 0x16, 0xa6, 0xc1, 0x4a, 0x31, 0x2b, 0x12, 0x3b, 0xcb, 0x8b, 0x62, 0x33, 0x36, 0x8f,
-	0x12, 0x56, 0xf0, 0xbe, 0x02, 0x72, 0x1e, 0x69, 0x53, 0x03, 0x23, 0xdc, 0x28, 0x8e, 0x8b, 0xc0,
-	0x6f, 0x8e, 0x32, 0x32, 0xa1, 0xce, 0x14, 0xb8, 0xda, 0x6d, 0x04, 0x50, 0x28, 0x42, 0x55, 0x9f,
-	0x28, 0x60, 0x2e, 0xb1, 0xe9, 0x04, 0xba, 0x62, 0x37, 0xd9, 0x15, 0xbf, 0x3b, 0xc2, 0x23, 0x0f,
-	0x68, 0x8a, 0x7f, 0xe9, 0x3d, 0x65, 0x1d, 0x63, 0x0a, 0xdf, 0x04, 0x05, 0x3d, 0xf6, 0xa5, 0xc0,
-	0x2b, 0x2a, 0xa2, 0x38, 0xe6, 0xba, 0x9d, 0x72, 0x21, 0xfe, 0x09, 0xc1, 0x43, 0x49, 0x3b, 0xe8,
-	0x81, 0x49, 0xcb, 0x15, 0xa2, 0x18, 0x9c, 0x61, 0x75, 0x58, 0x91, 0x12, 0xde, 0xa2, 0xa8, 0xc9,
-	0x09, 0x0f, 0x85, 0x40, 0xea, 0x53, 0x05, 0xfc, 0xf7, 0xf0, 0xf4, 0xc2, 0xd7, 0x41, 0x86, 0xed,
-	0xb9, 0x41, 0x27, 0x72, 0x2e, 0x50, 0x8b, 0xcd, 0x3d, 0x17, 0x1f, 0x74, 0xca, 0xc9, 0x93, 0xf3,
-	0x49, 0x24, 0xcc, 0xff, 0x71, 0x7b, 0x12, 0xaa, 0x52, 0x7a, 0xa0, 0x2a, 0x55, 0x41, 0xba, 0x6d,
-	0x99, 0xe2, 0xb6, 0xe4, 0xaa, 0x17, 0xa4, 0x41, 0xfa, 0x56, 0x6d, 0xe5, 0xa0, 0x53, 0x3e, 0x37,
-	0xe8, 0xdb, 0x20, 0x27, 0xe3, 0x69, 0xb7, 0x6a, 0x2b, 0x88, 0x6f, 0x56, 0xff, 0xca, 0xf4, 0x24,
-	0x8b, 0xdf, 0x69, 0x78, 0x05, 0xe4, 0x4c, 0x8b, 0x62, 0x83, 0x59, 0xc4, 0x91, 0x07, 0x2d, 0x05,
-	0x64, 0x57, 0x82, 0x85, 0x83, 0xf8, 0x00, 0x45, 0x1b, 0xe0, 0x1d, 0x90, 0x69, 0x50, 0xd2, 0x92,
-	0x6d, 0xcd, 0x28, 0xe5, 0x87, 0x57, 0x52, 0x14, 0x8a, 0x6b, 0x94, 0xb4, 0x90, 0x80, 0x82, 0x3b,
-	0x20, 0xc5, 0x88, 0x08, 0xd5, 0x31, 0x00, 0x02, 0x09, 0x98, 0xda, 0x24, 0x28, 0xc5, 0x08, 0xaf,
-	0x48, 0x0f, 0xd3, 0x5d, 0xcb, 0xc0, 0xc1, 0xcb, 0xc6, 0x90, 0x15, 0xb9, 0xe1, 0x7b, 0x8b, 0x2a,
-	0x52, 0x4e, 0x78, 0x28, 0x04, 0x82, 0xaf, 0xc6, 0xf4, 0x51, 0x2a, 0x5e, 0xf4, 0x08, 0xea, 0xd3,
-	0xc8, 0xdb, 0x60, 0x5c, 0xf7, 0xb3, 0x37, 0x2e, 0xb2, 0x87, 0xf8, 0xe3, 0x78, 0x39, 0x48, 0xdb,
-	0xca, 0x91, 0xbf, 0x8f, 0x63, 0xa3, 0xcd, 0xfd, 0x85, 0x9f, 0xc8, 0x35, 0x5e, 0x1e, 0xbe, 0x1f,
-	0x24, 0x11, 0xe0, 0x5b, 0xa0, 0x80, 0x1d, 0x7d, 0xcb, 0xc6, 0x6b, 0xa4, 0xd9, 0xb4, 0x9c, 0x66,
-	0x71, 0x62, 0x41, 0x59, 0x9c, 0xac, 0x9e, 0x92, 0xf4, 0x0a, 0xab, 0xf1, 0x45, 0x94, 0xb4, 0x55,
-	0x7f, 0x48, 0x01, 0x98, 0x88, 0xf8, 0x06, 0xd3, 0x99, 0xc7, 0x9b, 0xe4, 0x82, 0x13, 0x9f, 0x96,
-	0xca, 0x78, 0x3c, 0x8a, 0x1d, 0x52, 0x4d, 0xae, 0x27, 0x19, 0xc0, 0x4f, 0x40, 0x9e, 0x51, 0xbd,
-	0xd1, 0xb0, 0x0c, 0xc1, 0x51, 0x96, 0xf7, 0xca, 0x91, 0x19, 0x89, 0x1f, 0x1b, 0xb4, 0x30, 0x92,
-	0x9b, 0x31, 0x5f, 0x51, 0x5b, 0x13, 0x9f, 0x45, 0x09, 0x3c, 0xf5, 0xcf, 0x0c, 0x98, 0x5d, 0x27,
-	0x26, 0x16, 0xa3, 0x8d, 0x76, 0xab, 0xa5, 0xd3, 0x93, 0xe8, 0x26, 0xbe, 0x51, 0xc0, 0x4c, 0x3c,
-	0x10, 0x56, 0xd8, 0x58, 0xd4, 0x47, 0x98, 0x0c, 0x3f, 0x0c, 0xa7, 0x25, 0x93, 0x99, 0xf5, 0x24,
-	0x20, 0xea, 0x65, 0x00, 0x7f, 0x56, 0xc0, 0x59, 0x1f, 0xe5, 0xaa, 0xdd, 0xf6, 0x18, 0xa6, 0x3d,
-	0x3b, 0x64, 0xc7, 0x3e, 0x7a, 0x8a, 0xaf, 0x48, 0x8a, 0x67, 0x97, 0x9f, 0x81, 0x8e, 0x9e, 0xc9,
-	0x0d, 0x7e, 0xaf, 0x80, 0x53, 0xbe, 0x41, 0x2f, 0xeb, 0xcc, 0x31, 0xb1, 0x7e, 0x49, 0xb2, 0x3e,
-	0xb5, 0x7c, 0x18, 0x2c, 0x3a, 0x9c, 0x8d, 0xaa, 0x83, 0x7c, 0xfc, 0x0d, 0xea, 0x38, 0x5e, 0xc2,
-	0xbf, 0x50, 0xc0, 0x84, 0x54, 0x3b, 0x78, 0x29, 0xd6, 0x65, 0xfb, 0x10, 0xc5, 0xe7, 0x77, 0xd8,
-	0x70, 0x5d, 0xf6, 0xf7, 0xa9, 0xe7, 0x54, 0x7f, 0x9b, 0x59, 0xb6, 0xe6, 0xff, 0x28, 0xa6, 0xd5,
-	0x1c, 0x76, 0x93, 0x6e, 0x30, 0x6a, 0x39, 0xcd, 0xea, 0x64, 0xf2, 0x6d, 0xa0, 0x7a, 0xfe, 0xe1,
-	0x7e, 0x69, 0xec, 0xd1, 0x7e, 0x69, 0xec, 0xf1, 0x7e, 0x69, 0xec, 0xd3, 0x6e, 0x49, 0x79, 0xd8,
-	0x2d, 0x29, 0x8f, 0xba, 0x25, 0xe5, 0x71, 0xb7, 0xa4, 0xfc, 0xd6, 0x2d, 0x29, 0x5f, 0x3e, 0x29,
-	0x8d, 0xbd, 0x37, 0x21, 0x83, 0xfd, 0x77, 0x00, 0x00, 0x00, 0xff, 0xff, 0xe8, 0x1c, 0xc4, 0xa0,
-	0x27, 0x1d, 0x00, 0x00,
+	// 1863 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0xdb, 0xc8,
+	0x15, 0x37, 0xf5, 0x61, 0x4b, 0xcf, 0xb2, 0x63, 0x8f, 0x93, 0x5d, 0x36, 0x4d, 0x25, 0x2f, 0x5b,
+	0x14, 0x3e, 0x34, 0xd4, 0x26, 0x4d, 0xbb, 0x01, 0xba, 0x2d, 0x60, 0xc5, 0xce, 0x46, 0xad, 0xe3,
+	0x10, 0x63, 0xe5, 0x52, 0x14, 0x68, 0x69, 0x72, 0x2c, 0x71, 0x2d, 0x72, 0xb8, 0xc3, 0x91, 0x13,
+	0x2f, 0xd0, 0x8f, 0x45, 0x4f, 0xdd, 0x4b, 0xbf, 0x2e, 0xbd, 0xf4, 0x58, 0xa0, 0x28, 0xfa, 0x17,
+	0xf4, 0xd6, 0x5b, 0x8e, 0x7b, 0xdc, 0x4b, 0x85, 0x5a, 0x41, 0x17, 0xbd, 0xf5, 0xd2, 0x93, 0x4f,
+	0x05, 0x87, 0xc3, 0x2f, 0xc9, 0xde, 0xb8, 0x90, 0x6c, 0xec, 0x21, 0x27, 0x9b, 0x33, 0xef, 0xbd,
+	0xdf, 0x6f, 0xde, 0x7b, 0xf3, 0xf8, 0x9e, 0x08, 0x3b, 0x5d, 0x87, 0xf7, 0x06, 0xfb, 0xba, 0x45,
+	0xdd, 0xe6, 0x91, 0xfb, 0xcc, 0x64, 0xe4, 0x36, 0x37, 0xbd, 0x0f, 0x07, 0x4d, 0xd3, 0xe3, 0x8c,
+	0x98, 0x4d, 0xff, 0xb0, 0xdb, 0x34, 0x7d, 0x27, 0x68, 0x5a, 0xd4, 0xe3, 0x8c, 0xf6, 0xfd, 0xbe,
+	0xe9, 0x91, 0xe6, 0xd1, 0x9d, 0x7d, 0xc2, 0xcd, 0x3b, 0xcd, 0x2e, 0xf1, 0x08, 0x33, 0x39, 0xb1,
+	0x75, 0x9f, 0x51, 0x4e, 0xd1, 0xbb, 0xa9, 0x35, 0x3d, 0xb2, 0xf6, 0x63, 0x61, 0x4d, 0x8f, 0xac,
+	0xe9, 0xfe, 0x61, 0x57, 0x0f, 0xad, 0xe9, 0x59, 0x6b, 0xba, 0xb4, 0x76, 0xf3, 0x76, 0x86, 0x4b,
+	0x97, 0x76, 0x69, 0x53, 0x18, 0xdd, 0x1f, 0x1c, 0x88, 0x27, 0xf1, 0x20, 0xfe, 0x8b, 0xc0, 0x6e,
+	0x3e, 0xbc, 0x28, 0xf5, 0x80, 0x9b, 0x3c, 0x68, 0x1e, 0xdd, 0x31, 0xfb, 0x7e, 0x6f, 0x92, 0xf4,
+	0xcd, 0x7b, 0x87, 0xf7, 0x03, 0xdd, 0xa1, 0xa1, 0xac, 0x6b, 0x5a, 0x3d, 0xc7, 0x23, 0xec, 0x38,
+	0x55, 0x76, 0x09, 0x37, 0x9b, 0x47, 0x93, 0x5a, 0xcd, 0xf3, 0xb4, 0xd8, 0xc0, 0xe3, 0x8e, 0x4b,
+	0x26, 0x14, 0xbe, 0xfd, 0x2a, 0x85, 0xc0, 0xea, 0x11, 0xd7, 0x9c, 0xd0, 0xfb, 0xe6, 0x79, 0x7a,
+	0x03, 0xee, 0xf4, 0x9b, 0x8e, 0xc7, 0x03, 0xce, 0xc6, 0x95, 0xb4, 0xcf, 0x0a, 0x50, 0xdb, 0xb4,
+	0x6d, 0x46, 0x82, 0xe0, 0x3d, 0x46, 0x07, 0x3e, 0xfa, 0x09, 0x54, 0xc2, 0x93, 0xd8, 0x26, 0x37,
+	0x55, 0x65, 0x5d, 0xd9, 0x58, 0xbc, 0xfb, 0xb6, 0x1e, 0x19, 0xd6, 0xb3, 0x86, 0xd3, 0x08, 0x85,
+	0xd2, 0xfa, 0xd1, 0x1d, 0xfd, 0xc9, 0xfe, 0xfb, 0xc4, 0xe2, 0x8f, 0x09, 0x37, 0x5b, 0xe8, 0xc5,
+	0xb0, 0x31, 0x37, 0x1a, 0x36, 0x20, 0x5d, 0xc3, 0x89, 0x55, 0xe4, 0x41, 0xc9, 0xa7, 0x76, 0xa0,
+	0x16, 0xd6, 0x8b, 0x1b, 0x8b, 0x77, 0x77, 0xf4, 0x69, 0x52, 0x41, 0x17, 0xa4, 0x1f, 0x13, 0x77,
+	0x9f, 0x30, 0x83, 0xda, 0xad, 0x9a, 0x44, 0x2e, 0x19, 0xd4, 0x0e, 0xb0, 0xc0, 0x41, 0xbf, 0x54,
+	0xa0, 0xd6, 0x4d, 0xc5, 0x02, 0xb5, 0x28, 0x80, 0xdb, 0x33, 0x03, 0x6e, 0x5d, 0x97, 0xa8, 0xb5,
+	0xcc, 0x62, 0x80, 0x73, 0xa0, 0xda, 0x89, 0x02, 0x2b, 0x59, 0x47, 0xef, 0x38, 0x01, 0x47, 0x3f,
+	0x9a, 0x70, 0xb6, 0x7e, 0x31, 0x67, 0x87, 0xda, 0xc2, 0xd5, 0x2b, 0x12, 0xba, 0x12, 0xaf, 0x64,
+	0x1c, 0x4d, 0xa1, 0xec, 0x70, 0xe2, 0xc6, 0x9e, 0xfe, 0xfe, 0x74, 0x07, 0xce, 0x92, 0x6f, 0x2d,
+	0x49, 0xd8, 0x72, 0x3b, 0x04, 0xc0, 0x11, 0x8e, 0xf6, 0x97, 0x32, 0xac, 0x66, 0xc5, 0x0c, 0x93,
+	0x5b, 0xbd, 0x2b, 0xc8, 0xa8, 0x9f, 0x42, 0xd5, 0xb4, 0x6d, 0x62, 0x1b, 0x97, 0x95, 0x56, 0xab,
+	0x12, 0xbe, 0xba, 0x19, 0xc3, 0xe0, 0x14, 0x31, 0x4c, 0xb0, 0x45, 0x46, 0x5c, 0x7a, 0x24, 0x19,
+	0x14, 0x2f, 0x81, 0xc1, 0x9a, 0x64, 0xb0, 0x88, 0x53, 0x20, 0x9c, 0x45, 0x45, 0xbf, 0x53, 0x60,
+	0x55, 0x70, 0xca, 0x26, 0xa1, 0x5a, 0x9a, 0x75, 0xae, 0x7f, 0x49, 0x12, 0x59, 0xdd, 0x1c, 0xc7,
+	0xc2, 0x93, 0xf0, 0xe8, 0x0f, 0x0a, 0xac, 0x49, 0x92, 0x39, 0x5a, 0xe5, 0x59, 0xd3, 0xfa, 0xb2,
+	0xa4, 0xb5, 0x86, 0x27, 0xd1, 0xf0, 0x59, 0x14, 0xb4, 0x7f, 0x17, 0x60, 0x79, 0xd3, 0xf7, 0xfb,
+	0x0e, 0xb1, 0x3b, 0xf4, 0x75, 0xed, 0xbb, 0xcc, 0xda, 0xf7, 0x2f, 0x05, 0x50, 0xde, 0xd5, 0x57,
+	0x50, 0xfd, 0x3e, 0xc8, 0x57, 0xbf, 0x29, 0x7d, 0x9d, 0xa7, 0x7f, 0x4e, 0xfd, 0xfb, 0x6b, 0x19,
+	0xd6, 0xf2, 0x82, 0xaf, 0x2b, 0xe0, 0xeb, 0x0a, 0xf8, 0x85, 0xad, 0x80, 0x7f, 0x54, 0xa0, 0xb2,
+	0xed, 0xd9, 0x3e, 0x75, 0x3c, 0x8e, 0xbe, 0x0a, 0x05, 0xc7, 0x17, 0xd9, 0x59, 0x6b, 0xad, 0x8d,
+	0x86, 0x8d, 0x42, 0xdb, 0x38, 0x1d, 0x36, 0xaa, 0x6d, 0x43, 0xbe, 0xd0, 0x71, 0xc1, 0xf1, 0x51,
+	0x1f, 0xca, 0x3e, 0x65, 0x3c, 0x4e, 0xb1, 0xf7, 0xa6, 0x63, 0xbf, 0x6b, 0xba, 0x61, 0xe4, 0x18,
+	0x4f, 0xaf, 0x53, 0xf8, 0x14, 0xe0, 0x08, 0x44, 0xeb, 0xc3, 0x9b, 0xdb, 0xcf, 0x39, 0x61, 0x9e,
+	0xd9, 0xdf, 0xf6, 0xb8, 0xc3, 0x8f, 0x31, 0x39, 0x20, 0x8c, 0x78, 0x16, 0x41, 0xeb, 0x50, 0xf2,
+	0x4c, 0x97, 0x08, 0xbe, 0xd5, 0xb4, 0xf2, 0x85, 0x16, 0xb1, 0xd8, 0x41, 0x4d, 0xa8, 0x86, 0x7f,
+	0x03, 0xdf, 0xb4, 0x88, 0x5a, 0x10, 0x62, 0x49, 0x0e, 0xef, 0xc6, 0x1b, 0x38, 0x95, 0xd1, 0x3e,
+	0x2a, 0xc2, 0x62, 0xc6, 0x3d, 0x88, 0x40, 0xd1, 0xa7, 0xb6, 0xbc, 0xaf, 0x53, 0xf6, 0x4e, 0x06,
+	0xb5, 0x13, 0xee, 0xad, 0x85, 0xd1, 0xb0, 0x51, 0x0c, 0x57, 0x42, 0xfb, 0xe8, 0xb7, 0x0a, 0x2c,
+	0x93, 0xdc, 0x29, 0x05, 0xdb, 0xc5, 0xbb, 0x4f, 0xa7, 0x83, 0x3c, 0xc7, 0x73, 0x2d, 0x34, 0x1a,
+	0x36, 0x96, 0xc7, 0x36, 0xc7, 0x08, 0xa0, 0x67, 0x50, 0x25, 0x32, 0x2f, 0xe2, 0xbb, 0xfc, 0x70,
+	0x4a, 0x36, 0xd2, 0x5c, 0x1a, 0x83, 0x78, 0x25, 0xc0, 0x29, 0x96, 0xf6, 0x71, 0x01, 0x96, 0xf3,
+	0xd7, 0xfe, 0xaa, 0xc2, 0x10, 0xa5, 0x7f, 0xe1, 0x82, 0xe9, 0x5f, 0xbc, 0x8a, 0xf4, 0xff, 0x10,
+	0x6a, 0x8f, 0x3a, 0x1d, 0xc3, 0x60, 0x94, 0x53, 0x8b, 0xf6, 0xd1, 0xd7, 0x61, 0xde, 0x25, 0xbc,
+	0x27, 0x9d, 0x51, 0x6d, 0x2d, 0x4b, 0xad, 0xf9, 0xc7, 0x62, 0x15, 0xcb, 0xdd, 0xf0, 0x6e, 0xf4,
+	0x68, 0xc0, 0xd5, 0x42, 0xfe, 0x6e, 0x3c, 0xa2, 0x01, 0xc7, 0x62, 0x27, 0x94, 0xf0, 0x4d, 0xde,
+	0x53, 0x8b, 0x79, 0x09, 0xc3, 0xe4, 0x3d, 0x2c, 0x76, 0xb4, 0x7f, 0x28, 0xb0, 0xd0, 0x36, 0x5a,
+	0x7d, 0x6a, 0x1d, 0x22, 0x02, 0x25, 0xcb, 0xb1, 0x99, 0x0c, 0xc1, 0x83, 0xe9, 0x0e, 0xdd, 0x36,
+	0x76, 0x09, 0x4f, 0x21, 0x1f, 0xb4, 0xb7, 0x30, 0x16, 0xe6, 0xd1, 0x21, 0xcc, 0x93, 0xe7, 0x16,
+	0xf1, 0xb9, 0x2c, 0x2e, 0x33, 0x01, 0x4a, 0x7c, 0xb4, 0x2d, 0x4c, 0x63, 0x09, 0xa1, 0x1d, 0x40,
+	0x59, 0x08, 0x5c, 0xac, 0xec, 0xdd, 0x87, 0x9a, 0xcf, 0xc8, 0x81, 0xf3, 0x7c, 0x87, 0x78, 0x5d,
+	0xde, 0x13, 0x9e, 0x2d, 0xa7, 0x9d, 0x8f, 0x91, 0xd9, 0xc3, 0x39, 0x49, 0xed, 0x08, 0x60, 0xe7,
+	0x9d, 0x24, 0x82, 0x3d, 0x28, 0xf5, 0x38, 0xf7, 0x67, 0x93, 0xcc, 0xd9, 0xdc, 0x68, 0x55, 0x44,
+	0x84, 0x3b, 0x1d, 0x03, 0x0b, 0x04, 0xed, 0x57, 0x0a, 0x54, 0x93, 0xfc, 0x12, 0xf1, 0xa6, 0x8c,
+	0x0b, 0xdc, 0x72, 0xb6, 0x4f, 0x64, 0x1c, 0x97, 0x7c, 0x29, 0x21, 0xea, 0x69, 0xe1, 0xdc, 0x7a,
+	0x7a, 0x1f, 0x2a, 0xbe, 0x44, 0x93, 0x79, 0x73, 0x2b, 0x6e, 0xbe, 0x62, 0x16, 0xa7, 0x99, 0xff,
+	0x71, 0x22, 0xad, 0x7d, 0x5c, 0x82, 0xa5, 0x5d, 0xc2, 0x9f, 0x51, 0x76, 0x68, 0xd0, 0xbe, 0x63,
+	0x1d, 0x5f, 0x41, 0x3f, 0xc4, 0xa1, 0xcc, 0x06, 0x7d, 0x12, 0xbf, 0xa8, 0x9e, 0x4c, 0x79, 0x53,
+	0xb3, 0xec, 0xf1, 0xa0, 0x4f, 0xd2, 0x1b, 0x1b, 0x3e, 0x05, 0x38, 0x02, 0x43, 0xdf, 0x85, 0x6b,
+	0x66, 0xae, 0xfd, 0x8b, 0x2a, 0x45, 0x55, 0x64, 0xd6, 0xb5, 0x7c, 0x67, 0x18, 0xe0, 0x71, 0x59,
+	0xb4, 0x11, 0xba, 0xd8, 0xa1, 0x2c, 0x7c, 0x07, 0x94, 0xd6, 0x95, 0x0d, 0xa5, 0x55, 0x8b, 0xdc,
+	0x1b, 0xad, 0xe1, 0x64, 0x17, 0xdd, 0x83, 0x1a, 0x77, 0x08, 0x8b, 0x77, 0xd4, 0xb2, 0x08, 0xec,
+	0x4a, 0x98, 0x8c, 0x9d, 0xcc, 0x3a, 0xce, 0x49, 0xa1, 0x8f, 0x14, 0xa8, 0x06, 0x74, 0xc0, 0x2c,
+	0x82, 0xc9, 0x81, 0x3a, 0x2f, 0x1c, 0xdf, 0x99, 0xa5, 0x67, 0x92, 0xda, 0xba, 0x14, 0x56, 0xf8,
+	0xbd, 0x18, 0x0a, 0xa7, 0xa8, 0xda, 0x4b, 0x05, 0x56, 0x73, 0x4a, 0x57, 0x30, 0x09, 0xf8, 0xf9,
+	0x49, 0xe0, 0x07, 0x33, 0x3c, 0xf2, 0x39, 0x83, 0xc0, 0xdf, 0xc7, 0x4f, 0x69, 0x10, 0xc2, 0xd0,
+	0x3b, 0xb0, 0x64, 0x66, 0x7e, 0x1d, 0x09, 0x54, 0x45, 0x24, 0xc7, 0xea, 0x68, 0xd8, 0x58, 0xca,
+	0xfe, 0x6c, 0x12, 0xe0, 0xbc, 0x1c, 0x0a, 0xa0, 0xe2, 0xf8, 0xa2, 0x18, 0xc7, 0x67, 0xd8, 0x9e,
+	0xb6, 0x38, 0x0a, 0x6b, 0xa9, 0xd7, 0xe4, 0x42, 0x80, 0x13, 0x20, 0xed, 0x33, 0x05, 0xde, 0x38,
+	0x3b, 0xbc, 0xe8, 0x5b, 0x50, 0xe2, 0xc7, 0x7e, 0xdc, 0x7d, 0xbd, 0x15, 0x57, 0x8b, 0xce, 0xb1,
+	0x4f, 0x4e, 0x87, 0x8d, 0xfc, 0xc9, 0xc3, 0x45, 0x2c, 0xc4, 0xff, 0xef, 0x96, 0x2c, 0xa9, 0x4a,
+	0xc5, 0x73, 0xab, 0x52, 0x0b, 0x8a, 0x03, 0xc7, 0x16, 0xb7, 0xa5, 0xda, 0x7a, 0x5b, 0x0a, 0x14,
+	0x9f, 0xb6, 0xb7, 0x4e, 0x87, 0x8d, 0xb7, 0xce, 0xfb, 0x3d, 0x34, 0x24, 0x13, 0xe8, 0x4f, 0xdb,
+	0x5b, 0x38, 0x54, 0xd6, 0xfe, 0x3b, 0x3f, 0x16, 0xac, 0xf0, 0x4e, 0xa3, 0x77, 0xa1, 0x6a, 0x3b,
+	0x8c, 0x58, 0xdc, 0xa1, 0x9e, 0x3c, 0x68, 0x3d, 0x26, 0xbb, 0x15, 0x6f, 0x9c, 0x66, 0x1f, 0x70,
+	0xaa, 0x80, 0x3e, 0x80, 0xd2, 0x01, 0xa3, 0xae, 0x6c, 0xe5, 0x66, 0x59, 0x7e, 0xc2, 0x4c, 0x4a,
+	0x5d, 0xf1, 0x90, 0x51, 0x17, 0x0b, 0x28, 0x74, 0x08, 0x05, 0x4e, 0xd5, 0xe2, 0xe5, 0x00, 0x82,
+	0x04, 0x2c, 0x74, 0x28, 0x2e, 0x70, 0x1a, 0x66, 0x64, 0x40, 0xd8, 0x91, 0x63, 0x91, 0x78, 0xc0,
+	0x9a, 0x32, 0x23, 0xf7, 0x22, 0x6b, 0x69, 0x46, 0xca, 0x85, 0x00, 0x27, 0x40, 0xe8, 0x1b, 0x99,
+	0xfa, 0x28, 0x2b, 0x5e, 0xfa, 0x0a, 0x9a, 0xa8, 0x91, 0xef, 0xc3, 0xbc, 0x19, 0x45, 0x6f, 0x5e,
+	0x44, 0x0f, 0x87, 0x6d, 0xc0, 0x66, 0x1c, 0xb6, 0xad, 0x0b, 0x7f, 0x13, 0x20, 0xd6, 0x20, 0xb4,
+	0x97, 0x7c, 0x16, 0xd0, 0xc3, 0xf4, 0x88, 0xec, 0x60, 0x89, 0x80, 0xbe, 0x03, 0x4b, 0xc4, 0x33,
+	0xf7, 0xfb, 0x64, 0x87, 0x76, 0xbb, 0x8e, 0xd7, 0x55, 0x17, 0xd6, 0x95, 0x8d, 0x4a, 0xeb, 0x86,
+	0xa4, 0xb7, 0xb4, 0x9d, 0xdd, 0xc4, 0x79, 0x59, 0xc4, 0xa1, 0xca, 0x4c, 0x4e, 0x76, 0x1c, 0xd7,
+	0xe1, 0x6a, 0x65, 0x5d, 0x99, 0xbe, 0xb3, 0xc4, 0xb1, 0xb9, 0xa8, 0x10, 0x27, 0x8f, 0x38, 0x05,
+	0x42, 0x3f, 0x87, 0xc5, 0x7e, 0xd2, 0x99, 0x04, 0x6a, 0x55, 0x04, 0xf1, 0xd1, 0x74, 0xb8, 0x69,
+	0xab, 0x93, 0x4e, 0xeb, 0xe9, 0x5a, 0x80, 0xb3, 0x88, 0xda, 0x9f, 0x0b, 0x80, 0x72, 0x89, 0xb6,
+	0xc7, 0x4d, 0x1e, 0x84, 0xf3, 0xd0, 0x92, 0x97, 0x5d, 0x56, 0x95, 0x4b, 0x7c, 0x51, 0x25, 0x11,
+	0xca, 0xef, 0xe7, 0x19, 0xa0, 0x9f, 0x41, 0x8d, 0x33, 0xf3, 0xe0, 0xc0, 0xb1, 0x04, 0x47, 0x79,
+	0xab, 0xb7, 0x2e, 0xcc, 0x48, 0x7c, 0x57, 0xd2, 0x93, 0x04, 0xea, 0x64, 0x6c, 0xa5, 0x5d, 0x64,
+	0x76, 0x15, 0xe7, 0xf0, 0xb4, 0xff, 0x94, 0x60, 0x65, 0x97, 0xda, 0x44, 0x3c, 0xed, 0x0d, 0x5c,
+	0xd7, 0x64, 0x57, 0xd1, 0x44, 0xfd, 0x5e, 0x81, 0x6b, 0x59, 0x47, 0x38, 0x49, 0x3f, 0x65, 0xcc,
+	0x30, 0x18, 0x91, 0x1b, 0xde, 0x94, 0x4c, 0xae, 0xed, 0xe6, 0x01, 0xf1, 0x38, 0x03, 0xf4, 0x37,
+	0x05, 0x6e, 0x45, 0x28, 0x0f, 0xfa, 0x83, 0x80, 0x13, 0x36, 0xa6, 0xa1, 0x16, 0x2f, 0x89, 0xe2,
+	0xd7, 0x24, 0xc5, 0x5b, 0x9b, 0x9f, 0x83, 0x8e, 0x3f, 0x97, 0x1b, 0xfa, 0x93, 0x02, 0x37, 0x22,
+	0x81, 0x71, 0xd6, 0xa5, 0x4b, 0x62, 0xfd, 0x15, 0xc9, 0xfa, 0xc6, 0xe6, 0x59, 0xb0, 0xf8, 0x6c,
+	0x36, 0x9a, 0x09, 0xb5, 0xec, 0xb0, 0x7c, 0x19, 0xbf, 0xb7, 0xbc, 0x54, 0x20, 0xad, 0x4c, 0xc8,
+	0x80, 0xeb, 0x16, 0xf5, 0xbc, 0xe8, 0xf5, 0x19, 0x18, 0x84, 0xed, 0x11, 0x8b, 0x7a, 0xb6, 0x1c,
+	0x59, 0xe2, 0x51, 0xe3, 0xfa, 0x83, 0x33, 0x64, 0xf0, 0x99, 0x9a, 0x68, 0x0b, 0x56, 0x7c, 0xd3,
+	0x3a, 0x24, 0x3c, 0x63, 0x2d, 0x1a, 0xdc, 0x54, 0x69, 0x6d, 0xc5, 0x18, 0xdb, 0xc7, 0x13, 0x1a,
+	0xe8, 0x7b, 0xb0, 0xbc, 0x7f, 0xcc, 0x49, 0xc6, 0x46, 0x51, 0xd8, 0x78, 0x43, 0xda, 0x58, 0x6e,
+	0xe5, 0x76, 0xf1, 0x98, 0xb4, 0xf6, 0x6b, 0x05, 0x16, 0xe4, 0xab, 0x0c, 0xdd, 0xcb, 0x8c, 0x50,
+	0x91, 0x23, 0xd5, 0x57, 0x8f, 0x4f, 0x68, 0x57, 0x0e, 0x6f, 0x85, 0x57, 0xdc, 0xf1, 0xf0, 0x2b,
+	0xaf, 0x1e, 0x7d, 0xe5, 0xd5, 0xdb, 0x1e, 0x7f, 0xc2, 0xf6, 0x38, 0x73, 0xbc, 0x6e, 0xab, 0x92,
+	0x1f, 0xf5, 0x5a, 0xb7, 0x5f, 0x9c, 0xd4, 0xe7, 0x3e, 0x39, 0xa9, 0xcf, 0x7d, 0x7a, 0x52, 0x9f,
+	0xfb, 0xc5, 0xa8, 0xae, 0xbc, 0x18, 0xd5, 0x95, 0x4f, 0x46, 0x75, 0xe5, 0xd3, 0x51, 0x5d, 0xf9,
+	0xe7, 0xa8, 0xae, 0xfc, 0xe6, 0x65, 0x7d, 0xee, 0x87, 0x0b, 0x32, 0xa5, 0xfe, 0x37, 0x00, 0xa1,
+	0x1b, 0x8e, 0x52, 0xf8, 0x1f, 0x00, 0x00,
 }
 
 func (m *AddressGroup) Marshal() (dAtA []byte, err error) {
@@ -1386,6 +1486,44 @@ func (m *GroupMemberPod) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *HTTPProtocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HTTPProtocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HTTPProtocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i -= len(m.Path)
+	copy(dAtA[i:], m.Path)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Path)))
+	i--
+	dAtA[i] = 0x1a
+	i -= len(m.Host)
+	copy(dAtA[i:], m.Host)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Host)))
+	i--
+	dAtA[i] = 0x12
+	i -= len(m.Method)
+	copy(dAtA[i:], m.Method)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Method)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *IPBlock) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1466,6 +1604,41 @@ func (m *IPNet) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *L7Protocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *L7Protocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *L7Protocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		{
+			size, err := m.HTTP.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NamedPort) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1737,6 +1910,32 @@ func (m *NetworkPolicyRule) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.L7Protocols) > 0 {
+		for iNdEx := len(m.L7Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.L7Protocols[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGenerated(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
+	if m.RateLimit != nil {
+		{
+			size, err := m.RateLimit.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
 	i--
 	if m.EnableLogging {
 		dAtA[i] = 1
@@ -1948,6 +2147,38 @@ func (m *PodReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RateLimit) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RateLimit) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RateLimit) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i = encodeVarintGenerated(dAtA, i, uint64(m.BytesPerSecond))
+	i--
+	dAtA[i] = 0x18
+	i = encodeVarintGenerated(dAtA, i, uint64(m.PacketsPerSecond))
+	i--
+	dAtA[i] = 0x10
+	i = encodeVarintGenerated(dAtA, i, uint64(m.ConnectionsPerSecond))
+	i--
+	dAtA[i] = 0x8
+	return len(dAtA) - i, nil
+}
+
 func (m *Service) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -2229,6 +2460,21 @@ func (m *GroupMemberPod) Size() (n int) {
 	return n
 }
 
+func (m *HTTPProtocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Method)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Host)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Path)
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
 func (m *IPBlock) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2260,6 +2506,19 @@ func (m *IPNet) Size() (n int) {
 	return n
 }
 
+func (m *L7Protocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		l = m.HTTP.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
 func (m *NamedPort) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2386,6 +2645,16 @@ func (m *NetworkPolicyRule) Size() (n int) {
 		n += 1 + l + sovGenerated(uint64(l))
 	}
 	n += 2
+	if m.RateLimit != nil {
+		l = m.RateLimit.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	if len(m.L7Protocols) > 0 {
+		for _, e := range m.L7Protocols {
+			l = e.Size()
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2444,6 +2713,18 @@ func (m *PodReference) Size() (n int) {
 	return n
 }
 
+func (m *RateLimit) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 1 + sovGenerated(uint64(m.ConnectionsPerSecond))
+	n += 1 + sovGenerated(uint64(m.PacketsPerSecond))
+	n += 1 + sovGenerated(uint64(m.BytesPerSecond))
+	return n
+}
+
 func (m *Service) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2672,6 +2953,18 @@ func (this *GroupMemberPod) String() string {
 	}, "")
 	return s
 }
+func (this *HTTPProtocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HTTPProtocol{`,
+		`Method:` + fmt.Sprintf("%v", this.Method) + `,`,
+		`Host:` + fmt.Sprintf("%v", this.Host) + `,`,
+		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *IPBlock) String() string {
 	if this == nil {
 		return "nil"
@@ -2699,6 +2992,16 @@ func (this *IPNet) String() string {
 	}, "")
 	return s
 }
+func (this *L7Protocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&L7Protocol{`,
+		`HTTP:` + strings.Replace(this.HTTP.String(), "HTTPProtocol", "HTTPProtocol", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *NamedPort) String() string {
 	if this == nil {
 		return "nil"
@@ -2785,6 +3088,11 @@ func (this *NetworkPolicyRule) String() string {
 		repeatedStringForServices += strings.Replace(strings.Replace(f.String(), "Service", "Service", 1), `&`, ``, 1) + ","
 	}
 	repeatedStringForServices += "}"
+	repeatedStringForL7Protocols := "[]L7Protocol{"
+	for _, f := range this.L7Protocols {
+		repeatedStringForL7Protocols += strings.Replace(strings.Replace(f.String(), "L7Protocol", "L7Protocol", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForL7Protocols += "}"
 	s := strings.Join([]string{`&NetworkPolicyRule{`,
 		`Direction:` + fmt.Sprintf("%v", this.Direction) + `,`,
 		`From:` + strings.Replace(strings.Replace(this.From.String(), "NetworkPolicyPeer", "NetworkPolicyPeer", 1), `&`, ``, 1) + `,`,
@@ -2793,6 +3101,8 @@ func (this *NetworkPolicyRule) String() string {
 		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
 		`Action:` + valueToStringGenerated(this.Action) + `,`,
 		`EnableLogging:` + fmt.Sprintf("%v", this.EnableLogging) + `,`,
+		`RateLimit:` + strings.Replace(this.RateLimit.String(), "RateLimit", "RateLimit", 1) + `,`,
+		`L7Protocols:` + repeatedStringForL7Protocols + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2847,6 +3157,18 @@ func (this *PodReference) String() string {
 	}, "")
 	return s
 }
+func (this *RateLimit) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&RateLimit{`,
+		`ConnectionsPerSecond:` + fmt.Sprintf("%v", this.ConnectionsPerSecond) + `,`,
+		`PacketsPerSecond:` + fmt.Sprintf("%v", this.PacketsPerSecond) + `,`,
+		`BytesPerSecond:` + fmt.Sprintf("%v", this.BytesPerSecond) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *Service) String() string {
 	if this == nil {
 		return "nil"
@@ -4412,7 +4734,7 @@ func (m *GroupMemberPod) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *IPBlock) Unmarshal(dAtA []byte) error {
+func (m *HTTPProtocol) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4435,17 +4757,17 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IPBlock: wiretype end group for non-group")
+			return fmt.Errorf("proto: HTTPProtocol: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IPBlock: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: HTTPProtocol: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CIDR", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Method", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -4455,30 +4777,29 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.CIDR.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Method = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Except", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -4488,22 +4809,172 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Except = append(m.Except, IPNet{})
+			m.Host = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *IPBlock) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IPBlock: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IPBlock: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CIDR", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.CIDR.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Except", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Except = append(m.Except, IPNet{})
 			if err := m.Except[len(m.Except)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
@@ -4638,6 +5109,95 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *L7Protocol) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: L7Protocol: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: L7Protocol: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTP", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.HTTP == nil {
+				m.HTTP = &HTTPProtocol{}
+			}
+			if err := m.HTTP.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *NamedPort) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -5647,6 +6207,76 @@ func (m *NetworkPolicyRule) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.EnableLogging = bool(v != 0)
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RateLimit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RateLimit == nil {
+				m.RateLimit = &RateLimit{}
+			}
+			if err := m.RateLimit.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field L7Protocols", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.L7Protocols = append(m.L7Protocols, L7Protocol{})
+			if err := m.L7Protocols[len(m.L7Protocols)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -6095,6 +6725,116 @@ func (m *PodReference) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RateLimit) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RateLimit: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RateLimit: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectionsPerSecond", wireType)
+			}
+			m.ConnectionsPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ConnectionsPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PacketsPerSecond", wireType)
+			}
+			m.PacketsPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PacketsPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BytesPerSecond", wireType)
+			}
+			m.BytesPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BytesPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *Service) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -222,6 +222,42 @@ type NetworkPolicyRule struct {
 	Action *secv1alpha1.RuleAction `json:"action,omitempty" protobuf:"bytes,6,opt,name=action,casttype=github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1.RuleAction"`
 	// EnableLogging indicates whether or not to generate logs when rules are matched. Default to false.
 	EnableLogging bool `json:"enableLogging" protobuf:"varint,7,opt,name=enableLogging"`
+	// RateLimit limits the rate of the traffic matched by the rule. nil means
+	// the traffic is not limited.
+	RateLimit *RateLimit `json:"rateLimit,omitempty" protobuf:"bytes,8,opt,name=rateLimit"`
+	// L7Protocols restricts the traffic allowed by the rule to the application
+	// layer requests matching any of these protocols. Empty means the traffic
+	// is not restricted.
+	L7Protocols []L7Protocol `json:"l7Protocols,omitempty" protobuf:"bytes,9,rep,name=l7Protocols"`
+}
+
+// L7Protocol describes an application layer protocol match.
+type L7Protocol struct {
+	// HTTP matches HTTP requests.
+	HTTP *HTTPProtocol `json:"http,omitempty" protobuf:"bytes,1,opt,name=http"`
+}
+
+// HTTPProtocol matches HTTP requests. An empty field matches any value.
+type HTTPProtocol struct {
+	// Method is the method of the request.
+	Method string `json:"method,omitempty" protobuf:"bytes,1,opt,name=method"`
+	// Host is the host of the request. It matches any subdomain of a domain
+	// if it starts with "*.".
+	Host string `json:"host,omitempty" protobuf:"bytes,2,opt,name=host"`
+	// Path is the path of the request. It matches any path starting with a
+	// prefix if it ends with "*".
+	Path string `json:"path,omitempty" protobuf:"bytes,3,opt,name=path"`
+}
+
+// RateLimit describes the limits on the rate of the traffic matched by a rule.
+// A zero value means the corresponding rate is not limited.
+type RateLimit struct {
+	// ConnectionsPerSecond is the maximum rate of new connections.
+	ConnectionsPerSecond int32 `json:"connectionsPerSecond,omitempty" protobuf:"varint,1,opt,name=connectionsPerSecond"`
+	// PacketsPerSecond is the maximum rate of packets of the connections.
+	PacketsPerSecond int32 `json:"packetsPerSecond,omitempty" protobuf:"varint,2,opt,name=packetsPerSecond"`
+	// BytesPerSecond is the maximum rate of bytes of the connections.
+	BytesPerSecond int32 `json:"bytesPerSecond,omitempty" protobuf:"varint,3,opt,name=bytesPerSecond"`
 }
 
 // Protocol defines network protocols supported for things like container ports.
@@ -66,6 +66,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*HTTPProtocol)(nil), (*controlplane.HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_HTTPProtocol_To_controlplane_HTTPProtocol(a.(*HTTPProtocol), b.(*controlplane.HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.HTTPProtocol)(nil), (*HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_HTTPProtocol_To_v1beta1_HTTPProtocol(a.(*controlplane.HTTPProtocol), b.(*HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*IPBlock)(nil), (*controlplane.IPBlock)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_IPBlock_To_controlplane_IPBlock(a.(*IPBlock), b.(*controlplane.IPBlock), scope)
 	}); err != nil {
@@ -86,6 +96,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*L7Protocol)(nil), (*controlplane.L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_L7Protocol_To_controlplane_L7Protocol(a.(*L7Protocol), b.(*controlplane.L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.L7Protocol)(nil), (*L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_L7Protocol_To_v1beta1_L7Protocol(a.(*controlplane.L7Protocol), b.(*L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NamedPort)(nil), (*controlplane.NamedPort)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_NamedPort_To_controlplane_NamedPort(a.(*NamedPort), b.(*controlplane.NamedPort), scope)
 	}); err != nil {
@@ -176,6 +196,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RateLimit)(nil), (*controlplane.RateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_RateLimit_To_controlplane_RateLimit(a.(*RateLimit), b.(*controlplane.RateLimit), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.RateLimit)(nil), (*RateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_RateLimit_To_v1beta1_RateLimit(a.(*controlplane.RateLimit), b.(*RateLimit), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Service)(nil), (*controlplane.Service)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Service_To_controlplane_Service(a.(*Service), b.(*controlplane.Service), scope)
 	}); err != nil {
@@ -540,9 +570,34 @@ func autoConvert_controlplane_GroupMember_To_v1beta1_GroupMember(in *controlplan
 	out.ExternalEntity = (*ExternalEntityReference)(unsafe.Pointer(in.ExternalEntity))
 	// WARNING: in.IPs requires manual conversion: does not exist in peer-type
 	// WARNING: in.Ports requires manual conversion: does not exist in peer-type
+	// WARNING: in.Node requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1beta1_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	out.Method = in.Method
+	out.Host = in.Host
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_v1beta1_HTTPProtocol_To_controlplane_HTTPProtocol is an autogenerated conversion function.
+func Convert_v1beta1_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_v1beta1_HTTPProtocol_To_controlplane_HTTPProtocol(in, out, s)
+}
+
+func autoConvert_controlplane_HTTPProtocol_To_v1beta1_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	out.Method = in.Method
+	out.Host = in.Host
+	out.Path = in.Path
 	return nil
 }
 
+// Convert_controlplane_HTTPProtocol_To_v1beta1_HTTPProtocol is an autogenerated conversion function.
+func Convert_controlplane_HTTPProtocol_To_v1beta1_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_controlplane_HTTPProtocol_To_v1beta1_HTTPProtocol(in, out, s)
+}
+
 func autoConvert_v1beta1_IPBlock_To_controlplane_IPBlock(in *IPBlock, out *controlplane.IPBlock, s conversion.Scope) error {
 	if err := Convert_v1beta1_IPNet_To_controlplane_IPNet(&in.CIDR, &out.CIDR, s); err != nil {
 		return err
@@ -591,6 +646,26 @@ func Convert_controlplane_IPNet_To_v1beta1_IPNet(in *controlplane.IPNet, out *IP
 	return autoConvert_controlplane_IPNet_To_v1beta1_IPNet(in, out, s)
 }
 
+func autoConvert_v1beta1_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*controlplane.HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_v1beta1_L7Protocol_To_controlplane_L7Protocol is an autogenerated conversion function.
+func Convert_v1beta1_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	return autoConvert_v1beta1_L7Protocol_To_controlplane_L7Protocol(in, out, s)
+}
+
+func autoConvert_controlplane_L7Protocol_To_v1beta1_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_controlplane_L7Protocol_To_v1beta1_L7Protocol is an autogenerated conversion function.
+func Convert_controlplane_L7Protocol_To_v1beta1_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	return autoConvert_controlplane_L7Protocol_To_v1beta1_L7Protocol(in, out, s)
+}
+
 func autoConvert_v1beta1_NamedPort_To_controlplane_NamedPort(in *NamedPort, out *controlplane.NamedPort, s conversion.Scope) error {
 	out.Port = in.Port
 	out.Name = in.Name
@@ -727,6 +802,8 @@ func autoConvert_v1beta1_NetworkPolicyRule_To_controlplane_NetworkPolicyRule(in
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.RateLimit = (*controlplane.RateLimit)(unsafe.Pointer(in.RateLimit))
+	out.L7Protocols = *(*[]controlplane.L7Protocol)(unsafe.Pointer(&in.L7Protocols))
 	return nil
 }
 
@@ -747,6 +824,8 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta1_NetworkPolicyRule(in
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.RateLimit = (*RateLimit)(unsafe.Pointer(in.RateLimit))
+	out.L7Protocols = *(*[]L7Protocol)(unsafe.Pointer(&in.L7Protocols))
 	return nil
 }
 
@@ -829,6 +908,30 @@ func Convert_controlplane_PodReference_To_v1beta1_PodReference(in *controlplane.
 	return autoConvert_controlplane_PodReference_To_v1beta1_PodReference(in, out, s)
 }
 
+func autoConvert_v1beta1_RateLimit_To_controlplane_RateLimit(in *RateLimit, out *controlplane.RateLimit, s conversion.Scope) error {
+	out.ConnectionsPerSecond = in.ConnectionsPerSecond
+	out.PacketsPerSecond = in.PacketsPerSecond
+	out.BytesPerSecond = in.BytesPerSecond
+	return nil
+}
+
+// Convert_v1beta1_RateLimit_To_controlplane_RateLimit is an autogenerated conversion function.
+func Convert_v1beta1_RateLimit_To_controlplane_RateLimit(in *RateLimit, out *controlplane.RateLimit, s conversion.Scope) error {
+	return autoConvert_v1beta1_RateLimit_To_controlplane_RateLimit(in, out, s)
+}
+
+func autoConvert_controlplane_RateLimit_To_v1beta1_RateLimit(in *controlplane.RateLimit, out *RateLimit, s conversion.Scope) error {
+	out.ConnectionsPerSecond = in.ConnectionsPerSecond
+	out.PacketsPerSecond = in.PacketsPerSecond
+	out.BytesPerSecond = in.BytesPerSecond
+	return nil
+}
+
+// Convert_controlplane_RateLimit_To_v1beta1_RateLimit is an autogenerated conversion function.
+func Convert_controlplane_RateLimit_To_v1beta1_RateLimit(in *controlplane.RateLimit, out *RateLimit, s conversion.Scope) error {
+	return autoConvert_controlplane_RateLimit_To_v1beta1_RateLimit(in, out, s)
+}
+
 func autoConvert_v1beta1_Service_To_controlplane_Service(in *Service, out *controlplane.Service, s conversion.Scope) error {
 	out.Protocol = (*controlplane.Protocol)(unsafe.Pointer(in.Protocol))
 	out.Port = (*intstr.IntOrString)(unsafe.Pointer(in.Port))
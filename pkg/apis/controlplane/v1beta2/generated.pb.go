@@ -269,10 +269,38 @@ func (m *GroupMember) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GroupMember proto.InternalMessageInfo
 
+func (m *HTTPProtocol) Reset()      { *m = HTTPProtocol{} }
+func (*HTTPProtocol) ProtoMessage() {}
+func (*HTTPProtocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d31898dc88dbbf6e, []int{8}
+}
+func (m *HTTPProtocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HTTPProtocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *HTTPProtocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPProtocol.Merge(m, src)
+}
+func (m *HTTPProtocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *HTTPProtocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPProtocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPProtocol proto.InternalMessageInfo
+
 func (m *IPBlock) Reset()      { *m = IPBlock{} }
 func (*IPBlock) ProtoMessage() {}
 func (*IPBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{8}
+	return fileDescriptor_d31898dc88dbbf6e, []int{9}
 }
 func (m *IPBlock) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -300,7 +328,7 @@ var xxx_messageInfo_IPBlock proto.InternalMessageInfo
 func (m *IPNet) Reset()      { *m = IPNet{} }
 func (*IPNet) ProtoMessage() {}
 func (*IPNet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{9}
+	return fileDescriptor_d31898dc88dbbf6e, []int{10}
 }
 func (m *IPNet) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -325,10 +353,38 @@ func (m *IPNet) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_IPNet proto.InternalMessageInfo
 
+func (m *L7Protocol) Reset()      { *m = L7Protocol{} }
+func (*L7Protocol) ProtoMessage() {}
+func (*L7Protocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d31898dc88dbbf6e, []int{11}
+}
+func (m *L7Protocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *L7Protocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *L7Protocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_L7Protocol.Merge(m, src)
+}
+func (m *L7Protocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *L7Protocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_L7Protocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_L7Protocol proto.InternalMessageInfo
+
 func (m *NamedPort) Reset()      { *m = NamedPort{} }
 func (*NamedPort) ProtoMessage() {}
 func (*NamedPort) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{10}
+	return fileDescriptor_d31898dc88dbbf6e, []int{12}
 }
 func (m *NamedPort) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -356,7 +412,7 @@ var xxx_messageInfo_NamedPort proto.InternalMessageInfo
 func (m *NetworkPolicy) Reset()      { *m = NetworkPolicy{} }
 func (*NetworkPolicy) ProtoMessage() {}
 func (*NetworkPolicy) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{11}
+	return fileDescriptor_d31898dc88dbbf6e, []int{13}
 }
 func (m *NetworkPolicy) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -384,7 +440,7 @@ var xxx_messageInfo_NetworkPolicy proto.InternalMessageInfo
 func (m *NetworkPolicyList) Reset()      { *m = NetworkPolicyList{} }
 func (*NetworkPolicyList) ProtoMessage() {}
 func (*NetworkPolicyList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{12}
+	return fileDescriptor_d31898dc88dbbf6e, []int{14}
 }
 func (m *NetworkPolicyList) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -412,7 +468,7 @@ var xxx_messageInfo_NetworkPolicyList proto.InternalMessageInfo
 func (m *NetworkPolicyNodeStatus) Reset()      { *m = NetworkPolicyNodeStatus{} }
 func (*NetworkPolicyNodeStatus) ProtoMessage() {}
 func (*NetworkPolicyNodeStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{13}
+	return fileDescriptor_d31898dc88dbbf6e, []int{15}
 }
 func (m *NetworkPolicyNodeStatus) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -440,7 +496,7 @@ var xxx_messageInfo_NetworkPolicyNodeStatus proto.InternalMessageInfo
 func (m *NetworkPolicyPeer) Reset()      { *m = NetworkPolicyPeer{} }
 func (*NetworkPolicyPeer) ProtoMessage() {}
 func (*NetworkPolicyPeer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{14}
+	return fileDescriptor_d31898dc88dbbf6e, []int{16}
 }
 func (m *NetworkPolicyPeer) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -468,7 +524,7 @@ var xxx_messageInfo_NetworkPolicyPeer proto.InternalMessageInfo
 func (m *NetworkPolicyReference) Reset()      { *m = NetworkPolicyReference{} }
 func (*NetworkPolicyReference) ProtoMessage() {}
 func (*NetworkPolicyReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{15}
+	return fileDescriptor_d31898dc88dbbf6e, []int{17}
 }
 func (m *NetworkPolicyReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -496,7 +552,7 @@ var xxx_messageInfo_NetworkPolicyReference proto.InternalMessageInfo
 func (m *NetworkPolicyRule) Reset()      { *m = NetworkPolicyRule{} }
 func (*NetworkPolicyRule) ProtoMessage() {}
 func (*NetworkPolicyRule) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{16}
+	return fileDescriptor_d31898dc88dbbf6e, []int{18}
 }
 func (m *NetworkPolicyRule) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -524,7 +580,7 @@ var xxx_messageInfo_NetworkPolicyRule proto.InternalMessageInfo
 func (m *NetworkPolicyStats) Reset()      { *m = NetworkPolicyStats{} }
 func (*NetworkPolicyStats) ProtoMessage() {}
 func (*NetworkPolicyStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{17}
+	return fileDescriptor_d31898dc88dbbf6e, []int{19}
 }
 func (m *NetworkPolicyStats) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -552,7 +608,7 @@ var xxx_messageInfo_NetworkPolicyStats proto.InternalMessageInfo
 func (m *NetworkPolicyStatus) Reset()      { *m = NetworkPolicyStatus{} }
 func (*NetworkPolicyStatus) ProtoMessage() {}
 func (*NetworkPolicyStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{18}
+	return fileDescriptor_d31898dc88dbbf6e, []int{20}
 }
 func (m *NetworkPolicyStatus) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -577,10 +633,38 @@ func (m *NetworkPolicyStatus) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_NetworkPolicyStatus proto.InternalMessageInfo
 
+func (m *NodeReference) Reset()      { *m = NodeReference{} }
+func (*NodeReference) ProtoMessage() {}
+func (*NodeReference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d31898dc88dbbf6e, []int{21}
+}
+func (m *NodeReference) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NodeReference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *NodeReference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeReference.Merge(m, src)
+}
+func (m *NodeReference) XXX_Size() int {
+	return m.Size()
+}
+func (m *NodeReference) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeReference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeReference proto.InternalMessageInfo
+
 func (m *NodeStatsSummary) Reset()      { *m = NodeStatsSummary{} }
 func (*NodeStatsSummary) ProtoMessage() {}
 func (*NodeStatsSummary) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{19}
+	return fileDescriptor_d31898dc88dbbf6e, []int{22}
 }
 func (m *NodeStatsSummary) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -608,7 +692,7 @@ var xxx_messageInfo_NodeStatsSummary proto.InternalMessageInfo
 func (m *PodReference) Reset()      { *m = PodReference{} }
 func (*PodReference) ProtoMessage() {}
 func (*PodReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{20}
+	return fileDescriptor_d31898dc88dbbf6e, []int{23}
 }
 func (m *PodReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -633,10 +717,38 @@ func (m *PodReference) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PodReference proto.InternalMessageInfo
 
+func (m *RateLimit) Reset()      { *m = RateLimit{} }
+func (*RateLimit) ProtoMessage() {}
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d31898dc88dbbf6e, []int{24}
+}
+func (m *RateLimit) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RateLimit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *RateLimit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimit.Merge(m, src)
+}
+func (m *RateLimit) XXX_Size() int {
+	return m.Size()
+}
+func (m *RateLimit) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimit proto.InternalMessageInfo
+
 func (m *Service) Reset()      { *m = Service{} }
 func (*Service) ProtoMessage() {}
 func (*Service) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d31898dc88dbbf6e, []int{21}
+	return fileDescriptor_d31898dc88dbbf6e, []int{25}
 }
 func (m *Service) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -670,8 +782,10 @@ func init() {
 	proto.RegisterType((*AppliedToGroupPatch)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.AppliedToGroupPatch")
 	proto.RegisterType((*ExternalEntityReference)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.ExternalEntityReference")
 	proto.RegisterType((*GroupMember)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.GroupMember")
+	proto.RegisterType((*HTTPProtocol)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.HTTPProtocol")
 	proto.RegisterType((*IPBlock)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.IPBlock")
 	proto.RegisterType((*IPNet)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.IPNet")
+	proto.RegisterType((*L7Protocol)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.L7Protocol")
 	proto.RegisterType((*NamedPort)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NamedPort")
 	proto.RegisterType((*NetworkPolicy)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicy")
 	proto.RegisterType((*NetworkPolicyList)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicyList")
@@ -681,8 +795,10 @@ func init() {
 	proto.RegisterType((*NetworkPolicyRule)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicyRule")
 	proto.RegisterType((*NetworkPolicyStats)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicyStats")
 	proto.RegisterType((*NetworkPolicyStatus)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicyStatus")
+	proto.RegisterType((*NodeReference)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NodeReference")
 	proto.RegisterType((*NodeStatsSummary)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.NodeStatsSummary")
 	proto.RegisterType((*PodReference)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.PodReference")
+	proto.RegisterType((*RateLimit)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.RateLimit")
 	proto.RegisterType((*Service)(nil), "github.com.vmware_tanzu.antrea.pkg.apis.controlplane.v1beta2.Service")
 }
 
@@ -691,110 +807,125 @@ func init() {
 }
 
 var fileDescriptor_d31898dc88dbbf6e = []byte{
-	// 1635 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xbb, 0x6f, 0x1b, 0x47,
-	0x1a, 0xd7, 0xf2, 0x21, 0x89, 0x23, 0x52, 0x8f, 0x91, 0x7d, 0xe6, 0xf9, 0x7c, 0xa4, 0xbc, 0x77,
-	0x38, 0xa8, 0x38, 0x2f, 0x2d, 0x9d, 0xef, 0xce, 0x40, 0x9c, 0x42, 0xb4, 0x64, 0x81, 0x89, 0x4c,
-	0x13, 0x23, 0xa9, 0x09, 0x02, 0x24, 0xab, 0xdd, 0x21, 0xb5, 0xd6, 0x72, 0x77, 0x3d, 0x3b, 0x2b,
-	0x5b, 0x0e, 0x10, 0x24, 0x48, 0x95, 0x34, 0x79, 0x35, 0xa9, 0xd2, 0x19, 0xc9, 0xdf, 0x90, 0x2e,
-	0x9d, 0x4b, 0x97, 0x6e, 0x42, 0x44, 0x34, 0x92, 0x36, 0x45, 0x80, 0x20, 0x10, 0x10, 0x20, 0x98,
-	0xd9, 0xd9, 0x17, 0x29, 0xda, 0x0a, 0x48, 0x09, 0x01, 0x92, 0xca, 0xe2, 0x37, 0xdf, 0x7c, 0xbf,
-	0xdf, 0xf7, 0x9c, 0x99, 0x35, 0xd8, 0x68, 0x19, 0x74, 0xd7, 0xdb, 0x51, 0x34, 0xbb, 0x5d, 0xd9,
-	0x6f, 0xdf, 0x57, 0x09, 0xbe, 0x42, 0x55, 0xeb, 0xa1, 0x57, 0x51, 0x2d, 0x4a, 0xb0, 0x5a, 0x71,
-	0xf6, 0x5a, 0x15, 0xd5, 0x31, 0xdc, 0x8a, 0x66, 0x5b, 0x94, 0xd8, 0xa6, 0x63, 0xaa, 0x16, 0xae,
-	0xec, 0x2f, 0xed, 0x60, 0xaa, 0x2e, 0x57, 0x5a, 0xd8, 0xc2, 0x44, 0xa5, 0x58, 0x57, 0x1c, 0x62,
-	0x53, 0x1b, 0xde, 0x88, 0xac, 0x29, 0xbe, 0xb5, 0x37, 0xb8, 0x35, 0xc5, 0xb7, 0xa6, 0x38, 0x7b,
-	0x2d, 0x85, 0x59, 0x53, 0xe2, 0xd6, 0x14, 0x61, 0xed, 0xe2, 0x95, 0x18, 0x97, 0x96, 0xdd, 0xb2,
-	0x2b, 0xdc, 0xe8, 0x8e, 0xd7, 0xe4, 0xbf, 0xf8, 0x0f, 0xfe, 0x97, 0x0f, 0x76, 0xf1, 0xd6, 0x49,
-	0xa9, 0xbb, 0x54, 0xa5, 0x6e, 0x65, 0x7f, 0x49, 0x35, 0x9d, 0x5d, 0x75, 0xa9, 0x97, 0xf4, 0xc5,
-	0x6b, 0x7b, 0xd7, 0x5d, 0xc5, 0xb0, 0x99, 0x6e, 0x5b, 0xd5, 0x76, 0x0d, 0x0b, 0x93, 0x83, 0x68,
-	0x73, 0x1b, 0x53, 0xb5, 0xb2, 0xdf, 0xbf, 0xab, 0x32, 0x68, 0x17, 0xf1, 0x2c, 0x6a, 0xb4, 0x71,
-	0xdf, 0x86, 0xff, 0xbd, 0x68, 0x83, 0xab, 0xed, 0xe2, 0xb6, 0xda, 0xb7, 0xef, 0x3f, 0x83, 0xf6,
-	0x79, 0xd4, 0x30, 0x2b, 0x86, 0x45, 0x5d, 0x4a, 0x7a, 0x37, 0xc9, 0x3f, 0x49, 0x20, 0xbf, 0xa2,
-	0xeb, 0x04, 0xbb, 0xee, 0x3a, 0xb1, 0x3d, 0x07, 0xbe, 0x09, 0x26, 0x99, 0x27, 0xba, 0x4a, 0xd5,
-	0xa2, 0xb4, 0x20, 0x2d, 0x4e, 0x2d, 0x5f, 0x55, 0x7c, 0xc3, 0x4a, 0xdc, 0x70, 0x94, 0x21, 0xa6,
-	0xad, 0xec, 0x2f, 0x29, 0x77, 0x76, 0xee, 0x62, 0x8d, 0xde, 0xc6, 0x54, 0xad, 0xc2, 0xc7, 0x9d,
-	0xf2, 0x58, 0xb7, 0x53, 0x06, 0x91, 0x0c, 0x85, 0x56, 0xe1, 0x7b, 0x12, 0xc8, 0xb7, 0x18, 0xd6,
-	0x6d, 0xdc, 0xde, 0xc1, 0xc4, 0x2d, 0xa6, 0x16, 0xd2, 0x8b, 0x53, 0xcb, 0x35, 0x65, 0x98, 0x9a,
-	0x50, 0xd6, 0x23, 0x8b, 0xd5, 0x73, 0x02, 0x3f, 0x1f, 0x13, 0xba, 0x28, 0x01, 0x2a, 0x1f, 0x4a,
-	0x60, 0x36, 0xee, 0xf8, 0x86, 0xe1, 0x52, 0xf8, 0x7a, 0x9f, 0xf3, 0xca, 0xc9, 0x9c, 0x67, 0xbb,
-	0xb9, 0xeb, 0xb3, 0x02, 0x7a, 0x32, 0x90, 0xc4, 0x1c, 0xb7, 0x41, 0xd6, 0xa0, 0xb8, 0x1d, 0x38,
-	0xfc, 0xca, 0x70, 0x0e, 0xc7, 0xc9, 0x57, 0x0b, 0x02, 0x36, 0x5b, 0x63, 0x00, 0xc8, 0xc7, 0x91,
-	0x1f, 0xa5, 0xc1, 0x5c, 0x5c, 0xad, 0xa1, 0x52, 0x6d, 0xf7, 0x0c, 0x32, 0xfc, 0x89, 0x04, 0xe6,
-	0x54, 0x5d, 0xc7, 0xfa, 0xfa, 0xa9, 0xa6, 0xf9, 0xaf, 0x82, 0x04, 0xf3, 0x31, 0x89, 0x85, 0xfa,
-	0xe1, 0xe1, 0x67, 0x12, 0x98, 0x27, 0xb8, 0x6d, 0xef, 0xf7, 0xd0, 0x4a, 0x8f, 0x9a, 0xd6, 0xdf,
-	0x04, 0xad, 0x79, 0xd4, 0x8f, 0x86, 0x8e, 0xa3, 0x20, 0xff, 0x2c, 0x81, 0xe9, 0x15, 0xc7, 0x31,
-	0x0d, 0xac, 0x6f, 0xd9, 0x7f, 0xac, 0x36, 0xfc, 0x4e, 0x02, 0x30, 0xe9, 0xfa, 0x19, 0x34, 0xe2,
-	0xbd, 0x64, 0x23, 0x6e, 0x0c, 0xd9, 0x88, 0x09, 0xfa, 0x03, 0x5a, 0xf1, 0x8b, 0x34, 0x98, 0x4f,
-	0x2a, 0xfe, 0xd9, 0x8c, 0xbf, 0xcf, 0x66, 0x34, 0xc1, 0x85, 0xb5, 0x07, 0x14, 0x13, 0x4b, 0x35,
-	0xd7, 0x2c, 0x6a, 0xd0, 0x03, 0x84, 0x9b, 0x98, 0x60, 0x4b, 0xc3, 0x70, 0x01, 0x64, 0x2c, 0xb5,
-	0x8d, 0x79, 0xa2, 0x72, 0xd5, 0xbc, 0x30, 0x9d, 0xa9, 0xab, 0x6d, 0x8c, 0xf8, 0x0a, 0xac, 0x80,
-	0x1c, 0xfb, 0xd7, 0x75, 0x54, 0x0d, 0x17, 0x53, 0x5c, 0x6d, 0x4e, 0xa8, 0xe5, 0xea, 0xc1, 0x02,
-	0x8a, 0x74, 0xe4, 0xcf, 0xd3, 0x60, 0x2a, 0x06, 0x0f, 0x31, 0x48, 0x3b, 0xb6, 0x2e, 0x4a, 0x61,
-	0xc8, 0x13, 0xa2, 0x61, 0xeb, 0x21, 0xf7, 0xea, 0x44, 0xb7, 0x53, 0x4e, 0x33, 0x09, 0xb3, 0x0f,
-	0x3f, 0x96, 0xc0, 0x34, 0x4e, 0x78, 0xc9, 0xd9, 0x4e, 0x2d, 0x6f, 0x0f, 0x07, 0x39, 0x20, 0x72,
-	0x55, 0xd8, 0xed, 0x94, 0xa7, 0x7b, 0x16, 0x7b, 0x08, 0xc0, 0x7f, 0x81, 0xb4, 0xe1, 0xf8, 0x25,
-	0x90, 0xaf, 0x9e, 0x63, 0x74, 0x6b, 0x0d, 0xf7, 0xa8, 0x53, 0xce, 0xd5, 0x1a, 0xe2, 0x10, 0x43,
-	0x4c, 0x01, 0x9a, 0x20, 0xeb, 0xd8, 0x84, 0xba, 0xc5, 0x0c, 0x2f, 0x96, 0xf5, 0xe1, 0x18, 0xb3,
-	0xac, 0xe8, 0x0d, 0x9b, 0xd0, 0xa8, 0x71, 0xd9, 0x2f, 0x17, 0xf9, 0x20, 0xf2, 0x37, 0x12, 0x98,
-	0xa8, 0x35, 0xaa, 0xa6, 0xad, 0xed, 0x41, 0x0c, 0x32, 0x9a, 0xa1, 0x13, 0x91, 0x9d, 0x9b, 0xc3,
-	0x01, 0xd7, 0x1a, 0x75, 0x4c, 0xa3, 0x22, 0xba, 0x59, 0x5b, 0x45, 0x88, 0x9b, 0x87, 0x7b, 0x60,
-	0x1c, 0x3f, 0xd0, 0xb0, 0x43, 0x45, 0x97, 0x8e, 0x04, 0x68, 0x5a, 0x00, 0x8d, 0xaf, 0x71, 0xd3,
-	0x48, 0x40, 0xc8, 0x4d, 0x90, 0xe5, 0x0a, 0xf0, 0x1f, 0x20, 0x65, 0x38, 0xdc, 0xb5, 0x7c, 0x75,
-	0xbe, 0xdb, 0x29, 0xa7, 0x6a, 0x8d, 0x64, 0xf0, 0x53, 0x86, 0x03, 0xaf, 0x83, 0xbc, 0x43, 0x70,
-	0xd3, 0x78, 0xb0, 0x81, 0xad, 0x16, 0xdd, 0xe5, 0x45, 0x93, 0x8d, 0x06, 0x7d, 0x23, 0xb6, 0x86,
-	0x12, 0x9a, 0xf2, 0xfb, 0x12, 0xc8, 0x85, 0xb1, 0x66, 0x9d, 0xc4, 0xc2, 0xcb, 0xe1, 0xb2, 0x51,
-	0x10, 0xd8, 0x1a, 0xe2, 0x2b, 0x61, 0xaf, 0xa5, 0x06, 0xf6, 0xda, 0x75, 0x30, 0xc9, 0xef, 0xb0,
-	0x9a, 0x6d, 0x16, 0xd3, 0x5c, 0xeb, 0x52, 0x30, 0xf3, 0x1b, 0x42, 0x7e, 0x14, 0xfb, 0x1b, 0x85,
-	0xda, 0xf2, 0x07, 0x19, 0x50, 0xa8, 0x63, 0x7a, 0xdf, 0x26, 0x7b, 0x0d, 0xdb, 0x34, 0xb4, 0x83,
-	0x33, 0x18, 0xc3, 0x14, 0x64, 0x89, 0x67, 0xe2, 0x60, 0xf2, 0xde, 0x19, 0xb2, 0x6a, 0xe3, 0xec,
-	0x91, 0x67, 0xe2, 0xa8, 0x7a, 0xd9, 0x2f, 0x17, 0xf9, 0x60, 0xf0, 0x65, 0x30, 0xa3, 0x26, 0x4e,
-	0x1d, 0xbf, 0xbf, 0x72, 0x3c, 0xc3, 0x33, 0xc9, 0x03, 0xc9, 0x45, 0xbd, 0xba, 0x70, 0x91, 0x85,
-	0xd8, 0xb0, 0x09, 0x9b, 0x0f, 0x99, 0x05, 0x69, 0x51, 0xaa, 0xe6, 0xfd, 0xf0, 0xfa, 0x32, 0x14,
-	0xae, 0xc2, 0x6b, 0x20, 0x4f, 0x0d, 0x4c, 0x82, 0x95, 0x62, 0x96, 0x27, 0x76, 0x96, 0x15, 0xc5,
-	0x56, 0x4c, 0x8e, 0x12, 0x5a, 0xf0, 0x5d, 0x09, 0xe4, 0x5c, 0xdb, 0x23, 0x1a, 0x46, 0xb8, 0x59,
-	0x1c, 0xe7, 0x81, 0xdf, 0x1a, 0x65, 0x64, 0xc2, 0x01, 0x54, 0x60, 0x13, 0x78, 0x33, 0x80, 0x42,
-	0x11, 0xaa, 0xfc, 0x4c, 0x02, 0x73, 0x89, 0x4d, 0x67, 0x70, 0x01, 0x71, 0x92, 0x17, 0x90, 0x57,
-	0x47, 0xe8, 0xf2, 0x80, 0xfb, 0xc7, 0x5b, 0xe0, 0x42, 0x42, 0xad, 0x6e, 0xeb, 0x78, 0x93, 0xaa,
-	0xd4, 0x73, 0xe1, 0xbf, 0xc1, 0xa4, 0x65, 0xeb, 0xb8, 0x1e, 0x9d, 0x6c, 0x21, 0xf5, 0xba, 0x90,
-	0xa3, 0x50, 0x03, 0x2e, 0x03, 0x20, 0xde, 0x90, 0x86, 0x6d, 0xf1, 0xee, 0x4c, 0x47, 0x95, 0xbf,
-	0x1e, 0xae, 0xa0, 0x98, 0x96, 0xfc, 0x75, 0x6f, 0x88, 0x1b, 0x18, 0x13, 0xf8, 0x7f, 0x50, 0x50,
-	0x63, 0x8f, 0x13, 0xb7, 0x28, 0xf1, 0xca, 0x9c, 0xeb, 0x76, 0xca, 0x85, 0xf8, 0xab, 0xc5, 0x45,
-	0x49, 0x3d, 0xe8, 0x82, 0x49, 0xc3, 0xe1, 0x13, 0x39, 0x08, 0xe0, 0xda, 0xb0, 0x13, 0x92, 0x5b,
-	0x8b, 0xfc, 0x16, 0x02, 0x17, 0x85, 0x40, 0xf2, 0xf7, 0x12, 0xf8, 0xcb, 0xf1, 0xb5, 0x05, 0xff,
-	0x0b, 0x32, 0xf4, 0xc0, 0x09, 0x82, 0x77, 0x39, 0x18, 0x55, 0x5b, 0x07, 0x0e, 0x3e, 0xea, 0x94,
-	0x93, 0x9e, 0x33, 0x21, 0xe2, 0xea, 0xbf, 0xf9, 0xae, 0x10, 0x8e, 0xc4, 0xf4, 0xc0, 0x91, 0x58,
-	0x05, 0x69, 0xcf, 0xd0, 0x79, 0xab, 0xe6, 0xaa, 0x57, 0x85, 0x42, 0x7a, 0xbb, 0xb6, 0x7a, 0xd4,
-	0x29, 0x5f, 0x1e, 0xf4, 0x79, 0x80, 0x91, 0x71, 0x95, 0xed, 0xda, 0x2a, 0x62, 0x9b, 0xe5, 0x5f,
-	0x32, 0x3d, 0xc9, 0x62, 0x03, 0x05, 0xde, 0x00, 0x39, 0xdd, 0x20, 0x58, 0xe3, 0x59, 0xf7, 0x1d,
-	0x2d, 0x05, 0x64, 0x57, 0x83, 0x85, 0xa3, 0xf8, 0x0f, 0x14, 0x6d, 0x80, 0xf7, 0x40, 0xa6, 0x49,
-	0xec, 0xb6, 0xb8, 0x63, 0x8c, 0x72, 0xf6, 0xb1, 0x4a, 0x8a, 0x42, 0x71, 0x8b, 0xd8, 0x6d, 0xc4,
-	0xa1, 0xe0, 0x1e, 0x48, 0x51, 0x9b, 0x87, 0xea, 0x14, 0x00, 0x81, 0x00, 0x4c, 0x6d, 0xd9, 0x28,
-	0x45, 0x6d, 0x56, 0x91, 0x2e, 0x26, 0xfb, 0x86, 0x86, 0x83, 0x5b, 0xc9, 0x90, 0x15, 0xb9, 0xe9,
-	0x5b, 0x8b, 0x2a, 0x52, 0x08, 0x5c, 0x14, 0x02, 0xb1, 0xbe, 0x75, 0x7a, 0xc6, 0x6d, 0x74, 0xfe,
-	0xf5, 0x0d, 0xe8, 0xbb, 0x60, 0x5c, 0xf5, 0xb3, 0x37, 0xce, 0xb3, 0x87, 0xd8, 0x5d, 0x60, 0x25,
-	0x48, 0xdb, 0xea, 0x89, 0x3f, 0x91, 0x61, 0xcd, 0x63, 0xf6, 0xc2, 0xaf, 0x64, 0x0a, 0x2b, 0x0f,
-	0xdf, 0x0e, 0x12, 0x08, 0xf0, 0x25, 0x50, 0xc0, 0x96, 0xba, 0x63, 0xe2, 0x0d, 0xbb, 0xd5, 0x32,
-	0xac, 0x56, 0x71, 0x62, 0x41, 0x5a, 0x9c, 0xac, 0x9e, 0x17, 0xf4, 0x0a, 0x6b, 0xf1, 0x45, 0x94,
-	0xd4, 0x95, 0xbf, 0x4c, 0x01, 0x98, 0x88, 0x38, 0x1b, 0x53, 0x2e, 0xbb, 0xb1, 0x16, 0xac, 0xb8,
-	0x58, 0x8c, 0xe5, 0xd3, 0x39, 0x2e, 0x42, 0xaa, 0xc9, 0xf5, 0x24, 0x03, 0xf8, 0x36, 0xc8, 0x53,
-	0xa2, 0x36, 0x9b, 0x86, 0xc6, 0x39, 0x8a, 0xf2, 0x5e, 0x3d, 0x31, 0x23, 0xfe, 0xbd, 0x51, 0x09,
-	0x23, 0xb9, 0x15, 0xb3, 0x15, 0xdd, 0xa9, 0xe2, 0x52, 0x94, 0xc0, 0x93, 0x7f, 0x94, 0xc0, 0x7c,
-	0x5f, 0xa8, 0x3c, 0xf7, 0x0c, 0x6e, 0x33, 0x0f, 0x41, 0x96, 0x9d, 0x08, 0xc1, 0xfc, 0xdd, 0x1e,
-	0x61, 0x12, 0xa2, 0x93, 0x29, 0x3a, 0xca, 0x98, 0xcc, 0x45, 0x3e, 0xa4, 0xfc, 0x43, 0x06, 0xcc,
-	0x06, 0x4a, 0xee, 0xa6, 0xd7, 0x6e, 0xab, 0xe4, 0x2c, 0x2e, 0x70, 0x9f, 0x4a, 0x60, 0x26, 0x9e,
-	0x7e, 0x23, 0xf4, 0xbe, 0x31, 0x42, 0xef, 0xfd, 0xe4, 0x5f, 0x10, 0x4c, 0x66, 0xea, 0x49, 0x40,
-	0xd4, 0xcb, 0x00, 0x7e, 0x25, 0x81, 0x4b, 0x3e, 0xca, 0x4d, 0xd3, 0x73, 0x29, 0x26, 0x3d, 0x3b,
-	0xc4, 0x8b, 0x7a, 0xf4, 0x14, 0xff, 0x29, 0x28, 0x5e, 0x5a, 0x79, 0x0e, 0x3a, 0x7a, 0x2e, 0x37,
-	0xf8, 0x48, 0x02, 0xe7, 0x7d, 0x85, 0x5e, 0xd6, 0x99, 0x53, 0x62, 0xfd, 0x77, 0xc1, 0xfa, 0xfc,
-	0xca, 0x71, 0xb0, 0xe8, 0x78, 0x36, 0xb2, 0x0a, 0xf2, 0xf1, 0xb7, 0xf4, 0x69, 0x7c, 0x07, 0xf8,
-	0x50, 0x02, 0x13, 0x62, 0xc6, 0xc3, 0x6b, 0xb1, 0x87, 0x8d, 0x0f, 0x51, 0x7c, 0xf1, 0xa3, 0x06,
-	0xd6, 0xc5, 0x93, 0x2a, 0xf5, 0x82, 0xea, 0xf7, 0xa8, 0x61, 0x2a, 0xfe, 0xff, 0x06, 0x28, 0x35,
-	0x8b, 0xde, 0x21, 0x9b, 0x94, 0x18, 0x56, 0xab, 0x3a, 0x99, 0x7c, 0x80, 0x55, 0xaf, 0x3c, 0x3e,
-	0x2c, 0x8d, 0x3d, 0x39, 0x2c, 0x8d, 0x3d, 0x3d, 0x2c, 0x8d, 0xbd, 0xd3, 0x2d, 0x49, 0x8f, 0xbb,
-	0x25, 0xe9, 0x49, 0xb7, 0x24, 0x3d, 0xed, 0x96, 0xa4, 0x6f, 0xbb, 0x25, 0xe9, 0xa3, 0x67, 0xa5,
-	0xb1, 0xd7, 0x26, 0x44, 0xb0, 0x7f, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x95, 0xb9, 0x0c, 0x82, 0x20,
-	0x1a, 0x00, 0x00,
+	// 1875 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x1c, 0x49,
+	0x15, 0x4f, 0xcf, 0x87, 0xed, 0x79, 0x1e, 0x3b, 0x76, 0x39, 0xd9, 0x0c, 0x21, 0x8c, 0xbd, 0x0d,
+	0x5a, 0xf9, 0x40, 0x7a, 0xd6, 0x26, 0xb0, 0x91, 0x58, 0x90, 0xdc, 0xb1, 0xd7, 0x19, 0xd6, 0x71,
+	0x5a, 0x65, 0xe7, 0x82, 0x90, 0xa0, 0xdd, 0x53, 0x9e, 0xe9, 0xf5, 0x4c, 0x57, 0x6f, 0x55, 0x8d,
+	0x37, 0x0e, 0x12, 0x1f, 0xe2, 0xb4, 0x5c, 0xf8, 0xba, 0xf0, 0x0f, 0xac, 0xe0, 0x6f, 0xe0, 0xc6,
+	0x2d, 0xc7, 0x3d, 0xee, 0x85, 0x11, 0x99, 0x08, 0xae, 0x1c, 0x40, 0x08, 0xf9, 0x84, 0xaa, 0xba,
+	0xfa, 0x6b, 0xc6, 0xde, 0x04, 0x8d, 0x6d, 0x21, 0xc1, 0xc9, 0xee, 0x57, 0xaf, 0xde, 0xef, 0xf7,
+	0x5e, 0xbd, 0x7a, 0xaf, 0xaa, 0x06, 0x76, 0xda, 0xbe, 0xe8, 0xf4, 0x0f, 0x2c, 0x8f, 0xf6, 0x1a,
+	0xc7, 0xbd, 0x8f, 0x5c, 0x46, 0xee, 0x0a, 0x37, 0x78, 0xd6, 0x6f, 0xb8, 0x81, 0x60, 0xc4, 0x6d,
+	0x84, 0x47, 0xed, 0x86, 0x1b, 0xfa, 0xbc, 0xe1, 0xd1, 0x40, 0x30, 0xda, 0x0d, 0xbb, 0x6e, 0x40,
+	0x1a, 0xc7, 0x6b, 0x07, 0x44, 0xb8, 0xeb, 0x8d, 0x36, 0x09, 0x08, 0x73, 0x05, 0x69, 0x59, 0x21,
+	0xa3, 0x82, 0xa2, 0x77, 0x53, 0x6b, 0x56, 0x64, 0xed, 0xfb, 0xca, 0x9a, 0x15, 0x59, 0xb3, 0xc2,
+	0xa3, 0xb6, 0x25, 0xad, 0x59, 0x59, 0x6b, 0x96, 0xb6, 0x76, 0xfb, 0x6e, 0x86, 0x4b, 0x9b, 0xb6,
+	0x69, 0x43, 0x19, 0x3d, 0xe8, 0x1f, 0xaa, 0x2f, 0xf5, 0xa1, 0xfe, 0x8b, 0xc0, 0x6e, 0xbf, 0xf7,
+	0xba, 0xd4, 0xb9, 0x70, 0x05, 0x6f, 0x1c, 0xaf, 0xb9, 0xdd, 0xb0, 0xe3, 0xae, 0x8d, 0x92, 0xbe,
+	0x7d, 0xef, 0xe8, 0x3e, 0xb7, 0x7c, 0x2a, 0x75, 0x7b, 0xae, 0xd7, 0xf1, 0x03, 0xc2, 0x4e, 0xd2,
+	0xc9, 0x3d, 0x22, 0xdc, 0xc6, 0xf1, 0xf8, 0xac, 0xc6, 0x79, 0xb3, 0x58, 0x3f, 0x10, 0x7e, 0x8f,
+	0x8c, 0x4d, 0xf8, 0xc6, 0xab, 0x26, 0x70, 0xaf, 0x43, 0x7a, 0xee, 0xd8, 0xbc, 0xaf, 0x9d, 0x37,
+	0xaf, 0x2f, 0xfc, 0x6e, 0xc3, 0x0f, 0x04, 0x17, 0x6c, 0x74, 0x92, 0xf9, 0x4f, 0x03, 0xaa, 0x1b,
+	0xad, 0x16, 0x23, 0x9c, 0x6f, 0x33, 0xda, 0x0f, 0xd1, 0x0f, 0x60, 0x46, 0x7a, 0xd2, 0x72, 0x85,
+	0x5b, 0x33, 0x56, 0x8c, 0xd5, 0xd9, 0xf5, 0xb7, 0xad, 0xc8, 0xb0, 0x95, 0x35, 0x9c, 0xae, 0x90,
+	0xd4, 0xb6, 0x8e, 0xd7, 0xac, 0xc7, 0x07, 0x1f, 0x10, 0x4f, 0x3c, 0x22, 0xc2, 0xb5, 0xd1, 0xf3,
+	0xc1, 0xf2, 0xb5, 0xe1, 0x60, 0x19, 0x52, 0x19, 0x4e, 0xac, 0xa2, 0x9f, 0x19, 0x50, 0x6d, 0x4b,
+	0xac, 0x47, 0xa4, 0x77, 0x40, 0x18, 0xaf, 0x15, 0x56, 0x8a, 0xab, 0xb3, 0xeb, 0x4d, 0x6b, 0x92,
+	0x9c, 0xb0, 0xb6, 0x53, 0x8b, 0xf6, 0x0d, 0x8d, 0x5f, 0xcd, 0x08, 0x39, 0xce, 0x81, 0x9a, 0x2f,
+	0x0c, 0x58, 0xc8, 0x3a, 0xbe, 0xe3, 0x73, 0x81, 0xbe, 0x37, 0xe6, 0xbc, 0xf5, 0x7a, 0xce, 0xcb,
+	0xd9, 0xca, 0xf5, 0x05, 0x0d, 0x3d, 0x13, 0x4b, 0x32, 0x8e, 0x53, 0x28, 0xfb, 0x82, 0xf4, 0x62,
+	0x87, 0xbf, 0x33, 0x99, 0xc3, 0x59, 0xf2, 0xf6, 0x9c, 0x86, 0x2d, 0x37, 0x25, 0x00, 0x8e, 0x70,
+	0xcc, 0x4f, 0x8a, 0xb0, 0x98, 0x55, 0x73, 0x5c, 0xe1, 0x75, 0xae, 0x60, 0x85, 0x7f, 0x6d, 0xc0,
+	0xa2, 0xdb, 0x6a, 0x91, 0xd6, 0xf6, 0xa5, 0x2e, 0xf3, 0x17, 0x34, 0x89, 0xc5, 0x8d, 0x51, 0x2c,
+	0x3c, 0x0e, 0x8f, 0x7e, 0x6b, 0xc0, 0x12, 0x23, 0x3d, 0x7a, 0x3c, 0x42, 0xab, 0x78, 0xd1, 0xb4,
+	0xbe, 0xa8, 0x69, 0x2d, 0xe1, 0x71, 0x34, 0x7c, 0x16, 0x05, 0xf3, 0x5f, 0x06, 0xcc, 0x6f, 0x84,
+	0x61, 0xd7, 0x27, 0xad, 0x7d, 0xfa, 0xbf, 0xb5, 0x0d, 0xff, 0x62, 0x00, 0xca, 0xbb, 0x7e, 0x05,
+	0x1b, 0xf1, 0xc3, 0xfc, 0x46, 0xdc, 0x99, 0x70, 0x23, 0xe6, 0xe8, 0x9f, 0xb3, 0x15, 0x7f, 0x57,
+	0x84, 0xa5, 0xbc, 0xe2, 0xff, 0x37, 0xe3, 0x7f, 0xe7, 0x66, 0xec, 0xc2, 0xad, 0xad, 0xa7, 0x82,
+	0xb0, 0xc0, 0xed, 0x6e, 0x05, 0xc2, 0x17, 0x27, 0x98, 0x1c, 0x12, 0x46, 0x02, 0x8f, 0xa0, 0x15,
+	0x28, 0x05, 0x6e, 0x8f, 0xa8, 0x85, 0xaa, 0xd8, 0x55, 0x6d, 0xba, 0xb4, 0xeb, 0xf6, 0x08, 0x56,
+	0x23, 0xa8, 0x01, 0x15, 0xf9, 0x97, 0x87, 0xae, 0x47, 0x6a, 0x05, 0xa5, 0xb6, 0xa8, 0xd5, 0x2a,
+	0xbb, 0xf1, 0x00, 0x4e, 0x75, 0xcc, 0x8f, 0x4b, 0x30, 0x9b, 0x81, 0x47, 0x04, 0x8a, 0x21, 0x6d,
+	0xe9, 0x54, 0x98, 0xb0, 0x43, 0x38, 0xb4, 0x95, 0x70, 0xb7, 0xa7, 0x87, 0x83, 0xe5, 0xa2, 0x94,
+	0x48, 0xfb, 0xe8, 0x57, 0x06, 0xcc, 0x93, 0x9c, 0x97, 0x8a, 0xed, 0xec, 0xfa, 0x93, 0xc9, 0x20,
+	0xcf, 0x89, 0x9c, 0x8d, 0x86, 0x83, 0xe5, 0xf9, 0x91, 0xc1, 0x11, 0x02, 0xe8, 0x2d, 0x28, 0xfa,
+	0x61, 0x94, 0x02, 0x55, 0xfb, 0x86, 0xa4, 0xdb, 0x74, 0xf8, 0xe9, 0x60, 0xb9, 0xd2, 0x74, 0x74,
+	0x13, 0xc3, 0x52, 0x01, 0x75, 0xa1, 0x1c, 0x52, 0x26, 0x78, 0xad, 0xa4, 0x92, 0x65, 0x7b, 0x32,
+	0xc6, 0x72, 0x55, 0x5a, 0x0e, 0x65, 0x22, 0xdd, 0xb8, 0xf2, 0x8b, 0xe3, 0x08, 0x04, 0xf9, 0x50,
+	0x0a, 0x68, 0x8b, 0xd4, 0xca, 0x2a, 0x3c, 0xef, 0x4f, 0x08, 0x46, 0x5b, 0x24, 0x0d, 0xca, 0x8c,
+	0x4a, 0x1e, 0x29, 0x52, 0x10, 0xe6, 0x33, 0xa8, 0x3e, 0xdc, 0xdf, 0x77, 0x1c, 0x46, 0x05, 0xf5,
+	0x68, 0x17, 0xbd, 0x05, 0x53, 0x3d, 0x22, 0x3a, 0x3a, 0x1d, 0x2a, 0xf6, 0xbc, 0x26, 0x38, 0xf5,
+	0x48, 0x49, 0xb1, 0x1e, 0x95, 0x69, 0xd9, 0xa1, 0x5c, 0xd4, 0x0a, 0xf9, 0xb4, 0x7c, 0x48, 0xb9,
+	0xc0, 0x6a, 0x44, 0x6a, 0x84, 0xae, 0xe8, 0xd4, 0x8a, 0x79, 0x0d, 0xc7, 0x15, 0x1d, 0xac, 0x46,
+	0xcc, 0x3f, 0x19, 0x30, 0xdd, 0x74, 0xec, 0x2e, 0xf5, 0x8e, 0x10, 0x81, 0x92, 0xe7, 0xb7, 0x98,
+	0x4e, 0xc2, 0x07, 0x93, 0xb9, 0xdc, 0x74, 0x76, 0x89, 0x48, 0x21, 0x1f, 0x34, 0x37, 0x31, 0x56,
+	0xe6, 0xd1, 0x11, 0x4c, 0x91, 0xa7, 0x1e, 0x09, 0x85, 0x2e, 0x46, 0x17, 0x02, 0x94, 0xc4, 0x68,
+	0x4b, 0x99, 0xc6, 0x1a, 0xc2, 0x3c, 0x84, 0xb2, 0x52, 0x40, 0x5f, 0x86, 0x82, 0x1f, 0x2a, 0xd7,
+	0xaa, 0xf6, 0xd2, 0x70, 0xb0, 0x5c, 0x68, 0x3a, 0xf9, 0x1c, 0x2b, 0xf8, 0x21, 0xba, 0x0f, 0xd5,
+	0x90, 0x91, 0x43, 0xff, 0xe9, 0x0e, 0x09, 0xda, 0xa2, 0xa3, 0x22, 0x5b, 0x4e, 0xfb, 0x99, 0x93,
+	0x19, 0xc3, 0x39, 0x4d, 0xf3, 0x18, 0x60, 0xe7, 0x9d, 0x64, 0x05, 0x3b, 0x50, 0xea, 0x08, 0x11,
+	0x5e, 0xcc, 0x76, 0xce, 0xe6, 0x46, 0x94, 0x3b, 0x52, 0x82, 0x15, 0x82, 0xf9, 0xb1, 0x01, 0x95,
+	0x24, 0x95, 0xd5, 0x7a, 0x53, 0x26, 0x14, 0x6e, 0x39, 0xb3, 0xde, 0x94, 0x09, 0x5c, 0x0a, 0xb5,
+	0x86, 0x2a, 0x65, 0x85, 0x73, 0x4b, 0xd9, 0x7d, 0x98, 0x09, 0x35, 0x9a, 0xce, 0x9b, 0x3b, 0x71,
+	0x4b, 0x8d, 0x59, 0x9c, 0x66, 0xfe, 0xc7, 0x89, 0xb6, 0xf9, 0xf3, 0x12, 0xcc, 0xed, 0x12, 0xf1,
+	0x11, 0x65, 0x47, 0x0e, 0xed, 0xfa, 0xde, 0xc9, 0x15, 0x74, 0x39, 0x01, 0x65, 0xd6, 0xef, 0x92,
+	0xb8, 0xb1, 0x3d, 0x9e, 0x70, 0x9f, 0x66, 0xd9, 0xe3, 0x7e, 0x97, 0xa4, 0xc5, 0x41, 0x7e, 0x71,
+	0x1c, 0x81, 0xa1, 0x6f, 0xc1, 0x75, 0x37, 0xd7, 0xd4, 0xa3, 0xf2, 0x55, 0x51, 0x99, 0x75, 0x3d,
+	0xdf, 0xef, 0x39, 0x1e, 0xd5, 0x45, 0xab, 0x32, 0xc4, 0x3e, 0x65, 0xb2, 0xfc, 0x96, 0x56, 0x8c,
+	0x55, 0xc3, 0xae, 0x46, 0xe1, 0x8d, 0x64, 0x38, 0x19, 0x45, 0xf7, 0xa0, 0x2a, 0x7c, 0xc2, 0xe2,
+	0x11, 0x55, 0x8d, 0xca, 0xf6, 0x82, 0x4c, 0xc6, 0xfd, 0x8c, 0x1c, 0xe7, 0xb4, 0xd0, 0x4f, 0x0d,
+	0xa8, 0x70, 0xda, 0x67, 0x9e, 0x2c, 0x3a, 0xb5, 0x29, 0x15, 0xf8, 0xfd, 0x8b, 0x8c, 0x4c, 0x52,
+	0xca, 0xe6, 0x64, 0x83, 0xdb, 0x8b, 0xa1, 0x70, 0x8a, 0x6a, 0xbe, 0x34, 0x60, 0x31, 0x37, 0xe9,
+	0x0a, 0xce, 0x77, 0x61, 0xfe, 0x7c, 0xf7, 0xfe, 0x05, 0xba, 0x7c, 0xce, 0xf1, 0xee, 0x87, 0x70,
+	0x2b, 0xa7, 0x26, 0xab, 0xfa, 0x9e, 0x70, 0x45, 0x9f, 0xa3, 0xaf, 0xc2, 0x8c, 0xac, 0xee, 0xbb,
+	0xe9, 0xc1, 0x21, 0xa1, 0xbe, 0xab, 0xe5, 0x38, 0xd1, 0x40, 0xeb, 0x00, 0xfa, 0x8a, 0xee, 0xd3,
+	0x40, 0xed, 0xce, 0x62, 0x9a, 0xf9, 0xdb, 0xc9, 0x08, 0xce, 0x68, 0x99, 0x7f, 0x1c, 0x0d, 0xb1,
+	0x43, 0x08, 0x43, 0xef, 0xc0, 0x9c, 0x9b, 0xb9, 0xfb, 0xf1, 0x9a, 0xa1, 0x32, 0x73, 0x71, 0x38,
+	0x58, 0x9e, 0xcb, 0x5e, 0x0a, 0x39, 0xce, 0xeb, 0x21, 0x0e, 0x33, 0x7e, 0xa8, 0x3a, 0x41, 0x1c,
+	0xc0, 0xad, 0x49, 0x2b, 0xb3, 0xb2, 0x96, 0xfa, 0xad, 0x05, 0x1c, 0x27, 0x40, 0xe6, 0x5f, 0x0d,
+	0x78, 0xe3, 0xec, 0xdc, 0x42, 0x5f, 0x87, 0x92, 0x38, 0x09, 0xe3, 0xe0, 0xbd, 0x19, 0x97, 0xaa,
+	0xfd, 0x93, 0x90, 0x9c, 0x0e, 0x96, 0xf3, 0x9e, 0x4b, 0x21, 0x56, 0xea, 0xff, 0xf1, 0x51, 0x2c,
+	0x29, 0x89, 0xc5, 0x73, 0x4b, 0xa2, 0x0d, 0xc5, 0xbe, 0xdf, 0x52, 0x5b, 0xb5, 0x62, 0xbf, 0xad,
+	0x15, 0x8a, 0x4f, 0x9a, 0x9b, 0xa7, 0x83, 0xe5, 0x37, 0xcf, 0x7b, 0x7d, 0x91, 0x64, 0xb8, 0xf5,
+	0xa4, 0xb9, 0x89, 0xe5, 0x64, 0xf3, 0x1f, 0x53, 0x23, 0x8b, 0x25, 0x0b, 0x0a, 0x7a, 0x17, 0x2a,
+	0x2d, 0x9f, 0x11, 0x4f, 0xad, 0x7a, 0xe4, 0x68, 0x3d, 0x26, 0xbb, 0x19, 0x0f, 0x9c, 0x66, 0x3f,
+	0x70, 0x3a, 0x01, 0x7d, 0x08, 0xa5, 0x43, 0x46, 0x7b, 0xfa, 0x08, 0x77, 0x91, 0xb5, 0x4f, 0x66,
+	0x52, 0x1a, 0x8a, 0xf7, 0x18, 0xed, 0x61, 0x05, 0x85, 0x8e, 0xa0, 0x20, 0x68, 0xad, 0x78, 0x39,
+	0x80, 0xa0, 0x01, 0x0b, 0xfb, 0x14, 0x17, 0x04, 0x95, 0x19, 0xc9, 0x09, 0x3b, 0xf6, 0x3d, 0x12,
+	0x1f, 0xfa, 0x26, 0xcc, 0xc8, 0xbd, 0xc8, 0x5a, 0x9a, 0x91, 0x5a, 0xc0, 0x71, 0x02, 0x24, 0xf7,
+	0x6d, 0x38, 0x52, 0x6e, 0xd3, 0xfe, 0x37, 0x56, 0xa0, 0x3f, 0x80, 0x29, 0x37, 0x5a, 0xbd, 0x29,
+	0xb5, 0x7a, 0x58, 0x9e, 0x41, 0x36, 0xe2, 0x65, 0xdb, 0x7c, 0xed, 0x17, 0x48, 0xe2, 0xf5, 0xa5,
+	0xbd, 0xe4, 0x11, 0xd2, 0x92, 0xe9, 0x11, 0xd9, 0xc1, 0x1a, 0x01, 0x7d, 0x13, 0xe6, 0x48, 0xe0,
+	0x1e, 0x74, 0xc9, 0x0e, 0x6d, 0xb7, 0xfd, 0xa0, 0x5d, 0x9b, 0x5e, 0x31, 0x56, 0x67, 0xec, 0x9b,
+	0x9a, 0xde, 0xdc, 0x56, 0x76, 0x10, 0xe7, 0x75, 0x91, 0x80, 0x0a, 0x73, 0x05, 0xd9, 0xf1, 0x7b,
+	0xbe, 0xa8, 0xcd, 0xac, 0x18, 0x93, 0x9f, 0xa0, 0x71, 0x6c, 0x2e, 0xea, 0x02, 0xc9, 0x27, 0x4e,
+	0x81, 0xd0, 0x8f, 0x61, 0xb6, 0x9b, 0x1c, 0x8b, 0x78, 0xad, 0xa2, 0x16, 0xf1, 0xe1, 0x64, 0xb8,
+	0xe9, 0x39, 0xcb, 0x5e, 0xd2, 0xae, 0xcf, 0xa6, 0x32, 0x8e, 0xb3, 0x88, 0xe6, 0xef, 0x0b, 0x80,
+	0x72, 0x89, 0x26, 0xab, 0x33, 0x97, 0xf7, 0xa0, 0xb9, 0x20, 0x2b, 0xae, 0x19, 0x97, 0xd8, 0x25,
+	0x93, 0x15, 0xca, 0x8f, 0xe7, 0x19, 0xa0, 0x1f, 0x41, 0x55, 0x30, 0xf7, 0xf0, 0xd0, 0xf7, 0x14,
+	0x47, 0xbd, 0xab, 0x37, 0x5f, 0x9b, 0x91, 0x7a, 0xc5, 0xb6, 0x92, 0x04, 0xda, 0xcf, 0xd8, 0x4a,
+	0x8f, 0xb0, 0x59, 0x29, 0xce, 0xe1, 0x99, 0x7f, 0x37, 0x60, 0x69, 0x2c, 0x54, 0x7d, 0x7e, 0x05,
+	0x87, 0xb8, 0x67, 0x50, 0x96, 0x8d, 0x30, 0x6e, 0x3b, 0x4f, 0x2e, 0x70, 0x11, 0xd2, 0x86, 0x9c,
+	0x76, 0x70, 0x29, 0xe3, 0x38, 0x82, 0x34, 0xd7, 0x60, 0x2e, 0x77, 0x3b, 0x7b, 0xf5, 0x65, 0xdf,
+	0xfc, 0x5b, 0x09, 0x16, 0x62, 0xbb, 0x7c, 0xaf, 0xdf, 0xeb, 0xb9, 0xec, 0x2a, 0x8e, 0xba, 0xbf,
+	0x31, 0xe0, 0x7a, 0x36, 0x63, 0xfc, 0x24, 0x60, 0xce, 0x05, 0x06, 0x2c, 0xca, 0x97, 0x5b, 0x9a,
+	0xc9, 0xf5, 0xdd, 0x3c, 0x20, 0x1e, 0x65, 0x80, 0xfe, 0x60, 0xc0, 0x9d, 0x08, 0xe5, 0x41, 0xb7,
+	0xcf, 0x05, 0x61, 0x23, 0x33, 0x6a, 0xc5, 0x4b, 0xa2, 0xf8, 0x15, 0x4d, 0xf1, 0xce, 0xc6, 0xe7,
+	0xa0, 0xe3, 0xcf, 0xe5, 0x86, 0x3e, 0x31, 0xe0, 0x66, 0xa4, 0x30, 0xca, 0xba, 0x74, 0x49, 0xac,
+	0xbf, 0xa4, 0x59, 0xdf, 0xdc, 0x38, 0x0b, 0x16, 0x9f, 0xcd, 0xc6, 0x74, 0xa1, 0x9a, 0x7d, 0xd4,
+	0xb9, 0x8c, 0x07, 0xa9, 0x97, 0x06, 0xa4, 0x25, 0x1c, 0x39, 0x70, 0xc3, 0xa3, 0x41, 0x10, 0x9d,
+	0x33, 0xb8, 0x43, 0xd8, 0x1e, 0xf1, 0x68, 0xd0, 0xd2, 0x17, 0xcb, 0xf8, 0x42, 0x78, 0xe3, 0xc1,
+	0x19, 0x3a, 0xf8, 0xcc, 0x99, 0x68, 0x13, 0x16, 0x42, 0xd7, 0x3b, 0x22, 0x22, 0x63, 0x2d, 0xba,
+	0x5e, 0xd7, 0xb4, 0xb5, 0x05, 0x67, 0x64, 0x1c, 0x8f, 0xcd, 0x40, 0xdf, 0x86, 0xf9, 0x83, 0x13,
+	0x41, 0x32, 0x36, 0x8a, 0xca, 0xc6, 0x1b, 0xda, 0xc6, 0xbc, 0x9d, 0x1b, 0xc5, 0x23, 0xda, 0xe6,
+	0x2f, 0x0c, 0x98, 0xd6, 0x3d, 0x1f, 0xdd, 0xcb, 0x5c, 0x74, 0xa3, 0x40, 0xd6, 0x5e, 0x7d, 0xc9,
+	0x45, 0xbb, 0xfa, 0x8a, 0x5d, 0x78, 0xc5, 0x1e, 0x97, 0x3f, 0xbe, 0x59, 0xd1, 0x8f, 0x6f, 0x56,
+	0x33, 0x10, 0x8f, 0xd9, 0x9e, 0x60, 0x7e, 0xd0, 0xb6, 0x67, 0xf2, 0x17, 0x72, 0xfb, 0xee, 0xf3,
+	0x17, 0xf5, 0x6b, 0x9f, 0xbe, 0xa8, 0x5f, 0xfb, 0xec, 0x45, 0xfd, 0xda, 0x4f, 0x86, 0x75, 0xe3,
+	0xf9, 0xb0, 0x6e, 0x7c, 0x3a, 0xac, 0x1b, 0x9f, 0x0d, 0xeb, 0xc6, 0x9f, 0x87, 0x75, 0xe3, 0x97,
+	0x2f, 0xeb, 0xd7, 0xbe, 0x3b, 0xad, 0x53, 0xea, 0xdf, 0x03, 0x00, 0x4d, 0x34, 0x6f, 0x4c, 0x8f,
+	0x1d, 0x00, 0x00,
 }
 
 func (m *AddressGroup) Marshal() (dAtA []byte, err error) {
@@ -1160,6 +1291,18 @@ func (m *GroupMember) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Node != nil {
+		{
+			size, err := m.Node.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
 	if len(m.Ports) > 0 {
 		for iNdEx := len(m.Ports) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -1210,6 +1353,44 @@ func (m *GroupMember) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *HTTPProtocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HTTPProtocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HTTPProtocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i -= len(m.Path)
+	copy(dAtA[i:], m.Path)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Path)))
+	i--
+	dAtA[i] = 0x1a
+	i -= len(m.Host)
+	copy(dAtA[i:], m.Host)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Host)))
+	i--
+	dAtA[i] = 0x12
+	i -= len(m.Method)
+	copy(dAtA[i:], m.Method)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Method)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *IPBlock) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1290,6 +1471,41 @@ func (m *IPNet) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *L7Protocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *L7Protocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *L7Protocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		{
+			size, err := m.HTTP.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NamedPort) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1592,6 +1808,32 @@ func (m *NetworkPolicyRule) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.L7Protocols) > 0 {
+		for iNdEx := len(m.L7Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.L7Protocols[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGenerated(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
+	if m.RateLimit != nil {
+		{
+			size, err := m.RateLimit.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
 	i--
 	if m.EnableLogging {
 		dAtA[i] = 1
@@ -1742,6 +1984,34 @@ func (m *NetworkPolicyStatus) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *NodeReference) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NodeReference) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NodeReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i -= len(m.Name)
+	copy(dAtA[i:], m.Name)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Name)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *NodeStatsSummary) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1850,6 +2120,38 @@ func (m *PodReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RateLimit) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RateLimit) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RateLimit) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i = encodeVarintGenerated(dAtA, i, uint64(m.BytesPerSecond))
+	i--
+	dAtA[i] = 0x18
+	i = encodeVarintGenerated(dAtA, i, uint64(m.PacketsPerSecond))
+	i--
+	dAtA[i] = 0x10
+	i = encodeVarintGenerated(dAtA, i, uint64(m.ConnectionsPerSecond))
+	i--
+	dAtA[i] = 0x8
+	return len(dAtA) - i, nil
+}
+
 func (m *Service) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -2056,6 +2358,25 @@ func (m *GroupMember) Size() (n int) {
 			n += 1 + l + sovGenerated(uint64(l))
 		}
 	}
+	if m.Node != nil {
+		l = m.Node.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
+func (m *HTTPProtocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Method)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Host)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Path)
+	n += 1 + l + sovGenerated(uint64(l))
 	return n
 }
 
@@ -2090,6 +2411,19 @@ func (m *IPNet) Size() (n int) {
 	return n
 }
 
+func (m *L7Protocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		l = m.HTTP.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
 func (m *NamedPort) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2228,6 +2562,16 @@ func (m *NetworkPolicyRule) Size() (n int) {
 		n += 1 + l + sovGenerated(uint64(l))
 	}
 	n += 2
+	if m.RateLimit != nil {
+		l = m.RateLimit.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	if len(m.L7Protocols) > 0 {
+		for _, e := range m.L7Protocols {
+			l = e.Size()
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2261,7 +2605,18 @@ func (m *NetworkPolicyStatus) Size() (n int) {
 	return n
 }
 
-func (m *NodeStatsSummary) Size() (n int) {
+func (m *NodeReference) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
+func (m *NodeStatsSummary) Size() (n int) {
 	if m == nil {
 		return 0
 	}
@@ -2303,6 +2658,18 @@ func (m *PodReference) Size() (n int) {
 	return n
 }
 
+func (m *RateLimit) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 1 + sovGenerated(uint64(m.ConnectionsPerSecond))
+	n += 1 + sovGenerated(uint64(m.PacketsPerSecond))
+	n += 1 + sovGenerated(uint64(m.BytesPerSecond))
+	return n
+}
+
 func (m *Service) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2459,6 +2826,19 @@ func (this *GroupMember) String() string {
 		`ExternalEntity:` + strings.Replace(this.ExternalEntity.String(), "ExternalEntityReference", "ExternalEntityReference", 1) + `,`,
 		`IPs:` + fmt.Sprintf("%v", this.IPs) + `,`,
 		`Ports:` + repeatedStringForPorts + `,`,
+		`Node:` + strings.Replace(this.Node.String(), "NodeReference", "NodeReference", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HTTPProtocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HTTPProtocol{`,
+		`Method:` + fmt.Sprintf("%v", this.Method) + `,`,
+		`Host:` + fmt.Sprintf("%v", this.Host) + `,`,
+		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2490,6 +2870,16 @@ func (this *IPNet) String() string {
 	}, "")
 	return s
 }
+func (this *L7Protocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&L7Protocol{`,
+		`HTTP:` + strings.Replace(this.HTTP.String(), "HTTPProtocol", "HTTPProtocol", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *NamedPort) String() string {
 	if this == nil {
 		return "nil"
@@ -2587,6 +2977,11 @@ func (this *NetworkPolicyRule) String() string {
 		repeatedStringForServices += strings.Replace(strings.Replace(f.String(), "Service", "Service", 1), `&`, ``, 1) + ","
 	}
 	repeatedStringForServices += "}"
+	repeatedStringForL7Protocols := "[]L7Protocol{"
+	for _, f := range this.L7Protocols {
+		repeatedStringForL7Protocols += strings.Replace(strings.Replace(f.String(), "L7Protocol", "L7Protocol", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForL7Protocols += "}"
 	s := strings.Join([]string{`&NetworkPolicyRule{`,
 		`Direction:` + fmt.Sprintf("%v", this.Direction) + `,`,
 		`From:` + strings.Replace(strings.Replace(this.From.String(), "NetworkPolicyPeer", "NetworkPolicyPeer", 1), `&`, ``, 1) + `,`,
@@ -2595,6 +2990,8 @@ func (this *NetworkPolicyRule) String() string {
 		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
 		`Action:` + valueToStringGenerated(this.Action) + `,`,
 		`EnableLogging:` + fmt.Sprintf("%v", this.EnableLogging) + `,`,
+		`RateLimit:` + strings.Replace(this.RateLimit.String(), "RateLimit", "RateLimit", 1) + `,`,
+		`L7Protocols:` + repeatedStringForL7Protocols + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2626,6 +3023,16 @@ func (this *NetworkPolicyStatus) String() string {
 	}, "")
 	return s
 }
+func (this *NodeReference) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&NodeReference{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *NodeStatsSummary) String() string {
 	if this == nil {
 		return "nil"
@@ -2665,6 +3072,18 @@ func (this *PodReference) String() string {
 	}, "")
 	return s
 }
+func (this *RateLimit) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&RateLimit{`,
+		`ConnectionsPerSecond:` + fmt.Sprintf("%v", this.ConnectionsPerSecond) + `,`,
+		`PacketsPerSecond:` + fmt.Sprintf("%v", this.PacketsPerSecond) + `,`,
+		`BytesPerSecond:` + fmt.Sprintf("%v", this.BytesPerSecond) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *Service) String() string {
 	if this == nil {
 		return "nil"
@@ -3756,6 +4175,42 @@ func (m *GroupMember) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Node", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Node == nil {
+				m.Node = &NodeReference{}
+			}
+			if err := m.Node.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -3780,7 +4235,7 @@ func (m *GroupMember) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *IPBlock) Unmarshal(dAtA []byte) error {
+func (m *HTTPProtocol) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3803,17 +4258,17 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IPBlock: wiretype end group for non-group")
+			return fmt.Errorf("proto: HTTPProtocol: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IPBlock: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: HTTPProtocol: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CIDR", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Method", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -3823,30 +4278,29 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.CIDR.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Method = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Except", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -3856,25 +4310,55 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Except = append(m.Except, IPNet{})
-			if err := m.Except[len(m.Except)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Host = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3900,7 +4384,7 @@ func (m *IPBlock) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *IPNet) Unmarshal(dAtA []byte) error {
+func (m *IPBlock) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3923,17 +4407,17 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IPNet: wiretype end group for non-group")
+			return fmt.Errorf("proto: IPBlock: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IPNet: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: IPBlock: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field CIDR", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -3943,31 +4427,30 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IP = append(m.IP[:0], dAtA[iNdEx:postIndex]...)
-			if m.IP == nil {
-				m.IP = []byte{}
+			if err := m.CIDR.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PrefixLength", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Except", wireType)
 			}
-			m.PrefixLength = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -3977,11 +4460,26 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.PrefixLength |= int32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Except = append(m.Except, IPNet{})
+			if err := m.Except[len(m.Except)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -4006,7 +4504,7 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NamedPort) Unmarshal(dAtA []byte) error {
+func (m *IPNet) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4029,36 +4527,17 @@ func (m *NamedPort) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NamedPort: wiretype end group for non-group")
+			return fmt.Errorf("proto: IPNet: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NamedPort: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: IPNet: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
-			}
-			m.Port = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGenerated
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Port |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -4068,29 +4547,31 @@ func (m *NamedPort) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = string(dAtA[iNdEx:postIndex])
+			m.IP = append(m.IP[:0], dAtA[iNdEx:postIndex]...)
+			if m.IP == nil {
+				m.IP = []byte{}
+			}
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PrefixLength", wireType)
 			}
-			var stringLen uint64
+			m.PrefixLength = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -4100,24 +4581,11 @@ func (m *NamedPort) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.PrefixLength |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthGenerated
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthGenerated
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Protocol = Protocol(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -4142,7 +4610,7 @@ func (m *NamedPort) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NetworkPolicy) Unmarshal(dAtA []byte) error {
+func (m *L7Protocol) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4165,15 +4633,15 @@ func (m *NetworkPolicy) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NetworkPolicy: wiretype end group for non-group")
+			return fmt.Errorf("proto: L7Protocol: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NetworkPolicy: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: L7Protocol: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ObjectMeta", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTP", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4200,13 +4668,238 @@ func (m *NetworkPolicy) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ObjectMeta.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.HTTP == nil {
+				m.HTTP = &HTTPProtocol{}
+			}
+			if err := m.HTTP.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Rules", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NamedPort) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NamedPort: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NamedPort: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+			}
+			m.Port = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Port |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocol = Protocol(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NetworkPolicy) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NetworkPolicy: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NetworkPolicy: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectMeta", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ObjectMeta.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rules", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5119,6 +5812,76 @@ func (m *NetworkPolicyRule) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.EnableLogging = bool(v != 0)
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RateLimit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RateLimit == nil {
+				m.RateLimit = &RateLimit{}
+			}
+			if err := m.RateLimit.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field L7Protocols", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.L7Protocols = append(m.L7Protocols, L7Protocol{})
+			if err := m.L7Protocols[len(m.L7Protocols)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -5382,6 +6145,91 @@ func (m *NetworkPolicyStatus) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *NodeReference) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NodeReference: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NodeReference: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *NodeStatsSummary) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -5687,6 +6535,116 @@ func (m *PodReference) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RateLimit) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RateLimit: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RateLimit: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectionsPerSecond", wireType)
+			}
+			m.ConnectionsPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ConnectionsPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PacketsPerSecond", wireType)
+			}
+			m.PacketsPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PacketsPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BytesPerSecond", wireType)
+			}
+			m.BytesPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BytesPerSecond |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *Service) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
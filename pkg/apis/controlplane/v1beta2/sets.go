@@ -27,7 +27,7 @@ type groupMemberKey string
 type GroupMemberSet map[groupMemberKey]*GroupMember
 
 // normalizeGroupMember calculates the groupMemberKey of the provided
-// GroupMember based on the Pod's namespaced name or IP.
+// GroupMember based on the Pod's namespaced name, the Node's name or IP.
 func normalizeGroupMember(member *GroupMember) groupMemberKey {
 	// "/" is illegal in Namespace and name so is safe as the delimiter.
 	const delimiter = "/"
@@ -40,6 +40,11 @@ func normalizeGroupMember(member *GroupMember) groupMemberKey {
 		b.WriteString(member.ExternalEntity.Namespace)
 		b.WriteString(delimiter)
 		b.WriteString(member.ExternalEntity.Name)
+	} else if member.Node != nil {
+		// Nodes are cluster scoped, the empty Namespace distinguishes them from
+		// Pods and ExternalEntities.
+		b.WriteString(delimiter)
+		b.WriteString(member.Node.Name)
 	} else if len(member.IPs) != 0 {
 		for _, ip := range member.IPs {
 			b.Write(ip)
@@ -61,6 +61,12 @@ type ExternalEntityReference struct {
 	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
 }
 
+// NodeReference represents a Node Reference.
+type NodeReference struct {
+	// The name of this Node.
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+}
+
 // GroupMember represents resource member to be populated in Groups.
 // This supersedes GroupMemberPod, and will eventually replace it.
 type GroupMember struct {
@@ -72,6 +78,9 @@ type GroupMember struct {
 	IPs []IPAddress `json:"ips,omitempty" protobuf:"bytes,3,rep,name=ips"`
 	// Ports is the list NamedPort of the GroupMember.
 	Ports []NamedPort `json:"ports,omitempty" protobuf:"bytes,4,rep,name=ports"`
+	// Node maintains the reference to the Node. It's only set in AppliedToGroups
+	// selecting Nodes, whose NetworkPolicies are enforced on the Node's host traffic.
+	Node *NodeReference `json:"node,omitempty" protobuf:"bytes,5,opt,name=node"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -198,6 +207,42 @@ type NetworkPolicyRule struct {
 	Action *secv1alpha1.RuleAction `json:"action,omitempty" protobuf:"bytes,6,opt,name=action,casttype=github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1.RuleAction"`
 	// EnableLogging indicates whether or not to generate logs when rules are matched. Default to false.
 	EnableLogging bool `json:"enableLogging" protobuf:"varint,7,opt,name=enableLogging"`
+	// RateLimit limits the rate of the traffic matched by the rule. nil means
+	// the traffic is not limited.
+	RateLimit *RateLimit `json:"rateLimit,omitempty" protobuf:"bytes,8,opt,name=rateLimit"`
+	// L7Protocols restricts the traffic allowed by the rule to the application
+	// layer requests matching any of these protocols. Empty means the traffic
+	// is not restricted.
+	L7Protocols []L7Protocol `json:"l7Protocols,omitempty" protobuf:"bytes,9,rep,name=l7Protocols"`
+}
+
+// L7Protocol describes an application layer protocol match.
+type L7Protocol struct {
+	// HTTP matches HTTP requests.
+	HTTP *HTTPProtocol `json:"http,omitempty" protobuf:"bytes,1,opt,name=http"`
+}
+
+// HTTPProtocol matches HTTP requests. An empty field matches any value.
+type HTTPProtocol struct {
+	// Method is the method of the request.
+	Method string `json:"method,omitempty" protobuf:"bytes,1,opt,name=method"`
+	// Host is the host of the request. It matches any subdomain of a domain
+	// if it starts with "*.".
+	Host string `json:"host,omitempty" protobuf:"bytes,2,opt,name=host"`
+	// Path is the path of the request. It matches any path starting with a
+	// prefix if it ends with "*".
+	Path string `json:"path,omitempty" protobuf:"bytes,3,opt,name=path"`
+}
+
+// RateLimit describes the limits on the rate of the traffic matched by a rule.
+// A zero value means the corresponding rate is not limited.
+type RateLimit struct {
+	// ConnectionsPerSecond is the maximum rate of new connections.
+	ConnectionsPerSecond int32 `json:"connectionsPerSecond,omitempty" protobuf:"varint,1,opt,name=connectionsPerSecond"`
+	// PacketsPerSecond is the maximum rate of packets of the connections.
+	PacketsPerSecond int32 `json:"packetsPerSecond,omitempty" protobuf:"varint,2,opt,name=packetsPerSecond"`
+	// BytesPerSecond is the maximum rate of bytes of the connections.
+	BytesPerSecond int32 `json:"bytesPerSecond,omitempty" protobuf:"varint,3,opt,name=bytesPerSecond"`
 }
 
 // Protocol defines network protocols supported for things like container ports.
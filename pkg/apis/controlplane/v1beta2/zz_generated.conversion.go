@@ -116,6 +116,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*HTTPProtocol)(nil), (*controlplane.HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(a.(*HTTPProtocol), b.(*controlplane.HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.HTTPProtocol)(nil), (*HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(a.(*controlplane.HTTPProtocol), b.(*HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*IPBlock)(nil), (*controlplane.IPBlock)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_IPBlock_To_controlplane_IPBlock(a.(*IPBlock), b.(*controlplane.IPBlock), scope)
 	}); err != nil {
@@ -136,6 +146,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*L7Protocol)(nil), (*controlplane.L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol(a.(*L7Protocol), b.(*controlplane.L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.L7Protocol)(nil), (*L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol(a.(*controlplane.L7Protocol), b.(*L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NamedPort)(nil), (*controlplane.NamedPort)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_NamedPort_To_controlplane_NamedPort(a.(*NamedPort), b.(*controlplane.NamedPort), scope)
 	}); err != nil {
@@ -226,6 +246,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeReference)(nil), (*controlplane.NodeReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_NodeReference_To_controlplane_NodeReference(a.(*NodeReference), b.(*controlplane.NodeReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.NodeReference)(nil), (*NodeReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_NodeReference_To_v1beta2_NodeReference(a.(*controlplane.NodeReference), b.(*NodeReference), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NodeStatsSummary)(nil), (*controlplane.NodeStatsSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_NodeStatsSummary_To_controlplane_NodeStatsSummary(a.(*NodeStatsSummary), b.(*controlplane.NodeStatsSummary), scope)
 	}); err != nil {
@@ -246,6 +276,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RateLimit)(nil), (*controlplane.RateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_RateLimit_To_controlplane_RateLimit(a.(*RateLimit), b.(*controlplane.RateLimit), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.RateLimit)(nil), (*RateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_RateLimit_To_v1beta2_RateLimit(a.(*controlplane.RateLimit), b.(*RateLimit), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Service)(nil), (*controlplane.Service)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_Service_To_controlplane_Service(a.(*Service), b.(*controlplane.Service), scope)
 	}); err != nil {
@@ -422,6 +462,7 @@ func autoConvert_v1beta2_GroupMember_To_controlplane_GroupMember(in *GroupMember
 	out.ExternalEntity = (*controlplane.ExternalEntityReference)(unsafe.Pointer(in.ExternalEntity))
 	out.IPs = *(*[]controlplane.IPAddress)(unsafe.Pointer(&in.IPs))
 	out.Ports = *(*[]controlplane.NamedPort)(unsafe.Pointer(&in.Ports))
+	out.Node = (*controlplane.NodeReference)(unsafe.Pointer(in.Node))
 	return nil
 }
 
@@ -435,6 +476,7 @@ func autoConvert_controlplane_GroupMember_To_v1beta2_GroupMember(in *controlplan
 	out.ExternalEntity = (*ExternalEntityReference)(unsafe.Pointer(in.ExternalEntity))
 	out.IPs = *(*[]IPAddress)(unsafe.Pointer(&in.IPs))
 	out.Ports = *(*[]NamedPort)(unsafe.Pointer(&in.Ports))
+	out.Node = (*NodeReference)(unsafe.Pointer(in.Node))
 	return nil
 }
 
@@ -443,6 +485,30 @@ func Convert_controlplane_GroupMember_To_v1beta2_GroupMember(in *controlplane.Gr
 	return autoConvert_controlplane_GroupMember_To_v1beta2_GroupMember(in, out, s)
 }
 
+func autoConvert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	out.Method = in.Method
+	out.Host = in.Host
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol is an autogenerated conversion function.
+func Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in, out, s)
+}
+
+func autoConvert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	out.Method = in.Method
+	out.Host = in.Host
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol is an autogenerated conversion function.
+func Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in, out, s)
+}
+
 func autoConvert_v1beta2_IPBlock_To_controlplane_IPBlock(in *IPBlock, out *controlplane.IPBlock, s conversion.Scope) error {
 	if err := Convert_v1beta2_IPNet_To_controlplane_IPNet(&in.CIDR, &out.CIDR, s); err != nil {
 		return err
@@ -491,6 +557,26 @@ func Convert_controlplane_IPNet_To_v1beta2_IPNet(in *controlplane.IPNet, out *IP
 	return autoConvert_controlplane_IPNet_To_v1beta2_IPNet(in, out, s)
 }
 
+func autoConvert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*controlplane.HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol is an autogenerated conversion function.
+func Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	return autoConvert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in, out, s)
+}
+
+func autoConvert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol is an autogenerated conversion function.
+func Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	return autoConvert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in, out, s)
+}
+
 func autoConvert_v1beta2_NamedPort_To_controlplane_NamedPort(in *NamedPort, out *controlplane.NamedPort, s conversion.Scope) error {
 	out.Port = in.Port
 	out.Name = in.Name
@@ -649,6 +735,8 @@ func autoConvert_v1beta2_NetworkPolicyRule_To_controlplane_NetworkPolicyRule(in
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.RateLimit = (*controlplane.RateLimit)(unsafe.Pointer(in.RateLimit))
+	out.L7Protocols = *(*[]controlplane.L7Protocol)(unsafe.Pointer(&in.L7Protocols))
 	return nil
 }
 
@@ -669,6 +757,8 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta2_NetworkPolicyRule(in
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.RateLimit = (*RateLimit)(unsafe.Pointer(in.RateLimit))
+	out.L7Protocols = *(*[]L7Protocol)(unsafe.Pointer(&in.L7Protocols))
 	return nil
 }
 
@@ -725,6 +815,26 @@ func Convert_controlplane_NetworkPolicyStatus_To_v1beta2_NetworkPolicyStatus(in
 	return autoConvert_controlplane_NetworkPolicyStatus_To_v1beta2_NetworkPolicyStatus(in, out, s)
 }
 
+func autoConvert_v1beta2_NodeReference_To_controlplane_NodeReference(in *NodeReference, out *controlplane.NodeReference, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1beta2_NodeReference_To_controlplane_NodeReference is an autogenerated conversion function.
+func Convert_v1beta2_NodeReference_To_controlplane_NodeReference(in *NodeReference, out *controlplane.NodeReference, s conversion.Scope) error {
+	return autoConvert_v1beta2_NodeReference_To_controlplane_NodeReference(in, out, s)
+}
+
+func autoConvert_controlplane_NodeReference_To_v1beta2_NodeReference(in *controlplane.NodeReference, out *NodeReference, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_controlplane_NodeReference_To_v1beta2_NodeReference is an autogenerated conversion function.
+func Convert_controlplane_NodeReference_To_v1beta2_NodeReference(in *controlplane.NodeReference, out *NodeReference, s conversion.Scope) error {
+	return autoConvert_controlplane_NodeReference_To_v1beta2_NodeReference(in, out, s)
+}
+
 func autoConvert_v1beta2_NodeStatsSummary_To_controlplane_NodeStatsSummary(in *NodeStatsSummary, out *controlplane.NodeStatsSummary, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	out.NetworkPolicies = *(*[]controlplane.NetworkPolicyStats)(unsafe.Pointer(&in.NetworkPolicies))
@@ -773,6 +883,30 @@ func Convert_controlplane_PodReference_To_v1beta2_PodReference(in *controlplane.
 	return autoConvert_controlplane_PodReference_To_v1beta2_PodReference(in, out, s)
 }
 
+func autoConvert_v1beta2_RateLimit_To_controlplane_RateLimit(in *RateLimit, out *controlplane.RateLimit, s conversion.Scope) error {
+	out.ConnectionsPerSecond = in.ConnectionsPerSecond
+	out.PacketsPerSecond = in.PacketsPerSecond
+	out.BytesPerSecond = in.BytesPerSecond
+	return nil
+}
+
+// Convert_v1beta2_RateLimit_To_controlplane_RateLimit is an autogenerated conversion function.
+func Convert_v1beta2_RateLimit_To_controlplane_RateLimit(in *RateLimit, out *controlplane.RateLimit, s conversion.Scope) error {
+	return autoConvert_v1beta2_RateLimit_To_controlplane_RateLimit(in, out, s)
+}
+
+func autoConvert_controlplane_RateLimit_To_v1beta2_RateLimit(in *controlplane.RateLimit, out *RateLimit, s conversion.Scope) error {
+	out.ConnectionsPerSecond = in.ConnectionsPerSecond
+	out.PacketsPerSecond = in.PacketsPerSecond
+	out.BytesPerSecond = in.BytesPerSecond
+	return nil
+}
+
+// Convert_controlplane_RateLimit_To_v1beta2_RateLimit is an autogenerated conversion function.
+func Convert_controlplane_RateLimit_To_v1beta2_RateLimit(in *controlplane.RateLimit, out *RateLimit, s conversion.Scope) error {
+	return autoConvert_controlplane_RateLimit_To_v1beta2_RateLimit(in, out, s)
+}
+
 func autoConvert_v1beta2_Service_To_controlplane_Service(in *Service, out *controlplane.Service, s conversion.Scope) error {
 	out.Protocol = (*controlplane.Protocol)(unsafe.Pointer(in.Protocol))
 	out.Port = (*intstr.IntOrString)(unsafe.Pointer(in.Port))
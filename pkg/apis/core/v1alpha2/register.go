@@ -46,6 +46,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&ExternalEntity{},
 		&ExternalEntityList{},
+		&IPPool{},
+		&IPPoolList{},
+		&BGPPolicy{},
+		&BGPPolicyList{},
+		&ExternalIPPool{},
+		&ExternalIPPoolList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
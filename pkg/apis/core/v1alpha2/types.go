@@ -74,3 +74,249 @@ type ExternalEntityList struct {
 
 	Items []ExternalEntity `json:"items,omitempty"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPPool defines one or more IP ranges from which Antrea IPAM allocates Pod
+// IPs. Pods are allocated IPs from an IPPool when their Namespace is annotated
+// with the IPPool name.
+type IPPool struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard metadata of the object.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the desired state of the IPPool.
+	Spec IPPoolSpec `json:"spec"`
+	// Status is the most recently observed allocation state of the IPPool.
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+// IPPoolSpec defines the desired state of an IPPool.
+type IPPoolSpec struct {
+	// IPVersion is the IP version of the IP ranges, 4 or 6.
+	IPVersion int `json:"ipVersion"`
+	// IPRanges is the list of IP ranges, along with their subnet information.
+	IPRanges []SubnetIPRange `json:"ipRanges"`
+}
+
+// IPRange is a set of contiguous IP addresses, represented either by a CIDR or
+// by a start and an end IP (both included).
+type IPRange struct {
+	// The CIDR of this range, e.g. 10.10.10.0/24.
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+	// The start IP of the range, used in conjunction with End.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// The end IP of the range, used in conjunction with Start.
+	// +optional
+	End string `json:"end,omitempty"`
+}
+
+// SubnetInfo describes the subnet the IPs of an IPRange belong to. VLANs are not supported, the traffic of the Pods
+// is sent untagged.
+type SubnetInfo struct {
+	// Gateway IP of this subnet, e.g. 10.10.10.1.
+	Gateway string `json:"gateway"`
+	// Prefix length of the subnet, e.g. 24.
+	PrefixLength int32 `json:"prefixLength"`
+}
+
+// SubnetIPRange is an IPRange with its subnet information.
+type SubnetIPRange struct {
+	IPRange    `json:",inline"`
+	SubnetInfo `json:",inline"`
+}
+
+// IPPoolStatus is the allocation state of an IPPool.
+type IPPoolStatus struct {
+	// IPAddresses is the list of IPs currently allocated from the IPPool.
+	IPAddresses []IPAddressState `json:"ipAddresses,omitempty"`
+}
+
+// IPAddressPhase is the phase of an IP address in an IPPool.
+type IPAddressPhase string
+
+const (
+	IPAddressPhaseAllocated IPAddressPhase = "Allocated"
+)
+
+// IPAddressState describes an IP address allocated from an IPPool.
+type IPAddressState struct {
+	// IP address.
+	IPAddress string `json:"ipAddress"`
+	// Allocation state.
+	Phase IPAddressPhase `json:"phase"`
+	// Owner of the IP address.
+	Owner IPAddressOwner `json:"owner"`
+}
+
+// IPAddressOwner is the owner of an allocated IP address.
+type IPAddressOwner struct {
+	// Pod is set when the IP address is allocated to a Pod.
+	// +optional
+	Pod *PodOwner `json:"pod,omitempty"`
+}
+
+// PodOwner identifies the Pod an IP address is allocated to.
+type PodOwner struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	ContainerID string `json:"containerID"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []IPPool `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BGPPolicy configures the BGP speaker of the antrea-agents running on the
+// Nodes it selects, which advertises routes to the Pods and Services of the
+// cluster to upstream BGP peers.
+type BGPPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard metadata of the object.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the desired state of the BGPPolicy.
+	Spec BGPPolicySpec `json:"spec"`
+}
+
+// BGPPolicySpec defines the desired state of a BGPPolicy.
+type BGPPolicySpec struct {
+	// NodeSelector selects the Nodes the BGPPolicy applies to. If a Node is
+	// selected by multiple BGPPolicies, the oldest one takes effect.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	// LocalASN is the AS number of the BGP speaker on the selected Nodes.
+	LocalASN int64 `json:"localASN"`
+	// Advertisements configures the routes advertised to the BGP peers.
+	Advertisements Advertisements `json:"advertisements,omitempty"`
+	// BGPPeers is the list of BGP peers the BGP speaker connects to.
+	BGPPeers []BGPPeer `json:"bgpPeers,omitempty"`
+}
+
+// Advertisements configures the routes advertised by a BGP speaker.
+type Advertisements struct {
+	// Pod enables the advertisement of the PodCIDRs of the Node.
+	// +optional
+	Pod *PodAdvertisement `json:"pod,omitempty"`
+	// Service enables the advertisement of the IPs of Services.
+	// +optional
+	Service *ServiceAdvertisement `json:"service,omitempty"`
+}
+
+// PodAdvertisement configures the advertisement of the PodCIDRs of a Node.
+type PodAdvertisement struct{}
+
+// ServiceIPType is a type of Service IP which can be advertised.
+type ServiceIPType string
+
+const (
+	// ServiceIPTypeLoadBalancerIP is the ingress IPs of LoadBalancer Services.
+	ServiceIPTypeLoadBalancerIP ServiceIPType = "LoadBalancerIP"
+	// ServiceIPTypeExternalIP is the external IPs of Services.
+	ServiceIPTypeExternalIP ServiceIPType = "ExternalIP"
+)
+
+// ServiceAdvertisement configures the advertisement of Service IPs.
+type ServiceAdvertisement struct {
+	// IPTypes is the list of Service IP types to advertise.
+	IPTypes []ServiceIPType `json:"ipTypes,omitempty"`
+}
+
+// BGPPeer is a BGP peer of a BGP speaker.
+type BGPPeer struct {
+	// Address is the IP address of the peer. Only the routes of the same IP
+	// family as the peer address are advertised to the peer.
+	Address string `json:"address"`
+	// Port is the TCP port of the peer. Defaults to 179.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// ASN is the AS number of the peer.
+	ASN int64 `json:"asn"`
+	// HoldTime is the proposed hold time of the BGP session, in seconds.
+	// Defaults to 90. It must be at least 3, see RFC 4271.
+	// +optional
+	HoldTime int32 `json:"holdTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type BGPPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []BGPPolicy `json:"items"`
+}
+
+const (
+	// ExternalIPPoolAnnotationKey is the annotation of a LoadBalancer Service
+	// which specifies the ExternalIPPool its ingress IP is allocated from.
+	ExternalIPPoolAnnotationKey = "service.antrea.tanzu.vmware.com/external-ip-pool"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ExternalIPPool defines one or more IP ranges from which antrea-controller
+// allocates the ingress IPs of the LoadBalancer Services annotated with the
+// ExternalIPPool name. Each allocated IP is announced by one of the Nodes
+// selected by the ExternalIPPool.
+type ExternalIPPool struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard metadata of the object.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the desired state of the ExternalIPPool.
+	Spec ExternalIPPoolSpec `json:"spec"`
+	// Status is the most recently observed allocation state of the
+	// ExternalIPPool.
+	Status ExternalIPPoolStatus `json:"status,omitempty"`
+}
+
+// ExternalIPPoolSpec defines the desired state of an ExternalIPPool.
+type ExternalIPPoolSpec struct {
+	// IPRanges is the list of IP ranges the IPs are allocated from.
+	IPRanges []IPRange `json:"ipRanges"`
+	// NodeSelector selects the Nodes which can announce the allocated IPs. An
+	// empty selector selects all Nodes.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+}
+
+// ExternalIPPoolStatus is the allocation state of an ExternalIPPool.
+type ExternalIPPoolStatus struct {
+	// Usage is the usage of the IPs of the ExternalIPPool.
+	Usage ExternalIPPoolUsage `json:"usage,omitempty"`
+}
+
+// ExternalIPPoolUsage is the usage of the IPs of an ExternalIPPool.
+type ExternalIPPoolUsage struct {
+	// Total is the number of IPs in the ExternalIPPool.
+	Total int `json:"total"`
+	// Used is the number of IPs allocated from the ExternalIPPool.
+	Used int `json:"used"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ExternalIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ExternalIPPool `json:"items"`
+}
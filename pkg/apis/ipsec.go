@@ -0,0 +1,26 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apis
+
+const (
+	// IPsecCSRSignerName is the signerName of the CertificateSigningRequests
+	// created by antrea-agents to get the certificates of their IPsec
+	// tunnels, which are signed by antrea-controller.
+	IPsecCSRSignerName = "antrea.tanzu.vmware.com/antrea-agent-ipsec-tunnel"
+	// IPsecCAConfigMapName is the name of the ConfigMap which holds the CA
+	// certificate that signs the certificates of the IPsec tunnels.
+	IPsecCAConfigMapName = "antrea-ipsec-ca"
+	IPsecCAConfigMapKey  = "ca.crt"
+)
@@ -82,6 +82,53 @@ type NetworkPolicyStatus struct {
 	CurrentNodesRealized int32 `json:"currentNodesRealized"`
 	// The total number of nodes that should realize the NetworkPolicy.
 	DesiredNodesRealized int32 `json:"desiredNodesRealized"`
+	// Conditions represent the latest available observations of the NetworkPolicy.
+	// +optional
+	Conditions []NetworkPolicyCondition `json:"conditions,omitempty"`
+	// ScheduledRules is the activation state of the rules which have a schedule.
+	// +optional
+	ScheduledRules []ScheduledRuleStatus `json:"scheduledRules,omitempty"`
+}
+
+// ScheduledRuleStatus describes the activation state of a rule which has a schedule.
+type ScheduledRuleStatus struct {
+	// Name of the rule, or "<direction>-<index>" if the rule has no name, e.g.
+	// "ingress-0" for the first ingress rule.
+	Name string `json:"name"`
+	// Active is true if the rule is currently enforced.
+	Active bool `json:"active"`
+	// NextTransitionTime is the time at which the rule is next activated or
+	// deactivated. It is not set if the activation state never changes again.
+	// +optional
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+}
+
+// NetworkPolicyConditionType describes the type of a NetworkPolicyCondition.
+type NetworkPolicyConditionType string
+
+// These are the valid values for NetworkPolicyConditionType.
+const (
+	// NetworkPolicyRulesIneffective is True when some rules of the NetworkPolicy can never be
+	// matched, because they are shadowed by or redundant with higher precedence rules, or
+	// because their peers select no workloads. The Message lists the affected rules.
+	NetworkPolicyRulesIneffective NetworkPolicyConditionType = "RulesIneffective"
+)
+
+// NetworkPolicyCondition describes the state of a NetworkPolicy at a certain point.
+type NetworkPolicyCondition struct {
+	// Type of the condition.
+	Type NetworkPolicyConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // Rule describes the traffic allowed to/from the workloads selected by
@@ -110,6 +157,91 @@ type Rule struct {
 	// EnableLogging is used to indicate if agent should generate logs
 	// when rules are matched. Should be default to false.
 	EnableLogging bool `json:"enableLogging"`
+	// Schedule restricts the time during which the rule is active. The rule
+	// is always active if this field is not set.
+	// +optional
+	Schedule *RuleSchedule `json:"schedule,omitempty"`
+	// RateLimit limits the rate of the traffic matched by the rule. It can
+	// only be set for rules with the Allow action.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// L7Protocols restricts the traffic allowed by the rule to the
+	// application layer requests matching any of these protocols. It can only
+	// be set for ingress rules with the Allow action.
+	// +optional
+	L7Protocols []L7Protocol `json:"l7Protocols,omitempty"`
+}
+
+// L7Protocol describes an application layer protocol match. Exactly one
+// protocol must be set.
+type L7Protocol struct {
+	// HTTP matches HTTP requests.
+	// +optional
+	HTTP *HTTPProtocol `json:"http,omitempty"`
+}
+
+// HTTPProtocol matches HTTP requests. An empty field matches any value.
+type HTTPProtocol struct {
+	// Method is the method of the request, e.g. GET.
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Host is the host of the request. It matches any subdomain of a domain
+	// if it starts with "*.", e.g. "*.example.com".
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Path is the path of the request. It matches any path starting with a
+	// prefix if it ends with "*", e.g. "/api/*".
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// RateLimit describes the limits on the rate of the traffic matched by a rule.
+// The limits are enforced on each Node for each peer of the rule, and the
+// traffic exceeding them is dropped. At most one of PacketsPerSecond and
+// BytesPerSecond can be set.
+type RateLimit struct {
+	// ConnectionsPerSecond is the maximum rate of new connections matched by
+	// the rule.
+	// +optional
+	ConnectionsPerSecond int32 `json:"connectionsPerSecond,omitempty"`
+	// PacketsPerSecond is the maximum rate of packets of the connections
+	// matched by the rule, in both directions.
+	// +optional
+	PacketsPerSecond int32 `json:"packetsPerSecond,omitempty"`
+	// BytesPerSecond is the maximum rate of bytes of the connections matched
+	// by the rule, in both directions.
+	// +optional
+	BytesPerSecond int32 `json:"bytesPerSecond,omitempty"`
+}
+
+// RuleSchedule describes when a rule is active. A rule with a schedule is
+// active between its ActivationTime and its ExpirationTime, and within one of
+// its Windows if any is set.
+type RuleSchedule struct {
+	// Windows are the recurring time windows during which the rule is active.
+	// If empty, the rule is active at any time between its ActivationTime and
+	// its ExpirationTime.
+	// +optional
+	Windows []TimeWindow `json:"windows,omitempty"`
+	// ActivationTime is the time before which the rule is inactive.
+	// +optional
+	ActivationTime *metav1.Time `json:"activationTime,omitempty"`
+	// ExpirationTime is the time from which the rule is inactive.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+// TimeWindow is a daily time window in UTC.
+type TimeWindow struct {
+	// Start of the window in "HH:MM" format, e.g. "01:00".
+	Start string `json:"start"`
+	// End of the window in "HH:MM" format, e.g. "03:00". The window spans
+	// midnight if End is before Start.
+	End string `json:"end"`
+	// Days of the week on which the window starts, as three-letter
+	// abbreviations, e.g. "Mon". The window starts every day if empty.
+	// +optional
+	Days []string `json:"days,omitempty"`
 }
 
 // NetworkPolicyPeer describes the grouping selector of workloads.
@@ -138,6 +270,12 @@ type NetworkPolicyPeer struct {
 	// NamespaceSelector.
 	// Cannot be set with any other selector except NamespaceSelector.
 	ExternalEntitySelector *metav1.LabelSelector `json:"externalEntitySelector,omitempty"`
+	// Select Nodes in cluster as workloads in To/From fields. The addresses
+	// of the selected Nodes, including the gateway IPs allocated from their
+	// PodCIDRs, are matched by the rule.
+	// Cannot be set with any other selector.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
 }
 
 // IPBlock describes a particular CIDR (Ex. "192.168.1.1/24") that is allowed
@@ -146,6 +284,11 @@ type IPBlock struct {
 	// CIDR is a string representing the IP Block
 	// Valid examples are "192.168.1.1/24".
 	CIDR string `json:"cidr"`
+	// Except is a slice of CIDRs that should not be included within an IP Block
+	// Valid examples are "192.168.1.1/24".
+	// Except values will be rejected if they are outside the CIDR range.
+	// +optional
+	Except []string `json:"except,omitempty"`
 }
 
 // NetworkPolicyPort describes the port and protocol to match in a rule.
@@ -170,6 +313,11 @@ const (
 	RuleActionAllow RuleAction = "Allow"
 	// RuleActionDrop describes that rule matching traffic must be dropped.
 	RuleActionDrop RuleAction = "Drop"
+	// RuleActionAudit describes that rule matching traffic must be logged
+	// and counted without being allowed or dropped by the rule. Evaluation
+	// then continues with the next rule, as if the Audit rule was not
+	// matched.
+	RuleActionAudit RuleAction = "Audit"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -92,4 +92,7 @@ type TrafficStats struct {
 	Bytes int64
 	// Sessions is the sessions count hit by the NetworkPolicy.
 	Sessions int64
+	// RateLimitedPackets is the packets count dropped by the rate limits of the
+	// NetworkPolicy's rules.
+	RateLimitedPackets int64
 }
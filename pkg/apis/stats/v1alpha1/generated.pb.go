@@ -251,41 +251,43 @@ func init() {
 }
 
 var fileDescriptor_87568b32f9b1aa25 = []byte{
-	// 542 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x52, 0xcf, 0x8b, 0xd3, 0x40,
-	0x18, 0xed, 0xb4, 0x94, 0x2d, 0xb3, 0x2b, 0x4a, 0x10, 0x29, 0x45, 0x52, 0xe9, 0x5e, 0x56, 0x70,
-	0x67, 0xec, 0x22, 0x8b, 0x57, 0xa3, 0x08, 0x8a, 0x3f, 0x4a, 0x56, 0x10, 0x44, 0xd0, 0x69, 0x76,
-	0x9a, 0x8e, 0x6d, 0x32, 0x21, 0xf3, 0xa5, 0x4b, 0x45, 0x44, 0xf1, 0xea, 0xc1, 0x3f, 0xab, 0xc7,
-	0x3d, 0xee, 0x69, 0xb1, 0xf1, 0xe0, 0x1f, 0x20, 0x78, 0xf2, 0x20, 0x99, 0xb4, 0x4d, 0x6a, 0x59,
-	0x5a, 0x2a, 0xe8, 0x61, 0x6f, 0x99, 0x6f, 0xe6, 0xbd, 0xf7, 0xbd, 0xf7, 0x82, 0xef, 0xbb, 0x02,
-	0xba, 0x51, 0x9b, 0x38, 0xd2, 0xa3, 0x03, 0xef, 0x88, 0x85, 0x7c, 0x17, 0x98, 0xff, 0x36, 0xa2,
-	0xcc, 0x87, 0x90, 0x33, 0x1a, 0xf4, 0x5c, 0xca, 0x02, 0xa1, 0xa8, 0x02, 0x06, 0x8a, 0x0e, 0x9a,
-	0xac, 0x1f, 0x74, 0x59, 0x93, 0xba, 0xdc, 0xe7, 0x21, 0x03, 0x7e, 0x48, 0x82, 0x50, 0x82, 0x34,
-	0xf6, 0x33, 0x1e, 0x92, 0xf2, 0xbc, 0xd2, 0x3c, 0x24, 0xe5, 0x21, 0x41, 0xcf, 0x25, 0x09, 0x0f,
-	0xd1, 0x3c, 0x64, 0xca, 0x53, 0xdb, 0xcd, 0xe9, 0xbb, 0xd2, 0x95, 0x54, 0xd3, 0xb5, 0xa3, 0x8e,
-	0x3e, 0xe9, 0x83, 0xfe, 0x4a, 0x65, 0x6a, 0xb7, 0x7a, 0xb7, 0x15, 0x11, 0x32, 0x59, 0xc9, 0x63,
-	0x4e, 0x57, 0xf8, 0x3c, 0x1c, 0x66, 0x3b, 0x7a, 0x1c, 0x18, 0x1d, 0x2c, 0x2c, 0x57, 0xa3, 0x67,
-	0xa1, 0xc2, 0xc8, 0x07, 0xe1, 0xf1, 0x05, 0xc0, 0xfe, 0x32, 0x80, 0x72, 0xba, 0xdc, 0x63, 0x7f,
-	0xe2, 0x1a, 0x9f, 0x8a, 0xb8, 0x7e, 0x47, 0x1b, 0xbe, 0xdb, 0x8f, 0x14, 0xf0, 0xf0, 0x09, 0x87,
-	0x23, 0x19, 0xf6, 0x5a, 0xb2, 0x2f, 0x9c, 0xe1, 0x41, 0x62, 0xdd, 0x78, 0x8d, 0x2b, 0xc9, 0x9e,
-	0x87, 0x0c, 0x58, 0x15, 0x5d, 0x43, 0x3b, 0x9b, 0x7b, 0x37, 0x49, 0x2a, 0x47, 0xf2, 0x72, 0x59,
-	0x62, 0xc9, 0x6b, 0x32, 0x68, 0x92, 0xa7, 0xed, 0x37, 0xdc, 0x81, 0xc7, 0x1c, 0x98, 0x65, 0x8c,
-	0x4e, 0xeb, 0x85, 0xf8, 0xb4, 0x8e, 0xb3, 0x99, 0x3d, 0x63, 0x35, 0xde, 0xe3, 0x2d, 0x08, 0x59,
-	0xa7, 0x23, 0x1c, 0xad, 0x58, 0x2d, 0x6a, 0x95, 0x7b, 0x64, 0xbd, 0x8a, 0xc8, 0xb3, 0x1c, 0x97,
-	0x75, 0x79, 0xa2, 0xbc, 0x95, 0x9f, 0xda, 0x73, 0x7a, 0x8d, 0x8f, 0x45, 0xbc, 0xbd, 0x24, 0x85,
-	0x47, 0x42, 0x81, 0xf1, 0x72, 0x21, 0x09, 0xb2, 0x5a, 0x12, 0x09, 0x5a, 0xe7, 0x70, 0x69, 0xb2,
-	0x4d, 0x65, 0x3a, 0xc9, 0xa5, 0xf0, 0x0e, 0x97, 0x05, 0x70, 0x2f, 0xb1, 0x5f, 0xda, 0xd9, 0xdc,
-	0x7b, 0xbe, 0xae, 0xfd, 0x25, 0x4e, 0xac, 0x0b, 0x93, 0x1d, 0xca, 0x0f, 0x12, 0x35, 0x3b, 0x15,
-	0x6d, 0xfc, 0x42, 0xb8, 0x9a, 0x22, 0xcf, 0xe5, 0x2f, 0xf0, 0x03, 0xe1, 0xab, 0x67, 0xd9, 0xff,
-	0x07, 0xdd, 0x47, 0xf3, 0xdd, 0xb7, 0xfe, 0xae, 0xfb, 0x95, 0x4b, 0xff, 0x89, 0xb0, 0x71, 0x2e,
-	0xeb, 0xfe, 0x8e, 0xf0, 0x95, 0xff, 0x52, 0xb4, 0x9c, 0x2f, 0xfa, 0xe1, 0xba, 0x8e, 0x57, 0xae,
-	0xf8, 0x33, 0xc2, 0x73, 0x41, 0x18, 0xd7, 0xf1, 0x46, 0xc0, 0x9c, 0x1e, 0x07, 0xa5, 0xed, 0x95,
-	0xac, 0x8b, 0x13, 0xdc, 0x46, 0x2b, 0x1d, 0xdb, 0xd3, 0x7b, 0x63, 0x1b, 0x97, 0xdb, 0x43, 0xe0,
-	0x69, 0x3d, 0xa5, 0x4c, 0xc0, 0x4a, 0x86, 0x76, 0x7a, 0x67, 0xdc, 0xc0, 0x15, 0xc5, 0x95, 0x12,
-	0xd2, 0x57, 0xd5, 0x92, 0x7e, 0x37, 0xf3, 0x7f, 0x30, 0x99, 0xdb, 0xb3, 0x17, 0x16, 0x19, 0x8d,
-	0xcd, 0xc2, 0xf1, 0xd8, 0x2c, 0x9c, 0x8c, 0xcd, 0xc2, 0x87, 0xd8, 0x44, 0xa3, 0xd8, 0x44, 0xc7,
-	0xb1, 0x89, 0x4e, 0x62, 0x13, 0x7d, 0x8d, 0x4d, 0xf4, 0xe5, 0x9b, 0x59, 0x78, 0x51, 0x99, 0xfa,
-	0xfc, 0x1d, 0x00, 0x00, 0xff, 0xff, 0x80, 0xc7, 0x0a, 0x29, 0xef, 0x07, 0x00, 0x00,
+	// 564 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x52, 0xcf, 0x6b, 0x13, 0x41,
+	0x18, 0xcd, 0x24, 0x86, 0x86, 0x69, 0x45, 0x19, 0x44, 0x42, 0x90, 0x8d, 0xa4, 0x97, 0x0a, 0x76,
+	0xd6, 0x14, 0x29, 0x5e, 0x5d, 0x45, 0xb0, 0x54, 0x0d, 0x5b, 0x41, 0x10, 0x41, 0x27, 0x9b, 0xc9,
+	0x66, 0x4c, 0xf6, 0x07, 0x3b, 0xdf, 0xa6, 0x44, 0x44, 0x14, 0xff, 0x01, 0xff, 0xac, 0x1c, 0x7b,
+	0xcc, 0xa9, 0x98, 0xf5, 0xe0, 0x1f, 0x20, 0x78, 0xf2, 0x20, 0x3b, 0xbb, 0x49, 0x36, 0xc6, 0x92,
+	0x10, 0xc1, 0x1e, 0x7a, 0xcb, 0x7e, 0xf3, 0xbd, 0xf7, 0xbe, 0xf7, 0x5e, 0xf0, 0x23, 0x5b, 0x40,
+	0x27, 0x6c, 0x52, 0xcb, 0x73, 0xf4, 0xbe, 0x73, 0xcc, 0x02, 0xbe, 0x0b, 0xcc, 0x7d, 0x17, 0xea,
+	0xcc, 0x85, 0x80, 0x33, 0xdd, 0xef, 0xda, 0x3a, 0xf3, 0x85, 0xd4, 0x25, 0x30, 0x90, 0x7a, 0xbf,
+	0xce, 0x7a, 0x7e, 0x87, 0xd5, 0x75, 0x9b, 0xbb, 0x3c, 0x60, 0xc0, 0x5b, 0xd4, 0x0f, 0x3c, 0xf0,
+	0xc8, 0xfe, 0x8c, 0x87, 0x26, 0x3c, 0xaf, 0x15, 0x0f, 0x4d, 0x78, 0xa8, 0xdf, 0xb5, 0x69, 0xcc,
+	0x43, 0x15, 0x0f, 0x9d, 0xf0, 0x54, 0x76, 0x33, 0xfa, 0xb6, 0x67, 0x7b, 0xba, 0xa2, 0x6b, 0x86,
+	0x6d, 0xf5, 0xa5, 0x3e, 0xd4, 0xaf, 0x44, 0xa6, 0x72, 0xb7, 0x7b, 0x4f, 0x52, 0xe1, 0xc5, 0x27,
+	0x39, 0xcc, 0xea, 0x08, 0x97, 0x07, 0x83, 0xd9, 0x8d, 0x0e, 0x07, 0xa6, 0xf7, 0x17, 0x8e, 0xab,
+	0xe8, 0x67, 0xa1, 0x82, 0xd0, 0x05, 0xe1, 0xf0, 0x05, 0xc0, 0xfe, 0x32, 0x80, 0xb4, 0x3a, 0xdc,
+	0x61, 0x7f, 0xe2, 0x6a, 0x9f, 0xf3, 0xb8, 0x7a, 0x5f, 0x19, 0x7e, 0xd0, 0x0b, 0x25, 0xf0, 0xe0,
+	0x29, 0x87, 0x63, 0x2f, 0xe8, 0x36, 0xbc, 0x9e, 0xb0, 0x06, 0x47, 0xb1, 0x75, 0xf2, 0x06, 0x97,
+	0xe2, 0x3b, 0x5b, 0x0c, 0x58, 0x19, 0xdd, 0x44, 0x3b, 0x9b, 0x7b, 0x77, 0x68, 0x22, 0x47, 0xb3,
+	0x72, 0xb3, 0xc4, 0xe2, 0x6d, 0xda, 0xaf, 0xd3, 0x67, 0xcd, 0xb7, 0xdc, 0x82, 0x27, 0x1c, 0x98,
+	0x41, 0x86, 0xa7, 0xd5, 0x5c, 0x74, 0x5a, 0xc5, 0xb3, 0x99, 0x39, 0x65, 0x25, 0x1f, 0xf0, 0x16,
+	0x04, 0xac, 0xdd, 0x16, 0x96, 0x52, 0x2c, 0xe7, 0x95, 0xca, 0x43, 0xba, 0x5e, 0x45, 0xf4, 0x79,
+	0x86, 0xcb, 0xb8, 0x96, 0x2a, 0x6f, 0x65, 0xa7, 0xe6, 0x9c, 0x5e, 0xed, 0x53, 0x1e, 0x6f, 0x2f,
+	0x49, 0xe1, 0x50, 0x48, 0x20, 0xaf, 0x16, 0x92, 0xa0, 0xab, 0x25, 0x11, 0xa3, 0x55, 0x0e, 0x57,
+	0xd3, 0x6b, 0x4a, 0x93, 0x49, 0x26, 0x85, 0xf7, 0xb8, 0x28, 0x80, 0x3b, 0xb1, 0xfd, 0xc2, 0xce,
+	0xe6, 0xde, 0x8b, 0x75, 0xed, 0x2f, 0x71, 0x62, 0x5c, 0x4e, 0x6f, 0x28, 0x3e, 0x8e, 0xd5, 0xcc,
+	0x44, 0xb4, 0xf6, 0x0b, 0xe1, 0x72, 0x82, 0xbc, 0x90, 0x7f, 0x81, 0x1f, 0x08, 0xdf, 0x38, 0xcb,
+	0xfe, 0x7f, 0xe8, 0x3e, 0x9c, 0xef, 0xbe, 0xf1, 0x6f, 0xdd, 0xaf, 0x5c, 0xfa, 0x4f, 0x84, 0xc9,
+	0x85, 0xac, 0xfb, 0x3b, 0xc2, 0xd7, 0xcf, 0xa5, 0x68, 0x6f, 0xbe, 0xe8, 0x83, 0x75, 0x1d, 0xaf,
+	0x5c, 0xf1, 0x08, 0xe1, 0xb9, 0x20, 0xc8, 0x2d, 0xbc, 0xe1, 0x33, 0xab, 0xcb, 0x41, 0x2a, 0x7b,
+	0x05, 0xe3, 0x4a, 0x8a, 0xdb, 0x68, 0x24, 0x63, 0x73, 0xf2, 0x4e, 0xb6, 0x71, 0xb1, 0x39, 0x00,
+	0x9e, 0xd4, 0x53, 0x98, 0x09, 0x18, 0xf1, 0xd0, 0x4c, 0xde, 0xc8, 0x6d, 0x5c, 0x92, 0x5c, 0x4a,
+	0xe1, 0xb9, 0xb2, 0x5c, 0x50, 0x7b, 0x53, 0xff, 0x47, 0xe9, 0xdc, 0x9c, 0x6e, 0x90, 0x03, 0x4c,
+	0x02, 0x06, 0xfc, 0x50, 0x38, 0x02, 0x78, 0x2b, 0x55, 0x2c, 0x5f, 0x52, 0xb8, 0x4a, 0x8a, 0x23,
+	0xe6, 0xc2, 0x86, 0xf9, 0x17, 0x94, 0x41, 0x87, 0x63, 0x2d, 0x77, 0x32, 0xd6, 0x72, 0xa3, 0xb1,
+	0x96, 0xfb, 0x18, 0x69, 0x68, 0x18, 0x69, 0xe8, 0x24, 0xd2, 0xd0, 0x28, 0xd2, 0xd0, 0xd7, 0x48,
+	0x43, 0x5f, 0xbe, 0x69, 0xb9, 0x97, 0xa5, 0x49, 0x66, 0xbf, 0x07, 0x00, 0x77, 0x2a, 0xca, 0x0d,
+	0x3b, 0x08, 0x00, 0x00,
 }
 
 func (m *AntreaClusterNetworkPolicyStats) Marshal() (dAtA []byte, err error) {
@@ -578,6 +580,9 @@ func (m *TrafficStats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	i = encodeVarintGenerated(dAtA, i, uint64(m.RateLimitedPackets))
+	i--
+	dAtA[i] = 0x20
 	i = encodeVarintGenerated(dAtA, i, uint64(m.Sessions))
 	i--
 	dAtA[i] = 0x18
@@ -700,6 +705,7 @@ func (m *TrafficStats) Size() (n int) {
 	n += 1 + sovGenerated(uint64(m.Packets))
 	n += 1 + sovGenerated(uint64(m.Bytes))
 	n += 1 + sovGenerated(uint64(m.Sessions))
+	n += 1 + sovGenerated(uint64(m.RateLimitedPackets))
 	return n
 }
 
@@ -798,6 +804,7 @@ func (this *TrafficStats) String() string {
 		`Packets:` + fmt.Sprintf("%v", this.Packets) + `,`,
 		`Bytes:` + fmt.Sprintf("%v", this.Bytes) + `,`,
 		`Sessions:` + fmt.Sprintf("%v", this.Sessions) + `,`,
+		`RateLimitedPackets:` + fmt.Sprintf("%v", this.RateLimitedPackets) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1613,6 +1620,25 @@ func (m *TrafficStats) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RateLimitedPackets", wireType)
+			}
+			m.RateLimitedPackets = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RateLimitedPackets |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
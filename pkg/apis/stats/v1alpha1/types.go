@@ -102,4 +102,7 @@ type TrafficStats struct {
 	Bytes int64 `json:"bytes,omitempty" protobuf:"varint,2,opt,name=bytes"`
 	// Sessions is the sessions count hit by the NetworkPolicy.
 	Sessions int64 `json:"sessions,omitempty" protobuf:"varint,3,opt,name=sessions"`
+	// RateLimitedPackets is the packets count dropped by the rate limits of the
+	// NetworkPolicy's rules.
+	RateLimitedPackets int64 `json:"rateLimitedPackets,omitempty" protobuf:"varint,4,opt,name=rateLimitedPackets"`
 }
@@ -254,6 +254,7 @@ func autoConvert_v1alpha1_TrafficStats_To_stats_TrafficStats(in *TrafficStats, o
 	out.Packets = in.Packets
 	out.Bytes = in.Bytes
 	out.Sessions = in.Sessions
+	out.RateLimitedPackets = in.RateLimitedPackets
 	return nil
 }
 
@@ -266,6 +267,7 @@ func autoConvert_stats_TrafficStats_To_v1alpha1_TrafficStats(in *stats.TrafficSt
 	out.Packets = in.Packets
 	out.Bytes = in.Bytes
 	out.Sessions = in.Sessions
+	out.RateLimitedPackets = in.RateLimitedPackets
 	return nil
 }
 
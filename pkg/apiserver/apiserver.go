@@ -39,6 +39,7 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/certificate"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/endpoint"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/loglevel"
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/networkpolicyevaluation"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/handlers/webhook"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/registry/controlplane/nodestatssummary"
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/registry/networkpolicy/addressgroup"
@@ -82,6 +83,7 @@ type ExtraConfig struct {
 	networkPolicyStore            storage.Interface
 	controllerQuerier             querier.ControllerQuerier
 	endpointQuerier               controllernetworkpolicy.EndpointQuerier
+	policyEvaluator               controllernetworkpolicy.PolicyEvaluator
 	networkPolicyController       *controllernetworkpolicy.NetworkPolicyController
 	caCertController              *certificate.CACertController
 	statsAggregator               *stats.Aggregator
@@ -101,13 +103,28 @@ type APIServer struct {
 }
 
 func (s *APIServer) Run(stopCh <-chan struct{}) error {
-	// Make sure CACertController runs once to publish the CA cert before starting APIServer.
+	return s.GenericAPIServer.PrepareRun().Run(stopCh)
+}
+
+// PublishCACert publishes the CA cert of the APIServer once.
+func (s *APIServer) PublishCACert() {
 	if err := s.caCertController.RunOnce(); err != nil {
 		klog.Warningf("caCertController RunOnce failed: %v", err)
 	}
-	go s.caCertController.Run(1, stopCh)
+}
 
-	return s.GenericAPIServer.PrepareRun().Run(stopCh)
+// WatchCACert keeps the published CA cert of the APIServer up to date until stopCh is closed.
+func (s *APIServer) WatchCACert(stopCh <-chan struct{}) {
+	s.caCertController.Run(1, stopCh)
+}
+
+// RunCACertController publishes the CA cert of the APIServer and keeps it up to date until stopCh is closed.
+// When there are multiple antrea-controller replicas, only the leader must run it as each replica may have its
+// own self-signed certificate.
+func (s *APIServer) RunCACertController(stopCh <-chan struct{}) {
+	// Make sure CACertController runs once to publish the CA cert before it starts watching the cert.
+	s.PublishCACert()
+	s.WatchCACert(stopCh)
 }
 
 type completedConfig struct {
@@ -123,6 +140,7 @@ func NewConfig(
 	controllerQuerier querier.ControllerQuerier,
 	networkPolicyStatusController *controllernetworkpolicy.StatusController,
 	endpointQuerier controllernetworkpolicy.EndpointQuerier,
+	policyEvaluator controllernetworkpolicy.PolicyEvaluator,
 	npController *controllernetworkpolicy.NetworkPolicyController) *Config {
 	return &Config{
 		genericConfig: genericConfig,
@@ -134,6 +152,7 @@ func NewConfig(
 			statsAggregator:               statsAggregator,
 			controllerQuerier:             controllerQuerier,
 			endpointQuerier:               endpointQuerier,
+			policyEvaluator:               policyEvaluator,
 			networkPolicyController:       npController,
 			networkPolicyStatusController: networkPolicyStatusController,
 		},
@@ -240,6 +259,7 @@ func CleanupDeprecatedAPIServices(aggregatorClient clientset.Interface) error {
 func installHandlers(c *ExtraConfig, s *genericapiserver.GenericAPIServer) {
 	s.Handler.NonGoRestfulMux.HandleFunc("/loglevel", loglevel.HandleFunc())
 	s.Handler.NonGoRestfulMux.HandleFunc("/endpoint", endpoint.HandleFunc(c.endpointQuerier))
+	s.Handler.NonGoRestfulMux.HandleFunc("/networkpolicyevaluation", networkpolicyevaluation.HandleFunc(c.policyEvaluator))
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		// Get new NetworkPolicyMutator
 		m := controllernetworkpolicy.NewNetworkPolicyMutator(c.networkPolicyController)
@@ -0,0 +1,79 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicyevaluation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
+)
+
+// evaluationParams are the params of an evaluation request, which are sent as a JSON object in
+// the body of a POST request as the draft policies may be too large for a URL.
+type evaluationParams struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Protocol    string `json:"protocol"`
+	Port        string `json:"port"`
+	Policy      string `json:"policy"`
+}
+
+// HandleFunc creates a http.HandlerFunc which uses a PolicyEvaluator to evaluate the
+// NetworkPolicies for the traffic described by the params in the request body.
+func HandleFunc(pe networkpolicy.PolicyEvaluator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var params evaluationParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "failed to decode request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		// check for incomplete arguments
+		if params.Source == "" || params.Destination == "" {
+			http.Error(w, "source and destination must be provided", http.StatusBadRequest)
+			return
+		}
+		port, err := strconv.ParseInt(params.Port, 10, 32)
+		if err != nil {
+			http.Error(w, "a valid port must be provided", http.StatusBadRequest)
+			return
+		}
+		request := &networkpolicy.EvaluationRequest{
+			Source:        params.Source,
+			Destination:   params.Destination,
+			Protocol:      v1.Protocol(strings.ToUpper(params.Protocol)),
+			Port:          int32(port),
+			DraftPolicies: []byte(params.Policy),
+		}
+		// The errors returned by the evaluation are caused by the request, e.g. an endpoint
+		// which does not exist or an invalid draft policy.
+		response, err := pe.EvaluateNetworkPolicies(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(*response); err != nil {
+			http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
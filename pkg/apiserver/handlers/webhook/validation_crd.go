@@ -21,11 +21,27 @@ import (
 	"net/http"
 
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 
 	"github.com/vmware-tanzu/antrea/pkg/controller/networkpolicy"
 )
 
+// admissionReviewWithWarnings is an AdmissionReview whose response can carry warnings.
+// The warnings field of AdmissionResponse was introduced in K8s v1.19 and is
+// not available in the version of k8s.io/api in use. Older K8s versions ignore
+// it.
+type admissionReviewWithWarnings struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *admv1.AdmissionRequest        `json:"request,omitempty"`
+	Response        *admissionResponseWithWarnings `json:"response,omitempty"`
+}
+
+type admissionResponseWithWarnings struct {
+	*admv1.AdmissionResponse
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 func HandleValidationNetworkPolicy(v *networkpolicy.NetworkPolicyValidator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		klog.V(2).Info("Received request to validate Antrea Policy/Tier CRD")
@@ -46,6 +62,7 @@ func HandleValidationNetworkPolicy(v *networkpolicy.NetworkPolicyValidator) http
 			return
 		}
 		var admissionResponse *admv1.AdmissionResponse
+		var warnings []string
 		ar := admv1.AdmissionReview{}
 		ar.TypeMeta.Kind = "AdmissionReview"
 		ar.TypeMeta.APIVersion = "admission.k8s.io/v1"
@@ -53,13 +70,13 @@ func HandleValidationNetworkPolicy(v *networkpolicy.NetworkPolicyValidator) http
 			klog.Errorf("CRD validation received incorrect body")
 			admissionResponse = networkpolicy.GetAdmissionResponseForErr(err)
 		} else {
-			admissionResponse = v.Validate(&ar)
+			admissionResponse, warnings = v.Validate(&ar)
 		}
-		aReview := admv1.AdmissionReview{}
+		aReview := admissionReviewWithWarnings{}
 		aReview.TypeMeta.Kind = "AdmissionReview"
 		aReview.TypeMeta.APIVersion = "admission.k8s.io/v1"
 		if admissionResponse != nil {
-			aReview.Response = admissionResponse
+			aReview.Response = &admissionResponseWithWarnings{AdmissionResponse: admissionResponse, Warnings: warnings}
 			if ar.Request != nil {
 				aReview.Response.UID = ar.Request.UID
 			}
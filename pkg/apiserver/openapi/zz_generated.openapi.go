@@ -47,8 +47,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.ExternalEntityReference":           schema_pkg_apis_controlplane_v1beta1_ExternalEntityReference(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.GroupMember":                       schema_pkg_apis_controlplane_v1beta1_GroupMember(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.GroupMemberPod":                    schema_pkg_apis_controlplane_v1beta1_GroupMemberPod(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.HTTPProtocol":                      schema_pkg_apis_controlplane_v1beta1_HTTPProtocol(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.IPBlock":                           schema_pkg_apis_controlplane_v1beta1_IPBlock(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.IPNet":                             schema_pkg_apis_controlplane_v1beta1_IPNet(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.L7Protocol":                        schema_pkg_apis_controlplane_v1beta1_L7Protocol(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NamedPort":                         schema_pkg_apis_controlplane_v1beta1_NamedPort(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NetworkPolicy":                     schema_pkg_apis_controlplane_v1beta1_NetworkPolicy(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NetworkPolicyList":                 schema_pkg_apis_controlplane_v1beta1_NetworkPolicyList(ref),
@@ -58,6 +60,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NetworkPolicyStats":                schema_pkg_apis_controlplane_v1beta1_NetworkPolicyStats(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NodeStatsSummary":                  schema_pkg_apis_controlplane_v1beta1_NodeStatsSummary(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.PodReference":                      schema_pkg_apis_controlplane_v1beta1_PodReference(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.RateLimit":                         schema_pkg_apis_controlplane_v1beta1_RateLimit(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.Service":                           schema_pkg_apis_controlplane_v1beta1_Service(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.AddressGroup":                      schema_pkg_apis_controlplane_v1beta2_AddressGroup(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.AddressGroupList":                  schema_pkg_apis_controlplane_v1beta2_AddressGroupList(ref),
@@ -67,8 +70,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.AppliedToGroupPatch":               schema_pkg_apis_controlplane_v1beta2_AppliedToGroupPatch(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.ExternalEntityReference":           schema_pkg_apis_controlplane_v1beta2_ExternalEntityReference(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.GroupMember":                       schema_pkg_apis_controlplane_v1beta2_GroupMember(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.HTTPProtocol":                      schema_pkg_apis_controlplane_v1beta2_HTTPProtocol(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.IPBlock":                           schema_pkg_apis_controlplane_v1beta2_IPBlock(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.IPNet":                             schema_pkg_apis_controlplane_v1beta2_IPNet(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.L7Protocol":                        schema_pkg_apis_controlplane_v1beta2_L7Protocol(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NamedPort":                         schema_pkg_apis_controlplane_v1beta2_NamedPort(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicy":                     schema_pkg_apis_controlplane_v1beta2_NetworkPolicy(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyList":                 schema_pkg_apis_controlplane_v1beta2_NetworkPolicyList(ref),
@@ -78,8 +83,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyRule":                 schema_pkg_apis_controlplane_v1beta2_NetworkPolicyRule(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyStats":                schema_pkg_apis_controlplane_v1beta2_NetworkPolicyStats(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyStatus":               schema_pkg_apis_controlplane_v1beta2_NetworkPolicyStatus(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NodeReference":                     schema_pkg_apis_controlplane_v1beta2_NodeReference(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NodeStatsSummary":                  schema_pkg_apis_controlplane_v1beta2_NodeStatsSummary(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.PodReference":                      schema_pkg_apis_controlplane_v1beta2_PodReference(ref),
+		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.RateLimit":                         schema_pkg_apis_controlplane_v1beta2_RateLimit(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.Service":                           schema_pkg_apis_controlplane_v1beta2_Service(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/stats/v1alpha1.AntreaClusterNetworkPolicyStats":         schema_pkg_apis_stats_v1alpha1_AntreaClusterNetworkPolicyStats(ref),
 		"github.com/vmware-tanzu/antrea/pkg/apis/stats/v1alpha1.AntreaClusterNetworkPolicyStatsList":     schema_pkg_apis_stats_v1alpha1_AntreaClusterNetworkPolicyStatsList(ref),
@@ -1315,6 +1322,40 @@ func schema_pkg_apis_controlplane_v1beta1_GroupMemberPod(ref common.ReferenceCal
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta1_HTTPProtocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HTTPProtocol matches HTTP requests. An empty field matches any value.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"method": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Method is the method of the request.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"host": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Host is the host of the request. It matches any subdomain of a domain if it starts with \"*.\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the path of the request. It matches any path starting with a prefix if it ends with \"*\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta1_IPBlock(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1375,6 +1416,27 @@ func schema_pkg_apis_controlplane_v1beta1_IPNet(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta1_L7Protocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "L7Protocol describes an application layer protocol match.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"http": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTP matches HTTP requests.",
+							Ref:         ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.HTTPProtocol"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.HTTPProtocol"},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta1_NamedPort(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1679,12 +1741,31 @@ func schema_pkg_apis_controlplane_v1beta1_NetworkPolicyRule(ref common.Reference
 							Format:      "",
 						},
 					},
+					"rateLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimit limits the rate of the traffic matched by the rule. nil means the traffic is not limited.",
+							Ref:         ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.RateLimit"),
+						},
+					},
+					"l7Protocols": {
+						SchemaProps: spec.SchemaProps{
+							Description: "L7Protocols restricts the traffic allowed by the rule to the application layer requests matching any of these protocols. Empty means the traffic is not restricted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.L7Protocol"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"enableLogging"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NetworkPolicyPeer", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.Service"},
+			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.L7Protocol", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.NetworkPolicyPeer", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.RateLimit", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta1.Service"},
 	}
 }
 
@@ -1815,6 +1896,40 @@ func schema_pkg_apis_controlplane_v1beta1_PodReference(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta1_RateLimit(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RateLimit describes the limits on the rate of the traffic matched by a rule. A zero value means the corresponding rate is not limited.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"connectionsPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConnectionsPerSecond is the maximum rate of new connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"packetsPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PacketsPerSecond is the maximum rate of packets of the connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bytesPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BytesPerSecond is the maximum rate of bytes of the connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta1_Service(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2219,11 +2334,51 @@ func schema_pkg_apis_controlplane_v1beta2_GroupMember(ref common.ReferenceCallba
 							},
 						},
 					},
+					"node": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Node maintains the reference to the Node. It's only set in AppliedToGroups selecting Nodes, whose NetworkPolicies are enforced on the Node's host traffic.",
+							Ref:         ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NodeReference"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.ExternalEntityReference", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NamedPort", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.PodReference"},
+			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.ExternalEntityReference", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NamedPort", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NodeReference", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.PodReference"},
+	}
+}
+
+func schema_pkg_apis_controlplane_v1beta2_HTTPProtocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HTTPProtocol matches HTTP requests. An empty field matches any value.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"method": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Method is the method of the request.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"host": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Host is the host of the request. It matches any subdomain of a domain if it starts with \"*.\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the path of the request. It matches any path starting with a prefix if it ends with \"*\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -2287,6 +2442,27 @@ func schema_pkg_apis_controlplane_v1beta2_IPNet(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta2_L7Protocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "L7Protocol describes an application layer protocol match.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"http": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTP matches HTTP requests.",
+							Ref:         ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.HTTPProtocol"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.HTTPProtocol"},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta2_NamedPort(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2618,12 +2794,31 @@ func schema_pkg_apis_controlplane_v1beta2_NetworkPolicyRule(ref common.Reference
 							Format:      "",
 						},
 					},
+					"rateLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimit limits the rate of the traffic matched by the rule. nil means the traffic is not limited.",
+							Ref:         ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.RateLimit"),
+						},
+					},
+					"l7Protocols": {
+						SchemaProps: spec.SchemaProps{
+							Description: "L7Protocols restricts the traffic allowed by the rule to the application layer requests matching any of these protocols. Empty means the traffic is not restricted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.L7Protocol"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"enableLogging"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyPeer", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.Service"},
+			"github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.L7Protocol", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyPeer", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.RateLimit", "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2.Service"},
 	}
 }
 
@@ -2701,6 +2896,26 @@ func schema_pkg_apis_controlplane_v1beta2_NetworkPolicyStatus(ref common.Referen
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta2_NodeReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NodeReference represents a Node Reference.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of this Node.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta2_NodeStatsSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2801,6 +3016,40 @@ func schema_pkg_apis_controlplane_v1beta2_PodReference(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_controlplane_v1beta2_RateLimit(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RateLimit describes the limits on the rate of the traffic matched by a rule. A zero value means the corresponding rate is not limited.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"connectionsPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConnectionsPerSecond is the maximum rate of new connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"packetsPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PacketsPerSecond is the maximum rate of packets of the connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bytesPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BytesPerSecond is the maximum rate of bytes of the connections.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_controlplane_v1beta2_Service(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3121,6 +3370,13 @@ func schema_pkg_apis_stats_v1alpha1_TrafficStats(ref common.ReferenceCallback) c
 							Format:      "int64",
 						},
 					},
+					"rateLimitedPackets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimitedPackets is the packets count dropped by the rate limits of the NetworkPolicy's rules.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
@@ -76,16 +76,27 @@ func (r *REST) List(ctx context.Context, options *internalversion.ListOptions) (
 	if options != nil && options.LabelSelector != nil {
 		labelSelector = options.LabelSelector
 	}
-	addressGroups := r.addressGroupStore.List()
+	limit, continueToken := networkpolicy.GetPageOptions(options)
+	// The objects are filtered by the store before the page is cut, so that the pages are full as long as there are
+	// matching objects.
+	filter := func(obj interface{}) bool {
+		var item controlplane.AddressGroup
+		store.ToAddressGroupMsg(obj.(*types.AddressGroup), &item, false)
+		return labelSelector.Matches(labels.Set(item.Labels))
+	}
+	addressGroups, resourceVersion, nextContinueToken, err := r.addressGroupStore.ListPage(limit, continueToken, filter)
+	if err != nil {
+		return nil, err
+	}
 	items := make([]controlplane.AddressGroup, 0, len(addressGroups))
 	for i := range addressGroups {
 		var item controlplane.AddressGroup
 		store.ToAddressGroupMsg(addressGroups[i].(*types.AddressGroup), &item, true)
-		if labelSelector.Matches(labels.Set(item.Labels)) {
-			items = append(items, item)
-		}
+		items = append(items, item)
 	}
 	list := &controlplane.AddressGroupList{Items: items}
+	list.ResourceVersion = resourceVersion
+	list.Continue = nextContinueToken
 	return list, nil
 }
 
@@ -95,7 +106,7 @@ func (r *REST) NamespaceScoped() bool {
 
 func (r *REST) Watch(ctx context.Context, options *internalversion.ListOptions) (watch.Interface, error) {
 	key, label, field := networkpolicy.GetSelectors(options)
-	return r.addressGroupStore.Watch(ctx, key, label, field)
+	return r.addressGroupStore.Watch(ctx, key, label, field, networkpolicy.GetWatchOptions(options))
 }
 
 func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
@@ -78,3 +78,25 @@ func TestRESTList(t *testing.T) {
 		})
 	}
 }
+
+func TestRESTListPagination(t *testing.T) {
+	storage := store.NewAddressGroupStore()
+	for _, name := range []string{"foo", "bar", "baz"} {
+		storage.Create(&types.AddressGroup{Name: name})
+	}
+	r := NewREST(storage)
+
+	actualObj, err := r.List(context.TODO(), &internalversion.ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	list := actualObj.(*controlplane.AddressGroupList)
+	assert.Equal(t, []controlplane.AddressGroup{{ObjectMeta: v1.ObjectMeta{Name: "bar"}}, {ObjectMeta: v1.ObjectMeta{Name: "baz"}}}, list.Items)
+	assert.NotEmpty(t, list.ResourceVersion)
+	assert.NotEmpty(t, list.Continue)
+
+	actualObj, err = r.List(context.TODO(), &internalversion.ListOptions{Limit: 2, Continue: list.Continue})
+	assert.NoError(t, err)
+	nextList := actualObj.(*controlplane.AddressGroupList)
+	assert.Equal(t, []controlplane.AddressGroup{{ObjectMeta: v1.ObjectMeta{Name: "foo"}}}, nextList.Items)
+	assert.Equal(t, list.ResourceVersion, nextList.ResourceVersion)
+	assert.Empty(t, nextList.Continue)
+}
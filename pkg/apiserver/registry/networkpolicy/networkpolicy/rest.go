@@ -76,16 +76,27 @@ func (r *REST) List(ctx context.Context, options *internalversion.ListOptions) (
 	if options != nil && options.LabelSelector != nil {
 		labelSelector = options.LabelSelector
 	}
-	networkPolicies := r.networkPolicyStore.List()
+	limit, continueToken := networkpolicy.GetPageOptions(options)
+	// The objects are filtered by the store before the page is cut, so that the pages are full as long as there are
+	// matching objects.
+	filter := func(obj interface{}) bool {
+		var item controlplane.NetworkPolicy
+		store.ToNetworkPolicyMsg(obj.(*types.NetworkPolicy), &item, false)
+		return labelSelector.Matches(labels.Set(item.Labels))
+	}
+	networkPolicies, resourceVersion, nextContinueToken, err := r.networkPolicyStore.ListPage(limit, continueToken, filter)
+	if err != nil {
+		return nil, err
+	}
 	items := make([]controlplane.NetworkPolicy, 0, len(networkPolicies))
 	for i := range networkPolicies {
 		var item controlplane.NetworkPolicy
 		store.ToNetworkPolicyMsg(networkPolicies[i].(*types.NetworkPolicy), &item, true)
-		if labelSelector.Matches(labels.Set(item.Labels)) {
-			items = append(items, item)
-		}
+		items = append(items, item)
 	}
 	list := &controlplane.NetworkPolicyList{Items: items}
+	list.ResourceVersion = resourceVersion
+	list.Continue = nextContinueToken
 	return list, nil
 }
 
@@ -95,7 +106,7 @@ func (r *REST) NamespaceScoped() bool {
 
 func (r *REST) Watch(ctx context.Context, options *internalversion.ListOptions) (watch.Interface, error) {
 	key, label, field := networkpolicy.GetSelectors(options)
-	return r.networkPolicyStore.Watch(ctx, key, label, field)
+	return r.networkPolicyStore.Watch(ctx, key, label, field, networkpolicy.GetWatchOptions(options))
 }
 
 func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
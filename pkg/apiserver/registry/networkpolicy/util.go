@@ -18,6 +18,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
 )
 
 // GetSelectors extracts label selector, field selector, and key selector from the provided options.
@@ -33,3 +35,19 @@ func GetSelectors(options *internalversion.ListOptions) (string, labels.Selector
 	key, _ := field.RequiresExactMatch("metadata.name")
 	return key, label, field
 }
+
+// GetWatchOptions extracts the resourceVersion to resume from and whether bookmarks are allowed from the provided options.
+func GetWatchOptions(options *internalversion.ListOptions) storage.WatchOptions {
+	if options == nil {
+		return storage.WatchOptions{}
+	}
+	return storage.WatchOptions{ResourceVersion: options.ResourceVersion, AllowBookmarks: options.AllowWatchBookmarks}
+}
+
+// GetPageOptions extracts the limit and the continue token of a paginated list from the provided options.
+func GetPageOptions(options *internalversion.ListOptions) (int64, string) {
+	if options == nil {
+		return 0, ""
+	}
+	return options.Limit, options.Continue
+}
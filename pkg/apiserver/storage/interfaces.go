@@ -32,6 +32,18 @@ type Selectors struct {
 	Field fields.Selector
 }
 
+// WatchOptions represent the options of a watcher.
+type WatchOptions struct {
+	// ResourceVersion is the resourceVersion from which the watcher resumes. If it's empty or "0", the watcher first
+	// receives all the selected objects as init events followed by a bookmark event. Otherwise, the watcher first
+	// receives a bookmark event with the same resourceVersion followed by the events it missed since then.
+	ResourceVersion string
+	// AllowBookmarks indicates whether the watcher accepts periodic bookmark events. When it's set, all bookmark events
+	// carry the resourceVersion up to which the events have been sent to the watcher, so that it can resume from there
+	// after reconnecting.
+	AllowBookmarks bool
+}
+
 // InternalEvent is an internal event that can be converted to *watch.Event based on watcher's Selectors.
 // For example, an internal event may be converted to an ADDED event for one watcher, and to a MODIFIED event
 // for another.
@@ -73,11 +85,19 @@ type Interface interface {
 	// List gets a list of all objects.
 	List() []interface{}
 
+	// ListPage gets a list of at most limit objects ordered by key which match the provided filter, starting after the
+	// position encoded in the provided continue token, or from the first object if it's empty. The filter is applied
+	// before the list is cut, and all objects match it if it's nil. It returns the objects, the resourceVersion of the
+	// list and the continue token of the next page, which is empty if there are no more objects. All matching objects
+	// are returned if limit is not positive.
+	ListPage(limit int64, continueToken string, filter func(obj interface{}) bool) ([]interface{}, string, string, error)
+
 	// Delete removes an object that has specified key.
 	Delete(key string) error
 
-	// Watch starts watching with the specified key and selectors. Events will be sent to the returned watch.Interface.
-	Watch(ctx context.Context, key string, labelSelector labels.Selector, fieldSelector fields.Selector) (watch.Interface, error)
+	// Watch starts watching with the specified key, selectors and options. Events will be sent to the returned
+	// watch.Interface. It returns a ResourceExpired error if the watcher can't resume from the provided resourceVersion.
+	Watch(ctx context.Context, key string, labelSelector labels.Selector, fieldSelector fields.Selector, options WatchOptions) (watch.Interface, error)
 
 	// GetWatchersNum gets the number of watchers for the store.
 	GetWatchersNum() int
@@ -16,13 +16,19 @@ package ram
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/storage"
 	"k8s.io/client-go/tools/cache"
@@ -37,11 +43,29 @@ const (
 	// watcherAddTimeout is the timeout of sending one event to all watchers.
 	// Watchers whose buffer can't be available in it will be terminated.
 	watcherAddTimeout = 50 * time.Millisecond
+	// eventHistorySize is the number of latest events kept to resume watchers
+	// which reconnect with a resourceVersion.
+	eventHistorySize = 1000
 )
 
+// continueToken is the decoded form of the continue token of a paginated list.
+type continueToken struct {
+	// ResourceVersion is the resourceVersion of the first page, which is
+	// returned for all the pages so that a watch started after the list
+	// doesn't miss the events of the objects listed in the earlier pages.
+	ResourceVersion string `json:"rv"`
+	// StartKey is the key of the last object of the previous page.
+	StartKey string `json:"start"`
+}
+
 type watchersMap map[int]*storeWatcher
 
 // store implements ram.Interface, serving the requests for a given resource from its internal cache storage.
+// The watchers reconnecting with a recent resourceVersion only receive the events they missed, which are kept in a
+// bounded history. The resourceVersions are only meaningful to the store which generated them: a watcher
+// reconnecting to another store, e.g. to another antrea-controller replica after a failover or to a restarted
+// antrea-controller, is rejected with ResourceExpired even if the store was built from the same input, and must
+// receive all the objects again.
 type store struct {
 	// watcherMutex protects the watchers map from concurrent access during watcher insertion and deletion.
 	watcherMutex sync.RWMutex
@@ -66,6 +90,12 @@ type store struct {
 
 	// resourceVersion up to which the store has generated.
 	resourceVersion uint64
+	// history keeps the latest events, at most eventHistorySize, in the order
+	// of their resourceVersions.
+	history []antreastorage.InternalEvent
+	// historyStartVersion is the resourceVersion after which all events are
+	// kept in history.
+	historyStartVersion uint64
 	// watcherIdx is the index that will be allocated to next watcher and used as key in watchersMap
 	// so that a watcher can be deleted from the map according to its index later.
 	watcherIdx int
@@ -90,16 +120,22 @@ func NewStore(keyFunc cache.KeyFunc, indexers cache.Indexers, genEventFunc antre
 	if !timer.Stop() {
 		<-timer.C
 	}
+	// Start from a random resourceVersion so that the resourceVersions of a
+	// restarted or another antrea-controller can't be mistaken for ones of
+	// this store when watchers try to resume from them.
+	initialResourceVersion := uint64(rand.Int63nRange(1, 1<<31)) << 32
 	s := &store{
-		incoming:     make(chan antreastorage.InternalEvent, 100),
-		storage:      storage,
-		stopCh:       stopCh,
-		watchers:     make(map[int]*storeWatcher),
-		keyFunc:      keyFunc,
-		genEventFunc: genEventFunc,
-		selectFunc:   selectorFunc,
-		timer:        timer,
-		newFunc:      newFunc,
+		resourceVersion:     initialResourceVersion,
+		historyStartVersion: initialResourceVersion,
+		incoming:            make(chan antreastorage.InternalEvent, 100),
+		storage:             storage,
+		stopCh:              stopCh,
+		watchers:            make(map[int]*storeWatcher),
+		keyFunc:             keyFunc,
+		genEventFunc:        genEventFunc,
+		selectFunc:          selectorFunc,
+		timer:               timer,
+		newFunc:             newFunc,
 	}
 
 	go s.dispatchEvents()
@@ -113,7 +149,15 @@ func (s *store) nextResourceVersion() uint64 {
 	return s.resourceVersion
 }
 
+// processEvent records the event in history and queues it for dispatching.
+// It should be called while holding a lock on eventMutex.
 func (s *store) processEvent(event antreastorage.InternalEvent) {
+	if len(s.history) == eventHistorySize {
+		s.historyStartVersion = s.history[0].GetResourceVersion()
+		s.history[0] = nil
+		s.history = s.history[1:]
+	}
+	s.history = append(s.history, event)
 	if curLen := int64(len(s.incoming)); s.incomingHWM.Update(curLen) {
 		// Monitor if this gets backed up, and how much.
 		klog.V(1).Infof("%v objects queued in incoming channel", curLen)
@@ -196,6 +240,51 @@ func (s *store) List() []interface{} {
 	return s.storage.List()
 }
 
+// ListPage returns a page of the objects matching the filter ordered by their
+// keys. The pages are not a consistent snapshot: an object which changes
+// between two pages is returned in its latest state. The resourceVersion of
+// the first page is returned for all pages, the watchers starting from it
+// receive the events of the objects which change after it.
+func (s *store) ListPage(limit int64, continueValue string, filter func(obj interface{}) bool) ([]interface{}, string, string, error) {
+	s.eventMutex.RLock()
+	defer s.eventMutex.RUnlock()
+
+	token := continueToken{ResourceVersion: strconv.FormatUint(s.resourceVersion, 10)}
+	if continueValue != "" {
+		data, err := base64.RawURLEncoding.DecodeString(continueValue)
+		if err == nil {
+			err = json.Unmarshal(data, &token)
+		}
+		if err != nil || token.StartKey == "" {
+			return nil, "", "", errors.NewBadRequest(fmt.Sprintf("invalid continue token %q", continueValue))
+		}
+	}
+
+	keys := s.storage.ListKeys()
+	sort.Strings(keys)
+	start := 0
+	if token.StartKey != "" {
+		start = sort.Search(len(keys), func(i int) bool { return keys[i] > token.StartKey })
+	}
+	var objs []interface{}
+	// end is the position following the last scanned key. The page is cut
+	// after the limit is reached, the next page starts from the next key even
+	// if none of the following objects match the filter.
+	end := start
+	for ; end < len(keys) && (limit <= 0 || int64(len(objs)) < limit); end++ {
+		obj, _, _ := s.storage.GetByKey(keys[end])
+		if filter == nil || filter(obj) {
+			objs = append(objs, obj)
+		}
+	}
+	if end == len(keys) {
+		return objs, token.ResourceVersion, "", nil
+	}
+	token.StartKey = keys[end-1]
+	data, _ := json.Marshal(&token)
+	return objs, token.ResourceVersion, base64.RawURLEncoding.EncodeToString(data), nil
+}
+
 // Delete deletes the object from internal cache storage.
 func (s *store) Delete(key string) error {
 	s.eventMutex.Lock()
@@ -221,8 +310,8 @@ func (s *store) Delete(key string) error {
 	return nil
 }
 
-// Watch creates a watcher based on the key, label selector and field selector.
-func (s *store) Watch(ctx context.Context, key string, labelSelector labels.Selector, fieldSelector fields.Selector) (watch.Interface, error) {
+// Watch creates a watcher based on the key, label selector, field selector and options.
+func (s *store) Watch(ctx context.Context, key string, labelSelector labels.Selector, fieldSelector fields.Selector, options antreastorage.WatchOptions) (watch.Interface, error) {
 	if s.genEventFunc == nil {
 		return nil, fmt.Errorf("genEventFunc must be set to support watching")
 	}
@@ -237,36 +326,56 @@ func (s *store) Watch(ctx context.Context, key string, labelSelector labels.Sele
 		Field: fieldSelector,
 	}
 
-	allObjects := s.storage.List()
-	initEvents := make([]antreastorage.InternalEvent, 0, len(allObjects))
-	for _, obj := range allObjects {
-		// Objects retrieved from storage have been verified with keyFunc when they are inserted.
-		key, _ := s.keyFunc(obj)
-		// Check whether the watcher is interested in this object, don't generate an initEvent if not.
-		if s.selectFunc != nil && !s.selectFunc(selectors, key, obj) {
-			continue
-		}
+	var initEvents, missedEvents []antreastorage.InternalEvent
+	bookmarkVersion := s.resourceVersion
+	if options.ResourceVersion == "" || options.ResourceVersion == "0" {
+		allObjects := s.storage.List()
+		initEvents = make([]antreastorage.InternalEvent, 0, len(allObjects))
+		for _, obj := range allObjects {
+			// Objects retrieved from storage have been verified with keyFunc when they are inserted.
+			key, _ := s.keyFunc(obj)
+			// Check whether the watcher is interested in this object, don't generate an initEvent if not.
+			if s.selectFunc != nil && !s.selectFunc(selectors, key, obj) {
+				continue
+			}
 
-		event, err := s.genEventFunc(key, nil, obj, s.resourceVersion)
+			event, err := s.genEventFunc(key, nil, obj, s.resourceVersion)
+			if err != nil {
+				return nil, err
+			}
+			initEvents = append(initEvents, event)
+		}
+	} else {
+		resourceVersion, err := strconv.ParseUint(options.ResourceVersion, 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, errors.NewBadRequest(fmt.Sprintf("invalid resourceVersion %q", options.ResourceVersion))
+		}
+		// The events after the resourceVersion must all be in history, which also rejects the resourceVersions of
+		// another store as they are generated from a different random start.
+		if resourceVersion < s.historyStartVersion || resourceVersion > s.resourceVersion {
+			return nil, errors.NewResourceExpired(fmt.Sprintf("too old resourceVersion %s", options.ResourceVersion))
 		}
-		initEvents = append(initEvents, event)
+		idx := sort.Search(len(s.history), func(i int) bool {
+			return s.history[i].GetResourceVersion() > resourceVersion
+		})
+		missedEvents = make([]antreastorage.InternalEvent, len(s.history)-idx)
+		copy(missedEvents, s.history[idx:])
+		bookmarkVersion = resourceVersion
 	}
 
 	watcher := func() *storeWatcher {
 		s.watcherMutex.Lock()
 		defer s.watcherMutex.Unlock()
 
-		w := newStoreWatcher(watcherChanSize, selectors, forgetWatcher(s, s.watcherIdx), s.newFunc)
+		w := newStoreWatcher(watcherChanSize, selectors, forgetWatcher(s, s.watcherIdx), s.newFunc, options.AllowBookmarks)
 		s.watchers[s.watcherIdx] = w
 		s.watcherIdx++
 		return w
 	}()
 
 	// Specify current resourceVersion so that old events that were currently buffered in incoming channel won't be
-	// delivered to the watcher twice when initEvents already have them.
-	go watcher.process(ctx, initEvents, s.resourceVersion)
+	// delivered to the watcher twice when initEvents or missedEvents already have them.
+	go watcher.process(ctx, initEvents, bookmarkVersion, missedEvents, s.resourceVersion)
 	return watcher, nil
 }
 
@@ -22,7 +22,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -312,7 +314,7 @@ func TestRamStoreWatchAll(t *testing.T) {
 	}
 	for i, testCase := range testCases {
 		store := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
-		w, err := store.Watch(context.Background(), "", labels.Everything(), fields.Everything())
+		w, err := store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{})
 		if err != nil {
 			t.Errorf("%d: failed to watch object: %v", i, err)
 		}
@@ -379,7 +381,7 @@ func TestRamStoreWatchWithInitOperations(t *testing.T) {
 		store := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
 		// Init the storage before watching
 		testCase.initOperations(store)
-		w, err := store.Watch(context.Background(), "", labels.Everything(), fields.Everything())
+		w, err := store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{})
 		if err != nil {
 			t.Errorf("%d: failed to watch object: %v", i, err)
 		}
@@ -448,7 +450,7 @@ func TestRamStoreWatchWithSelector(t *testing.T) {
 	}
 	for i, testCase := range testCases {
 		store := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
-		w, err := store.Watch(context.Background(), "", testCase.labelSelector, fields.Everything())
+		w, err := store.Watch(context.Background(), "", testCase.labelSelector, fields.Everything(), antreastorage.WatchOptions{})
 		if err != nil {
 			t.Errorf("%d: failed to watch object: %v", i, err)
 		}
@@ -474,7 +476,7 @@ func TestRamStoreWatchTimeout(t *testing.T) {
 	maxBuffered := watcherChanSize*2 + 1
 
 	// w1 has consumer for its result chan.
-	w1, err := store.Watch(context.Background(), "", labels.SelectorFromSet(labels.Set{"app": "nginx"}), fields.Everything())
+	w1, err := store.Watch(context.Background(), "", labels.SelectorFromSet(labels.Set{"app": "nginx"}), fields.Everything(), antreastorage.WatchOptions{})
 	if err != nil {
 		t.Errorf("Failed to watch object: %v", err)
 	}
@@ -497,7 +499,7 @@ func TestRamStoreWatchTimeout(t *testing.T) {
 	}()
 
 	// w2 has no consumer for its result chan.
-	w2, err := store.Watch(context.Background(), "", labels.SelectorFromSet(labels.Set{"app": "nginx"}), fields.Everything())
+	w2, err := store.Watch(context.Background(), "", labels.SelectorFromSet(labels.Set{"app": "nginx"}), fields.Everything(), antreastorage.WatchOptions{})
 	if err != nil {
 		t.Errorf("Failed to watch object: %v", err)
 	}
@@ -527,3 +529,137 @@ func TestRamStoreWatchTimeout(t *testing.T) {
 	}
 	assert.Equal(t, 1, store.GetWatchersNum(), "Unexpected watchers number")
 }
+
+func TestRamStoreListPage(t *testing.T) {
+	store := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
+	for i := 0; i < 5; i++ {
+		store.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod%d", i)}})
+	}
+	getNames := func(objs []interface{}) []string {
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.(*v1.Pod).Name)
+		}
+		return names
+	}
+
+	objs, resourceVersion, continueToken, err := store.ListPage(2, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod0", "pod1"}, getNames(objs))
+	require.NotEmpty(t, continueToken)
+
+	// Objects changed between pages are returned in their latest state, with the resourceVersion of the first page.
+	store.Delete("pod2")
+	store.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod5"}})
+	objs, nextResourceVersion, continueToken, err := store.ListPage(2, continueToken, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod3", "pod4"}, getNames(objs))
+	assert.Equal(t, resourceVersion, nextResourceVersion)
+	require.NotEmpty(t, continueToken)
+
+	objs, _, continueToken, err = store.ListPage(2, continueToken, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod5"}, getNames(objs))
+	assert.Empty(t, continueToken)
+
+	objs, _, continueToken, err = store.ListPage(0, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod0", "pod1", "pod3", "pod4", "pod5"}, getNames(objs))
+	assert.Empty(t, continueToken)
+
+	// The objects are filtered before the page is cut.
+	filter := func(obj interface{}) bool { return obj.(*v1.Pod).Name != "pod1" && obj.(*v1.Pod).Name != "pod3" }
+	objs, _, continueToken, err = store.ListPage(2, "", filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod0", "pod4"}, getNames(objs))
+	require.NotEmpty(t, continueToken)
+	objs, _, continueToken, err = store.ListPage(2, continueToken, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod5"}, getNames(objs))
+	assert.Empty(t, continueToken)
+
+	_, _, _, err = store.ListPage(2, "invalid", nil)
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestRamStoreWatchResume(t *testing.T) {
+	store := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
+	store.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}})
+	w, err := store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{AllowBookmarks: true})
+	require.NoError(t, err)
+	assert.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+	bookmark := <-w.ResultChan()
+	require.Equal(t, watch.Bookmark, bookmark.Type)
+	resourceVersion := bookmark.Object.(*v1.Pod).ResourceVersion
+	require.NotEmpty(t, resourceVersion)
+	w.Stop()
+
+	// The events generated while the watcher is down.
+	store.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}})
+	store.Delete("pod1")
+
+	w, err = store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: resourceVersion, AllowBookmarks: true})
+	require.NoError(t, err)
+	expected := []watch.Event{
+		{Type: watch.Bookmark, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: resourceVersion}}},
+		{Type: watch.Added, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}}},
+		{Type: watch.Deleted, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}},
+	}
+	for _, expectedEvent := range expected {
+		assert.Equal(t, expectedEvent, <-w.ResultChan())
+	}
+	select {
+	case obj, ok := <-w.ResultChan():
+		t.Errorf("Unexpected excess event: %#v %t", obj, ok)
+	case <-time.After(100 * time.Millisecond):
+	}
+	w.Stop()
+
+	// The events after the resourceVersion are no longer all kept.
+	for i := 0; i < eventHistorySize; i++ {
+		store.Update(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Labels: map[string]string{"index": fmt.Sprint(i)}}})
+	}
+	_, err = store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: resourceVersion})
+	assert.True(t, errors.IsResourceExpired(err))
+	// The resourceVersion of another store.
+	_, err = store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: "1"})
+	assert.True(t, errors.IsResourceExpired(err))
+	_, err = store.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: "invalid"})
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestRamStoreWatchResumeAcrossStores(t *testing.T) {
+	newTestStore := func() *store {
+		s := NewStore(cache.MetaNamespaceKeyFunc, cache.Indexers{}, testGenEvent, testSelectFunc, func() runtime.Object { return new(v1.Pod) })
+		s.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}})
+		s.Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}})
+		return s
+	}
+	// Two stores built from the same input, like the stores of two antrea-controller replicas.
+	store1, store2 := newTestStore(), newTestStore()
+	w, err := store1.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{AllowBookmarks: true})
+	require.NoError(t, err)
+	assert.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+	assert.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+	bookmark := <-w.ResultChan()
+	require.Equal(t, watch.Bookmark, bookmark.Type)
+	resourceVersion := bookmark.Object.(*v1.Pod).ResourceVersion
+	w.Stop()
+
+	// The watch can't be resumed from the resourceVersion of the other store, the watcher must receive all the
+	// objects again.
+	_, err = store2.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: resourceVersion, AllowBookmarks: true})
+	assert.True(t, errors.IsResourceExpired(err))
+	w, err = store2.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{AllowBookmarks: true})
+	require.NoError(t, err)
+	assert.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+	assert.Equal(t, watch.Added, (<-w.ResultChan()).Type)
+	assert.Equal(t, watch.Bookmark, (<-w.ResultChan()).Type)
+	w.Stop()
+
+	// The watch can still be resumed from the store which generated the resourceVersion.
+	w, err = store1.Watch(context.Background(), "", labels.Everything(), fields.Everything(), antreastorage.WatchOptions{ResourceVersion: resourceVersion, AllowBookmarks: true})
+	require.NoError(t, err)
+	assert.Equal(t, watch.Event{Type: watch.Bookmark, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: resourceVersion}}}, <-w.ResultChan())
+	w.Stop()
+}
@@ -16,9 +16,11 @@ package ram
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/klog"
@@ -26,6 +28,10 @@ import (
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
 )
 
+// bookmarkInterval is the interval at which bookmark events are sent to the
+// watchers which allow them. Declared as a variable for testing.
+var bookmarkInterval = time.Minute
+
 type bookmarkEvent struct {
 	resourceVersion uint64
 	object          runtime.Object
@@ -54,16 +60,20 @@ type storeWatcher struct {
 	stopOnce sync.Once
 	// newFunc is a function that creates new empty object of this type.
 	newFunc func() runtime.Object
+	// allowBookmarks indicates whether periodic bookmark events are sent to the watcher and whether bookmark events
+	// carry resourceVersions.
+	allowBookmarks bool
 }
 
-func newStoreWatcher(chanSize int, selectors *storage.Selectors, forget func(), newFunc func() runtime.Object) *storeWatcher {
+func newStoreWatcher(chanSize int, selectors *storage.Selectors, forget func(), newFunc func() runtime.Object, allowBookmarks bool) *storeWatcher {
 	return &storeWatcher{
-		input:     make(chan storage.InternalEvent, chanSize),
-		result:    make(chan watch.Event, chanSize),
-		done:      make(chan struct{}),
-		selectors: selectors,
-		forget:    forget,
-		newFunc:   newFunc,
+		input:          make(chan storage.InternalEvent, chanSize),
+		result:         make(chan watch.Event, chanSize),
+		done:           make(chan struct{}),
+		selectors:      selectors,
+		forget:         forget,
+		newFunc:        newFunc,
+		allowBookmarks: allowBookmarks,
 	}
 }
 
@@ -100,21 +110,46 @@ func (w *storeWatcher) add(event storage.InternalEvent, timer *time.Timer) bool
 	}
 }
 
-// process first sends initEvents and then keeps sending events got from channel input
-// if they are newer than the specified resourceVersion.
-func (w *storeWatcher) process(ctx context.Context, initEvents []storage.InternalEvent, resourceVersion uint64) {
+// newBookmarkEvent creates a bookmark event. It carries the provided resourceVersion if the watcher allows bookmarks.
+func (w *storeWatcher) newBookmarkEvent(resourceVersion uint64) *bookmarkEvent {
+	obj := w.newFunc()
+	if w.allowBookmarks {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetResourceVersion(strconv.FormatUint(resourceVersion, 10))
+		}
+	}
+	return &bookmarkEvent{resourceVersion, obj}
+}
+
+// process first sends initEvents, a bookmark event with bookmarkVersion and missedEvents, and then keeps sending
+// events got from channel input if they are newer than the specified resourceVersion.
+func (w *storeWatcher) process(ctx context.Context, initEvents []storage.InternalEvent, bookmarkVersion uint64, missedEvents []storage.InternalEvent, resourceVersion uint64) {
 	for _, event := range initEvents {
 		w.sendWatchEvent(event, true)
 	}
-	// Send a dummy bookmark event to indicate the end of initEvents. This is
+	// Send a bookmark event to indicate the end of initEvents. This is
 	// an unusual way to use the bookmark event, as it is meant to be used to
-	// refresh the last resource version of a client. In Antrea we do not use
-	// resource version when restarting a watch, but we need a way to
+	// refresh the last resource version of a client, but we need a way to
 	// communicate to clients what the initial set of objects is, so that
 	// stale objects whose delete events were missed by the client (because
-	// the watch was down) can be deleted.
-	w.sendWatchEvent(&bookmarkEvent{resourceVersion, w.newFunc()}, true)
+	// the watch was down) can be deleted. When the watcher resumes from a
+	// resourceVersion, there are no initEvents and the bookmark event carries
+	// the same resourceVersion, telling the client that it will only receive
+	// the events it missed.
+	w.sendWatchEvent(w.newBookmarkEvent(bookmarkVersion), true)
+	for _, event := range missedEvents {
+		w.sendWatchEvent(event, false)
+	}
 	defer close(w.result)
+
+	// lastVersion is the resourceVersion of the last event processed by the watcher.
+	lastVersion := resourceVersion
+	var bookmarkCh <-chan time.Time
+	if w.allowBookmarks {
+		ticker := time.NewTicker(bookmarkInterval)
+		defer ticker.Stop()
+		bookmarkCh = ticker.C
+	}
 	for {
 		select {
 		case event, ok := <-w.input:
@@ -124,7 +159,10 @@ func (w *storeWatcher) process(ctx context.Context, initEvents []storage.Interna
 			}
 			if event.GetResourceVersion() > resourceVersion {
 				w.sendWatchEvent(event, false)
+				lastVersion = event.GetResourceVersion()
 			}
+		case <-bookmarkCh:
+			w.sendWatchEvent(w.newBookmarkEvent(lastVersion), false)
 		case <-ctx.Done():
 			klog.V(4).Info("The context has been canceled, stopping process for watcher")
 			return
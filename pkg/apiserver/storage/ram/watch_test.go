@@ -146,8 +146,8 @@ func TestEvents(t *testing.T) {
 	}
 
 	for i, testCase := range testCases {
-		w := newStoreWatcher(10, &storage.Selectors{}, func() {}, func() runtime.Object { return new(v1.Pod) })
-		go w.process(context.Background(), testCase.initEvents, 0)
+		w := newStoreWatcher(10, &storage.Selectors{}, func() {}, func() runtime.Object { return new(v1.Pod) }, false)
+		go w.process(context.Background(), testCase.initEvents, 0, nil, 0)
 
 		for _, event := range testCase.addedEvents {
 			w.nonBlockingAdd(event)
@@ -169,7 +169,7 @@ func TestEvents(t *testing.T) {
 }
 
 func TestAddTimeout(t *testing.T) {
-	w := newStoreWatcher(1, &storage.Selectors{}, func() {}, func() runtime.Object { return new(v1.Pod) })
+	w := newStoreWatcher(1, &storage.Selectors{}, func() {}, func() runtime.Object { return new(v1.Pod) }, false)
 	events := []storage.InternalEvent{
 		&simpleInternalEvent{
 			Type:            watch.Added,
@@ -192,3 +192,34 @@ func TestAddTimeout(t *testing.T) {
 		t.Error("add() succeeded, expected failure")
 	}
 }
+
+func TestPeriodicBookmarks(t *testing.T) {
+	defer func(interval time.Duration) {
+		bookmarkInterval = interval
+	}(bookmarkInterval)
+	bookmarkInterval = 100 * time.Millisecond
+
+	w := newStoreWatcher(10, &storage.Selectors{}, func() {}, func() runtime.Object { return new(v1.Pod) }, true)
+	defer w.Stop()
+	go w.process(context.Background(), nil, 5, nil, 5)
+	w.nonBlockingAdd(&simpleInternalEvent{
+		Type:            watch.Added,
+		Object:          &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}},
+		ResourceVersion: 6,
+	})
+	expected := []watch.Event{
+		{Type: watch.Bookmark, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "5"}}},
+		{Type: watch.Added, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}},
+		{Type: watch.Bookmark, Object: &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "6"}}},
+	}
+	for i, expectedEvent := range expected {
+		select {
+		case actualEvent := <-w.ResultChan():
+			if !reflect.DeepEqual(actualEvent, expectedEvent) {
+				t.Errorf("Unexpected event %d: %#v", i, actualEvent)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected event %d, got none", i)
+		}
+	}
+}
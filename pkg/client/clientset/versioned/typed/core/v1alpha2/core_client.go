@@ -24,7 +24,10 @@ import (
 
 type CoreV1alpha2Interface interface {
 	RESTClient() rest.Interface
+	BGPPoliciesGetter
 	ExternalEntitiesGetter
+	ExternalIPPoolsGetter
+	IPPoolsGetter
 }
 
 // CoreV1alpha2Client is used to interact with features provided by the core.antrea.tanzu.vmware.com group.
@@ -32,10 +35,22 @@ type CoreV1alpha2Client struct {
 	restClient rest.Interface
 }
 
+func (c *CoreV1alpha2Client) BGPPolicies() BGPPolicyInterface {
+	return newBGPPolicies(c)
+}
+
 func (c *CoreV1alpha2Client) ExternalEntities(namespace string) ExternalEntityInterface {
 	return newExternalEntities(c, namespace)
 }
 
+func (c *CoreV1alpha2Client) ExternalIPPools() ExternalIPPoolInterface {
+	return newExternalIPPools(c)
+}
+
+func (c *CoreV1alpha2Client) IPPools() IPPoolInterface {
+	return newIPPools(c)
+}
+
 // NewForConfig creates a new CoreV1alpha2Client for the given config.
 func NewForConfig(c *rest.Config) (*CoreV1alpha2Client, error) {
 	config := *c
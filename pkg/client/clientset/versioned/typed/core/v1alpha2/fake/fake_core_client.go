@@ -26,10 +26,22 @@ type FakeCoreV1alpha2 struct {
 	*testing.Fake
 }
 
+func (c *FakeCoreV1alpha2) BGPPolicies() v1alpha2.BGPPolicyInterface {
+	return &FakeBGPPolicies{c}
+}
+
 func (c *FakeCoreV1alpha2) ExternalEntities(namespace string) v1alpha2.ExternalEntityInterface {
 	return &FakeExternalEntities{c, namespace}
 }
 
+func (c *FakeCoreV1alpha2) ExternalIPPools() v1alpha2.ExternalIPPoolInterface {
+	return &FakeExternalIPPools{c}
+}
+
+func (c *FakeCoreV1alpha2) IPPools() v1alpha2.IPPoolInterface {
+	return &FakeIPPools{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeCoreV1alpha2) RESTClient() rest.Interface {
@@ -16,4 +16,10 @@
 
 package v1alpha2
 
+type BGPPolicyExpansion interface{}
+
 type ExternalEntityExpansion interface{}
+
+type ExternalIPPoolExpansion interface{}
+
+type IPPoolExpansion interface{}
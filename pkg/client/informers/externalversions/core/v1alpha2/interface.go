@@ -22,8 +22,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// BGPPolicies returns a BGPPolicyInformer.
+	BGPPolicies() BGPPolicyInformer
 	// ExternalEntities returns a ExternalEntityInformer.
 	ExternalEntities() ExternalEntityInformer
+	// ExternalIPPools returns a ExternalIPPoolInformer.
+	ExternalIPPools() ExternalIPPoolInformer
+	// IPPools returns a IPPoolInformer.
+	IPPools() IPPoolInformer
 }
 
 type version struct {
@@ -37,7 +43,22 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// BGPPolicies returns a BGPPolicyInformer.
+func (v *version) BGPPolicies() BGPPolicyInformer {
+	return &bGPPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ExternalEntities returns a ExternalEntityInformer.
 func (v *version) ExternalEntities() ExternalEntityInformer {
 	return &externalEntityInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// ExternalIPPools returns a ExternalIPPoolInformer.
+func (v *version) ExternalIPPools() ExternalIPPoolInformer {
+	return &externalIPPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// IPPools returns a IPPoolInformer.
+func (v *version) IPPools() IPPoolInformer {
+	return &iPPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -60,8 +60,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Clusterinformation().V1beta1().AntreaControllerInfos().Informer()}, nil
 
 		// Group=core.antrea.tanzu.vmware.com, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("bgppolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().BGPPolicies().Informer()}, nil
 	case v1alpha2.SchemeGroupVersion.WithResource("externalentities"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().ExternalEntities().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("externalippools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().ExternalIPPools().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("ippools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha2().IPPools().Informer()}, nil
 
 		// Group=ops.antrea.tanzu.vmware.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("traceflows"):
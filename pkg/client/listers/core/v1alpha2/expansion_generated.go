@@ -16,6 +16,10 @@
 
 package v1alpha2
 
+// BGPPolicyListerExpansion allows custom methods to be added to
+// BGPPolicyLister.
+type BGPPolicyListerExpansion interface{}
+
 // ExternalEntityListerExpansion allows custom methods to be added to
 // ExternalEntityLister.
 type ExternalEntityListerExpansion interface{}
@@ -23,3 +27,11 @@ type ExternalEntityListerExpansion interface{}
 // ExternalEntityNamespaceListerExpansion allows custom methods to be added to
 // ExternalEntityNamespaceLister.
 type ExternalEntityNamespaceListerExpansion interface{}
+
+// ExternalIPPoolListerExpansion allows custom methods to be added to
+// ExternalIPPoolLister.
+type ExternalIPPoolListerExpansion interface{}
+
+// IPPoolListerExpansion allows custom methods to be added to
+// IPPoolLister.
+type IPPoolListerExpansion interface{}
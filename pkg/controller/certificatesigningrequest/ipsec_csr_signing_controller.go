@@ -0,0 +1,427 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificatesigningrequest
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	certificatesinformers "k8s.io/client-go/informers/certificates/v1beta1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	certificateslisters "k8s.io/client-go/listers/certificates/v1beta1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+)
+
+const (
+	controllerName = "IPsecCSRSigningController"
+
+	// How long to wait before retrying the processing of a CSR.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+
+	// Default number of workers processing CSRs.
+	defaultWorkers = 2
+
+	// ipsecCASecretName is the name of the Secret which stores the CA
+	// certificate and private key used to sign the IPsec certificates.
+	ipsecCASecretName = "antrea-ipsec-ca"
+
+	// agentServiceAccountName is the name of the ServiceAccount used by
+	// antrea-agent, which is the only requester allowed to get IPsec
+	// certificates.
+	agentServiceAccountName = "antrea-agent"
+
+	// podNameExtraKey is the key of the Pod name in the extra information of
+	// the requester, which is set when the request is authenticated with a
+	// bound ServiceAccount token.
+	podNameExtraKey = "authentication.kubernetes.io/pod-name"
+)
+
+var (
+	// certificateDuration is the validity duration of the signed IPsec
+	// certificates. Declared as a variable for testing.
+	certificateDuration = 365 * 24 * time.Hour
+	// caRetryInterval is the interval of retrying to load the CA.
+	// Declared as a variable for testing.
+	caRetryInterval = 5 * time.Second
+)
+
+// IPsecCSRSigningController approves and signs the CertificateSigningRequests
+// created by antrea-agents for their IPsec tunnels, with a self-signed CA
+// stored in a Secret. The CA certificate is published in a ConfigMap, from
+// which antrea-agents and the OVS IPsec monitors can verify the certificates
+// of the peer Nodes.
+type IPsecCSRSigningController struct {
+	client           kubernetes.Interface
+	namespace        string
+	csrLister        certificateslisters.CertificateSigningRequestLister
+	csrListerSynced  cache.InformerSynced
+	nodeLister       corelisters.NodeLister
+	nodeListerSynced cache.InformerSynced
+	podLister        corelisters.PodLister
+	podListerSynced  cache.InformerSynced
+	queue            workqueue.RateLimitingInterface
+
+	// caCert and caKey are loaded once at startup, before any CSR is
+	// processed.
+	caCert    *x509.Certificate
+	caCertPEM []byte
+	caKey     crypto.Signer
+}
+
+// NewIPsecCSRSigningController returns a new *IPsecCSRSigningController. The CA
+// Secret and ConfigMap are in the provided Namespace, which should be the
+// Namespace of antrea-controller and antrea-agent.
+func NewIPsecCSRSigningController(
+	client kubernetes.Interface,
+	namespace string,
+	csrInformer certificatesinformers.CertificateSigningRequestInformer,
+	nodeInformer coreinformers.NodeInformer,
+	podInformer coreinformers.PodInformer) *IPsecCSRSigningController {
+	c := &IPsecCSRSigningController{
+		client:           client,
+		namespace:        namespace,
+		csrLister:        csrInformer.Lister(),
+		csrListerSynced:  csrInformer.Informer().HasSynced,
+		nodeLister:       nodeInformer.Lister(),
+		nodeListerSynced: nodeInformer.Informer().HasSynced,
+		podLister:        podInformer.Lister(),
+		podListerSynced:  podInformer.Informer().HasSynced,
+		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "ipsecCSRSigning"),
+	}
+	csrInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueCSR,
+			UpdateFunc: func(old, cur interface{}) {
+				c.enqueueCSR(cur)
+			},
+		},
+	)
+	return c
+}
+
+func (c *IPsecCSRSigningController) enqueueCSR(obj interface{}) {
+	csr, ok := obj.(*certificatesv1beta1.CertificateSigningRequest)
+	if !ok {
+		return
+	}
+	if csr.Spec.SignerName == nil || *csr.Spec.SignerName != apis.IPsecCSRSignerName {
+		return
+	}
+	c.queue.Add(csr.Name)
+}
+
+// Run loads or creates the CA, then starts the workers processing the CSRs.
+func (c *IPsecCSRSigningController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if err := wait.PollImmediateUntil(caRetryInterval, func() (bool, error) {
+		if err := c.syncCA(); err != nil {
+			klog.Errorf("Failed to set up the IPsec CA: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}, stopCh); err != nil {
+		return
+	}
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.csrListerSynced, c.nodeListerSynced, c.podListerSynced) {
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// syncCA loads the CA certificate and key from the Secret, creating a
+// self-signed CA if the Secret doesn't exist, and publishes the CA certificate
+// to the ConfigMap.
+func (c *IPsecCSRSigningController) syncCA() error {
+	secret, err := c.client.CoreV1().Secrets(c.namespace).Get(context.TODO(), ipsecCASecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret, err = c.createCASecret()
+	}
+	if err != nil {
+		return err
+	}
+	certs, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return fmt.Errorf("error parsing the certificate of Secret %s: %v", ipsecCASecretName, err)
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return fmt.Errorf("error parsing the private key of Secret %s: %v", ipsecCASecretName, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("the private key of Secret %s is not a signer", ipsecCASecretName)
+	}
+	caCertPEM := secret.Data[corev1.TLSCertKey]
+
+	configMap, err := c.client.CoreV1().ConfigMaps(c.namespace).Get(context.TODO(), apis.IPsecCAConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting ConfigMap %s: %v", apis.IPsecCAConfigMapName, err)
+	}
+	if configMap.Data[apis.IPsecCAConfigMapKey] != string(caCertPEM) {
+		configMap = configMap.DeepCopy()
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[apis.IPsecCAConfigMapKey] = string(caCertPEM)
+		if _, err := c.client.CoreV1().ConfigMaps(c.namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error updating ConfigMap %s: %v", apis.IPsecCAConfigMapName, err)
+		}
+		klog.Infof("Published the IPsec CA certificate to ConfigMap %s", apis.IPsecCAConfigMapName)
+	}
+
+	c.caCert = certs[0]
+	c.caCertPEM = caCertPEM
+	c.caKey = signer
+	return nil
+}
+
+func (c *IPsecCSRSigningController) createCASecret() (*corev1.Secret, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("error generating the IPsec CA private key: %v", err)
+	}
+	cert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "antrea-ipsec-ca"}, key)
+	if err != nil {
+		return nil, fmt.Errorf("error generating the IPsec CA certificate: %v", err)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: ipsecCASecretName, Namespace: c.namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: cert.Raw}),
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	created, err := c.client.CoreV1().Secrets(c.namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// Another replica may have created it at the same time.
+		return c.client.CoreV1().Secrets(c.namespace).Get(context.TODO(), ipsecCASecretName, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating Secret %s: %v", ipsecCASecretName, err)
+	}
+	klog.Infof("Created self-signed IPsec CA in Secret %s", ipsecCASecretName)
+	return created, nil
+}
+
+func (c *IPsecCSRSigningController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *IPsecCSRSigningController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncCSR(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing CertificateSigningRequest %s, requeuing. Error: %v", key, err)
+	}
+	return true
+}
+
+func (c *IPsecCSRSigningController) syncCSR(name string) error {
+	csr, err := c.csrLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if len(csr.Status.Certificate) > 0 || isDenied(csr) {
+		return nil
+	}
+	if !isApproved(csr) {
+		return c.approveOrDenyCSR(csr)
+	}
+	return c.signCSR(csr)
+}
+
+// approveOrDenyCSR approves the CSR if it's requested by antrea-agent for the
+// Node it's running on, and denies it otherwise. The approved CSR will be
+// signed when the update event is processed.
+func (c *IPsecCSRSigningController) approveOrDenyCSR(csr *certificatesv1beta1.CertificateSigningRequest) error {
+	csr = csr.DeepCopy()
+	condition := certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           certificatesv1beta1.CertificateApproved,
+		Reason:         "AutoApproved",
+		Message:        "Automatically approved by " + controllerName,
+		LastUpdateTime: metav1.Now(),
+	}
+	if err := c.validateCSR(csr); err != nil {
+		klog.Infof("Denying CertificateSigningRequest %s: %v", csr.Name, err)
+		condition.Type = certificatesv1beta1.CertificateDenied
+		condition.Reason = "InvalidRequest"
+		condition.Message = err.Error()
+	} else {
+		klog.Infof("Approving CertificateSigningRequest %s", csr.Name)
+	}
+	csr.Status.Conditions = append(csr.Status.Conditions, condition)
+	_, err := c.client.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *IPsecCSRSigningController) validateCSR(csr *certificatesv1beta1.CertificateSigningRequest) error {
+	expectedUsername := fmt.Sprintf("system:serviceaccount:%s:%s", c.namespace, agentServiceAccountName)
+	if csr.Spec.Username != expectedUsername {
+		return fmt.Errorf("requester %s is not %s", csr.Spec.Username, expectedUsername)
+	}
+	if len(csr.Spec.Usages) != 1 || csr.Spec.Usages[0] != certificatesv1beta1.UsageIPsecTunnel {
+		return fmt.Errorf("usages must be [%s]", certificatesv1beta1.UsageIPsecTunnel)
+	}
+	request, err := parseCSR(csr.Spec.Request)
+	if err != nil {
+		return err
+	}
+	if len(request.DNSNames) > 0 || len(request.IPAddresses) > 0 || len(request.EmailAddresses) > 0 || len(request.URIs) > 0 {
+		return fmt.Errorf("subject alternative names are not allowed")
+	}
+	nodeName := request.Subject.CommonName
+	if _, err := c.nodeLister.Get(nodeName); err != nil {
+		return fmt.Errorf("common name %s is not an existing Node", nodeName)
+	}
+	// Make sure the antrea-agent requests the certificate of its own Node. The
+	// requester Pod is only known when the request is authenticated with a
+	// bound ServiceAccount token, otherwise any antrea-agent could request the
+	// certificate of any Node.
+	podNames := csr.Spec.Extra[podNameExtraKey]
+	if len(podNames) == 0 {
+		return fmt.Errorf("requester Pod is unknown, the request must be authenticated with a bound ServiceAccount token")
+	}
+	pod, err := c.podLister.Pods(c.namespace).Get(podNames[0])
+	if err != nil {
+		return fmt.Errorf("requester Pod %s not found", podNames[0])
+	}
+	if pod.Spec.NodeName != nodeName {
+		return fmt.Errorf("requester Pod %s is not running on Node %s", pod.Name, nodeName)
+	}
+	return nil
+}
+
+func (c *IPsecCSRSigningController) signCSR(csr *certificatesv1beta1.CertificateSigningRequest) error {
+	request, err := parseCSR(csr.Spec.Request)
+	if err != nil {
+		// It was valid when approved, should not happen.
+		klog.Errorf("Failed to parse the approved CertificateSigningRequest %s: %v", csr.Name, err)
+		return nil
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	notAfter := now.Add(certificateDuration)
+	if notAfter.After(c.caCert.NotAfter) {
+		notAfter = c.caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: request.Subject.CommonName},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageIPSECTunnel},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.caCert, request.PublicKey, c.caKey)
+	if err != nil {
+		return fmt.Errorf("error signing the certificate: %v", err)
+	}
+	csr = csr.DeepCopy()
+	csr.Status.Certificate = pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der})
+	if _, err := c.client.CertificatesV1beta1().CertificateSigningRequests().UpdateStatus(context.TODO(), csr, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating the status: %v", err)
+	}
+	klog.Infof("Signed CertificateSigningRequest %s for Node %s", csr.Name, request.Subject.CommonName)
+	return nil
+}
+
+func parseCSR(pemBytes []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != certutil.CertificateRequestBlockType {
+		return nil, fmt.Errorf("PEM block type must be %s", certutil.CertificateRequestBlockType)
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := request.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid signature of the request: %v", err)
+	}
+	return request, nil
+}
+
+func isApproved(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certificatesv1beta1.CertificateApproved {
+			return true
+		}
+	}
+	return false
+}
+
+func isDenied(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certificatesv1beta1.CertificateDenied {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificatesigningrequest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+)
+
+const (
+	testNamespace = "kube-system"
+	testUsername  = "system:serviceaccount:kube-system:antrea-agent"
+)
+
+func newCSR(t *testing.T, name, commonName string, dnsNames []string, username string, extra map[string]certificatesv1beta1.ExtraValue) *certificatesv1beta1.CertificateSigningRequest {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: dnsNames,
+	}, key)
+	require.NoError(t, err)
+	signerName := apis.IPsecCSRSignerName
+	return &certificatesv1beta1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:    pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateRequestBlockType, Bytes: der}),
+			SignerName: &signerName,
+			Usages:     []certificatesv1beta1.KeyUsage{certificatesv1beta1.UsageIPsecTunnel},
+			Username:   username,
+			Extra:      extra,
+		},
+	}
+}
+
+func getCondition(csr *certificatesv1beta1.CertificateSigningRequest) certificatesv1beta1.RequestConditionType {
+	if len(csr.Status.Conditions) == 0 {
+		return ""
+	}
+	return csr.Status.Conditions[len(csr.Status.Conditions)-1].Type
+}
+
+func TestIPsecCSRSigningController(t *testing.T) {
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	agentPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "antrea-agent-abcde", Namespace: testNamespace},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+	}
+	caConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: apis.IPsecCAConfigMapName, Namespace: testNamespace}}
+	client := fake.NewSimpleClientset(node1, agentPod, caConfigMap)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	controller := NewIPsecCSRSigningController(client, testNamespace,
+		informerFactory.Certificates().V1beta1().CertificateSigningRequests(),
+		informerFactory.Core().V1().Nodes(),
+		informerFactory.Core().V1().Pods())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	go controller.Run(stopCh)
+
+	podExtra := map[string]certificatesv1beta1.ExtraValue{podNameExtraKey: {agentPod.Name}}
+	tests := []struct {
+		name           string
+		csr            *certificatesv1beta1.CertificateSigningRequest
+		expectApproved bool
+	}{
+		{
+			name:           "valid",
+			csr:            newCSR(t, "valid", "node1", nil, testUsername, podExtra),
+			expectApproved: true,
+		},
+		{
+			name: "no requester Pod",
+			csr:  newCSR(t, "no-requester-pod", "node1", nil, testUsername, nil),
+		},
+		{
+			name: "unknown Node",
+			csr:  newCSR(t, "unknown-node", "node2", nil, testUsername, podExtra),
+		},
+		{
+			name: "Pod on another Node",
+			csr: newCSR(t, "pod-on-another-node", "node1", nil, testUsername,
+				map[string]certificatesv1beta1.ExtraValue{podNameExtraKey: {"antrea-agent-fghij"}}),
+		},
+		{
+			name: "unexpected requester",
+			csr:  newCSR(t, "unexpected-requester", "node1", nil, "system:serviceaccount:default:default", podExtra),
+		},
+		{
+			name: "SANs",
+			csr:  newCSR(t, "sans", "node1", []string{"node1.example.com"}, testUsername, podExtra),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), tt.csr, metav1.CreateOptions{})
+			require.NoError(t, err)
+			var csr *certificatesv1beta1.CertificateSigningRequest
+			err = wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+				csr, err = client.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), tt.csr.Name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				if tt.expectApproved {
+					return len(csr.Status.Certificate) > 0, nil
+				}
+				return getCondition(csr) == certificatesv1beta1.CertificateDenied, nil
+			})
+			require.NoError(t, err)
+			if !tt.expectApproved {
+				assert.Empty(t, csr.Status.Certificate)
+				return
+			}
+			assert.Equal(t, certificatesv1beta1.CertificateApproved, getCondition(csr))
+
+			// The certificate must be verifiable with the published CA.
+			configMap, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), apis.IPsecCAConfigMapName, metav1.GetOptions{})
+			require.NoError(t, err)
+			caCerts, err := certutil.ParseCertsPEM([]byte(configMap.Data[apis.IPsecCAConfigMapKey]))
+			require.NoError(t, err)
+			certs, err := certutil.ParseCertsPEM(csr.Status.Certificate)
+			require.NoError(t, err)
+			roots := x509.NewCertPool()
+			roots.AddCert(caCerts[0])
+			_, err = certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageIPSECTunnel}})
+			assert.NoError(t, err)
+			assert.Equal(t, "node1", certs[0].Subject.CommonName)
+		})
+	}
+}
+
+func TestSyncCAReusesSecret(t *testing.T) {
+	caConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: apis.IPsecCAConfigMapName, Namespace: testNamespace}}
+	client := fake.NewSimpleClientset(caConfigMap)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	newController := func() *IPsecCSRSigningController {
+		return NewIPsecCSRSigningController(client, testNamespace,
+			informerFactory.Certificates().V1beta1().CertificateSigningRequests(),
+			informerFactory.Core().V1().Nodes(),
+			informerFactory.Core().V1().Pods())
+	}
+
+	c1 := newController()
+	require.NoError(t, c1.syncCA())
+	// A new leader must load the same CA instead of generating another one.
+	c2 := newController()
+	require.NoError(t, c2.syncCA())
+	assert.Equal(t, c1.caCertPEM, c2.caCertPEM)
+
+	configMap, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), apis.IPsecCAConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, string(c1.caCertPEM), configMap.Data[apis.IPsecCAConfigMapKey])
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sleaderelection "k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+)
+
+const (
+	// leaseName is the name of the Lease held by the leader of the antrea-controller replicas.
+	leaseName = "antrea-controller"
+
+	// The timing of the leader election uses the same default values as kube-controller-manager. A standby replica
+	// takes over within leaseDuration after the leader stops renewing the Lease, and immediately after the leader
+	// releases it when it's stopped gracefully.
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Elector elects the leader among the antrea-controller replicas with a Lease in the Antrea Namespace.
+// All the replicas compute the internal NetworkPolicy objects, while only the leader is ready to serve the
+// antrea-agents and runs the functions which update resources in the K8s API, so that a standby replica
+// can take over with its internal objects already computed when the leader fails.
+type Elector struct {
+	elector *k8sleaderelection.LeaderElector
+	// leaderFuncs are the functions run once the replica becomes the leader.
+	leaderFuncs []func(stopCh <-chan struct{})
+	// stopCh is closed when the Elector is stopped.
+	stopCh <-chan struct{}
+}
+
+// NewElector creates an Elector identified by the provided identity, which must be unique among the replicas.
+func NewElector(client kubernetes.Interface, namespace, identity string) (*Elector, error) {
+	e := &Elector{}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      leaseName,
+		},
+		Client: client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	elector, err := k8sleaderelection.NewLeaderElector(k8sleaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaseName,
+		Callbacks: k8sleaderelection.LeaderCallbacks{
+			OnStartedLeading: e.onStartedLeading,
+			OnStoppedLeading: e.onStoppedLeading,
+			OnNewLeader: func(leader string) {
+				klog.Infof("New leader of antrea-controller elected: %s", leader)
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating leader elector: %v", err)
+	}
+	e.elector = elector
+	return e, nil
+}
+
+// AddLeaderFunc adds a function run once the replica becomes the leader. The provided stop channel is closed
+// when the replica stops leading. It must be called before Run.
+func (e *Elector) AddLeaderFunc(f func(stopCh <-chan struct{})) {
+	e.leaderFuncs = append(e.leaderFuncs, f)
+}
+
+// IsLeader returns whether the replica is the leader.
+func (e *Elector) IsLeader() bool {
+	return e.elector.IsLeader()
+}
+
+// Run runs the leader election until stopCh is closed, and releases the Lease if the replica is the leader.
+func (e *Elector) Run(stopCh <-chan struct{}) {
+	e.stopCh = stopCh
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	klog.Infof("Starting leader election of antrea-controller")
+	e.elector.Run(ctx)
+}
+
+func (e *Elector) onStartedLeading(ctx context.Context) {
+	klog.Infof("Became the leader of antrea-controller")
+	for _, f := range e.leaderFuncs {
+		go f(ctx.Done())
+	}
+}
+
+func (e *Elector) onStoppedLeading() {
+	select {
+	case <-e.stopCh:
+		klog.Infof("Stopped leader election of antrea-controller")
+	default:
+		// Another replica may already be the leader. Exit rather than risking both replicas updating the
+		// same resources, the replica will be restarted as a standby one.
+		klog.Fatalf("Lost the leadership of antrea-controller, exiting")
+	}
+}
+
+// Name implements healthz.HealthChecker.
+func (e *Elector) Name() string {
+	return "leader"
+}
+
+// Check implements healthz.HealthChecker. It fails if the replica is not the leader, so that the antrea Service
+// only selects the leader.
+func (e *Elector) Check(_ *http.Request) error {
+	if !e.IsLeader() {
+		return errors.New("not the leader of antrea-controller")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestElector(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leader, err := NewElector(client, "kube-system", "controller-1")
+	require.NoError(t, err)
+	standby, err := NewElector(client, "kube-system", "controller-2")
+	require.NoError(t, err)
+
+	leaderStarted := make(chan struct{})
+	leaderStopped := make(chan struct{})
+	leader.AddLeaderFunc(func(stopCh <-chan struct{}) {
+		close(leaderStarted)
+		<-stopCh
+		close(leaderStopped)
+	})
+	standby.AddLeaderFunc(func(stopCh <-chan struct{}) {
+		t.Errorf("The standby replica must not run the leader functions")
+	})
+	assert.Error(t, leader.Check(nil))
+
+	leaderStopCh := make(chan struct{})
+	go leader.Run(leaderStopCh)
+	select {
+	case <-leaderStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The leader functions were not run")
+	}
+	assert.NoError(t, leader.Check(nil))
+
+	standbyStopCh := make(chan struct{})
+	go standby.Run(standbyStopCh)
+	// Wait for the standby replica to observe the leader.
+	require.NoError(t, wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return standby.elector.GetLeader() == "controller-1", nil
+	}))
+	assert.Error(t, standby.Check(nil))
+	close(standbyStopCh)
+
+	// The leader releases the Lease when it's stopped.
+	close(leaderStopCh)
+	select {
+	case <-leaderStopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The leader functions were not stopped")
+	}
+	require.NoError(t, wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		lease, err := client.CoordinationV1().Leases("kube-system").Get(context.TODO(), leaseName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == "", nil
+	}))
+}
@@ -15,6 +15,8 @@
 package networkpolicy
 
 import (
+	"time"
+
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
@@ -34,6 +36,7 @@ func (n *NetworkPolicyController) addANP(obj interface{}) {
 	internalNP := n.processAntreaNetworkPolicy(np)
 	klog.V(2).Infof("Creating new internal NetworkPolicy %s for %s", internalNP.Name, internalNP.SourceRef.ToString())
 	n.internalNetworkPolicyStore.Create(internalNP)
+	n.enqueueRuleScheduleTransition(internalNP)
 	key := internalNetworkPolicyKeyFunc(np)
 	n.enqueueInternalNetworkPolicy(key)
 }
@@ -44,10 +47,6 @@ func (n *NetworkPolicyController) updateANP(old, cur interface{}) {
 	defer n.heartbeat("updateANP")
 	curNP := cur.(*secv1alpha1.NetworkPolicy)
 	klog.Infof("Processing Antrea NetworkPolicy %s/%s UPDATE event", curNP.Namespace, curNP.Name)
-	// Update an internal NetworkPolicy, corresponding to this NetworkPolicy and
-	// enqueue task to internal NetworkPolicy Workqueue.
-	curInternalNP := n.processAntreaNetworkPolicy(curNP)
-	klog.V(2).Infof("Updating existing internal NetworkPolicy %s for %s", curInternalNP.Name, curInternalNP.SourceRef.ToString())
 	// Retrieve old secv1alpha1.NetworkPolicy object.
 	oldNP := old.(*secv1alpha1.NetworkPolicy)
 	// Old and current NetworkPolicy share the same key.
@@ -56,8 +55,14 @@ func (n *NetworkPolicyController) updateANP(old, cur interface{}) {
 	// to an internal NetworkPolicy is not allowed. This will avoid the
 	// case in which an Update to an internal NetworkPolicy object may
 	// cause the SpanMeta member to be overridden with stale SpanMeta members
-	// from an older internal NetworkPolicy.
+	// from an older internal NetworkPolicy. The policy is processed with the
+	// lock held as it may also be processed again by syncInternalNetworkPolicy
+	// when its scheduled rules are activated or deactivated.
 	n.internalNetworkPolicyMutex.Lock()
+	// Update an internal NetworkPolicy, corresponding to this NetworkPolicy and
+	// enqueue task to internal NetworkPolicy Workqueue.
+	curInternalNP := n.processAntreaNetworkPolicy(curNP)
+	klog.V(2).Infof("Updating existing internal NetworkPolicy %s for %s", curInternalNP.Name, curInternalNP.SourceRef.ToString())
 	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	// Must preserve old internal NetworkPolicy Span.
@@ -65,6 +70,7 @@ func (n *NetworkPolicyController) updateANP(old, cur interface{}) {
 	n.internalNetworkPolicyStore.Update(curInternalNP)
 	// Unlock the internal NetworkPolicy store.
 	n.internalNetworkPolicyMutex.Unlock()
+	n.enqueueRuleScheduleTransition(curInternalNP)
 	// Enqueue addressGroup keys to update their Node span.
 	for _, rule := range curInternalNP.Rules {
 		for _, addrGroupName := range rule.From.AddressGroups {
@@ -131,8 +137,14 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 			np.Namespace, at.PodSelector, at.NamespaceSelector, at.ExternalEntitySelector))
 	}
 	rules := make([]controlplane.NetworkPolicyRule, 0, len(np.Spec.Ingress)+len(np.Spec.Egress))
+	var scheduledRules []secv1alpha1.ScheduledRuleStatus
+	now := time.Now()
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, ingressRule := range np.Spec.Ingress {
+		// Skip the rules which are not active at the moment according to their schedule.
+		if !scheduleRuleState(&np.Spec.Ingress[idx], controlplane.DirectionIn, idx, now, &scheduledRules) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(ingressRule.Ports)
 		rules = append(rules, controlplane.NetworkPolicyRule{
@@ -142,10 +154,16 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 			Action:        ingressRule.Action,
 			Priority:      int32(idx),
 			EnableLogging: ingressRule.EnableLogging,
+			RateLimit:     toAntreaRateLimitForCRD(ingressRule.RateLimit),
+			L7Protocols:   toAntreaL7ProtocolsForCRD(ingressRule.L7Protocols),
 		})
 	}
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, egressRule := range np.Spec.Egress {
+		// Skip the rules which are not active at the moment according to their schedule.
+		if !scheduleRuleState(&np.Spec.Egress[idx], controlplane.DirectionOut, idx, now, &scheduledRules) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(egressRule.Ports)
 		rules = append(rules, controlplane.NetworkPolicyRule{
@@ -155,6 +173,7 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 			Action:        egressRule.Action,
 			Priority:      int32(idx),
 			EnableLogging: egressRule.EnableLogging,
+			RateLimit:     toAntreaRateLimitForCRD(egressRule.RateLimit),
 		})
 	}
 	tierPriority := n.getTierPriority(np.Spec.Tier)
@@ -172,6 +191,7 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *secv1alpha1.Net
 		Rules:           rules,
 		Priority:        &np.Spec.Priority,
 		TierPriority:    &tierPriority,
+		ScheduledRules:  scheduledRules,
 	}
 	return internalNetworkPolicy
 }
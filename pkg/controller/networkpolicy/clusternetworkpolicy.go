@@ -15,6 +15,8 @@
 package networkpolicy
 
 import (
+	"time"
+
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
@@ -34,6 +36,7 @@ func (n *NetworkPolicyController) addCNP(obj interface{}) {
 	internalNP := n.processClusterNetworkPolicy(cnp)
 	klog.V(2).Infof("Creating new internal NetworkPolicy %s for %s", internalNP.Name, internalNP.SourceRef.ToString())
 	n.internalNetworkPolicyStore.Create(internalNP)
+	n.enqueueRuleScheduleTransition(internalNP)
 	key := internalNetworkPolicyKeyFunc(cnp)
 	n.enqueueInternalNetworkPolicy(key)
 }
@@ -44,10 +47,6 @@ func (n *NetworkPolicyController) updateCNP(old, cur interface{}) {
 	defer n.heartbeat("updateCNP")
 	curCNP := cur.(*secv1alpha1.ClusterNetworkPolicy)
 	klog.Infof("Processing ClusterNetworkPolicy %s UPDATE event", curCNP.Name)
-	// Update an internal NetworkPolicy, corresponding to this NetworkPolicy and
-	// enqueue task to internal NetworkPolicy Workqueue.
-	curInternalNP := n.processClusterNetworkPolicy(curCNP)
-	klog.V(2).Infof("Updating existing internal NetworkPolicy %s for %s", curInternalNP.Name, curInternalNP.SourceRef.ToString())
 	// Retrieve old secv1alpha1.NetworkPolicy object.
 	oldCNP := old.(*secv1alpha1.ClusterNetworkPolicy)
 	// Old and current NetworkPolicy share the same key.
@@ -56,8 +55,14 @@ func (n *NetworkPolicyController) updateCNP(old, cur interface{}) {
 	// to an internal NetworkPolicy is not allowed. This will avoid the
 	// case in which an Update to an internal NetworkPolicy object may
 	// cause the SpanMeta member to be overridden with stale SpanMeta members
-	// from an older internal NetworkPolicy.
+	// from an older internal NetworkPolicy. The policy is processed with the
+	// lock held as it may also be processed again by syncInternalNetworkPolicy
+	// when its scheduled rules are activated or deactivated.
 	n.internalNetworkPolicyMutex.Lock()
+	// Update an internal NetworkPolicy, corresponding to this NetworkPolicy and
+	// enqueue task to internal NetworkPolicy Workqueue.
+	curInternalNP := n.processClusterNetworkPolicy(curCNP)
+	klog.V(2).Infof("Updating existing internal NetworkPolicy %s for %s", curInternalNP.Name, curInternalNP.SourceRef.ToString())
 	oldInternalNPObj, _, _ := n.internalNetworkPolicyStore.Get(key)
 	oldInternalNP := oldInternalNPObj.(*antreatypes.NetworkPolicy)
 	// Must preserve old internal NetworkPolicy Span.
@@ -65,6 +70,7 @@ func (n *NetworkPolicyController) updateCNP(old, cur interface{}) {
 	n.internalNetworkPolicyStore.Update(curInternalNP)
 	// Unlock the internal NetworkPolicy store.
 	n.internalNetworkPolicyMutex.Unlock()
+	n.enqueueRuleScheduleTransition(curInternalNP)
 	// Enqueue addressGroup keys to update their Node span.
 	for _, rule := range curInternalNP.Rules {
 		for _, addrGroupName := range rule.From.AddressGroups {
@@ -127,11 +133,21 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 	// Create AppliedToGroup for each AppliedTo present in
 	// ClusterNetworkPolicy spec.
 	for _, at := range cnp.Spec.AppliedTo {
+		if at.NodeSelector != nil {
+			appliedToGroupNames = append(appliedToGroupNames, n.createAppliedToGroupForSelector(toNodeGroupSelector(at.NodeSelector)))
+			continue
+		}
 		appliedToGroupNames = append(appliedToGroupNames, n.createAppliedToGroup("", at.PodSelector, at.NamespaceSelector, at.ExternalEntitySelector))
 	}
 	rules := make([]controlplane.NetworkPolicyRule, 0, len(cnp.Spec.Ingress)+len(cnp.Spec.Egress))
+	var scheduledRules []secv1alpha1.ScheduledRuleStatus
+	now := time.Now()
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, ingressRule := range cnp.Spec.Ingress {
+		// Skip the rules which are not active at the moment according to their schedule.
+		if !scheduleRuleState(&cnp.Spec.Ingress[idx], controlplane.DirectionIn, idx, now, &scheduledRules) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(ingressRule.Ports)
 		rules = append(rules, controlplane.NetworkPolicyRule{
@@ -141,10 +157,16 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 			Action:        ingressRule.Action,
 			Priority:      int32(idx),
 			EnableLogging: ingressRule.EnableLogging,
+			RateLimit:     toAntreaRateLimitForCRD(ingressRule.RateLimit),
+			L7Protocols:   toAntreaL7ProtocolsForCRD(ingressRule.L7Protocols),
 		})
 	}
 	// Compute NetworkPolicyRule for Egress Rule.
 	for idx, egressRule := range cnp.Spec.Egress {
+		// Skip the rules which are not active at the moment according to their schedule.
+		if !scheduleRuleState(&cnp.Spec.Egress[idx], controlplane.DirectionOut, idx, now, &scheduledRules) {
+			continue
+		}
 		// Set default action to ALLOW to allow traffic.
 		services, namedPortExists := toAntreaServicesForCRD(egressRule.Ports)
 		rules = append(rules, controlplane.NetworkPolicyRule{
@@ -154,6 +176,7 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 			Action:        egressRule.Action,
 			Priority:      int32(idx),
 			EnableLogging: egressRule.EnableLogging,
+			RateLimit:     toAntreaRateLimitForCRD(egressRule.RateLimit),
 		})
 	}
 	tierPriority := n.getTierPriority(cnp.Spec.Tier)
@@ -170,6 +193,7 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *secv1alpha1.C
 		Rules:           rules,
 		Priority:        &cnp.Spec.Priority,
 		TierPriority:    &tierPriority,
+		ScheduledRules:  scheduledRules,
 	}
 	return internalNetworkPolicy
 }
@@ -17,6 +17,7 @@ package networkpolicy
 import (
 	"strings"
 
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -54,19 +55,45 @@ func toAntreaServicesForCRD(npPorts []secv1alpha1.NetworkPolicyPort) ([]controlp
 	return antreaServices, namedPortExists
 }
 
-// toAntreaIPBlockForCRD converts a secv1alpha1.IPBlock to an Antrea IPBlock.
-func toAntreaIPBlockForCRD(ipBlock *secv1alpha1.IPBlock) (*controlplane.IPBlock, error) {
-	// Convert the allowed IPBlock to networkpolicy.IPNet.
-	ipNet, err := cidrStrToIPNet(ipBlock.CIDR)
-	if err != nil {
-		return nil, err
+// toAntreaRateLimitForCRD converts a secv1alpha1.RateLimit to an Antrea
+// RateLimit.
+func toAntreaRateLimitForCRD(rateLimit *secv1alpha1.RateLimit) *controlplane.RateLimit {
+	if rateLimit == nil {
+		return nil
 	}
-	antreaIPBlock := &controlplane.IPBlock{
-		CIDR: *ipNet,
-		// secv1alpha.IPBlock does not have the Except slices.
-		Except: []controlplane.IPNet{},
+	return &controlplane.RateLimit{
+		ConnectionsPerSecond: rateLimit.ConnectionsPerSecond,
+		PacketsPerSecond:     rateLimit.PacketsPerSecond,
+		BytesPerSecond:       rateLimit.BytesPerSecond,
 	}
-	return antreaIPBlock, nil
+}
+
+// toAntreaL7ProtocolsForCRD converts a slice of secv1alpha1.L7Protocol objects
+// to a slice of Antrea L7Protocol objects.
+func toAntreaL7ProtocolsForCRD(l7Protocols []secv1alpha1.L7Protocol) []controlplane.L7Protocol {
+	var antreaL7Protocols []controlplane.L7Protocol
+	for _, l7Protocol := range l7Protocols {
+		antreaL7Protocol := controlplane.L7Protocol{}
+		if l7Protocol.HTTP != nil {
+			antreaL7Protocol.HTTP = &controlplane.HTTPProtocol{
+				Method: l7Protocol.HTTP.Method,
+				Host:   l7Protocol.HTTP.Host,
+				Path:   l7Protocol.HTTP.Path,
+			}
+		}
+		antreaL7Protocols = append(antreaL7Protocols, antreaL7Protocol)
+	}
+	return antreaL7Protocols
+}
+
+// toAntreaIPBlockForCRD converts a secv1alpha1.IPBlock to an Antrea IPBlock.
+// The Except CIDRs are handled the same way as the ones of a K8s NetworkPolicy
+// IPBlock.
+func toAntreaIPBlockForCRD(ipBlock *secv1alpha1.IPBlock) (*controlplane.IPBlock, error) {
+	return toAntreaIPBlock(&networkingv1.IPBlock{
+		CIDR:   ipBlock.CIDR,
+		Except: ipBlock.Except,
+	})
 }
 
 func (n *NetworkPolicyController) toAntreaPeerForCRD(peers []secv1alpha1.NetworkPolicyPeer,
@@ -91,8 +118,8 @@ func (n *NetworkPolicyController) toAntreaPeerForCRD(peers []secv1alpha1.Network
 	}
 	var ipBlocks []controlplane.IPBlock
 	for _, peer := range peers {
-		// A secv1alpha1.NetworkPolicyPeer will either have an IPBlock or a
-		// podSelector and/or namespaceSelector set.
+		// A secv1alpha1.NetworkPolicyPeer will either have an IPBlock, a
+		// nodeSelector or a podSelector and/or namespaceSelector set.
 		if peer.IPBlock != nil {
 			ipBlock, err := toAntreaIPBlockForCRD(peer.IPBlock)
 			if err != nil {
@@ -113,7 +140,12 @@ func (n *NetworkPolicyController) toAntreaPeerForCRD(peers []secv1alpha1.Network
 // function simply creates the object without actually populating the
 // PodAddresses as the affected Pods are calculated during sync process.
 func (n *NetworkPolicyController) createAddressGroupForCRD(peer secv1alpha1.NetworkPolicyPeer, np metav1.Object) string {
-	groupSelector := toGroupSelector(np.GetNamespace(), peer.PodSelector, peer.NamespaceSelector, peer.ExternalEntitySelector)
+	var groupSelector *antreatypes.GroupSelector
+	if peer.NodeSelector != nil {
+		groupSelector = toNodeGroupSelector(peer.NodeSelector)
+	} else {
+		groupSelector = toGroupSelector(np.GetNamespace(), peer.PodSelector, peer.NamespaceSelector, peer.ExternalEntitySelector)
+	}
 	normalizedUID := getNormalizedUID(groupSelector.NormalizedName)
 	// Get or create an AddressGroup for the generated UID.
 	_, found, _ := n.addressGroupStore.Get(normalizedUID)
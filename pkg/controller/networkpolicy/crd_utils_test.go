@@ -97,6 +97,19 @@ func TestToAntreaIPBlockForCRD(t *testing.T) {
 			controlplane.IPBlock{},
 			fmt.Errorf("invalid format for IPBlock CIDR: 10.0.0.0"),
 		},
+		{
+			&secv1alpha1.IPBlock{
+				CIDR:   "10.0.0.0/24",
+				Except: []string{"10.0.0.128/25"},
+			},
+			controlplane.IPBlock{
+				CIDR: expIPNet,
+				Except: []controlplane.IPNet{
+					{IP: ipStrToIPAddress("10.0.0.128"), PrefixLength: 25},
+				},
+			},
+			nil,
+		},
 	}
 	for _, table := range tables {
 		antreaIPBlock, err := toAntreaIPBlockForCRD(table.ipBlock)
@@ -115,6 +128,9 @@ func TestToAntreaIPBlockForCRD(t *testing.T) {
 		if table.expValue.CIDR.PrefixLength != ipNet.PrefixLength {
 			t.Errorf("Unexpected PrefixLength in Antrea IPBlock conversion. Expected %v, got %v", table.expValue.CIDR.PrefixLength, ipNet.PrefixLength)
 		}
+		if len(table.expValue.Except) > 0 {
+			assert.Equal(t, table.expValue.Except, antreaIPBlock.Except)
+		}
 	}
 }
 
@@ -165,6 +165,13 @@ type NetworkPolicyController struct {
 	// tierListerSynced is a function which returns true if the Tiers shared informer has been synced at least once.
 	tierListerSynced cache.InformerSynced
 
+	nodeInformer coreinformers.NodeInformer
+	// nodeLister is able to list/get Nodes and is populated by the shared informer passed to
+	// NewNetworkPolicyController.
+	nodeLister corelisters.NodeLister
+	// nodeListerSynced is a function which returns true if the Node shared informer has been synced at least once.
+	nodeListerSynced cache.InformerSynced
+
 	// addressGroupStore is the storage where the populated Address Groups are stored.
 	addressGroupStore storage.Interface
 	// appliedToGroupStore is the storage where the populated AppliedTo Groups are stored.
@@ -206,6 +213,7 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 	cnpInformer secinformers.ClusterNetworkPolicyInformer,
 	anpInformer secinformers.NetworkPolicyInformer,
 	tierInformer secinformers.TierInformer,
+	nodeInformer coreinformers.NodeInformer,
 	addressGroupStore storage.Interface,
 	appliedToGroupStore storage.Interface,
 	internalNetworkPolicyStore storage.Interface) *NetworkPolicyController {
@@ -326,6 +334,17 @@ func NewNetworkPolicyController(kubeClient clientset.Interface,
 			},
 			resyncPeriod,
 		)
+		n.nodeInformer = nodeInformer
+		n.nodeLister = nodeInformer.Lister()
+		n.nodeListerSynced = nodeInformer.Informer().HasSynced
+		nodeInformer.Informer().AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    n.addNode,
+				UpdateFunc: n.updateNode,
+				DeleteFunc: n.deleteNode,
+			},
+			resyncPeriod,
+		)
 	}
 	return n
 }
@@ -385,6 +404,17 @@ func toGroupSelector(namespace string, podSelector, nsSelector, extEntitySelecto
 	return &groupSelector
 }
 
+// toNodeGroupSelector converts the nodeSelector to a networkpolicy.GroupSelector
+// object. A Node GroupSelector is cluster scoped and cannot be combined with
+// any other selector.
+func toNodeGroupSelector(nodeSelector *metav1.LabelSelector) *antreatypes.GroupSelector {
+	nSelector, _ := metav1.LabelSelectorAsSelector(nodeSelector)
+	return &antreatypes.GroupSelector{
+		NodeSelector:   nSelector,
+		NormalizedName: fmt.Sprintf("nodeSelector=%s", nSelector.String()),
+	}
+}
+
 // getNormalizedUID generates a unique UUID based on a given string.
 // For example, it can be used to generate keys using normalized selectors
 // unique within the Namespace by adding the constant UID.
@@ -415,7 +445,12 @@ func generateNormalizedName(namespace string, podSelector, nsSelector, eeSelecto
 
 // createAppliedToGroup creates an AppliedToGroup object in store if it is not created already.
 func (n *NetworkPolicyController) createAppliedToGroup(npNsName string, pSel, nSel, eSel *metav1.LabelSelector) string {
-	groupSelector := toGroupSelector(npNsName, pSel, nSel, eSel)
+	return n.createAppliedToGroupForSelector(toGroupSelector(npNsName, pSel, nSel, eSel))
+}
+
+// createAppliedToGroupForSelector creates an AppliedToGroup object with the
+// provided GroupSelector in store if it is not created already.
+func (n *NetworkPolicyController) createAppliedToGroupForSelector(groupSelector *antreatypes.GroupSelector) string {
 	appliedToGroupUID := getNormalizedUID(groupSelector.NormalizedName)
 	// Get or create a AppliedToGroup for the generated UID.
 	// Ignoring returned error (here and elsewhere in this file) as with the
@@ -496,9 +531,9 @@ func (n *NetworkPolicyController) labelsMatchGroupSelector(obj metav1.Object, ns
 // match the Namespace's labels.
 func (n *NetworkPolicyController) filterAddressGroupsForNamespace(namespace *v1.Namespace) sets.String {
 	matchingKeys := sets.String{}
-	// Only cluster scoped groups or AddressGroups created by CNP can possibly select this Namespace.
-	addressGroups, _ := n.addressGroupStore.GetByIndex(cache.NamespaceIndex, "")
-	for _, group := range addressGroups {
+	// Only cluster scoped groups or AddressGroups created by CNP can possibly select this Namespace,
+	// and they are indexed by the Namespace labels their NamespaceSelector requires.
+	for _, group := range getGroupsByIndexKeys(n.addressGroupStore, store.NamespaceLabelIndex, store.NamespaceLabelIndexKeys(namespace)) {
 		addrGroup := group.(*antreatypes.AddressGroup)
 		// AddressGroup created by CNP might not have NamespaceSelector.
 		if addrGroup.Selector.NamespaceSelector != nil && addrGroup.Selector.NamespaceSelector.Matches(labels.Set(namespace.Labels)) {
@@ -513,11 +548,10 @@ func (n *NetworkPolicyController) filterAddressGroupsForNamespace(namespace *v1.
 // match the ExternalEntity or Pod's labels.
 func (n *NetworkPolicyController) filterAddressGroupsForPodOrExternalEntity(obj metav1.Object) sets.String {
 	matchingKeySet := sets.String{}
-	// AddressGroups that are in this namespace or that are cluster scoped can possibly select this Pod/ExternalEntity.
-	localAddressGroups, _ := n.addressGroupStore.GetByIndex(cache.NamespaceIndex, obj.GetNamespace())
-	clusterScopedAddressGroups, _ := n.addressGroupStore.GetByIndex(cache.NamespaceIndex, "")
 	ns, _ := n.namespaceLister.Get(obj.GetNamespace())
-	for _, group := range append(localAddressGroups, clusterScopedAddressGroups...) {
+	// Only the AddressGroups indexed by the labels of the Pod/ExternalEntity or its Namespace can
+	// possibly select the Pod/ExternalEntity.
+	for _, group := range getGroupsByIndexKeys(n.addressGroupStore, store.GroupLabelIndex, store.GroupLabelIndexKeys(obj, ns)) {
 		addrGroup := group.(*antreatypes.AddressGroup)
 		if n.labelsMatchGroupSelector(obj, ns, &addrGroup.Selector) {
 			matchingKeySet.Insert(addrGroup.Name)
@@ -531,13 +565,10 @@ func (n *NetworkPolicyController) filterAddressGroupsForPodOrExternalEntity(obj
 // match the ExternalEntity or Pod's labels.
 func (n *NetworkPolicyController) filterAppliedToGroupsForPodOrExternalEntity(obj metav1.Object) sets.String {
 	matchingKeySet := sets.String{}
-	// Get appliedToGroups from the namespace level
-	appliedToGroups, _ := n.appliedToGroupStore.GetByIndex(cache.NamespaceIndex, obj.GetNamespace())
-	// Get appliedToGroups from the cluster level
-	clusterATGroups, _ := n.appliedToGroupStore.GetByIndex(cache.NamespaceIndex, "")
-	appliedToGroups = append(appliedToGroups, clusterATGroups...)
 	ns, _ := n.namespaceLister.Get(obj.GetNamespace())
-	for _, group := range appliedToGroups {
+	// Only the AppliedToGroups indexed by the labels of the Pod/ExternalEntity or its Namespace can
+	// possibly select the Pod/ExternalEntity.
+	for _, group := range getGroupsByIndexKeys(n.appliedToGroupStore, store.GroupLabelIndex, store.GroupLabelIndexKeys(obj, ns)) {
 		appGroup := group.(*antreatypes.AppliedToGroup)
 		if n.labelsMatchGroupSelector(obj, ns, &appGroup.Selector) {
 			matchingKeySet.Insert(appGroup.Name)
@@ -547,6 +578,24 @@ func (n *NetworkPolicyController) filterAppliedToGroupsForPodOrExternalEntity(ob
 	return matchingKeySet
 }
 
+// getGroupsByIndexKeys returns the groups indexed by any of the provided keys of the index. A
+// group indexed by several of the keys is returned once.
+func getGroupsByIndexKeys(groupStore storage.Interface, indexName string, indexKeys []string) []interface{} {
+	var groups []interface{}
+	seen := map[interface{}]struct{}{}
+	for _, key := range indexKeys {
+		objs, _ := groupStore.GetByIndex(indexName, key)
+		for _, obj := range objs {
+			if _, exists := seen[obj]; exists {
+				continue
+			}
+			seen[obj] = struct{}{}
+			groups = append(groups, obj)
+		}
+	}
+	return groups
+}
+
 // createAddressGroup creates an AddressGroup object corresponding to a
 // NetworkPolicyPeer object in NetworkPolicyRule. This function simply
 // creates the object without actually populating the PodAddresses as the
@@ -1076,9 +1125,9 @@ func (n *NetworkPolicyController) Run(stopCh <-chan struct{}) {
 	defer klog.Infof("Shutting down %s", controllerName)
 
 	cacheSyncs := []cache.InformerSynced{n.podListerSynced, n.namespaceListerSynced, n.networkPolicyListerSynced}
-	// Only wait for cnpListerSynced and anpListerSynced when AntreaPolicy feature gate is enabled.
+	// Only wait for cnpListerSynced, anpListerSynced and nodeListerSynced when AntreaPolicy feature gate is enabled.
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
-		cacheSyncs = append(cacheSyncs, n.cnpListerSynced, n.anpListerSynced)
+		cacheSyncs = append(cacheSyncs, n.cnpListerSynced, n.anpListerSynced, n.nodeListerSynced)
 	}
 	if !cache.WaitForNamedCacheSync(controllerName, stopCh, cacheSyncs...) {
 		return
@@ -1236,6 +1285,14 @@ func (n *NetworkPolicyController) syncAddressGroup(key string) error {
 	groupSelector := addressGroup.Selector
 	pods, externalEntities := n.processSelector(groupSelector)
 	memberSet := controlplane.GroupMemberSet{}
+	if groupSelector.NodeSelector != nil {
+		nodes, _ := n.nodeLister.List(groupSelector.NodeSelector)
+		for _, node := range nodes {
+			if member := nodeToGroupMember(node); len(member.IPs) > 0 {
+				memberSet.Insert(member)
+			}
+		}
+	}
 	for _, pod := range pods {
 		if pod.Status.PodIP == "" {
 			// No need to insert Pod IPAddress when it is unset.
@@ -1253,7 +1310,7 @@ func (n *NetworkPolicyController) syncAddressGroup(key string) error {
 		GroupMembers: memberSet,
 		SpanMeta:     antreatypes.SpanMeta{NodeNames: addrGroupNodeNames},
 	}
-	klog.V(2).Infof("Updating existing AddressGroup %s with %d Pods/ExternalEntities and %d Nodes", key, len(memberSet), addrGroupNodeNames.Len())
+	klog.V(2).Infof("Updating existing AddressGroup %s with %d Pods/ExternalEntities/Nodes and %d Nodes", key, len(memberSet), addrGroupNodeNames.Len())
 	n.addressGroupStore.Update(updatedAddressGroup)
 	return nil
 }
@@ -1406,6 +1463,15 @@ func (n *NetworkPolicyController) syncAppliedToGroup(key string) error {
 		memberSetByNode[extEntity.Spec.ExternalNode] = entitySet
 		appGroupNodeNames.Insert(extEntity.Spec.ExternalNode)
 	}
+	if groupSelector.NodeSelector != nil {
+		// The Nodes selected by the group are the members themselves, each of
+		// them is only sent to the antrea-agent running on it.
+		nodes, _ := n.nodeLister.List(groupSelector.NodeSelector)
+		for _, node := range nodes {
+			memberSetByNode[node.Name] = controlplane.NewGroupMemberSet(nodeToAppliedToGroupMember(node))
+			appGroupNodeNames.Insert(node.Name)
+		}
+	}
 	updatedAppliedToGroup := &antreatypes.AppliedToGroup{
 		UID:               appliedToGroup.UID,
 		Name:              appliedToGroup.Name,
@@ -1460,6 +1526,16 @@ func (n *NetworkPolicyController) syncInternalNetworkPolicy(key string) error {
 	// Maintain a copy of old SpanMeta Nodenames so we can later enqueue Groups
 	// only if it is updated.
 	oldNodeNames := internalNP.SpanMeta.NodeNames
+	// If the activation state of some scheduled rules has changed, process the
+	// Antrea-native policy again, with the lock held so that it cannot interleave
+	// with the event handlers of the policy.
+	var oldInternalNP *antreatypes.NetworkPolicy
+	if ruleScheduleTransitionDue(internalNP, time.Now()) {
+		if reprocessedNP := n.reprocessAntreaPolicy(internalNP); reprocessedNP != nil {
+			klog.V(2).Infof("Processed rule schedule transition of %s", internalNP.SourceRef.ToString())
+			oldInternalNP, internalNP = internalNP, reprocessedNP
+		}
+	}
 	// Calculate the set of Node names based on the span of the
 	// AppliedToGroups referenced by this NetworkPolicy.
 	for _, appliedToGroupName := range internalNP.AppliedToGroups {
@@ -1480,13 +1556,24 @@ func (n *NetworkPolicyController) syncInternalNetworkPolicy(key string) error {
 		TierPriority:    internalNP.TierPriority,
 		SpanMeta:        antreatypes.SpanMeta{NodeNames: nodeNames},
 		Generation:      internalNP.Generation,
+		ScheduledRules:  internalNP.ScheduledRules,
 	}
 	klog.V(4).Infof("Updating internal NetworkPolicy %s with %d Nodes", key, nodeNames.Len())
 	n.internalNetworkPolicyStore.Update(updatedNetworkPolicy)
 	// Internal NetworkPolicy update is complete. Safe to unlock the
 	// critical section.
 	n.internalNetworkPolicyMutex.Unlock()
-	if nodeNames.Equal(oldNodeNames) {
+	if oldInternalNP != nil {
+		n.enqueueRuleScheduleTransition(internalNP)
+		// The deactivated rules may have been the last references of some groups.
+		for _, atg := range oldInternalNP.AppliedToGroups {
+			n.deleteDereferencedAppliedToGroup(atg)
+		}
+		n.deleteDereferencedAddressGroups(oldInternalNP)
+	}
+	// The AddressGroups of the activated rules must be enqueued to update their Node span
+	// even if the Node span of the internal NetworkPolicy is unchanged.
+	if oldInternalNP == nil && nodeNames.Equal(oldNodeNames) {
 		// Node span for internal NetworkPolicy was not modified. No need to enqueue
 		// AddressGroups.
 		klog.V(4).Infof("Internal NetworkPolicy %s Node span remains unchanged. No need to enqueue AddressGroups.", key)
@@ -33,6 +33,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
 )
 
 /*
@@ -178,6 +181,43 @@ func TestInitXLargeScaleWithOneNamespace(t *testing.T) {
 	testComputeNetworkPolicy(t, 15*time.Second, namespaces[0:1], networkPolicies, pods)
 }
 
+/*
+BenchmarkFilterGroupsForPodWithOneNamespace benchmarks the filtering of the groups matching a Pod, which happens on
+each Pod event, with 10k AppliedToGroups and 10k AddressGroups selecting Pods of one Namespace by distinct labels,
+and 100 AddressGroups selecting Pods by their Namespace's labels. The reference value is:
+
+LABEL-INDEX    NS/OP      B/OP      ALLOCS/OP
+without        4386671    534880    14
+with           13584      2336      56
+*/
+func BenchmarkFilterGroupsForPodWithOneNamespace(b *testing.B) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: rand.String(8), Labels: map[string]string{"env": "prod"}},
+	}
+	_, c := newController()
+	c.namespaceStore.Add(namespace)
+	for i := 0; i < 10000; i++ {
+		podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": fmt.Sprintf("app-%d", i)}}
+		selector := *toGroupSelector(namespace.Name, podSelector, nil, nil)
+		c.appliedToGroupStore.Create(&antreatypes.AppliedToGroup{Name: fmt.Sprintf("atg-%d", i), Selector: selector})
+		c.addressGroupStore.Create(&antreatypes.AddressGroup{Name: fmt.Sprintf("ag-%d", i), Selector: selector})
+	}
+	for i := 0; i < 100; i++ {
+		nsSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"env": fmt.Sprintf("env-%d", i)}}
+		selector := *toGroupSelector("", nil, nsSelector, nil)
+		c.addressGroupStore.Create(&antreatypes.AddressGroup{Name: fmt.Sprintf("cluster-ag-%d", i), Selector: selector})
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: "pod1", Labels: map[string]string{"app": "app-1", "version": "v1"}},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.filterAppliedToGroupsForPodOrExternalEntity(pod)
+		c.filterAddressGroupsForPodOrExternalEntity(pod)
+	}
+}
+
 func testComputeNetworkPolicy(t *testing.T, maxExecutionTime time.Duration, namespaces []*corev1.Namespace, networkPolicies []*networkingv1.NetworkPolicy, pods []*corev1.Pod) {
 	objs := toRunTimeObjects(namespaces, networkPolicies, pods)
 	_, c := newController(objs...)
@@ -262,9 +302,9 @@ NAMESPACES   PODS    NETWORK-POLICIES    TIME(s)    MEMORY(M)    EXECUTIONS    E
 }
 
 func statEvents(c *networkPolicyController, addressGroupEvents, appliedToGroupEvents, networkPolicyEvents *int32, stopCh chan struct{}) {
-	addressGroupWatcher, _ := c.addressGroupStore.Watch(context.Background(), "", labels.Everything(), fields.Everything())
-	appliedToGroupWatcher, _ := c.appliedToGroupStore.Watch(context.Background(), "", labels.Everything(), fields.Everything())
-	networkPolicyWatcher, _ := c.internalNetworkPolicyStore.Watch(context.Background(), "", labels.Everything(), fields.Everything())
+	addressGroupWatcher, _ := c.addressGroupStore.Watch(context.Background(), "", labels.Everything(), fields.Everything(), storage.WatchOptions{})
+	appliedToGroupWatcher, _ := c.appliedToGroupStore.Watch(context.Background(), "", labels.Everything(), fields.Everything(), storage.WatchOptions{})
+	networkPolicyWatcher, _ := c.internalNetworkPolicyStore.Watch(context.Background(), "", labels.Everything(), fields.Everything(), storage.WatchOptions{})
 	for {
 		select {
 		case <-addressGroupWatcher.ResultChan():
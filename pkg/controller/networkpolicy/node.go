@@ -0,0 +1,210 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/containernetworking/plugins/pkg/ip"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+// addNode retrieves all AddressGroups and AppliedToGroups which match the
+// Node's labels and enqueues the group keys for further processing.
+func (n *NetworkPolicyController) addNode(obj interface{}) {
+	defer n.heartbeat("addNode")
+	node := obj.(*v1.Node)
+	klog.V(2).Infof("Processing Node %s ADD event, labels: %v", node.Name, node.Labels)
+	addressGroupKeys := n.filterAddressGroupsForNode(node)
+	for group := range addressGroupKeys {
+		n.enqueueAddressGroup(group)
+	}
+	appliedToGroupKeys := n.filterAppliedToGroupsForNode(node)
+	for group := range appliedToGroupKeys {
+		n.enqueueAppliedToGroup(group)
+	}
+}
+
+// updateNode retrieves all AddressGroups and AppliedToGroups which match the
+// current and old Node's labels and enqueues the group keys for further
+// processing.
+func (n *NetworkPolicyController) updateNode(oldObj, curObj interface{}) {
+	defer n.heartbeat("updateNode")
+	oldNode := oldObj.(*v1.Node)
+	curNode := curObj.(*v1.Node)
+	klog.V(2).Infof("Processing Node %s UPDATE event, labels: %v", curNode.Name, curNode.Labels)
+	// No need to trigger processing of groups if there is no change in the
+	// Node labels or the addresses of the Node.
+	labelsEqual := labels.Equals(labels.Set(oldNode.Labels), labels.Set(curNode.Labels))
+	addressesEqual := reflect.DeepEqual(nodeToGroupMember(oldNode).IPs, nodeToGroupMember(curNode).IPs)
+	if labelsEqual && addressesEqual {
+		klog.V(4).Infof("No change in Node %s. Skipping NetworkPolicy evaluation.", curNode.Name)
+		return
+	}
+	oldAddressGroupKeySet := n.filterAddressGroupsForNode(oldNode)
+	curAddressGroupKeySet := n.filterAddressGroupsForNode(curNode)
+	var addressGroupKeys sets.String
+	// AddressGroup keys must be enqueued only if the Node's addresses have
+	// changed or if Node's label change causes it to match new Groups.
+	if !addressesEqual {
+		addressGroupKeys = oldAddressGroupKeySet.Union(curAddressGroupKeySet)
+	} else {
+		// No need to enqueue common AddressGroups as they already have latest
+		// Node information.
+		addressGroupKeys = oldAddressGroupKeySet.Difference(curAddressGroupKeySet).Union(curAddressGroupKeySet.Difference(oldAddressGroupKeySet))
+	}
+	for group := range addressGroupKeys {
+		n.enqueueAddressGroup(group)
+	}
+	// AppliedToGroups only reference the Nodes by name, they must be enqueued
+	// only if the Node's label change causes it to match new Groups.
+	if !labelsEqual {
+		oldAppliedToGroupKeySet := n.filterAppliedToGroupsForNode(oldNode)
+		curAppliedToGroupKeySet := n.filterAppliedToGroupsForNode(curNode)
+		appliedToGroupKeys := oldAppliedToGroupKeySet.Difference(curAppliedToGroupKeySet).Union(curAppliedToGroupKeySet.Difference(oldAppliedToGroupKeySet))
+		for group := range appliedToGroupKeys {
+			n.enqueueAppliedToGroup(group)
+		}
+	}
+}
+
+// deleteNode retrieves all AddressGroups and AppliedToGroups which match the
+// Node's labels and enqueues the group keys for further processing.
+func (n *NetworkPolicyController) deleteNode(old interface{}) {
+	node, ok := old.(*v1.Node)
+	if !ok {
+		tombstone, ok := old.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting Node, invalid type: %v", old)
+			return
+		}
+		node, ok = tombstone.Obj.(*v1.Node)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting Node, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	defer n.heartbeat("deleteNode")
+
+	klog.V(2).Infof("Processing Node %s DELETE event, labels: %v", node.Name, node.Labels)
+	addressGroupKeys := n.filterAddressGroupsForNode(node)
+	for group := range addressGroupKeys {
+		n.enqueueAddressGroup(group)
+	}
+	appliedToGroupKeys := n.filterAppliedToGroupsForNode(node)
+	for group := range appliedToGroupKeys {
+		n.enqueueAppliedToGroup(group)
+	}
+}
+
+// filterAddressGroupsForNode computes a list of AddressGroup keys which match
+// the Node's labels.
+func (n *NetworkPolicyController) filterAddressGroupsForNode(node *v1.Node) sets.String {
+	matchingKeys := sets.String{}
+	// Only cluster scoped groups can possibly select a Node.
+	addressGroups, _ := n.addressGroupStore.GetByIndex(cache.NamespaceIndex, "")
+	for _, group := range addressGroups {
+		addrGroup := group.(*antreatypes.AddressGroup)
+		if addrGroup.Selector.NodeSelector != nil && addrGroup.Selector.NodeSelector.Matches(labels.Set(node.Labels)) {
+			matchingKeys.Insert(addrGroup.Name)
+			klog.V(2).Infof("Node %s matched AddressGroup %s", node.Name, addrGroup.Name)
+		}
+	}
+	return matchingKeys
+}
+
+// filterAppliedToGroupsForNode computes a list of AppliedToGroup keys which
+// match the Node's labels.
+func (n *NetworkPolicyController) filterAppliedToGroupsForNode(node *v1.Node) sets.String {
+	matchingKeys := sets.String{}
+	// Only cluster scoped groups can possibly select a Node.
+	appliedToGroups, _ := n.appliedToGroupStore.GetByIndex(cache.NamespaceIndex, "")
+	for _, group := range appliedToGroups {
+		appGroup := group.(*antreatypes.AppliedToGroup)
+		if appGroup.Selector.NodeSelector != nil && appGroup.Selector.NodeSelector.Matches(labels.Set(node.Labels)) {
+			matchingKeys.Insert(appGroup.Name)
+			klog.V(2).Infof("Node %s matched AppliedToGroup %s", node.Name, appGroup.Name)
+		}
+	}
+	return matchingKeys
+}
+
+// nodeToAppliedToGroupMember is util function to convert a Node to a
+// GroupMember of AppliedToGroups. Like Pods, it only includes the reference to
+// the Node as the antrea-agent enforcing the policies knows the addresses of
+// its own Node.
+func nodeToAppliedToGroupMember(node *v1.Node) *controlplane.GroupMember {
+	return &controlplane.GroupMember{Node: &controlplane.NodeReference{Name: node.Name}}
+}
+
+// nodeToGroupMember is util function to convert a Node to a GroupMember type.
+// The GroupMember includes the InternalIP and ExternalIP addresses of the Node
+// and the gateway IPs of the Node.
+func nodeToGroupMember(node *v1.Node) *controlplane.GroupMember {
+	member := &controlplane.GroupMember{}
+	addrs := sets.NewString()
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP || addr.Type == v1.NodeExternalIP {
+			addrs.Insert(addr.Address)
+		}
+	}
+	addrs.Insert(nodeGatewayIPs(node)...)
+	// Use a sorted list so that the same Node always generates the same GroupMember.
+	for _, addr := range addrs.List() {
+		if ipAddr := ipStrToIPAddress(addr); ipAddr != nil {
+			member.IPs = append(member.IPs, ipAddr)
+		}
+	}
+	return member
+}
+
+// nodeGatewayIPs returns the gateway IPs published by the antrea-agent in the
+// Node annotation. If the annotation is absent, e.g. before the antrea-agent
+// has started on the Node, it returns the gateway IP of each PodCIDR allocated
+// to the Node, which is the first address of the PodCIDR.
+func nodeGatewayIPs(node *v1.Node) []string {
+	var gatewayIPs []string
+	if annotation, exists := node.Annotations[apis.NodeGatewayIPsAnnotationKey]; exists {
+		for _, gatewayIP := range strings.Split(annotation, ",") {
+			if gatewayIP = strings.TrimSpace(gatewayIP); gatewayIP != "" {
+				gatewayIPs = append(gatewayIPs, gatewayIP)
+			}
+		}
+		return gatewayIPs
+	}
+	podCIDRs := node.Spec.PodCIDRs
+	if len(podCIDRs) == 0 && node.Spec.PodCIDR != "" {
+		podCIDRs = []string{node.Spec.PodCIDR}
+	}
+	for _, podCIDR := range podCIDRs {
+		_, ipNet, err := net.ParseCIDR(podCIDR)
+		if err != nil {
+			klog.Errorf("Failed to parse PodCIDR %s of Node %s: %v", podCIDR, node.Name, err)
+			continue
+		}
+		gatewayIPs = append(gatewayIPs, ip.NextIP(ipNet.IP).String())
+	}
+	return gatewayIPs
+}
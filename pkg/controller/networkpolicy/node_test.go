@@ -0,0 +1,235 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis"
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func getNodeTestCNP(appliedTo, nodeSelector metav1.LabelSelector) *secv1alpha1.ClusterNetworkPolicy {
+	allowAction := secv1alpha1.RuleActionAllow
+	return &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnpA", UID: "uidA"},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{
+				{PodSelector: &appliedTo},
+			},
+			Priority: float64(1),
+			Egress: []secv1alpha1.Rule{
+				{
+					To: []secv1alpha1.NetworkPolicyPeer{
+						{NodeSelector: &nodeSelector},
+					},
+					Action: &allowAction,
+				},
+			},
+		},
+	}
+}
+
+func newTestNode(name string, nodeLabels map[string]string, internalIP, podCIDR string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: nodeLabels,
+		},
+		Spec: corev1.NodeSpec{
+			PodCIDR:  podCIDR,
+			PodCIDRs: []string{podCIDR},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: name},
+				{Type: corev1.NodeInternalIP, Address: internalIP},
+			},
+		},
+	}
+}
+
+func TestNodeToGroupMember(t *testing.T) {
+	node := newTestNode("nodeA", nil, "172.16.0.1", "10.10.1.0/24")
+	node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "8.8.8.8"})
+	expectedMember := &controlplane.GroupMember{
+		IPs: []controlplane.IPAddress{
+			ipStrToIPAddress("10.10.1.1"),
+			ipStrToIPAddress("172.16.0.1"),
+			ipStrToIPAddress("8.8.8.8"),
+		},
+	}
+	assert.Equal(t, expectedMember, nodeToGroupMember(node))
+
+	// The gateway IPs published in the Node annotation take precedence over
+	// the ones derived from the PodCIDRs.
+	node.Annotations = map[string]string{apis.NodeGatewayIPsAnnotationKey: "172.16.0.1,fd00:10:10:1::1"}
+	expectedMember = &controlplane.GroupMember{
+		IPs: []controlplane.IPAddress{
+			ipStrToIPAddress("172.16.0.1"),
+			ipStrToIPAddress("8.8.8.8"),
+			ipStrToIPAddress("fd00:10:10:1::1"),
+		},
+	}
+	assert.Equal(t, expectedMember, nodeToGroupMember(node))
+}
+
+func TestAddNode(t *testing.T) {
+	selectorSpec := metav1.LabelSelector{
+		MatchLabels: map[string]string{"group": "appliedTo"},
+	}
+	selectorNode := metav1.LabelSelector{
+		MatchLabels: map[string]string{"role": "master"},
+	}
+	tests := []struct {
+		name       string
+		addedNode  *corev1.Node
+		groupMatch bool
+	}{
+		{
+			"no-match-node",
+			newTestNode("nodeA", map[string]string{"role": "worker"}, "172.16.0.1", "10.10.1.0/24"),
+			false,
+		},
+		{
+			"node-match",
+			newTestNode("nodeB", map[string]string{"role": "master"}, "172.16.0.2", "10.10.2.0/24"),
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, npc := newController()
+			npc.addCNP(getNodeTestCNP(selectorSpec, selectorNode))
+			nodeGroupID := getNormalizedUID(toNodeGroupSelector(&selectorNode).NormalizedName)
+			// Drain the queues populated by the CNP ADD event.
+			getQueuedGroups(npc)
+
+			npc.nodeStore.Add(tt.addedNode)
+			npc.addNode(tt.addedNode)
+			_, addrGroups := getQueuedGroups(npc)
+			assert.Equal(t, tt.groupMatch, addrGroups.Has(nodeGroupID))
+
+			npc.syncAddressGroup(nodeGroupID)
+			updatedAddrGroupObj, _, _ := npc.addressGroupStore.Get(nodeGroupID)
+			updatedAddrGroup := updatedAddrGroupObj.(*antreatypes.AddressGroup)
+			assert.Equal(t, tt.groupMatch, updatedAddrGroup.GroupMembers.Has(nodeToGroupMember(tt.addedNode)))
+		})
+	}
+}
+
+func TestUpdateNode(t *testing.T) {
+	selectorSpec := metav1.LabelSelector{
+		MatchLabels: map[string]string{"group": "appliedTo"},
+	}
+	selectorNode := metav1.LabelSelector{
+		MatchLabels: map[string]string{"role": "master"},
+	}
+	nodeGroupID := getNormalizedUID(toNodeGroupSelector(&selectorNode).NormalizedName)
+	oldNode := newTestNode("nodeA", map[string]string{"role": "master"}, "172.16.0.1", "10.10.1.0/24")
+	tests := []struct {
+		name       string
+		curNode    *corev1.Node
+		groupMatch bool
+	}{
+		{
+			"no-change",
+			oldNode.DeepCopy(),
+			false,
+		},
+		{
+			"label-change",
+			newTestNode("nodeA", map[string]string{"role": "worker"}, "172.16.0.1", "10.10.1.0/24"),
+			true,
+		},
+		{
+			"address-change",
+			newTestNode("nodeA", map[string]string{"role": "master"}, "172.16.0.2", "10.10.1.0/24"),
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, npc := newController()
+			npc.addCNP(getNodeTestCNP(selectorSpec, selectorNode))
+			getQueuedGroups(npc)
+
+			npc.updateNode(oldNode, tt.curNode)
+			_, addrGroups := getQueuedGroups(npc)
+			assert.Equal(t, tt.groupMatch, addrGroups.Has(nodeGroupID))
+		})
+	}
+}
+
+func TestNodeGroupSelectorNotMatchingPods(t *testing.T) {
+	selectorNode := metav1.LabelSelector{}
+	_, npc := newController()
+	groupSelector := toNodeGroupSelector(&selectorNode)
+	pod := getPod("p1", "nsA", "nodeA", "1.1.1.1", false)
+	assert.False(t, npc.labelsMatchGroupSelector(pod, nil, groupSelector))
+}
+
+func TestNodeAppliedToGroup(t *testing.T) {
+	selectorNode := metav1.LabelSelector{
+		MatchLabels: map[string]string{"role": "master"},
+	}
+	cnp := getNodeTestCNP(metav1.LabelSelector{}, metav1.LabelSelector{})
+	cnp.Spec.AppliedTo = []secv1alpha1.NetworkPolicyPeer{{NodeSelector: &selectorNode}}
+	masterNode := newTestNode("nodeA", map[string]string{"role": "master"}, "172.16.0.1", "10.10.1.0/24")
+	workerNode := newTestNode("nodeB", map[string]string{"role": "worker"}, "172.16.0.2", "10.10.2.0/24")
+
+	_, npc := newController()
+	npc.nodeStore.Add(masterNode)
+	npc.nodeStore.Add(workerNode)
+	npc.addCNP(cnp)
+	nodeGroupID := getNormalizedUID(toNodeGroupSelector(&selectorNode).NormalizedName)
+	atGroups, _ := getQueuedGroups(npc)
+	assert.True(t, atGroups.Has(nodeGroupID))
+
+	npc.syncAppliedToGroup(nodeGroupID)
+	atGroupObj, _, _ := npc.appliedToGroupStore.Get(nodeGroupID)
+	atGroup := atGroupObj.(*antreatypes.AppliedToGroup)
+	expectedMembers := map[string]controlplane.GroupMemberSet{
+		"nodeA": controlplane.NewGroupMemberSet(&controlplane.GroupMember{Node: &controlplane.NodeReference{Name: "nodeA"}}),
+	}
+	assert.Equal(t, expectedMembers, atGroup.GroupMemberByNode)
+	assert.Equal(t, sets.NewString("nodeA"), atGroup.SpanMeta.NodeNames)
+
+	// A Node whose label change causes it to match the group triggers its processing.
+	updatedWorkerNode := workerNode.DeepCopy()
+	updatedWorkerNode.Labels = map[string]string{"role": "master"}
+	npc.nodeStore.Update(updatedWorkerNode)
+	npc.updateNode(workerNode, updatedWorkerNode)
+	atGroups, _ = getQueuedGroups(npc)
+	assert.True(t, atGroups.Has(nodeGroupID))
+
+	npc.syncAppliedToGroup(nodeGroupID)
+	atGroupObj, _, _ = npc.appliedToGroupStore.Get(nodeGroupID)
+	atGroup = atGroupObj.(*antreatypes.AppliedToGroup)
+	assert.Equal(t, sets.NewString("nodeA", "nodeB"), atGroup.SpanMeta.NodeNames)
+
+	// A change of the Node's addresses doesn't affect AppliedToGroups.
+	updatedMasterNode := newTestNode("nodeA", map[string]string{"role": "master"}, "172.16.0.3", "10.10.1.0/24")
+	npc.updateNode(masterNode, updatedMasterNode)
+	atGroups, _ = getQueuedGroups(npc)
+	assert.False(t, atGroups.Has(nodeGroupID))
+}
@@ -0,0 +1,1002 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	cpv1beta "github.com/vmware-tanzu/antrea/pkg/apis/controlplane/v1beta2"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+const (
+	// servicePrefix is the prefix of a destination referring to a Service.
+	servicePrefix = "svc:"
+)
+
+// draftPolicyCodecs is used to decode the draft policies of an evaluation request.
+var draftPolicyCodecs serializer.CodecFactory
+
+func init() {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	utilruntime.Must(secv1alpha1.AddToScheme(scheme))
+	draftPolicyCodecs = serializer.NewCodecFactory(scheme)
+}
+
+// PolicyEvaluator handles requests for antctl query networkpolicyevaluation.
+type PolicyEvaluator interface {
+	// EvaluateNetworkPolicies evaluates the K8s NetworkPolicies and Antrea-native policies, along
+	// with the draft policies of the request if any, against the traffic described by the request,
+	// and returns the verdict and the rules deciding it.
+	EvaluateNetworkPolicies(request *EvaluationRequest) (*EvaluationResponse, error)
+}
+
+// policyEvaluator implements the PolicyEvaluator interface.
+type policyEvaluator struct {
+	networkPolicyController *NetworkPolicyController
+	// serviceLister is able to get the Services referred to by the destinations of the requests.
+	serviceLister corelisters.ServiceLister
+}
+
+// EvaluationRequest describes the traffic to evaluate.
+type EvaluationRequest struct {
+	// Source is either a Pod in the form of "<namespace>/<name>", or an IP address.
+	Source string
+	// Destination is either a Pod in the form of "<namespace>/<name>", a Service in the form of
+	// "svc:<namespace>/<name>", or an IP address.
+	Destination string
+	// Protocol of the traffic, defaults to TCP.
+	Protocol v1.Protocol
+	// Port is the destination port of the traffic. It's the Service port if Destination is a Service.
+	Port int32
+	// DraftPolicies is a YAML or JSON stream of K8s NetworkPolicies, Antrea NetworkPolicies and
+	// Antrea ClusterNetworkPolicies which are evaluated as if they were applied. A draft policy
+	// replaces the existing policy with the same kind, Namespace and name.
+	DraftPolicies []byte
+}
+
+// EvaluationResponse is the reply struct for antctl networkpolicyevaluation queries.
+type EvaluationResponse struct {
+	Evaluations []Evaluation `json:"evaluations,omitempty"`
+}
+
+// Evaluation is the result of evaluating the traffic between a source and a destination. There
+// are multiple Evaluations in a response if the destination is a Service with multiple endpoints.
+type Evaluation struct {
+	Source      EvaluationEndpoint `json:"source"`
+	Destination EvaluationEndpoint `json:"destination"`
+	Protocol    v1.Protocol        `json:"protocol"`
+	Port        int32              `json:"port"`
+	// Verdict is Drop if the traffic is dropped in either direction, otherwise Allow.
+	Verdict secv1alpha1.RuleAction `json:"verdict"`
+	// Egress is the result of evaluating the policies applied to the source.
+	Egress DirectionEvaluation `json:"egress"`
+	// Ingress is the result of evaluating the policies applied to the destination.
+	Ingress DirectionEvaluation `json:"ingress"`
+}
+
+type EvaluationEndpoint struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+type DirectionEvaluation struct {
+	Verdict secv1alpha1.RuleAction `json:"verdict"`
+	// Rule is the rule deciding the verdict. It is nil if no rule matches the traffic, in which
+	// case the traffic is dropped if the endpoint is isolated by K8s NetworkPolicies, and allowed
+	// otherwise.
+	Rule *EvaluatedRule `json:"rule,omitempty"`
+	// Isolated is true if the endpoint is isolated by K8s NetworkPolicies in this direction.
+	Isolated bool `json:"isolated,omitempty"`
+	// AuditRules are the rules with the Audit action which match the traffic.
+	AuditRules []EvaluatedRule `json:"auditRules,omitempty"`
+}
+
+type EvaluatedRule struct {
+	PolicyRef
+	Type      cpv1beta.NetworkPolicyType `json:"type"`
+	Tier      string                     `json:"tier,omitempty"`
+	Direction cpv1beta.Direction         `json:"direction"`
+	RuleIndex int                        `json:"ruleindex"`
+	RuleName  string                     `json:"rulename,omitempty"`
+	Action    secv1alpha1.RuleAction     `json:"action"`
+	// Draft is true if the rule comes from a draft policy of the request.
+	Draft bool `json:"draft,omitempty"`
+}
+
+// evaluationPolicy is the representation of a policy used for evaluation. It is calculated from
+// the internal NetworkPolicy and the groups computed for it for an existing policy, and from the
+// spec of the policy for a draft policy, so that draft policies can be evaluated without adding
+// any group to the stores.
+type evaluationPolicy struct {
+	ref  EvaluatedRule
+	key  string
+	tier *int32
+	// priority is the priority of Antrea-native policies.
+	priority  float64
+	appliedTo []evaluationPeer
+	ingress   []evaluationRule
+	egress    []evaluationRule
+	// ingressIsolated and egressIsolated are only used by K8s NetworkPolicies.
+	ingressIsolated bool
+	egressIsolated  bool
+}
+
+type evaluationRule struct {
+	// index is the index of the rule in the ingress or egress rules of the original policy.
+	index  int
+	name   string
+	action secv1alpha1.RuleAction
+	// nil peers means all addresses are matched.
+	peers []evaluationPeer
+	// nil ports means all ports are matched.
+	ports []evaluationPort
+}
+
+type evaluationPeer struct {
+	// If namespace is set, only Pods in the Namespace are matched.
+	namespace         string
+	podSelector       labels.Selector
+	namespaceSelector labels.Selector
+	// nodeIPs are the addresses of the Nodes selected by a nodeSelector.
+	nodeIPs sets.String
+	// members are the GroupMembers of an AppliedToGroup or an AddressGroup. If it is not nil,
+	// only the GroupMembers are matched and the selectors are not used.
+	members controlplane.GroupMemberSet
+	ipBlock *evaluationIPBlock
+}
+
+type evaluationIPBlock struct {
+	cidr   *net.IPNet
+	except []*net.IPNet
+}
+
+type evaluationPort struct {
+	protocol v1.Protocol
+	port     *intstr.IntOrString
+}
+
+// evaluationEndpoint is a resolved source or destination.
+type evaluationEndpoint struct {
+	// pod is nil if the endpoint is not a Pod to which policies can be applied.
+	pod       *v1.Pod
+	namespace *v1.Namespace
+	ip        net.IP
+	ref       EvaluationEndpoint
+}
+
+// NewPolicyEvaluator returns a new *policyEvaluator.
+func NewPolicyEvaluator(networkPolicyController *NetworkPolicyController, serviceInformer coreinformers.ServiceInformer) *policyEvaluator {
+	return &policyEvaluator{
+		networkPolicyController: networkPolicyController,
+		serviceLister:           serviceInformer.Lister(),
+	}
+}
+
+// EvaluateNetworkPolicies evaluates the policies for the traffic in the request. The policies are
+// evaluated in the same order as in the datapath: Antrea-native policies in non-baseline Tiers,
+// K8s NetworkPolicies, then Antrea-native policies in the baseline Tier. The traffic must be
+// allowed by the policies applied to the source and the policies applied to the destination.
+func (e *policyEvaluator) EvaluateNetworkPolicies(request *EvaluationRequest) (*EvaluationResponse, error) {
+	protocol := request.Protocol
+	if protocol == "" {
+		protocol = v1.ProtocolTCP
+	}
+	if protocol != v1.ProtocolTCP && protocol != v1.ProtocolUDP && protocol != v1.ProtocolSCTP {
+		return nil, fmt.Errorf("unsupported protocol %s", protocol)
+	}
+	if request.Port <= 0 || request.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", request.Port)
+	}
+	policies, err := e.getPolicies(request.DraftPolicies)
+	if err != nil {
+		return nil, err
+	}
+	src, err := e.resolveEndpoint(request.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source: %v", err)
+	}
+	var dsts []*evaluationEndpoint
+	var ports []int32
+	if strings.HasPrefix(request.Destination, servicePrefix) {
+		dsts, ports, err = e.resolveService(strings.TrimPrefix(request.Destination, servicePrefix), protocol, request.Port)
+	} else {
+		var dst *evaluationEndpoint
+		dst, err = e.resolveEndpoint(request.Destination)
+		dsts, ports = []*evaluationEndpoint{dst}, []int32{request.Port}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %v", err)
+	}
+	response := &EvaluationResponse{}
+	for i, dst := range dsts {
+		egress := evaluateDirection(policies, cpv1beta.DirectionOut, src, dst, dst, protocol, ports[i])
+		ingress := evaluateDirection(policies, cpv1beta.DirectionIn, dst, src, dst, protocol, ports[i])
+		verdict := secv1alpha1.RuleActionAllow
+		if egress.Verdict == secv1alpha1.RuleActionDrop || ingress.Verdict == secv1alpha1.RuleActionDrop {
+			verdict = secv1alpha1.RuleActionDrop
+		}
+		response.Evaluations = append(response.Evaluations, Evaluation{
+			Source:      src.ref,
+			Destination: dst.ref,
+			Protocol:    protocol,
+			Port:        ports[i],
+			Verdict:     verdict,
+			Egress:      egress,
+			Ingress:     ingress,
+		})
+	}
+	return response, nil
+}
+
+// evaluateDirection evaluates the policies applied to the endpoint "applied" for the traffic
+// between the endpoint and its peer in the provided direction.
+func evaluateDirection(policies []*evaluationPolicy, direction cpv1beta.Direction, applied, peer, dst *evaluationEndpoint, protocol v1.Protocol, port int32) DirectionEvaluation {
+	result := DirectionEvaluation{Verdict: secv1alpha1.RuleActionAllow}
+	if applied.pod == nil {
+		// Policies are only applied to Pods.
+		return result
+	}
+	var antreaPolicies, k8sPolicies, baselinePolicies []*evaluationPolicy
+	for _, p := range policies {
+		if !p.appliesTo(applied) {
+			continue
+		}
+		if p.tier == nil {
+			k8sPolicies = append(k8sPolicies, p)
+		} else if *p.tier == BaselineTierPriority {
+			baselinePolicies = append(baselinePolicies, p)
+		} else {
+			antreaPolicies = append(antreaPolicies, p)
+		}
+	}
+	// matchFirstRule returns the first rule matching the traffic in the provided policies. Audit
+	// rules are recorded and the evaluation continues with the next rules, like in the datapath.
+	matchFirstRule := func(policies []*evaluationPolicy) *EvaluatedRule {
+		for _, p := range policies {
+			rules := p.ingress
+			if direction == cpv1beta.DirectionOut {
+				rules = p.egress
+			}
+			for _, r := range rules {
+				if !r.matches(peer, dst, protocol, port) {
+					continue
+				}
+				rule := p.ref
+				rule.Direction = direction
+				rule.RuleIndex = r.index
+				rule.RuleName = r.name
+				rule.Action = r.action
+				if r.action == secv1alpha1.RuleActionAudit {
+					result.AuditRules = append(result.AuditRules, rule)
+					continue
+				}
+				return &rule
+			}
+		}
+		return nil
+	}
+	if rule := matchFirstRule(antreaPolicies); rule != nil {
+		result.Verdict, result.Rule = rule.Action, rule
+		return result
+	}
+	for _, p := range k8sPolicies {
+		if direction == cpv1beta.DirectionIn && p.ingressIsolated || direction == cpv1beta.DirectionOut && p.egressIsolated {
+			result.Isolated = true
+		}
+	}
+	if rule := matchFirstRule(k8sPolicies); rule != nil {
+		result.Verdict, result.Rule = rule.Action, rule
+		return result
+	}
+	if result.Isolated {
+		result.Verdict = secv1alpha1.RuleActionDrop
+		return result
+	}
+	if rule := matchFirstRule(baselinePolicies); rule != nil {
+		result.Verdict, result.Rule = rule.Action, rule
+	}
+	return result
+}
+
+func (p *evaluationPolicy) appliesTo(ep *evaluationEndpoint) bool {
+	for _, peer := range p.appliedTo {
+		if peer.matchesPod(ep) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *evaluationRule) matches(peer, dst *evaluationEndpoint, protocol v1.Protocol, port int32) bool {
+	if r.ports != nil {
+		portMatched := false
+		for _, p := range r.ports {
+			if p.matches(dst, protocol, port) {
+				portMatched = true
+				break
+			}
+		}
+		if !portMatched {
+			return false
+		}
+	}
+	if r.peers == nil {
+		return true
+	}
+	for _, p := range r.peers {
+		if p.matches(peer) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *evaluationPort) matches(dst *evaluationEndpoint, protocol v1.Protocol, port int32) bool {
+	if p.protocol != protocol {
+		return false
+	}
+	if p.port == nil {
+		return true
+	}
+	if p.port.Type == intstr.Int {
+		return p.port.IntVal == port
+	}
+	// Named ports are resolved with the container ports of the destination Pod.
+	if dst.pod == nil {
+		return false
+	}
+	return resolveNamedPort(dst.pod, p.port.StrVal, protocol) == port
+}
+
+func (p *evaluationPeer) matches(ep *evaluationEndpoint) bool {
+	if p.members != nil {
+		return p.matchesMember(ep)
+	}
+	if p.nodeIPs != nil {
+		return p.nodeIPs.Has(ep.ip.String())
+	}
+	if p.ipBlock != nil {
+		if p.ipBlock.cidr == nil || !p.ipBlock.cidr.Contains(ep.ip) {
+			return false
+		}
+		for _, except := range p.ipBlock.except {
+			if except.Contains(ep.ip) {
+				return false
+			}
+		}
+		return true
+	}
+	return p.matchesPod(ep)
+}
+
+func (p *evaluationPeer) matchesPod(ep *evaluationEndpoint) bool {
+	if ep.pod == nil {
+		return false
+	}
+	if p.members != nil {
+		return p.matchesMember(ep)
+	}
+	if p.namespace != "" && p.namespace != ep.pod.Namespace {
+		return false
+	}
+	if p.namespaceSelector != nil && (ep.namespace == nil || !p.namespaceSelector.Matches(labels.Set(ep.namespace.Labels))) {
+		return false
+	}
+	if p.podSelector != nil && !p.podSelector.Matches(labels.Set(ep.pod.Labels)) {
+		return false
+	}
+	return p.podSelector != nil || p.namespaceSelector != nil
+}
+
+// matchesMember returns whether the endpoint is one of the GroupMembers of the peer. A Pod is
+// matched by its reference, and any other endpoint, e.g. a Node, by its IP address.
+func (p *evaluationPeer) matchesMember(ep *evaluationEndpoint) bool {
+	for _, member := range p.members {
+		if member.Pod != nil && ep.pod != nil {
+			if member.Pod.Namespace == ep.pod.Namespace && member.Pod.Name == ep.pod.Name {
+				return true
+			}
+			continue
+		}
+		for _, ip := range member.IPs {
+			if ep.ip.Equal(net.IP(ip)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resolveNamedPort(pod *v1.Pod, name string, protocol v1.Protocol) int32 {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			portProtocol := port.Protocol
+			if portProtocol == "" {
+				portProtocol = v1.ProtocolTCP
+			}
+			if port.Name == name && portProtocol == protocol {
+				return port.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+// resolveEndpoint resolves a Pod in the form of "<namespace>/<name>", or an IP address. An IP
+// address is resolved to the Pod owning it if there is one.
+func (e *policyEvaluator) resolveEndpoint(endpoint string) (*evaluationEndpoint, error) {
+	n := e.networkPolicyController
+	if ip := net.ParseIP(endpoint); ip != nil {
+		pods, err := n.podLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			if pod.Spec.HostNetwork {
+				continue
+			}
+			for _, podIP := range pod.Status.PodIPs {
+				if ip.Equal(net.ParseIP(podIP.IP)) {
+					return e.podEndpoint(pod, ip), nil
+				}
+			}
+		}
+		return &evaluationEndpoint{ip: ip, ref: EvaluationEndpoint{IP: ip.String()}}, nil
+	}
+	namespace, name := "default", endpoint
+	if parts := strings.Split(endpoint, "/"); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	} else if len(parts) > 2 {
+		return nil, fmt.Errorf("%s is neither an IP address nor a Pod", endpoint)
+	}
+	pod, err := n.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(pod.Status.PodIP)
+	if ip == nil {
+		return nil, fmt.Errorf("Pod %s/%s has no IP address", namespace, name)
+	}
+	return e.podEndpoint(pod, ip), nil
+}
+
+func (e *policyEvaluator) podEndpoint(pod *v1.Pod, ip net.IP) *evaluationEndpoint {
+	ep := &evaluationEndpoint{
+		ip:  ip,
+		ref: EvaluationEndpoint{Namespace: pod.Namespace, Name: pod.Name, IP: ip.String()},
+	}
+	// Policies are not applied to hostNetwork Pods, which are treated as IP addresses.
+	if !pod.Spec.HostNetwork {
+		ep.pod = pod
+		ep.namespace, _ = e.networkPolicyController.namespaceLister.Get(pod.Namespace)
+	}
+	return ep
+}
+
+// resolveService resolves a Service in the form of "<namespace>/<name>" to the Pods selected by
+// it, along with the target port of each Pod.
+func (e *policyEvaluator) resolveService(service string, protocol v1.Protocol, port int32) ([]*evaluationEndpoint, []int32, error) {
+	n := e.networkPolicyController
+	parts := strings.Split(service, "/")
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("Service %s must be in the form of <namespace>/<name>", service)
+	}
+	svc, err := e.serviceLister.Services(parts[0]).Get(parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	var servicePort *v1.ServicePort
+	for i := range svc.Spec.Ports {
+		p := &svc.Spec.Ports[i]
+		if p.Port == port && (p.Protocol == protocol || p.Protocol == "" && protocol == v1.ProtocolTCP) {
+			servicePort = p
+			break
+		}
+	}
+	if servicePort == nil {
+		return nil, nil, fmt.Errorf("Service %s has no %s port %d", service, protocol, port)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil, fmt.Errorf("Service %s has no selector", service)
+	}
+	pods, err := n.podLister.Pods(svc.Namespace).List(labels.SelectorFromSet(svc.Spec.Selector))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	var endpoints []*evaluationEndpoint
+	var targetPorts []int32
+	for _, pod := range pods {
+		ip := net.ParseIP(pod.Status.PodIP)
+		if ip == nil {
+			continue
+		}
+		targetPort := port
+		if servicePort.TargetPort.Type == intstr.String {
+			targetPort = resolveNamedPort(pod, servicePort.TargetPort.StrVal, protocol)
+		} else if servicePort.TargetPort.IntVal != 0 {
+			targetPort = servicePort.TargetPort.IntVal
+		}
+		if targetPort == 0 {
+			continue
+		}
+		endpoints = append(endpoints, e.podEndpoint(pod, ip))
+		targetPorts = append(targetPorts, targetPort)
+	}
+	if len(endpoints) == 0 {
+		return nil, nil, fmt.Errorf("Service %s has no endpoints", service)
+	}
+	return endpoints, targetPorts, nil
+}
+
+// getPolicies returns all the policies to evaluate, including the draft policies which replace
+// the existing policies with the same kind, Namespace and name.
+func (e *policyEvaluator) getPolicies(draftPolicies []byte) ([]*evaluationPolicy, error) {
+	n := e.networkPolicyController
+	drafts, err := e.decodeDraftPolicies(draftPolicies)
+	if err != nil {
+		return nil, err
+	}
+	draftKeys := make(map[string]bool, len(drafts))
+	for _, p := range drafts {
+		draftKeys[p.key] = true
+	}
+	policies := drafts
+	addPolicy := func(p *evaluationPolicy) {
+		if !draftKeys[p.key] {
+			policies = append(policies, p)
+		}
+	}
+	// The existing policies are evaluated with the internal NetworkPolicies and the groups
+	// computed for them, which are what the agents enforce.
+	for _, obj := range n.internalNetworkPolicyStore.List() {
+		addPolicy(e.toEvaluationPolicy(obj.(*antreatypes.NetworkPolicy)))
+	}
+	// Sort policies by their effective priorities. Policies with the same priority are
+	// sorted by key to get a stable result.
+	sort.SliceStable(policies, func(i, j int) bool {
+		pi, pj := policies[i], policies[j]
+		if pi.tier != nil && pj.tier != nil {
+			if *pi.tier != *pj.tier {
+				return *pi.tier < *pj.tier
+			}
+			if pi.priority != pj.priority {
+				return pi.priority < pj.priority
+			}
+		}
+		return pi.key < pj.key
+	})
+	return policies, nil
+}
+
+// decodeDraftPolicies decodes the draft policies from a YAML or JSON stream.
+func (e *policyEvaluator) decodeDraftPolicies(data []byte) ([]*evaluationPolicy, error) {
+	n := e.networkPolicyController
+	var policies []*evaluationPolicy
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read draft policies: %v", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := draftPolicyCodecs.UniversalDeserializer().Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode draft policy: %v", err)
+		}
+		switch p := obj.(type) {
+		case *networkingv1.NetworkPolicy:
+			if p.Namespace == "" {
+				p.Namespace = "default"
+			}
+			policy := toEvaluationPolicyForK8sNP(p)
+			policy.ref.Draft = true
+			policies = append(policies, policy)
+		case *secv1alpha1.ClusterNetworkPolicy, *secv1alpha1.NetworkPolicy:
+			if n.tierLister == nil {
+				return nil, fmt.Errorf("draft Antrea-native policies cannot be evaluated when AntreaPolicy feature is disabled")
+			}
+			var policy *evaluationPolicy
+			if cnp, ok := p.(*secv1alpha1.ClusterNetworkPolicy); ok {
+				tierPriority, err := e.getDraftTierPriority(cnp.Spec.Tier)
+				if err != nil {
+					return nil, err
+				}
+				policy = e.toEvaluationPolicyForAntreaNP(cpv1beta.AntreaClusterNetworkPolicy, &cnp.ObjectMeta, "", cnp.Spec.Tier, tierPriority, cnp.Spec.Priority, cnp.Spec.AppliedTo, cnp.Spec.Ingress, cnp.Spec.Egress)
+			} else {
+				anp := p.(*secv1alpha1.NetworkPolicy)
+				if anp.Namespace == "" {
+					anp.Namespace = "default"
+				}
+				tierPriority, err := e.getDraftTierPriority(anp.Spec.Tier)
+				if err != nil {
+					return nil, err
+				}
+				policy = e.toEvaluationPolicyForAntreaNP(cpv1beta.AntreaNetworkPolicy, &anp.ObjectMeta, anp.Namespace, anp.Spec.Tier, tierPriority, anp.Spec.Priority, anp.Spec.AppliedTo, anp.Spec.Ingress, anp.Spec.Egress)
+			}
+			policy.ref.Draft = true
+			policies = append(policies, policy)
+		default:
+			return nil, fmt.Errorf("unsupported draft policy type %T", obj)
+		}
+	}
+	return policies, nil
+}
+
+// getDraftTierPriority returns the priority of the Tier referenced by a draft policy. Unlike
+// getTierPriority, it returns an error if the Tier does not exist, as such a policy would be
+// rejected by the validation webhook.
+func (e *policyEvaluator) getDraftTierPriority(tier string) (int32, error) {
+	n := e.networkPolicyController
+	if tier == "" {
+		return DefaultTierPriority, nil
+	}
+	if staticTierSet.Has(tier) {
+		tier = strings.ToLower(tier)
+	}
+	t, err := n.tierLister.Get(tier)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get Tier %s of draft policy: %v", tier, err)
+	}
+	return t.Spec.Priority, nil
+}
+
+// toEvaluationPolicy converts an internal NetworkPolicy to an evaluationPolicy, using the members
+// of its AppliedToGroups and AddressGroups.
+func (e *policyEvaluator) toEvaluationPolicy(np *antreatypes.NetworkPolicy) *evaluationPolicy {
+	n := e.networkPolicyController
+	policyType := cpv1beta.NetworkPolicyType(np.SourceRef.Type)
+	policy := &evaluationPolicy{
+		ref: EvaluatedRule{
+			PolicyRef: PolicyRef{Namespace: np.SourceRef.Namespace, Name: np.SourceRef.Name, UID: np.SourceRef.UID},
+			Type:      policyType,
+		},
+		key:  fmt.Sprintf("%s/%s/%s", policyType, np.SourceRef.Namespace, np.SourceRef.Name),
+		tier: np.TierPriority,
+	}
+	if np.Priority != nil {
+		policy.priority = *np.Priority
+	}
+	for _, groupName := range np.AppliedToGroups {
+		obj, found, _ := n.appliedToGroupStore.Get(groupName)
+		if !found {
+			continue
+		}
+		members := controlplane.GroupMemberSet{}
+		for _, nodeMembers := range obj.(*antreatypes.AppliedToGroup).GroupMemberByNode {
+			members = members.Union(nodeMembers)
+		}
+		policy.appliedTo = append(policy.appliedTo, evaluationPeer{members: members})
+	}
+	toPeers := func(peer controlplane.NetworkPolicyPeer) []evaluationPeer {
+		// Unlike the spec, an internal rule without any peer matches nothing.
+		result := []evaluationPeer{}
+		for _, groupName := range peer.AddressGroups {
+			members := controlplane.GroupMemberSet{}
+			if obj, found, _ := n.addressGroupStore.Get(groupName); found {
+				members = obj.(*antreatypes.AddressGroup).GroupMembers
+			}
+			result = append(result, evaluationPeer{members: members})
+		}
+		for _, ipBlock := range peer.IPBlocks {
+			result = append(result, evaluationPeer{ipBlock: toEvaluationIPBlockForInternalIPBlock(ipBlock)})
+		}
+		return result
+	}
+	var ingressNames, egressNames []string
+	if policy.tier != nil {
+		policy.ref.Tier, ingressNames, egressNames = e.getAntreaPolicyNames(np.SourceRef)
+	}
+	var ingressIndex, egressIndex int
+	for _, rule := range np.Rules {
+		r := evaluationRule{action: secv1alpha1.RuleActionAllow}
+		if rule.Action != nil {
+			r.action = *rule.Action
+		}
+		for _, service := range rule.Services {
+			protocol := v1.ProtocolTCP
+			if service.Protocol != nil {
+				protocol = v1.Protocol(*service.Protocol)
+			}
+			r.ports = append(r.ports, evaluationPort{protocol: protocol, port: service.Port})
+		}
+		// The rules of Antrea-native policies have their index in the original policy as
+		// priority, as the inactive rules are not present in the internal NetworkPolicy.
+		if rule.Direction == controlplane.DirectionIn {
+			r.index = ingressIndex
+			if policy.tier != nil {
+				r.index = int(rule.Priority)
+				if r.index < len(ingressNames) {
+					r.name = ingressNames[r.index]
+				}
+			}
+			r.peers = toPeers(rule.From)
+			policy.ingress = append(policy.ingress, r)
+			policy.ingressIsolated = true
+			ingressIndex++
+		} else {
+			r.index = egressIndex
+			if policy.tier != nil {
+				r.index = int(rule.Priority)
+				if r.index < len(egressNames) {
+					r.name = egressNames[r.index]
+				}
+			}
+			r.peers = toPeers(rule.To)
+			policy.egress = append(policy.egress, r)
+			policy.egressIsolated = true
+			egressIndex++
+		}
+	}
+	return policy
+}
+
+// getAntreaPolicyNames returns the Tier and the rule names of an Antrea-native policy, which are
+// not part of the internal NetworkPolicy.
+func (e *policyEvaluator) getAntreaPolicyNames(ref *controlplane.NetworkPolicyReference) (string, []string, []string) {
+	n := e.networkPolicyController
+	var tier string
+	var ingress, egress []secv1alpha1.Rule
+	if ref.Type == controlplane.AntreaClusterNetworkPolicy && n.cnpLister != nil {
+		cnp, err := n.cnpLister.Get(ref.Name)
+		if err != nil {
+			return defaultTierName, nil, nil
+		}
+		tier, ingress, egress = cnp.Spec.Tier, cnp.Spec.Ingress, cnp.Spec.Egress
+	} else if ref.Type == controlplane.AntreaNetworkPolicy && n.anpLister != nil {
+		anp, err := n.anpLister.NetworkPolicies(ref.Namespace).Get(ref.Name)
+		if err != nil {
+			return defaultTierName, nil, nil
+		}
+		tier, ingress, egress = anp.Spec.Tier, anp.Spec.Ingress, anp.Spec.Egress
+	}
+	if tier == "" {
+		tier = defaultTierName
+	}
+	ruleNames := func(rules []secv1alpha1.Rule) []string {
+		names := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			names = append(names, rule.Name)
+		}
+		return names
+	}
+	return tier, ruleNames(ingress), ruleNames(egress)
+}
+
+func toEvaluationPolicyForK8sNP(np *networkingv1.NetworkPolicy) *evaluationPolicy {
+	policy := &evaluationPolicy{
+		ref: EvaluatedRule{
+			PolicyRef: PolicyRef{Namespace: np.Namespace, Name: np.Name, UID: np.UID},
+			Type:      cpv1beta.K8sNetworkPolicy,
+		},
+		key:       fmt.Sprintf("%s/%s/%s", cpv1beta.K8sNetworkPolicy, np.Namespace, np.Name),
+		appliedTo: []evaluationPeer{{namespace: np.Namespace, podSelector: toSelector(&np.Spec.PodSelector)}},
+	}
+	toPeers := func(peers []networkingv1.NetworkPolicyPeer) []evaluationPeer {
+		if len(peers) == 0 {
+			return nil
+		}
+		result := []evaluationPeer{}
+		for _, peer := range peers {
+			if peer.IPBlock != nil {
+				result = append(result, evaluationPeer{ipBlock: toEvaluationIPBlock(peer.IPBlock.CIDR, peer.IPBlock.Except)})
+				continue
+			}
+			p := evaluationPeer{
+				podSelector:       toSelector(peer.PodSelector),
+				namespaceSelector: toSelector(peer.NamespaceSelector),
+			}
+			if p.namespaceSelector == nil {
+				p.namespace = np.Namespace
+			}
+			result = append(result, p)
+		}
+		return result
+	}
+	toPorts := func(ports []networkingv1.NetworkPolicyPort) []evaluationPort {
+		if len(ports) == 0 {
+			return nil
+		}
+		result := []evaluationPort{}
+		for _, port := range ports {
+			protocol := v1.ProtocolTCP
+			if port.Protocol != nil {
+				protocol = *port.Protocol
+			}
+			result = append(result, evaluationPort{protocol: protocol, port: port.Port})
+		}
+		return result
+	}
+	for i, rule := range np.Spec.Ingress {
+		policy.ingress = append(policy.ingress, evaluationRule{index: i, action: secv1alpha1.RuleActionAllow, peers: toPeers(rule.From), ports: toPorts(rule.Ports)})
+	}
+	for i, rule := range np.Spec.Egress {
+		policy.egress = append(policy.egress, evaluationRule{index: i, action: secv1alpha1.RuleActionAllow, peers: toPeers(rule.To), ports: toPorts(rule.Ports)})
+	}
+	if len(np.Spec.PolicyTypes) == 0 {
+		// Same defaulting as the K8s apiserver.
+		policy.ingressIsolated = true
+		policy.egressIsolated = len(np.Spec.Egress) > 0
+	}
+	for _, policyType := range np.Spec.PolicyTypes {
+		if policyType == networkingv1.PolicyTypeIngress {
+			policy.ingressIsolated = true
+		} else if policyType == networkingv1.PolicyTypeEgress {
+			policy.egressIsolated = true
+		}
+	}
+	return policy
+}
+
+// toEvaluationPolicyForAntreaNP converts a draft Antrea ClusterNetworkPolicy or Antrea
+// NetworkPolicy to an evaluationPolicy. namespace is empty for Antrea ClusterNetworkPolicies.
+func (e *policyEvaluator) toEvaluationPolicyForAntreaNP(policyType cpv1beta.NetworkPolicyType, meta *metav1.ObjectMeta, namespace, tier string, tierPriority int32, priority float64, appliedTo []secv1alpha1.NetworkPolicyPeer, ingress, egress []secv1alpha1.Rule) *evaluationPolicy {
+	if tier == "" {
+		tier = defaultTierName
+	}
+	policy := &evaluationPolicy{
+		ref: EvaluatedRule{
+			PolicyRef: PolicyRef{Namespace: namespace, Name: meta.Name, UID: meta.UID},
+			Type:      policyType,
+			Tier:      tier,
+		},
+		key:      fmt.Sprintf("%s/%s/%s", policyType, namespace, meta.Name),
+		tier:     &tierPriority,
+		priority: priority,
+	}
+	toPeers := func(peers []secv1alpha1.NetworkPolicyPeer) []evaluationPeer {
+		if len(peers) == 0 {
+			return nil
+		}
+		result := []evaluationPeer{}
+		for _, peer := range peers {
+			if peer.IPBlock != nil {
+				result = append(result, evaluationPeer{ipBlock: toEvaluationIPBlock(peer.IPBlock.CIDR, peer.IPBlock.Except)})
+				continue
+			}
+			if peer.NodeSelector != nil {
+				result = append(result, evaluationPeer{nodeIPs: e.getNodeIPs(peer.NodeSelector)})
+				continue
+			}
+			if peer.ExternalEntitySelector != nil {
+				// ExternalEntities are not supported by the evaluation.
+				continue
+			}
+			p := evaluationPeer{
+				podSelector:       toSelector(peer.PodSelector),
+				namespaceSelector: toSelector(peer.NamespaceSelector),
+			}
+			if p.namespaceSelector == nil {
+				p.namespace = namespace
+			}
+			result = append(result, p)
+		}
+		return result
+	}
+	now := time.Now()
+	toRules := func(rules []secv1alpha1.Rule, egress bool) []evaluationRule {
+		var result []evaluationRule
+		for i, rule := range rules {
+			// The rules which are not active at the moment are not enforced.
+			if !isRuleActive(rule.Schedule, now) {
+				continue
+			}
+			r := evaluationRule{index: i, name: rule.Name, action: secv1alpha1.RuleActionAllow}
+			if rule.Action != nil {
+				r.action = *rule.Action
+			}
+			if egress {
+				r.peers = toPeers(rule.To)
+			} else {
+				r.peers = toPeers(rule.From)
+			}
+			for _, port := range rule.Ports {
+				protocol := v1.ProtocolTCP
+				if port.Protocol != nil {
+					protocol = *port.Protocol
+				}
+				r.ports = append(r.ports, evaluationPort{protocol: protocol, port: port.Port})
+			}
+			result = append(result, r)
+		}
+		return result
+	}
+	policy.appliedTo = toPeers(appliedTo)
+	policy.ingress = toRules(ingress, false)
+	policy.egress = toRules(egress, true)
+	return policy
+}
+
+// getNodeIPs returns the addresses of the Nodes selected by nodeSelector, which are the same as
+// the addresses of the GroupMembers calculated for nodeSelector peers.
+func (e *policyEvaluator) getNodeIPs(nodeSelector *metav1.LabelSelector) sets.String {
+	ips := sets.NewString()
+	nodes, _ := e.networkPolicyController.nodeLister.List(toSelector(nodeSelector))
+	for _, node := range nodes {
+		for _, ip := range nodeToGroupMember(node).IPs {
+			ips.Insert(net.IP(ip).String())
+		}
+	}
+	return ips
+}
+
+func toSelector(selector *metav1.LabelSelector) labels.Selector {
+	if selector == nil {
+		return nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return s
+}
+
+func toEvaluationIPBlock(cidr string, except []string) *evaluationIPBlock {
+	// An invalid CIDR is left nil and matches nothing.
+	ipBlock := &evaluationIPBlock{}
+	_, ipBlock.cidr, _ = net.ParseCIDR(cidr)
+	for _, e := range except {
+		if _, exceptNet, err := net.ParseCIDR(e); err == nil {
+			ipBlock.except = append(ipBlock.except, exceptNet)
+		}
+	}
+	return ipBlock
+}
+
+func toEvaluationIPBlockForInternalIPBlock(ipBlock controlplane.IPBlock) *evaluationIPBlock {
+	toIPNet := func(ipNet controlplane.IPNet) *net.IPNet {
+		ip := net.IP(ipNet.IP)
+		bits := net.IPv4len * 8
+		if ip.To4() == nil {
+			bits = net.IPv6len * 8
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(ipNet.PrefixLength), bits)}
+	}
+	result := &evaluationIPBlock{cidr: toIPNet(ipBlock.CIDR)}
+	for _, except := range ipBlock.Except {
+		result.except = append(result.except, toIPNet(except))
+	}
+	return result
+}
@@ -0,0 +1,305 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func newEvaluatorTestController() *networkPolicyController {
+	_, npc := newController()
+	npc.cnpLister = npc.crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies().Lister()
+	npc.namespaceStore.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "nsA"}})
+	client := getPod("client", "nsA", "", "10.0.0.1", false)
+	client.Labels = map[string]string{"app": "client"}
+	server := getPod("server", "nsA", "", "10.0.0.2", false)
+	server.Labels = map[string]string{"app": "server"}
+	npc.podStore.Add(client)
+	npc.podStore.Add(server)
+	return npc
+}
+
+// syncEvaluatorTestGroups computes the members of the groups created for the policies, which are
+// used to evaluate the existing policies.
+func syncEvaluatorTestGroups(t *testing.T, npc *networkPolicyController) {
+	for _, obj := range npc.appliedToGroupStore.List() {
+		require.NoError(t, npc.syncAppliedToGroup(obj.(*antreatypes.AppliedToGroup).Name))
+	}
+	for _, obj := range npc.addressGroupStore.List() {
+		require.NoError(t, npc.syncAddressGroup(obj.(*antreatypes.AddressGroup).Name))
+	}
+}
+
+func TestEvaluateNetworkPolicies(t *testing.T) {
+	dropAction := secv1alpha1.RuleActionDrop
+	auditAction := secv1alpha1.RuleActionAudit
+	serverSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "server"}}
+	clientSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}
+	k8sNP := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nsA", Name: "npA", UID: "uidA"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: serverSelector,
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{{Port: &int80}},
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &clientSelector}},
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	tierCNP := func(name, tier string, action *secv1alpha1.RuleAction) *secv1alpha1.ClusterNetworkPolicy {
+		return &secv1alpha1.ClusterNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID("uid-" + name)},
+			Spec: secv1alpha1.ClusterNetworkPolicySpec{
+				Tier:      tier,
+				Priority:  1,
+				AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &serverSelector}},
+				Ingress: []secv1alpha1.Rule{
+					{
+						Action: action,
+						From:   []secv1alpha1.NetworkPolicyPeer{{PodSelector: &clientSelector}},
+					},
+				},
+			},
+		}
+	}
+	cnp := func(action *secv1alpha1.RuleAction) *secv1alpha1.ClusterNetworkPolicy {
+		return tierCNP("cnpA", "", action)
+	}
+	tests := []struct {
+		name            string
+		k8sNPs          []*networkingv1.NetworkPolicy
+		cnps            []*secv1alpha1.ClusterNetworkPolicy
+		port            int32
+		expVerdict      secv1alpha1.RuleAction
+		expIsolated     bool
+		expIngressRule  bool
+		expAuditedRules int
+	}{
+		{
+			name:       "no-policy",
+			port:       80,
+			expVerdict: secv1alpha1.RuleActionAllow,
+		},
+		{
+			name:           "k8s-np-allow",
+			k8sNPs:         []*networkingv1.NetworkPolicy{k8sNP},
+			port:           80,
+			expVerdict:     secv1alpha1.RuleActionAllow,
+			expIsolated:    true,
+			expIngressRule: true,
+		},
+		{
+			name:        "k8s-np-isolated",
+			k8sNPs:      []*networkingv1.NetworkPolicy{k8sNP},
+			port:        81,
+			expVerdict:  secv1alpha1.RuleActionDrop,
+			expIsolated: true,
+		},
+		{
+			name:           "cnp-drop",
+			k8sNPs:         []*networkingv1.NetworkPolicy{k8sNP},
+			cnps:           []*secv1alpha1.ClusterNetworkPolicy{cnp(&dropAction)},
+			port:           80,
+			expVerdict:     secv1alpha1.RuleActionDrop,
+			expIngressRule: true,
+		},
+		{
+			name:            "cnp-audit",
+			k8sNPs:          []*networkingv1.NetworkPolicy{k8sNP},
+			cnps:            []*secv1alpha1.ClusterNetworkPolicy{cnp(&auditAction)},
+			port:            80,
+			expVerdict:      secv1alpha1.RuleActionAllow,
+			expIsolated:     true,
+			expIngressRule:  true,
+			expAuditedRules: 1,
+		},
+		{
+			// The evaluation continues after the Audit rule, and the traffic is
+			// dropped by the rule of the lower Tier.
+			name:            "cnp-audit-then-drop-in-lower-tier",
+			k8sNPs:          []*networkingv1.NetworkPolicy{k8sNP},
+			cnps:            []*secv1alpha1.ClusterNetworkPolicy{tierCNP("cnpA", "securityops", &auditAction), tierCNP("cnpB", "", &dropAction)},
+			port:            80,
+			expVerdict:      secv1alpha1.RuleActionDrop,
+			expIngressRule:  true,
+			expAuditedRules: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			npc := newEvaluatorTestController()
+			npc.tierStore.Add(&secv1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: "securityops"}, Spec: secv1alpha1.TierSpec{Priority: 100}})
+			for _, np := range tt.k8sNPs {
+				npc.networkPolicyStore.Add(np)
+				npc.addNetworkPolicy(np)
+			}
+			for _, cnp := range tt.cnps {
+				npc.cnpStore.Add(cnp)
+				npc.addCNP(cnp)
+			}
+			syncEvaluatorTestGroups(t, npc)
+			evaluator := NewPolicyEvaluator(npc.NetworkPolicyController, npc.informerFactory.Core().V1().Services())
+			response, err := evaluator.EvaluateNetworkPolicies(&EvaluationRequest{
+				Source:      "nsA/client",
+				Destination: "nsA/server",
+				Protocol:    corev1.ProtocolTCP,
+				Port:        tt.port,
+			})
+			require.NoError(t, err)
+			require.Len(t, response.Evaluations, 1)
+			evaluation := response.Evaluations[0]
+			assert.Equal(t, tt.expVerdict, evaluation.Verdict)
+			assert.Equal(t, secv1alpha1.RuleActionAllow, evaluation.Egress.Verdict)
+			assert.Equal(t, tt.expIsolated, evaluation.Ingress.Isolated)
+			assert.Equal(t, tt.expIngressRule, evaluation.Ingress.Rule != nil)
+			assert.Len(t, evaluation.Ingress.AuditRules, tt.expAuditedRules)
+		})
+	}
+}
+
+func TestEvaluateNetworkPoliciesRuleIndex(t *testing.T) {
+	dropAction := secv1alpha1.RuleActionDrop
+	clientSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}
+	cnp := &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnpA", UID: "uidA"},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			Priority:  1,
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "server"}}}},
+			Ingress: []secv1alpha1.Rule{
+				{
+					Name:     "expired",
+					Action:   &dropAction,
+					From:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &clientSelector}},
+					Schedule: &secv1alpha1.RuleSchedule{ExpirationTime: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+				},
+				{
+					Name:   "active",
+					Action: &dropAction,
+					From:   []secv1alpha1.NetworkPolicyPeer{{PodSelector: &clientSelector}},
+				},
+			},
+		},
+	}
+	npc := newEvaluatorTestController()
+	npc.cnpStore.Add(cnp)
+	npc.addCNP(cnp)
+	syncEvaluatorTestGroups(t, npc)
+	evaluator := NewPolicyEvaluator(npc.NetworkPolicyController, npc.informerFactory.Core().V1().Services())
+	response, err := evaluator.EvaluateNetworkPolicies(&EvaluationRequest{Source: "nsA/client", Destination: "nsA/server", Port: 80})
+	require.NoError(t, err)
+	require.Len(t, response.Evaluations, 1)
+	// The index of the rule in the original policy is reported, even though the inactive rule
+	// before it is not present in the internal NetworkPolicy.
+	rule := response.Evaluations[0].Ingress.Rule
+	require.NotNil(t, rule)
+	assert.Equal(t, 1, rule.RuleIndex)
+	assert.Equal(t, "active", rule.RuleName)
+	assert.Equal(t, defaultTierName, rule.Tier)
+}
+
+func TestEvaluateDraftNetworkPolicies(t *testing.T) {
+	draft := []byte(`apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+  namespace: nsA
+spec:
+  podSelector: {}
+  policyTypes:
+  - Ingress
+`)
+	npc := newEvaluatorTestController()
+	evaluator := NewPolicyEvaluator(npc.NetworkPolicyController, npc.informerFactory.Core().V1().Services())
+	request := &EvaluationRequest{
+		Source:        "nsA/client",
+		Destination:   "10.0.0.2",
+		Port:          80,
+		DraftPolicies: draft,
+	}
+	response, err := evaluator.EvaluateNetworkPolicies(request)
+	require.NoError(t, err)
+	require.Len(t, response.Evaluations, 1)
+	assert.Equal(t, EvaluationEndpoint{Namespace: "nsA", Name: "server", IP: "10.0.0.2"}, response.Evaluations[0].Destination)
+	assert.Equal(t, secv1alpha1.RuleActionDrop, response.Evaluations[0].Verdict)
+	assert.True(t, response.Evaluations[0].Ingress.Isolated)
+	// The draft policy must not be added to the cluster.
+	request.DraftPolicies = nil
+	response, err = evaluator.EvaluateNetworkPolicies(request)
+	require.NoError(t, err)
+	assert.Equal(t, secv1alpha1.RuleActionAllow, response.Evaluations[0].Verdict)
+
+	// Draft Antrea-native policies referring to a missing Tier are rejected.
+	request.DraftPolicies = []byte(`apiVersion: security.antrea.tanzu.vmware.com/v1alpha1
+kind: ClusterNetworkPolicy
+metadata:
+  name: cnp
+spec:
+  tier: missing
+  priority: 1
+  appliedTo:
+  - podSelector: {}
+`)
+	_, err = evaluator.EvaluateNetworkPolicies(request)
+	assert.Error(t, err)
+}
+
+func TestEvaluateNetworkPoliciesService(t *testing.T) {
+	npc := newEvaluatorTestController()
+	// The Service is only added to the informer cache, from which the evaluator must resolve it.
+	npc.informerFactory.Core().V1().Services().Informer().GetStore().Add(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "nsA"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "server"},
+			Ports:    []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(8080)}},
+		},
+	})
+	evaluator := NewPolicyEvaluator(npc.NetworkPolicyController, npc.informerFactory.Core().V1().Services())
+	response, err := evaluator.EvaluateNetworkPolicies(&EvaluationRequest{Source: "nsA/client", Destination: "svc:nsA/svc", Port: 80})
+	require.NoError(t, err)
+	require.Len(t, response.Evaluations, 1)
+	assert.Equal(t, EvaluationEndpoint{Namespace: "nsA", Name: "server", IP: "10.0.0.2"}, response.Evaluations[0].Destination)
+	assert.Equal(t, int32(8080), response.Evaluations[0].Port)
+
+	_, err = evaluator.EvaluateNetworkPolicies(&EvaluationRequest{Source: "nsA/client", Destination: "svc:nsA/missing", Port: 80})
+	assert.Error(t, err)
+}
+
+func TestEvaluateNetworkPoliciesInvalidRequest(t *testing.T) {
+	npc := newEvaluatorTestController()
+	evaluator := NewPolicyEvaluator(npc.NetworkPolicyController, npc.informerFactory.Core().V1().Services())
+	for _, request := range []*EvaluationRequest{
+		{Source: "nsA/client", Destination: "nsA/server", Port: 0},
+		{Source: "nsA/client", Destination: "nsA/server", Protocol: corev1.Protocol("ICMP"), Port: 80},
+		{Source: "nsA/missing", Destination: "nsA/server", Port: 80},
+		{Source: "nsA/client", Destination: "a/b/c", Port: 80},
+	} {
+		_, err := evaluator.EvaluateNetworkPolicies(request)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,570 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+const (
+	// ruleShadowed is the reason of a rule which is covered by a higher precedence rule with a
+	// different action.
+	ruleShadowed = "ShadowedRule"
+	// ruleRedundant is the reason of a rule which is covered by a higher precedence rule with the
+	// same action.
+	ruleRedundant = "RedundantRule"
+	// ruleEmptySelector is the reason of a rule whose peers select no workloads.
+	ruleEmptySelector = "EmptySelectorRule"
+)
+
+// RuleAnalyzer analyzes the rules of Antrea-native policies against the rules with higher
+// precedence, i.e. the preceding rules of the same policy and the rules of the policies with a
+// higher precedence in the same Tier or in higher Tiers, to find the rules which can never be
+// matched.
+// The analysis is conservative: a rule is only reported as shadowed or redundant when a higher
+// precedence rule selects a superset of its traffic based on the syntax of the selectors, so
+// not every ineffective rule is reported.
+type RuleAnalyzer struct {
+	networkPolicyController *NetworkPolicyController
+}
+
+// ruleFinding describes a rule which can never be matched.
+type ruleFinding struct {
+	reason  string
+	message string
+}
+
+// analyzedPolicy is the representation of an Antrea-native policy used by the RuleAnalyzer.
+type analyzedPolicy struct {
+	// key uniquely identifies the policy, regardless of whether it has been created.
+	key string
+	// ref is the human-readable reference of the policy.
+	ref          string
+	namespace    string
+	tierPriority int32
+	priority     float64
+	appliedTo    []secv1alpha1.NetworkPolicyPeer
+	ingress      []secv1alpha1.Rule
+	egress       []secv1alpha1.Rule
+}
+
+// NewRuleAnalyzer returns a new *RuleAnalyzer.
+func NewRuleAnalyzer(networkPolicyController *NetworkPolicyController) *RuleAnalyzer {
+	return &RuleAnalyzer{
+		networkPolicyController: networkPolicyController,
+	}
+}
+
+func (a *RuleAnalyzer) toAnalyzedCNP(cnp *secv1alpha1.ClusterNetworkPolicy) *analyzedPolicy {
+	return &analyzedPolicy{
+		key:          "acnp/" + cnp.Name,
+		ref:          "ClusterNetworkPolicy " + cnp.Name,
+		tierPriority: a.networkPolicyController.getTierPriority(cnp.Spec.Tier),
+		priority:     cnp.Spec.Priority,
+		appliedTo:    cnp.Spec.AppliedTo,
+		ingress:      cnp.Spec.Ingress,
+		egress:       cnp.Spec.Egress,
+	}
+}
+
+func (a *RuleAnalyzer) toAnalyzedANP(anp *secv1alpha1.NetworkPolicy) *analyzedPolicy {
+	return &analyzedPolicy{
+		key:          "anp/" + anp.Namespace + "/" + anp.Name,
+		ref:          "NetworkPolicy " + anp.Namespace + "/" + anp.Name,
+		namespace:    anp.Namespace,
+		tierPriority: a.networkPolicyController.getTierPriority(anp.Spec.Tier),
+		priority:     anp.Spec.Priority,
+		appliedTo:    anp.Spec.AppliedTo,
+		ingress:      anp.Spec.Ingress,
+		egress:       anp.Spec.Egress,
+	}
+}
+
+// analyzeCNP returns the findings for the rules of an Antrea ClusterNetworkPolicy.
+func (a *RuleAnalyzer) analyzeCNP(cnp *secv1alpha1.ClusterNetworkPolicy) []ruleFinding {
+	return a.analyze(a.toAnalyzedCNP(cnp))
+}
+
+// analyzeANP returns the findings for the rules of an Antrea NetworkPolicy.
+func (a *RuleAnalyzer) analyzeANP(anp *secv1alpha1.NetworkPolicy) []ruleFinding {
+	return a.analyze(a.toAnalyzedANP(anp))
+}
+
+func (a *RuleAnalyzer) analyze(policy *analyzedPolicy) []ruleFinding {
+	higher := a.getHigherPrecedencePolicies(policy)
+	findings := a.analyzeRules(policy, higher, true)
+	return append(findings, a.analyzeRules(policy, higher, false)...)
+}
+
+// getHigherPrecedencePolicies returns the Antrea-native policies which are evaluated before the
+// provided policy, sorted by precedence. Policies with the same Tier and priority are evaluated
+// in an undefined order, so they are not included.
+func (a *RuleAnalyzer) getHigherPrecedencePolicies(policy *analyzedPolicy) []*analyzedPolicy {
+	n := a.networkPolicyController
+	var policies []*analyzedPolicy
+	addPolicy := func(p *analyzedPolicy) {
+		if p.key == policy.key {
+			return
+		}
+		if p.tierPriority < policy.tierPriority || p.tierPriority == policy.tierPriority && p.priority < policy.priority {
+			policies = append(policies, p)
+		}
+	}
+	if n.cnpLister != nil {
+		cnps, _ := n.cnpLister.List(labels.Everything())
+		for _, cnp := range cnps {
+			addPolicy(a.toAnalyzedCNP(cnp))
+		}
+	}
+	if n.anpLister != nil {
+		anps, _ := n.anpLister.List(labels.Everything())
+		for _, anp := range anps {
+			addPolicy(a.toAnalyzedANP(anp))
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		pi, pj := policies[i], policies[j]
+		if pi.tierPriority != pj.tierPriority {
+			return pi.tierPriority < pj.tierPriority
+		}
+		if pi.priority != pj.priority {
+			return pi.priority < pj.priority
+		}
+		return pi.key < pj.key
+	})
+	return policies
+}
+
+func (a *RuleAnalyzer) analyzeRules(policy *analyzedPolicy, higher []*analyzedPolicy, ingress bool) []ruleFinding {
+	rules, direction := policy.egress, "egress"
+	if ingress {
+		rules, direction = policy.ingress, "ingress"
+	}
+	var findings []ruleFinding
+	for i := range rules {
+		rule := &rules[i]
+		if finding := a.findCoveringRule(policy, rule, rules[:i], higher, ingress); finding != nil {
+			finding.message = fmt.Sprintf("%s %s", describeRule(direction, i, rule.Name), finding.message)
+			findings = append(findings, *finding)
+			continue
+		}
+		if a.peersSelectNothing(policy.namespace, rulePeers(rule, ingress)) {
+			findings = append(findings, ruleFinding{
+				reason:  ruleEmptySelector,
+				message: fmt.Sprintf("%s selects no workloads", describeRule(direction, i, rule.Name)),
+			})
+		}
+	}
+	return findings
+}
+
+// findCoveringRule returns a finding if the rule is covered by one of the preceding rules of the
+// same policy or by a rule of a higher precedence policy.
+func (a *RuleAnalyzer) findCoveringRule(policy *analyzedPolicy, rule *secv1alpha1.Rule, preceding []secv1alpha1.Rule, higher []*analyzedPolicy, ingress bool) *ruleFinding {
+	direction := "egress"
+	if ingress {
+		direction = "ingress"
+	}
+	newFinding := func(hp *analyzedPolicy, hr *secv1alpha1.Rule, index int) *ruleFinding {
+		reason, verb := ruleShadowed, "shadowed by"
+		if ruleAction(hr) == ruleAction(rule) {
+			reason, verb = ruleRedundant, "redundant with"
+		}
+		target := describeRule(direction, index, hr.Name)
+		if hp != policy {
+			target = fmt.Sprintf("%s of %s", target, hp.ref)
+		}
+		return &ruleFinding{
+			reason:  reason,
+			message: fmt.Sprintf("is %s %s which has action %s", verb, target, ruleAction(hr)),
+		}
+	}
+	for i := range preceding {
+		if a.ruleCovers(policy, &preceding[i], policy, rule, ingress) {
+			return newFinding(policy, &preceding[i], i)
+		}
+	}
+	for _, hp := range higher {
+		rules := hp.egress
+		if ingress {
+			rules = hp.ingress
+		}
+		for i := range rules {
+			hr := &rules[i]
+			if a.ruleCovers(hp, hr, policy, rule, ingress) {
+				return newFinding(hp, hr, i)
+			}
+		}
+	}
+	return nil
+}
+
+// ruleCovers returns whether the traffic matched by rule hr of policy hp is a superset of the
+// traffic matched by rule tr of policy tp.
+func (a *RuleAnalyzer) ruleCovers(hp *analyzedPolicy, hr *secv1alpha1.Rule, tp *analyzedPolicy, tr *secv1alpha1.Rule, ingress bool) bool {
+	// A rule which has a schedule is not always enforced, so it cannot make other rules ineffective.
+	// The traffic matching an Audit rule is still evaluated by the next rules, and a rule with L7
+	// protocols or a rate limit only decides for a part of the traffic it matches, so they cannot
+	// make other rules ineffective either.
+	return hr.Schedule == nil &&
+		ruleAction(hr) != secv1alpha1.RuleActionAudit &&
+		len(hr.L7Protocols) == 0 &&
+		hr.RateLimit == nil &&
+		a.peersCover(hp.namespace, hp.appliedTo, tp.namespace, tp.appliedTo) &&
+		a.peersCover(hp.namespace, rulePeers(hr, ingress), tp.namespace, rulePeers(tr, ingress)) &&
+		portsCover(hr.Ports, tr.Ports)
+}
+
+// peersCover returns whether the workloads selected by the peers hPeers are a superset of the
+// workloads selected by tPeers. An empty list of peers selects all workloads.
+func (a *RuleAnalyzer) peersCover(hNamespace string, hPeers []secv1alpha1.NetworkPolicyPeer, tNamespace string, tPeers []secv1alpha1.NetworkPolicyPeer) bool {
+	if len(hPeers) == 0 {
+		return true
+	}
+	if len(tPeers) == 0 {
+		return false
+	}
+	for i := range tPeers {
+		covered := false
+		for j := range hPeers {
+			if a.peerCovers(hNamespace, &hPeers[j], tNamespace, &tPeers[i]) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *RuleAnalyzer) peerCovers(hNamespace string, hp *secv1alpha1.NetworkPolicyPeer, tNamespace string, tp *secv1alpha1.NetworkPolicyPeer) bool {
+	switch {
+	case hp.IPBlock != nil:
+		return tp.IPBlock != nil && ipBlockCovers(hp.IPBlock, tp.IPBlock)
+	case hp.NodeSelector != nil:
+		return tp.NodeSelector != nil && selectorCovers(hp.NodeSelector, tp.NodeSelector)
+	case tp.IPBlock != nil || tp.NodeSelector != nil:
+		return false
+	}
+	if (hp.ExternalEntitySelector != nil) != (tp.ExternalEntitySelector != nil) {
+		return false
+	}
+	hSelector, tSelector := hp.PodSelector, tp.PodSelector
+	if hp.ExternalEntitySelector != nil {
+		hSelector, tSelector = hp.ExternalEntitySelector, tp.ExternalEntitySelector
+	}
+	if !selectorCovers(hSelector, tSelector) {
+		return false
+	}
+	hScopeNamespace, hScopeSelector := namespaceScope(hNamespace, hp)
+	tScopeNamespace, tScopeSelector := namespaceScope(tNamespace, tp)
+	if hScopeSelector == nil {
+		return tScopeSelector == nil && hScopeNamespace == tScopeNamespace
+	}
+	if tScopeSelector != nil {
+		return selectorCovers(hScopeSelector, tScopeSelector)
+	}
+	if isEmptySelector(hScopeSelector) {
+		return true
+	}
+	namespace, err := a.networkPolicyController.namespaceLister.Get(tScopeNamespace)
+	if err != nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(hScopeSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(namespace.Labels))
+}
+
+// peersSelectNothing returns whether none of the peers currently selects any workload. It
+// returns false if there are no peers, as the rule then matches all workloads.
+func (a *RuleAnalyzer) peersSelectNothing(namespace string, peers []secv1alpha1.NetworkPolicyPeer) bool {
+	if len(peers) == 0 {
+		return false
+	}
+	for i := range peers {
+		if !a.peerSelectsNothing(namespace, &peers[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *RuleAnalyzer) peerSelectsNothing(namespace string, peer *secv1alpha1.NetworkPolicyPeer) bool {
+	n := a.networkPolicyController
+	if peer.IPBlock != nil {
+		return false
+	}
+	if peer.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(peer.NodeSelector)
+		if err != nil {
+			return false
+		}
+		nodes, err := n.nodeLister.List(selector)
+		return err == nil && len(nodes) == 0
+	}
+	workloadSelector := peer.PodSelector
+	if peer.ExternalEntitySelector != nil {
+		workloadSelector = peer.ExternalEntitySelector
+	}
+	if workloadSelector == nil {
+		workloadSelector = &metav1.LabelSelector{}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(workloadSelector)
+	if err != nil {
+		return false
+	}
+	scopeNamespace, scopeSelector := namespaceScope(namespace, peer)
+	namespaces := []string{scopeNamespace}
+	if scopeSelector != nil {
+		nsSelector, err := metav1.LabelSelectorAsSelector(scopeSelector)
+		if err != nil {
+			return false
+		}
+		nsList, err := n.namespaceLister.List(nsSelector)
+		if err != nil {
+			return false
+		}
+		namespaces = namespaces[:0]
+		for _, ns := range nsList {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+	for _, ns := range namespaces {
+		if peer.ExternalEntitySelector != nil {
+			entities, err := n.externalEntityLister.ExternalEntities(ns).List(selector)
+			if err != nil || len(entities) > 0 {
+				return false
+			}
+		} else {
+			pods, err := n.podLister.Pods(ns).List(selector)
+			if err != nil || len(pods) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// namespaceScope returns the Namespaces in which a peer selects workloads, either a single
+// Namespace or a Namespace selector.
+func namespaceScope(policyNamespace string, peer *secv1alpha1.NetworkPolicyPeer) (string, *metav1.LabelSelector) {
+	if peer.NamespaceSelector != nil {
+		return "", peer.NamespaceSelector
+	}
+	if policyNamespace != "" {
+		return policyNamespace, nil
+	}
+	// Peers of Antrea ClusterNetworkPolicies without a Namespace selector select workloads
+	// in all Namespaces.
+	return "", &metav1.LabelSelector{}
+}
+
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return selector == nil || len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
+}
+
+// selectorCovers returns whether all the objects selected by label selector t are also selected
+// by label selector h. A nil selector selects all objects.
+func selectorCovers(h, t *metav1.LabelSelector) bool {
+	if isEmptySelector(h) {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	for k, v := range h.MatchLabels {
+		if tv, ok := t.MatchLabels[k]; !ok || tv != v {
+			return false
+		}
+	}
+	for _, he := range h.MatchExpressions {
+		if !requirementImplied(he, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// requirementImplied returns whether the requirement is met by all the objects selected by the
+// label selector.
+func requirementImplied(requirement metav1.LabelSelectorRequirement, selector *metav1.LabelSelector) bool {
+	for _, te := range selector.MatchExpressions {
+		if reflect.DeepEqual(requirement, te) {
+			return true
+		}
+	}
+	v, ok := selector.MatchLabels[requirement.Key]
+	if !ok {
+		return false
+	}
+	switch requirement.Operator {
+	case metav1.LabelSelectorOpExists:
+		return true
+	case metav1.LabelSelectorOpIn:
+		for _, value := range requirement.Values {
+			if value == v {
+				return true
+			}
+		}
+	case metav1.LabelSelectorOpNotIn:
+		for _, value := range requirement.Values {
+			if value == v {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ipBlockCovers returns whether the IP addresses matched by IPBlock h are a superset of the IP
+// addresses matched by IPBlock t.
+func ipBlockCovers(h, t *secv1alpha1.IPBlock) bool {
+	_, hNet, err := net.ParseCIDR(h.CIDR)
+	if err != nil {
+		return false
+	}
+	_, tNet, err := net.ParseCIDR(t.CIDR)
+	if err != nil {
+		return false
+	}
+	if !cidrContains(hNet, tNet) {
+		return false
+	}
+	// Every except of h overlapping with t must be excluded from t as well.
+	for _, hExcept := range h.Except {
+		_, hExceptNet, err := net.ParseCIDR(hExcept)
+		if err != nil {
+			return false
+		}
+		if !cidrContains(hExceptNet, tNet) && !cidrContains(tNet, hExceptNet) {
+			continue
+		}
+		excluded := false
+		for _, tExcept := range t.Except {
+			if _, tExceptNet, err := net.ParseCIDR(tExcept); err == nil && cidrContains(tExceptNet, hExceptNet) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return false
+		}
+	}
+	return true
+}
+
+// cidrContains returns whether CIDR a contains CIDR b.
+func cidrContains(a, b *net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// portsCover returns whether the ports hPorts match all the traffic matched by tPorts. An empty
+// list of ports matches all ports.
+func portsCover(hPorts, tPorts []secv1alpha1.NetworkPolicyPort) bool {
+	if len(hPorts) == 0 {
+		return true
+	}
+	if len(tPorts) == 0 {
+		return false
+	}
+	for _, tp := range tPorts {
+		covered := false
+		for _, hp := range hPorts {
+			if portProtocol(hp) != portProtocol(tp) {
+				continue
+			}
+			if hp.Port == nil || tp.Port != nil && *hp.Port == *tp.Port {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func portProtocol(port secv1alpha1.NetworkPolicyPort) v1.Protocol {
+	if port.Protocol == nil {
+		return v1.ProtocolTCP
+	}
+	return *port.Protocol
+}
+
+func rulePeers(rule *secv1alpha1.Rule, ingress bool) []secv1alpha1.NetworkPolicyPeer {
+	if ingress {
+		return rule.From
+	}
+	return rule.To
+}
+
+func ruleAction(rule *secv1alpha1.Rule) secv1alpha1.RuleAction {
+	if rule.Action == nil {
+		return secv1alpha1.RuleActionAllow
+	}
+	return *rule.Action
+}
+
+func describeRule(direction string, index int, name string) string {
+	if name != "" {
+		return fmt.Sprintf("%s rule %q", direction, name)
+	}
+	return fmt.Sprintf("%s rule %d", direction, index)
+}
+
+// findingsToCondition converts the findings of the RuleAnalyzer to a NetworkPolicyCondition.
+func findingsToCondition(findings []ruleFinding) secv1alpha1.NetworkPolicyCondition {
+	condition := secv1alpha1.NetworkPolicyCondition{
+		Type:   secv1alpha1.NetworkPolicyRulesIneffective,
+		Status: v1.ConditionFalse,
+		Reason: "AllRulesEffective",
+	}
+	if len(findings) == 0 {
+		return condition
+	}
+	messages := make([]string, 0, len(findings))
+	for _, f := range findings {
+		messages = append(messages, f.message)
+	}
+	condition.Status = v1.ConditionTrue
+	condition.Reason = findings[0].reason
+	for _, f := range findings[1:] {
+		if f.reason != condition.Reason {
+			condition.Reason = "IneffectiveRules"
+			break
+		}
+	}
+	condition.Message = strings.Join(messages, "; ")
+	return condition
+}
@@ -0,0 +1,195 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func newAnalyzerTestCNP(name, tier string, priority float64, appliedTo metav1.LabelSelector, rules ...secv1alpha1.Rule) *secv1alpha1.ClusterNetworkPolicy {
+	return &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			Tier:      tier,
+			Priority:  priority,
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &appliedTo}},
+			Ingress:   rules,
+		},
+	}
+}
+
+func TestAnalyzeRules(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	auditAction := secv1alpha1.RuleActionAudit
+	selectorA := metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}}
+	selectorAB := metav1.LabelSelector{MatchLabels: map[string]string{"app": "a", "env": "b"}}
+	selectorC := metav1.LabelSelector{MatchLabels: map[string]string{"app": "c"}}
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	ruleFrom := func(action *secv1alpha1.RuleAction, selector metav1.LabelSelector, protocol *corev1.Protocol) secv1alpha1.Rule {
+		rule := secv1alpha1.Rule{
+			Action: action,
+			From:   []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selector}},
+		}
+		if protocol != nil {
+			rule.Ports = []secv1alpha1.NetworkPolicyPort{{Protocol: protocol, Port: &int80}}
+		}
+		return rule
+	}
+	ipBlockRule := func(action *secv1alpha1.RuleAction, ipBlock *secv1alpha1.IPBlock) secv1alpha1.Rule {
+		return secv1alpha1.Rule{
+			Action: action,
+			From:   []secv1alpha1.NetworkPolicyPeer{{IPBlock: ipBlock}},
+		}
+	}
+	l7Rule := ruleFrom(&allowAction, metav1.LabelSelector{}, nil)
+	l7Rule.L7Protocols = []secv1alpha1.L7Protocol{{HTTP: &secv1alpha1.HTTPProtocol{Method: "GET"}}}
+	rateLimitedRule := ruleFrom(&allowAction, selectorA, nil)
+	rateLimitedRule.RateLimit = &secv1alpha1.RateLimit{ConnectionsPerSecond: 10}
+	tests := []struct {
+		name        string
+		existing    []*secv1alpha1.ClusterNetworkPolicy
+		policy      *secv1alpha1.ClusterNetworkPolicy
+		expFindings []string
+	}{
+		{
+			name:   "no-finding",
+			policy: newAnalyzerTestCNP("cnp", "", 1, selectorA, ruleFrom(&allowAction, selectorA, &tcp), ruleFrom(&dropAction, selectorA, &udp)),
+		},
+		{
+			name:        "shadowed-in-same-policy",
+			policy:      newAnalyzerTestCNP("cnp", "", 1, selectorA, ruleFrom(&dropAction, selectorA, nil), ruleFrom(&allowAction, selectorAB, &tcp)),
+			expFindings: []string{ruleShadowed},
+		},
+		{
+			name:        "redundant-with-higher-priority-policy",
+			existing:    []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "", 1, metav1.LabelSelector{}, ruleFrom(&allowAction, selectorA, &tcp))},
+			policy:      newAnalyzerTestCNP("cnp", "", 2, selectorA, ruleFrom(&allowAction, selectorAB, &tcp)),
+			expFindings: []string{ruleRedundant},
+		},
+		{
+			name:     "lower-priority-policy",
+			existing: []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("low", "", 3, metav1.LabelSelector{}, ruleFrom(&dropAction, selectorA, nil))},
+			policy:   newAnalyzerTestCNP("cnp", "", 2, selectorA, ruleFrom(&allowAction, selectorA, &tcp)),
+		},
+		{
+			name:        "shadowed-by-higher-tier",
+			existing:    []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "securityops", 10, metav1.LabelSelector{}, ruleFrom(&dropAction, metav1.LabelSelector{}, nil))},
+			policy:      newAnalyzerTestCNP("cnp", "", 1, selectorA, ruleFrom(&allowAction, selectorA, &tcp)),
+			expFindings: []string{ruleShadowed},
+		},
+		{
+			name:     "not-covered-applied-to",
+			existing: []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "", 1, selectorAB, ruleFrom(&dropAction, metav1.LabelSelector{}, nil))},
+			policy:   newAnalyzerTestCNP("cnp", "", 2, selectorA, ruleFrom(&allowAction, selectorA, &tcp)),
+		},
+		{
+			name:     "audit-does-not-shadow-non-baseline-rule",
+			existing: []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "", 1, metav1.LabelSelector{}, ruleFrom(&auditAction, metav1.LabelSelector{}, nil))},
+			policy:   newAnalyzerTestCNP("cnp", "", 2, selectorA, ruleFrom(&allowAction, selectorA, &tcp)),
+		},
+		{
+			name:   "audit-does-not-shadow-rule-in-same-policy",
+			policy: newAnalyzerTestCNP("cnp", "", 1, selectorA, ruleFrom(&auditAction, selectorA, nil), ruleFrom(&dropAction, selectorAB, &tcp)),
+		},
+		{
+			name:     "l7-rule-does-not-cover",
+			existing: []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "", 1, metav1.LabelSelector{}, l7Rule)},
+			policy:   newAnalyzerTestCNP("cnp", "", 2, selectorA, ruleFrom(&allowAction, selectorA, &tcp)),
+		},
+		{
+			name:   "rate-limited-rule-does-not-cover",
+			policy: newAnalyzerTestCNP("cnp", "", 1, selectorA, rateLimitedRule, ruleFrom(&allowAction, selectorAB, &tcp)),
+		},
+		{
+			name:     "audit-does-not-shadow-baseline-rule",
+			existing: []*secv1alpha1.ClusterNetworkPolicy{newAnalyzerTestCNP("high", "", 1, metav1.LabelSelector{}, ruleFrom(&auditAction, metav1.LabelSelector{}, nil))},
+			policy:   newAnalyzerTestCNP("cnp", "baseline", 2, selectorA, ruleFrom(&dropAction, selectorA, &tcp)),
+		},
+		{
+			name: "ip-block-covered",
+			policy: newAnalyzerTestCNP("cnp", "", 1, selectorA,
+				ipBlockRule(&dropAction, &secv1alpha1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}),
+				ipBlockRule(&allowAction, &secv1alpha1.IPBlock{CIDR: "10.2.0.0/16"}),
+				ipBlockRule(&allowAction, &secv1alpha1.IPBlock{CIDR: "10.0.0.0/12"})),
+			expFindings: []string{ruleShadowed},
+		},
+		{
+			name:        "empty-selector",
+			policy:      newAnalyzerTestCNP("cnp", "", 1, selectorA, ruleFrom(&allowAction, selectorC, nil)),
+			expFindings: []string{ruleEmptySelector},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, npc := newController()
+			npc.cnpLister = npc.crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies().Lister()
+			npc.tierStore.Add(&secv1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: "securityops"}, Spec: secv1alpha1.TierSpec{Priority: 100}})
+			npc.tierStore.Add(&secv1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: "application"}, Spec: secv1alpha1.TierSpec{Priority: DefaultTierPriority}})
+			npc.tierStore.Add(&secv1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: "baseline"}, Spec: secv1alpha1.TierSpec{Priority: BaselineTierPriority}})
+			pod := getPod("p1", "nsA", "", "1.1.1.1", false)
+			pod.Labels = map[string]string{"app": "a", "env": "b"}
+			npc.podStore.Add(pod)
+			npc.namespaceStore.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "nsA"}})
+			for _, cnp := range tt.existing {
+				npc.cnpStore.Add(cnp)
+			}
+			findings := NewRuleAnalyzer(npc.NetworkPolicyController).analyzeCNP(tt.policy)
+			var reasons []string
+			for _, f := range findings {
+				reasons = append(reasons, f.reason)
+			}
+			assert.Equal(t, tt.expFindings, reasons)
+		})
+	}
+}
+
+func TestSelectorCovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		h      *metav1.LabelSelector
+		t      *metav1.LabelSelector
+		covers bool
+	}{
+		{"nil-covers-all", nil, &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}, true},
+		{"subset-labels", &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}, &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b", "c": "d"}}, true},
+		{"different-labels", &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}, &metav1.LabelSelector{MatchLabels: map[string]string{"a": "c"}}, false},
+		{"all-not-covered", &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}, &metav1.LabelSelector{}, false},
+		{
+			"in-expression",
+			&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpIn, Values: []string{"b", "c"}}}},
+			&metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}},
+			true,
+		},
+		{
+			"not-in-expression",
+			&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"b"}}}},
+			&metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.covers, selectorCovers(tt.h, tt.t))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+const (
+	// timeWindowFormat is the format of the start and the end of a TimeWindow.
+	timeWindowFormat = "15:04"
+	// scheduleHorizonDays is the number of days over which the edges of the time windows are
+	// looked up to find the next transition of a rule. As windows recur at most weekly, the
+	// activation state of a rule within a week after its activation time is representative
+	// of its activation state at any later time.
+	scheduleHorizonDays = 8
+)
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// timeOfDay returns the offset from midnight of a time in "HH:MM" format.
+func timeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse(timeWindowFormat, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, it must be in HH:MM format", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// validateRuleSchedule validates that the times of the schedule of a rule are valid and that its
+// activation time is before its expiration time.
+func validateRuleSchedule(schedule *secv1alpha1.RuleSchedule) (string, bool) {
+	if schedule.ActivationTime != nil && schedule.ExpirationTime != nil && !schedule.ActivationTime.Before(schedule.ExpirationTime) {
+		return "schedule activationTime must be before expirationTime", false
+	}
+	for _, w := range schedule.Windows {
+		start, err := timeOfDay(w.Start)
+		if err != nil {
+			return fmt.Sprintf("invalid schedule window start: %v", err), false
+		}
+		end, err := timeOfDay(w.End)
+		if err != nil {
+			return fmt.Sprintf("invalid schedule window end: %v", err), false
+		}
+		if start == end {
+			return fmt.Sprintf("schedule window %s-%s must not be empty", w.Start, w.End), false
+		}
+		for _, day := range w.Days {
+			if _, ok := weekdays[day]; !ok {
+				return fmt.Sprintf("invalid schedule window day %q, it must be one of Mon, Tue, Wed, Thu, Fri, Sat, Sun", day), false
+			}
+		}
+	}
+	return "", true
+}
+
+// windowOccurrence is an occurrence of a TimeWindow, from start (inclusive) to end (exclusive).
+type windowOccurrence struct {
+	start time.Time
+	end   time.Time
+}
+
+// windowOccurrences returns the occurrences of the time windows which start on the days between
+// from and to, including both.
+func windowOccurrences(windows []secv1alpha1.TimeWindow, from, to time.Time) []windowOccurrence {
+	var occurrences []windowOccurrence
+	for _, w := range windows {
+		// The windows have been validated.
+		start, _ := timeOfDay(w.Start)
+		end, _ := timeOfDay(w.End)
+		duration := end - start
+		if duration <= 0 {
+			duration += 24 * time.Hour
+		}
+		days := map[time.Weekday]bool{}
+		for _, day := range w.Days {
+			days[weekdays[day]] = true
+		}
+		from, to := from.UTC(), to.UTC()
+		for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); !day.After(to); day = day.AddDate(0, 0, 1) {
+			if len(days) > 0 && !days[day.Weekday()] {
+				continue
+			}
+			occurrences = append(occurrences, windowOccurrence{start: day.Add(start), end: day.Add(start + duration)})
+		}
+	}
+	return occurrences
+}
+
+// isRuleActive returns whether a rule with the schedule is active at the given time.
+func isRuleActive(schedule *secv1alpha1.RuleSchedule, t time.Time) bool {
+	if schedule == nil {
+		return true
+	}
+	if schedule.ActivationTime != nil && t.Before(schedule.ActivationTime.Time) {
+		return false
+	}
+	if schedule.ExpirationTime != nil && !t.Before(schedule.ExpirationTime.Time) {
+		return false
+	}
+	if len(schedule.Windows) == 0 {
+		return true
+	}
+	// A window which started the day before may span midnight.
+	for _, o := range windowOccurrences(schedule.Windows, t.AddDate(0, 0, -1), t) {
+		if !t.Before(o.start) && t.Before(o.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleScheduleState returns whether a rule with the schedule is active at the given time, and the
+// time at which its activation state changes next, which is zero if it never changes again.
+func ruleScheduleState(schedule *secv1alpha1.RuleSchedule, now time.Time) (bool, time.Time) {
+	active := isRuleActive(schedule, now)
+	if schedule == nil {
+		return active, time.Time{}
+	}
+	var edges []time.Time
+	if schedule.ActivationTime != nil {
+		edges = append(edges, schedule.ActivationTime.Time)
+	}
+	if schedule.ExpirationTime != nil {
+		edges = append(edges, schedule.ExpirationTime.Time)
+	}
+	from := now
+	if schedule.ActivationTime != nil && schedule.ActivationTime.After(now) {
+		from = schedule.ActivationTime.Time
+	}
+	// The end of a window which started the day before may be the next transition.
+	for _, o := range windowOccurrences(schedule.Windows, from.AddDate(0, 0, -1), from.AddDate(0, 0, scheduleHorizonDays)) {
+		edges = append(edges, o.start, o.end)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Before(edges[j]) })
+	for _, edge := range edges {
+		if edge.After(now) && isRuleActive(schedule, edge) != active {
+			return active, edge
+		}
+	}
+	return active, time.Time{}
+}
+
+// scheduledRuleName returns the name of a rule in the ScheduledRules status of a policy.
+func scheduledRuleName(rule *secv1alpha1.Rule, direction controlplane.Direction, idx int) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	if direction == controlplane.DirectionIn {
+		return fmt.Sprintf("ingress-%d", idx)
+	}
+	return fmt.Sprintf("egress-%d", idx)
+}
+
+// scheduleRuleState returns whether a rule of an Antrea-native policy is active now. If the rule has
+// a schedule, its activation state is appended to scheduledRules.
+func scheduleRuleState(rule *secv1alpha1.Rule, direction controlplane.Direction, idx int, now time.Time, scheduledRules *[]secv1alpha1.ScheduledRuleStatus) bool {
+	if rule.Schedule == nil {
+		return true
+	}
+	active, next := ruleScheduleState(rule.Schedule, now)
+	status := secv1alpha1.ScheduledRuleStatus{
+		Name:   scheduledRuleName(rule, direction, idx),
+		Active: active,
+	}
+	if !next.IsZero() {
+		status.NextTransitionTime = &metav1.Time{Time: next}
+	}
+	*scheduledRules = append(*scheduledRules, status)
+	return active
+}
+
+// enqueueRuleScheduleTransition enqueues an internal NetworkPolicy to be synced again when the
+// activation state of one of its scheduled rules changes next.
+func (n *NetworkPolicyController) enqueueRuleScheduleTransition(internalNP *antreatypes.NetworkPolicy) {
+	var next time.Time
+	for _, status := range internalNP.ScheduledRules {
+		if status.NextTransitionTime != nil && (next.IsZero() || status.NextTransitionTime.Before(&metav1.Time{Time: next})) {
+			next = status.NextTransitionTime.Time
+		}
+	}
+	if next.IsZero() {
+		return
+	}
+	klog.V(2).Infof("Scheduling next rule transition of %s at %s", internalNP.SourceRef.ToString(), next.Format(time.RFC3339))
+	n.internalNetworkPolicyQueue.AddAfter(internalNP.Name, time.Until(next))
+}
+
+// ruleScheduleTransitionDue returns whether the activation state of one of the scheduled rules of
+// an internal NetworkPolicy may have changed since the policy was processed.
+func ruleScheduleTransitionDue(internalNP *antreatypes.NetworkPolicy, now time.Time) bool {
+	for _, status := range internalNP.ScheduledRules {
+		if status.NextTransitionTime != nil && !status.NextTransitionTime.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// reprocessAntreaPolicy processes again the Antrea-native policy of an internal NetworkPolicy, so
+// that the rules which have been activated or deactivated since the policy was last processed are
+// added to or removed from the internal NetworkPolicy. It returns nil if the policy no longer
+// exists. It must be called with internalNetworkPolicyMutex held, so that it's serialized with the
+// event handlers of the policy.
+func (n *NetworkPolicyController) reprocessAntreaPolicy(internalNP *antreatypes.NetworkPolicy) *antreatypes.NetworkPolicy {
+	ref := internalNP.SourceRef
+	switch ref.Type {
+	case controlplane.AntreaClusterNetworkPolicy:
+		cnp, err := n.cnpLister.Get(ref.Name)
+		if err != nil || cnp.UID != ref.UID {
+			return nil
+		}
+		return n.processClusterNetworkPolicy(cnp)
+	case controlplane.AntreaNetworkPolicy:
+		anp, err := n.anpLister.NetworkPolicies(ref.Namespace).Get(ref.Name)
+		if err != nil || anp.UID != ref.UID {
+			return nil
+		}
+		return n.processAntreaNetworkPolicy(anp)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+	antreatypes "github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func TestRuleScheduleState(t *testing.T) {
+	// Monday, 2020-11-02 00:30 UTC.
+	now := time.Date(2020, 11, 2, 0, 30, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2020, 11, day, hour, minute, 0, 0, time.UTC)
+	}
+	metaTime := func(t time.Time) *metav1.Time {
+		return &metav1.Time{Time: t}
+	}
+	tests := []struct {
+		name       string
+		schedule   *secv1alpha1.RuleSchedule
+		expActive  bool
+		expNextRun time.Time
+	}{
+		{
+			name:      "no-schedule",
+			expActive: true,
+		},
+		{
+			name:       "before-window",
+			schedule:   &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "01:00", End: "03:00"}}},
+			expActive:  false,
+			expNextRun: at(2, 1, 0),
+		},
+		{
+			name:       "in-window-spanning-midnight",
+			schedule:   &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "23:00", End: "01:00"}}},
+			expActive:  true,
+			expNextRun: at(2, 1, 0),
+		},
+		{
+			name:       "window-on-other-days",
+			schedule:   &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "01:00", End: "03:00", Days: []string{"Wed", "Fri"}}}},
+			expActive:  false,
+			expNextRun: at(4, 1, 0),
+		},
+		{
+			name:       "contiguous-windows",
+			schedule:   &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "00:00", End: "01:00"}, {Start: "01:00", End: "02:00"}}},
+			expActive:  true,
+			expNextRun: at(2, 2, 0),
+		},
+		{
+			name:       "until-expiration",
+			schedule:   &secv1alpha1.RuleSchedule{ExpirationTime: metaTime(at(2, 2, 30))},
+			expActive:  true,
+			expNextRun: at(2, 2, 30),
+		},
+		{
+			name:      "expired",
+			schedule:  &secv1alpha1.RuleSchedule{ExpirationTime: metaTime(at(1, 0, 0))},
+			expActive: false,
+		},
+		{
+			name: "window-after-activation",
+			schedule: &secv1alpha1.RuleSchedule{
+				ActivationTime: metaTime(at(20, 12, 0)),
+				Windows:        []secv1alpha1.TimeWindow{{Start: "01:00", End: "03:00"}},
+			},
+			expActive:  false,
+			expNextRun: at(21, 1, 0),
+		},
+		{
+			name: "expiration-in-window",
+			schedule: &secv1alpha1.RuleSchedule{
+				ExpirationTime: metaTime(at(2, 0, 45)),
+				Windows:        []secv1alpha1.TimeWindow{{Start: "00:00", End: "01:00"}},
+			},
+			expActive:  true,
+			expNextRun: at(2, 0, 45),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, next := ruleScheduleState(tt.schedule, now)
+			assert.Equal(t, tt.expActive, active)
+			assert.Equal(t, tt.expNextRun, next)
+		})
+	}
+}
+
+func TestValidateRuleSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		schedule   *secv1alpha1.RuleSchedule
+		expAllowed bool
+	}{
+		{
+			name:       "valid",
+			schedule:   &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "22:00", End: "02:00", Days: []string{"Sat", "Sun"}}}},
+			expAllowed: true,
+		},
+		{
+			name:     "invalid-time",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "1am", End: "02:00"}}},
+		},
+		{
+			name:     "empty-window",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "02:00", End: "02:00"}}},
+		},
+		{
+			name:     "invalid-day",
+			schedule: &secv1alpha1.RuleSchedule{Windows: []secv1alpha1.TimeWindow{{Start: "01:00", End: "02:00", Days: []string{"Monday"}}}},
+		},
+		{
+			name: "expiration-before-activation",
+			schedule: &secv1alpha1.RuleSchedule{
+				ActivationTime: &metav1.Time{Time: time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)},
+				ExpirationTime: &metav1.Time{Time: time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, allowed := validateRuleSchedule(tt.schedule)
+			assert.Equal(t, tt.expAllowed, allowed)
+		})
+	}
+}
+
+func TestProcessScheduledRules(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	selectorA := metav1.LabelSelector{MatchLabels: map[string]string{"foo1": "bar1"}}
+	now := time.Now()
+	expiration := metav1.NewTime(now.Add(time.Hour).Truncate(time.Second))
+	cnp := &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnpA", UID: "uidA"},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+			Priority:  1,
+			Ingress: []secv1alpha1.Rule{
+				{
+					Action:   &allowAction,
+					From:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+					Name:     "expired",
+					Schedule: &secv1alpha1.RuleSchedule{ExpirationTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+				},
+				{
+					Action:   &allowAction,
+					From:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+					Schedule: &secv1alpha1.RuleSchedule{ExpirationTime: &expiration},
+				},
+			},
+			Egress: []secv1alpha1.Rule{
+				{
+					Action: &allowAction,
+					To:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+				},
+			},
+		},
+	}
+	_, c := newController()
+	internalNP := c.processClusterNetworkPolicy(cnp)
+	require.Len(t, internalNP.Rules, 2)
+	// The priority of the active ingress rule is its index in the policy.
+	assert.Equal(t, int32(1), internalNP.Rules[0].Priority)
+	assert.Equal(t, []secv1alpha1.ScheduledRuleStatus{
+		{Name: "expired", Active: false},
+		{Name: "ingress-1", Active: true, NextTransitionTime: &expiration},
+	}, internalNP.ScheduledRules)
+}
+
+func TestSyncRuleScheduleTransition(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	selectorA := metav1.LabelSelector{MatchLabels: map[string]string{"foo1": "bar1"}}
+	now := time.Now()
+	cnp := &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnpA", UID: "uidA"},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+			Priority:  1,
+			Ingress: []secv1alpha1.Rule{
+				{
+					Action:   &allowAction,
+					From:     []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selectorA}},
+					Schedule: &secv1alpha1.RuleSchedule{ExpirationTime: &metav1.Time{Time: now.Add(time.Hour)}},
+				},
+			},
+		},
+	}
+	_, c := newController()
+	c.cnpLister = c.crdInformerFactory.Security().V1alpha1().ClusterNetworkPolicies().Lister()
+	c.cnpStore.Add(cnp)
+	c.addCNP(cnp)
+	key := internalNetworkPolicyKeyFunc(cnp)
+	require.NoError(t, c.syncInternalNetworkPolicy(key))
+	obj, _, _ := c.internalNetworkPolicyStore.Get(key)
+	require.Len(t, obj.(*antreatypes.NetworkPolicy).Rules, 1)
+	assert.Len(t, obj.(*antreatypes.NetworkPolicy).ScheduledRules, 1)
+
+	// The rule expires: the policy is processed again when the internal NetworkPolicy is synced.
+	cnp.Spec.Ingress[0].Schedule.ExpirationTime = &metav1.Time{Time: now.Add(-time.Second)}
+	obj.(*antreatypes.NetworkPolicy).ScheduledRules[0].NextTransitionTime = &metav1.Time{Time: now.Add(-time.Second)}
+	require.NoError(t, c.syncInternalNetworkPolicy(key))
+	obj, _, _ = c.internalNetworkPolicyStore.Get(key)
+	assert.Empty(t, obj.(*antreatypes.NetworkPolicy).Rules)
+	assert.Equal(t, []secv1alpha1.ScheduledRuleStatus{{Name: "ingress-0", Active: false}}, obj.(*antreatypes.NetworkPolicy).ScheduledRules)
+	// The AddressGroup of the deactivated rule is no longer referenced.
+	assert.Empty(t, c.addressGroupStore.List())
+}
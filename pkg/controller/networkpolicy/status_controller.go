@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -39,6 +40,9 @@ import (
 
 const (
 	statusControllerName = "NetworkPolicyStatusController"
+	// ruleAnalysisInterval is the interval at which all Antrea-native policies are analyzed again, to update
+	// the RulesIneffective conditions affected by the changes of other policies.
+	ruleAnalysisInterval = time.Minute
 )
 
 // StatusController is responsible for synchronizing the status of Antrea ClusterNetworkPolicy and Antrea NetworkPolicy.
@@ -68,9 +72,13 @@ type StatusController struct {
 	anpLister seclisters.NetworkPolicyLister
 	// anpListerSynced is a function which returns true if the AntreaNetworkPolicies shared informer has been synced at least once.
 	anpListerSynced cache.InformerSynced
+
+	// ruleAnalyzer finds the rules of Antrea-native policies which can never be matched, to report them in the
+	// RulesIneffective condition. The condition is not reported if it's nil.
+	ruleAnalyzer *RuleAnalyzer
 }
 
-func NewStatusController(antreaClient antreaclientset.Interface, internalNetworkPolicyStore storage.Interface, cnpInformer secinformers.ClusterNetworkPolicyInformer, anpInformer secinformers.NetworkPolicyInformer) *StatusController {
+func NewStatusController(antreaClient antreaclientset.Interface, internalNetworkPolicyStore storage.Interface, cnpInformer secinformers.ClusterNetworkPolicyInformer, anpInformer secinformers.NetworkPolicyInformer, ruleAnalyzer *RuleAnalyzer) *StatusController {
 	c := &StatusController{
 		npControlInterface: &networkPolicyControl{
 			antreaClient: antreaClient,
@@ -80,8 +88,11 @@ func NewStatusController(antreaClient antreaclientset.Interface, internalNetwork
 		queue:                      workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "networkpolicy"),
 		internalNetworkPolicyStore: internalNetworkPolicyStore,
 		statuses:                   map[string]map[string]*controlplane.NetworkPolicyNodeStatus{},
+		cnpLister:                  cnpInformer.Lister(),
 		cnpListerSynced:            cnpInformer.Informer().HasSynced,
+		anpLister:                  anpInformer.Lister(),
 		anpListerSynced:            anpInformer.Informer().HasSynced,
+		ruleAnalyzer:               ruleAnalyzer,
 	}
 	// To save a "GET" query before each update, UpdateAntreaClusterNetworkPolicyStatus treats the cache of Lister as
 	// the state of kube-apiserver. In some cases the cache may not be in sync, then we might skip updating a policy's
@@ -108,7 +119,7 @@ func NewStatusController(antreaClient antreaclientset.Interface, internalNetwork
 func (c *StatusController) updateCNP(old, cur interface{}) {
 	curCNP := cur.(*secv1alpha1.ClusterNetworkPolicy)
 	oldCNP := old.(*secv1alpha1.ClusterNetworkPolicy)
-	if oldCNP.Status == curCNP.Status {
+	if apiequality.Semantic.DeepEqual(oldCNP.Status, curCNP.Status) {
 		return
 	}
 	key := internalNetworkPolicyKeyFunc(oldCNP)
@@ -118,7 +129,7 @@ func (c *StatusController) updateCNP(old, cur interface{}) {
 func (c *StatusController) updateANP(old, cur interface{}) {
 	curANP := cur.(*secv1alpha1.NetworkPolicy)
 	oldANP := old.(*secv1alpha1.NetworkPolicy)
-	if oldANP.Status == curANP.Status {
+	if apiequality.Semantic.DeepEqual(oldANP.Status, curANP.Status) {
 		return
 	}
 	key := internalNetworkPolicyKeyFunc(oldANP)
@@ -191,6 +202,12 @@ func (c *StatusController) Run(stopCh <-chan struct{}) {
 
 	go wait.NonSlidingUntil(c.watchInternalNetworkPolicy, 5*time.Second, stopCh)
 
+	// The rules of a policy may be shadowed by the rules of other policies, which are not watched by a policy's
+	// sync. Instead of analyzing all the policies each time a policy changes, they are analyzed periodically.
+	if c.ruleAnalyzer != nil {
+		go wait.Until(c.enqueueAllAntreaPolicies, ruleAnalysisInterval, stopCh)
+	}
+
 	for i := 0; i < defaultWorkers; i++ {
 		go wait.Until(c.runWorker, time.Second, stopCh)
 	}
@@ -198,7 +215,7 @@ func (c *StatusController) Run(stopCh <-chan struct{}) {
 }
 
 func (c *StatusController) watchInternalNetworkPolicy() {
-	watcher, err := c.internalNetworkPolicyStore.Watch(context.TODO(), "", labels.Everything(), fields.Everything())
+	watcher, err := c.internalNetworkPolicyStore.Watch(context.TODO(), "", labels.Everything(), fields.Everything(), storage.WatchOptions{})
 	if err != nil {
 		klog.Errorf("Failed to start watch for internal NetworkPolicy: %v", err)
 		return
@@ -224,6 +241,15 @@ func (c *StatusController) watchInternalNetworkPolicy() {
 	}
 }
 
+func (c *StatusController) enqueueAllAntreaPolicies() {
+	for _, obj := range c.internalNetworkPolicyStore.List() {
+		np := obj.(*antreatypes.NetworkPolicy)
+		if np.SourceRef.Type != controlplane.K8sNetworkPolicy {
+			c.queue.Add(np.Name)
+		}
+	}
+}
+
 func (c *StatusController) runWorker() {
 	for c.processNextWorkItem() {
 	}
@@ -268,10 +294,16 @@ func (c *StatusController) syncHandler(key string) error {
 	// It means the NetworkPolicy hasn't been processed once. Set it to Pending to differentiate from NetworkPolicies
 	// that spans 0 Node.
 	if internalNP.SpanMeta.NodeNames == nil {
-		return c.npControlInterface.UpdateAntreaNetworkPolicyStatus(internalNP.SourceRef.Namespace, internalNP.SourceRef.Name, &secv1alpha1.NetworkPolicyStatus{
+		status := &secv1alpha1.NetworkPolicyStatus{
 			Phase:              secv1alpha1.NetworkPolicyPending,
 			ObservedGeneration: internalNP.Generation,
-		})
+			Conditions:         c.getConditions(internalNP.SourceRef),
+			ScheduledRules:     internalNP.ScheduledRules,
+		}
+		if internalNP.SourceRef.Type == controlplane.AntreaNetworkPolicy {
+			return c.npControlInterface.UpdateAntreaNetworkPolicyStatus(internalNP.SourceRef.Namespace, internalNP.SourceRef.Name, status)
+		}
+		return c.npControlInterface.UpdateAntreaClusterNetworkPolicyStatus(internalNP.SourceRef.Name, status)
 	}
 	desiredNodes := len(internalNP.SpanMeta.NodeNames)
 	currentNodes := 0
@@ -297,6 +329,8 @@ func (c *StatusController) syncHandler(key string) error {
 		ObservedGeneration:   internalNP.Generation,
 		CurrentNodesRealized: int32(currentNodes),
 		DesiredNodesRealized: int32(desiredNodes),
+		Conditions:           c.getConditions(internalNP.SourceRef),
+		ScheduledRules:       internalNP.ScheduledRules,
 	}
 	klog.V(2).Infof("Updating NetworkPolicy %s status: %v", internalNP.SourceRef.ToString(), status)
 	if internalNP.SourceRef.Type == controlplane.AntreaNetworkPolicy {
@@ -306,6 +340,43 @@ func (c *StatusController) syncHandler(key string) error {
 	}
 }
 
+// getConditions returns the conditions of an Antrea-native policy computed from its spec.
+func (c *StatusController) getConditions(ref *controlplane.NetworkPolicyReference) []secv1alpha1.NetworkPolicyCondition {
+	if c.ruleAnalyzer == nil {
+		return nil
+	}
+	var findings []ruleFinding
+	if ref.Type == controlplane.AntreaNetworkPolicy {
+		anp, err := c.anpLister.NetworkPolicies(ref.Namespace).Get(ref.Name)
+		if err != nil {
+			return nil
+		}
+		findings = c.ruleAnalyzer.analyzeANP(anp)
+	} else {
+		cnp, err := c.cnpLister.Get(ref.Name)
+		if err != nil {
+			return nil
+		}
+		findings = c.ruleAnalyzer.analyzeCNP(cnp)
+	}
+	return []secv1alpha1.NetworkPolicyCondition{findingsToCondition(findings)}
+}
+
+// mergeConditions sets the LastTransitionTime of the desired conditions, keeping the one of the
+// current condition of the same type if its status is unchanged.
+func mergeConditions(current, desired []secv1alpha1.NetworkPolicyCondition) {
+	now := v1.Now()
+	for i := range desired {
+		desired[i].LastTransitionTime = now
+		for _, cond := range current {
+			if cond.Type == desired[i].Type && cond.Status == desired[i].Status {
+				desired[i].LastTransitionTime = cond.LastTransitionTime
+				break
+			}
+		}
+	}
+}
+
 // networkPolicyControlInterface is an interface that knows how to update Antrea NetworkPolicy status.
 // It's created as an interface to allow testing.
 type networkPolicyControlInterface interface {
@@ -325,7 +396,8 @@ func (c *networkPolicyControl) UpdateAntreaNetworkPolicyStatus(namespace, name s
 		klog.Infof("Didn't find the original Antrea NetworkPolicy %s/%s, skip updating status", namespace, name)
 		return nil
 	}
-	if anp.Status == *status {
+	mergeConditions(anp.Status.Conditions, status.Conditions)
+	if apiequality.Semantic.DeepEqual(anp.Status, *status) {
 		return nil
 	}
 	toUpdate := anp.DeepCopy()
@@ -341,7 +413,8 @@ func (c *networkPolicyControl) UpdateAntreaClusterNetworkPolicyStatus(name strin
 		return nil
 	}
 	// If the current status equals to the desired status, no need to update.
-	if cnp.Status == *status {
+	mergeConditions(cnp.Status.Conditions, status.Conditions)
+	if apiequality.Semantic.DeepEqual(cnp.Status, *status) {
 		return nil
 	}
 	toUpdate := cnp.DeepCopy()
@@ -348,3 +348,22 @@ func BenchmarkSyncHandler(b *testing.B) {
 		statusController.syncHandler("anp1")
 	}
 }
+
+func TestMergeConditions(t *testing.T) {
+	lastTransitionTime := v1.NewTime(time.Now().Add(-time.Hour))
+	current := []secv1alpha1.NetworkPolicyCondition{
+		{Type: secv1alpha1.NetworkPolicyRulesIneffective, Status: "True", LastTransitionTime: lastTransitionTime, Reason: ruleShadowed},
+	}
+	// The status is unchanged, the LastTransitionTime must be kept.
+	desired := []secv1alpha1.NetworkPolicyCondition{
+		{Type: secv1alpha1.NetworkPolicyRulesIneffective, Status: "True", Reason: ruleRedundant},
+	}
+	mergeConditions(current, desired)
+	assert.Equal(t, lastTransitionTime, desired[0].LastTransitionTime)
+	// The status is changed, the LastTransitionTime must be updated.
+	desired = []secv1alpha1.NetworkPolicyCondition{
+		{Type: secv1alpha1.NetworkPolicyRulesIneffective, Status: "False"},
+	}
+	mergeConditions(current, desired)
+	assert.True(t, desired[0].LastTransitionTime.After(lastTransitionTime.Time))
+}
@@ -147,7 +147,7 @@ func TestWatchAddressGroupEvent(t *testing.T) {
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
 			store := NewAddressGroupStore()
-			w, err := store.Watch(context.Background(), "", labels.Everything(), testCase.fieldSelector)
+			w, err := store.Watch(context.Background(), "", labels.Everything(), testCase.fieldSelector, storage.WatchOptions{})
 			if err != nil {
 				t.Errorf("Failed to watch object: %v", err)
 			}
@@ -175,6 +175,13 @@ func NewAppliedToGroupStore() storage.Interface {
 			}
 			return []string{atg.Selector.Namespace}, nil
 		},
+		GroupLabelIndex: func(obj interface{}) ([]string, error) {
+			atg, ok := obj.(*types.AppliedToGroup)
+			if !ok {
+				return []string{}, nil
+			}
+			return groupLabelIndexFunc(&atg.Selector), nil
+		},
 	}
 	return ram.NewStore(AppliedToGroupKeyFunc, indexers, genAppliedToGroupEvent, keyAndSpanSelectFunc, func() runtime.Object { return new(controlplane.AppliedToGroup) })
 }
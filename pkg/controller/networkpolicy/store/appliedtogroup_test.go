@@ -157,7 +157,7 @@ func TestWatchAppliedToGroupEvent(t *testing.T) {
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
 			store := NewAppliedToGroupStore()
-			w, err := store.Watch(context.Background(), "", labels.Everything(), testCase.fieldSelector)
+			w, err := store.Watch(context.Background(), "", labels.Everything(), testCase.fieldSelector, storage.WatchOptions{})
 			if err != nil {
 				t.Fatalf("Failed to watch object: %v", err)
 			}
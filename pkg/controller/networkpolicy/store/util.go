@@ -17,10 +17,152 @@ package store
 import (
 	"reflect"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
 	"github.com/vmware-tanzu/antrea/pkg/apiserver/storage"
 	"github.com/vmware-tanzu/antrea/pkg/controller/types"
 )
 
+const (
+	// GroupLabelIndex is the name of the index of the groups by the labels which the Pods, the ExternalEntities or
+	// their Namespaces must have to be selected by the groups. Use GroupLabelIndexKeys to get the candidate groups of
+	// a Pod or an ExternalEntity, and evaluate their selectors to get the matching ones.
+	GroupLabelIndex = "groupLabel"
+	// NamespaceLabelIndex is the name of the index of the cluster scoped groups by the labels which the Namespaces must
+	// have to be selected by their NamespaceSelector. Use NamespaceLabelIndexKeys to get the candidate groups of a
+	// Namespace.
+	NamespaceLabelIndex = "namespaceLabel"
+
+	// anyLabelTerm is the index term of the label selectors which don't require any label.
+	anyLabelTerm = "*"
+
+	podKind            = "pod"
+	externalEntityKind = "externalentity"
+	namespaceKind      = "namespace"
+)
+
+// selectorLabelTerms returns the index terms of the label selector, i.e. "key=value" or "key" labels one of which all
+// the label sets selected by the selector have. The first requirement on the values of a key is used as it's the most
+// selective, then the first requirement on the existence of a key. anyLabelTerm is returned if the selector doesn't
+// require any label, and false is returned if the selector doesn't select any label set.
+func selectorLabelTerms(selector labels.Selector) ([]string, bool) {
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return nil, false
+	}
+	var keyTerms []string
+	for _, r := range requirements {
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			var terms []string
+			for _, value := range r.Values().List() {
+				terms = append(terms, r.Key()+"="+value)
+			}
+			return terms, true
+		case selection.Exists, selection.GreaterThan, selection.LessThan:
+			if keyTerms == nil {
+				keyTerms = []string{r.Key()}
+			}
+		}
+	}
+	if keyTerms != nil {
+		return keyTerms, true
+	}
+	return []string{anyLabelTerm}, true
+}
+
+// labelTerms returns all the index terms matching the label set.
+func labelTerms(set map[string]string) []string {
+	terms := make([]string, 0, 2*len(set)+1)
+	terms = append(terms, anyLabelTerm)
+	for key, value := range set {
+		terms = append(terms, key+"="+value, key)
+	}
+	return terms
+}
+
+// labelIndexKey returns the label index key of the term in the scope, which is the Namespace of the Namespace scoped
+// groups and empty for the cluster scoped groups, and the kind of the selected objects.
+func labelIndexKey(scope, kind, term string) string {
+	return scope + "/" + kind + ":" + term
+}
+
+// groupLabelIndexFunc returns the GroupLabelIndex keys of the GroupSelector. A group is indexed by the terms of its
+// PodSelector or ExternalEntitySelector, unless they don't require any label and the group is also indexed by the
+// terms of its NamespaceSelector. The groups which can't select any Pod or ExternalEntity are not indexed.
+func groupLabelIndexFunc(selector *types.GroupSelector) []string {
+	if selector.NodeSelector != nil {
+		return []string{}
+	}
+	kind, objSelector := podKind, selector.PodSelector
+	if objSelector == nil {
+		kind, objSelector = externalEntityKind, selector.ExternalEntitySelector
+	}
+	var keys []string
+	if objSelector != nil {
+		terms, selectable := selectorLabelTerms(objSelector)
+		if !selectable {
+			return []string{}
+		}
+		if terms[0] != anyLabelTerm || selector.NamespaceSelector == nil {
+			for _, term := range terms {
+				keys = append(keys, labelIndexKey(selector.Namespace, kind, term))
+			}
+			return keys
+		}
+	} else if selector.NamespaceSelector == nil {
+		return []string{}
+	}
+	terms, selectable := selectorLabelTerms(selector.NamespaceSelector)
+	if !selectable {
+		return []string{}
+	}
+	for _, term := range terms {
+		keys = append(keys, labelIndexKey("", namespaceKind, term))
+	}
+	return keys
+}
+
+// namespaceLabelIndexFunc returns the NamespaceLabelIndex keys of the GroupSelector.
+func namespaceLabelIndexFunc(selector *types.GroupSelector) []string {
+	if selector.NamespaceSelector == nil {
+		return []string{}
+	}
+	terms, selectable := selectorLabelTerms(selector.NamespaceSelector)
+	if !selectable {
+		return []string{}
+	}
+	return terms
+}
+
+// GroupLabelIndexKeys returns the GroupLabelIndex keys of the groups which may select the Pod or the ExternalEntity.
+// ns is the Namespace of the object, or nil if it's not known yet.
+func GroupLabelIndexKeys(obj metav1.Object, ns *v1.Namespace) []string {
+	kind := externalEntityKind
+	if _, ok := obj.(*v1.Pod); ok {
+		kind = podKind
+	}
+	var keys []string
+	for _, term := range labelTerms(obj.GetLabels()) {
+		keys = append(keys, labelIndexKey(obj.GetNamespace(), kind, term), labelIndexKey("", kind, term))
+	}
+	// The groups selecting Namespaces can't select the object until its Namespace is known.
+	if ns != nil {
+		for _, term := range labelTerms(ns.Labels) {
+			keys = append(keys, labelIndexKey("", namespaceKind, term))
+		}
+	}
+	return keys
+}
+
+// NamespaceLabelIndexKeys returns the NamespaceLabelIndex keys of the groups which may select the Namespace.
+func NamespaceLabelIndexKeys(ns *v1.Namespace) []string {
+	return labelTerms(ns.Labels)
+}
+
 // keyAndSpanSelectFunc returns whether the provided selectors matches the key and/or the nodeNames.
 func keyAndSpanSelectFunc(selectors *storage.Selectors, key string, obj interface{}) bool {
 	// If Key is present in selectors, the provided key must match it.
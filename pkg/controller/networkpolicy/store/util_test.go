@@ -0,0 +1,119 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	"github.com/vmware-tanzu/antrea/pkg/controller/types"
+)
+
+func mustSelector(t *testing.T, selector *metav1.LabelSelector) labels.Selector {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestGroupLabelIndex(t *testing.T) {
+	webSelector := mustSelector(t, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}})
+	tierSelector := mustSelector(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"db", "cache"}}},
+	})
+	roleExistsSelector := mustSelector(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "role", Operator: metav1.LabelSelectorOpExists}},
+	})
+	notTestSelector := mustSelector(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"test"}}},
+	})
+	prodSelector := mustSelector(t, &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}})
+
+	groups := []*types.AddressGroup{
+		{Name: "ns1-web", Selector: types.GroupSelector{Namespace: "ns1", PodSelector: webSelector}},
+		{Name: "ns1-all", Selector: types.GroupSelector{Namespace: "ns1", PodSelector: labels.Everything()}},
+		{Name: "ns2-web", Selector: types.GroupSelector{Namespace: "ns2", PodSelector: webSelector}},
+		{Name: "ns1-ee-web", Selector: types.GroupSelector{Namespace: "ns1", ExternalEntitySelector: webSelector}},
+		{Name: "cluster-tier", Selector: types.GroupSelector{PodSelector: tierSelector}},
+		{Name: "cluster-role", Selector: types.GroupSelector{PodSelector: roleExistsSelector}},
+		{Name: "cluster-not-test", Selector: types.GroupSelector{PodSelector: notTestSelector}},
+		{Name: "prod-all", Selector: types.GroupSelector{PodSelector: labels.Everything(), NamespaceSelector: prodSelector}},
+		{Name: "prod-web", Selector: types.GroupSelector{PodSelector: webSelector, NamespaceSelector: prodSelector}},
+		{Name: "prod", Selector: types.GroupSelector{NamespaceSelector: prodSelector}},
+		{Name: "nodes", Selector: types.GroupSelector{NodeSelector: labels.Everything()}},
+	}
+	s := NewAddressGroupStore()
+	for _, group := range groups {
+		s.Create(group)
+	}
+	getGroups := func(indexName string, keys []string) sets.String {
+		names := sets.NewString()
+		for _, key := range keys {
+			objs, err := s.GetByIndex(indexName, key)
+			assert.NoError(t, err)
+			for _, obj := range objs {
+				names.Insert(obj.(*types.AddressGroup).Name)
+			}
+		}
+		return names
+	}
+
+	prodNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"env": "prod"}}}
+	devNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"env": "dev"}}}
+	tests := []struct {
+		name           string
+		obj            metav1.Object
+		ns             *v1.Namespace
+		expectedGroups sets.String
+	}{
+		{
+			name:           "web Pod in prod Namespace",
+			obj:            &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Labels: map[string]string{"app": "web"}}},
+			ns:             prodNamespace,
+			expectedGroups: sets.NewString("ns1-web", "ns1-all", "cluster-not-test", "prod-all", "prod-web", "prod"),
+		},
+		{
+			name:           "db Pod with role in dev Namespace",
+			obj:            &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Labels: map[string]string{"tier": "db", "role": "primary"}}},
+			ns:             devNamespace,
+			expectedGroups: sets.NewString("ns1-all", "cluster-tier", "cluster-role", "cluster-not-test"),
+		},
+		{
+			name: "web Pod in unknown Namespace",
+			obj:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Labels: map[string]string{"app": "web"}}},
+			// The groups indexed by their PodSelector are candidates regardless of their NamespaceSelector.
+			expectedGroups: sets.NewString("ns1-web", "ns1-all", "cluster-not-test", "prod-web"),
+		},
+		{
+			name:           "web ExternalEntity in dev Namespace",
+			obj:            &v1alpha2.ExternalEntity{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Labels: map[string]string{"app": "web"}}},
+			ns:             devNamespace,
+			expectedGroups: sets.NewString("ns1-ee-web"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedGroups, getGroups(GroupLabelIndex, GroupLabelIndexKeys(tt.obj, tt.ns)))
+		})
+	}
+
+	assert.Equal(t, sets.NewString("prod-all", "prod-web", "prod"), getGroups(NamespaceLabelIndex, NamespaceLabelIndexKeys(prodNamespace)))
+	assert.Equal(t, sets.NewString(), getGroups(NamespaceLabelIndex, NamespaceLabelIndexKeys(devNamespace)))
+}
@@ -15,14 +15,22 @@
 package networkpolicy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"net"
 	"strconv"
+	"strings"
 
 	admv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog"
 
 	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
@@ -56,6 +64,22 @@ type antreaPolicyValidator resourceValidator
 // tierValidator implements the validator interface for Tier resources.
 type tierValidator resourceValidator
 
+// tierUseVerb is the custom RBAC verb on Tiers which allows a user to create
+// and update the Antrea-native policies in a Tier.
+const tierUseVerb = "use"
+
+// minRateLimitBytesPerSecond is the lowest byte rate limit of a rule, as OVS
+// meters measure byte rates in kilobits per second.
+const minRateLimitBytesPerSecond = 125
+
+// maxRateLimitBytesPerSecond is the highest byte rate limit of a rule, whose
+// rate in bits per second still fits in the 32-bit rate of an OVS meter band.
+const maxRateLimitBytesPerSecond = math.MaxUint32 / 8
+
+// httpMethods is the set of HTTP request methods which can be matched by the
+// HTTP protocol of a rule.
+var httpMethods = sets.NewString("GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH")
+
 var (
 	// reservedTierPriorities stores the reserved priority range from 251, 252, 254 and 255.
 	// The priority 250 is reserved for default Tier but not part of this set in order to be
@@ -90,13 +114,18 @@ type NetworkPolicyValidator struct {
 	// tierValidators maintains a list of validator objects which
 	// implement the validator interface for Tier resources.
 	tierValidators []validator
+	// ruleAnalyzer finds the rules of admitted Antrea-native policies which
+	// can never be matched, to report them as admission warnings.
+	ruleAnalyzer *RuleAnalyzer
 }
 
 // NewNetworkPolicyValidator returns a new *NetworkPolicyValidator.
 func NewNetworkPolicyValidator(networkPolicyController *NetworkPolicyController) *NetworkPolicyValidator {
 	// initialize the validator registry with the default validators that need to
 	// be called.
-	vr := NetworkPolicyValidator{}
+	vr := NetworkPolicyValidator{
+		ruleAnalyzer: NewRuleAnalyzer(networkPolicyController),
+	}
 	// apv is an instance of antreaPolicyValidator to validate Antrea-native
 	// policy events.
 	apv := antreaPolicyValidator{
@@ -111,10 +140,13 @@ func NewNetworkPolicyValidator(networkPolicyController *NetworkPolicyController)
 	return &vr
 }
 
-// Validate function validates a Tier or Antrea Policy object
-func (v *NetworkPolicyValidator) Validate(ar *admv1.AdmissionReview) *admv1.AdmissionResponse {
+// Validate function validates a Tier or Antrea Policy object. Along with the
+// AdmissionResponse, it returns the warnings to be reported to the client
+// when an Antrea Policy is admitted with rules which can never be matched.
+func (v *NetworkPolicyValidator) Validate(ar *admv1.AdmissionReview) (*admv1.AdmissionResponse, []string) {
 	var result *metav1.Status
 	var msg string
+	var warnings []string
 	allowed := false
 	op := ar.Request.Operation
 	ui := ar.Request.UserInfo
@@ -127,13 +159,13 @@ func (v *NetworkPolicyValidator) Validate(ar *admv1.AdmissionReview) *admv1.Admi
 		if curRaw != nil {
 			if err := json.Unmarshal(curRaw, &curTier); err != nil {
 				klog.Errorf("Error de-serializing current Tier")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		if oldRaw != nil {
 			if err := json.Unmarshal(oldRaw, &oldTier); err != nil {
 				klog.Errorf("Error de-serializing old Tier")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		msg, allowed = v.validateTier(&curTier, &oldTier, op, ui)
@@ -143,32 +175,38 @@ func (v *NetworkPolicyValidator) Validate(ar *admv1.AdmissionReview) *admv1.Admi
 		if curRaw != nil {
 			if err := json.Unmarshal(curRaw, &curCNP); err != nil {
 				klog.Errorf("Error de-serializing current Antrea ClusterNetworkPolicy")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		if oldRaw != nil {
 			if err := json.Unmarshal(oldRaw, &oldCNP); err != nil {
 				klog.Errorf("Error de-serializing old Antrea ClusterNetworkPolicy")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		msg, allowed = v.validateAntreaPolicy(&curCNP, &oldCNP, op, ui)
+		if allowed && op != admv1.Delete {
+			warnings = findingsToWarnings(v.ruleAnalyzer.analyzeCNP(&curCNP))
+		}
 	case "NetworkPolicy":
 		klog.V(2).Info("Validating Antrea NetworkPolicy CRD")
 		var curANP, oldANP secv1alpha1.NetworkPolicy
 		if curRaw != nil {
 			if err := json.Unmarshal(curRaw, &curANP); err != nil {
 				klog.Errorf("Error de-serializing current Antrea NetworkPolicy")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		if oldRaw != nil {
 			if err := json.Unmarshal(oldRaw, &oldANP); err != nil {
 				klog.Errorf("Error de-serializing old Antrea NetworkPolicy")
-				return GetAdmissionResponseForErr(err)
+				return GetAdmissionResponseForErr(err), nil
 			}
 		}
 		msg, allowed = v.validateAntreaPolicy(&curANP, &oldANP, op, ui)
+		if allowed && op != admv1.Delete {
+			warnings = findingsToWarnings(v.ruleAnalyzer.analyzeANP(&curANP))
+		}
 	}
 	if msg != "" {
 		result = &metav1.Status{
@@ -178,7 +216,17 @@ func (v *NetworkPolicyValidator) Validate(ar *admv1.AdmissionReview) *admv1.Admi
 	return &admv1.AdmissionResponse{
 		Allowed: allowed,
 		Result:  result,
+	}, warnings
+}
+
+// findingsToWarnings converts the findings of the RuleAnalyzer to admission
+// warnings.
+func findingsToWarnings(findings []ruleFinding) []string {
+	var warnings []string
+	for _, f := range findings {
+		warnings = append(warnings, f.message)
 	}
+	return warnings
 }
 
 // validateAntreaPolicy validates the admission of a Antrea NetworkPolicy CRDs
@@ -271,27 +319,40 @@ func GetAdmissionResponseForErr(err error) *admv1.AdmissionResponse {
 // createValidate validates the CREATE events of Antrea-native policies,
 func (a *antreaPolicyValidator) createValidate(curObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
 	var tier string
+	var appliedTo []secv1alpha1.NetworkPolicyPeer
 	var ingress, egress []secv1alpha1.Rule
 	switch curObj.(type) {
 	case *secv1alpha1.ClusterNetworkPolicy:
 		curCNP := curObj.(*secv1alpha1.ClusterNetworkPolicy)
 		tier = curCNP.Spec.Tier
+		appliedTo = curCNP.Spec.AppliedTo
 		ingress = curCNP.Spec.Ingress
 		egress = curCNP.Spec.Egress
 	case *secv1alpha1.NetworkPolicy:
 		curANP := curObj.(*secv1alpha1.NetworkPolicy)
 		tier = curANP.Spec.Tier
+		appliedTo = curANP.Spec.AppliedTo
 		ingress = curANP.Spec.Ingress
 		egress = curANP.Spec.Egress
 	}
-	reason, allowed := a.validateTierForPolicy(tier)
+	reason, allowed := a.validateTierForPolicy(tier, userInfo)
 	if !allowed {
 		return reason, allowed
 	}
 	if ruleNameUnique := a.validateRuleName(ingress, egress); !ruleNameUnique {
 		return fmt.Sprint("rules names must be unique within the policy"), false
 	}
-	return "", true
+	if reason, allowed := a.validateRuleSchedules(ingress, egress); !allowed {
+		return reason, allowed
+	}
+	if reason, allowed := a.validateRateLimits(ingress, egress); !allowed {
+		return reason, allowed
+	}
+	if reason, allowed := a.validateL7Protocols(ingress, egress); !allowed {
+		return reason, allowed
+	}
+	_, namespaced := curObj.(*secv1alpha1.NetworkPolicy)
+	return a.validatePeers(appliedTo, ingress, egress, namespaced)
 }
 
 // validateRuleName validates if the name of each rule is unique within a policy
@@ -309,8 +370,210 @@ func (v *antreaPolicyValidator) validateRuleName(ingress, egress []secv1alpha1.R
 	return isUnique(ingress) && isUnique(egress)
 }
 
-// validateTierForPolicy validates whether a referenced Tier exists.
-func (v *antreaPolicyValidator) validateTierForPolicy(tier string) (string, bool) {
+// validateRuleSchedules validates the schedules of the rules of a policy.
+func (v *antreaPolicyValidator) validateRuleSchedules(ingress, egress []secv1alpha1.Rule) (string, bool) {
+	for _, rules := range [][]secv1alpha1.Rule{ingress, egress} {
+		for _, rule := range rules {
+			if rule.Schedule == nil {
+				continue
+			}
+			if reason, allowed := validateRuleSchedule(rule.Schedule); !allowed {
+				return reason, allowed
+			}
+		}
+	}
+	return "", true
+}
+
+// validateRateLimits validates that the rate limits of the rules of a policy are
+// set only for Allow rules and can be enforced with OVS meters, which measure
+// either packets or kilobits per second.
+func (v *antreaPolicyValidator) validateRateLimits(ingress, egress []secv1alpha1.Rule) (string, bool) {
+	for _, rules := range [][]secv1alpha1.Rule{ingress, egress} {
+		for _, rule := range rules {
+			rateLimit := rule.RateLimit
+			if rateLimit == nil {
+				continue
+			}
+			if rule.Action == nil || *rule.Action != secv1alpha1.RuleActionAllow {
+				return "rateLimit can only be set for rules with the Allow action", false
+			}
+			if rateLimit.ConnectionsPerSecond < 0 || rateLimit.PacketsPerSecond < 0 || rateLimit.BytesPerSecond < 0 {
+				return "rateLimit rates must not be negative", false
+			}
+			if rateLimit.ConnectionsPerSecond == 0 && rateLimit.PacketsPerSecond == 0 && rateLimit.BytesPerSecond == 0 {
+				return "rateLimit must set at least one of connectionsPerSecond, packetsPerSecond and bytesPerSecond", false
+			}
+			if rateLimit.PacketsPerSecond > 0 && rateLimit.BytesPerSecond > 0 {
+				return "rateLimit cannot set both packetsPerSecond and bytesPerSecond", false
+			}
+			if rateLimit.BytesPerSecond > 0 && rateLimit.BytesPerSecond < minRateLimitBytesPerSecond {
+				return fmt.Sprintf("rateLimit bytesPerSecond must be at least %d", minRateLimitBytesPerSecond), false
+			}
+			if rateLimit.BytesPerSecond > maxRateLimitBytesPerSecond {
+				return fmt.Sprintf("rateLimit bytesPerSecond must be at most %d", maxRateLimitBytesPerSecond), false
+			}
+		}
+	}
+	return "", true
+}
+
+// validateL7Protocols validates that the application layer protocols of the
+// rules of a policy are set only for ingress Allow rules matching TCP traffic,
+// and that the HTTP matches are well-formed.
+func (v *antreaPolicyValidator) validateL7Protocols(ingress, egress []secv1alpha1.Rule) (string, bool) {
+	for _, rule := range egress {
+		if len(rule.L7Protocols) > 0 {
+			return "l7Protocols can only be set for ingress rules", false
+		}
+	}
+	for _, rule := range ingress {
+		if len(rule.L7Protocols) == 0 {
+			continue
+		}
+		if rule.Action == nil || *rule.Action != secv1alpha1.RuleActionAllow {
+			return "l7Protocols can only be set for rules with the Allow action", false
+		}
+		for _, port := range rule.Ports {
+			if port.Protocol != nil && *port.Protocol != v1.ProtocolTCP {
+				return "l7Protocols can only be set for rules matching TCP ports", false
+			}
+		}
+		for _, l7Protocol := range rule.L7Protocols {
+			if l7Protocol.HTTP == nil {
+				return "l7Protocols must set a protocol", false
+			}
+			if reason, allowed := validateHTTPProtocol(l7Protocol.HTTP); !allowed {
+				return reason, allowed
+			}
+		}
+	}
+	return "", true
+}
+
+// validateHTTPProtocol validates the method, host and path of an HTTP match.
+func validateHTTPProtocol(http *secv1alpha1.HTTPProtocol) (string, bool) {
+	if http.Method != "" && !httpMethods.Has(http.Method) {
+		return fmt.Sprintf("invalid HTTP method %s", http.Method), false
+	}
+	if http.Host != "" {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(http.Host, "*.")); len(errs) > 0 {
+			return fmt.Sprintf("invalid HTTP host %s: %s", http.Host, strings.Join(errs, ", ")), false
+		}
+	}
+	if http.Path != "" {
+		if !strings.HasPrefix(http.Path, "/") {
+			return fmt.Sprintf("invalid HTTP path %s: it must start with /", http.Path), false
+		}
+		if strings.Contains(strings.TrimSuffix(http.Path, "*"), "*") {
+			return fmt.Sprintf("invalid HTTP path %s: * can only be used at the end", http.Path), false
+		}
+	}
+	return "", true
+}
+
+// validatePeers validates that the NetworkPolicyPeers used in the AppliedTo,
+// To and From fields of a policy set a supported combination of fields.
+// Only cluster scoped policies can be applied to Nodes.
+func (v *antreaPolicyValidator) validatePeers(appliedTo []secv1alpha1.NetworkPolicyPeer, ingress, egress []secv1alpha1.Rule, namespaced bool) (string, bool) {
+	appliedToNodes := false
+	for _, at := range appliedTo {
+		if at.NodeSelector == nil {
+			continue
+		}
+		if namespaced {
+			return "nodeSelector cannot be set in appliedTo of a namespaced policy", false
+		}
+		if at.PodSelector != nil || at.NamespaceSelector != nil || at.ExternalEntitySelector != nil || at.IPBlock != nil {
+			return "nodeSelector cannot be set with other appliedTo fields", false
+		}
+		appliedToNodes = true
+	}
+	if appliedToNodes {
+		if reason, allowed := validateNodeRules(ingress); !allowed {
+			return reason, allowed
+		}
+		if reason, allowed := validateNodeRules(egress); !allowed {
+			return reason, allowed
+		}
+	}
+	checkPeers := func(peers []secv1alpha1.NetworkPolicyPeer) (string, bool) {
+		for _, peer := range peers {
+			if peer.NodeSelector != nil && (peer.PodSelector != nil || peer.NamespaceSelector != nil || peer.ExternalEntitySelector != nil || peer.IPBlock != nil) {
+				return "nodeSelector cannot be set with other peer fields", false
+			}
+			if peer.IPBlock != nil {
+				if reason, allowed := validateIPBlock(peer.IPBlock); !allowed {
+					return reason, allowed
+				}
+			}
+		}
+		return "", true
+	}
+	for _, rule := range ingress {
+		if reason, allowed := checkPeers(rule.From); !allowed {
+			return reason, allowed
+		}
+	}
+	for _, rule := range egress {
+		if reason, allowed := checkPeers(rule.To); !allowed {
+			return reason, allowed
+		}
+	}
+	return "", true
+}
+
+// validateNodeRules validates that the rules of a policy applied to Nodes only
+// use the features supported by the iptables rules enforcing them on the Nodes.
+func validateNodeRules(rules []secv1alpha1.Rule) (string, bool) {
+	for _, rule := range rules {
+		if rule.RateLimit != nil {
+			return "rateLimit cannot be set in policies applied to Nodes", false
+		}
+		if len(rule.L7Protocols) > 0 {
+			return "l7Protocols cannot be set in policies applied to Nodes", false
+		}
+		for _, port := range rule.Ports {
+			if port.Port != nil && port.Port.Type == intstr.String {
+				return "named ports cannot be used in policies applied to Nodes", false
+			}
+		}
+	}
+	return "", true
+}
+
+// validateIPBlock validates that the CIDR of an IPBlock is valid and that all
+// the Except CIDRs are valid and fall within the CIDR range.
+func validateIPBlock(ipBlock *secv1alpha1.IPBlock) (string, bool) {
+	_, ipNet, err := net.ParseCIDR(ipBlock.CIDR)
+	if err != nil {
+		return fmt.Sprintf("invalid ipBlock cidr %s: %v", ipBlock.CIDR, err), false
+	}
+	ones, bits := ipNet.Mask.Size()
+	for _, except := range ipBlock.Except {
+		exceptIP, exceptNet, err := net.ParseCIDR(except)
+		if err != nil {
+			return fmt.Sprintf("invalid ipBlock except %s: %v", except, err), false
+		}
+		exceptOnes, exceptBits := exceptNet.Mask.Size()
+		if exceptBits != bits || exceptOnes < ones || !ipNet.Contains(exceptIP) {
+			return fmt.Sprintf("ipBlock except %s is not within cidr %s", except, ipBlock.CIDR), false
+		}
+	}
+	return "", true
+}
+
+// validateTierForPolicy validates whether a referenced Tier exists, and
+// whether the user is allowed to use it.
+func (v *antreaPolicyValidator) validateTierForPolicy(tier string, userInfo authenticationv1.UserInfo) (string, bool) {
+	if reason, allowed := v.validateTierExists(tier); !allowed {
+		return reason, allowed
+	}
+	return v.validateTierUse(tier, userInfo)
+}
+
+// validateTierExists validates whether a referenced Tier exists.
+func (v *antreaPolicyValidator) validateTierExists(tier string) (string, bool) {
 	// "tier" must exist before referencing
 	if tier == "" || staticTierSet.Has(tier) {
 		// Empty Tier name corresponds to default Tier.
@@ -323,18 +586,98 @@ func (v *antreaPolicyValidator) validateTierForPolicy(tier string) (string, bool
 	return "", true
 }
 
+// tierResourceName returns the name of the Tier resource referenced by the
+// Tier of a policy.
+func tierResourceName(tier string) string {
+	if tier == "" {
+		return defaultTierName
+	} else if staticTierSet.Has(tier) {
+		// Static Tier names correspond to the lower case names of the Tier CRDs.
+		return strings.ToLower(tier)
+	}
+	return tier
+}
+
+// validateTierUse validates whether the user has the "use" verb on the Tier,
+// which is required to create the policies in this Tier or to move policies
+// into it. It's checked with a SubjectAccessReview, so that the Tiers in which
+// a user can create policies can be delegated with RBAC.
+func (v *antreaPolicyValidator) validateTierUse(tier string, userInfo authenticationv1.UserInfo) (string, bool) {
+	tier = tierResourceName(tier)
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for k, val := range userInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(val)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     tierUseVerb,
+				Group:    secv1alpha1.SchemeGroupVersion.Group,
+				Version:  secv1alpha1.SchemeGroupVersion.Version,
+				Resource: "tiers",
+				Name:     tier,
+			},
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			Extra:  extra,
+			UID:    userInfo.UID,
+		},
+	}
+	response, err := v.networkPolicyController.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), sar, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Error when checking if user %s can use tier %s: %v", userInfo.Username, tier, err)
+		return fmt.Sprintf("unable to check if user %s can use tier %s", userInfo.Username, tier), false
+	}
+	if !response.Status.Allowed {
+		return fmt.Sprintf("user %s is not allowed to use tier %s", userInfo.Username, tier), false
+	}
+	return "", true
+}
+
 // updateValidate validates the UPDATE events of Antrea-native policies.
 func (a *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
-	var tier string
+	var tier, oldTier string
+	var appliedTo []secv1alpha1.NetworkPolicyPeer
+	var ingress, egress []secv1alpha1.Rule
 	switch curObj.(type) {
 	case *secv1alpha1.ClusterNetworkPolicy:
 		curCNP := curObj.(*secv1alpha1.ClusterNetworkPolicy)
 		tier = curCNP.Spec.Tier
+		oldTier = oldObj.(*secv1alpha1.ClusterNetworkPolicy).Spec.Tier
+		appliedTo = curCNP.Spec.AppliedTo
+		ingress = curCNP.Spec.Ingress
+		egress = curCNP.Spec.Egress
 	case *secv1alpha1.NetworkPolicy:
 		curANP := curObj.(*secv1alpha1.NetworkPolicy)
 		tier = curANP.Spec.Tier
+		oldTier = oldObj.(*secv1alpha1.NetworkPolicy).Spec.Tier
+		appliedTo = curANP.Spec.AppliedTo
+		ingress = curANP.Spec.Ingress
+		egress = curANP.Spec.Egress
+	}
+	reason, allowed := a.validateTierExists(tier)
+	if !allowed {
+		return reason, allowed
+	}
+	// The "use" verb is only required to move a policy into another Tier, so
+	// that the users who could update their policies before the verb was
+	// introduced can still update them in their current Tier.
+	if tierResourceName(tier) != tierResourceName(oldTier) {
+		if reason, allowed := a.validateTierUse(tier, userInfo); !allowed {
+			return reason, allowed
+		}
+	}
+	if reason, allowed := a.validateRuleSchedules(ingress, egress); !allowed {
+		return reason, allowed
+	}
+	if reason, allowed := a.validateRateLimits(ingress, egress); !allowed {
+		return reason, allowed
+	}
+	if reason, allowed := a.validateL7Protocols(ingress, egress); !allowed {
+		return reason, allowed
 	}
-	return a.validateTierForPolicy(tier)
+	_, namespaced := curObj.(*secv1alpha1.NetworkPolicy)
+	return a.validatePeers(appliedTo, ingress, egress, namespaced)
 }
 
 // deleteValidate validates the DELETE events of Antrea-native policies.
@@ -0,0 +1,314 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8stesting "k8s.io/client-go/testing"
+
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
+)
+
+func TestValidateIPBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		ipBlock *secv1alpha1.IPBlock
+		allowed bool
+	}{
+		{
+			"valid-cidr",
+			&secv1alpha1.IPBlock{CIDR: "10.0.0.0/8"},
+			true,
+		},
+		{
+			"invalid-cidr",
+			&secv1alpha1.IPBlock{CIDR: "10.0.0.0"},
+			false,
+		},
+		{
+			"valid-except",
+			&secv1alpha1.IPBlock{CIDR: "0.0.0.0/0", Except: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}},
+			true,
+		},
+		{
+			"invalid-except",
+			&secv1alpha1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.0.0.0"}},
+			false,
+		},
+		{
+			"except-outside-cidr",
+			&secv1alpha1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"192.168.0.0/16"}},
+			false,
+		},
+		{
+			"except-larger-than-cidr",
+			&secv1alpha1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.0.0/8"}},
+			false,
+		},
+		{
+			"except-different-family",
+			&secv1alpha1.IPBlock{CIDR: "0.0.0.0/0", Except: []string{"fd00::/8"}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, allowed := validateIPBlock(tt.ipBlock)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestValidatePeers(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	allowAction := secv1alpha1.RuleActionAllow
+	namedPort := intstr.FromString("http")
+	tests := []struct {
+		name       string
+		appliedTo  []secv1alpha1.NetworkPolicyPeer
+		ingress    []secv1alpha1.Rule
+		egress     []secv1alpha1.Rule
+		namespaced bool
+		allowed    bool
+	}{
+		{
+			"node-selector-peer",
+			[]secv1alpha1.NetworkPolicyPeer{{PodSelector: selector}},
+			nil,
+			[]secv1alpha1.Rule{{To: []secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector}}}},
+			false,
+			true,
+		},
+		{
+			"node-selector-with-pod-selector",
+			[]secv1alpha1.NetworkPolicyPeer{{PodSelector: selector}},
+			[]secv1alpha1.Rule{{From: []secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector, PodSelector: selector}}}},
+			nil,
+			false,
+			false,
+		},
+		{
+			"ip-block-with-invalid-except",
+			[]secv1alpha1.NetworkPolicyPeer{{PodSelector: selector}},
+			[]secv1alpha1.Rule{{From: []secv1alpha1.NetworkPolicyPeer{{IPBlock: &secv1alpha1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"11.0.0.0/8"}}}}}},
+			nil,
+			false,
+			false,
+		},
+		{
+			"node-selector-applied-to",
+			[]secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector}},
+			[]secv1alpha1.Rule{{From: []secv1alpha1.NetworkPolicyPeer{{IPBlock: &secv1alpha1.IPBlock{CIDR: "10.0.0.0/8"}}}}},
+			nil,
+			false,
+			true,
+		},
+		{
+			"node-selector-applied-to-in-namespaced-policy",
+			[]secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector}},
+			nil,
+			nil,
+			true,
+			false,
+		},
+		{
+			"node-selector-with-pod-selector-applied-to",
+			[]secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector, PodSelector: selector}},
+			nil,
+			nil,
+			false,
+			false,
+		},
+		{
+			"node-selector-applied-to-with-rate-limit",
+			[]secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector}},
+			[]secv1alpha1.Rule{{Action: &allowAction, RateLimit: &secv1alpha1.RateLimit{ConnectionsPerSecond: 10}}},
+			nil,
+			false,
+			false,
+		},
+		{
+			"node-selector-applied-to-with-named-port",
+			[]secv1alpha1.NetworkPolicyPeer{{NodeSelector: selector}},
+			nil,
+			[]secv1alpha1.Rule{{Action: &allowAction, Ports: []secv1alpha1.NetworkPolicyPort{{Port: &namedPort}}}},
+			false,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &antreaPolicyValidator{}
+			_, allowed := v.validatePeers(tt.appliedTo, tt.ingress, tt.egress, tt.namespaced)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestValidateRateLimits(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	tests := []struct {
+		name      string
+		action    *secv1alpha1.RuleAction
+		rateLimit *secv1alpha1.RateLimit
+		allowed   bool
+	}{
+		{"connections-and-bytes", &allowAction, &secv1alpha1.RateLimit{ConnectionsPerSecond: 10, BytesPerSecond: 1000000}, true},
+		{"drop-rule", &dropAction, &secv1alpha1.RateLimit{ConnectionsPerSecond: 10}, false},
+		{"no-rate", &allowAction, &secv1alpha1.RateLimit{}, false},
+		{"negative-rate", &allowAction, &secv1alpha1.RateLimit{PacketsPerSecond: -1, ConnectionsPerSecond: 10}, false},
+		{"packets-and-bytes", &allowAction, &secv1alpha1.RateLimit{PacketsPerSecond: 100, BytesPerSecond: 1000000}, false},
+		{"bytes-below-one-kilobit", &allowAction, &secv1alpha1.RateLimit{BytesPerSecond: 100}, false},
+		{"bytes-at-meter-limit", &allowAction, &secv1alpha1.RateLimit{BytesPerSecond: maxRateLimitBytesPerSecond}, true},
+		{"bytes-above-meter-limit", &allowAction, &secv1alpha1.RateLimit{BytesPerSecond: maxRateLimitBytesPerSecond + 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &antreaPolicyValidator{}
+			_, allowed := v.validateRateLimits(nil, []secv1alpha1.Rule{{Action: tt.action, RateLimit: tt.rateLimit}})
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestValidateL7Protocols(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	dropAction := secv1alpha1.RuleActionDrop
+	protocolTCP := v1.ProtocolTCP
+	protocolUDP := v1.ProtocolUDP
+	httpRule := func(action *secv1alpha1.RuleAction, protocol *v1.Protocol, http *secv1alpha1.HTTPProtocol) secv1alpha1.Rule {
+		return secv1alpha1.Rule{
+			Action:      action,
+			Ports:       []secv1alpha1.NetworkPolicyPort{{Protocol: protocol}},
+			L7Protocols: []secv1alpha1.L7Protocol{{HTTP: http}},
+		}
+	}
+	tests := []struct {
+		name    string
+		ingress []secv1alpha1.Rule
+		egress  []secv1alpha1.Rule
+		allowed bool
+	}{
+		{"http", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{Method: "GET", Host: "*.example.com", Path: "/api/*"})}, nil, true},
+		{"http-default-protocol", []secv1alpha1.Rule{httpRule(&allowAction, nil, &secv1alpha1.HTTPProtocol{})}, nil, true},
+		{"egress-rule", nil, []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{})}, false},
+		{"drop-rule", []secv1alpha1.Rule{httpRule(&dropAction, &protocolTCP, &secv1alpha1.HTTPProtocol{})}, nil, false},
+		{"udp-port", []secv1alpha1.Rule{httpRule(&allowAction, &protocolUDP, &secv1alpha1.HTTPProtocol{})}, nil, false},
+		{"no-protocol", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, nil)}, nil, false},
+		{"invalid-method", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{Method: "get"})}, nil, false},
+		{"invalid-host", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{Host: "example.*.com"})}, nil, false},
+		{"relative-path", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{Path: "api"})}, nil, false},
+		{"wildcard-in-path", []secv1alpha1.Rule{httpRule(&allowAction, &protocolTCP, &secv1alpha1.HTTPProtocol{Path: "/*/api"})}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &antreaPolicyValidator{}
+			_, allowed := v.validateL7Protocols(tt.ingress, tt.egress)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
+
+func TestValidateAntreaPolicyWarnings(t *testing.T) {
+	allowAction := secv1alpha1.RuleActionAllow
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	cnp := &secv1alpha1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnpA"},
+		Spec: secv1alpha1.ClusterNetworkPolicySpec{
+			Priority:  1,
+			AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selector}},
+			Ingress: []secv1alpha1.Rule{
+				{Action: &allowAction, Name: "allow-all"},
+				{Action: &allowAction, Name: "allow-foo", From: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selector}}},
+			},
+		},
+	}
+	raw, _ := json.Marshal(cnp)
+	_, npc := newController()
+	v := NewNetworkPolicyValidator(npc.NetworkPolicyController)
+	response, warnings := v.Validate(&admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Kind: "ClusterNetworkPolicy"},
+			Operation: admv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	assert.True(t, response.Allowed)
+	assert.Equal(t, []string{`ingress rule "allow-foo" is redundant with ingress rule "allow-all" which has action Allow`}, warnings)
+}
+
+func TestValidateTierUse(t *testing.T) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	newCNP := func(tier string) *secv1alpha1.ClusterNetworkPolicy {
+		return &secv1alpha1.ClusterNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "cnpA"},
+			Spec: secv1alpha1.ClusterNetworkPolicySpec{
+				Tier:      tier,
+				Priority:  1,
+				AppliedTo: []secv1alpha1.NetworkPolicyPeer{{PodSelector: &selector}},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		user       string
+		tier       string
+		expAllowed bool
+	}{
+		{"default-tier", "dev", "", true},
+		{"allowed-tier", "dev", "application", true},
+		{"denied-tier", "dev", "emergency", false},
+		{"denied-static-tier", "dev", "Emergency", false},
+		{"delegated-tier", "secops", "Emergency", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, npc := newController()
+			for _, tier := range systemGeneratedTiers {
+				npc.tierStore.Add(tier)
+			}
+			// Only "secops" can use the emergency Tier, all the users can use the application Tier.
+			client.PrependReactor("create", "subjectaccessreviews", k8stesting.ReactionFunc(func(action k8stesting.Action) (bool, runtime.Object, error) {
+				sar := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				attrs := sar.Spec.ResourceAttributes
+				sar.Status.Allowed = attrs.Verb == "use" && attrs.Resource == "tiers" && (attrs.Name == "application" || attrs.Name == "emergency" && sar.Spec.User == "secops")
+				return true, sar, nil
+			}))
+			v := antreaPolicyValidator{networkPolicyController: npc.NetworkPolicyController}
+			_, allowed := v.createValidate(newCNP(tt.tier), authenticationv1.UserInfo{Username: tt.user})
+			assert.Equal(t, tt.expAllowed, allowed)
+			_, allowed = v.updateValidate(newCNP(tt.tier), newCNP(""), authenticationv1.UserInfo{Username: tt.user})
+			assert.Equal(t, tt.expAllowed, allowed)
+			// A policy which already exists in the Tier can be updated
+			// without the "use" verb.
+			_, allowed = v.updateValidate(newCNP(tt.tier), newCNP(tt.tier), authenticationv1.UserInfo{Username: tt.user})
+			assert.True(t, allowed)
+			// A policy can be moved to a Tier which the user can use.
+			_, allowed = v.updateValidate(newCNP("application"), newCNP(tt.tier), authenticationv1.UserInfo{Username: tt.user})
+			assert.True(t, allowed)
+		})
+	}
+}
@@ -0,0 +1,582 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ip"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	clientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions/core/v1alpha2"
+	crdlisters "github.com/vmware-tanzu/antrea/pkg/client/listers/core/v1alpha2"
+)
+
+const (
+	controllerName = "ServiceExternalIPController"
+	// How long to wait before retrying the processing of a Service or an
+	// ExternalIPPool.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing the Services.
+	defaultWorkers = 4
+)
+
+// allocation is an IP allocated to a Service from an ExternalIPPool.
+type allocation struct {
+	pool string
+	ip   string
+}
+
+// ServiceExternalIPController allocates the ingress IPs of the LoadBalancer
+// Services annotated with an ExternalIPPool from the ExternalIPPool, and sets
+// them in the status of the Services. The allocations are persisted only in the
+// status of the Services, from which they are restored when the controller
+// starts.
+type ServiceExternalIPController struct {
+	client                     kubernetes.Interface
+	crdClient                  clientset.Interface
+	serviceLister              corelisters.ServiceLister
+	serviceListerSynced        cache.InformerSynced
+	externalIPPoolLister       crdlisters.ExternalIPPoolLister
+	externalIPPoolListerSynced cache.InformerSynced
+	queue                      workqueue.RateLimitingInterface
+	externalIPPoolStatusQueue  workqueue.RateLimitingInterface
+
+	// allocationsMutex protects allocations, serviceAllocations and
+	// pendingServices.
+	allocationsMutex sync.Mutex
+	// allocations maps the name of an ExternalIPPool to the IPs allocated
+	// from it, which are mapped to the keys of the Services owning them.
+	allocations map[string]map[string]string
+	// serviceAllocations maps the key of a Service to its allocation.
+	serviceAllocations map[string]allocation
+	// pendingServices maps the name of an ExternalIPPool to the keys of the
+	// Services which failed to get an IP from it, which are requeued when an
+	// IP of the ExternalIPPool is released.
+	pendingServices map[string]sets.String
+}
+
+// NewServiceExternalIPController returns a new *ServiceExternalIPController.
+func NewServiceExternalIPController(
+	client kubernetes.Interface,
+	crdClient clientset.Interface,
+	serviceInformer coreinformers.ServiceInformer,
+	externalIPPoolInformer crdinformers.ExternalIPPoolInformer) *ServiceExternalIPController {
+	c := &ServiceExternalIPController{
+		client:                     client,
+		crdClient:                  crdClient,
+		serviceLister:              serviceInformer.Lister(),
+		serviceListerSynced:        serviceInformer.Informer().HasSynced,
+		externalIPPoolLister:       externalIPPoolInformer.Lister(),
+		externalIPPoolListerSynced: externalIPPoolInformer.Informer().HasSynced,
+		queue:                      workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "serviceExternalIP"),
+		externalIPPoolStatusQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "externalIPPoolStatus"),
+		allocations:                map[string]map[string]string{},
+		serviceAllocations:         map[string]allocation{},
+		pendingServices:            map[string]sets.String{},
+	}
+	serviceInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueService,
+			UpdateFunc: func(old, cur interface{}) {
+				c.enqueueService(cur)
+			},
+			DeleteFunc: c.enqueueService,
+		},
+	)
+	externalIPPoolInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueExternalIPPool,
+			UpdateFunc: func(old, cur interface{}) {
+				c.enqueueExternalIPPool(cur)
+			},
+			DeleteFunc: c.enqueueExternalIPPool,
+		},
+	)
+	return c
+}
+
+func (c *ServiceExternalIPController) enqueueService(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get key of Service: %v", err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueExternalIPPool enqueues the Services which use the ExternalIPPool,
+// as their allocations may be affected by the change of the IP ranges, or the
+// deletion of the ExternalIPPool.
+func (c *ServiceExternalIPController) enqueueExternalIPPool(obj interface{}) {
+	pool, ok := obj.(*v1alpha2.ExternalIPPool)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		pool, ok = deletedState.Obj.(*v1alpha2.ExternalIPPool)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-ExternalIPPool object: %v", deletedState.Obj)
+			return
+		}
+	}
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list Services: %v", err)
+		return
+	}
+	for _, service := range services {
+		if service.Annotations[v1alpha2.ExternalIPPoolAnnotationKey] == pool.Name {
+			c.enqueueService(service)
+		}
+	}
+	c.allocationsMutex.Lock()
+	defer c.allocationsMutex.Unlock()
+	for key, a := range c.serviceAllocations {
+		if a.pool == pool.Name {
+			c.queue.Add(key)
+		}
+	}
+	c.externalIPPoolStatusQueue.Add(pool.Name)
+}
+
+// Run restores the allocations from the status of the Services, then starts
+// the workers processing the Services and the status of the ExternalIPPools.
+func (c *ServiceExternalIPController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+	defer c.externalIPPoolStatusQueue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.serviceListerSynced, c.externalIPPoolListerSynced) {
+		return
+	}
+	if err := c.restoreAllocations(); err != nil {
+		klog.Errorf("Failed to restore the allocations of the Service external IPs: %v", err)
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	go wait.Until(c.externalIPPoolStatusWorker, time.Second, stopCh)
+	<-stopCh
+}
+
+// restoreAllocations restores the allocations from the ingress IPs of the
+// Services, so that they are not allocated to other Services after the
+// controller restarts.
+func (c *ServiceExternalIPController) restoreAllocations() error {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	c.allocationsMutex.Lock()
+	defer c.allocationsMutex.Unlock()
+	for _, service := range services {
+		poolName := getExternalIPPoolName(service)
+		if poolName == "" {
+			continue
+		}
+		pool, err := c.externalIPPoolLister.Get(poolName)
+		if err != nil {
+			continue
+		}
+		key, _ := cache.MetaNamespaceKeyFunc(service)
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			ingressIP := net.ParseIP(ingress.IP)
+			if ingressIP == nil || !poolContains(pool, ingressIP) {
+				continue
+			}
+			if _, exists := c.allocations[poolName][ingressIP.String()]; exists {
+				klog.Warningf("IP %s of Service %s is already allocated to another Service", ingressIP, key)
+				continue
+			}
+			c.allocate(key, poolName, ingressIP.String())
+			break
+		}
+	}
+	return nil
+}
+
+func (c *ServiceExternalIPController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ServiceExternalIPController) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncService(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing Service %s, requeuing. Error: %v", key, err)
+	}
+	return true
+}
+
+func (c *ServiceExternalIPController) syncService(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing external IP of Service %s. (%v)", key, time.Since(startTime))
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		service = nil
+	}
+
+	allocatedIP, releasedIP, err := c.reconcileAllocation(key, service)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return nil
+	}
+	return c.updateServiceStatus(service, allocatedIP, releasedIP)
+}
+
+// reconcileAllocation makes sure the Service has an IP allocated from the
+// ExternalIPPool it requests, and releases the IP allocated to it if it no
+// longer requests one. It returns the IP allocated to the Service and the IP
+// released from it, either of which can be empty.
+func (c *ServiceExternalIPController) reconcileAllocation(key string, service *corev1.Service) (string, string, error) {
+	var poolName string
+	if service != nil {
+		poolName = getExternalIPPoolName(service)
+	}
+	var pool *v1alpha2.ExternalIPPool
+	if poolName != "" {
+		var err error
+		pool, err = c.externalIPPoolLister.Get(poolName)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", "", err
+			}
+			klog.Warningf("ExternalIPPool %s requested by Service %s not found", poolName, key)
+		}
+	}
+
+	c.allocationsMutex.Lock()
+	defer c.allocationsMutex.Unlock()
+
+	c.deletePendingService(key)
+	var releasedIP string
+	existing, hasExisting := c.serviceAllocations[key]
+	if hasExisting {
+		if pool != nil && existing.pool == poolName && poolContains(pool, net.ParseIP(existing.ip)) {
+			return existing.ip, "", nil
+		}
+		c.release(key)
+		releasedIP = existing.ip
+		klog.Infof("Released IP %s of ExternalIPPool %s from Service %s", existing.ip, existing.pool, key)
+	}
+	if pool == nil {
+		return "", releasedIP, nil
+	}
+
+	allocatedIP, err := c.allocateFromPool(pool, service.Spec.LoadBalancerIP)
+	if err != nil {
+		// Retrying would not help before an IP of the ExternalIPPool is
+		// released, or the ExternalIPPool changes, both of which requeue the
+		// Service.
+		klog.Errorf("Failed to allocate IP from ExternalIPPool %s for Service %s: %v", poolName, key, err)
+		if c.pendingServices[poolName] == nil {
+			c.pendingServices[poolName] = sets.NewString()
+		}
+		c.pendingServices[poolName].Insert(key)
+		return "", releasedIP, nil
+	}
+	c.allocate(key, poolName, allocatedIP)
+	klog.Infof("Allocated IP %s of ExternalIPPool %s to Service %s", allocatedIP, poolName, key)
+	return allocatedIP, releasedIP, nil
+}
+
+// allocateFromPool returns the requested IP if it's available in the
+// ExternalIPPool, or the first available IP if no IP is requested.
+func (c *ServiceExternalIPController) allocateFromPool(pool *v1alpha2.ExternalIPPool, requestedIP string) (string, error) {
+	allocated := c.allocations[pool.Name]
+	if requestedIP != "" {
+		ipAddr := net.ParseIP(requestedIP)
+		if ipAddr == nil || !poolContains(pool, ipAddr) {
+			return "", fmt.Errorf("requested IP %s is not in the ExternalIPPool", requestedIP)
+		}
+		if _, exists := allocated[ipAddr.String()]; exists {
+			return "", fmt.Errorf("requested IP %s is already allocated", requestedIP)
+		}
+		return ipAddr.String(), nil
+	}
+	for _, ipRange := range pool.Spec.IPRanges {
+		start, end, err := parseIPRange(&ipRange)
+		if err != nil {
+			continue
+		}
+		for cur := start; ip.Cmp(cur, end) <= 0; cur = ip.NextIP(cur) {
+			if _, exists := allocated[cur.String()]; !exists {
+				return cur.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no available IP")
+}
+
+// allocate, release and deletePendingService must be called with
+// allocationsMutex held.
+func (c *ServiceExternalIPController) allocate(key, poolName, ipAddr string) {
+	if c.allocations[poolName] == nil {
+		c.allocations[poolName] = map[string]string{}
+	}
+	c.allocations[poolName][ipAddr] = key
+	c.serviceAllocations[key] = allocation{pool: poolName, ip: ipAddr}
+	c.externalIPPoolStatusQueue.Add(poolName)
+}
+
+func (c *ServiceExternalIPController) release(key string) {
+	a, exists := c.serviceAllocations[key]
+	if !exists {
+		return
+	}
+	delete(c.allocations[a.pool], a.ip)
+	if len(c.allocations[a.pool]) == 0 {
+		delete(c.allocations, a.pool)
+	}
+	delete(c.serviceAllocations, key)
+	c.externalIPPoolStatusQueue.Add(a.pool)
+	// The released IP may be allocated to the Services waiting for one.
+	for pendingKey := range c.pendingServices[a.pool] {
+		c.queue.Add(pendingKey)
+	}
+}
+
+func (c *ServiceExternalIPController) deletePendingService(key string) {
+	for poolName, keys := range c.pendingServices {
+		keys.Delete(key)
+		if keys.Len() == 0 {
+			delete(c.pendingServices, poolName)
+		}
+	}
+}
+
+// updateServiceStatus sets the allocated IP as the only ingress IP of a Service
+// requesting an ExternalIPPool. For other Services, it only removes the IP
+// released from them, as their ingress IPs are not managed by this controller.
+func (c *ServiceExternalIPController) updateServiceStatus(service *corev1.Service, allocatedIP, releasedIP string) error {
+	var ingress []corev1.LoadBalancerIngress
+	if getExternalIPPoolName(service) != "" {
+		if allocatedIP != "" {
+			ingress = []corev1.LoadBalancerIngress{{IP: allocatedIP}}
+		}
+	} else {
+		if releasedIP == "" {
+			return nil
+		}
+		for _, i := range service.Status.LoadBalancer.Ingress {
+			if i.IP != releasedIP {
+				ingress = append(ingress, i)
+			}
+		}
+	}
+	if loadBalancerIngressEqual(service.Status.LoadBalancer.Ingress, ingress) {
+		return nil
+	}
+	toUpdate := service.DeepCopy()
+	toUpdate.Status.LoadBalancer.Ingress = ingress
+	if _, err := c.client.CoreV1().Services(service.Namespace).UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating the status of Service %s/%s: %v", service.Namespace, service.Name, err)
+	}
+	return nil
+}
+
+func (c *ServiceExternalIPController) externalIPPoolStatusWorker() {
+	for c.processNextExternalIPPoolStatusWorkItem() {
+	}
+}
+
+func (c *ServiceExternalIPController) processNextExternalIPPoolStatusWorkItem() bool {
+	obj, quit := c.externalIPPoolStatusQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.externalIPPoolStatusQueue.Done(obj)
+
+	if name, ok := obj.(string); !ok {
+		c.externalIPPoolStatusQueue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncExternalIPPoolStatus(name); err == nil {
+		c.externalIPPoolStatusQueue.Forget(name)
+	} else {
+		c.externalIPPoolStatusQueue.AddRateLimited(name)
+		klog.Errorf("Error syncing status of ExternalIPPool %s, requeuing. Error: %v", name, err)
+	}
+	return true
+}
+
+func (c *ServiceExternalIPController) syncExternalIPPoolStatus(name string) error {
+	pool, err := c.externalIPPoolLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	c.allocationsMutex.Lock()
+	usage := v1alpha2.ExternalIPPoolUsage{Total: poolSize(pool), Used: len(c.allocations[name])}
+	c.allocationsMutex.Unlock()
+	if pool.Status.Usage == usage {
+		return nil
+	}
+	toUpdate := pool.DeepCopy()
+	toUpdate.Status.Usage = usage
+	_, err = c.crdClient.CoreV1alpha2().ExternalIPPools().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+	return err
+}
+
+// getExternalIPPoolName returns the name of the ExternalIPPool the Service
+// requests its ingress IP from, or an empty string if it's not a LoadBalancer
+// Service annotated with an ExternalIPPool.
+func getExternalIPPoolName(service *corev1.Service) string {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+	return service.Annotations[v1alpha2.ExternalIPPoolAnnotationKey]
+}
+
+func loadBalancerIngressEqual(a, b []corev1.LoadBalancerIngress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseIPRange returns the first and the last IPs of the range. For an IPv4
+// CIDR, the network and broadcast addresses are excluded.
+func parseIPRange(ipRange *v1alpha2.IPRange) (net.IP, net.IP, error) {
+	if ipRange.CIDR != "" {
+		_, ipNet, err := net.ParseCIDR(ipRange.CIDR)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CIDR %s: %v", ipRange.CIDR, err)
+		}
+		ones, bits := ipNet.Mask.Size()
+		start := ipNet.IP
+		end := make(net.IP, len(ipNet.IP))
+		for i := range ipNet.IP {
+			end[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+		}
+		if bits == net.IPv4len*8 && bits-ones > 1 {
+			start = ip.NextIP(start)
+			end = ip.PrevIP(end)
+		}
+		return start, end, nil
+	}
+	start := net.ParseIP(ipRange.Start)
+	end := net.ParseIP(ipRange.End)
+	if start == nil || end == nil {
+		return nil, nil, fmt.Errorf("invalid IP range %s-%s", ipRange.Start, ipRange.End)
+	}
+	if (start.To4() != nil) != (end.To4() != nil) {
+		return nil, nil, fmt.Errorf("IP range %s-%s mixes IP families", ipRange.Start, ipRange.End)
+	}
+	if ip.Cmp(start, end) > 0 {
+		return nil, nil, fmt.Errorf("start IP %s of range is greater than end IP %s", ipRange.Start, ipRange.End)
+	}
+	return start, end, nil
+}
+
+func poolContains(pool *v1alpha2.ExternalIPPool, ipAddr net.IP) bool {
+	if ipAddr == nil {
+		return false
+	}
+	for _, ipRange := range pool.Spec.IPRanges {
+		start, end, err := parseIPRange(&ipRange)
+		if err != nil {
+			continue
+		}
+		if (ipAddr.To4() != nil) == (start.To4() != nil) && ip.Cmp(ipAddr, start) >= 0 && ip.Cmp(ipAddr, end) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// poolSize returns the number of IPs in the ExternalIPPool, capped to the
+// maximum int32 value for the large IPv6 ranges.
+func poolSize(pool *v1alpha2.ExternalIPPool) int {
+	total := big.NewInt(0)
+	for _, ipRange := range pool.Spec.IPRanges {
+		start, end, err := parseIPRange(&ipRange)
+		if err != nil {
+			klog.Errorf("Invalid IP range in ExternalIPPool %s: %v", pool.Name, err)
+			continue
+		}
+		size := new(big.Int).Sub(ipToInt(end), ipToInt(start))
+		total.Add(total, size.Add(size, big.NewInt(1)))
+	}
+	if !total.IsInt64() || total.Int64() > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(total.Int64())
+}
+
+func ipToInt(ipAddr net.IP) *big.Int {
+	if ipv4 := ipAddr.To4(); ipv4 != nil {
+		return new(big.Int).SetBytes(ipv4)
+	}
+	return new(big.Int).SetBytes(ipAddr.To16())
+}
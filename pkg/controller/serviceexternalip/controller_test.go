@@ -0,0 +1,266 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware-tanzu/antrea/pkg/apis/core/v1alpha2"
+	fakeversioned "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "github.com/vmware-tanzu/antrea/pkg/client/informers/externalversions"
+)
+
+func newExternalIPPool(name string, ranges ...v1alpha2.IPRange) *v1alpha2.ExternalIPPool {
+	return &v1alpha2.ExternalIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha2.ExternalIPPoolSpec{IPRanges: ranges},
+	}
+}
+
+func newService(name, poolName, loadBalancerIP string, ingressIPs ...string) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:           corev1.ServiceTypeLoadBalancer,
+			LoadBalancerIP: loadBalancerIP,
+		},
+	}
+	if poolName != "" {
+		service.Annotations = map[string]string{v1alpha2.ExternalIPPoolAnnotationKey: poolName}
+	}
+	for _, ingressIP := range ingressIPs {
+		service.Status.LoadBalancer.Ingress = append(service.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ingressIP})
+	}
+	return service
+}
+
+type fakeController struct {
+	*ServiceExternalIPController
+	client    *fake.Clientset
+	crdClient *fakeversioned.Clientset
+}
+
+func newFakeController(t *testing.T, objects []runtime.Object, crdObjects []runtime.Object) *fakeController {
+	client := fake.NewSimpleClientset(objects...)
+	crdClient := fakeversioned.NewSimpleClientset(crdObjects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	c := NewServiceExternalIPController(client, crdClient,
+		informerFactory.Core().V1().Services(),
+		crdInformerFactory.Core().V1alpha2().ExternalIPPools())
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	informerFactory.Start(stopCh)
+	crdInformerFactory.Start(stopCh)
+	go c.Run(stopCh)
+	return &fakeController{ServiceExternalIPController: c, client: client, crdClient: crdClient}
+}
+
+func (c *fakeController) waitForIngressIPs(t *testing.T, name string, expectedIPs ...string) {
+	var ingressIPs []string
+	err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		service, err := c.client.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		ingressIPs = nil
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			ingressIPs = append(ingressIPs, ingress.IP)
+		}
+		return assert.ObjectsAreEqual(expectedIPs, ingressIPs), nil
+	})
+	require.NoError(t, err, "Expected ingress IPs %v of Service %s, got %v", expectedIPs, name, ingressIPs)
+}
+
+func (c *fakeController) waitForUsage(t *testing.T, name string, expectedUsage v1alpha2.ExternalIPPoolUsage) {
+	var usage v1alpha2.ExternalIPPoolUsage
+	err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		pool, err := c.crdClient.CoreV1alpha2().ExternalIPPools().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		usage = pool.Status.Usage
+		return usage == expectedUsage, nil
+	})
+	require.NoError(t, err, "Expected usage %v of ExternalIPPool %s, got %v", expectedUsage, name, usage)
+}
+
+func TestAllocation(t *testing.T) {
+	pool := newExternalIPPool("pool1", v1alpha2.IPRange{Start: "10.10.10.2", End: "10.10.10.3"})
+	c := newFakeController(t, []runtime.Object{
+		newService("svc1", "pool1", ""),
+		newService("svc2", "pool1", "10.10.10.3"),
+		newService("not-annotated", "", "", "1.1.1.1"),
+	}, []runtime.Object{pool})
+
+	c.waitForIngressIPs(t, "svc1", "10.10.10.2")
+	c.waitForIngressIPs(t, "svc2", "10.10.10.3")
+	c.waitForIngressIPs(t, "not-annotated", "1.1.1.1")
+	c.waitForUsage(t, "pool1", v1alpha2.ExternalIPPoolUsage{Total: 2, Used: 2})
+
+	// The pool is exhausted.
+	_, err := c.client.CoreV1().Services("default").Create(context.TODO(), newService("svc3", "pool1", ""), metav1.CreateOptions{})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	c.waitForIngressIPs(t, "svc3")
+
+	// The IP is released when the Service is deleted, and allocated to the
+	// pending Service, which is requeued when an IP of the ExternalIPPool is
+	// released.
+	err = c.client.CoreV1().Services("default").Delete(context.TODO(), "svc1", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	c.waitForIngressIPs(t, "svc3", "10.10.10.2")
+	c.waitForUsage(t, "pool1", v1alpha2.ExternalIPPoolUsage{Total: 2, Used: 2})
+
+	// The IP is released and removed from the status when the annotation
+	// is removed.
+	svc2, err := c.client.CoreV1().Services("default").Get(context.TODO(), "svc2", metav1.GetOptions{})
+	require.NoError(t, err)
+	svc2.Annotations = nil
+	_, err = c.client.CoreV1().Services("default").Update(context.TODO(), svc2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	c.waitForIngressIPs(t, "svc2")
+	c.waitForUsage(t, "pool1", v1alpha2.ExternalIPPoolUsage{Total: 2, Used: 1})
+
+	// The IPs are released when the ExternalIPPool is deleted.
+	err = c.crdClient.CoreV1alpha2().ExternalIPPools().Delete(context.TODO(), "pool1", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	c.waitForIngressIPs(t, "svc3")
+}
+
+func TestRequeuePendingServices(t *testing.T) {
+	pool := newExternalIPPool("pool1", v1alpha2.IPRange{CIDR: "10.10.10.1/32"})
+	client := fake.NewSimpleClientset()
+	crdClient := fakeversioned.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	externalIPPoolInformer := crdInformerFactory.Core().V1alpha2().ExternalIPPools()
+	c := NewServiceExternalIPController(client, crdClient, informerFactory.Core().V1().Services(), externalIPPoolInformer)
+	require.NoError(t, externalIPPoolInformer.Informer().GetIndexer().Add(pool))
+
+	allocatedIP, _, err := c.reconcileAllocation("default/svc1", newService("svc1", "pool1", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.10.1", allocatedIP)
+	// The pool is exhausted, svc2 waits for an IP.
+	allocatedIP, _, err = c.reconcileAllocation("default/svc2", newService("svc2", "pool1", ""))
+	require.NoError(t, err)
+	assert.Empty(t, allocatedIP)
+	assert.Equal(t, map[string]sets.String{"pool1": sets.NewString("default/svc2")}, c.pendingServices)
+
+	// svc2 is requeued when the IP of svc1 is released.
+	_, releasedIP, err := c.reconcileAllocation("default/svc1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.10.1", releasedIP)
+	require.Equal(t, 1, c.queue.Len())
+	key, _ := c.queue.Get()
+	assert.Equal(t, "default/svc2", key)
+	allocatedIP, _, err = c.reconcileAllocation("default/svc2", newService("svc2", "pool1", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.10.10.1", allocatedIP)
+	assert.Empty(t, c.pendingServices)
+}
+
+func TestRestoreAllocations(t *testing.T) {
+	pool := newExternalIPPool("pool1", v1alpha2.IPRange{CIDR: "10.10.10.0/30"})
+	c := newFakeController(t, []runtime.Object{
+		newService("svc1", "pool1", "", "10.10.10.2"),
+		newService("svc2", "pool1", ""),
+	}, []runtime.Object{pool})
+
+	// The IP allocated to svc1 before the restart must not be allocated to
+	// svc2.
+	c.waitForIngressIPs(t, "svc2", "10.10.10.1")
+	c.waitForIngressIPs(t, "svc1", "10.10.10.2")
+	c.waitForUsage(t, "pool1", v1alpha2.ExternalIPPoolUsage{Total: 2, Used: 2})
+}
+
+func TestParseIPRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		ipRange       v1alpha2.IPRange
+		expectedStart string
+		expectedEnd   string
+		expectedErr   bool
+	}{
+		{
+			name:          "IPv4 CIDR",
+			ipRange:       v1alpha2.IPRange{CIDR: "10.10.10.0/24"},
+			expectedStart: "10.10.10.1",
+			expectedEnd:   "10.10.10.254",
+		},
+		{
+			name:          "IPv4 /32 CIDR",
+			ipRange:       v1alpha2.IPRange{CIDR: "10.10.10.1/32"},
+			expectedStart: "10.10.10.1",
+			expectedEnd:   "10.10.10.1",
+		},
+		{
+			name:          "IPv6 CIDR",
+			ipRange:       v1alpha2.IPRange{CIDR: "2001:db8::/120"},
+			expectedStart: "2001:db8::",
+			expectedEnd:   "2001:db8::ff",
+		},
+		{
+			name:          "IPv4 range",
+			ipRange:       v1alpha2.IPRange{Start: "10.10.10.10", End: "10.10.10.20"},
+			expectedStart: "10.10.10.10",
+			expectedEnd:   "10.10.10.20",
+		},
+		{
+			name:        "reversed range",
+			ipRange:     v1alpha2.IPRange{Start: "10.10.10.20", End: "10.10.10.10"},
+			expectedErr: true,
+		},
+		{
+			name:        "mixed families",
+			ipRange:     v1alpha2.IPRange{Start: "10.10.10.10", End: "2001:db8::1"},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseIPRange(&tt.ipRange)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, net.ParseIP(tt.expectedStart).Equal(start))
+			assert.True(t, net.ParseIP(tt.expectedEnd).Equal(end))
+		})
+	}
+}
+
+func TestPoolSize(t *testing.T) {
+	pool := newExternalIPPool("pool1",
+		v1alpha2.IPRange{CIDR: "10.10.10.0/24"},
+		v1alpha2.IPRange{Start: "10.10.20.1", End: "10.10.20.10"})
+	assert.Equal(t, 264, poolSize(pool))
+	pool = newExternalIPPool("pool2", v1alpha2.IPRange{CIDR: "2001:db8::/64"})
+	assert.Equal(t, 1<<31-1, poolSize(pool))
+}
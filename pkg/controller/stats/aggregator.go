@@ -368,4 +368,5 @@ func addUp(stats *statsv1alpha1.TrafficStats, inc *statsv1alpha1.TrafficStats) {
 	stats.Sessions += inc.Sessions
 	stats.Packets += inc.Packets
 	stats.Bytes += inc.Bytes
+	stats.RateLimitedPackets += inc.RateLimitedPackets
 }
@@ -20,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/vmware-tanzu/antrea/pkg/apis/controlplane"
+	secv1alpha1 "github.com/vmware-tanzu/antrea/pkg/apis/security/v1alpha1"
 )
 
 // SpanMeta describes the span information of an object.
@@ -58,6 +59,9 @@ type GroupSelector struct {
 	// If Namespace and NamespaceSelector both are unset, it selects the ExternalEntities in all the Namespaces.
 	// TODO: Add validation in API to not allow externalEntitySelector and podSelector in the same group.
 	ExternalEntitySelector labels.Selector
+	// This is a label selector which selects Nodes. If this field is set, all other fields except
+	// NormalizedName must be unset, and the group selects the addresses of the matching Nodes.
+	NodeSelector labels.Selector
 }
 
 // AppliedToGroup describes a set of GroupMembers to apply Network Policies to.
@@ -112,4 +116,7 @@ type NetworkPolicy struct {
 	// TierPriority represents the priority of the Tier associated with this Network
 	// Policy.
 	TierPriority *int32
+	// ScheduledRules is the activation state of the rules of the original Network
+	// Policy which have a schedule. Inactive rules are not present in Rules.
+	ScheduledRules []secv1alpha1.ScheduledRuleStatus
 }
@@ -52,6 +52,36 @@ const (
 	// alpha: v0.10
 	// Enable collecting and exposing NetworkPolicy statistics.
 	NetworkPolicyStats featuregate.Feature = "NetworkPolicyStats"
+
+	// alpha: v0.12
+	// Enable Antrea IPAM which allocates Pod IPs from IPPools selected by
+	// Namespace annotation.
+	AntreaIPAM featuregate.Feature = "AntreaIPAM"
+
+	// alpha: v0.12
+	// Enable the BGP speaker which advertises the PodCIDRs of the Node and the
+	// Service IPs to the BGP peers configured by BGPPolicies.
+	BGPPolicy featuregate.Feature = "BGPPolicy"
+
+	// alpha: v0.12
+	// Enable certificate-based authentication of the IPsec tunnels, with the
+	// certificates of the Nodes signed by antrea-controller.
+	IPsecCertAuth featuregate.Feature = "IPsecCertAuth"
+
+	// alpha: v0.12
+	// Enable allocating the ingress IPs of LoadBalancer Services from
+	// ExternalIPPools, and announcing them from the selected Nodes.
+	ServiceExternalIP featuregate.Feature = "ServiceExternalIP"
+
+	// alpha: v0.12
+	// Enable mapping the container ports of the Pods selected by annotated
+	// Services to unique ports of their Node, for external load balancers.
+	NodePortLocal featuregate.Feature = "NodePortLocal"
+
+	// alpha: v0.12
+	// Enable AntreaProxy to retrieve the Endpoints of the Services from the
+	// EndpointSlice API instead of the Endpoints API.
+	EndpointSlice featuregate.Feature = "EndpointSlice"
 )
 
 var (
@@ -71,6 +101,12 @@ var (
 		Traceflow:          {Default: true, PreRelease: featuregate.Beta},
 		FlowExporter:       {Default: false, PreRelease: featuregate.Alpha},
 		NetworkPolicyStats: {Default: false, PreRelease: featuregate.Alpha},
+		AntreaIPAM:         {Default: false, PreRelease: featuregate.Alpha},
+		BGPPolicy:          {Default: false, PreRelease: featuregate.Alpha},
+		IPsecCertAuth:      {Default: false, PreRelease: featuregate.Alpha},
+		ServiceExternalIP:  {Default: false, PreRelease: featuregate.Alpha},
+		NodePortLocal:      {Default: false, PreRelease: featuregate.Alpha},
+		EndpointSlice:      {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	// UnsupportedFeaturesOnWindows records the features not supported on
@@ -83,7 +119,13 @@ var (
 	// In future, if a feature is supported on both Linux and Windows, but
 	// can have different FeatureSpecs between Linux and Windows, we should
 	// still define a separate defaultAntreaFeatureGates map for Windows.
-	unsupportedFeaturesOnWindows = map[featuregate.Feature]struct{}{}
+	unsupportedFeaturesOnWindows = map[featuregate.Feature]struct{}{
+		AntreaIPAM:        {},
+		BGPPolicy:         {},
+		IPsecCertAuth:     {},
+		ServiceExternalIP: {},
+		NodePortLocal:     {},
+	}
 )
 
 func init() {
@@ -15,6 +15,10 @@
 package k8s
 
 import (
+	"fmt"
+
+	discovery "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -25,8 +29,9 @@ import (
 	crdclientset "github.com/vmware-tanzu/antrea/pkg/client/clientset/versioned"
 )
 
-// CreateClients creates kube clients from the given config.
-func CreateClients(config componentbaseconfig.ClientConnectionConfiguration) (clientset.Interface, aggregatorclientset.Interface, crdclientset.Interface, error) {
+// CreateClients creates kube clients from the given config. If kubeAPIServerOverride is not empty, it overrides the
+// address of the apiserver provided by the kubeconfig file or the in-cluster config.
+func CreateClients(config componentbaseconfig.ClientConnectionConfiguration, kubeAPIServerOverride string) (clientset.Interface, aggregatorclientset.Interface, crdclientset.Interface, error) {
 	var kubeConfig *rest.Config
 	var err error
 
@@ -41,6 +46,9 @@ func CreateClients(config componentbaseconfig.ClientConnectionConfiguration) (cl
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if len(kubeAPIServerOverride) != 0 {
+		kubeConfig.Host = kubeAPIServerOverride
+	}
 
 	kubeConfig.AcceptContentTypes = config.AcceptContentTypes
 	kubeConfig.ContentType = config.ContentType
@@ -63,3 +71,22 @@ func CreateClients(config componentbaseconfig.ClientConnectionConfiguration) (cl
 	}
 	return client, aggregatorClient, crdClient, nil
 }
+
+// EndpointSliceAPIAvailable returns whether the discovery.k8s.io/v1beta1
+// EndpointSlice API is served by the K8s apiserver.
+func EndpointSliceAPIAvailable(k8sClient clientset.Interface) (bool, error) {
+	resources, err := k8sClient.Discovery().ServerResourcesForGroupVersion(discovery.SchemeGroupVersion.String())
+	if err != nil {
+		// The group version doesn't exist.
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error getting server resources for GroupVersion %s: %v", discovery.SchemeGroupVersion.String(), err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == "EndpointSlice" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -24,14 +24,19 @@ import (
 type Protocol string
 type TableIDType uint8
 type GroupIDType uint32
+type MeterIDType uint32
 
 type MissActionType uint32
 type Range [2]uint32
 type OFOperation int
+type MeterFlag uint16
+type MeterBandType uint16
 
 const (
 	LastTableID TableIDType = 0xff
 	TableIDAll              = LastTableID
+	// MeterIDAll refers to all the Meters on the OFSwitch. It can only be used to delete Meters.
+	MeterIDAll MeterIDType = 0xffffffff
 )
 
 const (
@@ -77,12 +82,30 @@ const (
 	DeleteMessage
 )
 
+const (
+	// MeterKbps specifies that the rates of the Meter bands are in kilobits per second.
+	MeterKbps MeterFlag = 1 << 0
+	// MeterPktps specifies that the rates of the Meter bands are in packets per second.
+	MeterPktps MeterFlag = 1 << 1
+	// MeterBurst specifies that the burst sizes of the Meter bands are applied.
+	MeterBurst MeterFlag = 1 << 2
+	// MeterStats specifies that the statistics of the Meter are collected.
+	MeterStats MeterFlag = 1 << 3
+)
+
+const (
+	// MeterBandDrop drops the packets exceeding the rate of the band.
+	MeterBandDrop MeterBandType = 1
+)
+
 // Bridge defines operations on an openflow bridge.
 type Bridge interface {
 	CreateTable(id, next TableIDType, missAction MissActionType) Table
 	DeleteTable(id TableIDType) bool
 	CreateGroup(id GroupIDType) Group
 	DeleteGroup(id GroupIDType) bool
+	// CreateMeter creates a Meter with the given ID. flags specifies the unit of the rates of the Meter bands.
+	CreateMeter(id MeterIDType, flags MeterFlag) Meter
 	DumpTableStatus() []TableStatus
 	// DumpFlows queries the Openflow entries from OFSwitch. The filter of the query is Openflow cookieID; the result is
 	// a map from flow cookieID to FlowStates.
@@ -107,6 +130,10 @@ type Bridge interface {
 	// Bridge receives a PacketIn message with the specified reason, it sends the message to the consumer using the
 	// provided channel.
 	SubscribePacketIn(reason uint8, ch chan *ofctrl.PacketIn) error
+	// SubscribePacketInByCookie registers a consumer to listen to the PacketIn messages sent by the flows whose cookie
+	// matches the provided cookie and mask, whatever their reason. The messages are not sent to the consumer of their
+	// reason.
+	SubscribePacketInByCookie(cookie, mask uint64, ch chan *ofctrl.PacketIn) error
 	// AddTLVMap adds a TLV mapping with OVS field tun_metadataX. The value loaded in tun_metadataX is transported by
 	// Geneve header with the specified <optClass, optType, optLength>. The value of OptLength must be a multiple of 4.
 	// The value loaded into field tun_metadataX must fit within optLength bytes.
@@ -115,6 +142,9 @@ type Bridge interface {
 	SendPacketOut(packetOut *ofctrl.PacketOut) error
 	// BuildPacketOut returns a new PacketOutBuilder.
 	BuildPacketOut() PacketOutBuilder
+	// ResumePacketIn sends the packet of a PacketIn message back to the OVS Bridge, which processes it from the provided
+	// table with the registers of the PacketIn message. The connection tracking fields are not restored.
+	ResumePacketIn(pktIn *ofctrl.PacketIn, tableID TableIDType) error
 }
 
 // TableStatus represents the status of a specific flow table. The status is useful for debugging.
@@ -137,6 +167,7 @@ type EntryType string
 const (
 	FlowEntry  EntryType = "FlowEntry"
 	GroupEntry EntryType = "GroupEntry"
+	MeterEntry EntryType = "MeterEntry"
 )
 
 type OFEntry interface {
@@ -196,6 +227,9 @@ type Action interface {
 	Group(id GroupIDType) FlowBuilder
 	Learn(id TableIDType, priority uint16, idleTimeout, hardTimeout uint16, cookieID uint64) LearnAction
 	GotoTable(table TableIDType) FlowBuilder
+	// Meter applies the Meter to the packets before the other actions. A Flow using a Meter can only be combined
+	// with GotoTable, and it cannot be installed in a bundle.
+	Meter(id MeterIDType) FlowBuilder
 	SendToController(reason uint8) FlowBuilder
 	Note(notes string) FlowBuilder
 }
@@ -229,6 +263,7 @@ type FlowBuilder interface {
 	MatchCTLabelRange(high, low uint64, bitRange Range) FlowBuilder
 	MatchConjID(value uint32) FlowBuilder
 	MatchDstPort(port uint16, portMask *uint16) FlowBuilder
+	MatchSrcPort(port uint16, portMask *uint16) FlowBuilder
 	MatchICMPv6Type(icmp6Type byte) FlowBuilder
 	MatchICMPv6Code(icmp6Code byte) FlowBuilder
 	MatchTunMetadata(index int, data uint32) FlowBuilder
@@ -236,7 +271,7 @@ type FlowBuilder interface {
 	MatchCTSrcIP(ip net.IP) FlowBuilder
 	// MatchCTSrcIPNet matches the source IPv4 address of the connection tracker original direction tuple with IP masking.
 	MatchCTSrcIPNet(ipnet net.IPNet) FlowBuilder
-	// MatchCTDstIP matches the destination IPv4 address of the connection tracker original direction tuple.
+	// MatchCTDstIP matches the destination IPv4 or IPv6 address of the connection tracker original direction tuple.
 	MatchCTDstIP(ip net.IP) FlowBuilder
 	// MatchCTDstIP matches the destination IPv4 address of the connection tracker original direction tuple with IP masking.
 	MatchCTDstIPNet(ipNet net.IPNet) FlowBuilder
@@ -279,6 +314,20 @@ type Group interface {
 	Bucket() BucketBuilder
 }
 
+type Meter interface {
+	OFEntry
+	GetID() MeterIDType
+	ResetMeterBands() Meter
+	MeterBand() MeterBandBuilder
+}
+
+type MeterBandBuilder interface {
+	MeterType(meterType MeterBandType) MeterBandBuilder
+	Rate(rate uint32) MeterBandBuilder
+	Burst(burst uint32) MeterBandBuilder
+	Done() Meter
+}
+
 type BucketBuilder interface {
 	Weight(val uint16) BucketBuilder
 	LoadReg(regID int, data uint32) BucketBuilder
@@ -535,5 +535,12 @@ func getFieldRange(name string) (*openflow13.MatchField, Range, error) {
 // GotoTable is an action to jump to the specified table.
 func (a *ofFlowAction) GotoTable(table TableIDType) FlowBuilder {
 	a.builder.ofFlow.Goto(uint8(table))
+	a.builder.gotoTableID = &table
+	return a.builder
+}
+
+// Meter is an action to apply the Meter to the packets.
+func (a *ofFlowAction) Meter(id MeterIDType) FlowBuilder {
+	a.builder.meterID = id
 	return a.builder
 }
@@ -145,6 +145,9 @@ type OFBridge struct {
 	connected chan bool
 	// pktConsumers is a map from PacketIn reason to the channel that is used to publish the PacketIn message.
 	pktConsumers sync.Map
+	// pktCookieConsumers is a map from the cookie and mask of the flows sending PacketIn messages to the channel that
+	// is used to publish the PacketIn messages, which takes precedence over pktConsumers.
+	pktCookieConsumers sync.Map
 }
 
 func (b *OFBridge) CreateGroup(id GroupIDType) Group {
@@ -167,6 +170,10 @@ func (b *OFBridge) DeleteGroup(id GroupIDType) bool {
 	return true
 }
 
+func (b *OFBridge) CreateMeter(id MeterIDType, flags MeterFlag) Meter {
+	return &ofMeter{bridge: b, id: id, flags: flags}
+}
+
 func (b *OFBridge) CreateTable(id, next TableIDType, missAction MissActionType) Table {
 	t := newOFTable(id, next, missAction)
 
@@ -207,8 +214,17 @@ func (b *OFBridge) DumpTableStatus() []TableStatus {
 // PacketRcvd is a callback when a packetIn is received on ofctrl.OFSwitch.
 func (b *OFBridge) PacketRcvd(sw *ofctrl.OFSwitch, packet *ofctrl.PacketIn) {
 	klog.Infof("Received packet: %+v", packet)
-	reason := packet.Reason
-	ch, found := b.pktConsumers.Load(reason)
+	var ch interface{}
+	found := false
+	b.pktCookieConsumers.Range(func(key, value interface{}) bool {
+		if k := key.(cookieKey); packet.Cookie&k.mask == k.cookie {
+			ch, found = value, true
+		}
+		return !found
+	})
+	if !found {
+		ch, found = b.pktConsumers.Load(packet.Reason)
+	}
 	if found {
 		pktCh, _ := ch.(chan *ofctrl.PacketIn)
 		pktCh <- packet
@@ -528,6 +544,21 @@ func (b *OFBridge) SubscribePacketIn(reason uint8, ch chan *ofctrl.PacketIn) err
 	return nil
 }
 
+// cookieKey is the cookie and mask of the flows whose PacketIn messages are sent to a consumer.
+type cookieKey struct {
+	cookie, mask uint64
+}
+
+func (b *OFBridge) SubscribePacketInByCookie(cookie, mask uint64, ch chan *ofctrl.PacketIn) error {
+	key := cookieKey{cookie: cookie & mask, mask: mask}
+	_, exist := b.pktCookieConsumers.Load(key)
+	if exist {
+		return fmt.Errorf("packetIn cookie %#x/%#x already exists", key.cookie, key.mask)
+	}
+	b.pktCookieConsumers.Store(key, ch)
+	return nil
+}
+
 func (b *OFBridge) AddTLVMap(optClass uint16, optType uint8, optLength uint8, tunMetadataIndex uint16) error {
 	if err := b.ofSwitch.AddTunnelTLVMap(optClass, optType, optLength, tunMetadataIndex); err != nil {
 		return err
@@ -539,6 +570,10 @@ func (b *OFBridge) SendPacketOut(packetOut *ofctrl.PacketOut) error {
 	return b.ofSwitch.Send(packetOut.GetMessage())
 }
 
+func (b *OFBridge) ResumePacketIn(pktIn *ofctrl.PacketIn, tableID TableIDType) error {
+	return b.ofSwitch.Send(resumePacketOut(pktIn, tableID))
+}
+
 func (b *OFBridge) BuildPacketOut() PacketOutBuilder {
 	return &ofPacketOutBuilder{
 		pktOut: new(ofctrl.PacketOut),
@@ -558,11 +593,12 @@ func (b *OFBridge) RetryInterval() time.Duration {
 
 func NewOFBridge(br string, mgmtAddr string) Bridge {
 	s := &OFBridge{
-		bridgeName:    br,
-		mgmtAddr:      mgmtAddr,
-		tableCache:    make(map[TableIDType]*ofTable),
-		retryInterval: 1 * time.Second,
-		pktConsumers:  sync.Map{},
+		bridgeName:         br,
+		mgmtAddr:           mgmtAddr,
+		tableCache:         make(map[TableIDType]*ofTable),
+		retryInterval:      1 * time.Second,
+		pktConsumers:       sync.Map{},
+		pktCookieConsumers: sync.Map{},
 	}
 	s.controller = ofctrl.NewController(s)
 	return s
@@ -0,0 +1,44 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketRcvdByCookie(t *testing.T) {
+	b := NewOFBridge("br-int", "").(*OFBridge)
+	reasonCh := make(chan *ofctrl.PacketIn, 2)
+	cookieCh := make(chan *ofctrl.PacketIn, 2)
+	require.NoError(t, b.SubscribePacketIn(0, reasonCh))
+	require.NoError(t, b.SubscribePacketInByCookie(0x0000_0600_0000_0000, 0x0000_ff00_0000_0000, cookieCh))
+	assert.Error(t, b.SubscribePacketInByCookie(0x0001_0600_0000_0000, 0x0000_ff00_0000_0000, cookieCh))
+
+	cookiePktIn := &ofctrl.PacketIn{Reason: 0, Cookie: 0x0001_0600_0000_0003}
+	reasonPktIn := &ofctrl.PacketIn{Reason: 0, Cookie: 0x0001_0500_0000_0003}
+	b.PacketRcvd(nil, cookiePktIn)
+	b.PacketRcvd(nil, reasonPktIn)
+	// The PacketIn messages of another reason without a matching cookie are ignored.
+	b.PacketRcvd(nil, &ofctrl.PacketIn{Reason: 1})
+
+	require.Len(t, cookieCh, 1)
+	assert.Equal(t, cookiePktIn, <-cookieCh)
+	require.Len(t, reasonCh, 1)
+	assert.Equal(t, reasonPktIn, <-reasonCh)
+}
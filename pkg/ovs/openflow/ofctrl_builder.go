@@ -438,6 +438,19 @@ func (b *ofFlowBuilder) MatchDstPort(port uint16, portMask *uint16) FlowBuilder
 	return b
 }
 
+// MatchSrcPort adds match condition for matching source port in transport layer. OVS will match the port exactly
+// if portMask is nil.
+func (b *ofFlowBuilder) MatchSrcPort(port uint16, portMask *uint16) FlowBuilder {
+	b.Match.SrcPort = port
+	b.Match.SrcPortMask = portMask
+	matchStr := fmt.Sprintf("tp_src=0x%x", port)
+	if portMask != nil {
+		matchStr = fmt.Sprintf("%s/0x%x", matchStr, portMask)
+	}
+	b.matchers = append(b.matchers, matchStr)
+	return b
+}
+
 // MatchCTSrcIP matches the source IPv4 address of the connection tracker original direction tuple. This match requires
 // a match to valid connection tracking state as a prerequisite, and valid connection tracking state matches include
 // "+new", "+est", "+rel" and "+trk-inv".
@@ -459,8 +472,13 @@ func (b *ofFlowBuilder) MatchCTSrcIPNet(ipNet net.IPNet) FlowBuilder {
 // requires a match to valid connection tracking state as a prerequisite, and valid connection tracking state matches
 // include "+new", "+est", "+rel" and "+trk-inv".
 func (b *ofFlowBuilder) MatchCTDstIP(ip net.IP) FlowBuilder {
-	b.Match.CtIpDa = &ip
-	b.matchers = append(b.matchers, fmt.Sprintf("ct_nw_dst=%s", ip.String()))
+	if ip.To4() != nil {
+		b.Match.CtIpDa = &ip
+		b.matchers = append(b.matchers, fmt.Sprintf("ct_nw_dst=%s", ip.String()))
+	} else {
+		b.Match.CtIpv6Da = &ip
+		b.matchers = append(b.matchers, fmt.Sprintf("ct_ipv6_dst=%s", ip.String()))
+	}
 	return b
 }
 
@@ -495,11 +513,11 @@ func (b *ofFlowBuilder) MatchCTDstPort(port uint16) FlowBuilder {
 // "+new", "+est", "+rel" and "+trk-inv".
 func (b *ofFlowBuilder) MatchCTProtocol(proto Protocol) FlowBuilder {
 	switch proto {
-	case ProtocolTCP:
+	case ProtocolTCP, ProtocolTCPv6:
 		b.Match.CtIpProto = 6
-	case ProtocolUDP:
+	case ProtocolUDP, ProtocolUDPv6:
 		b.Match.CtIpProto = 17
-	case ProtocolSCTP:
+	case ProtocolSCTP, ProtocolSCTPv6:
 		b.Match.CtIpProto = 132
 	case ProtocolICMP:
 		b.Match.CtIpProto = 1
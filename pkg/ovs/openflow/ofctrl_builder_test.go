@@ -16,6 +16,7 @@ package openflow
 
 import (
 	"fmt"
+	"net"
 	"testing"
 
 	"github.com/contiv/ofnet/ofctrl"
@@ -42,3 +43,24 @@ func TestMatchCTLabelRange(t *testing.T) {
 		require.Equal(t, tc.expectedLowMask, match.CtLabelLoMask, fmt.Sprintf("Expected low mask is equal, test case: %+v", tc))
 	}
 }
+
+func TestMatchCTDstIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip              string
+		expectedMatcher string
+	}{
+		{ip: "10.96.0.10", expectedMatcher: "ct_nw_dst=10.96.0.10"},
+		{ip: "fd00:10:96::a", expectedMatcher: "ct_ipv6_dst=fd00:10:96::a"},
+	} {
+		b := &ofFlowBuilder{ofFlow: ofFlow{Flow: &ofctrl.Flow{}}}
+		b.MatchCTDstIP(net.ParseIP(tc.ip))
+		require.Equal(t, []string{tc.expectedMatcher}, b.matchers)
+		if net.ParseIP(tc.ip).To4() != nil {
+			require.NotNil(t, b.Match.CtIpDa)
+			require.Nil(t, b.Match.CtIpv6Da)
+		} else {
+			require.Nil(t, b.Match.CtIpDa)
+			require.NotNil(t, b.Match.CtIpv6Da)
+		}
+	}
+}
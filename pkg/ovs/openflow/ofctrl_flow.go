@@ -33,6 +33,10 @@ type ofFlow struct {
 	ctStates *openflow13.CTStates
 	// isDropFlow is true if this flow actions contain "drop"
 	isDropFlow bool
+	// meterID is the ID of the Meter applied by the flow. It is 0 if the flow doesn't use a Meter.
+	meterID MeterIDType
+	// gotoTableID is the table which the flow goes to after applying the Meter.
+	gotoTableID *TableIDType
 }
 
 // Reset updates the ofFlow.Flow.Table field with ofFlow.table.Table.
@@ -44,7 +48,7 @@ func (f *ofFlow) Reset() {
 }
 
 func (f *ofFlow) Add() error {
-	err := f.Flow.Send(openflow13.FC_ADD)
+	err := f.send(openflow13.FC_ADD)
 	if err != nil {
 		return err
 	}
@@ -53,7 +57,7 @@ func (f *ofFlow) Add() error {
 }
 
 func (f *ofFlow) Modify() error {
-	err := f.Flow.Send(openflow13.FC_MODIFY_STRICT)
+	err := f.send(openflow13.FC_MODIFY_STRICT)
 	if err != nil {
 		return err
 	}
@@ -71,6 +75,33 @@ func (f *ofFlow) Delete() error {
 	return nil
 }
 
+// send sends the FlowMod message of the flow to the OFSwitch. The ofctrl.Flow doesn't support the meter instruction,
+// so the FlowMod message of a flow using a Meter is built from the matches of the ofctrl.Flow.
+func (f *ofFlow) send(command int) error {
+	if f.meterID == 0 {
+		return f.Flow.Send(command)
+	}
+	flowMod, err := f.meterFlowMod(command)
+	if err != nil {
+		return err
+	}
+	return f.Flow.Table.Switch.Send(flowMod)
+}
+
+func (f *ofFlow) meterFlowMod(command int) (*openflow13.FlowMod, error) {
+	// A FlowMod message for deletion has only the matches of the flow.
+	flowMod, err := f.Flow.GenerateFlowModMessage(openflow13.FC_DELETE_STRICT)
+	if err != nil {
+		return nil, err
+	}
+	flowMod.Command = uint8(command)
+	flowMod.AddInstruction(&instrMeter{meterID: f.meterID})
+	if f.gotoTableID != nil {
+		flowMod.AddInstruction(openflow13.NewInstrGotoTable(uint8(*f.gotoTableID)))
+	}
+	return flowMod, nil
+}
+
 func (f *ofFlow) Type() EntryType {
 	return FlowEntry
 }
@@ -109,6 +140,9 @@ func (f *ofFlow) GetBundleMessage(entryOper OFOperation) (ofctrl.OpenFlowModMess
 	case DeleteMessage:
 		operation = openflow13.FC_DELETE_STRICT
 	}
+	if f.meterID != 0 && entryOper != DeleteMessage {
+		return nil, fmt.Errorf("flow using meter %d cannot be added in a bundle", f.meterID)
+	}
 	message, err := f.Flow.GetBundleMessage(operation)
 	if err != nil {
 		return nil, err
@@ -142,6 +176,8 @@ func (f *ofFlow) CopyToBuilder(priority uint16, copyActions bool) FlowBuilder {
 	}
 	if copyActions {
 		newFlow.isDropFlow = f.isDropFlow
+		newFlow.meterID = f.meterID
+		newFlow.gotoTableID = f.gotoTableID
 	}
 	return &ofFlowBuilder{newFlow}
 }
@@ -0,0 +1,214 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/contiv/libOpenflow/common"
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+)
+
+// The commands of the MeterMod message.
+const (
+	meterCommandAdd    uint16 = 0
+	meterCommandModify uint16 = 1
+	meterCommandDelete uint16 = 2
+)
+
+const (
+	meterModLen    = 16
+	meterBandLen   = 16
+	instrMeterLen  = 8
+	instrTypeMeter = openflow13.InstrType_METER
+)
+
+// meterBand is the OpenFlow 1.3 ofp_meter_band_header of a Meter band.
+type meterBand struct {
+	bandType  MeterBandType
+	rate      uint32
+	burstSize uint32
+}
+
+// meterMod is the OpenFlow 1.3 METER_MOD message, which is not provided by libOpenflow.
+type meterMod struct {
+	common.Header
+	command uint16
+	flags   MeterFlag
+	meterID MeterIDType
+	bands   []meterBand
+}
+
+func (m *meterMod) Len() uint16 {
+	return uint16(meterModLen + meterBandLen*len(m.bands))
+}
+
+func (m *meterMod) MarshalBinary() ([]byte, error) {
+	m.Header.Length = m.Len()
+	data, err := m.Header.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	body := make([]byte, m.Len()-m.Header.Len())
+	binary.BigEndian.PutUint16(body[0:], m.command)
+	binary.BigEndian.PutUint16(body[2:], uint16(m.flags))
+	binary.BigEndian.PutUint32(body[4:], uint32(m.meterID))
+	for i, band := range m.bands {
+		b := body[8+i*meterBandLen:]
+		binary.BigEndian.PutUint16(b[0:], uint16(band.bandType))
+		binary.BigEndian.PutUint16(b[2:], meterBandLen)
+		binary.BigEndian.PutUint32(b[4:], band.rate)
+		binary.BigEndian.PutUint32(b[8:], band.burstSize)
+	}
+	return append(data, body...), nil
+}
+
+func (m *meterMod) UnmarshalBinary(data []byte) error {
+	return errors.New("unmarshalling MeterMod messages is not supported")
+}
+
+// instrMeter is the OpenFlow 1.3 meter instruction. The InstrMeter of libOpenflow doesn't marshal the Meter ID.
+type instrMeter struct {
+	meterID MeterIDType
+}
+
+func (i *instrMeter) Len() uint16 {
+	return instrMeterLen
+}
+
+func (i *instrMeter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, instrMeterLen)
+	binary.BigEndian.PutUint16(data[0:], instrTypeMeter)
+	binary.BigEndian.PutUint16(data[2:], instrMeterLen)
+	binary.BigEndian.PutUint32(data[4:], uint32(i.meterID))
+	return data, nil
+}
+
+func (i *instrMeter) UnmarshalBinary(data []byte) error {
+	if len(data) != instrMeterLen {
+		return errors.New("wrong size to unmarshal a meter instruction")
+	}
+	i.meterID = MeterIDType(binary.BigEndian.Uint32(data[4:]))
+	return nil
+}
+
+func (i *instrMeter) AddAction(act openflow13.Action, prepend bool) error {
+	return errors.New("actions are not supported in meter instructions")
+}
+
+// ofMeter implements openflow.Meter. OVS doesn't support MeterMod messages in bundles, so the Meter is sent to the
+// OFSwitch directly.
+type ofMeter struct {
+	bridge *OFBridge
+	id     MeterIDType
+	flags  MeterFlag
+	bands  []meterBand
+}
+
+func (m *ofMeter) message(command uint16) *meterMod {
+	msg := &meterMod{
+		Header:  openflow13.NewOfp13Header(),
+		command: command,
+		flags:   m.flags,
+		meterID: m.id,
+	}
+	msg.Header.Type = openflow13.Type_MeterMod
+	if command != meterCommandDelete {
+		msg.bands = m.bands
+	}
+	return msg
+}
+
+func (m *ofMeter) send(command uint16) error {
+	if m.bridge.ofSwitch == nil {
+		return fmt.Errorf("OFSwitch is not connected")
+	}
+	return m.bridge.ofSwitch.Send(m.message(command))
+}
+
+// Reset does nothing, as the Meter always uses the current OFSwitch of the bridge.
+func (m *ofMeter) Reset() {
+}
+
+func (m *ofMeter) Add() error {
+	return m.send(meterCommandAdd)
+}
+
+func (m *ofMeter) Modify() error {
+	return m.send(meterCommandModify)
+}
+
+func (m *ofMeter) Delete() error {
+	return m.send(meterCommandDelete)
+}
+
+func (m *ofMeter) Type() EntryType {
+	return MeterEntry
+}
+
+func (m *ofMeter) KeyString() string {
+	return fmt.Sprintf("meter_id:%d", m.id)
+}
+
+func (m *ofMeter) GetBundleMessage(entryOper OFOperation) (ofctrl.OpenFlowModMessage, error) {
+	return nil, fmt.Errorf("meter %d cannot be added in a bundle", m.id)
+}
+
+func (m *ofMeter) GetID() MeterIDType {
+	return m.id
+}
+
+func (m *ofMeter) ResetMeterBands() Meter {
+	m.bands = nil
+	return m
+}
+
+func (m *ofMeter) MeterBand() MeterBandBuilder {
+	return &meterBandBuilder{
+		meter: m,
+		band:  meterBand{bandType: MeterBandDrop},
+	}
+}
+
+type meterBandBuilder struct {
+	meter *ofMeter
+	band  meterBand
+}
+
+// MeterType sets the type of the Meter band.
+func (b *meterBandBuilder) MeterType(meterType MeterBandType) MeterBandBuilder {
+	b.band.bandType = meterType
+	return b
+}
+
+// Rate sets the rate of the Meter band, in the unit specified by the flags of the Meter.
+func (b *meterBandBuilder) Rate(rate uint32) MeterBandBuilder {
+	b.band.rate = rate
+	return b
+}
+
+// Burst sets the burst size of the Meter band. It is used only if the Meter has the MeterBurst flag.
+func (b *meterBandBuilder) Burst(burst uint32) MeterBandBuilder {
+	b.band.burstSize = burst
+	return b
+}
+
+func (b *meterBandBuilder) Done() Meter {
+	b.meter.bands = append(b.meter.bands, b.band)
+	return b.meter
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterModMessage(t *testing.T) {
+	meter := (&OFBridge{}).CreateMeter(10, MeterPktps|MeterStats).
+		MeterBand().Rate(100).Done().(*ofMeter)
+
+	msg := meter.message(meterCommandAdd)
+	msg.Header.Xid = 1
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		// Header: version 4, type 29, length 32, xid 1.
+		0x04, 0x1d, 0x00, 0x20, 0x00, 0x00, 0x00, 0x01,
+		// Command add, flags pktps|stats, meter ID 10.
+		0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x0a,
+		// Drop band with rate 100 and no burst size.
+		0x00, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}, data)
+
+	// The bands are not sent when deleting the Meter.
+	msg = meter.message(meterCommandDelete)
+	msg.Header.Xid = 1
+	data, err = msg.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x04, 0x1d, 0x00, 0x10, 0x00, 0x00, 0x00, 0x01,
+		0x00, 0x02, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x0a,
+	}, data)
+}
+
+func TestMeterFlow(t *testing.T) {
+	table := &ofTable{
+		id:    5,
+		next:  6,
+		Table: &ofctrl.Table{TableId: 5},
+	}
+	flow := table.BuildFlow(uint16(100)).MatchProtocol(ProtocolIP).
+		Cookie(uint64(1004)).
+		MatchCTStateNew(true).
+		Action().Meter(10).
+		Action().GotoTable(6).
+		Done().(*ofFlow)
+
+	flowMod, err := flow.meterFlowMod(openflow13.FC_ADD)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(openflow13.FC_ADD), flowMod.Command)
+	assert.Equal(t, uint64(1004), flowMod.Cookie)
+	require.Len(t, flowMod.Instructions, 2)
+	data, err := flowMod.Instructions[0].MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x06, 0x00, 0x08, 0x00, 0x00, 0x00, 0x0a}, data)
+	assert.Equal(t, uint8(6), flowMod.Instructions[1].(*openflow13.InstrGotoTable).TableId)
+
+	// The flow can only be deleted in a bundle.
+	_, err = flow.GetBundleMessage(AddMessage)
+	assert.Error(t, err)
+	_, err = flow.GetBundleMessage(DeleteMessage)
+	assert.NoError(t, err)
+
+	copiedFlow := flow.CopyToBuilder(0, true).Done().(*ofFlow)
+	assert.Equal(t, MeterIDType(10), copiedFlow.meterID)
+}
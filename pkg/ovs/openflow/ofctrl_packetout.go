@@ -19,6 +19,7 @@ import (
 	"math/rand"
 	"net"
 
+	"github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet/ofctrl"
 )
@@ -228,6 +229,30 @@ func (b *ofPacketOutBuilder) Done() *ofctrl.PacketOut {
 	return b.pktOut
 }
 
+// resumePacketOut returns the packetOut message sending the packet of the packetIn message to the provided table. The
+// message keeps the in_port of the packet, and loads the registers of the packetIn message before resubmitting the
+// packet.
+func resumePacketOut(pktIn *ofctrl.PacketIn, tableID TableIDType) *openflow13.PacketOut {
+	packetOut := openflow13.NewPacketOut()
+	packetOut.InPort = openflow13.P_CONTROLLER
+	for _, field := range pktIn.Match.Fields {
+		switch value := field.Value.(type) {
+		case *openflow13.InPortField:
+			packetOut.InPort = value.InPort
+		case *openflow13.Uint32Message:
+			if field.Class != openflow13.OXM_CLASS_NXM_1 || field.Field > openflow13.NXM_NX_REG15 {
+				continue
+			}
+			regField := openflow13.NewRegMatchField(int(field.Field-openflow13.NXM_NX_REG0), 0, nil)
+			packetOut.AddAction(openflow13.NewNXActionRegLoad(openflow13.NewNXRange(0, 31).ToOfsBits(), regField, uint64(value.Data)))
+		}
+	}
+	packetOut.AddAction(openflow13.NewNXActionResubmitTableAction(openflow13.OFPP_IN_PORT, uint8(tableID)))
+	data := pktIn.Data
+	packetOut.Data = &data
+	return packetOut
+}
+
 func (b *ofPacketOutBuilder) setICMPData() {
 	data := make([]byte, 4)
 	if b.icmpID != nil {
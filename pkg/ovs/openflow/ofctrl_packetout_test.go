@@ -0,0 +1,74 @@
+// Copyright 2020 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumePacketOut(t *testing.T) {
+	pktIn := &ofctrl.PacketIn{
+		Match: openflow13.Match{
+			Fields: []openflow13.MatchField{
+				*openflow13.NewInPortField(3),
+				*openflow13.NewRegMatchField(1, 5, nil),
+				*openflow13.NewRegMatchField(6, 10, nil),
+				*openflow13.NewCTMarkMatchField(0x20, nil),
+			},
+		},
+		Data: protocol.Ethernet{
+			HWDst:     net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x01},
+			HWSrc:     net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x02},
+			Ethertype: protocol.IPv4_MSG,
+			Data: &protocol.IPv4{
+				Version:  4,
+				IHL:      5,
+				Length:   20,
+				TTL:      64,
+				Protocol: protocol.Type_TCP,
+				NWSrc:    net.ParseIP("10.10.0.1").To4(),
+				NWDst:    net.ParseIP("10.10.0.2").To4(),
+			},
+		},
+	}
+
+	packetOut := resumePacketOut(pktIn, 105)
+	assert.Equal(t, uint32(3), packetOut.InPort)
+	require.Len(t, packetOut.Actions, 3)
+	for i, expected := range []struct {
+		reg   string
+		value uint64
+	}{{"NXM_NX_REG1", 5}, {"NXM_NX_REG6", 10}} {
+		load, ok := packetOut.Actions[i].(*openflow13.NXActionRegLoad)
+		require.True(t, ok)
+		expectedField, _ := openflow13.FindFieldHeaderByName(expected.reg, false)
+		assert.Equal(t, expectedField.Field, load.DstReg.Field)
+		assert.Equal(t, expected.value, load.Value)
+		assert.Equal(t, openflow13.NewNXRange(0, 31).ToOfsBits(), load.OfsNbits)
+	}
+	resubmit, ok := packetOut.Actions[2].(*openflow13.NXActionResubmitTable)
+	require.True(t, ok)
+	assert.Equal(t, uint8(105), resubmit.TableID)
+	assert.Equal(t, uint16(openflow13.OFPP_IN_PORT), resubmit.InPort)
+	_, err := packetOut.MarshalBinary()
+	require.NoError(t, err)
+}
@@ -14,7 +14,7 @@
 //
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/vmware-tanzu/antrea/pkg/ovs/openflow (interfaces: Bridge,Table,Flow,Action,CTAction,FlowBuilder)
+// Source: github.com/vmware-tanzu/antrea/pkg/ovs/openflow (interfaces: Bridge,Table,Flow,Action,CTAction,FlowBuilder,Meter,MeterBandBuilder)
 
 // Package testing is a generated GoMock package.
 package testing
@@ -134,6 +134,20 @@ func (mr *MockBridgeMockRecorder) CreateGroup(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockBridge)(nil).CreateGroup), arg0)
 }
 
+// CreateMeter mocks base method
+func (m *MockBridge) CreateMeter(arg0 openflow.MeterIDType, arg1 openflow.MeterFlag) openflow.Meter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMeter", arg0, arg1)
+	ret0, _ := ret[0].(openflow.Meter)
+	return ret0
+}
+
+// CreateMeter indicates an expected call of CreateMeter
+func (mr *MockBridgeMockRecorder) CreateMeter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMeter", reflect.TypeOf((*MockBridge)(nil).CreateMeter), arg0, arg1)
+}
+
 // CreateTable mocks base method
 func (m *MockBridge) CreateTable(arg0, arg1 openflow.TableIDType, arg2 openflow.MissActionType) openflow.Table {
 	m.ctrl.T.Helper()
@@ -247,6 +261,20 @@ func (mr *MockBridgeMockRecorder) IsConnected() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConnected", reflect.TypeOf((*MockBridge)(nil).IsConnected))
 }
 
+// ResumePacketIn mocks base method
+func (m *MockBridge) ResumePacketIn(arg0 *ofctrl.PacketIn, arg1 openflow.TableIDType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumePacketIn", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumePacketIn indicates an expected call of ResumePacketIn
+func (mr *MockBridgeMockRecorder) ResumePacketIn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumePacketIn", reflect.TypeOf((*MockBridge)(nil).ResumePacketIn), arg0, arg1)
+}
+
 // SendPacketOut mocks base method
 func (m *MockBridge) SendPacketOut(arg0 *ofctrl.PacketOut) error {
 	m.ctrl.T.Helper()
@@ -275,6 +303,20 @@ func (mr *MockBridgeMockRecorder) SubscribePacketIn(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePacketIn", reflect.TypeOf((*MockBridge)(nil).SubscribePacketIn), arg0, arg1)
 }
 
+// SubscribePacketInByCookie mocks base method
+func (m *MockBridge) SubscribePacketInByCookie(arg0, arg1 uint64, arg2 chan *ofctrl.PacketIn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribePacketInByCookie", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribePacketInByCookie indicates an expected call of SubscribePacketInByCookie
+func (mr *MockBridgeMockRecorder) SubscribePacketInByCookie(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePacketInByCookie", reflect.TypeOf((*MockBridge)(nil).SubscribePacketInByCookie), arg0, arg1, arg2)
+}
+
 // MockTable is a mock of Table interface
 type MockTable struct {
 	ctrl     *gomock.Controller
@@ -721,6 +763,20 @@ func (mr *MockActionMockRecorder) LoadRegRange(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadRegRange", reflect.TypeOf((*MockAction)(nil).LoadRegRange), arg0, arg1, arg2)
 }
 
+// Meter mocks base method
+func (m *MockAction) Meter(arg0 openflow.MeterIDType) openflow.FlowBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Meter", arg0)
+	ret0, _ := ret[0].(openflow.FlowBuilder)
+	return ret0
+}
+
+// Meter indicates an expected call of Meter
+func (mr *MockActionMockRecorder) Meter(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Meter", reflect.TypeOf((*MockAction)(nil).Meter), arg0)
+}
+
 // Move mocks base method
 func (m *MockAction) Move(arg0, arg1 string) openflow.FlowBuilder {
 	m.ctrl.T.Helper()
@@ -1691,6 +1747,20 @@ func (mr *MockFlowBuilderMockRecorder) MatchSrcMAC(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchSrcMAC", reflect.TypeOf((*MockFlowBuilder)(nil).MatchSrcMAC), arg0)
 }
 
+// MatchSrcPort mocks base method
+func (m *MockFlowBuilder) MatchSrcPort(arg0 uint16, arg1 *uint16) openflow.FlowBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchSrcPort", arg0, arg1)
+	ret0, _ := ret[0].(openflow.FlowBuilder)
+	return ret0
+}
+
+// MatchSrcPort indicates an expected call of MatchSrcPort
+func (mr *MockFlowBuilderMockRecorder) MatchSrcPort(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchSrcPort", reflect.TypeOf((*MockFlowBuilder)(nil).MatchSrcPort), arg0, arg1)
+}
+
 // MatchTunMetadata mocks base method
 func (m *MockFlowBuilder) MatchTunMetadata(arg0 int, arg1 uint32) openflow.FlowBuilder {
 	m.ctrl.T.Helper()
@@ -1746,3 +1816,244 @@ func (mr *MockFlowBuilderMockRecorder) SetIdleTimeout(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIdleTimeout", reflect.TypeOf((*MockFlowBuilder)(nil).SetIdleTimeout), arg0)
 }
+
+// MockMeter is a mock of Meter interface
+type MockMeter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMeterMockRecorder
+}
+
+// MockMeterMockRecorder is the mock recorder for MockMeter
+type MockMeterMockRecorder struct {
+	mock *MockMeter
+}
+
+// NewMockMeter creates a new mock instance
+func NewMockMeter(ctrl *gomock.Controller) *MockMeter {
+	mock := &MockMeter{ctrl: ctrl}
+	mock.recorder = &MockMeterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMeter) EXPECT() *MockMeterMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method
+func (m *MockMeter) Add() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add
+func (mr *MockMeterMockRecorder) Add() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockMeter)(nil).Add))
+}
+
+// Delete mocks base method
+func (m *MockMeter) Delete() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockMeterMockRecorder) Delete() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMeter)(nil).Delete))
+}
+
+// GetBundleMessage mocks base method
+func (m *MockMeter) GetBundleMessage(arg0 openflow.OFOperation) (ofctrl.OpenFlowModMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBundleMessage", arg0)
+	ret0, _ := ret[0].(ofctrl.OpenFlowModMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBundleMessage indicates an expected call of GetBundleMessage
+func (mr *MockMeterMockRecorder) GetBundleMessage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBundleMessage", reflect.TypeOf((*MockMeter)(nil).GetBundleMessage), arg0)
+}
+
+// GetID mocks base method
+func (m *MockMeter) GetID() openflow.MeterIDType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetID")
+	ret0, _ := ret[0].(openflow.MeterIDType)
+	return ret0
+}
+
+// GetID indicates an expected call of GetID
+func (mr *MockMeterMockRecorder) GetID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetID", reflect.TypeOf((*MockMeter)(nil).GetID))
+}
+
+// KeyString mocks base method
+func (m *MockMeter) KeyString() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyString")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyString indicates an expected call of KeyString
+func (mr *MockMeterMockRecorder) KeyString() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyString", reflect.TypeOf((*MockMeter)(nil).KeyString))
+}
+
+// MeterBand mocks base method
+func (m *MockMeter) MeterBand() openflow.MeterBandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeterBand")
+	ret0, _ := ret[0].(openflow.MeterBandBuilder)
+	return ret0
+}
+
+// MeterBand indicates an expected call of MeterBand
+func (mr *MockMeterMockRecorder) MeterBand() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeterBand", reflect.TypeOf((*MockMeter)(nil).MeterBand))
+}
+
+// Modify mocks base method
+func (m *MockMeter) Modify() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Modify")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockMeterMockRecorder) Modify() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockMeter)(nil).Modify))
+}
+
+// Reset mocks base method
+func (m *MockMeter) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockMeterMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockMeter)(nil).Reset))
+}
+
+// ResetMeterBands mocks base method
+func (m *MockMeter) ResetMeterBands() openflow.Meter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetMeterBands")
+	ret0, _ := ret[0].(openflow.Meter)
+	return ret0
+}
+
+// ResetMeterBands indicates an expected call of ResetMeterBands
+func (mr *MockMeterMockRecorder) ResetMeterBands() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetMeterBands", reflect.TypeOf((*MockMeter)(nil).ResetMeterBands))
+}
+
+// Type mocks base method
+func (m *MockMeter) Type() openflow.EntryType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Type")
+	ret0, _ := ret[0].(openflow.EntryType)
+	return ret0
+}
+
+// Type indicates an expected call of Type
+func (mr *MockMeterMockRecorder) Type() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Type", reflect.TypeOf((*MockMeter)(nil).Type))
+}
+
+// MockMeterBandBuilder is a mock of MeterBandBuilder interface
+type MockMeterBandBuilder struct {
+	ctrl     *gomock.Controller
+	recorder *MockMeterBandBuilderMockRecorder
+}
+
+// MockMeterBandBuilderMockRecorder is the mock recorder for MockMeterBandBuilder
+type MockMeterBandBuilderMockRecorder struct {
+	mock *MockMeterBandBuilder
+}
+
+// NewMockMeterBandBuilder creates a new mock instance
+func NewMockMeterBandBuilder(ctrl *gomock.Controller) *MockMeterBandBuilder {
+	mock := &MockMeterBandBuilder{ctrl: ctrl}
+	mock.recorder = &MockMeterBandBuilderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMeterBandBuilder) EXPECT() *MockMeterBandBuilderMockRecorder {
+	return m.recorder
+}
+
+// Burst mocks base method
+func (m *MockMeterBandBuilder) Burst(arg0 uint32) openflow.MeterBandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Burst", arg0)
+	ret0, _ := ret[0].(openflow.MeterBandBuilder)
+	return ret0
+}
+
+// Burst indicates an expected call of Burst
+func (mr *MockMeterBandBuilderMockRecorder) Burst(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Burst", reflect.TypeOf((*MockMeterBandBuilder)(nil).Burst), arg0)
+}
+
+// Done mocks base method
+func (m *MockMeterBandBuilder) Done() openflow.Meter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(openflow.Meter)
+	return ret0
+}
+
+// Done indicates an expected call of Done
+func (mr *MockMeterBandBuilderMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockMeterBandBuilder)(nil).Done))
+}
+
+// MeterType mocks base method
+func (m *MockMeterBandBuilder) MeterType(arg0 openflow.MeterBandType) openflow.MeterBandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeterType", arg0)
+	ret0, _ := ret[0].(openflow.MeterBandBuilder)
+	return ret0
+}
+
+// MeterType indicates an expected call of MeterType
+func (mr *MockMeterBandBuilderMockRecorder) MeterType(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeterType", reflect.TypeOf((*MockMeterBandBuilder)(nil).MeterType), arg0)
+}
+
+// Rate mocks base method
+func (m *MockMeterBandBuilder) Rate(arg0 uint32) openflow.MeterBandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rate", arg0)
+	ret0, _ := ret[0].(openflow.MeterBandBuilder)
+	return ret0
+}
+
+// Rate indicates an expected call of Rate
+func (mr *MockMeterBandBuilderMockRecorder) Rate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockMeterBandBuilder)(nil).Rate), arg0)
+}
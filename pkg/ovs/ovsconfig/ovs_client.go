@@ -372,7 +372,7 @@ func (br *OVSBridge) CreateInternalPort(name string, ofPortRequest int32, extern
 // the bridge.
 // If ofPortRequest is not zero, it will be passed to the OVS port creation.
 func (br *OVSBridge) CreateTunnelPort(name string, tunnelType TunnelType, ofPortRequest int32) (string, Error) {
-	return br.createTunnelPort(name, tunnelType, ofPortRequest, false, "", "", "", nil)
+	return br.createTunnelPort(name, tunnelType, ofPortRequest, false, "", "", "", "", nil)
 }
 
 // CreateTunnelPortExt creates a tunnel port with the specified name and type
@@ -380,9 +380,11 @@ func (br *OVSBridge) CreateTunnelPort(name string, tunnelType TunnelType, ofPort
 // If ofPortRequest is not zero, it will be passed to the OVS port creation.
 // If remoteIP is not empty, it will be set to the tunnel port interface
 // options; otherwise flow based tunneling will be configured.
-// psk is for the pre-shared key of IPSec ESP tunnel. If it is not empty, it
-// will be set to the tunnel port interface options. Flow based IPSec tunnel is
-// not supported, so remoteIP must be provided too when psk is not empty.
+// remoteName is the expected common name of the certificate of the remote
+// IPSec endpoint, and psk is for the pre-shared key of IPSec ESP tunnel. If
+// either is not empty, it will be set to the tunnel port interface options.
+// Flow based IPSec tunnel is not supported, so remoteIP must be provided too
+// when remoteName or psk is not empty.
 // If externalIDs is not nill, the IDs in it will be added to the port's
 // external_ids.
 func (br *OVSBridge) CreateTunnelPortExt(
@@ -392,12 +394,13 @@ func (br *OVSBridge) CreateTunnelPortExt(
 	csum bool,
 	localIP string,
 	remoteIP string,
+	remoteName string,
 	psk string,
 	externalIDs map[string]interface{}) (string, Error) {
-	if psk != "" && remoteIP == "" {
+	if (remoteName != "" || psk != "") && remoteIP == "" {
 		return "", newInvalidArgumentsError("IPSec tunnel can not be flow based. remoteIP must be set")
 	}
-	return br.createTunnelPort(name, tunnelType, ofPortRequest, csum, localIP, remoteIP, psk, externalIDs)
+	return br.createTunnelPort(name, tunnelType, ofPortRequest, csum, localIP, remoteIP, remoteName, psk, externalIDs)
 }
 
 func (br *OVSBridge) createTunnelPort(
@@ -407,6 +410,7 @@ func (br *OVSBridge) createTunnelPort(
 	csum bool,
 	localIP string,
 	remoteIP string,
+	remoteName string,
 	psk string,
 	externalIDs map[string]interface{}) (string, Error) {
 
@@ -429,6 +433,9 @@ func (br *OVSBridge) createTunnelPort(
 		options["local_ip"] = localIP
 	}
 
+	if remoteName != "" {
+		options["remote_name"] = remoteName
+	}
 	if psk != "" {
 		options["psk"] = psk
 	}
@@ -478,15 +485,15 @@ func (br *OVSBridge) SetInterfaceOptions(name string, options map[string]interfa
 	return nil
 }
 
-// ParseTunnelInterfaceOptions reads remote IP, local IP, IPSec PSK, and csum
-// from the tunnel interface options and returns them.
-func ParseTunnelInterfaceOptions(portData *OVSPortData) (net.IP, net.IP, string, bool) {
+// ParseTunnelInterfaceOptions reads remote IP, local IP, IPSec remote name,
+// IPSec PSK, and csum from the tunnel interface options and returns them.
+func ParseTunnelInterfaceOptions(portData *OVSPortData) (net.IP, net.IP, string, string, bool) {
 	if portData.Options == nil {
-		return nil, nil, "", false
+		return nil, nil, "", "", false
 	}
 
 	var ok bool
-	var remoteIPStr, localIPStr, psk string
+	var remoteIPStr, localIPStr, remoteName, psk string
 	var remoteIP, localIP net.IP
 	var csum bool
 
@@ -499,11 +506,12 @@ func ParseTunnelInterfaceOptions(portData *OVSPortData) (net.IP, net.IP, string,
 		localIP = net.ParseIP(localIPStr)
 	}
 
+	remoteName = portData.Options["remote_name"]
 	psk = portData.Options["psk"]
 	if csumStr, ok := portData.Options["csum"]; ok {
 		csum, _ = strconv.ParseBool(csumStr)
 	}
-	return remoteIP, localIP, psk, csum
+	return remoteIP, localIP, remoteName, psk, csum
 }
 
 // CreateUplinkPort creates uplink port.
@@ -17,6 +17,7 @@ package e2e
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -1205,12 +1206,21 @@ func TestAntreaPolicyStatus(t *testing.T) {
 		CurrentNodesRealized: 2,
 		DesiredNodesRealized: 2,
 	}
+	// The RulesIneffective condition is always reported, but its status depends on the Namespaces and Pods
+	// selected by the peers, which are not created by this test, so only its presence is checked.
+	statusMatches := func(status secv1alpha1.NetworkPolicyStatus) bool {
+		if len(status.Conditions) != 1 || status.Conditions[0].Type != secv1alpha1.NetworkPolicyRulesIneffective {
+			return false
+		}
+		status.Conditions = nil
+		return reflect.DeepEqual(status, expectedStatus)
+	}
 	err = wait.Poll(100*time.Millisecond, 3*time.Second, func() (bool, error) {
 		anp, err := data.securityClient.NetworkPolicies(anp.Namespace).Get(context.TODO(), anp.Name, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
-		return anp.Status == expectedStatus, nil
+		return statusMatches(anp.Status), nil
 	})
 	assert.NoError(t, err, "Antrea NetworkPolicy failed to reach expected status")
 	err = wait.Poll(100*time.Millisecond, 3*time.Second, func() (bool, error) {
@@ -1218,7 +1228,7 @@ func TestAntreaPolicyStatus(t *testing.T) {
 		if err != nil {
 			return false, err
 		}
-		return anp.Status == expectedStatus, nil
+		return statusMatches(anp.Status), nil
 	})
 	assert.NoError(t, err, "Antrea ClusterNetworkPolicy failed to reach expected status")
 }
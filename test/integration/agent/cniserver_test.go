@@ -572,7 +572,7 @@ func newTester() *cmdAddDelTester {
 		k8sFake.NewSimpleClientset(),
 		make(chan v1beta2.PodReference, 100),
 		false,
-		nil,
+		routeMock,
 		tester.networkReadyCh)
 	tester.server.Initialize(ovsServiceMock, ofServiceMock, ifaceStore, "")
 	ctx := context.Background()
@@ -600,7 +600,7 @@ func cmdAddDelCheckTest(testNS ns.NetNS, tc testCase, dataDir string) {
 	tester.setNS(testNS, targetNS)
 
 	ipamResult := ipamtest.GenerateIPAMResult("0.4.0", tc.addresses, tc.Routes, tc.DNS)
-	ipamMock.EXPECT().Add(mock.Any(), mock.Any()).Return(ipamResult, nil).AnyTimes()
+	ipamMock.EXPECT().Add(mock.Any(), mock.Any(), mock.Any()).Return(true, ipamResult, nil).AnyTimes()
 
 	// Mock ovs output while get ovs port external configuration
 	ovsPortname := util.GenerateContainerInterfaceName(testPod, testPodNamespace, ContainerID)
@@ -653,6 +653,7 @@ func TestAntreaServerFunc(t *testing.T) {
 	_ = ipam.RegisterIPAMDriver("mock", ipamMock)
 	ovsServiceMock = ovsconfigtest.NewMockOVSBridgeClient(controller)
 	ofServiceMock = openflowtest.NewMockClient(controller)
+	routeMock = routetest.NewMockInterface(controller)
 
 	var originalNS ns.NetNS
 	var dataDir string
@@ -666,11 +667,14 @@ func TestAntreaServerFunc(t *testing.T) {
 		dataDir, err = ioutil.TempDir("", "antrea_server_test")
 		require.Nil(t, err)
 
-		ipamMock.EXPECT().Del(mock.Any(), mock.Any()).Return(nil).AnyTimes()
-		ipamMock.EXPECT().Check(mock.Any(), mock.Any()).Return(nil).AnyTimes()
+		ipamMock.EXPECT().Del(mock.Any(), mock.Any(), mock.Any()).Return(true, nil).AnyTimes()
+		ipamMock.EXPECT().Check(mock.Any(), mock.Any(), mock.Any()).Return(true, nil).AnyTimes()
 
 		ovsServiceMock.EXPECT().GetPortList().Return([]ovsconfig.OVSPortData{}, nil).AnyTimes()
 		ovsServiceMock.EXPECT().IsHardwareOffloadEnabled().Return(false).AnyTimes()
+
+		routeMock.EXPECT().AddLocalPodRoutes(mock.Any()).Return(nil).AnyTimes()
+		routeMock.EXPECT().DeleteLocalPodRoutes(mock.Any()).Return(nil).AnyTimes()
 	}
 
 	teardown := func() {
@@ -834,6 +838,7 @@ func TestCNIServerChaining(t *testing.T) {
 		orderedCalls = append(orderedCalls,
 			routeMock.EXPECT().UnMigrateRoutesFromGw(containterHostRt, ""),
 			ofServiceMock.EXPECT().UninstallPodFlows(ovsPortname),
+			routeMock.EXPECT().DeleteLocalPodRoutes([]net.IP{podIP}),
 			ovsServiceMock.EXPECT().DeletePort(ovsPortUUID),
 		)
 		mock.InOrder(orderedCalls...)
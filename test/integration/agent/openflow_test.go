@@ -100,7 +100,7 @@ func TestConnectivityFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 	defer func() {
@@ -127,7 +127,7 @@ func TestConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsConnectivityFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -154,11 +154,11 @@ func TestReplayFlowsConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsNetworkPolicyFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
-	_, err = c.Initialize(roundInfo, &config1.NodeConfig{}, config1.TrafficEncapModeEncap)
+	_, err = c.Initialize(roundInfo, &config1.NodeConfig{}, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap})
 	require.Nil(t, err, "Failed to initialize OFClient")
 
 	defer func() {
@@ -238,7 +238,7 @@ func testReplayFlows(t *testing.T) {
 }
 
 func testInitialize(t *testing.T, config *testConfig) {
-	if _, err := c.Initialize(roundInfo, config.nodeConfig, config1.TrafficEncapModeEncap); err != nil {
+	if _, err := c.Initialize(roundInfo, config.nodeConfig, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap}); err != nil {
 		t.Errorf("Failed to initialize openflow client: %v", err)
 	}
 	for _, tableFlow := range prepareDefaultFlows(config) {
@@ -326,11 +326,11 @@ func TestNetworkPolicyFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
-	_, err = c.Initialize(roundInfo, &config1.NodeConfig{PodIPv4CIDR: podIPv4CIDR, PodIPv6CIDR: podIPv6CIDR}, config1.TrafficEncapModeEncap)
+	_, err = c.Initialize(roundInfo, &config1.NodeConfig{PodIPv4CIDR: podIPv4CIDR, PodIPv6CIDR: podIPv6CIDR}, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap})
 	require.Nil(t, err, "Failed to initialize OFClient")
 
 	defer func() {
@@ -432,11 +432,75 @@ func TestNetworkPolicyFlows(t *testing.T) {
 	checkOVSFlowMetrics(t, c)
 }
 
+// TestAuditRuleWithLowerTierDropRule checks that a packet logged by an Audit rule is still dropped by a lower priority
+// Drop rule of a lower Tier, which shares the same rule table.
+func TestAuditRuleWithLowerTierDropRule(t *testing.T) {
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
+	err := ofTestUtils.PrepareOVSBridge(br)
+	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
+
+	_, err = c.Initialize(roundInfo, &config1.NodeConfig{PodIPv4CIDR: podIPv4CIDR}, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap})
+	require.Nil(t, err, "Failed to initialize OFClient")
+
+	defer func() {
+		err = c.Disconnect()
+		assert.Nil(t, err, fmt.Sprintf("Error while disconnecting from OVS bridge: %v", err))
+		err = ofTestUtils.DeleteOVSBridge(br)
+		assert.Nil(t, err, fmt.Sprintf("Error while deleting OVS bridge: %v", err))
+	}()
+
+	srcIP, dstIP := "192.168.1.3", "192.168.3.4"
+	auditAction := secv1alpha1.RuleActionAudit
+	dropAction := secv1alpha1.RuleActionDrop
+	auditPriority, dropPriority := uint16(14900), uint16(14800)
+	auditRule := &types.PolicyRule{
+		Direction: v1beta2.DirectionOut,
+		From:      prepareIPAddresses([]string{srcIP}),
+		To:        prepareIPAddresses([]string{dstIP}),
+		Action:    &auditAction,
+		Priority:  &auditPriority,
+		FlowID:    uint32(110),
+		TableID:   ofClient.AntreaPolicyEgressRuleTable,
+		PolicyRef: &v1beta2.NetworkPolicyReference{
+			Type: v1beta2.AntreaClusterNetworkPolicy,
+			Name: "acnp-tier-a",
+			UID:  "uid-a",
+		},
+	}
+	dropRule := &types.PolicyRule{
+		Direction: v1beta2.DirectionOut,
+		From:      prepareIPAddresses([]string{srcIP}),
+		To:        prepareIPAddresses([]string{dstIP}),
+		Action:    &dropAction,
+		Priority:  &dropPriority,
+		FlowID:    uint32(111),
+		TableID:   ofClient.AntreaPolicyEgressRuleTable,
+		PolicyRef: &v1beta2.NetworkPolicyReference{
+			Type: v1beta2.AntreaClusterNetworkPolicy,
+			Name: "acnp-tier-b",
+			UID:  "uid-b",
+		},
+	}
+	require.Nil(t, c.InstallPolicyRuleFlows(auditRule), "Failed to InstallPolicyRuleFlows")
+	require.Nil(t, c.InstallPolicyRuleFlows(dropRule), "Failed to InstallPolicyRuleFlows")
+
+	// Send the traced packet to the rule table directly.
+	_, err = ovsCtlClient.RunOfctlCmd("add-flow", fmt.Sprintf("table=0,priority=65535,in_port=LOCAL,ip,actions=resubmit(,%d)", ofClient.AntreaPolicyEgressRuleTable))
+	require.Nil(t, err, "Failed to add flow")
+	out, execErr := ovsCtlClient.RunAppctlCmd("ofproto/trace", true, fmt.Sprintf("in_port=LOCAL,ip,nw_src=%s,nw_dst=%s", srcIP, dstIP))
+	require.Nil(t, execErr, "Failed to trace packet")
+	trace := string(out)
+	// The packet is logged by the Audit rule, and then dropped by the Drop rule.
+	assert.Contains(t, trace, fmt.Sprintf("conj_id=%d", auditRule.FlowID))
+	assert.Contains(t, trace, fmt.Sprintf("conj_id=%d", dropRule.FlowID))
+	assert.Contains(t, trace, fmt.Sprintf("%d. reg0=0x100000/0x100000", ofClient.EgressMetricTable))
+}
+
 func TestIPv6ConnectivityFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -467,11 +531,11 @@ type svcConfig struct {
 }
 
 func TestProxyServiceFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, true, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
-	_, err = c.Initialize(roundInfo, &config1.NodeConfig{}, config1.TrafficEncapModeEncap)
+	_, err = c.Initialize(roundInfo, &config1.NodeConfig{}, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap})
 	require.Nil(t, err, "Failed to initialize OFClient")
 
 	defer func() {
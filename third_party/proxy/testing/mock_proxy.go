@@ -23,6 +23,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	proxy "github.com/vmware-tanzu/antrea/third_party/proxy"
 	v1 "k8s.io/api/core/v1"
+	v1beta1 "k8s.io/api/discovery/v1beta1"
 	reflect "reflect"
 )
 
@@ -64,6 +65,54 @@ func (mr *MockProviderMockRecorder) GetServiceByIP(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceByIP", reflect.TypeOf((*MockProvider)(nil).GetServiceByIP), arg0)
 }
 
+// OnEndpointSliceAdd mocks base method
+func (m *MockProvider) OnEndpointSliceAdd(arg0 *v1beta1.EndpointSlice) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnEndpointSliceAdd", arg0)
+}
+
+// OnEndpointSliceAdd indicates an expected call of OnEndpointSliceAdd
+func (mr *MockProviderMockRecorder) OnEndpointSliceAdd(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnEndpointSliceAdd", reflect.TypeOf((*MockProvider)(nil).OnEndpointSliceAdd), arg0)
+}
+
+// OnEndpointSliceDelete mocks base method
+func (m *MockProvider) OnEndpointSliceDelete(arg0 *v1beta1.EndpointSlice) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnEndpointSliceDelete", arg0)
+}
+
+// OnEndpointSliceDelete indicates an expected call of OnEndpointSliceDelete
+func (mr *MockProviderMockRecorder) OnEndpointSliceDelete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnEndpointSliceDelete", reflect.TypeOf((*MockProvider)(nil).OnEndpointSliceDelete), arg0)
+}
+
+// OnEndpointSliceUpdate mocks base method
+func (m *MockProvider) OnEndpointSliceUpdate(arg0, arg1 *v1beta1.EndpointSlice) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnEndpointSliceUpdate", arg0, arg1)
+}
+
+// OnEndpointSliceUpdate indicates an expected call of OnEndpointSliceUpdate
+func (mr *MockProviderMockRecorder) OnEndpointSliceUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnEndpointSliceUpdate", reflect.TypeOf((*MockProvider)(nil).OnEndpointSliceUpdate), arg0, arg1)
+}
+
+// OnEndpointSlicesSynced mocks base method
+func (m *MockProvider) OnEndpointSlicesSynced() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnEndpointSlicesSynced")
+}
+
+// OnEndpointSlicesSynced indicates an expected call of OnEndpointSlicesSynced
+func (mr *MockProviderMockRecorder) OnEndpointSlicesSynced() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnEndpointSlicesSynced", reflect.TypeOf((*MockProvider)(nil).OnEndpointSlicesSynced))
+}
+
 // OnEndpointsAdd mocks base method
 func (m *MockProvider) OnEndpointsAdd(arg0 *v1.Endpoints) {
 	m.ctrl.T.Helper()
@@ -30,8 +30,8 @@ limitations under the License.
 
 Modifies:
 - Replace import "k8s.io/kubernetes/pkg/proxy/config" with "github.com/vmware-tanzu/antrea/third_party/proxy/config"
-- Remove config.EndpointSliceHandler, config.NodeHandler from Provider interface type
-- Remove NodeHandler, EndpointSliceHandler, Sync() from Provider interface
+- Remove config.NodeHandler from Provider interface type
+- Remove NodeHandler, Sync() from Provider interface
 - Add Run(), GetServiceByIP() to Provider interface
 */
 
@@ -50,6 +50,7 @@ import (
 // Provider is the interface provided by proxier implementations.
 type Provider interface {
 	config.EndpointsHandler
+	config.EndpointSliceHandler
 	config.ServiceHandler
 
 	// SyncLoop runs periodic work.